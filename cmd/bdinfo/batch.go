@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/autobrr/go-bdinfo/internal/util"
+	bdinfo "github.com/autobrr/go-bdinfo/pkg/bdinfo"
+)
+
+// batchEntry is one row of the summary printed after a multi-disc run.
+type batchEntry struct {
+	disc     string
+	playlist string
+	length   string
+	size     uint64
+	status   string
+	err      error
+}
+
+// newBatchEntry summarizes the outcome of scanning target. err is the error
+// that stopped the disc, if any; non-fatal scan errors are read from result.
+func newBatchEntry(target string, result bdinfo.Result, err error) batchEntry {
+	entry := batchEntry{disc: target, playlist: result.MainPlaylist, status: "ok", err: err}
+	for _, playlist := range result.Playlists {
+		if playlist.Name == result.MainPlaylist {
+			entry.length = playlist.Length
+			entry.size = playlist.SizeBytes
+			break
+		}
+	}
+	switch {
+	case err != nil:
+		entry.status = "failed: " + err.Error()
+	case result.Scan.ScanError != "":
+		entry.status = "errors: " + result.Scan.ScanError
+	case len(result.Scan.FileErrors) == 1:
+		entry.status = "errors: 1 file"
+	case len(result.Scan.FileErrors) > 1:
+		entry.status = fmt.Sprintf("errors: %d files", len(result.Scan.FileErrors))
+	}
+	return entry
+}
+
+// writeBatchSummary prints one aligned row per disc with its main playlist,
+// runtime, size and scan status.
+func writeBatchSummary(w io.Writer, entries []batchEntry) error {
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Disc\tMain Playlist\tLength\tSize\tStatus")
+	fmt.Fprintln(tw, "----\t-------------\t------\t----\t------")
+	for _, entry := range entries {
+		playlist, length, size := "-", "-", "-"
+		if entry.playlist != "" {
+			playlist = entry.playlist
+			length = entry.length
+			size = util.FormatFileSize(float64(entry.size), true)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", entry.disc, playlist, length, size, entry.status)
+	}
+	return tw.Flush()
+}
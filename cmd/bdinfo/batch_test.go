@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	bdinfo "github.com/autobrr/go-bdinfo/pkg/bdinfo"
+)
+
+func TestNewBatchEntry(t *testing.T) {
+	result := bdinfo.Result{
+		MainPlaylist: "00800.MPLS",
+		Playlists: []bdinfo.PlaylistInfo{
+			{Name: "00001.MPLS", Length: "0:01:00.000", SizeBytes: 1},
+			{Name: "00800.MPLS", Length: "1:58:12.345", SizeBytes: 40 << 30},
+		},
+	}
+	entry := newBatchEntry("/discs/A", result, nil)
+	if entry.playlist != "00800.MPLS" || entry.length != "1:58:12.345" || entry.size != 40<<30 || entry.status != "ok" {
+		t.Fatalf("ok entry=%+v", entry)
+	}
+
+	result.Scan.FileErrors = map[string]string{"00001.M2TS": "read error", "00002.M2TS": "read error"}
+	if entry := newBatchEntry("/discs/A", result, nil); entry.status != "errors: 2 files" {
+		t.Fatalf("file errors status=%q", entry.status)
+	}
+
+	if entry := newBatchEntry("/discs/B", bdinfo.Result{}, errors.New("no BDMV")); entry.status != "failed: no BDMV" || entry.err == nil {
+		t.Fatalf("failed entry=%+v", entry)
+	}
+}
+
+func TestWriteBatchSummary(t *testing.T) {
+	var out strings.Builder
+	err := writeBatchSummary(&out, []batchEntry{
+		{disc: "/discs/A", playlist: "00800.MPLS", length: "1:58:12.345", size: 40 << 30, status: "ok"},
+		{disc: "/discs/LONG_NAME", status: "failed: no BDMV", err: errors.New("no BDMV")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("lines=%q", lines)
+	}
+	if !strings.HasPrefix(lines[0], "Disc") || !strings.Contains(lines[0], "Main Playlist") {
+		t.Fatalf("header=%q", lines[0])
+	}
+	if !strings.Contains(lines[2], "00800.MPLS") || !strings.Contains(lines[2], "40.00 GB") || !strings.HasSuffix(lines[2], "ok") {
+		t.Fatalf("row=%q", lines[2])
+	}
+	if !strings.HasSuffix(lines[3], "failed: no BDMV") {
+		t.Fatalf("row=%q", lines[3])
+	}
+	if strings.Index(lines[2], "00800.MPLS") != strings.Index(lines[0], "Main Playlist") {
+		t.Fatalf("columns not aligned:\n%s", out.String())
+	}
+}
+
+func TestCombinedReport(t *testing.T) {
+	combined := bdinfo.MultiDiscResult{Discs: []bdinfo.DiscResult{
+		{Path: "/discs/A", Result: bdinfo.Result{
+			Disc:         bdinfo.DiscInfo{Label: "MOVIE_A"},
+			MainPlaylist: "00800.MPLS",
+			Playlists: []bdinfo.PlaylistInfo{{
+				Name: "00800.MPLS", Length: "1:58:12.345", SizeBytes: 40 << 30,
+				Streams: []bdinfo.StreamInfo{
+					{Kind: bdinfo.StreamKindVideo, CodecShort: "HEVC"},
+					{Kind: bdinfo.StreamKindAudio, CodecShort: "TrueHD", Hidden: true},
+					{Kind: bdinfo.StreamKindAudio, CodecShort: "DTS-HD MA", Audio: &bdinfo.AudioDetails{Channels: "7.1"}},
+				},
+			}},
+			Report: "DISC INFO:\n\nDisc Label:     MOVIE_A\n",
+		}},
+		{Path: "/discs/B", Error: "no BDMV"},
+	}}
+
+	out := combinedReport("/out/BDINFO.txt", combined)
+	lines := strings.Split(out, "\n")
+	if lines[0] != "DISC INDEX:" || !strings.Contains(lines[2], "Disc Label") {
+		t.Fatalf("index header got:\n%s", out)
+	}
+	for _, want := range []string{"MOVIE_A", "00800.MPLS", "1:58:12.345", "40.00 GB", "HEVC", "DTS-HD MA 7.1"} {
+		if !strings.Contains(lines[4], want) {
+			t.Fatalf("index row %q has no %q", lines[4], want)
+		}
+	}
+	if !strings.Contains(lines[5], "/discs/B") || !strings.Contains(lines[5], "failed") {
+		t.Fatalf("failed row=%q", lines[5])
+	}
+	if !strings.Contains(out, "DISC 1 OF 2: /discs/A\n") || !strings.Contains(out, "Disc Label:     MOVIE_A") {
+		t.Fatalf("disc section missing:\n%s", out)
+	}
+	if !strings.Contains(out, "DISC 2 OF 2: /discs/B\n") || !strings.Contains(out, "Error: no BDMV") {
+		t.Fatalf("failed disc section missing:\n%s", out)
+	}
+
+	if out := combinedReport("/out/BDINFO.xml", combined); out != combined.Discs[0].Result.Report {
+		t.Fatalf("XML combined report got=%q", out)
+	}
+}
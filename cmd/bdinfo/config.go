@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// configFlags are command-line only: they pick or write the config file, or do
+// not describe how a disc is scanned and reported.
+var configFlags = map[string]bool{
+	"help":            true,
+	"config":          true,
+	"bdinfo-settings": true,
+	"save-config":     true,
+	"self-update":     true,
+	"update":          true,
+}
+
+// configEntry is one key of the config file with its values; an array gives
+// one value per element.
+type configEntry struct {
+	line   int
+	key    string
+	values []string
+	array  bool
+}
+
+// defaultConfigPath returns ~/.config/bdinfo/config.toml (or the platform's
+// equivalent), or "" when there is no user config directory.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bdinfo", "config.toml")
+}
+
+// applyConfigFile sets every flag of cmd not given on the command line or
+// through the environment from the config file: --config when set, the default
+// path otherwise. A missing default file is not an error. Keys are long flag
+// names; keys of another bdinfo command are skipped, so one file serves both
+// bdinfo and bdinfo render.
+func applyConfigFile(cmd *cobra.Command, path string) error {
+	explicit := path != ""
+	if !explicit {
+		if path = defaultConfigPath(); path == "" {
+			return nil
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("config: %w", err)
+	}
+	defer f.Close()
+
+	entries, err := parseConfig(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	root := cmd.Root()
+	known := func(key string) bool {
+		if root.Flags().Lookup(key) != nil {
+			return true
+		}
+		for _, sub := range root.Commands() {
+			if sub.Flags().Lookup(key) != nil {
+				return true
+			}
+		}
+		return false
+	}
+	if err := applyConfig(cmd.Flags(), entries, known); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// applyConfig sets the flags not changed yet from entries. Keys missing from
+// flags are skipped when known reports them, and rejected otherwise.
+func applyConfig(flags *pflag.FlagSet, entries []configEntry, known func(string) bool) error {
+	for _, entry := range entries {
+		f := flags.Lookup(entry.key)
+		if configFlags[entry.key] || (f == nil && !known(entry.key)) {
+			return fmt.Errorf("line %d: unknown option %q", entry.line, entry.key)
+		}
+		if f == nil || f.Changed {
+			continue
+		}
+		slice, isSlice := f.Value.(pflag.SliceValue)
+		var err error
+		switch {
+		case entry.array && !isSlice:
+			return fmt.Errorf("line %d: %s: takes a single value, not an array", entry.line, entry.key)
+		case entry.array:
+			// Elements are taken whole: Set would split "a,b" like --path-map a,b.
+			if err = slice.Replace(entry.values); err == nil {
+				f.Changed = true
+			}
+		default:
+			err = flags.Set(entry.key, entry.values[0])
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %s: %w", entry.line, entry.key, err)
+		}
+	}
+	return nil
+}
+
+// parseConfig reads the TOML subset bdinfo writes: comments and one
+// `key = value` per line, where a value is a string, boolean, number or a
+// one-line array of those. Tables are not supported; keys are flag names.
+func parseConfig(r io.Reader) ([]configEntry, error) {
+	var entries []configEntry
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported", n)
+		}
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		key = strings.TrimSpace(key)
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			key = unquoted
+		}
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", n)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("line %d: duplicate key %q", n, key)
+		}
+		seen[key] = true
+
+		entry := configEntry{line: n, key: key}
+		rest = strings.TrimSpace(rest)
+		var err error
+		if strings.HasPrefix(rest, "[") {
+			entry.array = true
+			entry.values, rest, err = parseConfigArray(rest[1:])
+		} else {
+			var value string
+			value, rest, err = parseConfigValue(rest)
+			entry.values = []string{value}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n, key, err)
+		}
+		if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+			return nil, fmt.Errorf("line %d: %s: unexpected %q after value", n, key, rest)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// parseConfigArray parses the elements of an array after its opening bracket.
+func parseConfigArray(s string) ([]string, string, error) {
+	values := []string{}
+	for {
+		s = strings.TrimSpace(s)
+		if rest, ok := strings.CutPrefix(s, "]"); ok {
+			return values, rest, nil
+		}
+		value, rest, err := parseConfigValue(s)
+		if err != nil {
+			return nil, "", err
+		}
+		values = append(values, value)
+		rest = strings.TrimSpace(rest)
+		switch {
+		case strings.HasPrefix(rest, ","):
+			s = rest[1:]
+		case strings.HasPrefix(rest, "]"):
+			s = rest
+		default:
+			return nil, "", errors.New("unterminated array")
+		}
+	}
+}
+
+// parseConfigValue parses one scalar at the start of s and returns the rest.
+// Basic strings take Go/TOML escapes; literal strings ('...') take none.
+func parseConfigValue(s string) (string, string, error) {
+	switch {
+	case s == "":
+		return "", "", errors.New("missing value")
+	case s[0] == '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", errors.New("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	case s[0] == '"':
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				value, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					return "", "", fmt.Errorf("invalid string %s", s[:i+1])
+				}
+				return value, s[i+1:], nil
+			}
+		}
+		return "", "", errors.New("unterminated string")
+	}
+	end := strings.IndexAny(s, ",]# \t")
+	if end < 0 {
+		end = len(s)
+	}
+	if end == 0 {
+		return "", "", errors.New("missing value")
+	}
+	return s[:end], s[end:], nil
+}
+
+// writeConfig writes every flag set on the command line, through the
+// environment or by a loaded config file, in the format parseConfig reads.
+func writeConfig(w io.Writer, flags *pflag.FlagSet) error {
+	var b strings.Builder
+	b.WriteString("# bdinfo config: keys are long flag names.\n")
+	b.WriteString("# Command-line flags and BDINFO_* variables override these values.\n")
+	flags.VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || configFlags[f.Name] {
+			return
+		}
+		fmt.Fprintf(&b, "%s = %s\n", f.Name, configValue(f.Value))
+	})
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func configValue(value pflag.Value) string {
+	if slice, ok := value.(pflag.SliceValue); ok {
+		items := slice.GetSlice()
+		for i, item := range items {
+			items[i] = configQuote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	switch value.Type() {
+	case "bool", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return value.String()
+	}
+	return configQuote(value.String())
+}
+
+// configQuote writes s as a TOML basic string.
+func configQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, "\\u%04x", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// saveConfigFile writes the current flags to path, or to the default path.
+func saveConfigFile(flags *pflag.FlagSet, path string) (string, error) {
+	if path == "" {
+		if path = defaultConfigPath(); path == "" {
+			return "", errors.New("no user config directory; use --config to choose a file")
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := writeConfig(&b, flags); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(b.String()), 0o644)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func TestParseConfig(t *testing.T) {
+	entries, err := parseConfig(strings.NewReader(`# defaults
+main = true
+workers = 4 # scan workers
+"max-read-mbps" = 200.5
+reportfilename = "BDInfo_{0} \"x\".txt"
+tempdir = 'C:\temp'
+path-map = ["/mnt/nas:/media", '/srv/out:/out',]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string][]string{}
+	for _, entry := range entries {
+		got[entry.key] = entry.values
+	}
+	want := map[string][]string{
+		"main":           {"true"},
+		"workers":        {"4"},
+		"max-read-mbps":  {"200.5"},
+		"reportfilename": {`BDInfo_{0} "x".txt`},
+		"tempdir":        {`C:\temp`},
+		"path-map":       {"/mnt/nas:/media", "/srv/out:/out"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("entries=%v", got)
+	}
+	for key, values := range want {
+		if strings.Join(got[key], "|") != strings.Join(values, "|") {
+			t.Fatalf("%s=%q want %q", key, got[key], values)
+		}
+	}
+	if entries[2].line != 4 {
+		t.Fatalf("line=%d", entries[2].line)
+	}
+
+	for _, bad := range []string{
+		"[bdinfo]\n",
+		"main\n",
+		"main = true\nmain = false\n",
+		"reportfilename = \"open\n",
+		"path-map = [\"a\" \"b\"]\n",
+		"main = true false\n",
+		"main =\n",
+	} {
+		if _, err := parseConfig(strings.NewReader(bad)); err == nil {
+			t.Fatalf("parseConfig(%q) should fail", bad)
+		}
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	var (
+		main    bool
+		workers int
+		delay   time.Duration
+		report  string
+		mapping []string
+	)
+	newFlags := func() *pflag.FlagSet {
+		flags := pflag.NewFlagSet("bdinfo", pflag.ContinueOnError)
+		flags.BoolVar(&main, "main", false, "")
+		flags.IntVar(&workers, "workers", 0, "")
+		flags.DurationVar(&delay, "io-retry-delay", time.Second, "")
+		flags.StringVarP(&report, "reportfilename", "o", "", "")
+		flags.StringSliceVar(&mapping, "path-map", nil, "")
+		return flags
+	}
+	flags := newFlags()
+	if err := flags.Parse([]string{"-o", "cli.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := parseConfig(strings.NewReader(`main = true
+workers = 4
+io-retry-delay = "250ms"
+reportfilename = "config.txt"
+path-map = ["/mnt/nas:/media", "/srv/a,b:/out"]
+format = "json"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	known := func(key string) bool { return key == "format" }
+	if err := applyConfig(flags, entries, known); err != nil {
+		t.Fatal(err)
+	}
+	if !main || workers != 4 || delay != 250*time.Millisecond {
+		t.Fatalf("config not applied: main=%v workers=%d delay=%v", main, workers, delay)
+	}
+	if len(mapping) != 2 || mapping[1] != "/srv/a,b:/out" {
+		t.Fatalf("array elements should be set one by one: %q", mapping)
+	}
+	if report != "cli.txt" {
+		t.Fatalf("command line should win over config: report=%q", report)
+	}
+
+	for _, bad := range []string{
+		"colour = true\n",
+		"config = \"other.toml\"\n",
+		"workers = many\n",
+		"main = [true]\n",
+	} {
+		entries, err := parseConfig(strings.NewReader(bad))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := applyConfig(newFlags(), entries, known); err == nil {
+			t.Fatalf("applyConfig(%q) should fail", bad)
+		}
+	}
+}
+
+func TestWriteConfig(t *testing.T) {
+	var (
+		main    bool
+		workers int
+		report  string
+		config  string
+		mapping []string
+	)
+	flags := pflag.NewFlagSet("bdinfo", pflag.ContinueOnError)
+	flags.BoolVar(&main, "main", false, "")
+	flags.IntVar(&workers, "workers", 0, "")
+	flags.StringVar(&report, "reportfilename", "", "")
+	flags.StringVar(&config, "config", "", "")
+	flags.StringSliceVar(&mapping, "path-map", nil, "")
+	if err := flags.Parse([]string{"--main", "--reportfilename", `BDInfo_{0} "x".txt`, "--config", "c.toml", "--path-map", "/a:/b,/c:/d"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := writeConfig(&out, flags); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"main = true\n",
+		`reportfilename = "BDInfo_{0} \"x\".txt"` + "\n",
+		`path-map = ["/a:/b", "/c:/d"]` + "\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("config missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "workers") || strings.Contains(text, "config =") {
+		t.Fatalf("unset and command-line only flags should not be written:\n%s", text)
+	}
+
+	entries, err := parseConfig(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("written config does not parse: %v\n%s", err, text)
+	}
+	if len(entries) != 3 || entries[2].values[0] != `BDInfo_{0} "x".txt` {
+		t.Fatalf("round trip entries=%+v", entries)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/go-bdinfo/internal/fs/udf"
+)
+
+type debugUDFOptions struct {
+	avdp       bool
+	lvd        bool
+	partitions bool
+	fsd        bool
+	icbs       []string
+}
+
+var debugUDFOpts debugUDFOptions
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Low-level disc inspection tools",
+	Long:  "Low-level disc inspection tools for investigating parsing and parity issues.",
+}
+
+var debugUDFCmd = &cobra.Command{
+	Use:   "udf <iso>",
+	Short: "Inspect UDF structures of an ISO",
+	Long: "Inspect UDF structures of an ISO.\n\n" +
+		"Without structure flags, prints an overview (volume info, key BDMV directories and file headers).",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDebugUDF(cmd.OutOrStdout(), args[0], debugUDFOpts)
+	},
+}
+
+func init() {
+	debugUDFCmd.Flags().BoolVar(&debugUDFOpts.avdp, "avdp", false, "Dump the anchor volume descriptor pointer")
+	debugUDFCmd.Flags().BoolVar(&debugUDFOpts.lvd, "lvd", false, "Dump the logical volume descriptor")
+	debugUDFCmd.Flags().BoolVar(&debugUDFOpts.partitions, "partitions", false, "Dump partition descriptors and partition maps")
+	debugUDFCmd.Flags().BoolVar(&debugUDFOpts.fsd, "fsd", false, "Dump the file set descriptor")
+	debugUDFCmd.Flags().StringSliceVar(&debugUDFOpts.icbs, "icb", nil, "Dump the file entry at an ICB address (<lbn> or <partref>:<lbn>, repeatable)")
+
+	debugCmd.AddCommand(debugUDFCmd)
+}
+
+func runDebugUDF(out io.Writer, iso string, opts debugUDFOptions) error {
+	icbs := make([]udf.LongAD, 0, len(opts.icbs))
+	for _, raw := range opts.icbs {
+		icb, err := parseICBAddr(raw)
+		if err != nil {
+			return err
+		}
+		icbs = append(icbs, icb)
+	}
+
+	r, err := udf.NewReader(iso)
+	if err != nil {
+		return fmt.Errorf("open udf: %w", err)
+	}
+	defer r.Close()
+
+	selected := opts.avdp || opts.lvd || opts.partitions || opts.fsd || len(icbs) > 0
+	if !selected {
+		dumpUDFOverview(out, r)
+		return nil
+	}
+
+	if opts.avdp {
+		dumpUDFAnchor(out, r)
+	}
+	if opts.lvd {
+		dumpUDFLogicalVolume(out, r)
+	}
+	if opts.partitions {
+		dumpUDFPartitions(out, r)
+	}
+	if opts.fsd {
+		dumpUDFFileSet(out, r)
+	}
+	for _, icb := range icbs {
+		dumpUDFICB(out, r, icb)
+	}
+	return nil
+}
+
+// parseICBAddr parses "<lbn>" or "<partref>:<lbn>" into an ICB long_ad.
+func parseICBAddr(raw string) (udf.LongAD, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return udf.LongAD{}, errors.New("empty icb address")
+	}
+	prefStr, lbnStr, hasPref := strings.Cut(raw, ":")
+	if !hasPref {
+		lbnStr = prefStr
+		prefStr = "0"
+	}
+	pref, err := strconv.ParseUint(prefStr, 0, 16)
+	if err != nil {
+		return udf.LongAD{}, fmt.Errorf("invalid icb partition reference %q: %w", prefStr, err)
+	}
+	lbn, err := strconv.ParseUint(lbnStr, 0, 32)
+	if err != nil {
+		return udf.LongAD{}, fmt.Errorf("invalid icb block %q: %w", lbnStr, err)
+	}
+	return udf.LongAD{
+		ExtentLocation: udf.LBAddr{
+			LogicalBlockNumber:       uint32(lbn),
+			PartitionReferenceNumber: uint16(pref),
+		},
+	}, nil
+}
+
+func dumpUDFAnchor(out io.Writer, r *udf.Reader) {
+	fmt.Fprintln(out, "AVDP:")
+	avdp := r.AnchorDescriptor()
+	if avdp == nil {
+		fmt.Fprintln(out, "  (not found)")
+		return
+	}
+	fmt.Fprintf(out, "  tag=%d version=%d location=%d\n", avdp.DescriptorTag.TagIdentifier, avdp.DescriptorTag.DescriptorVersion, avdp.DescriptorTag.TagLocation)
+	fmt.Fprintf(out, "  mainVDS: loc=%d len=%d\n", avdp.MainVolumeDescriptorSequenceExtent.Location, avdp.MainVolumeDescriptorSequenceExtent.Length)
+	fmt.Fprintf(out, "  reserveVDS: loc=%d len=%d\n", avdp.ReserveVolumeDescriptorSequenceExtent.Location, avdp.ReserveVolumeDescriptorSequenceExtent.Length)
+}
+
+func dumpUDFLogicalVolume(out io.Writer, r *udf.Reader) {
+	fmt.Fprintln(out, "LVD:")
+	lvd := r.LogicalVolume()
+	if lvd == nil {
+		fmt.Fprintln(out, "  (not found)")
+		return
+	}
+	fmt.Fprintf(out, "  identifier=%q\n", r.DecodeString(lvd.LogicalVolumeIdentifier[:]))
+	fmt.Fprintf(out, "  domain=%q\n", entityIdent(lvd.DomainIdentifier))
+	fmt.Fprintf(out, "  implementation=%q\n", entityIdent(lvd.ImplementationIdentifier))
+	fmt.Fprintf(out, "  blockSize=%d mapTableLength=%d partitionMaps=%d\n", lvd.LogicalBlockSize, lvd.MapTableLength, lvd.NumberOfPartitionMaps)
+	fmt.Fprintf(out, "  contentsUse=% x\n", lvd.LogicalVolumeContentsUse[:])
+	fmt.Fprintf(out, "  integritySequence: loc=%d len=%d\n", lvd.IntegritySequenceExtent.Location, lvd.IntegritySequenceExtent.Length)
+}
+
+func dumpUDFPartitions(out io.Writer, r *udf.Reader) {
+	fmt.Fprintln(out, "PARTITIONS:")
+	for _, pd := range r.PartitionDescriptors() {
+		fmt.Fprintf(out, "  number=%d contents=%q access=%d start=%d length=%d\n",
+			pd.PartitionNumber, entityIdent(pd.PartitionContents), pd.AccessType, pd.PartitionStartingLocation, pd.PartitionLength)
+	}
+	fmt.Fprintln(out, "PARTITION MAPS:")
+	for _, pm := range r.DebugPartitionMaps() {
+		fmt.Fprintf(out, "  %s\n", pm)
+	}
+}
+
+func dumpUDFFileSet(out io.Writer, r *udf.Reader) {
+	fmt.Fprintln(out, "FSD:")
+	fsd := r.FileSet()
+	if fsd == nil {
+		fmt.Fprintln(out, "  (not found)")
+		return
+	}
+	fmt.Fprintf(out, "  location=%d (partition start %d)\n", r.FileSetLocation(), r.PartitionStart())
+	fmt.Fprintf(out, "  logicalVolume=%q fileSet=%q\n", r.DecodeString(fsd.LogicalVolumeIdentifier[:]), r.DecodeString(fsd.FileSetIdentifier[:]))
+	fmt.Fprintf(out, "  fileSetNumber=%d descriptorNumber=%d interchange=%d/%d\n", fsd.FileSetNumber, fsd.FileSetDescriptorNumber, fsd.InterchangeLevel, fsd.MaximumInterchangeLevel)
+	fmt.Fprintf(out, "  domain=%q\n", entityIdent(fsd.DomainIdentifier))
+	fmt.Fprintf(out, "  rootICB: extentLen=%d lbn=%d pref=%d\n", fsd.RootDirectoryICB.ExtentLength, fsd.RootDirectoryICB.ExtentLocation.LogicalBlockNumber, fsd.RootDirectoryICB.ExtentLocation.PartitionReferenceNumber)
+	fmt.Fprintf(out, "  streamDirICB: extentLen=%d lbn=%d pref=%d\n", fsd.SystemStreamDirectoryICB.ExtentLength, fsd.SystemStreamDirectoryICB.ExtentLocation.LogicalBlockNumber, fsd.SystemStreamDirectoryICB.ExtentLocation.PartitionReferenceNumber)
+}
+
+func dumpUDFICB(out io.Writer, r *udf.Reader, icb udf.LongAD) {
+	fmt.Fprintf(out, "ICB %d:%d:\n", icb.ExtentLocation.PartitionReferenceNumber, icb.ExtentLocation.LogicalBlockNumber)
+	info, err := r.InspectICB(icb)
+	if err != nil {
+		fmt.Fprintf(out, "  err: %v\n", err)
+		return
+	}
+	fmt.Fprintf(out, "  block=%d tag=%d fileType=%d allocType=%d\n", info.Location, info.TagIdentifier, info.FileType, info.Flags&0x7)
+	fmt.Fprintf(out, "  informationLength=%d modTime=%s\n", info.InformationLength, info.ModTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(out, "  extents (%d):\n", len(info.Extents))
+	for _, ext := range info.Extents {
+		fmt.Fprintf(out, "  - len=%d lbn=%d pref=%d\n", ext.Length, ext.LBN, ext.PartitionRef)
+	}
+}
+
+func entityIdent(id udf.EntityID) string {
+	return strings.TrimRight(string(id.Identifier[:]), "\x00 ")
+}
+
+// dumpUDFOverview lists key directories/files and sanity-checks headers and sizes.
+func dumpUDFOverview(out io.Writer, r *udf.Reader) {
+	fmt.Fprintf(out, "label=%q blockSize=%d partitionStart=%d fileSetLocation=%d\n", r.GetVolumeLabel(), r.BlockSize(), r.PartitionStart(), r.FileSetLocation())
+	fmt.Fprintf(out, "partitionMaps=%v\n", r.DebugPartitionMaps())
+	fmt.Fprintf(out, "rootICB: extentLen=%d lbn=%d pref=%d\n", r.RootICB().ExtentLength, r.RootICB().ExtentLocation.LogicalBlockNumber, r.RootICB().ExtentLocation.PartitionReferenceNumber)
+
+	dir, err := r.ReadDirectory("/")
+	if err != nil {
+		fmt.Fprintf(out, "ReadDirectory(/) err: %v\n", err)
+		return
+	}
+
+	dirs, err := dir.GetDirectories()
+	if err != nil {
+		fmt.Fprintf(out, "GetDirectories err: %v\n", err)
+		return
+	}
+	fmt.Fprintf(out, "root dirs (%d):\n", len(dirs))
+	for _, d := range dirs {
+		fmt.Fprintf(out, "- %q\n", d.Name)
+	}
+
+	files, err := dir.GetFiles()
+	if err != nil {
+		fmt.Fprintf(out, "GetFiles err: %v\n", err)
+		return
+	}
+	fmt.Fprintf(out, "root files (%d):\n", len(files))
+	for _, f := range files {
+		fmt.Fprintf(out, "- %q size=%d\n", f.Name, f.Size())
+	}
+
+	if !dumpUDFHeaders(out, r, "/BDMV/PLAYLIST", "PLAYLIST", 0, false) {
+		return
+	}
+	if !dumpUDFHeaders(out, r, "/BDMV/CLIPINF", "CLIPINF", 10, true) {
+		return
+	}
+
+	st, err := r.ReadDirectory("/BDMV/STREAM")
+	if err != nil {
+		fmt.Fprintf(out, "ReadDirectory(/BDMV/STREAM) err: %v\n", err)
+		return
+	}
+	stFiles, err := st.GetFiles()
+	if err != nil {
+		fmt.Fprintf(out, "GetFiles(/BDMV/STREAM) err: %v\n", err)
+		return
+	}
+	fmt.Fprintf(out, "STREAM files (%d):\n", len(stFiles))
+	for i, f := range stFiles {
+		if i >= 10 {
+			break
+		}
+		fmt.Fprintf(out, "- %q size=%d\n", f.Name, f.Size())
+	}
+}
+
+func dumpUDFHeaders(out io.Writer, r *udf.Reader, dirPath string, label string, limit int, withSize bool) bool {
+	dir, err := r.ReadDirectory(dirPath)
+	if err != nil {
+		fmt.Fprintf(out, "ReadDirectory(%s) err: %v\n", dirPath, err)
+		return false
+	}
+	files, err := dir.GetFiles()
+	if err != nil {
+		fmt.Fprintf(out, "GetFiles(%s) err: %v\n", dirPath, err)
+		return false
+	}
+	fmt.Fprintf(out, "%s files (%d):\n", label, len(files))
+	for i, f := range files {
+		if limit > 0 && i >= limit {
+			break
+		}
+		rc, err := f.Open()
+		if err != nil {
+			fmt.Fprintf(out, "- %q open err: %v\n", f.Name, err)
+			continue
+		}
+		buf := make([]byte, 8)
+		n, rerr := rc.Read(buf)
+		_ = rc.Close()
+		if rerr != nil && n == 0 {
+			fmt.Fprintf(out, "- %q read err: %v\n", f.Name, rerr)
+			continue
+		}
+		if withSize {
+			fmt.Fprintf(out, "- %q head=%q size=%d\n", f.Name, buf[:n], f.Size())
+		} else {
+			fmt.Fprintf(out, "- %q head=%q\n", f.Name, buf[:n])
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseICBAddr(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		lbn     uint32
+		pref    uint16
+		wantErr bool
+	}{
+		{name: "lbn only", input: "32", lbn: 32, pref: 0},
+		{name: "with partition ref", input: "1:0", lbn: 0, pref: 1},
+		{name: "hex lbn", input: "0:0x20", lbn: 32, pref: 0},
+		{name: "empty", input: "", wantErr: true},
+		{name: "bad lbn", input: "0:abc", wantErr: true},
+		{name: "bad pref", input: "x:1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseICBAddr(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseICBAddr(%q) expected error", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseICBAddr(%q) error = %v", tt.input, err)
+			}
+			if got.ExtentLocation.LogicalBlockNumber != tt.lbn || got.ExtentLocation.PartitionReferenceNumber != tt.pref {
+				t.Fatalf("parseICBAddr(%q)=%d:%d want %d:%d", tt.input, got.ExtentLocation.PartitionReferenceNumber, got.ExtentLocation.LogicalBlockNumber, tt.pref, tt.lbn)
+			}
+		})
+	}
+}
+
+func TestDecodeTSPacketPES(t *testing.T) {
+	pkt := make([]byte, 192)
+	pkt[3] = 0x10 // ATS=16
+	h := pkt[4:]
+	h[0] = 0x47
+	h[1] = 0x50 // PUSI + PID high bits
+	h[2] = 0x11 // PID 0x1011
+	h[3] = 0x17 // payload only, cc=7
+	copy(h[4:], []byte{0x00, 0x00, 0x01, 0xE0, 0x00, 0x00, 0x80, 0x80, 0x05})
+	// PTS = 90000 (1s).
+	copy(h[13:], []byte{0x21, 0x00, 0x05, 0xBF, 0x21})
+
+	line, pid, ok := decodeTSPacket(pkt, 4)
+	if !ok {
+		t.Fatalf("decodeTSPacket lost sync")
+	}
+	if pid != 0x1011 {
+		t.Fatalf("pid got=%#x want=%#x", pid, 0x1011)
+	}
+	for _, want := range []string{"ats=16", "pusi=true", "cc=7", "sid=0xE0", "pts=90000 (1.000000s)"} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("decodeTSPacket line=%q missing %q", line, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type debugTSOptions struct {
+	pid    string
+	offset int64
+	length int64
+	count  int
+}
+
+var debugTSOpts debugTSOptions
+
+var debugTSCmd = &cobra.Command{
+	Use:   "ts <m2ts>",
+	Short: "Inspect TS packets of an M2TS file",
+	Long: "Inspect TS packets of an M2TS file.\n\n" +
+		"Prints decoded TS packet headers, adaptation field PCRs, PES headers and PTS/DTS timestamps " +
+		"for a PID and byte range. Useful when chasing parity differences with official BDInfo.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDebugTS(cmd.OutOrStdout(), args[0], debugTSOpts)
+	},
+}
+
+func init() {
+	debugTSCmd.Flags().StringVar(&debugTSOpts.pid, "pid", "", "Only print packets for this PID (decimal or 0x hex)")
+	debugTSCmd.Flags().Int64Var(&debugTSOpts.offset, "offset", 0, "Byte offset to start at (rounded down to a packet boundary)")
+	debugTSCmd.Flags().Int64Var(&debugTSOpts.length, "length", 0, "Number of bytes to inspect (0 = to end of file)")
+	debugTSCmd.Flags().IntVar(&debugTSOpts.count, "count", 1000, "Maximum number of packets to print, lost sync included (0 = unlimited)")
+
+	debugCmd.AddCommand(debugTSCmd)
+}
+
+func runDebugTS(out io.Writer, path string, opts debugTSOptions) error {
+	filterPID := -1
+	if strings.TrimSpace(opts.pid) != "" {
+		pid, err := strconv.ParseUint(strings.TrimSpace(opts.pid), 0, 13)
+		if err != nil {
+			return fmt.Errorf("invalid pid %q: %w", opts.pid, err)
+		}
+		filterPID = int(pid)
+	}
+	if opts.offset < 0 || opts.length < 0 {
+		return errors.New("offset and length must not be negative")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	first := make([]byte, 192)
+	if _, err := io.ReadFull(f, first); err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	packetSize := 192
+	syncOffset := 4
+	if first[0] == 0x47 {
+		packetSize = 188
+		syncOffset = 0
+	} else if first[4] != 0x47 {
+		return fmt.Errorf("invalid TS sync for %s", path)
+	}
+
+	start := opts.offset - (opts.offset % int64(packetSize))
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+	var src io.Reader = f
+	if opts.length > 0 {
+		src = io.LimitReader(f, opts.offset+opts.length-start)
+	}
+	rd := bufio.NewReaderSize(src, 1<<20)
+
+	fmt.Fprintf(out, "file=%q packetSize=%d start=%d\n", path, packetSize, start)
+	pkt := make([]byte, packetSize)
+	printed := 0
+	for index := start / int64(packetSize); ; index++ {
+		if _, err := io.ReadFull(rd, pkt); err != nil {
+			break
+		}
+		offset := index * int64(packetSize)
+		line, pid, ok := decodeTSPacket(pkt, syncOffset)
+		switch {
+		case !ok && filterPID >= 0:
+			// Without a sync byte the PID cannot be trusted.
+			continue
+		case !ok:
+			line = "lost sync"
+		case filterPID >= 0 && int(pid) != filterPID:
+			continue
+		}
+		fmt.Fprintf(out, "0x%010X #%d %s\n", offset, index, line)
+		printed++
+		if opts.count > 0 && printed >= opts.count {
+			break
+		}
+	}
+	return nil
+}
+
+// decodeTSPacket renders the TS header, adaptation PCR and PES header (if any) of one packet.
+func decodeTSPacket(pkt []byte, syncOffset int) (string, uint16, bool) {
+	if len(pkt) < syncOffset+4 || pkt[syncOffset] != 0x47 {
+		return "", 0, false
+	}
+	var b strings.Builder
+	if syncOffset == 4 {
+		ats := uint32(pkt[0]&0x3F)<<24 | uint32(pkt[1])<<16 | uint32(pkt[2])<<8 | uint32(pkt[3])
+		fmt.Fprintf(&b, "ats=%d ", ats)
+	}
+	h := pkt[syncOffset:]
+	tei := h[1]&0x80 != 0
+	pusi := h[1]&0x40 != 0
+	pid := uint16(h[1]&0x1F)<<8 | uint16(h[2])
+	scrambling := (h[3] >> 6) & 0x3
+	afc := (h[3] >> 4) & 0x3
+	cc := h[3] & 0x0F
+	fmt.Fprintf(&b, "pid=%d (0x%04X) pusi=%t afc=%d cc=%d", pid, pid, pusi, afc, cc)
+	if tei {
+		b.WriteString(" tei=true")
+	}
+	if scrambling != 0 {
+		fmt.Fprintf(&b, " scrambling=%d", scrambling)
+	}
+
+	idx := 4
+	if afc == 2 || afc == 3 {
+		if idx >= len(h) {
+			return b.String(), pid, true
+		}
+		adapLen := int(h[idx])
+		if adapLen > 0 && idx+1 < len(h) {
+			flags := h[idx+1]
+			if flags&0x80 != 0 {
+				b.WriteString(" discontinuity=true")
+			}
+			if flags&0x40 != 0 {
+				b.WriteString(" rai=true")
+			}
+			if flags&0x10 != 0 && adapLen >= 7 && idx+8 <= len(h) {
+				p := h[idx+2 : idx+8]
+				base := uint64(p[0])<<25 | uint64(p[1])<<17 | uint64(p[2])<<9 | uint64(p[3])<<1 | uint64(p[4])>>7
+				ext := uint64(p[4]&0x01)<<8 | uint64(p[5])
+				pcr := base*300 + ext
+				fmt.Fprintf(&b, " pcr=%d (%.6fs)", pcr, float64(pcr)/27000000.0)
+			}
+		}
+		idx += 1 + adapLen
+	}
+	if afc == 0 || afc == 2 || idx >= len(h) {
+		return b.String(), pid, true
+	}
+	payload := h[idx:]
+	fmt.Fprintf(&b, " payload=%d", len(payload))
+	if pusi && len(payload) >= 9 && payload[0] == 0x00 && payload[1] == 0x00 && payload[2] == 0x01 {
+		streamID := payload[3]
+		pesLen := int(payload[4])<<8 | int(payload[5])
+		fmt.Fprintf(&b, " pes: sid=0x%02X len=%d", streamID, pesLen)
+		flags := (payload[7] >> 6) & 0x03
+		hdrLen := int(payload[8])
+		fmt.Fprintf(&b, " hdr=%d", hdrLen)
+		if (flags == 2 || flags == 3) && len(payload) >= 14 {
+			pts := decodePESTimestamp(payload[9:14])
+			fmt.Fprintf(&b, " pts=%d (%.6fs)", pts, float64(pts)/90000.0)
+		}
+		if flags == 3 && len(payload) >= 19 {
+			dts := decodePESTimestamp(payload[14:19])
+			fmt.Fprintf(&b, " dts=%d (%.6fs)", dts, float64(dts)/90000.0)
+		}
+	}
+	return b.String(), pid, true
+}
+
+func decodePESTimestamp(data []byte) uint64 {
+	if len(data) < 5 {
+		return 0
+	}
+	ts := uint64(data[0]&0x0E) << 29
+	ts |= uint64(data[1]) << 22
+	ts |= uint64(data[2]&0xFE) << 14
+	ts |= uint64(data[3]) << 7
+	ts |= uint64(data[4]) >> 1
+	return ts
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDebugTS_LostSync(t *testing.T) {
+	packet := func(pid uint16) []byte {
+		pkt := make([]byte, 192)
+		if pid != 0 {
+			pkt[4], pkt[5], pkt[6], pkt[7] = 0x47, byte(pid>>8)&0x1F, byte(pid), 0x10
+		}
+		return pkt
+	}
+	var data []byte
+	for _, pid := range []uint16{0x1011, 0, 0x1100, 0, 0x1011} {
+		data = append(data, packet(pid)...)
+	}
+	path := filepath.Join(t.TempDir(), "00001.m2ts")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts debugTSOptions
+		want []string // packet index and start of each line
+	}{
+		{name: "all", want: []string{"#0 ats=0 pid=4113", "#1 lost sync", "#2 ats=0 pid=4352", "#3 lost sync", "#4 ats=0 pid=4113"}},
+		{name: "pid filter", opts: debugTSOptions{pid: "0x1011"}, want: []string{"#0 ats=0 pid=4113", "#4 ats=0 pid=4113"}},
+		{name: "count", opts: debugTSOptions{count: 2}, want: []string{"#0 ats=0 pid=4113", "#1 lost sync"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := runDebugTS(&b, path, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")[1:]
+			if len(lines) != len(tt.want) {
+				t.Fatalf("lines got=%q want=%q", lines, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], want) {
+					t.Fatalf("line %d got=%q want %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func TestApplyEnv(t *testing.T) {
+	var (
+		mbps    float64
+		main    bool
+		delay   time.Duration
+		report  string
+		mapping []string
+	)
+	flags := pflag.NewFlagSet("bdinfo", pflag.ContinueOnError)
+	flags.Float64Var(&mbps, "max-read-mbps", 0, "")
+	flags.BoolVar(&main, "main", false, "")
+	flags.DurationVar(&delay, "io-retry-delay", time.Second, "")
+	flags.StringVarP(&report, "reportfilename", "o", "", "")
+	flags.StringSliceVar(&mapping, "path-map", nil, "")
+	if err := flags.Parse([]string{"-o", "cli.txt"}); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"BDINFO_MAX_READ_MBPS":  "200",
+		"BDINFO_MAIN":           "true",
+		"BDINFO_IO_RETRY_DELAY": "250ms",
+		"BDINFO_REPORTFILENAME": "env.txt",
+		"BDINFO_PATH_MAP":       "/mnt/media:/media,/srv/out:/out",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	if err := applyEnv(flags, lookup); err != nil {
+		t.Fatal(err)
+	}
+	if mbps != 200 || !main || delay != 250*time.Millisecond || len(mapping) != 2 {
+		t.Fatalf("env not applied: mbps=%v main=%v delay=%v map=%v", mbps, main, delay, mapping)
+	}
+	if report != "cli.txt" {
+		t.Fatalf("command line should win over env: report=%q", report)
+	}
+	if !flags.Changed("main") {
+		t.Fatal("env-set flag should count as changed")
+	}
+
+	env = map[string]string{"BDINFO_MAIN": "maybe"}
+	flags.Lookup("main").Changed = false
+	if err := applyEnv(flags, lookup); err == nil {
+		t.Fatal("invalid env value should fail")
+	}
+}
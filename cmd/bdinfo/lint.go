@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/go-bdinfo/internal/report"
+)
+
+var lintFormat string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <report>",
+	Short: "Check a text report against tracker BDInfo validators",
+	Long: "Check a text report's layout (section order, column widths, required lines, paste markers) " +
+		"against what tracker BDInfo validators parse, before pasting it. Use - to read the report from stdin.\n\n" +
+		"Exits non-zero when an error is found; warnings alone do not fail.",
+	Args: cobra.ExactArgs(1),
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format: text, json")
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(strings.TrimSpace(lintFormat))
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format %q (supported: text, json)", lintFormat)
+	}
+
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	issues := report.Lint(string(data))
+	out := cmd.OutOrStdout()
+	if format == "json" {
+		if issues == nil {
+			issues = []report.LintIssue{}
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Fprintln(out, issue)
+		}
+	}
+
+	failed := 0
+	for _, issue := range issues {
+		if issue.Severity == report.LintError {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s: %d error(s)", args[0], failed)
+	}
+	if format == "text" && len(issues) == 0 {
+		fmt.Fprintf(out, "%s: OK\n", args[0])
+	}
+	return nil
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -14,8 +19,10 @@ import (
 	"github.com/blang/semver"
 	"github.com/creativeprojects/go-selfupdate"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/fs"
 	"github.com/autobrr/go-bdinfo/internal/settings"
 	"github.com/autobrr/go-bdinfo/internal/util"
 	bdinfo "github.com/autobrr/go-bdinfo/pkg/bdinfo"
@@ -27,31 +34,90 @@ type rootOptions struct {
 	path             string
 	pathFlag         string
 	playlist         string
+	title            int
 	reportPath       string
 	reportFile       string
 	filterShortValue int
 	genDiag          bool
 	extDiag          bool
+	frameData        bool
 	enableSSIF       bool
 	filterLooping    bool
 	filterShort      bool
 	keepOrder        bool
 	genSummary       bool
 	includeNotes     bool
+	notesHome        bool
+	notesForums      bool
+	productVersion   string
+	generatedBy      bool
+	reportLanguage   string
+	reportLabels     string
+	timeFormat       string
+	roundingMode     string
+	playlistSort     string
 	groupByTime      bool
 	forumsOnly       bool
 	mainOnly         bool
+	topPlaylists     int
 	bigPlaylistOnly  bool
 	summaryOnly      bool
 	stdout           bool
 	printToConsole   bool
 	selfUpdate       bool
 	progress         bool
+	jsonl            bool
+	events           bool
+	tee              bool
+	oneline          bool
+	ffprobe          bool
+	trace            string
+	traceFile        string
+	tempDir          string
+	noTempFiles      bool
+	readOnly         bool
+	playlistIndex    bool
+	jarImagesDir     string
+	titleMap         bool
+	restrictions     bool
+	offsets3D        bool
+	bitrates3D       bool
+	sparklines       bool
+	chapterNames     bool
+	protection       bool
+	collapseDups     bool
+	wideColumns      bool
+	emptySections    bool
+	splitOverlaps    bool
+	unknownPIDs      bool
+	subtitleSummary  bool
+	quick            bool
+	fullScan         bool
+	lowMemory        bool
+	ioRetries        int
+	ioRetryDelay     time.Duration
+	maxReadMbps      float64
+	pathMap          []string
+	workers          int
+	ioMode           string
+	saveScan         string
+	cacheDir         string
+	maxOpenFiles     int
+	maxRSS           int
+	batchResume      string
+	chaptersOut      string
+	bitrateGraph     string
+	ocrCmd           string
+	ocrOut           string
+	subtitleTiming   string
+	ocrAll           bool
+	configPath       string
+	saveConfig       bool
+	bdinfoSettings   string
 
 	// Compatibility-only flags (accepted, currently no-op).
 	displayChapterCount bool
 	autoSaveReport      bool
-	generateFrameData   bool
 	useImagePrefix      bool
 	imagePrefixValue    string
 	isExecutedAsScript  bool
@@ -111,37 +177,107 @@ func init() {
 	// Official BDInfo compatibility: path as required flag. Positional arg still supported.
 	rootCmd.Flags().StringVarP(&opts.pathFlag, "path", "p", "", "Required. The path to iso or bluray folder")
 	rootCmd.Flags().StringVar(&opts.playlist, "playlist", "", "Process only the selected playlist (e.g. 00000.mpls)")
+	rootCmd.Flags().IntVar(&opts.title, "title", 0, "Process only the playlists disc title N plays (from index.bdmv; see --titles)")
 	rootCmd.Flags().StringVarP(&opts.reportPath, "reportpath", "r", "", "The folder where report will be saved (compat)")
-	rootCmd.Flags().StringVarP(&opts.reportFile, "reportfilename", "o", "", "The report filename with extension (use - for stdout)")
-	rootCmd.Flags().BoolVar(&opts.stdout, "stdout", false, "Write report to stdout")
-	rootCmd.Flags().BoolVarP(&opts.genDiag, "generatestreamdiagnostics", "g", false, "Generate the stream diagnostics section")
-	rootCmd.Flags().BoolVarP(&opts.extDiag, "extendedstreamdiagnostics", "e", false, "Enable extended video diagnostics (HEVC metadata)")
 	rootCmd.Flags().BoolVarP(&opts.enableSSIF, "enablessif", "b", false, "Enable SSIF support (default on; use --enablessif=false to disable)")
 	rootCmd.Flags().BoolVarP(&opts.displayChapterCount, "displaychaptercount", "c", false, "Enable chapter count (compat)")
 	rootCmd.Flags().BoolVarP(&opts.autoSaveReport, "autosavereport", "a", false, "Auto save report (compat)")
 	// No short flag: `-f` is already used by `--forumsonly` in this CLI.
-	rootCmd.Flags().BoolVar(&opts.generateFrameData, "generateframedatafile", false, "Generate frame data file (compat)")
+	rootCmd.Flags().BoolVar(&opts.frameData, "generateframedatafile", false, "Also write the type, size and time of each video frame of the reported playlists next to the report as <report>.<playlist>.FrameData.txt")
 	rootCmd.Flags().BoolVarP(&opts.filterLooping, "filterloopingplaylists", "l", false, "Filter looping playlists")
 	rootCmd.Flags().BoolVarP(&opts.filterShort, "filtershortplaylist", "y", false, "Filter short playlists (default on; use --filtershortplaylist=false to disable)")
+	rootCmd.Flags().BoolVar(&opts.unknownPIDs, "unknown-pids", false, "Count packets of PIDs missing from the clip info and list the significant ones")
+	rootCmd.Flags().BoolVar(&opts.subtitleSummary, "subtitle-summary", false, "Count PGS subtitle captions, mark forced-only tracks and add a SUBTITLE SUMMARY section grouped by language")
+	rootCmd.Flags().BoolVar(&opts.quick, "quick", false, "Read only the first 8 MiB of each stream file: codecs and stream list with nominal bitrates, for cataloging")
+	rootCmd.Flags().BoolVar(&opts.fullScan, "full-scan", false, "Read every stream file a second time, like official BDInfo, so bitrates and chapter peak stats come from a full pass")
+	rootCmd.Flags().BoolVar(&opts.lowMemory, "low-memory", false, "Scan with small reads, one worker and small HEVC tag buffers, for NAS boxes and Raspberry Pi devices")
+	rootCmd.Flags().BoolVar(&opts.splitOverlaps, "split-overlaps", false, "Share packets between overlapping play items of a playlist instead of counting them in each (differs from official BDInfo)")
 	rootCmd.Flags().IntVarP(&opts.filterShortValue, "filtershortplaylistvalue", "v", 20, "Short playlist length threshold in seconds")
 	rootCmd.Flags().BoolVarP(&opts.useImagePrefix, "useimageprefix", "i", false, "Use image prefix (compat)")
 	rootCmd.Flags().StringVarP(&opts.imagePrefixValue, "useimageprefixvalue", "x", "video-", "Image prefix (compat)")
-	rootCmd.Flags().BoolVarP(&opts.keepOrder, "keepstreamorder", "k", false, "Keep stream order")
-	rootCmd.Flags().BoolVarP(&opts.genSummary, "generatetextsummary", "m", false, "Generate quick summary block (default on; use --generatetextsummary=false to disable)")
-	rootCmd.Flags().BoolVarP(&opts.includeNotes, "includeversionandnotes", "q", false, "Include version and scan notes (default on; use --includeversionandnotes=false to disable)")
-	rootCmd.Flags().BoolVarP(&opts.groupByTime, "groupbytime", "j", false, "Group by time")
-	rootCmd.Flags().BoolVarP(&opts.forumsOnly, "forumsonly", "f", false, "Output only the forums paste block")
-	rootCmd.Flags().BoolVar(&opts.mainOnly, "main", false, "Output only the main playlist (likely what you want)")
-	rootCmd.Flags().BoolVarP(&opts.bigPlaylistOnly, "printonlybigplaylist", "z", false, "Print report with only biggest playlist (compat)")
 	rootCmd.Flags().BoolVarP(&opts.printToConsole, "printtoconsole", "w", false, "Print report to console (compat)")
-	rootCmd.Flags().BoolVarP(&opts.summaryOnly, "summaryonly", "s", false, "Output only the quick summary block (likely what you want)")
 	rootCmd.Flags().BoolVarP(&opts.isExecutedAsScript, "isexecutedasscript", "d", false, "Check if is executed as script (compat)")
 	rootCmd.Flags().BoolVar(&opts.selfUpdate, "self-update", false, "Update bdinfo to latest version (release builds only)")
 	rootCmd.Flags().BoolVar(&opts.selfUpdate, "update", false, "Update bdinfo to latest version (release builds only)")
 	rootCmd.Flags().BoolVar(&opts.progress, "progress", false, "Print scan progress to stderr")
+	rootCmd.Flags().BoolVar(&opts.jsonl, "jsonl", false, "Write one JSON result per disc per line (NDJSON) to stdout instead of text reports")
+	rootCmd.Flags().BoolVar(&opts.tee, "tee", false, "Write the report to stdout as well as to its file")
+	rootCmd.Flags().BoolVar(&opts.events, "events", false, "Print a JSON line to stderr after each disc listing the files written (path, format, bytes)")
+	rootCmd.Flags().BoolVar(&opts.oneline, "oneline", false, "Write one summary line per disc (label | length | size | video | audio | subtitles) to stdout instead of text reports")
+	rootCmd.Flags().BoolVar(&opts.ffprobe, "ffprobe", false, "Write the main playlist of each disc as ffprobe-style JSON (streams and format, one line per disc) to stdout instead of text reports")
+	rootCmd.Flags().StringVar(&opts.trace, "trace", "", "Write a machine-readable audit trace (supported: bitrate)")
+	rootCmd.Flags().StringVar(&opts.traceFile, "tracefile", "", "Trace output file (default: BDInfo_<trace>-trace.jsonl)")
+	rootCmd.Flags().StringVar(&opts.tempDir, "tempdir", "", "Directory for temporary files (default: OS temp dir)")
+	rootCmd.Flags().BoolVar(&opts.noTempFiles, "notempfiles", false, "Never write anything outside the report path (no temp files, no traces)")
+	rootCmd.Flags().StringVar(&opts.jarImagesDir, "extractjarimages", "", "Extract JPEG/PNG images embedded in BD-J JARs into this directory and list them in the report")
+	rootCmd.Flags().StringVar(&opts.chaptersOut, "chapters-out", "", "Write the main (or --playlist) playlist's chapters to this file ({0} = disc label): Matroska XML for .xml, OGM text otherwise")
+	rootCmd.Flags().StringVar(&opts.ocrCmd, "ocr-cmd", "", "Run this command on each forced PGS subtitle image (PNG on stdin) and take its stdout as the text; needs --ocr-out")
+	rootCmd.Flags().StringVar(&opts.bitrateGraph, "bitrate-graph", "", "Write the per-second bitrate of the main (or --playlist) playlist's video and audio streams to this CSV file, or JSON for a .json name ({0} = disc label, {1} = playlist, one file per playlist)")
+	rootCmd.Flags().StringVar(&opts.subtitleTiming, "subtitle-timing-out", "", "Write when each PGS caption of the main (or --playlist) playlist is shown to this file, as CSV when it ends in .csv and JSON otherwise ({0} = disc label)")
+	rootCmd.Flags().StringVar(&opts.ocrOut, "ocr-out", "", "Write the main (or --playlist) playlist's subtitles read by --ocr-cmd as SRT files ({0} = disc label, {1} = track, e.g. 4608-eng)")
+	rootCmd.Flags().BoolVar(&opts.ocrAll, "ocr-all", false, "Run --ocr-cmd on every PGS subtitle image, not only forced ones")
+	rootCmd.Flags().StringVar(&opts.saveScan, "save-scan", "", "Save the completed scan to this file ({0} = disc label) to re-render later with: bdinfo render <file>")
+	rootCmd.Flags().StringVar(&opts.cacheDir, "cache-dir", "", "Keep each stream file's scan results in this directory so rescanning the disc (other report flags, interrupted runs) skips files already read")
+	rootCmd.Flags().IntVar(&opts.workers, "workers", 0, "Scan worker count (0 = automatic; env BDINFO_WORKERS)")
+	rootCmd.Flags().StringVar(&opts.ioMode, "io-mode", "auto", "Scan parallelism when --workers is 0: auto, sequential (one worker), parallel (most workers, for fast storage)")
+	rootCmd.Flags().IntVar(&opts.ioRetries, "io-retries", 0, "Retry failed file opens and reads this many times (for flaky network storage)")
+	rootCmd.Flags().DurationVar(&opts.ioRetryDelay, "io-retry-delay", time.Second, "Wait before the first I/O retry; doubles after each further failure")
+	rootCmd.Flags().Float64Var(&opts.maxReadMbps, "max-read-mbps", 0, "Limit disc reads to this many megabits per second across all workers (0 = unlimited)")
+	rootCmd.Flags().IntVar(&opts.maxOpenFiles, "max-open-files", 0, "Keep at most this many disc files open at once, for low file descriptor limits (0 = unlimited)")
+	rootCmd.Flags().IntVar(&opts.maxRSS, "max-rss", 0, "In batch mode, restart bdinfo for the remaining discs once its resident memory exceeds this many MiB after a disc (0 = no limit)")
+	rootCmd.Flags().StringVar(&opts.batchResume, "batch-resume", "", "Batch state handed to the process restarted by --max-rss")
+	_ = rootCmd.Flags().MarkHidden("batch-resume")
+	rootCmd.Flags().StringSliceVar(&opts.pathMap, "path-map", nil, "Translate container paths to host paths in outputs (host:container, repeatable)")
+	rootCmd.Flags().BoolVarP(&opts.extDiag, "extendedstreamdiagnostics", "e", false, "Enable extended video diagnostics (HEVC, AVC and MVC metadata)")
+	addReportFlags(rootCmd.Flags())
+	rootCmd.Flags().BoolVar(&opts.readOnly, "read-only", false, "Fail if the report or trace would be written inside the scanned disc path")
+	rootCmd.Flags().BoolVar(&opts.playlistIndex, "playlist-index", false, "Also write a JSON index of the playlists (names, lengths, sizes, main) next to the report as <report>.index.json")
+	rootCmd.Flags().StringVar(&opts.configPath, "config", "", "Config file with default flag values (default: ~/.config/bdinfo/config.toml)")
+	rootCmd.Flags().BoolVar(&opts.saveConfig, "save-config", false, "Write the flags set on the command line, environment or config file to the config file and exit")
+	rootCmd.Flags().StringVar(&opts.bdinfoSettings, "bdinfo-settings", "", "Official BDInfo user.config to import settings from (default: detected on Windows; none to skip)")
 
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(debugCmd)
+	rootCmd.AddCommand(renderCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(queryCmd)
+}
+
+// addReportFlags registers the flags that shape a report, shared by the scan and
+// render commands.
+func addReportFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&opts.reportFile, "reportfilename", "o", "", "The report filename with extension (use - for stdout)")
+	flags.BoolVar(&opts.stdout, "stdout", false, "Write report to stdout")
+	flags.BoolVarP(&opts.genDiag, "generatestreamdiagnostics", "g", false, "Generate the stream diagnostics section")
+	flags.BoolVarP(&opts.keepOrder, "keepstreamorder", "k", false, "Keep stream order")
+	flags.BoolVarP(&opts.genSummary, "generatetextsummary", "m", false, "Generate quick summary block (default on; use --generatetextsummary=false to disable)")
+	flags.BoolVarP(&opts.includeNotes, "includeversionandnotes", "q", false, "Include version and scan notes (default on; use --includeversionandnotes=false to disable)")
+	flags.BoolVar(&opts.notesHome, "includenoteshome", false, "Include the BDINFO HOME links in the notes block (default on; use --includenoteshome=false to disable)")
+	flags.BoolVar(&opts.notesForums, "includenotesforums", false, "Include the forums report links in the notes block (default on; use --includenotesforums=false to disable)")
+	flags.StringVar(&opts.productVersion, "productversion", "", "Override the BDInfo version printed in the report")
+	flags.BoolVar(&opts.generatedBy, "generated-by", false, "Append a \"Generated by:\" footer naming the go-bdinfo version that made the report")
+	flags.StringVar(&opts.reportLanguage, "reportlanguage", "en", "Language for report labels outside the forums paste (en, de, fr)")
+	flags.StringVar(&opts.reportLabels, "reportlabels", "", "JSON file mapping English report labels to translations (overrides --reportlanguage)")
+	flags.StringVar(&opts.timeFormat, "timeformat", "hms", "Time format for CHAPTERS/FILES: hms (h:mm:ss.mmm), smpte (h:mm:ss:ff), seconds")
+	flags.StringVar(&opts.roundingMode, "rounding", "official", "Rounding of kbps/Mbps figures: official (half to even, as BDInfo), mathematical (half away from zero)")
+	flags.StringVar(&opts.playlistSort, "sort-playlists", "size", "Report playlist order: size (default), length, name, bitrate")
+	flags.BoolVarP(&opts.groupByTime, "groupbytime", "j", false, "Group by time")
+	flags.BoolVarP(&opts.forumsOnly, "forumsonly", "f", false, "Output only the forums paste block")
+	flags.BoolVar(&opts.mainOnly, "main", false, "Output only the main playlist (likely what you want)")
+	flags.IntVar(&opts.topPlaylists, "top", 0, "Output only the N largest/longest playlists (ranked like --main)")
+	flags.BoolVarP(&opts.bigPlaylistOnly, "printonlybigplaylist", "z", false, "Print report with only biggest playlist (compat)")
+	flags.BoolVarP(&opts.summaryOnly, "summaryonly", "s", false, "Output only the quick summary block (likely what you want)")
+	flags.BoolVar(&opts.titleMap, "titles", false, "Include a TITLES section mapping index.bdmv titles to playlists")
+	flags.BoolVar(&opts.restrictions, "restrictions", false, "Include a PLAYBACK RESTRICTIONS section per playlist (UO mask table, random access, still modes)")
+	flags.BoolVar(&opts.chapterNames, "chapter-names", false, "Include a CHAPTER NAMES section per playlist when the disc names its chapters (BDMV/META/TN)")
+	flags.BoolVar(&opts.protection, "protection-details", false, "Add the AACS MKB version and BD+ content code date to the Protection line (differs from official BDInfo)")
+	flags.BoolVar(&opts.collapseDups, "collapse-duplicates", false, "Report one playlist per group of duplicates (same clips, any order) and list the groups in a DUPLICATE PLAYLISTS section")
+	flags.BoolVar(&opts.wideColumns, "wide-columns", false, "Widen stream table columns to fit long codec names instead of BDInfo's fixed widths (differs from official BDInfo)")
+	flags.BoolVar(&opts.emptySections, "empty-sections", false, "Keep VIDEO/AUDIO/SUBTITLES tables for playlists without such streams and mark empty tables with a placeholder row")
+	flags.BoolVar(&opts.offsets3D, "3d-offsets", false, "Include a 3D GRAPHICS OFFSETS section per playlist (offset sequences used by 3D subtitles)")
+	flags.BoolVar(&opts.sparklines, "bitrate-sparklines", false, "Include a BITRATE GRAPH section per playlist (ASCII sparkline of each video stream's bitrate over time; audio too with --bitrate-graph)")
+	flags.BoolVar(&opts.bitrates3D, "3d-bitrates", false, "Include a 3D BITRATES (SSIF) section per 3D playlist (per-eye and combined MVC video bitrate)")
 }
 
 func main() {
@@ -170,19 +306,48 @@ func normalizeArgs(args []string) []string {
 		"-g": "--generatestreamdiagnostics", "--generatestreamdiagnostics": "--generatestreamdiagnostics",
 		"-e": "--extendedstreamdiagnostics", "--extendedstreamdiagnostics": "--extendedstreamdiagnostics",
 		"-b": "--enablessif", "--enablessif": "--enablessif",
-		"-l": "--filterloopingplaylists", "--filterloopingplaylists": "--filterloopingplaylists",
+		"--generateframedatafile": "--generateframedatafile",
+		"-l":                      "--filterloopingplaylists", "--filterloopingplaylists": "--filterloopingplaylists",
 		"-y": "--filtershortplaylist", "--filtershortplaylist": "--filtershortplaylist",
 		"-k": "--keepstreamorder", "--keepstreamorder": "--keepstreamorder",
 		"-m": "--generatetextsummary", "--generatetextsummary": "--generatetextsummary",
 		"-q": "--includeversionandnotes", "--includeversionandnotes": "--includeversionandnotes",
+		"--includenoteshome": "--includenoteshome", "--includenotesforums": "--includenotesforums",
 		"-j": "--groupbytime", "--groupbytime": "--groupbytime",
 		"-f": "--forumsonly", "--forumsonly": "--forumsonly",
 		"-w": "--printtoconsole", "--printtoconsole": "--printtoconsole",
 		"-z": "--printonlybigplaylist", "--printonlybigplaylist": "--printonlybigplaylist",
 		"--main": "--main",
 		"-s":     "--summaryonly", "--summaryonly": "--summaryonly",
-		"--stdout":   "--stdout",
-		"--progress": "--progress",
+		"--stdout":              "--stdout",
+		"--progress":            "--progress",
+		"--jsonl":               "--jsonl",
+		"--events":              "--events",
+		"--tee":                 "--tee",
+		"--ocr-all":             "--ocr-all",
+		"--oneline":             "--oneline",
+		"--ffprobe":             "--ffprobe",
+		"--notempfiles":         "--notempfiles",
+		"--read-only":           "--read-only",
+		"--playlist-index":      "--playlist-index",
+		"--titles":              "--titles",
+		"--restrictions":        "--restrictions",
+		"--3d-offsets":          "--3d-offsets",
+		"--3d-bitrates":         "--3d-bitrates",
+		"--bitrate-sparklines":  "--bitrate-sparklines",
+		"--chapter-names":       "--chapter-names",
+		"--protection-details":  "--protection-details",
+		"--collapse-duplicates": "--collapse-duplicates",
+		"--wide-columns":        "--wide-columns",
+		"--empty-sections":      "--empty-sections",
+		"--split-overlaps":      "--split-overlaps",
+		"--unknown-pids":        "--unknown-pids",
+		"--subtitle-summary":    "--subtitle-summary",
+		"--quick":               "--quick",
+		"--full-scan":           "--full-scan",
+		"--low-memory":          "--low-memory",
+		"--generated-by":        "--generated-by",
+		"--save-config":         "--save-config",
 	}
 
 	out := make([]string, 0, len(args))
@@ -222,6 +387,11 @@ func filterROMToPlaylist(rom *bdrom.BDROM, playlistName string) error {
 	}
 
 	pl, ok := rom.PlaylistFiles[normalized]
+	if !ok && rom.IsBDAV && filepath.Ext(normalized) == ".MPLS" {
+		// BDAV recorder discs name their playlists NNNNN.RPLS.
+		normalized = strings.TrimSuffix(normalized, ".MPLS") + ".RPLS"
+		pl, ok = rom.PlaylistFiles[normalized]
+	}
 	if !ok {
 		return fmt.Errorf("playlist not found: %s", normalized)
 	}
@@ -232,6 +402,23 @@ func filterROMToPlaylist(rom *bdrom.BDROM, playlistName string) error {
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
+	if err := applyEnv(cmd.Flags(), os.LookupEnv); err != nil {
+		return err
+	}
+	if err := applyConfigFile(cmd, opts.configPath); err != nil {
+		return err
+	}
+	if err := applyOfficialSettings(cmd.Flags(), opts.bdinfoSettings); err != nil {
+		return err
+	}
+	if opts.saveConfig {
+		path, err := saveConfigFile(cmd.Flags(), opts.configPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Config written: %s\n", path)
+		return nil
+	}
 	if opts.selfUpdate {
 		return runSelfUpdate(cmd.Context())
 	}
@@ -249,16 +436,20 @@ func runRoot(cmd *cobra.Command, args []string) error {
 		return errors.New("path is required")
 	}
 
-	cwd, _ := os.Getwd()
-	s := settings.Default(cwd)
+	// Defaults are relative to the working directory at write time, so a
+	// container without a resolvable cwd still gets usable settings.
+	s := settings.Default(".")
 
 	flags := cmd.Flags()
-	if flags.Changed("generatestreamdiagnostics") {
-		s.GenerateStreamDiagnostics = opts.genDiag
+	if err := applyReportFlags(flags, &s); err != nil {
+		return err
 	}
 	if flags.Changed("extendedstreamdiagnostics") {
 		s.ExtendedStreamDiagnostics = opts.extDiag
 	}
+	if flags.Changed("generateframedatafile") {
+		s.GenerateFrameDataFile = opts.frameData
+	}
 	if flags.Changed("enablessif") {
 		s.EnableSSIF = opts.enableSSIF
 	}
@@ -268,19 +459,31 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	if flags.Changed("filtershortplaylist") {
 		s.FilterShortPlaylists = opts.filterShort
 	}
-	s.FilterShortPlaylistsVal = opts.filterShortValue
-	if flags.Changed("keepstreamorder") {
-		s.KeepStreamOrder = opts.keepOrder
+	if flags.Changed("split-overlaps") {
+		s.SplitOverlappingClips = opts.splitOverlaps
 	}
-	if flags.Changed("generatetextsummary") {
-		s.GenerateTextSummary = opts.genSummary
+	if flags.Changed("unknown-pids") {
+		s.TrackUnknownPIDs = opts.unknownPIDs
 	}
-	if opts.reportFile != "" {
-		s.ReportFileName = opts.reportFile
+	if flags.Changed("subtitle-summary") {
+		s.SubtitleSummary = opts.subtitleSummary
 	}
-	if opts.stdout {
-		s.ReportFileName = "-"
+	if flags.Changed("quick") {
+		s.QuickScan = opts.quick
+	}
+	if flags.Changed("full-scan") {
+		s.FullScan = opts.fullScan
+	}
+	if s.QuickScan && s.FullScan {
+		return errors.New("--quick and --full-scan cannot be combined")
+	}
+	if s.QuickScan && s.GenerateFrameDataFile {
+		return errors.New("--quick and --generateframedatafile cannot be combined")
+	}
+	if flags.Changed("low-memory") {
+		s.LowMemory = opts.lowMemory
 	}
+	s.FilterShortPlaylistsVal = opts.filterShortValue
 	if flags.Changed("printtoconsole") && opts.printToConsole {
 		s.ReportFileName = "-"
 	}
@@ -290,27 +493,299 @@ func runRoot(cmd *cobra.Command, args []string) error {
 			s.ReportFileName = filepath.Join(opts.reportPath, filepath.Base(s.ReportFileName))
 		}
 	}
+	if flags.Changed("playlist") {
+		s.PlaylistOnly = normalizePlaylistName(opts.playlist)
+	}
+	if flags.Changed("title") {
+		if opts.title < 1 {
+			return errors.New("--title must be at least 1")
+		}
+		s.TitleOnly = opts.title
+	}
+	if s.PlaylistOnly != "" && s.TitleOnly != 0 {
+		return errors.New("--title cannot be combined with --playlist")
+	}
+	if s.PlaylistOnly != "" {
+		s.MainPlaylistOnly = false
+		s.BigPlaylistOnly = false
+		s.TopPlaylists = 0
+	}
+
+	if flags.Changed("tempdir") {
+		s.TempDir = opts.tempDir
+	}
+	if flags.Changed("notempfiles") {
+		s.NoTempFiles = opts.noTempFiles
+	}
+	if flags.Changed("workers") {
+		if opts.workers < 0 {
+			return errors.New("--workers must not be negative")
+		}
+		s.Workers = opts.workers
+	}
+	if flags.Changed("io-mode") {
+		if !bdrom.ValidIOMode(opts.ioMode) {
+			return fmt.Errorf("unsupported --io-mode %q (supported: auto, sequential, parallel)", opts.ioMode)
+		}
+		s.IOMode = opts.ioMode
+	}
+	if flags.Changed("io-retries") {
+		if opts.ioRetries < 0 {
+			return errors.New("--io-retries must not be negative")
+		}
+		s.IORetries = opts.ioRetries
+	}
+	if flags.Changed("io-retry-delay") {
+		if opts.ioRetryDelay < 0 {
+			return errors.New("--io-retry-delay must not be negative")
+		}
+		s.IORetryDelay = opts.ioRetryDelay
+	}
+	if flags.Changed("max-read-mbps") {
+		if opts.maxReadMbps < 0 {
+			return errors.New("--max-read-mbps must not be negative")
+		}
+		s.MaxReadMbps = opts.maxReadMbps
+	}
+	if flags.Changed("max-open-files") {
+		if opts.maxOpenFiles < 0 {
+			return errors.New("--max-open-files must not be negative")
+		}
+		s.MaxOpenFiles = opts.maxOpenFiles
+	}
+
+	run := runOptions{progress: opts.progress, batchResume: opts.batchResume, restart: restartSelf}
+	if flags.Changed("max-rss") {
+		if opts.maxRSS < 0 {
+			return errors.New("--max-rss must not be negative")
+		}
+		if opts.maxRSS > 0 && s.NoTempFiles {
+			return errors.New("--max-rss hands the batch to the restarted process in a temp file and cannot be combined with --notempfiles")
+		}
+		if opts.maxRSS > 0 && opts.trace != "" {
+			return errors.New("--max-rss cannot be combined with --trace (a restart would truncate the trace)")
+		}
+		run.maxRSS = uint64(opts.maxRSS) << 20
+	}
+	pm, err := parsePathMap(opts.pathMap)
+	if err != nil {
+		return err
+	}
+	run.pathMap = pm
+	streamed := 0
+	for _, set := range []bool{opts.jsonl, opts.oneline, opts.ffprobe} {
+		if set {
+			streamed++
+		}
+	}
+	if streamed > 1 {
+		return errors.New("--jsonl, --oneline and --ffprobe cannot be combined")
+	}
+	if opts.jsonl {
+		run.jsonl = json.NewEncoder(os.Stdout)
+	}
+	if opts.oneline {
+		run.oneline = os.Stdout
+	}
+	if opts.ffprobe {
+		run.ffprobe = json.NewEncoder(os.Stdout)
+	}
+	if opts.playlistIndex {
+		if s.ReportFileName == "-" || streamed > 0 {
+			return errors.New("--playlist-index writes next to the report file and needs one")
+		}
+		run.playlistIndex = true
+	}
+	if opts.events {
+		run.outputs = newOutputLog(os.Stderr, run.pathMap)
+	}
+	if opts.tee {
+		if streamed > 0 {
+			return errors.New("--tee cannot be combined with --jsonl, --oneline or --ffprobe")
+		}
+		// A report already going to stdout needs no copy.
+		run.tee = s.ReportFileName != "-"
+	}
+	if opts.readOnly {
+		run.readOnlyRoot = opts.path
+		if s.ReportFileName != "-" && streamed == 0 {
+			if err := run.checkWritable(filepath.Dir(s.ReportFileName)); err != nil {
+				return err
+			}
+		}
+	}
+	if opts.jarImagesDir != "" {
+		if s.NoTempFiles {
+			return errors.New("--extractjarimages writes outside the report path and cannot be combined with --notempfiles")
+		}
+		if err := run.checkWritable(opts.jarImagesDir); err != nil {
+			return err
+		}
+		s.HarvestJARImages = true
+		run.jarImagesDir = opts.jarImagesDir
+	}
+	if opts.saveScan != "" {
+		if s.NoTempFiles {
+			return errors.New("--save-scan writes outside the report path and cannot be combined with --notempfiles")
+		}
+		if err := run.checkWritable(opts.saveScan); err != nil {
+			return err
+		}
+		run.saveScan = opts.saveScan
+	}
+	if opts.cacheDir != "" {
+		if s.NoTempFiles {
+			return errors.New("--cache-dir writes outside the report path and cannot be combined with --notempfiles")
+		}
+		if err := run.checkWritable(opts.cacheDir); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(opts.cacheDir, 0o755); err != nil {
+			return fmt.Errorf("--cache-dir: %w", err)
+		}
+		s.CacheDir = opts.cacheDir
+	}
+	if opts.chaptersOut != "" {
+		if s.NoTempFiles {
+			return errors.New("--chapters-out writes outside the report path and cannot be combined with --notempfiles")
+		}
+		if err := run.checkWritable(opts.chaptersOut); err != nil {
+			return err
+		}
+		run.chaptersOut = opts.chaptersOut
+	}
+	if opts.bitrateGraph != "" {
+		if s.NoTempFiles {
+			return errors.New("--bitrate-graph writes outside the report path and cannot be combined with --notempfiles")
+		}
+		if s.QuickScan {
+			return errors.New("--quick and --bitrate-graph cannot be combined")
+		}
+		if err := run.checkWritable(opts.bitrateGraph); err != nil {
+			return err
+		}
+		s.BitrateGraph = true
+		run.bitrateGraph = opts.bitrateGraph
+	}
+	if opts.subtitleTiming != "" {
+		if s.NoTempFiles {
+			return errors.New("--subtitle-timing-out writes outside the report path and cannot be combined with --notempfiles")
+		}
+		if s.QuickScan {
+			return errors.New("--quick and --subtitle-timing-out cannot be combined")
+		}
+		if err := run.checkWritable(opts.subtitleTiming); err != nil {
+			return err
+		}
+		s.SubtitleTiming = true
+		run.subtitleTiming = opts.subtitleTiming
+	}
+	if opts.ocrCmd != "" || opts.ocrOut != "" {
+		if opts.ocrCmd == "" || opts.ocrOut == "" {
+			return errors.New("--ocr-cmd and --ocr-out must be used together")
+		}
+		if s.NoTempFiles {
+			return errors.New("--ocr-out writes outside the report path and cannot be combined with --notempfiles")
+		}
+		if !strings.Contains(opts.ocrOut, "{1}") {
+			return errors.New("--ocr-out needs {1} in the file name (one SRT per subtitle track)")
+		}
+		if err := run.checkWritable(opts.ocrOut); err != nil {
+			return err
+		}
+		run.ocrCmd = strings.Fields(opts.ocrCmd)
+		if len(run.ocrCmd) == 0 {
+			return errors.New("--ocr-cmd: empty command")
+		}
+		run.ocrAll = opts.ocrAll
+		run.ocrOut = opts.ocrOut
+	}
+	if opts.trace != "" {
+		if s.NoTempFiles {
+			return errors.New("--trace writes outside the report path and cannot be combined with --notempfiles")
+		}
+		closeTrace, err := openTrace(&run, opts.trace, opts.traceFile, ".")
+		if err != nil {
+			return err
+		}
+		defer closeTrace()
+	}
+
+	if err := runForPath(cmd.Context(), opts.path, s, run); err != nil {
+		return err
+	}
+	fmt.Fprintln(run.status(s.ReportFileName), "Scan complete.")
+	return nil
+}
+
+// applyReportFlags copies the flags registered by addReportFlags into s.
+func applyReportFlags(flags *pflag.FlagSet, s *settings.Settings) error {
+	if flags.Changed("generatestreamdiagnostics") {
+		s.GenerateStreamDiagnostics = opts.genDiag
+	}
+	if flags.Changed("keepstreamorder") {
+		s.KeepStreamOrder = opts.keepOrder
+	}
+	if flags.Changed("generatetextsummary") {
+		s.GenerateTextSummary = opts.genSummary
+	}
+	if opts.reportFile != "" {
+		s.ReportFileName = opts.reportFile
+	}
+	if opts.stdout {
+		s.ReportFileName = "-"
+	}
 	if flags.Changed("includeversionandnotes") {
 		s.IncludeVersionAndNotes = opts.includeNotes
 	}
+	if flags.Changed("includenoteshome") {
+		s.IncludeNotesHome = opts.notesHome
+	}
+	if flags.Changed("includenotesforums") {
+		s.IncludeNotesForums = opts.notesForums
+	}
+	if flags.Changed("productversion") {
+		s.ProductVersion = opts.productVersion
+	}
+	if flags.Changed("generated-by") {
+		s.IncludeGeneratedBy = opts.generatedBy
+	}
+	if version != "dev" {
+		// Release builds carry their version; dev builds fall back to build info.
+		s.ToolVersion = version
+	}
+	if flags.Changed("reportlanguage") {
+		s.ReportLanguage = opts.reportLanguage
+	}
+	if flags.Changed("reportlabels") {
+		s.ReportLabelsFile = opts.reportLabels
+	}
+	if flags.Changed("timeformat") {
+		s.TimeFormat = opts.timeFormat
+	}
+	if flags.Changed("rounding") {
+		s.RoundingMode = opts.roundingMode
+	}
+	if flags.Changed("sort-playlists") {
+		s.PlaylistSort = opts.playlistSort
+	}
 	if flags.Changed("groupbytime") {
 		s.GroupByTime = opts.groupByTime
 	}
 	if flags.Changed("forumsonly") {
 		s.ForumsOnly = opts.forumsOnly
 	}
-	if flags.Changed("playlist") {
-		s.PlaylistOnly = normalizePlaylistName(opts.playlist)
-	}
 	if flags.Changed("main") {
 		s.MainPlaylistOnly = opts.mainOnly
 	}
 	if flags.Changed("printonlybigplaylist") {
 		s.BigPlaylistOnly = opts.bigPlaylistOnly
 	}
-	if s.PlaylistOnly != "" {
-		s.MainPlaylistOnly = false
-		s.BigPlaylistOnly = false
+	if flags.Changed("top") {
+		if opts.topPlaylists < 0 {
+			return errors.New("--top must not be negative")
+		}
+		s.TopPlaylists = opts.topPlaylists
 	}
 	if flags.Changed("summaryonly") {
 		s.SummaryOnly = opts.summaryOnly
@@ -318,14 +793,35 @@ func runRoot(cmd *cobra.Command, args []string) error {
 			s.GenerateTextSummary = true
 		}
 	}
-
-	if err := runForPath(cmd.Context(), opts.path, s, opts.progress); err != nil {
-		return err
+	if flags.Changed("titles") {
+		s.IncludeTitleMap = opts.titleMap
+	}
+	if flags.Changed("restrictions") {
+		s.IncludeRestrictions = opts.restrictions
+	}
+	if flags.Changed("chapter-names") {
+		s.IncludeChapterNames = opts.chapterNames
+	}
+	if flags.Changed("protection-details") {
+		s.IncludeProtectionDetails = opts.protection
+	}
+	if flags.Changed("collapse-duplicates") {
+		s.CollapseDuplicates = opts.collapseDups
 	}
-	if s.ReportFileName == "-" {
-		fmt.Fprintln(os.Stderr, "Scan complete.")
-	} else {
-		fmt.Println("Scan complete.")
+	if flags.Changed("3d-offsets") {
+		s.Include3DOffsets = opts.offsets3D
+	}
+	if flags.Changed("3d-bitrates") {
+		s.Include3DBitrates = opts.bitrates3D
+	}
+	if flags.Changed("bitrate-sparklines") {
+		s.IncludeBitrateGraph = opts.sparklines
+	}
+	if flags.Changed("wide-columns") {
+		s.WideColumns = opts.wideColumns
+	}
+	if flags.Changed("empty-sections") {
+		s.ShowEmptySections = opts.emptySections
 	}
 	return nil
 }
@@ -365,17 +861,330 @@ func runSelfUpdate(ctx context.Context) error {
 	return nil
 }
 
-func runForPath(ctx context.Context, path string, settings settings.Settings, progress bool) error {
-	lower := strings.ToLower(path)
-	if strings.HasSuffix(lower, ".iso") {
-		reportPath, err := scanAndReport(ctx, path, settings, progress)
+// runOptions carries CLI-only behavior that is not part of the BDInfo settings.
+type runOptions struct {
+	progress     bool
+	bitrateTrace io.Writer
+	// readOnlyRoot is the scanned disc path when --read-only is set.
+	readOnlyRoot string
+	jarImagesDir string
+	// jsonl, when set, receives one JSON Result per disc instead of text reports.
+	jsonl *json.Encoder
+	// oneline, when set, receives the one-line summary of each disc instead of
+	// text reports (--oneline).
+	oneline io.Writer
+	// ffprobe, when set, receives the ffprobe-style JSON of each disc's main
+	// playlist instead of text reports (--ffprobe).
+	ffprobe *json.Encoder
+	// pathMap translates container paths to host paths in outputs (--path-map).
+	pathMap pathMap
+	// saveScan is the --save-scan file name; {0} is replaced by the disc label.
+	saveScan string
+	// chaptersOut is the --chapters-out file name; {0} is replaced by the disc label.
+	chaptersOut string
+	// bitrateGraph is the --bitrate-graph file name; {0} is replaced by the disc label.
+	bitrateGraph string
+	// subtitleTiming is the --subtitle-timing-out file name; {0} is replaced
+	// by the disc label.
+	subtitleTiming string
+	// outputs, when set, lists the files written for each disc (--events).
+	outputs *outputLog
+	// tee copies each report written to a file to stdout as well (--tee).
+	tee bool
+	// playlistIndex writes Result.PlaylistIndex next to each report
+	// (--playlist-index).
+	playlistIndex bool
+	// ocrCmd is the --ocr-cmd command line, split on spaces; ocrOut is the
+	// --ocr-out file name with {0} the disc label and {1} the track.
+	ocrCmd []string
+	ocrAll bool
+	ocrOut string
+	// maxRSS is the --max-rss limit in bytes. Once a batch disc leaves the
+	// process above it, restart runs a fresh process that continues the
+	// batch from the state file it is given.
+	maxRSS  uint64
+	restart func(stateFile string) error
+	// batchResume is the state file of a batch restarted by --max-rss.
+	batchResume string
+}
+
+// status returns where status lines such as "Report written:" go: stdout,
+// unless stdout carries the report or the --jsonl/--oneline/--ffprobe output.
+func (r runOptions) status(reportFileName string) io.Writer {
+	if reportFileName == "-" || r.streamed() || r.tee {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// hostResult returns result with its recorded paths translated by --path-map.
+func (r runOptions) hostResult(result bdinfo.Result) bdinfo.Result {
+	result.Disc.Path = r.pathMap.toHost(result.Disc.Path)
+	result.ReportPath = r.pathMap.toHost(result.ReportPath)
+	if result.FFprobe != nil {
+		ffprobe := *result.FFprobe
+		ffprobe.Format.Filename = "bluray:" + result.Disc.Path
+		result.FFprobe = &ffprobe
+	}
+	return result
+}
+
+// streamed reports whether each disc is written as one line to stdout
+// (--jsonl, --oneline or --ffprobe) instead of as a report.
+func (r runOptions) streamed() bool {
+	return r.jsonl != nil || r.oneline != nil || r.ffprobe != nil
+}
+
+// emit writes result as its line of the --jsonl, --oneline or --ffprobe output.
+func (r runOptions) emit(result bdinfo.Result) error {
+	if r.jsonl != nil {
+		return r.jsonl.Encode(r.hostResult(result))
+	}
+	if r.ffprobe != nil {
+		result = r.hostResult(result)
+		if result.FFprobe == nil {
+			return errors.New("--ffprobe: no playlist to describe")
+		}
+		return r.ffprobe.Encode(result.FFprobe)
+	}
+	_, err := fmt.Fprintln(r.oneline, result.OneLine)
+	return err
+}
+
+// checkWritable fails if target lies inside the read-only disc path.
+func (r runOptions) checkWritable(target string) error {
+	if r.readOnlyRoot == "" || target == "-" {
+		return nil
+	}
+	if pathWithin(r.readOnlyRoot, target) {
+		return fmt.Errorf("read-only: refusing to write %s inside scanned path %s", target, r.readOnlyRoot)
+	}
+	return nil
+}
+
+// pathWithin reports whether target equals root or lies beneath it,
+// resolving symlinks where the paths exist.
+func pathWithin(root string, target string) bool {
+	resolve := func(p string) string {
+		abs, err := filepath.Abs(p)
 		if err != nil {
-			return err
+			return filepath.Clean(p)
+		}
+		// Resolve the longest existing prefix so not-yet-created files still compare.
+		dir, rest := abs, ""
+		for {
+			if real, err := filepath.EvalSymlinks(dir); err == nil {
+				return filepath.Join(real, rest)
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return abs
+			}
+			rest = filepath.Join(filepath.Base(dir), rest)
+			dir = parent
+		}
+	}
+	rel, err := filepath.Rel(resolve(root), resolve(target))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// openTrace opens the audit trace requested via --trace and attaches it to run.
+func openTrace(run *runOptions, kind string, path string, baseDir string) (func(), error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind != "bitrate" {
+		return nil, fmt.Errorf("unsupported trace %q (supported: bitrate)", kind)
+	}
+	if path == "" {
+		path = filepath.Join(baseDir, "BDInfo_"+kind+"-trace.jsonl")
+	}
+	if err := run.checkWritable(path); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	run.bitrateTrace = f
+	return func() {
+		_ = f.Close()
+		fmt.Fprintf(os.Stderr, "Trace written: %s\n", run.pathMap.toHost(path))
+	}, nil
+}
+
+func runForPath(ctx context.Context, path string, settings settings.Settings, run runOptions) error {
+	targets, multi := discTargets(path)
+	if !multi {
+		if run.streamed() {
+			result, err := scanDisc(ctx, path, settings, run)
+			if err == nil {
+				err = run.emit(result)
+			}
+			return errors.Join(err, run.outputs.flush(run.pathMap.toHost(path), err))
+		}
+		reportPath, err := scanAndReport(ctx, path, settings, run)
+		if err != nil {
+			return errors.Join(err, run.outputs.flush(run.pathMap.toHost(path), err))
 		}
 		if reportPath != "-" {
-			fmt.Printf("Report written: %s\n", reportPath)
+			fmt.Fprintf(run.status(reportPath), "Report written: %s\n", run.pathMap.toHost(reportPath))
 		}
-		return nil
+		return run.outputs.flush(run.pathMap.toHost(path), nil)
+	}
+
+	if run.saveScan != "" && !strings.Contains(run.saveScan, "{0}") {
+		return errors.New("--save-scan needs {0} in the file name when scanning several discs")
+	}
+	if run.chaptersOut != "" && !strings.Contains(run.chaptersOut, "{0}") {
+		return errors.New("--chapters-out needs {0} in the file name when scanning several discs")
+	}
+	if run.bitrateGraph != "" && !strings.Contains(run.bitrateGraph, "{0}") {
+		return errors.New("--bitrate-graph needs {0} in the file name when scanning several discs")
+	}
+	if run.subtitleTiming != "" && !strings.Contains(run.subtitleTiming, "{0}") {
+		return errors.New("--subtitle-timing-out needs {0} in the file name when scanning several discs")
+	}
+	if run.ocrOut != "" && !strings.Contains(run.ocrOut, "{0}") {
+		return errors.New("--ocr-out needs {0} in the file name when scanning several discs")
+	}
+
+	// Batch mode keeps going past failed discs and summarizes them at the end.
+	combinedPath := settings.ReportFileName
+	if combinedPath == "-" || run.streamed() {
+		combinedPath = ""
+	}
+	// Combined reports are assembled in memory so multi-disc runs never leave
+	// intermediate per-disc files next to the sources. Without one, only the
+	// summary rows are kept so long batches do not hold every disc's result.
+	var combined bdinfo.MultiDiscResult
+	entries := make([]batchEntry, 0, len(targets))
+	next := 0
+	if run.batchResume != "" {
+		state, err := readBatchState(run.batchResume, path)
+		if err != nil {
+			return err
+		}
+		targets, next = state.Targets, state.Next
+		combined.Discs, entries = state.Discs, state.entries()
+	}
+	for i := next; i < len(targets); i++ {
+		target := targets[i]
+		result, err := scanBatchDisc(ctx, target, settings, run, combinedPath != "")
+		disc := bdinfo.DiscResult{Path: run.pathMap.toHost(target), Result: run.hostResult(result)}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", disc.Path, err)
+			disc.Error = err.Error()
+		}
+		if flushErr := run.outputs.flush(disc.Path, err); flushErr != nil {
+			return flushErr
+		}
+		if combinedPath != "" {
+			combined.Discs = append(combined.Discs, disc)
+		}
+		entries = append(entries, newBatchEntry(disc.Path, result, err))
+		if run.maxRSS > 0 && i+1 < len(targets) && overMaxRSS(run.maxRSS) {
+			return restartBatch(settings, run, newBatchState(path, targets, i+1, combined.Discs, entries))
+		}
+	}
+	if combinedPath != "" && slices.ContainsFunc(combined.Discs, func(d bdinfo.DiscResult) bool { return d.Error == "" }) {
+		if err := run.checkWritable(combinedPath); err != nil {
+			return err
+		}
+		report := combinedReport(combinedPath, combined)
+		if err := writeReport(combinedPath, report); err != nil {
+			return err
+		}
+		if err := run.teeReport(report); err != nil {
+			return err
+		}
+		fmt.Fprintf(run.status(combinedPath), "Report written: %s\n", run.pathMap.toHost(combinedPath))
+		run.outputs.add(combinedPath, reportFormat(combinedPath), len(report))
+		if err := run.outputs.flush("", nil); err != nil {
+			return err
+		}
+	}
+
+	if err := writeBatchSummary(run.status(settings.ReportFileName), entries); err != nil {
+		return err
+	}
+	failed := 0
+	for _, entry := range entries {
+		if entry.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d discs failed", failed, len(entries))
+	}
+	return nil
+}
+
+// restartBatch hands the rest of the batch to a fresh process, releasing the
+// memory the scans so far have left behind (--max-rss).
+func restartBatch(settings settings.Settings, run runOptions, state batchState) error {
+	stateFile, err := writeBatchState(settings, state)
+	if err != nil {
+		return fmt.Errorf("--max-rss: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Resident memory above --max-rss; restarting for the remaining %d discs\n", len(state.Targets)-state.Next)
+	if err := run.restart(stateFile); err != nil {
+		_ = os.Remove(stateFile)
+		return fmt.Errorf("--max-rss: restart: %w", err)
+	}
+	return nil
+}
+
+// combinedReport assembles the report of a multi-disc run: an index table and
+// a section per disc for text reports. XML and CSV reports cannot carry the
+// index, so their per-disc reports are only concatenated.
+func combinedReport(reportPath string, combined bdinfo.MultiDiscResult) string {
+	ext := strings.ToLower(filepath.Ext(reportPath))
+	if ext != ".xml" && ext != ".csv" {
+		return combined.Report()
+	}
+	reports := make([]string, 0, len(combined.Discs))
+	for _, disc := range combined.Discs {
+		if disc.Error == "" {
+			reports = append(reports, disc.Result.Report)
+		}
+	}
+	return strings.Join(reports, "\n\n\n\n\n")
+}
+
+// scanBatchDisc scans one disc of a batch and emits it according to the output
+// mode: a JSON line, its own report file, or nothing when combine is set and the
+// caller collects the report into the combined one.
+func scanBatchDisc(ctx context.Context, target string, settings settings.Settings, run runOptions, combine bool) (bdinfo.Result, error) {
+	result, err := scanDisc(ctx, target, settings, run)
+	if err != nil {
+		return result, err
+	}
+	switch {
+	case run.streamed():
+		return result, run.emit(result)
+	case combine:
+		return result, nil
+	}
+	if err := writeResultReport(run, result); err != nil {
+		return result, err
+	}
+	if result.ReportPath != "-" {
+		fmt.Fprintf(run.status(result.ReportPath), "Report written: %s\n", run.pathMap.toHost(result.ReportPath))
+	}
+	return result, nil
+}
+
+// discTargets resolves path to the discs to scan. multi is set when path is a
+// folder holding several BDMV (or BDAV, or DVD VIDEO_TS) folders or ISO files
+// (batch mode).
+func discTargets(path string) (targets []string, multi bool) {
+	if path == fs.StdinPath || fs.IsRemoteURL(path) || strings.HasSuffix(strings.ToLower(path), ".iso") {
+		return []string{path}, false
 	}
 
 	bdmvDirs := []string{}
@@ -383,19 +1192,28 @@ func runForPath(ctx context.Context, path string, settings settings.Settings, pr
 		if err != nil {
 			return nil
 		}
-		if d.IsDir() && strings.EqualFold(d.Name(), "BDMV") {
-			bdmvDirs = append(bdmvDirs, p)
+		if fs.IsMacOSNoise(d.Name(), d.IsDir()) {
+			return skipNoise(d)
+		}
+		if d.IsDir() && (strings.EqualFold(d.Name(), "BDMV") || strings.EqualFold(d.Name(), "BDAV") || strings.EqualFold(d.Name(), "VIDEO_TS")) {
+			// A disc with both directories is one target.
+			if !slices.ContainsFunc(bdmvDirs, func(dir string) bool { return filepath.Dir(dir) == filepath.Dir(p) }) {
+				bdmvDirs = append(bdmvDirs, p)
+			}
 			return filepath.SkipDir
 		}
 		return nil
 	})
 	isIsoLevel := false
-	isoFiles := []string{}
 	if len(bdmvDirs) == 0 {
+		isoFiles := []string{}
 		_ = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
 			if err != nil {
 				return nil
 			}
+			if fs.IsMacOSNoise(d.Name(), d.IsDir()) {
+				return skipNoise(d)
+			}
 			if !d.IsDir() && strings.HasSuffix(strings.ToLower(p), ".iso") {
 				isoFiles = append(isoFiles, p)
 			}
@@ -407,82 +1225,126 @@ func runForPath(ctx context.Context, path string, settings settings.Settings, pr
 		}
 	}
 
-	if len(bdmvDirs) > 1 || isIsoLevel {
-		stdout := settings.ReportFileName == "-"
-		oldReport := settings.ReportFileName
-		if stdout {
-			oldReport = ""
+	if len(bdmvDirs) <= 1 && !isIsoLevel {
+		return []string{path}, false
+	}
+	for _, sub := range bdmvDirs {
+		if isIsoLevel {
+			targets = append(targets, sub)
+		} else {
+			targets = append(targets, filepath.Dir(sub))
 		}
-		reports := []string{}
-		for _, sub := range bdmvDirs {
-			target := sub
-			if !isIsoLevel {
-				target = filepath.Dir(sub)
-			}
-			if oldReport != "" {
-				parent := filepath.Dir(target)
-				if isIsoLevel {
-					settings.ReportFileName = filepath.Join(parent, strings.TrimSuffix(filepath.Base(target), filepath.Ext(target))) + "." + strings.TrimPrefix(filepath.Ext(oldReport), ".")
-				} else {
-					settings.ReportFileName = filepath.Join(parent, filepath.Base(target)) + "." + strings.TrimPrefix(filepath.Ext(oldReport), ".")
-				}
-				reports = append(reports, settings.ReportFileName)
-			}
-			reportPath, err := scanAndReport(ctx, target, settings, progress)
-			if err != nil {
-				return err
-			}
-			if oldReport == "" && reportPath != "-" {
-				fmt.Printf("Report written: %s\n", reportPath)
-			}
+	}
+	return targets, true
+}
+
+// skipNoise skips a macOS metadata entry found while walking for discs.
+func skipNoise(d os.DirEntry) error {
+	if d.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+func scanAndReport(ctx context.Context, path string, settings settings.Settings, run runOptions) (string, error) {
+	result, err := scanDisc(ctx, path, settings, run)
+	if err != nil {
+		return "", err
+	}
+	if err := writeResultReport(run, result); err != nil {
+		return "", err
+	}
+	return result.ReportPath, nil
+}
+
+// writeResultReport writes the report of one disc, plus the clip table that
+// accompanies a CSV report, the --generateframedatafile frame data and, with
+// --playlist-index, the playlist index.
+func writeResultReport(run runOptions, result bdinfo.Result) error {
+	if err := run.checkWritable(result.ReportPath); err != nil {
+		return err
+	}
+	if err := writeReport(result.ReportPath, result.Report); err != nil {
+		return err
+	}
+	if err := run.teeReport(result.Report); err != nil {
+		return err
+	}
+	run.outputs.add(result.ReportPath, reportFormat(result.ReportPath), len(result.Report))
+	if result.ClipsPath != "" {
+		if err := run.checkWritable(result.ClipsPath); err != nil {
+			return err
 		}
-		if oldReport != "" && len(reports) > 0 {
-			if len(reports) == 1 {
-				_ = os.Rename(reports[0], oldReport)
-				fmt.Printf("Report written: %s\n", oldReport)
-				return nil
-			}
-			combined, err := os.Create(oldReport)
-			if err != nil {
-				return err
-			}
-			defer combined.Close()
-			for _, reportFile := range reports {
-				data, err := os.ReadFile(reportFile)
-				if err != nil {
-					continue
-				}
-				combined.Write(data)
-				combined.WriteString("\n\n\n\n\n")
-				_ = os.Remove(reportFile)
-			}
-			fmt.Printf("Report written: %s\n", oldReport)
-			return nil
+		if err := writeReport(result.ClipsPath, result.ClipsCSV); err != nil {
+			return err
 		}
-		return nil
+		run.outputs.add(result.ClipsPath, "csv", len(result.ClipsCSV))
+	}
+	for _, file := range result.FrameData {
+		if err := run.checkWritable(file.Path); err != nil {
+			return err
+		}
+		if err := writeReport(file.Path, file.Data); err != nil {
+			return err
+		}
+		run.outputs.add(file.Path, "txt", len(file.Data))
 	}
+	if run.playlistIndex {
+		return writePlaylistIndex(run, result)
+	}
+	return nil
+}
 
-	reportPath, err := scanAndReport(ctx, path, settings, progress)
+// playlistIndexPath names the --playlist-index file of a report:
+// BDINFO.MOVIE.txt gets BDINFO.MOVIE.index.json.
+func playlistIndexPath(reportPath string) string {
+	return strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + ".index.json"
+}
+
+// writePlaylistIndex writes the playlist index next to the disc's report.
+func writePlaylistIndex(run runOptions, result bdinfo.Result) error {
+	target := playlistIndexPath(result.ReportPath)
+	if err := run.checkWritable(target); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(result.PlaylistIndex(), "", "  ")
 	if err != nil {
 		return err
 	}
-	if reportPath != "-" {
-		fmt.Printf("Report written: %s\n", reportPath)
+	data = append(data, '\n')
+	if err := writeReport(target, string(data)); err != nil {
+		return err
 	}
+	run.outputs.add(target, "json", len(data))
 	return nil
 }
 
-func scanAndReport(ctx context.Context, path string, settings settings.Settings, progress bool) (string, error) {
+// scanDisc runs the library scan for one disc, printing progress when requested.
+func scanDisc(ctx context.Context, path string, settings settings.Settings, run runOptions) (bdinfo.Result, error) {
 	start := time.Now()
+	progress := run.progress
 	var progressPrinter *scanProgressPrinter
 	if progress {
-		fmt.Fprintf(os.Stderr, "Scanning: %s\n", path)
+		fmt.Fprintf(os.Stderr, "Scanning: %s\n", run.pathMap.toHost(path))
 		progressPrinter = newScanProgressPrinter(os.Stderr)
 	}
 
+	var saveScan io.Writer
+	var saved bytes.Buffer
+	if run.saveScan != "" {
+		saveScan = &saved
+	}
+	var subtitleOCR func(bdinfo.SubtitleImage) (string, error)
+	if len(run.ocrCmd) > 0 {
+		subtitleOCR = ocrCommand(ctx, run.ocrCmd, run.ocrAll)
+	}
+
 	result, err := bdinfo.Run(ctx, bdinfo.Options{
-		Path:     path,
-		Settings: toLibrarySettings(settings),
+		Path:         path,
+		Settings:     toLibrarySettings(settings),
+		BitrateTrace: run.bitrateTrace,
+		SaveScan:     saveScan,
+		SubtitleOCR:  subtitleOCR,
 		OnProgress: func(event bdinfo.ProgressEvent) {
 			if !progress {
 				return
@@ -498,11 +1360,35 @@ func scanAndReport(ctx context.Context, path string, settings settings.Settings,
 		},
 	})
 	if err != nil {
-		return "", err
+		return bdinfo.Result{}, err
 	}
-
-	if err := writeReport(result.ReportPath, result.Report); err != nil {
-		return "", err
+	if err := writeJARImages(run.jarImagesDir, result.JARImages, run.outputs); err != nil {
+		return bdinfo.Result{}, err
+	}
+	if saveScan != nil {
+		if err := writeSavedScan(run, result.Disc.Label, saved.Bytes()); err != nil {
+			return bdinfo.Result{}, err
+		}
+	}
+	if run.chaptersOut != "" {
+		if err := writeChaptersFile(run, result, settings.PlaylistOnly); err != nil {
+			return bdinfo.Result{}, err
+		}
+	}
+	if run.bitrateGraph != "" {
+		if err := writeBitrateGraph(run, result); err != nil {
+			return bdinfo.Result{}, err
+		}
+	}
+	if run.subtitleTiming != "" {
+		if err := writeSubtitleTiming(run, result, settings.PlaylistOnly); err != nil {
+			return bdinfo.Result{}, err
+		}
+	}
+	if run.ocrOut != "" {
+		if err := writeSubtitleFiles(run, result, settings.PlaylistOnly); err != nil {
+			return bdinfo.Result{}, err
+		}
 	}
 
 	if progress {
@@ -512,7 +1398,7 @@ func scanAndReport(ctx context.Context, path string, settings settings.Settings,
 		fmt.Fprintf(os.Stderr, "Scan complete in %s\n", time.Since(start).Round(time.Millisecond))
 	}
 
-	return result.ReportPath, nil
+	return result, nil
 }
 
 func scanProgressFromEvent(event bdinfo.ProgressEvent) bdrom.ScanProgress {
@@ -530,11 +1416,14 @@ func scanProgressFromEvent(event bdinfo.ProgressEvent) bdrom.ScanProgress {
 		stage = bdrom.ScanStageComplete
 	}
 	return bdrom.ScanProgress{
-		Stage:          stage,
-		Completed:      event.Completed,
-		Total:          event.Total,
-		ProcessedBytes: event.ProcessedBytes,
-		TotalBytes:     event.TotalBytes,
+		Stage:              stage,
+		Completed:          event.Completed,
+		Total:              event.Total,
+		ProcessedBytes:     event.ProcessedBytes,
+		TotalBytes:         event.TotalBytes,
+		File:               event.File,
+		FileProcessedBytes: event.FileProcessedBytes,
+		FileTotalBytes:     event.FileTotalBytes,
 	}
 }
 
@@ -542,6 +1431,7 @@ func toLibrarySettings(s settings.Settings) bdinfo.Settings {
 	return bdinfo.Settings{
 		GenerateStreamDiagnostics: s.GenerateStreamDiagnostics,
 		ExtendedStreamDiagnostics: s.ExtendedStreamDiagnostics,
+		GenerateFrameDataFile:     s.GenerateFrameDataFile,
 		EnableSSIF:                s.EnableSSIF,
 		BigPlaylistOnly:           s.BigPlaylistOnly,
 		FilterLoopingPlaylists:    s.FilterLoopingPlaylists,
@@ -551,12 +1441,161 @@ func toLibrarySettings(s settings.Settings) bdinfo.Settings {
 		GenerateTextSummary:       s.GenerateTextSummary,
 		ReportFileName:            s.ReportFileName,
 		IncludeVersionAndNotes:    s.IncludeVersionAndNotes,
+		IncludeNotesHome:          s.IncludeNotesHome,
+		IncludeNotesForums:        s.IncludeNotesForums,
+		ProductVersion:            s.ProductVersion,
+		ToolVersion:               s.ToolVersion,
+		IncludeGeneratedBy:        s.IncludeGeneratedBy,
+		ReportLanguage:            s.ReportLanguage,
+		ReportLabelsFile:          s.ReportLabelsFile,
+		TimeFormat:                s.TimeFormat,
+		RoundingMode:              s.RoundingMode,
+		PlaylistSort:              s.PlaylistSort,
 		GroupByTime:               s.GroupByTime,
 		ForumsOnly:                s.ForumsOnly,
 		PlaylistOnly:              s.PlaylistOnly,
+		TitleOnly:                 s.TitleOnly,
 		MainPlaylistOnly:          s.MainPlaylistOnly,
+		TopPlaylists:              s.TopPlaylists,
 		SummaryOnly:               s.SummaryOnly,
+		TempDir:                   s.TempDir,
+		NoTempFiles:               s.NoTempFiles,
+		HarvestJARImages:          s.HarvestJARImages,
+		IncludeTitleMap:           s.IncludeTitleMap,
+		IncludeRestrictions:       s.IncludeRestrictions,
+		Include3DOffsets:          s.Include3DOffsets,
+		Include3DBitrates:         s.Include3DBitrates,
+		IncludeBitrateGraph:       s.IncludeBitrateGraph,
+		IncludeChapterNames:       s.IncludeChapterNames,
+		IncludeProtectionDetails:  s.IncludeProtectionDetails,
+		CollapseDuplicates:        s.CollapseDuplicates,
+		BitrateGraph:              s.BitrateGraph,
+		SubtitleTiming:            s.SubtitleTiming,
+		WideColumns:               s.WideColumns,
+		ShowEmptySections:         s.ShowEmptySections,
+		SplitOverlappingClips:     s.SplitOverlappingClips,
+		TrackUnknownPIDs:          s.TrackUnknownPIDs,
+		SubtitleSummary:           s.SubtitleSummary,
+		QuickScan:                 s.QuickScan,
+		FullScan:                  s.FullScan,
+		LowMemory:                 s.LowMemory,
+		IORetries:                 s.IORetries,
+		IORetryDelay:              s.IORetryDelay,
+		MaxReadMbps:               s.MaxReadMbps,
+		Workers:                   s.Workers,
+		IOMode:                    s.IOMode,
+		CacheDir:                  s.CacheDir,
+		MaxOpenFiles:              s.MaxOpenFiles,
+	}
+}
+
+// writeJARImages stores harvested BD-J images as <dir>/<JAR>/<entry path>.
+func writeJARImages(dir string, images []bdinfo.JARImage, outputs *outputLog) error {
+	if dir == "" || len(images) == 0 {
+		return nil
+	}
+	for _, img := range images {
+		// Clean against a rooted path so entry names cannot escape dir.
+		name := filepath.FromSlash(path.Clean("/" + img.Name))
+		target := filepath.Join(dir, strings.TrimSuffix(img.JAR, filepath.Ext(img.JAR)), name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, img.Data, 0o644); err != nil {
+			return err
+		}
+		outputs.add(target, img.Format, len(img.Data))
+	}
+	fmt.Fprintf(os.Stderr, "Extracted %d BD-J images to %s\n", len(images), dir)
+	return nil
+}
+
+// writeSavedScan stores the scan captured for --save-scan under its final name.
+func writeSavedScan(run runOptions, label string, data []byte) error {
+	target := strings.ReplaceAll(run.saveScan, "{0}", label)
+	if err := run.checkWritable(target); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, data, 0o644); err != nil {
+		return err
+	}
+	run.outputs.add(target, "scan", len(data))
+	fmt.Fprintf(os.Stderr, "Scan saved: %s\n", run.pathMap.toHost(target))
+	return nil
+}
+
+// writeChaptersFile writes the chapters of the selected (or main) playlist for
+// --chapters-out. A playlist without chapters only gets a note on stderr.
+func writeChaptersFile(run runOptions, result bdinfo.Result, playlistName string) error {
+	playlist, ok := result.Playlist(playlistName)
+	if !ok {
+		return errors.New("--chapters-out: no playlist to take chapters from")
+	}
+	if len(playlist.Chapters) == 0 {
+		fmt.Fprintf(os.Stderr, "Chapters: %s has no chapters, nothing written\n", playlist.Name)
+		return nil
+	}
+	target := strings.ReplaceAll(run.chaptersOut, "{0}", result.Disc.Label)
+	if err := run.checkWritable(target); err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if err := playlist.WriteChapters(&b, bdinfo.ChapterFormatForPath(target)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, b.Bytes(), 0o644); err != nil {
+		return err
+	}
+	run.outputs.add(target, string(bdinfo.ChapterFormatForPath(target)), b.Len())
+	fmt.Fprintf(os.Stderr, "Chapters written: %s (%s)\n", run.pathMap.toHost(target), playlist.Name)
+	return nil
+}
+
+// writeBitrateGraph writes the main playlist's bitrate graph for
+// --bitrate-graph, or that of every playlist when the name holds {1}; a name
+// ending in .json gets JSON, any other CSV.
+func writeBitrateGraph(run runOptions, result bdinfo.Result) error {
+	graphs := []*bdinfo.BitrateGraph{result.BitrateGraph}
+	if strings.Contains(run.bitrateGraph, "{1}") {
+		graphs = result.BitrateGraphs
+	}
+	if len(graphs) == 0 || graphs[0] == nil {
+		return errors.New("--bitrate-graph: no playlist to graph")
+	}
+	for _, graph := range graphs {
+		playlist := strings.TrimSuffix(graph.Playlist, filepath.Ext(graph.Playlist))
+		target := strings.ReplaceAll(strings.ReplaceAll(run.bitrateGraph, "{0}", result.Disc.Label), "{1}", playlist)
+		if err := run.checkWritable(target); err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		format := "csv"
+		if strings.EqualFold(filepath.Ext(target), ".json") {
+			format = "json"
+			enc := json.NewEncoder(&b)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(graph); err != nil {
+				return err
+			}
+		} else if err := graph.WriteCSV(&b); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, b.Bytes(), 0o644); err != nil {
+			return err
+		}
+		run.outputs.add(target, format, b.Len())
+		fmt.Fprintf(os.Stderr, "Bitrate graph written: %s (%s)\n", run.pathMap.toHost(target), graph.Playlist)
+	}
+	return nil
+}
+
+// teeReport copies a report just written to its file to stdout (--tee).
+func (r runOptions) teeReport(output string) error {
+	if !r.tee {
+		return nil
 	}
+	_, err := os.Stdout.WriteString(output)
+	return err
 }
 
 func writeReport(reportPath string, output string) error {
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	bdinfo "github.com/autobrr/go-bdinfo/pkg/bdinfo"
 )
 
 func TestNormalizeArgs_BoolValueTokens(t *testing.T) {
@@ -127,3 +133,389 @@ func TestFormatReadSpeed(t *testing.T) {
 		t.Fatalf("formatReadSpeed oneMB got=%q", got)
 	}
 }
+
+func TestRunOptionsCheckWritable(t *testing.T) {
+	disc := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(disc, "BDMV"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	run := runOptions{readOnlyRoot: disc}
+
+	tests := []struct {
+		name    string
+		target  string
+		wantErr bool
+	}{
+		{name: "disc root", target: disc, wantErr: true},
+		{name: "inside bdmv", target: filepath.Join(disc, "BDMV", "BDInfo_DISC.txt"), wantErr: true},
+		{name: "new file in root", target: filepath.Join(disc, "report.txt"), wantErr: true},
+		{name: "sibling", target: disc + "-reports", wantErr: false},
+		{name: "parent", target: filepath.Dir(disc), wantErr: false},
+		{name: "stdout", target: "-", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run.checkWritable(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkWritable(%q) err=%v wantErr=%v", tt.target, err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (runOptions{}).checkWritable(disc); err != nil {
+		t.Fatalf("checkWritable without read-only err=%v", err)
+	}
+}
+
+func TestRunOptionsStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		run        runOptions
+		reportFile string
+		want       *os.File
+	}{
+		{name: "report file", reportFile: "BDInfo_DISC.bdinfo", want: os.Stdout},
+		{name: "stdout report", reportFile: "-", want: os.Stderr},
+		{name: "tee", run: runOptions{tee: true}, reportFile: "BDInfo_DISC.bdinfo", want: os.Stderr},
+		{name: "oneline", run: runOptions{oneline: os.Stdout}, reportFile: "BDInfo_DISC.bdinfo", want: os.Stderr},
+	}
+	for _, tt := range tests {
+		if got := tt.run.status(tt.reportFile); got != tt.want {
+			t.Errorf("%s: status went to %v", tt.name, got)
+		}
+	}
+}
+
+func TestRunOptionsEmitFFprobe(t *testing.T) {
+	pm, err := parsePathMap([]string{"/srv/discs:/media"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	run := runOptions{pathMap: pm, ffprobe: json.NewEncoder(&out)}
+	ffprobe := &bdinfo.FFprobeOutput{
+		Streams: []bdinfo.FFprobeStream{{CodecName: "hevc", CodecType: "video", ID: "0x1011", Width: 3840, Height: 2160}},
+		Format:  bdinfo.FFprobeFormat{Filename: "bluray:/media/DISC", NbStreams: 1, FormatName: "mpegts"},
+	}
+	result := bdinfo.Result{Disc: bdinfo.DiscInfo{Path: "/media/DISC"}, FFprobe: ffprobe}
+	if err := run.emit(result); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"streams":[{"index":0,"codec_name":"hevc","codec_type":"video","width":3840,"height":2160,"id":"0x1011"}],` +
+		`"format":{"filename":"bluray:/srv/discs/DISC","nb_streams":1,"format_name":"mpegts","format_long_name":"","duration":"","size":"","bit_rate":""}}` + "\n"
+	if out.String() != want {
+		t.Fatalf("emit wrote:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if ffprobe.Format.Filename != "bluray:/media/DISC" {
+		t.Fatalf("emit changed the result's ffprobe filename to %q", ffprobe.Format.Filename)
+	}
+	if err := run.emit(bdinfo.Result{}); err == nil {
+		t.Fatal("emit without a playlist returned no error")
+	}
+}
+
+func TestWriteJARImages(t *testing.T) {
+	dir := t.TempDir()
+	images := []bdinfo.JARImage{
+		{JAR: "00000.JAR", Name: "images/bg.png", Data: []byte("png")},
+		{JAR: "00000.JAR", Name: "../../escape.jpg", Data: []byte("jpg")},
+	}
+	if err := writeJARImages(dir, images, nil); err != nil {
+		t.Fatalf("writeJARImages error: %v", err)
+	}
+	for _, rel := range []string{"00000/images/bg.png", "00000/escape.jpg"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+			t.Fatalf("expected %s: %v", rel, err)
+		}
+	}
+}
+
+func TestWriteChaptersFile(t *testing.T) {
+	dir := t.TempDir()
+	result := bdinfo.Result{
+		Disc:         bdinfo.DiscInfo{Label: "DISC"},
+		MainPlaylist: "00800.MPLS",
+		Playlists: []bdinfo.PlaylistInfo{
+			{Name: "00001.MPLS"},
+			{Name: "00800.MPLS", Chapters: []bdinfo.ChapterInfo{
+				{Number: 1, StartSeconds: 0, LengthSeconds: 605.5},
+				{Number: 2, StartSeconds: 605.5, LengthSeconds: 3600, Name: "Chase"},
+				{Number: 3, StartSeconds: 4205.5004},
+			}},
+		},
+	}
+
+	run := runOptions{chaptersOut: filepath.Join(dir, "{0}.txt")}
+	if err := writeChaptersFile(run, result, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "DISC.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CHAPTER01=00:00:00.000\nCHAPTER01NAME=Chapter 01\n" +
+		"CHAPTER02=00:10:05.500\nCHAPTER02NAME=Chase\n" +
+		"CHAPTER03=01:10:05.500\nCHAPTER03NAME=Chapter 03\n"
+	if string(data) != want {
+		t.Fatalf("OGM chapters:\n%s\nwant:\n%s", data, want)
+	}
+
+	run.chaptersOut = filepath.Join(dir, "chapters.xml")
+	if err := writeChaptersFile(run, result, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(run.chaptersOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<Chapters>",
+		"<ChapterTimeStart>00:10:05.500000000</ChapterTimeStart>",
+		"<ChapterString>Chase</ChapterString>",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("Matroska chapters missing %q:\n%s", want, data)
+		}
+	}
+
+	run.chaptersOut = filepath.Join(dir, "empty.txt")
+	if err := writeChaptersFile(run, result, "00001.mpls"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(run.chaptersOut); !os.IsNotExist(err) {
+		t.Fatalf("chapter file written for playlist without chapters: %v", err)
+	}
+}
+
+func TestWriteBitrateGraph(t *testing.T) {
+	dir := t.TempDir()
+	result := bdinfo.Result{
+		Disc: bdinfo.DiscInfo{Label: "DISC"},
+		BitrateGraph: &bdinfo.BitrateGraph{Playlist: "00800.MPLS", Seconds: 2, Series: []bdinfo.BitrateSeries{
+			{ID: "00800.MPLS/video/4113", Kind: bdinfo.StreamKindVideo, PID: 4113, BitratesBps: []uint64{30000000, 28000000}},
+			{ID: "00800.MPLS/audio/4352", Kind: bdinfo.StreamKindAudio, PID: 4352, BitratesBps: []uint64{4608000, 3900000}},
+		}},
+	}
+
+	run := runOptions{bitrateGraph: filepath.Join(dir, "{0}.csv")}
+	if err := writeBitrateGraph(run, result); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "DISC.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Second,00800.MPLS/video/4113,00800.MPLS/audio/4352\n" +
+		"0,30000000,4608000\n" +
+		"1,28000000,3900000\n"
+	if string(data) != want {
+		t.Fatalf("bitrate graph:\n%s\nwant:\n%s", data, want)
+	}
+
+	result.BitrateGraphs = []*bdinfo.BitrateGraph{result.BitrateGraph, {Playlist: "00801.MPLS", Seconds: 1, Series: []bdinfo.BitrateSeries{}}}
+	run.bitrateGraph = filepath.Join(dir, "{0}-{1}.json")
+	if err := writeBitrateGraph(run, result); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"DISC-00800.json", "DISC-00801.json"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var graph bdinfo.BitrateGraph
+		if err := json.Unmarshal(data, &graph); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if want := strings.TrimSuffix(name[5:], ".json") + ".MPLS"; graph.Playlist != want {
+			t.Fatalf("%s holds %s", name, graph.Playlist)
+		}
+	}
+
+	result.BitrateGraph = nil
+	run.bitrateGraph = filepath.Join(dir, "{0}.csv")
+	if err := writeBitrateGraph(run, result); err == nil {
+		t.Fatal("no error without a bitrate graph")
+	}
+}
+
+func TestDiscTargets(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"DISC_A/BDMV", "DISC_A/BDAV", "DISC_B/BDMV", "DISC_C/BDAV"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".fseventsd", "BDMV"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	targets, multi := discTargets(root)
+	if !multi || len(targets) != 3 {
+		t.Fatalf("discTargets(batch) got=%v multi=%v", targets, multi)
+	}
+	for _, target := range targets {
+		if filepath.Base(target) == ".fseventsd" {
+			t.Fatalf("discTargets included macOS metadata dir: %v", targets)
+		}
+	}
+
+	single := filepath.Join(root, "DISC_A")
+	if targets, multi := discTargets(single); multi || len(targets) != 1 || targets[0] != single {
+		t.Fatalf("discTargets(single) got=%v multi=%v", targets, multi)
+	}
+	if targets, multi := discTargets("/x/disc.iso"); multi || len(targets) != 1 {
+		t.Fatalf("discTargets(iso) got=%v multi=%v", targets, multi)
+	}
+}
+
+func TestWriteResultReport_FrameData(t *testing.T) {
+	dir := t.TempDir()
+	frames := "# 00800.MPLS PID 4113 (0x1011) MPEG-4 AVC Video\n# Time\tType\tBytes\tPackets\n0.000000\tI\t90000\t480\n"
+	result := bdinfo.Result{
+		Report:     "report\n",
+		ReportPath: filepath.Join(dir, "BDINFO.MOVIE.txt"),
+		FrameData: []bdinfo.FrameDataFile{
+			{Playlist: "00800.MPLS", Path: filepath.Join(dir, "BDINFO.MOVIE.00800.FrameData.txt"), Data: frames},
+		},
+	}
+	if err := writeResultReport(runOptions{}, result); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "BDINFO.MOVIE.00800.FrameData.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != frames {
+		t.Fatalf("frame data got=%q want=%q", data, frames)
+	}
+}
+
+// writeFrameDataDisc lays out a disc named MOVIE whose playlist 00800 plays
+// 60 seconds of clip 00001, an AVC stream on PID 0x1011 with one PES packet
+// per frame.
+func writeFrameDataDisc(t *testing.T, frames int) string {
+	t.Helper()
+	disc := filepath.Join(t.TempDir(), "MOVIE")
+	for _, dir := range []string{"PLAYLIST", "CLIPINF", "STREAM"} {
+		if err := os.MkdirAll(filepath.Join(disc, "BDMV", dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(disc, "BDMV", path), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 1080p, 23.976 fps, 16:9.
+	coding := []byte{5, 0x1B, 0x61, 0x30, 0, 0}
+
+	clpi := make([]byte, 256)
+	copy(clpi, "HDMV0200")
+	binary.BigEndian.PutUint32(clpi[12:], 200)
+	copy(clpi[40+151:], "HDMV")
+	binary.BigEndian.PutUint32(clpi[200:], 20)
+	clpi[204+8] = 1 // one stream
+	binary.BigEndian.PutUint16(clpi[204+10:], 0x1011)
+	copy(clpi[204+12:], coding)
+	write("CLIPINF/00001.clpi", clpi)
+
+	item := []byte("00001M2TS")
+	item = append(item, 0x00, 0x00, 0x00) // connection, stc_id
+	item = binary.BigEndian.AppendUint32(item, 0)
+	item = binary.BigEndian.AppendUint32(item, 45000*60)
+	item = append(item, make([]byte, 12)...) // UO mask, flags, still
+	item = append(item, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	item = append(item, 9, 1, 0x10, 0x11, 0, 0, 0, 0, 0, 0)
+	item = append(item, coding...)
+	list := binary.BigEndian.AppendUint16(nil, uint16(len(item)))
+	list = append(list, item...)
+	mpls := make([]byte, 0x40)
+	copy(mpls, "MPLS0200")
+	mpls = binary.BigEndian.AppendUint32(mpls, uint32(6+len(list)))
+	mpls = binary.BigEndian.AppendUint16(mpls, 0)
+	mpls = binary.BigEndian.AppendUint16(mpls, 1)
+	mpls = binary.BigEndian.AppendUint16(mpls, 0)
+	mpls = append(mpls, list...)
+	binary.BigEndian.PutUint32(mpls[8:], 0x40)
+	binary.BigEndian.PutUint32(mpls[12:], uint32(len(mpls)))
+	mpls = append(mpls, 0, 0, 0, 2, 0, 0) // no chapters
+	write("PLAYLIST/00800.mpls", mpls)
+
+	var m2ts []byte
+	for i := range frames {
+		pts := uint64(i) * 3754
+		pes := []byte{0x00, 0x00, 0x01, 0xE0, 0x00, 0x00, 0x80, 0x80, 0x05,
+			0x21 | byte(pts>>29)&0x0E, byte(pts >> 22), byte(pts>>14)&0xFE | 0x01, byte(pts >> 7), byte(pts<<1) | 0x01}
+		packet := make([]byte, 192)
+		packet[4] = 0x47
+		packet[5] = 0x40 | 0x10 // payload start, PID 0x1011
+		packet[6] = 0x11
+		packet[7] = 0x10 | byte(i&0x0F)
+		copy(packet[8:], pes)
+		m2ts = append(m2ts, packet...)
+	}
+	write("STREAM/00001.m2ts", m2ts)
+	return disc
+}
+
+func TestRunRoot_GenerateFrameData(t *testing.T) {
+	disc := writeFrameDataDisc(t, 30)
+	out := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	rootCmd.SetArgs([]string{"--generateframedatafile", "--bdinfo-settings", "none", "--reportpath", out, disc})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "BDInfo_MOVIE.00800.FrameData.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) < 3 || lines[0] != "# 00800.MPLS PID 4113 (0x1011) MPEG-4 AVC Video" || lines[1] != "# Time\tType\tBytes\tPackets" {
+		t.Fatalf("frame data got=%q", data)
+	}
+	// The first rows close once the following timestamps arrive; every frame
+	// after that is one 170 byte PES packet.
+	if len(lines) != 2+28 || lines[3] != "0.125133\t\t170\t1" {
+		t.Fatalf("frame rows got=%q", lines[2:])
+	}
+}
+
+func TestWritePlaylistIndex(t *testing.T) {
+	dir := t.TempDir()
+	result := bdinfo.Result{
+		Disc:         bdinfo.DiscInfo{Label: "MOVIE"},
+		MainPlaylist: "00800.MPLS",
+		Playlists: []bdinfo.PlaylistInfo{
+			{Name: "00800.MPLS", Length: "2:01:05.400", LengthSeconds: 7265.4, SizeBytes: 40_000_000_000},
+			{Name: "00001.MPLS", Length: "0:01:30.000", LengthSeconds: 90, SizeBytes: 200_000_000},
+		},
+		Report:     "report\n",
+		ReportPath: filepath.Join(dir, "BDINFO.MOVIE.txt"),
+	}
+	run := runOptions{playlistIndex: true}
+	if err := writeResultReport(run, result); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "BDINFO.MOVIE.index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index bdinfo.PlaylistIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+	if index.Label != "MOVIE" || index.MainPlaylist != "00800.MPLS" || len(index.Playlists) != 2 {
+		t.Fatalf("index got=%+v", index)
+	}
+	if got := index.Playlists[0]; !got.Main || got.Length != "2:01:05.400" || got.SizeBytes != 40_000_000_000 {
+		t.Fatalf("main entry got=%+v", got)
+	}
+	if index.Playlists[1].Main {
+		t.Fatalf("second entry marked main: %+v", index.Playlists[1])
+	}
+}
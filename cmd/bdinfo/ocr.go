@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/pkg/bdinfo"
+)
+
+// ocrCommand returns the Options.SubtitleOCR callback for --ocr-cmd: it runs
+// the command once per subtitle image, with the image as PNG on stdin, and
+// takes its stdout as the text. Only forced subtitles are read unless all is
+// set.
+func ocrCommand(ctx context.Context, args []string, all bool) func(bdinfo.SubtitleImage) (string, error) {
+	return func(img bdinfo.SubtitleImage) (string, error) {
+		if !img.Forced && !all {
+			return "", nil
+		}
+		var in bytes.Buffer
+		if err := png.Encode(&in, img.Image); err != nil {
+			return "", err
+		}
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stdin = &in
+		out, err := cmd.Output()
+		if err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+				return "", fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			return "", fmt.Errorf("%s: %w", args[0], err)
+		}
+		return string(out), nil
+	}
+}
+
+// writeSubtitleFiles writes one SRT per subtitle track of the selected (or
+// main) playlist for --ocr-out; {1} in the name is the track's PID and
+// language, e.g. 4608-eng. Tracks without captions are skipped.
+func writeSubtitleFiles(run runOptions, result bdinfo.Result, playlistName string) error {
+	playlist, ok := result.Playlist(playlistName)
+	if !ok {
+		return errors.New("--ocr-out: no playlist to take subtitles from")
+	}
+	var pids []uint16
+	languages := map[uint16]string{}
+	for _, caption := range result.Captions {
+		if _, ok := languages[caption.PID]; !ok {
+			pids = append(pids, caption.PID)
+		}
+		languages[caption.PID] = caption.Language
+	}
+	slices.Sort(pids)
+	written := 0
+	for _, pid := range pids {
+		var b bytes.Buffer
+		if err := playlist.WriteSRT(&b, result.Captions, pid); err != nil {
+			return err
+		}
+		if b.Len() == 0 {
+			continue
+		}
+		language := languages[pid]
+		if language == "" {
+			language = "und"
+		}
+		track := fmt.Sprintf("%d-%s", pid, language)
+		target := strings.ReplaceAll(strings.ReplaceAll(run.ocrOut, "{0}", result.Disc.Label), "{1}", track)
+		if err := run.checkWritable(target); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, b.Bytes(), 0o644); err != nil {
+			return err
+		}
+		run.outputs.add(target, "srt", b.Len())
+		fmt.Fprintf(os.Stderr, "Subtitles written: %s (%s)\n", run.pathMap.toHost(target), playlist.Name)
+		written++
+	}
+	if written == 0 {
+		fmt.Fprintf(os.Stderr, "Subtitles: no captions read in %s, nothing written\n", playlist.Name)
+	}
+	return nil
+}
+
+// subtitleTimingFile is the JSON written by --subtitle-timing-out.
+type subtitleTimingFile struct {
+	Playlist string                  `json:"playlist"`
+	Captions []bdinfo.SubtitleTiming `json:"captions"`
+}
+
+// writeSubtitleTiming writes the caption timeline of the selected (or main)
+// playlist for --subtitle-timing-out, as CSV for a .csv name and JSON
+// otherwise.
+func writeSubtitleTiming(run runOptions, result bdinfo.Result, playlistName string) error {
+	playlist, ok := result.Playlist(playlistName)
+	if !ok {
+		return errors.New("--subtitle-timing-out: no playlist to take subtitles from")
+	}
+	timeline := playlist.SubtitleTimeline(result.SubtitleTimings)
+	target := strings.ReplaceAll(run.subtitleTiming, "{0}", result.Disc.Label)
+	if err := run.checkWritable(target); err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	format := "json"
+	if strings.EqualFold(filepath.Ext(target), ".csv") {
+		format = "csv"
+		if err := bdinfo.WriteSubtitleTimingCSV(&b, timeline); err != nil {
+			return err
+		}
+	} else {
+		if timeline == nil {
+			timeline = []bdinfo.SubtitleTiming{}
+		}
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(subtitleTimingFile{Playlist: playlist.Name, Captions: timeline}); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(target, b.Bytes(), 0o644); err != nil {
+		return err
+	}
+	run.outputs.add(target, format, b.Len())
+	fmt.Fprintf(os.Stderr, "Subtitle timing written: %s (%s, %d captions)\n", run.pathMap.toHost(target), playlist.Name, len(timeline))
+	return nil
+}
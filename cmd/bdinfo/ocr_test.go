@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/pkg/bdinfo"
+)
+
+func TestOCRCommand(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+	img := bdinfo.SubtitleImage{Forced: true, Image: image.NewNRGBA(image.Rect(0, 0, 2, 2))}
+	ocr := ocrCommand(context.Background(), []string{"echo", "Hello"}, false)
+	if text, err := ocr(img); err != nil || text != "Hello\n" {
+		t.Fatalf("forced image read as %q, %v", text, err)
+	}
+	img.Forced = false
+	if text, err := ocr(img); err != nil || text != "" {
+		t.Fatalf("unforced image read as %q, %v without --ocr-all", text, err)
+	}
+	if text, _ := ocrCommand(context.Background(), []string{"echo", "Hello"}, true)(img); text != "Hello\n" {
+		t.Fatalf("unforced image read as %q with --ocr-all", text)
+	}
+	if _, err := ocrCommand(context.Background(), []string{"false"}, true)(img); err == nil {
+		t.Fatal("failing command returned no error")
+	}
+}
+
+func TestWriteSubtitleFiles(t *testing.T) {
+	dir := t.TempDir()
+	result := bdinfo.Result{
+		Disc:         bdinfo.DiscInfo{Label: "DISC"},
+		MainPlaylist: "00800.MPLS",
+		Playlists: []bdinfo.PlaylistInfo{
+			{Name: "00800.MPLS", Clips: []bdinfo.ClipInfo{
+				{Name: "00001.M2TS", StartSeconds: 0, InSeconds: 600, OutSeconds: 1200},
+				{Name: "00002.M2TS", StartSeconds: 600, InSeconds: 10, OutSeconds: 70},
+				{Name: "00009.M2TS", Angle: 1, StartSeconds: 600, InSeconds: 10, OutSeconds: 70},
+			}},
+		},
+		Captions: []bdinfo.Caption{
+			{File: "00001.M2TS", PID: 4608, Language: "eng", Forced: true, StartSeconds: 601.5, EndSeconds: 603, Text: "Stop."},
+			{File: "00001.M2TS", PID: 4608, Language: "eng", StartSeconds: 100, EndSeconds: 102, Text: "Cut away."},
+			{File: "00002.M2TS", PID: 4608, Language: "eng", StartSeconds: 68, Text: "Who?"},
+			{File: "00009.M2TS", PID: 4608, Language: "eng", StartSeconds: 20, EndSeconds: 21, Text: "Other angle."},
+			{File: "00002.M2TS", PID: 4609, StartSeconds: 20, EndSeconds: 21, Text: "Ici."},
+		},
+	}
+
+	run := runOptions{ocrOut: filepath.Join(dir, "{0}.{1}.srt")}
+	if err := writeSubtitleFiles(run, result, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "DISC.4608-eng.srt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "1\n00:00:01,500 --> 00:00:03,000\nStop.\n\n" +
+		"2\n00:10:58,000 --> 00:11:00,000\nWho?\n\n"
+	if string(data) != want {
+		t.Fatalf("SRT:\n%s\nwant:\n%s", data, want)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "DISC.4609-und.srt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1\n00:10:10,000 --> 00:10:11,000\nIci.\n\n"; string(data) != want {
+		t.Fatalf("SRT:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestWriteSubtitleTiming(t *testing.T) {
+	dir := t.TempDir()
+	result := bdinfo.Result{
+		Disc:         bdinfo.DiscInfo{Label: "DISC"},
+		MainPlaylist: "00800.MPLS",
+		Playlists: []bdinfo.PlaylistInfo{
+			{Name: "00800.MPLS", Clips: []bdinfo.ClipInfo{{Name: "00001.M2TS", StartSeconds: 0, InSeconds: 600, OutSeconds: 1200}}},
+		},
+		SubtitleTimings: []bdinfo.SubtitleTiming{
+			{File: "00001.M2TS", PID: 4608, Language: "eng", Forced: true, StartSeconds: 601.5, EndSeconds: 603},
+		},
+	}
+
+	run := runOptions{subtitleTiming: filepath.Join(dir, "{0}.csv")}
+	if err := writeSubtitleTiming(run, result, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "DISC.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "PID,Language,Forced,File,Start,End,Duration\n4608,eng,true,00001.M2TS,1.500,3.000,1.500\n"; string(data) != want {
+		t.Fatalf("CSV:\n%s\nwant:\n%s", data, want)
+	}
+
+	run.subtitleTiming = filepath.Join(dir, "{0}.json")
+	if err := writeSubtitleTiming(run, result, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "DISC.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file subtitleTimingFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	if file.Playlist != "00800.MPLS" || len(file.Captions) != 1 || file.Captions[0].StartSeconds != 1.5 {
+		t.Fatalf("JSON %+v", file)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// officialSettingsOff disables the import of official BDInfo settings.
+const officialSettingsOff = "none"
+
+// officialSettingFlags maps the settings official BDInfo saves in its
+// user.config to the flags they set. Settings with no counterpart, such as
+// LastPath, are skipped.
+var officialSettingFlags = map[string]string{
+	"AutosaveReport":            "autosavereport",
+	"DisplayChapterCount":       "displaychaptercount",
+	"EnableSSIF":                "enablessif",
+	"ExtendedStreamDiagnostics": "extendedstreamdiagnostics",
+	"FilterLoopingPlaylists":    "filterloopingplaylists",
+	"FilterShortPlaylists":      "filtershortplaylist",
+	"FilterShortPlaylistsValue": "filtershortplaylistvalue",
+	"GenerateFrameDataFile":     "generateframedatafile",
+	"GenerateStreamDiagnostics": "generatestreamdiagnostics",
+	"GenerateTextSummary":       "generatetextsummary",
+	"GroupByTime":               "groupbytime",
+	"IncludeVersionAndNotes":    "includeversionandnotes",
+	"KeepStreamOrder":           "keepstreamorder",
+	"UseImagePrefix":            "useimageprefix",
+	"UseImagePrefixValue":       "useimageprefixvalue",
+}
+
+// officialSetting is one <setting> of a .NET user.config.
+type officialSetting struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+// defaultOfficialSettingsPath returns the user.config official BDInfo last
+// saved on Windows (%LOCALAPPDATA%\Cinema Squid\BDInfo.exe_Url_*\<version>),
+// or "" when there is none.
+func defaultOfficialSettingsPath() string {
+	local := os.Getenv("LOCALAPPDATA")
+	if local == "" {
+		return ""
+	}
+	matches, _ := filepath.Glob(filepath.Join(local, "Cinema Squid", "BDInfo*", "*", "user.config"))
+	newest := ""
+	var newestTime int64
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		if t := info.ModTime().UnixNano(); newest == "" || t > newestTime {
+			newest, newestTime = match, t
+		}
+	}
+	return newest
+}
+
+// applyOfficialSettings sets the flags not changed yet (by the command line,
+// the environment or the config file) from an official BDInfo user.config:
+// path when set, the detected one otherwise. A detected file is announced on
+// stderr, since it changes the defaults without being asked for.
+func applyOfficialSettings(flags *pflag.FlagSet, path string) error {
+	if path == officialSettingsOff {
+		return nil
+	}
+	explicit := path != ""
+	if !explicit {
+		if path = defaultOfficialSettingsPath(); path == "" {
+			return nil
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("bdinfo settings: %w", err)
+	}
+	defer f.Close()
+
+	settings, err := parseOfficialSettings(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := applyOfficialSettingValues(flags, settings); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if !explicit {
+		fmt.Fprintf(os.Stderr, "Imported BDInfo settings: %s (use --bdinfo-settings=%s to skip)\n", path, officialSettingsOff)
+	}
+	return nil
+}
+
+// applyOfficialSettingValues sets the mapped flags that flags has and that are
+// not changed yet.
+func applyOfficialSettingValues(flags *pflag.FlagSet, settings []officialSetting) error {
+	for _, setting := range settings {
+		name, ok := officialSettingFlags[setting.Name]
+		if !ok {
+			continue
+		}
+		f := flags.Lookup(name)
+		if f == nil || f.Changed {
+			continue
+		}
+		if err := flags.Set(name, strings.TrimSpace(setting.Value)); err != nil {
+			return fmt.Errorf("%s: %w", setting.Name, err)
+		}
+	}
+	return nil
+}
+
+// parseOfficialSettings reads every <setting name="..."><value>...</value>
+// element of a .NET user.config, wherever its settings section is.
+func parseOfficialSettings(r io.Reader) ([]officialSetting, error) {
+	var settings []officialSetting
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "setting" {
+			continue
+		}
+		var setting officialSetting
+		if err := decoder.DecodeElement(&setting, &start); err != nil {
+			return nil, err
+		}
+		settings = append(settings, setting)
+	}
+	if len(settings) == 0 {
+		return nil, errors.New("no BDInfo settings found")
+	}
+	return settings, nil
+}
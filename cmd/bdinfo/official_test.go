@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+const testUserConfig = `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+    <userSettings>
+        <BDInfo.Properties.Settings>
+            <setting name="GenerateStreamDiagnostics" serializeAs="String">
+                <value>True</value>
+            </setting>
+            <setting name="EnableSSIF" serializeAs="String">
+                <value>False</value>
+            </setting>
+            <setting name="FilterShortPlaylistsValue" serializeAs="String">
+                <value>45</value>
+            </setting>
+            <setting name="KeepStreamOrder" serializeAs="String">
+                <value>True</value>
+            </setting>
+            <setting name="LastPath" serializeAs="String">
+                <value>D:\</value>
+            </setting>
+        </BDInfo.Properties.Settings>
+    </userSettings>
+</configuration>
+`
+
+func TestApplyOfficialSettings(t *testing.T) {
+	var (
+		diag, ssif, keepOrder bool
+		shortValue            int
+	)
+	flags := pflag.NewFlagSet("bdinfo", pflag.ContinueOnError)
+	flags.BoolVar(&diag, "generatestreamdiagnostics", false, "")
+	flags.BoolVar(&ssif, "enablessif", false, "")
+	flags.BoolVar(&keepOrder, "keepstreamorder", false, "")
+	flags.IntVar(&shortValue, "filtershortplaylistvalue", 20, "")
+	if err := flags.Parse([]string{"--keepstreamorder=false"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "user.config")
+	if err := os.WriteFile(path, []byte(testUserConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyOfficialSettings(flags, path); err != nil {
+		t.Fatalf("applyOfficialSettings() error: %v", err)
+	}
+	if !diag || ssif || shortValue != 45 || !flags.Changed("enablessif") {
+		t.Fatalf("settings not imported: diag=%v ssif=%v short=%d", diag, ssif, shortValue)
+	}
+	if keepOrder {
+		t.Fatal("command line should win over BDInfo settings")
+	}
+}
+
+func TestApplyOfficialSettings_Detect(t *testing.T) {
+	local := t.TempDir()
+	t.Setenv("LOCALAPPDATA", local)
+	dir := filepath.Join(local, "Cinema Squid", "BDInfo.exe_Url_abc123", "0.7.5.6")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user.config"), []byte(testUserConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := defaultOfficialSettingsPath(); got != filepath.Join(dir, "user.config") {
+		t.Fatalf("defaultOfficialSettingsPath() = %q", got)
+	}
+
+	var diag bool
+	flags := pflag.NewFlagSet("bdinfo", pflag.ContinueOnError)
+	flags.BoolVar(&diag, "generatestreamdiagnostics", false, "")
+	if err := applyOfficialSettings(flags, officialSettingsOff); err != nil || diag {
+		t.Fatalf("--bdinfo-settings=none still imported: diag=%v err=%v", diag, err)
+	}
+}
+
+func TestParseOfficialSettings_Invalid(t *testing.T) {
+	for _, bad := range []string{"not xml <", "<configuration></configuration>"} {
+		if _, err := parseOfficialSettings(strings.NewReader(bad)); err == nil {
+			t.Fatalf("parseOfficialSettings(%q) should fail", bad)
+		}
+	}
+
+	flags := pflag.NewFlagSet("bdinfo", pflag.ContinueOnError)
+	flags.Int("filtershortplaylistvalue", 20, "")
+	err := applyOfficialSettingValues(flags, []officialSetting{{Name: "FilterShortPlaylistsValue", Value: "long"}})
+	if err == nil || !strings.Contains(err.Error(), "FilterShortPlaylistsValue") {
+		t.Fatalf("bad value error = %v", err)
+	}
+}
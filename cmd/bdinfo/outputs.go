@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// outputFile is one file written for a disc, as listed by --events.
+type outputFile struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// writtenEvent is the --events line printed once a disc's outputs are on disk.
+// Disc is empty for the combined report of a batch; Error is set when the disc
+// failed after some of its files were written.
+type writtenEvent struct {
+	Event   string       `json:"event"`
+	Disc    string       `json:"disc,omitempty"`
+	Outputs []outputFile `json:"outputs"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// outputLog collects the files written for the current disc and prints them as
+// a writtenEvent. A nil log (no --events) records nothing.
+type outputLog struct {
+	enc     *json.Encoder
+	pathMap pathMap
+	files   []outputFile
+}
+
+func newOutputLog(w io.Writer, pm pathMap) *outputLog {
+	return &outputLog{enc: json.NewEncoder(w), pathMap: pm}
+}
+
+// add records a written file. Reports sent to stdout ("-") are not files.
+func (l *outputLog) add(path string, format string, size int) {
+	if l == nil || path == "" || path == "-" {
+		return
+	}
+	l.files = append(l.files, outputFile{Path: l.pathMap.toHost(path), Format: format, Bytes: int64(size)})
+}
+
+// flush prints the event for disc (already host-mapped) and starts a new one.
+func (l *outputLog) flush(disc string, err error) error {
+	if l == nil {
+		return nil
+	}
+	event := writtenEvent{Event: "written", Disc: disc, Outputs: l.files}
+	if event.Outputs == nil {
+		event.Outputs = []outputFile{}
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	l.files = nil
+	return l.enc.Encode(event)
+}
+
+// reportFormat names the format of a report file from its extension, as
+// RenderReport picks it.
+func reportFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return "xml"
+	case ".csv":
+		return "csv"
+	default:
+		return "text"
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestOutputLog(t *testing.T) {
+	var buf bytes.Buffer
+	pm, err := parsePathMap([]string{"/mnt/nas:/media"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := newOutputLog(&buf, pm)
+	log.add("/media/BDInfo_DISC.xml", reportFormat("/media/BDInfo_DISC.xml"), 1200)
+	log.add("-", "text", 10)
+	log.add("/media/DISC.txt", "ogm", 64)
+	if err := log.flush("/mnt/nas/Disc", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.flush("/mnt/nas/Other", errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := json.NewDecoder(&buf)
+	var first, second writtenEvent
+	if err := dec.Decode(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode(&second); err != nil {
+		t.Fatal(err)
+	}
+	want := []outputFile{
+		{Path: "/mnt/nas/BDInfo_DISC.xml", Format: "xml", Bytes: 1200},
+		{Path: "/mnt/nas/DISC.txt", Format: "ogm", Bytes: 64},
+	}
+	if first.Event != "written" || first.Disc != "/mnt/nas/Disc" || first.Error != "" || len(first.Outputs) != len(want) {
+		t.Fatalf("first event got=%+v", first)
+	}
+	for i := range want {
+		if first.Outputs[i] != want[i] {
+			t.Fatalf("output %d got=%+v want=%+v", i, first.Outputs[i], want[i])
+		}
+	}
+	if second.Disc != "/mnt/nas/Other" || second.Error != "boom" || second.Outputs == nil || len(second.Outputs) != 0 {
+		t.Fatalf("second event got=%+v", second)
+	}
+
+	var nilLog *outputLog
+	nilLog.add("/media/x", "text", 1)
+	if err := nilLog.flush("disc", nil); err != nil {
+		t.Fatalf("nil log flush err=%v", err)
+	}
+}
+
+func TestReportFormat(t *testing.T) {
+	for path, want := range map[string]string{"a.bdinfo": "text", "a.XML": "xml", "a.csv": "csv", "a.txt": "text"} {
+		if got := reportFormat(path); got != want {
+			t.Errorf("reportFormat(%q)=%q want=%q", path, got, want)
+		}
+	}
+}
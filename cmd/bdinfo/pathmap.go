@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+)
+
+// pathMapping translates a container path prefix back to the host path it is
+// mounted from.
+type pathMapping struct {
+	host      string
+	container string
+}
+
+// pathMap rewrites paths recorded in outputs (report locations, disc paths in
+// JSON, the batch summary) so they make sense outside the container.
+type pathMap []pathMapping
+
+// parsePathMap parses --path-map values of the form host:container. The split is
+// on the last colon so Windows host paths such as C:\media:/media work.
+func parsePathMap(values []string) (pathMap, error) {
+	var m pathMap
+	for _, value := range values {
+		i := strings.LastIndex(value, ":")
+		if i <= 0 || i == len(value)-1 {
+			return nil, fmt.Errorf("--path-map %q: want host:container", value)
+		}
+		m = append(m, pathMapping{host: value[:i], container: filepath.ToSlash(filepath.Clean(value[i+1:]))})
+	}
+	return m, nil
+}
+
+// toHost returns p with the longest matching container prefix replaced by its
+// host path. Relative paths, such as reports written to the default ".", are
+// resolved against the working directory first. Paths outside every mapping
+// are returned unchanged; remote URLs only lose their password.
+func (m pathMap) toHost(p string) string {
+	if fs.IsRemoteURL(p) {
+		return fs.RedactURL(p)
+	}
+	if len(m) == 0 || p == "" || p == "-" {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	clean := filepath.ToSlash(abs)
+	best := -1
+	for i, mapping := range m {
+		if !pathWithinPrefix(mapping.container, clean) {
+			continue
+		}
+		if best < 0 || len(mapping.container) > len(m[best].container) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return p
+	}
+	host := m[best].host
+	rest := strings.TrimPrefix(clean, m[best].container)
+	if rest == "" {
+		return host
+	}
+	rest = strings.TrimLeft(rest, "/")
+	// Join with the host's own separator.
+	sep := "/"
+	if strings.Contains(host, `\`) {
+		sep = `\`
+		rest = strings.ReplaceAll(rest, "/", `\`)
+	}
+	return strings.TrimRight(host, `/\`) + sep + rest
+}
+
+// pathWithinPrefix reports whether p is prefix or lies below it, lexically.
+func pathWithinPrefix(prefix string, p string) bool {
+	if prefix == "/" {
+		return strings.HasPrefix(p, "/")
+	}
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
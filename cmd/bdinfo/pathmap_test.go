@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestPathMapToHost(t *testing.T) {
+	m, err := parsePathMap([]string{"/mnt/nas/movies:/media", "/mnt/nas/movies/4k:/media/uhd", `D:\reports:/out`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "/media/Disc/BDMV", want: "/mnt/nas/movies/Disc/BDMV"},
+		{in: "/media", want: "/mnt/nas/movies"},
+		{in: "/media/uhd/Disc", want: "/mnt/nas/movies/4k/Disc"},
+		{in: "/out/BDInfo_DISC.bdinfo", want: `D:\reports\BDInfo_DISC.bdinfo`},
+		{in: "/mediaX/Disc", want: "/mediaX/Disc"},
+		{in: "-", want: "-"},
+	}
+	for _, tt := range tests {
+		if got := m.toHost(tt.in); got != tt.want {
+			t.Errorf("toHost(%q)=%q want=%q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParsePathMapInvalid(t *testing.T) {
+	for _, value := range []string{"/media", ":/media", "/mnt:"} {
+		if _, err := parsePathMap([]string{value}); err == nil {
+			t.Errorf("parsePathMap(%q) should fail", value)
+		}
+	}
+}
+
+func TestPathMapToHostRelative(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	m, err := parsePathMap([]string{"/mnt/nas/reports:" + dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.toHost("BDInfo_DISC.bdinfo"), "/mnt/nas/reports/BDInfo_DISC.bdinfo"; got != want {
+		t.Fatalf("toHost() of a relative report got=%q want=%q", got, want)
+	}
+	if got, want := m.toHost("../elsewhere.txt"), "../elsewhere.txt"; got != want {
+		t.Fatalf("toHost() outside the mapping got=%q want=%q", got, want)
+	}
+}
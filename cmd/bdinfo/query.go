@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	bdinfo "github.com/autobrr/go-bdinfo/pkg/bdinfo"
+)
+
+var (
+	queryFields   []string
+	queryPlaylist string
+	queryQuick    bool
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query <path> --field <name> [--field <name> ...]",
+	Short: "Print single values of a disc for shell scripting",
+	Long: "Scan a disc and print the requested fields of its main playlist, one value per line in the order given, " +
+		"instead of a report. Values that do not apply print as empty lines.\n\n" +
+		"Fields: " + strings.Join(bdinfo.FieldNames(), ", "),
+	Example: "  bdinfo query /mnt/disc --field runtime_minutes --field video_codec",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runQuery,
+}
+
+func init() {
+	queryCmd.Flags().StringSliceVar(&queryFields, "field", nil, "Field to print (repeat or separate with commas)")
+	queryCmd.Flags().StringVar(&queryPlaylist, "playlist", "", "Playlist to query instead of the main one (e.g. 00800 or 00800.MPLS)")
+	queryCmd.Flags().BoolVar(&queryQuick, "quick", false, "Read only the first 8 MiB of each stream file (nominal bitrates)")
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	if err := applyEnv(cmd.Flags(), os.LookupEnv); err != nil {
+		return err
+	}
+	if len(queryFields) == 0 {
+		return errors.New("at least one --field is required")
+	}
+	// Check the names before scanning, which can take minutes.
+	names := bdinfo.FieldNames()
+	for _, name := range queryFields {
+		if !slices.Contains(names, strings.ToLower(strings.TrimSpace(name))) {
+			return fmt.Errorf("unknown field %q (supported: %s)", name, strings.Join(names, ", "))
+		}
+	}
+
+	s := settings.Default(".")
+	s.ReportFileName = "-"
+	s.QuickScan = queryQuick
+	result, err := bdinfo.Run(cmd.Context(), bdinfo.Options{Path: args[0], Settings: toLibrarySettings(s)})
+	if err != nil {
+		return err
+	}
+
+	playlist := ""
+	if queryPlaylist != "" {
+		playlist = normalizePlaylistName(queryPlaylist)
+	}
+	out := cmd.OutOrStdout()
+	for _, name := range queryFields {
+		value, err := result.Field(name, playlist)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, value)
+	}
+	return nil
+}
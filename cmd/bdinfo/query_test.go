@@ -0,0 +1,22 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunQueryChecksFieldsBeforeScanning(t *testing.T) {
+	t.Cleanup(func() { queryFields = nil })
+
+	queryFields = nil
+	if err := runQuery(queryCmd, []string{t.TempDir()}); err == nil || !strings.Contains(err.Error(), "--field") {
+		t.Fatalf("runQuery() without fields got err=%v", err)
+	}
+
+	// An unknown name fails before the missing disc is read.
+	queryFields = []string{"runtime_minutes", "runtime_hours"}
+	err := runQuery(queryCmd, []string{"/nonexistent/disc"})
+	if err == nil || !strings.Contains(err.Error(), `unknown field "runtime_hours"`) || !strings.Contains(err.Error(), "runtime_minutes") {
+		t.Fatalf("runQuery() with an unknown field got err=%v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	bdinfo "github.com/autobrr/go-bdinfo/pkg/bdinfo"
+)
+
+var renderFormat string
+
+var renderCmd = &cobra.Command{
+	Use:   "render <scan>",
+	Short: "Render a report from a saved scan",
+	Long: "Render a report from a scan saved with --save-scan, without reading the disc again.\n\n" +
+		"Report flags (--main, --summaryonly, --reportlanguage, ...) apply as they do when scanning; " +
+		"flags that change what is read from the disc were fixed when the scan was saved.",
+	Args: cobra.ExactArgs(1),
+	RunE: runRender,
+}
+
+func init() {
+	renderCmd.Flags().StringVar(&renderFormat, "format", "text", "Output format: text, json, oneline, ffprobe")
+	renderCmd.Flags().StringVar(&opts.configPath, "config", "", "Config file with default flag values (default: ~/.config/bdinfo/config.toml)")
+	renderCmd.Flags().StringVar(&opts.bdinfoSettings, "bdinfo-settings", "", "Official BDInfo user.config to import settings from (default: detected on Windows; none to skip)")
+	addReportFlags(renderCmd.Flags())
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	if err := applyEnv(flags, os.LookupEnv); err != nil {
+		return err
+	}
+	if err := applyConfigFile(cmd, opts.configPath); err != nil {
+		return err
+	}
+	if err := applyOfficialSettings(flags, opts.bdinfoSettings); err != nil {
+		return err
+	}
+	format := bdinfo.Format(strings.ToLower(strings.TrimSpace(renderFormat)))
+	if format != bdinfo.FormatText && format != bdinfo.FormatJSON && format != bdinfo.FormatOneLine && format != bdinfo.FormatFFprobe {
+		return fmt.Errorf("unsupported format %q (supported: text, json, oneline, ffprobe)", renderFormat)
+	}
+
+	// Without -o the report goes to stdout rather than next to the scan.
+	s := settings.Default(".")
+	s.ReportFileName = "-"
+	if err := applyReportFlags(flags, &s); err != nil {
+		return err
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	full, err := bdinfo.LoadScan(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	output, err := bdinfo.Render(full, format, toLibrarySettings(s))
+	if err != nil {
+		return err
+	}
+	if err := writeReport(s.ReportFileName, output); err != nil {
+		return err
+	}
+	if s.ReportFileName != "-" {
+		fmt.Printf("Report written: %s\n", s.ReportFileName)
+	}
+	return nil
+}
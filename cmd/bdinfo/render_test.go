@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	bdinfo "github.com/autobrr/go-bdinfo/pkg/bdinfo"
+)
+
+func TestSaveScanRendersSameReport(t *testing.T) {
+	root := t.TempDir()
+	disc := filepath.Join(root, "MOVIE")
+	for _, dir := range []string{"PLAYLIST", "CLIPINF", "STREAM"} {
+		if err := os.MkdirAll(filepath.Join(disc, "BDMV", dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := settings.Default(root)
+	run := runOptions{saveScan: filepath.Join(root, "{0}.bdcache")}
+	result, err := scanDisc(context.Background(), disc, s, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(root, "MOVIE.bdcache"))
+	if err != nil {
+		t.Fatalf("saved scan not named after the disc label: %v", err)
+	}
+	defer f.Close()
+	full, err := bdinfo.LoadScan(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := bdinfo.Render(full, bdinfo.FormatText, toLibrarySettings(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != result.Report {
+		t.Fatalf("rendered report differs from the scan's report:\n%s\n---\n%s", got, result.Report)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package main
+
+import "errors"
+
+// restartSelf fails on platforms that cannot start a new process.
+func restartSelf(string) error {
+	return errors.New("restarting is not supported on this platform")
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// restartSelf replaces the process with a fresh bdinfo that resumes the batch
+// from stateFile.
+func restartSelf(stateFile string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := append([]string{os.Args[0]}, restartArgs(os.Args[1:], stateFile)...)
+	return syscall.Exec(exe, args, os.Environ())
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	bdinfo "github.com/autobrr/go-bdinfo/pkg/bdinfo"
+)
+
+// residentBytes returns the resident set size of the process. Without
+// /proc/self/statm it falls back to the memory the Go runtime obtained from
+// the OS.
+func residentBytes() uint64 {
+	if data, err := os.ReadFile("/proc/self/statm"); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) > 1 {
+			if pages, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				return pages * uint64(os.Getpagesize())
+			}
+		}
+	}
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.Sys
+}
+
+// overMaxRSS returns heap freed by the finished disc to the OS and reports
+// whether the process is still above the --max-rss limit.
+func overMaxRSS(limit uint64) bool {
+	debug.FreeOSMemory()
+	return residentBytes() > limit
+}
+
+// batchState is the progress of a batch handed to the restarted process by
+// the --max-rss guard: the discs still to scan and everything the combined
+// report and the summary need from the discs already done.
+type batchState struct {
+	Path    string              `json:"path"`
+	Targets []string            `json:"targets"`
+	Next    int                 `json:"next"`
+	Discs   []bdinfo.DiscResult `json:"discs,omitempty"`
+	Entries []batchStateEntry   `json:"entries"`
+}
+
+// batchStateEntry is a batchEntry with exported fields.
+type batchStateEntry struct {
+	Disc     string `json:"disc"`
+	Playlist string `json:"playlist,omitempty"`
+	Length   string `json:"length,omitempty"`
+	Size     uint64 `json:"size,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+func newBatchState(path string, targets []string, next int, discs []bdinfo.DiscResult, entries []batchEntry) batchState {
+	state := batchState{Path: path, Targets: targets, Next: next, Discs: discs}
+	for _, entry := range entries {
+		saved := batchStateEntry{Disc: entry.disc, Playlist: entry.playlist, Length: entry.length, Size: entry.size, Status: entry.status}
+		if entry.err != nil {
+			saved.Error = entry.err.Error()
+		}
+		state.Entries = append(state.Entries, saved)
+	}
+	return state
+}
+
+func (s batchState) entries() []batchEntry {
+	entries := make([]batchEntry, 0, len(s.Targets))
+	for _, saved := range s.Entries {
+		entry := batchEntry{disc: saved.Disc, playlist: saved.Playlist, length: saved.Length, size: saved.Size, status: saved.Status}
+		if saved.Error != "" {
+			entry.err = errors.New(saved.Error)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// writeBatchState saves state to a temporary file and returns its name.
+func writeBatchState(cfg settings.Settings, state batchState) (string, error) {
+	f, err := cfg.CreateTemp("bdinfo-batch-*.json")
+	if err != nil {
+		return "", err
+	}
+	if err := json.NewEncoder(f).Encode(state); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// readBatchState loads and removes the state file written by writeBatchState.
+func readBatchState(name string, path string) (batchState, error) {
+	var state batchState
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return state, fmt.Errorf("--batch-resume: %w", err)
+	}
+	_ = os.Remove(name)
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("--batch-resume: %w", err)
+	}
+	if state.Path != path || state.Next > len(state.Targets) {
+		return state, fmt.Errorf("--batch-resume: %s does not continue a batch of %s", name, path)
+	}
+	return state, nil
+}
+
+// restartArgs returns the command line of the current process with the
+// state file passed as --batch-resume, replacing an earlier one.
+func restartArgs(args []string, stateFile string) []string {
+	out := make([]string, 0, len(args)+2)
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--batch-resume":
+			i++
+			continue
+		case strings.HasPrefix(args[i], "--batch-resume="):
+			continue
+		}
+		out = append(out, args[i])
+	}
+	return append(out, "--batch-resume", stateFile)
+}
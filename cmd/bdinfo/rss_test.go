@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+func TestResidentBytes(t *testing.T) {
+	if got := residentBytes(); got == 0 {
+		t.Fatal("residentBytes() = 0")
+	}
+}
+
+func TestRestartArgs(t *testing.T) {
+	got := restartArgs([]string{"/discs", "--batch-resume", "old.json", "--max-rss=512", "--batch-resume=older.json"}, "new.json")
+	want := []string{"/discs", "--max-rss=512", "--batch-resume", "new.json"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("restartArgs() got=%q want=%q", got, want)
+	}
+}
+
+func TestRunForPath_MaxRSSRestart(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"DISC_A/BDMV", "DISC_B/BDMV", "DISC_C/BDMV"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := settings.Default(t.TempDir())
+	cfg.TempDir = t.TempDir()
+
+	var stateFile string
+	run := runOptions{maxRSS: 1, restart: func(name string) error {
+		stateFile = name
+		return nil
+	}}
+	if err := runForPath(context.Background(), root, cfg, run); err != nil {
+		t.Fatalf("runForPath() before restart error = %v", err)
+	}
+	if stateFile == "" {
+		t.Fatal("runForPath() did not restart above --max-rss")
+	}
+
+	// The restarted process scans the remaining discs and summarizes all three.
+	run = runOptions{batchResume: stateFile}
+	err := runForPath(context.Background(), root, cfg, run)
+	if err == nil || err.Error() != "3 of 3 discs failed" {
+		t.Fatalf("runForPath() after restart error = %v", err)
+	}
+	if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+		t.Fatalf("state file %s left behind: %v", stateFile, err)
+	}
+
+	if _, err := readBatchState(stateFile, root); err == nil || !strings.Contains(err.Error(), "--batch-resume") {
+		t.Fatalf("readBatchState(missing) error = %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package bdrom
+
+import (
+	"github.com/autobrr/go-bdinfo/internal/fs"
+)
+
+// backupFile returns the copy of file kept in BDMV/BACKUP/<dir>, or nil when
+// the disc has none.
+func (b *BDROM) backupFile(dir string, file fs.FileInfo) fs.FileInfo {
+	if b.bdmvDirectory == nil || file == nil {
+		return nil
+	}
+	backup, err := b.bdmvDirectory.GetDirectory("BACKUP")
+	if err != nil {
+		return nil
+	}
+	sub, err := backup.GetDirectory(dir)
+	if err != nil {
+		return nil
+	}
+	copy, err := sub.GetFile(file.Name())
+	if err != nil {
+		return nil
+	}
+	return copy
+}
+
+// scanStreamClipFile scans clip and, when that fails and BDMV/BACKUP/CLIPINF
+// holds a copy that parses, takes the copy instead. primaryErr is the error of
+// the primary file when the backup replaced it.
+func (b *BDROM) scanStreamClipFile(clip *StreamClipFile) (primaryErr, err error) {
+	err = clip.Scan()
+	if err == nil {
+		return nil, nil
+	}
+	backup := b.backupFile("CLIPINF", clip.FileInfo)
+	if backup == nil {
+		return nil, err
+	}
+	retry := NewStreamClipFile(backup)
+	if retry.Scan() != nil {
+		return nil, err
+	}
+	retry.Name = clip.Name
+	*clip = *retry
+	return err, nil
+}
+
+// scanPlaylistFile scans playlist and, when that fails and
+// BDMV/BACKUP/PLAYLIST holds a copy that parses, takes the copy instead.
+// primaryErr is the error of the primary file when the backup replaced it.
+func (b *BDROM) scanPlaylistFile(playlist *PlaylistFile) (primaryErr, err error) {
+	err = playlist.Scan(b.StreamFiles, b.StreamClipFiles)
+	if err == nil {
+		return nil, nil
+	}
+	backup := b.backupFile("PLAYLIST", playlist.FileInfo)
+	if backup == nil {
+		return nil, err
+	}
+	retry := NewPlaylistFile(backup, playlist.Settings)
+	retry.Name = playlist.Name
+	retry.ReachableFromTitle1 = playlist.ReachableFromTitle1
+	retry.ChapterNames = playlist.ChapterNames
+	if retry.Scan(b.StreamFiles, b.StreamClipFiles) != nil {
+		return nil, err
+	}
+	*playlist = *retry
+	return err, nil
+}
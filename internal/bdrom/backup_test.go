@@ -0,0 +1,62 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+func TestScan_BackupFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"PLAYLIST", "CLIPINF", "STREAM", "BACKUP/PLAYLIST", "BACKUP/CLIPINF"} {
+		if err := os.MkdirAll(filepath.Join(root, "BDMV", dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	good := testMPLS([][2]uint32{{0, 45000 * 60}})
+	write := func(path string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, "BDMV", path), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clpi := make([]byte, 256)
+	copy(clpi, "HDMV0200")
+	binary.BigEndian.PutUint32(clpi[12:], 200) // ProgramInfo with no streams
+	copy(clpi[clipInfoOffset+151:], "HDMV")
+	binary.BigEndian.PutUint32(clpi[200:], 12)
+	write("CLIPINF/00001.clpi", clpi[:16])
+	write("BACKUP/CLIPINF/00001.clpi", clpi)
+	write("PLAYLIST/00001.mpls", []byte("XXXX0000 corrupt playlist"))
+	write("BACKUP/PLAYLIST/00001.mpls", good)
+	write("PLAYLIST/00002.mpls", []byte("XXXX0000 corrupt, no backup"))
+
+	rom, err := New(root, settings.Default(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rom.Close()
+	scan := rom.Scan()
+
+	if _, ok := scan.BackupFiles["00001.CLPI"]; !ok || rom.StreamClipFiles["00001.CLPI"].FormatIdentifier != "HDMV" {
+		t.Fatalf("clip info not read from backup: %v", scan.BackupFiles)
+	}
+	if _, ok := scan.BackupFiles["00001.MPLS"]; !ok {
+		t.Fatalf("BackupFiles = %v, want 00001.MPLS (errors %v)", scan.BackupFiles, scan.FileErrors)
+	}
+	if err := scan.FileErrors["00001.MPLS"]; err != nil {
+		t.Fatalf("playlist read from backup still failed: %v", err)
+	}
+	if pl := rom.PlaylistFiles["00001.MPLS"]; pl.Name != "00001.MPLS" || pl.TotalLength() != 60 {
+		t.Fatalf("backup playlist name=%s length=%v", pl.Name, pl.TotalLength())
+	}
+	if scan.FileErrors["00002.MPLS"] == nil {
+		t.Fatal("corrupt playlist without backup scanned without error")
+	}
+	if _, ok := scan.BackupFiles["00002.MPLS"]; ok {
+		t.Fatal("playlist without backup listed as read from BACKUP")
+	}
+}
@@ -0,0 +1,59 @@
+package bdrom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// testRPLS is testMPLS with the BDAV playlist type indicator.
+func testRPLS(items [][2]uint32) []byte {
+	data := testMPLS(items)
+	copy(data, "PLST0200")
+	return data
+}
+
+func TestNew_BDAVStructure(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"PLAYLIST", "CLIPINF", "STREAM"} {
+		if err := os.MkdirAll(filepath.Join(root, "BDAV", dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "BDAV", "PLAYLIST", "00001.rpls"), testRPLS([][2]uint32{{0, 45000}}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rom, err := New(root, settings.Default(""))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer rom.Close()
+	if !rom.IsBDAV {
+		t.Fatal("IsBDAV not set for a BDAV directory")
+	}
+	if _, ok := rom.PlaylistFiles["00001.RPLS"]; !ok {
+		t.Fatalf("PlaylistFiles got=%v want 00001.RPLS", rom.PlaylistOrder)
+	}
+}
+
+func TestPlaylistScan_BDAV(t *testing.T) {
+	audio := &stream.AudioStream{}
+	audio.PID = 4352
+	audio.StreamType = stream.StreamTypeAC3Audio
+	clipFiles := map[string]*StreamClipFile{"00001.CLPI": {Name: "00001.CLPI", Streams: map[uint16]stream.Info{4352: audio}}}
+
+	p := NewPlaylistFile(&memFileInfo{name: "00001.rpls", data: testRPLS([][2]uint32{{0, 45000 * 60}})}, settings.Default(""))
+	if err := p.Scan(map[string]*StreamFile{}, clipFiles); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	if !p.IsBDAV || p.TotalLength() != 60 {
+		t.Fatalf("got IsBDAV=%v TotalLength()=%v", p.IsBDAV, p.TotalLength())
+	}
+	if len(p.AudioStreams) != 1 || p.AudioStreams[0].IsHidden || p.HasHiddenTracks {
+		t.Fatalf("clip info streams should be reported unhidden, got %+v", p.AudioStreams)
+	}
+}
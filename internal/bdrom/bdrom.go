@@ -2,7 +2,9 @@ package bdrom
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,7 +12,6 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -35,6 +36,9 @@ type BDROM struct {
 	metaDirectory     fs.DirectoryInfo
 	bdjoDirectory     fs.DirectoryInfo
 	snpDirectory      fs.DirectoryInfo
+	// handles caps the files open at once (Settings.MaxOpenFiles); nil when
+	// unlimited.
+	handles *fs.HandleBudget
 
 	DirectoryRoot     string
 	DirectoryBDMV     string
@@ -56,6 +60,18 @@ type BDROM struct {
 	Is3D        bool
 	Is50Hz      bool
 	IsUHD       bool
+	// IsBDAV is set for recorder and camcorder discs that use the BDAV
+	// directory (PLAYLIST/*.rpls and *.vpls) instead of BDMV.
+	IsBDAV bool
+	// MKBVersion is the version of the AACS Media Key Block on the disc, or 0
+	// when it has no readable AACS/MKB_RO.inf.
+	MKBVersion int
+	// BDPlusDate is the build date (YYYY-MM-DD) of the BD+ content code, which
+	// identifies the SVM generation, or "" when the disc has none.
+	BDPlusDate string
+	// IsOpticalMedia is set when the disc folder is read straight from an optical
+	// drive; scans then run sequentially with a smaller read-ahead.
+	IsOpticalMedia bool
 
 	PlaylistFiles    map[string]*PlaylistFile
 	PlaylistOrder    []string
@@ -63,12 +79,33 @@ type BDROM struct {
 	StreamFiles      map[string]*StreamFile
 	InterleavedFiles map[string]*InterleavedFile
 
+	// IndexTitles lists the index.bdmv entries and the playlists their movie
+	// objects play (empty when index.bdmv/MovieObject.bdmv cannot be parsed).
+	IndexTitles []IndexEntry
+
+	// JARImages holds the images harvested from BD-J JARs when
+	// Settings.HarvestJARImages is enabled.
+	JARImages []JARImage
+
+	// BitrateTrace, when set, receives an audit of the packet windows and inputs
+	// behind every bitrate figure produced by the scan.
+	BitrateTrace *BitrateTrace
+
+	// PGSCaptions, when set, receives every subtitle image decoded from the
+	// presentation graphics streams during Scan. It is called from the scan
+	// workers, concurrently for different stream files.
+	PGSCaptions func(PGSCaption)
+
 	cleanup func()
 }
 
 type ScanResult struct {
 	ScanError  error
 	FileErrors map[string]error
+	// BackupFiles lists the playlists and clip infos read from their
+	// BDMV/BACKUP copies, with the error of the primary file.
+	BackupFiles map[string]error
+	Stats       ScanStats
 }
 
 type ScanProgressStage string
@@ -87,17 +124,47 @@ type ScanProgress struct {
 	Total          int
 	ProcessedBytes uint64
 	TotalBytes     uint64
+
+	// File names the stream file a ScanStageStream update comes from, with its
+	// own progress; empty for stage-level updates.
+	File               string
+	FileProcessedBytes uint64
+	FileTotalBytes     uint64
 }
 
 type ScanProgressFunc func(ScanProgress)
 
 const maxScanWorkers = 8
 
-func scanWorkerLimit(total int, totalBytes uint64) int {
-	if override := os.Getenv("BDINFO_WORKERS"); override != "" {
-		if parsed, err := strconv.Atoi(override); err == nil && parsed > 0 {
-			return clampWorkers(parsed, total)
-		}
+// I/O modes accepted by Settings.IOMode.
+const (
+	IOModeAuto       = "auto"       // heuristics: one stream worker, one worker on optical drives
+	IOModeSequential = "sequential" // one worker for every scan stage
+	IOModeParallel   = "parallel"   // the most workers allowed for every stage, for fast storage
+)
+
+// ValidIOMode reports whether mode is a supported Settings.IOMode value.
+func ValidIOMode(mode string) bool {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", IOModeAuto, IOModeSequential, IOModeParallel:
+		return true
+	default:
+		return false
+	}
+}
+
+// opticalReadChunk is the stream read-ahead used on optical drives, where large
+// reads mostly add latency without improving throughput.
+const opticalReadChunk = 1 << 20
+
+// lowMemoryReadChunk is the stream read-ahead of Settings.LowMemory scans.
+const lowMemoryReadChunk = 256 << 10
+
+// scanWorkerLimit picks the worker count for total jobs; override > 0 (from
+// Settings.Workers) replaces the heuristic.
+func scanWorkerLimit(total int, totalBytes uint64, override int) int {
+	if override > 0 {
+		return clampWorkers(override, total)
 	}
 	if totalBytes > 0 {
 		// Stream scans are storage-bound in this workload. Sequential single-worker reads
@@ -108,6 +175,29 @@ func scanWorkerLimit(total int, totalBytes uint64) int {
 	return clampWorkers(limit, total)
 }
 
+// workerLimit is scanWorkerLimit with Settings.IOMode applied: sequential and
+// parallel replace the heuristic, and auto forces optical media to a single
+// worker to avoid seek-thrash on the drive. Settings.LowMemory keeps a single
+// worker in every mode. Settings.Workers overrides all.
+func (b *BDROM) workerLimit(total int, totalBytes uint64) int {
+	if b.Settings.Workers > 0 {
+		return clampWorkers(b.Settings.Workers, total)
+	}
+	if b.Settings.LowMemory {
+		return clampWorkers(1, total)
+	}
+	switch strings.ToLower(strings.TrimSpace(b.Settings.IOMode)) {
+	case IOModeSequential:
+		return clampWorkers(1, total)
+	case IOModeParallel:
+		return clampWorkers(maxScanWorkers, total)
+	}
+	if b.IsOpticalMedia {
+		return clampWorkers(1, total)
+	}
+	return scanWorkerLimit(total, totalBytes, 0)
+}
+
 func clampWorkers(limit int, total int) int {
 	if limit < 1 {
 		limit = 1
@@ -216,18 +306,46 @@ func orderedStreamFiles(files map[string]*StreamFile) []*StreamFile {
 func streamFilesTotalSize(streamFiles []*StreamFile) uint64 {
 	var total uint64
 	for _, streamFile := range streamFiles {
-		if streamFile == nil {
-			continue
-		}
-		if streamFile.Size > 0 {
-			total += uint64(streamFile.Size)
-		} else if streamFile.FileInfo != nil {
-			total += uint64(streamFile.FileInfo.Length())
-		}
+		total += streamFileSize(streamFile)
 	}
 	return total
 }
 
+func streamFileSize(streamFile *StreamFile) uint64 {
+	switch {
+	case streamFile == nil:
+		return 0
+	case streamFile.Size > 0:
+		return uint64(streamFile.Size)
+	case streamFile.FileInfo != nil:
+		return uint64(streamFile.FileInfo.Length())
+	default:
+		return 0
+	}
+}
+
+// streamReadSize returns how much of streamFile the stream scan reads: all of
+// it, or its head for a quick scan.
+func (b *BDROM) streamReadSize(streamFile *StreamFile) uint64 {
+	size := streamFileSize(streamFile)
+	if b.Settings.QuickScan {
+		return min(size, quickScanBytes)
+	}
+	return size
+}
+
+// streamFileProgress attaches the per-file fields of streamFile, of which the
+// scan reads total bytes, to update.
+func streamFileProgress(update ScanProgress, streamFile *StreamFile, processed, total uint64) ScanProgress {
+	update.File = streamFile.Name
+	update.FileTotalBytes = total
+	update.FileProcessedBytes = processed
+	if update.FileTotalBytes > 0 {
+		update.FileProcessedBytes = min(processed, update.FileTotalBytes)
+	}
+	return update
+}
+
 func orderedPlaylists(playlists map[string]*PlaylistFile, order []string) []*PlaylistFile {
 	if len(playlists) == 0 {
 		return nil
@@ -273,16 +391,43 @@ func buildStreamPlaylistIndex(playlists []*PlaylistFile) map[*StreamFile][]*Play
 }
 
 func New(path string, settings settings.Settings) (*BDROM, error) {
+	return NewContext(context.Background(), path, settings)
+}
+
+// NewContext is New with a context for the HTTP requests of a disc image
+// opened from a URL, which keep using it for the rest of the scan.
+func NewContext(ctx context.Context, path string, settings settings.Settings) (*BDROM, error) {
+	if !ValidIOMode(settings.IOMode) {
+		return nil, fmt.Errorf("unsupported io mode: %s", settings.IOMode)
+	}
 	rootPath := path
 	cleanup := func() {
 		// No cleanup needed for regular directory access
 	}
 	fileSystem := fs.NewDiskFileSystem()
 	volumeLabel := ""
+	retry := fs.RetryPolicy{Retries: settings.IORetries, Delay: settings.IORetryDelay}
 
-	if strings.HasSuffix(strings.ToLower(path), ".iso") {
+	remote := fs.IsRemoteURL(path)
+	stdin := path == fs.StdinPath
+	if stdin && !settings.QuickScan {
+		return nil, errors.New("an image read from stdin needs a quick scan: other scans read every stream file and would spool the whole image")
+	}
+	if remote || stdin || strings.HasSuffix(strings.ToLower(path), ".iso") {
 		isoFS := fs.NewISOFileSystem()
-		if err := isoFS.Mount(path); err != nil {
+		mountRetry := retry
+		mount := isoFS.Mount
+		switch {
+		case remote:
+			mount = func(rawURL string) error { return isoFS.MountURL(ctx, rawURL) }
+		case stdin:
+			// A pipe cannot be read twice.
+			mountRetry = fs.RetryPolicy{}
+			mount = func(string) error {
+				return isoFS.MountStdin(func() (*os.File, error) { return settings.CreateTemp("bdinfo-stdin-*.iso") })
+			}
+		}
+		if err := mountRetry.Do(func() error { return mount(path) }); err != nil {
 			return nil, err
 		}
 		fileSystem = isoFS
@@ -290,6 +435,11 @@ func New(path string, settings settings.Settings) (*BDROM, error) {
 		volumeLabel = isoFS.GetVolumeLabel()
 		cleanup = func() { _ = isoFS.Unmount() }
 	}
+	isOptical := !fileSystem.IsISO() && fs.IsOpticalDrive(path)
+	fileSystem = fs.WithRetry(fileSystem, retry)
+	fileSystem = fs.WithRateLimit(fileSystem, fs.NewRateLimiter(settings.MaxReadMbps))
+	handles := fs.NewHandleBudget(settings.MaxOpenFiles)
+	fileSystem = fs.WithHandleBudget(fileSystem, handles)
 
 	rootDir, err := fileSystem.GetDirectoryInfo(rootPath)
 	if err != nil {
@@ -304,21 +454,24 @@ func New(path string, settings settings.Settings) (*BDROM, error) {
 	}
 
 	rom := &BDROM{
-		Path:             path,
+		Path:             fs.RedactURL(path),
 		Settings:         settings,
 		fileSystem:       fileSystem,
 		rootDirectory:    rootDir,
 		bdmvDirectory:    bdmvDir,
+		handles:          handles,
 		PlaylistFiles:    make(map[string]*PlaylistFile),
 		PlaylistOrder:    make([]string, 0),
 		StreamClipFiles:  make(map[string]*StreamClipFile),
 		StreamFiles:      make(map[string]*StreamFile),
 		InterleavedFiles: make(map[string]*InterleavedFile),
+		IsOpticalMedia:   isOptical,
 		cleanup:          cleanup,
 	}
 
 	rom.DirectoryRoot = rootDir.FullName()
 	rom.DirectoryBDMV = bdmvDir.FullName()
+	rom.IsBDAV = strings.EqualFold(bdmvDir.Name(), "BDAV")
 
 	if dir, err := bdmvDir.GetDirectory("BDJO"); err == nil {
 		rom.bdjoDirectory = dir
@@ -360,6 +513,8 @@ func New(path string, settings settings.Settings) (*BDROM, error) {
 	rom.VolumeLabel = volumeLabel
 	rom.Size = uint64(getDirectorySizeFS(rootDir))
 
+	rom.IndexTitles = readIndexTitles(bdmvDir)
+
 	if indexFile, err := bdmvDir.GetFile("index.bdmv"); err == nil {
 		if header, err := readFileHeader(indexFile, 8); err == nil && len(header) >= 8 {
 			if string(header[:8]) == "INDX0300" {
@@ -371,12 +526,19 @@ func New(path string, settings settings.Settings) (*BDROM, error) {
 	rom.IsBDPlus = directoryExistsFS(rootDir, "BDSVM") ||
 		directoryExistsFS(rootDir, "SLYVM") ||
 		directoryExistsFS(rootDir, "ANYVM")
+	rom.MKBVersion = readMKBVersion(rootDir)
+	if rom.IsBDPlus {
+		rom.BDPlusDate = readBDPlusDate(rootDir)
+	}
 
 	if rom.bdjoDirectory != nil {
 		if files, err := rom.bdjoDirectory.GetFiles(); err == nil && len(files) > 0 {
 			rom.IsBDJava = true
 		}
 	}
+	if settings.HarvestJARImages && rom.IsBDJava {
+		rom.JARImages = harvestJARImages(bdmvDir)
+	}
 
 	if rom.snpDirectory != nil {
 		if files, err := rom.snpDirectory.GetFiles(); err == nil {
@@ -400,15 +562,25 @@ func New(path string, settings settings.Settings) (*BDROM, error) {
 	}
 
 	rom.DiscTitle = readDiscTitleFS(rom.metaDirectory)
+	chapterNames := readChapterNames(rom.metaDirectory)
 
 	if rom.playlistDirectory != nil {
-		files, err := rom.playlistDirectory.GetFilesPattern("*.mpls")
-		if err != nil || len(files) == 0 {
-			files, err = rom.playlistDirectory.GetFilesPattern("*.MPLS")
+		extensions := []string{"mpls"}
+		if rom.IsBDAV {
+			extensions = []string{"rpls", "vpls"}
 		}
-		if err == nil {
+		for _, ext := range extensions {
+			files, err := rom.playlistDirectory.GetFilesPattern("*." + ext)
+			if err != nil || len(files) == 0 {
+				files, err = rom.playlistDirectory.GetFilesPattern("*." + strings.ToUpper(ext))
+			}
+			if err != nil {
+				continue
+			}
 			for _, file := range files {
 				pl := NewPlaylistFile(file, settings)
+				pl.ReachableFromTitle1 = titleReaches(rom.IndexTitles, 1, pl.Name)
+				pl.ChapterNames = chapterNames[pl.Name]
 				rom.PlaylistFiles[pl.Name] = pl
 				rom.PlaylistOrder = append(rom.PlaylistOrder, pl.Name)
 			}
@@ -423,6 +595,12 @@ func New(path string, settings settings.Settings) (*BDROM, error) {
 		if err == nil {
 			for _, file := range files {
 				sf := NewStreamFile(file)
+				switch {
+				case settings.LowMemory:
+					sf.readChunkSize = lowMemoryReadChunk
+				case rom.IsOpticalMedia:
+					sf.readChunkSize = opticalReadChunk
+				}
 				rom.StreamFiles[sf.Name] = sf
 			}
 		}
@@ -467,7 +645,8 @@ func (b *BDROM) Scan() ScanResult {
 }
 
 func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
-	result := ScanResult{FileErrors: make(map[string]error)}
+	result := ScanResult{FileErrors: make(map[string]error), BackupFiles: make(map[string]error)}
+	stats := newScanStatsRecorder()
 	var errMu sync.Mutex
 	emit := func(update ScanProgress) {
 		if progress != nil {
@@ -478,8 +657,20 @@ func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
 	clipFiles := orderedStreamClipFiles(b.StreamClipFiles)
 	emit(ScanProgress{Stage: ScanStageClipInfo, Total: len(clipFiles)})
 	var clipDone atomic.Int64
-	runParallel(clipFiles, scanWorkerLimit(len(clipFiles), 0), func(clip *StreamClipFile) error {
-		return clip.Scan()
+	usedBackup := func(name string, primaryErr error) {
+		if primaryErr == nil {
+			return
+		}
+		errMu.Lock()
+		result.BackupFiles[name] = primaryErr
+		errMu.Unlock()
+	}
+	runParallel(clipFiles, b.workerLimit(len(clipFiles), 0), func(clip *StreamClipFile) error {
+		return stats.timeFile(clip.Name, func() error {
+			primaryErr, err := b.scanStreamClipFile(clip)
+			usedBackup(clip.Name, primaryErr)
+			return err
+		})
 	}, func(_ *StreamClipFile) {
 		done := int(clipDone.Add(1))
 		emit(ScanProgress{Stage: ScanStageClipInfo, Completed: done, Total: len(clipFiles)})
@@ -499,8 +690,12 @@ func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
 	playlists := orderedPlaylists(b.PlaylistFiles, b.PlaylistOrder)
 	emit(ScanProgress{Stage: ScanStagePlaylist, Total: len(playlists)})
 	var playlistDone atomic.Int64
-	runParallel(playlists, scanWorkerLimit(len(playlists), 0), func(playlist *PlaylistFile) error {
-		return playlist.Scan(b.StreamFiles, b.StreamClipFiles)
+	runParallel(playlists, b.workerLimit(len(playlists), 0), func(playlist *PlaylistFile) error {
+		return stats.timeFile(playlist.Name, func() error {
+			primaryErr, err := b.scanPlaylistFile(playlist)
+			usedBackup(playlist.Name, primaryErr)
+			return err
+		})
 	}, func(_ *PlaylistFile) {
 		done := int(playlistDone.Add(1))
 		emit(ScanProgress{Stage: ScanStagePlaylist, Completed: done, Total: len(playlists)})
@@ -509,6 +704,7 @@ func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
 		result.FileErrors[playlist.Name] = err
 		errMu.Unlock()
 	})
+	markOrphanedPlaylists(b.IndexTitles, playlists)
 
 	// scan stream files
 	streamFiles := orderedStreamFiles(b.StreamFiles)
@@ -521,7 +717,19 @@ func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
 		filteredStreamFiles = append(filteredStreamFiles, streamFile)
 	}
 	streamFiles = filteredStreamFiles
-	streamBytes := streamFilesTotalSize(streamFiles)
+	// Caption decoding needs every PG packet, so such a scan reads every file;
+	// it still refreshes the cache. The bitrate trace notes the files restored
+	// from the cache, which add no windows.
+	cache := newStreamCache(b)
+	for _, streamFile := range streamFiles {
+		streamFile.trace = b.BitrateTrace
+		streamFile.captions = b.PGSCaptions
+		streamFile.recordClips = cache != nil
+	}
+	var streamBytes uint64
+	for _, streamFile := range streamFiles {
+		streamBytes += b.streamReadSize(streamFile)
+	}
 	emit(ScanProgress{Stage: ScanStageStream, Total: len(streamFiles), TotalBytes: streamBytes})
 	var streamDone atomic.Int64
 	var streamProcessed atomic.Uint64
@@ -530,7 +738,7 @@ func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
 	lastStreamBytes := uint64(0)
 	const streamEmitBytes = uint64(4 * 1024 * 1024)
 	const streamEmitInterval = 500 * time.Millisecond
-	emitStream := func(force bool) {
+	emitStream := func(force bool, streamFile *StreamFile, fileProcessed uint64) {
 		processed := streamProcessed.Load()
 		done := int(streamDone.Load())
 		if !force {
@@ -543,19 +751,39 @@ func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
 			lastStreamEmit = time.Now()
 			streamEmitMu.Unlock()
 		}
-		emit(ScanProgress{Stage: ScanStageStream, Completed: done, Total: len(streamFiles), ProcessedBytes: processed, TotalBytes: streamBytes})
-	}
-	runParallel(streamFiles, scanWorkerLimit(len(streamFiles), streamBytes), func(streamFile *StreamFile) error {
-		return streamFile.ScanWithProgress(streamPlaylists[streamFile], false, func(delta uint64) {
-			if delta == 0 {
-				return
+		emit(streamFileProgress(ScanProgress{Stage: ScanStageStream, Completed: done, Total: len(streamFiles), ProcessedBytes: processed, TotalBytes: streamBytes}, streamFile, fileProcessed, b.streamReadSize(streamFile)))
+	}
+	stats.workers = b.workerLimit(len(streamFiles), streamBytes)
+	runParallel(streamFiles, stats.workers, func(streamFile *StreamFile) error {
+		var fileProcessed uint64
+		return stats.timeFile(streamFile.Name, func() error {
+			size := b.streamReadSize(streamFile)
+			if b.PGSCaptions == nil && cache.restore(streamFile, streamPlaylists[streamFile]) {
+				b.BitrateTrace.recordCached(streamFile)
+				streamProcessed.Add(size)
+				stats.addCacheHit()
+				return nil
 			}
-			streamProcessed.Add(delta)
-			emitStream(false)
+			err := streamFile.ScanWithProgress(streamPlaylists[streamFile], false, func(delta uint64) {
+				if delta == 0 {
+					return
+				}
+				stats.addBytes(delta)
+				streamProcessed.Add(delta)
+				fileProcessed += delta
+				emitStream(false, streamFile, fileProcessed)
+			})
+			if err == nil {
+				cache.store(streamFile, streamPlaylists[streamFile])
+			}
+			streamFile.clipRecords = nil
+			// A later full scan reads the file again; report its captions once.
+			streamFile.captions = nil
+			return err
 		})
-	}, func(_ *StreamFile) {
+	}, func(streamFile *StreamFile) {
 		streamDone.Add(1)
-		emitStream(true)
+		emitStream(true, streamFile, b.streamReadSize(streamFile))
 	}, func(streamFile *StreamFile, err error) {
 		errMu.Lock()
 		result.FileErrors[streamFile.Name] = err
@@ -565,7 +793,7 @@ func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
 
 	emit(ScanProgress{Stage: ScanStageInitialize, Total: len(playlists)})
 	var initDone atomic.Int64
-	runParallel(playlists, scanWorkerLimit(len(playlists), 0), func(playlist *PlaylistFile) error {
+	runParallel(playlists, b.workerLimit(len(playlists), 0), func(playlist *PlaylistFile) error {
 		playlist.Initialize()
 		return nil
 	}, func(_ *PlaylistFile) {
@@ -595,6 +823,8 @@ func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
 		}
 	}
 
+	b.BitrateTrace.recordPlaylists(playlists, "scan")
+	result.Stats = stats.finish()
 	emit(ScanProgress{Stage: ScanStageComplete, Completed: 1, Total: 1})
 
 	return result
@@ -603,10 +833,11 @@ func (b *BDROM) ScanWithProgress(progress ScanProgressFunc) ScanResult {
 // ScanFull performs a full bitrate/diagnostics scan over stream files.
 func (b *BDROM) ScanFull() ScanResult {
 	result := ScanResult{FileErrors: make(map[string]error)}
+	stats := newScanStatsRecorder()
 	var errMu sync.Mutex
 
 	playlists := orderedPlaylists(b.PlaylistFiles, b.PlaylistOrder)
-	runParallel(playlists, scanWorkerLimit(len(playlists), 0), func(playlist *PlaylistFile) error {
+	runParallel(playlists, b.workerLimit(len(playlists), 0), func(playlist *PlaylistFile) error {
 		playlist.ClearBitrates()
 		return nil
 	}, nil, nil)
@@ -621,14 +852,22 @@ func (b *BDROM) ScanFull() ScanResult {
 		filteredStreamFiles = append(filteredStreamFiles, streamFile)
 	}
 	streamFiles = filteredStreamFiles
+	for _, streamFile := range streamFiles {
+		streamFile.trace = b.BitrateTrace
+	}
 	streamBytes := streamFilesTotalSize(streamFiles)
-	runParallel(streamFiles, scanWorkerLimit(len(streamFiles), streamBytes), func(streamFile *StreamFile) error {
-		return streamFile.Scan(streamPlaylists[streamFile], true)
+	stats.workers = b.workerLimit(len(streamFiles), streamBytes)
+	runParallel(streamFiles, stats.workers, func(streamFile *StreamFile) error {
+		return stats.timeFile(streamFile.Name, func() error {
+			return streamFile.ScanWithProgress(streamPlaylists[streamFile], true, stats.addBytes)
+		})
 	}, nil, func(streamFile *StreamFile, err error) {
 		errMu.Lock()
 		result.FileErrors[streamFile.Name] = err
 		errMu.Unlock()
 	})
+	b.BitrateTrace.recordPlaylists(playlists, "full")
+	result.Stats = stats.finish()
 
 	return result
 }
@@ -636,6 +875,7 @@ func (b *BDROM) ScanFull() ScanResult {
 // ScanFullWithProgress performs a full bitrate/diagnostics scan over stream files with progress updates.
 func (b *BDROM) ScanFullWithProgress(progress ScanProgressFunc) ScanResult {
 	result := ScanResult{FileErrors: make(map[string]error)}
+	stats := newScanStatsRecorder()
 	var errMu sync.Mutex
 	emit := func(update ScanProgress) {
 		if progress != nil {
@@ -646,7 +886,7 @@ func (b *BDROM) ScanFullWithProgress(progress ScanProgressFunc) ScanResult {
 	playlists := orderedPlaylists(b.PlaylistFiles, b.PlaylistOrder)
 	emit(ScanProgress{Stage: ScanStageInitialize, Total: len(playlists)})
 	var initDone atomic.Int64
-	runParallel(playlists, scanWorkerLimit(len(playlists), 0), func(playlist *PlaylistFile) error {
+	runParallel(playlists, b.workerLimit(len(playlists), 0), func(playlist *PlaylistFile) error {
 		playlist.ClearBitrates()
 		return nil
 	}, func(_ *PlaylistFile) {
@@ -664,41 +904,64 @@ func (b *BDROM) ScanFullWithProgress(progress ScanProgressFunc) ScanResult {
 		filteredStreamFiles = append(filteredStreamFiles, streamFile)
 	}
 	streamFiles = filteredStreamFiles
+	for _, streamFile := range streamFiles {
+		streamFile.trace = b.BitrateTrace
+	}
 	streamBytes := streamFilesTotalSize(streamFiles)
 	emit(ScanProgress{Stage: ScanStageStream, Total: len(streamFiles), TotalBytes: streamBytes})
 	var streamDone atomic.Int64
 	var streamProcessed atomic.Uint64
-	runParallel(streamFiles, scanWorkerLimit(len(streamFiles), streamBytes), func(streamFile *StreamFile) error {
-		return streamFile.ScanWithProgress(streamPlaylists[streamFile], true, func(delta uint64) {
-			if delta == 0 {
-				return
-			}
-			processed := streamProcessed.Add(delta)
-			emit(ScanProgress{Stage: ScanStageStream, Completed: int(streamDone.Load()), Total: len(streamFiles), ProcessedBytes: processed, TotalBytes: streamBytes})
+	stats.workers = b.workerLimit(len(streamFiles), streamBytes)
+	runParallel(streamFiles, stats.workers, func(streamFile *StreamFile) error {
+		var fileProcessed uint64
+		return stats.timeFile(streamFile.Name, func() error {
+			return streamFile.ScanWithProgress(streamPlaylists[streamFile], true, func(delta uint64) {
+				if delta == 0 {
+					return
+				}
+				stats.addBytes(delta)
+				processed := streamProcessed.Add(delta)
+				fileProcessed += delta
+				emit(streamFileProgress(ScanProgress{Stage: ScanStageStream, Completed: int(streamDone.Load()), Total: len(streamFiles), ProcessedBytes: processed, TotalBytes: streamBytes}, streamFile, fileProcessed, streamFileSize(streamFile)))
+			})
 		})
-	}, func(_ *StreamFile) {
+	}, func(streamFile *StreamFile) {
 		done := int(streamDone.Add(1))
-		emit(ScanProgress{Stage: ScanStageStream, Completed: done, Total: len(streamFiles), ProcessedBytes: streamProcessed.Load(), TotalBytes: streamBytes})
+		emit(streamFileProgress(ScanProgress{Stage: ScanStageStream, Completed: done, Total: len(streamFiles), ProcessedBytes: streamProcessed.Load(), TotalBytes: streamBytes}, streamFile, streamFileSize(streamFile), streamFileSize(streamFile)))
 	}, func(streamFile *StreamFile, err error) {
 		errMu.Lock()
 		result.FileErrors[streamFile.Name] = err
 		errMu.Unlock()
 	})
+	b.BitrateTrace.recordPlaylists(playlists, "full")
+	result.Stats = stats.finish()
 	emit(ScanProgress{Stage: ScanStageComplete, Completed: 1, Total: 1})
 
 	return result
 }
 
+// findBDMVDirectory finds the BDMV directory at or below root, breadth first,
+// and falls back to a BDAV directory for recorder discs.
 func findBDMVDirectory(root fs.DirectoryInfo) (fs.DirectoryInfo, error) {
 	if root == nil {
 		return nil, fmt.Errorf("unable to locate BD structure")
 	}
-	if strings.EqualFold(root.Name(), "BDMV") {
+	for _, name := range []string{"BDMV", "BDAV"} {
+		if dir := findBDDirectory(root, name); dir != nil {
+			return dir, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to locate BD structure")
+}
+
+// findBDDirectory finds a directory called name that holds PLAYLIST or STREAM.
+func findBDDirectory(root fs.DirectoryInfo, name string) fs.DirectoryInfo {
+	if strings.EqualFold(root.Name(), name) {
 		if _, err := root.GetDirectory("PLAYLIST"); err == nil {
-			return root, nil
+			return root
 		}
 		if _, err := root.GetDirectory("STREAM"); err == nil {
-			return root, nil
+			return root
 		}
 	}
 
@@ -712,19 +975,18 @@ func findBDMVDirectory(root fs.DirectoryInfo) (fs.DirectoryInfo, error) {
 			continue
 		}
 		for _, sub := range dirs {
-			if strings.EqualFold(sub.Name(), "BDMV") {
+			if strings.EqualFold(sub.Name(), name) {
 				if _, err := sub.GetDirectory("PLAYLIST"); err == nil {
-					return sub, nil
+					return sub
 				}
 				if _, err := sub.GetDirectory("STREAM"); err == nil {
-					return sub, nil
+					return sub
 				}
 			}
 			queue = append(queue, sub)
 		}
 	}
-
-	return nil, fmt.Errorf("unable to locate BD structure")
+	return nil
 }
 
 func directoryExistsFS(root fs.DirectoryInfo, name string) bool {
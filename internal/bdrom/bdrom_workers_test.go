@@ -1,38 +1,98 @@
 package bdrom
 
-import "testing"
+import (
+	"testing"
 
-func TestScanWorkerLimit_ISOStreamScanDefaultsToOneWorker(t *testing.T) {
-	t.Setenv("BDINFO_WORKERS", "")
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
 
-	if got, want := scanWorkerLimit(8, 90<<30), 1; got != want {
+func TestScanWorkerLimit_ISOStreamScanDefaultsToOneWorker(t *testing.T) {
+	if got, want := scanWorkerLimit(8, 90<<30, 0), 1; got != want {
 		t.Fatalf("scanWorkerLimit(iso stream)=%d want %d", got, want)
 	}
 }
 
 func TestScanWorkerLimit_StreamScanDefaultsToOneWorker(t *testing.T) {
-	t.Setenv("BDINFO_WORKERS", "")
-
-	if got, want := scanWorkerLimit(8, 90<<30), 1; got != want {
+	if got, want := scanWorkerLimit(8, 90<<30, 0), 1; got != want {
 		t.Fatalf("scanWorkerLimit(non-iso stream)=%d want %d", got, want)
 	}
 }
 
 func TestScanWorkerLimit_MetadataScanUsesTunedLimit(t *testing.T) {
-	t.Setenv("BDINFO_WORKERS", "")
-
-	got := scanWorkerLimit(8, 0)
+	got := scanWorkerLimit(8, 0, 0)
 	want := clampWorkers(tunedWorkerLimit(8, 0), 8)
 	if got != want {
 		t.Fatalf("scanWorkerLimit(metadata)=%d want %d", got, want)
 	}
 }
 
-func TestScanWorkerLimit_EnvOverrideWins(t *testing.T) {
+func TestScanWorkerLimit_OverrideWins(t *testing.T) {
+	want := clampWorkers(3, 8)
+	if got := scanWorkerLimit(8, 90<<30, 3); got != want {
+		t.Fatalf("scanWorkerLimit(override)=%d want %d", got, want)
+	}
+}
+
+func TestWorkerLimit_IgnoresEnvironment(t *testing.T) {
 	t.Setenv("BDINFO_WORKERS", "3")
 
-	want := clampWorkers(3, 8)
-	if got := scanWorkerLimit(8, 90<<30); got != want {
-		t.Fatalf("scanWorkerLimit(env override)=%d want %d", got, want)
+	rom := &BDROM{}
+	if got, want := rom.workerLimit(8, 90<<30), 1; got != want {
+		t.Fatalf("workerLimit with BDINFO_WORKERS set=%d want %d", got, want)
+	}
+}
+
+func TestWorkerLimit_OpticalMediaUsesOneWorker(t *testing.T) {
+	rom := &BDROM{IsOpticalMedia: true}
+	if got, want := rom.workerLimit(8, 0), 1; got != want {
+		t.Fatalf("workerLimit(optical metadata)=%d want %d", got, want)
+	}
+
+	rom.Settings = settings.Settings{Workers: 3}
+	if got, want := rom.workerLimit(8, 0), clampWorkers(3, 8); got != want {
+		t.Fatalf("workerLimit(optical override)=%d want %d", got, want)
+	}
+}
+
+func TestWorkerLimit_IOMode(t *testing.T) {
+	rom := &BDROM{Settings: settings.Settings{IOMode: IOModeParallel}}
+	if got, want := rom.workerLimit(8, 90<<30), clampWorkers(maxScanWorkers, 8); got != want {
+		t.Fatalf("workerLimit(parallel stream)=%d want %d", got, want)
+	}
+	rom.IsOpticalMedia = true
+	if got, want := rom.workerLimit(8, 0), clampWorkers(maxScanWorkers, 8); got != want {
+		t.Fatalf("workerLimit(parallel optical)=%d want %d", got, want)
+	}
+
+	rom = &BDROM{Settings: settings.Settings{IOMode: IOModeSequential}}
+	if got := rom.workerLimit(8, 0); got != 1 {
+		t.Fatalf("workerLimit(sequential metadata)=%d want 1", got)
+	}
+
+	rom.Settings.Workers = 3
+	if got, want := rom.workerLimit(8, 0), clampWorkers(3, 8); got != want {
+		t.Fatalf("workerLimit(sequential override)=%d want %d", got, want)
+	}
+
+	rom = &BDROM{Settings: settings.Settings{IOMode: IOModeAuto}}
+	if got, want := rom.workerLimit(8, 0), scanWorkerLimit(8, 0, 0); got != want {
+		t.Fatalf("workerLimit(auto metadata)=%d want %d", got, want)
+	}
+}
+
+func TestNew_RejectsUnknownIOMode(t *testing.T) {
+	if _, err := New(t.TempDir(), settings.Settings{IOMode: "turbo"}); err == nil || err.Error() != "unsupported io mode: turbo" {
+		t.Fatalf("New err=%v", err)
+	}
+}
+
+func TestWorkerLimit_LowMemoryUsesOneWorker(t *testing.T) {
+	rom := &BDROM{Settings: settings.Settings{IOMode: IOModeParallel, LowMemory: true}}
+	if got := rom.workerLimit(8, 0); got != 1 {
+		t.Fatalf("workerLimit(low memory parallel)=%d want 1", got)
+	}
+	rom.Settings.Workers = 3
+	if got, want := rom.workerLimit(8, 0), clampWorkers(3, 8); got != want {
+		t.Fatalf("workerLimit(low memory override)=%d want %d", got, want)
 	}
 }
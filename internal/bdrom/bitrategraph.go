@@ -0,0 +1,26 @@
+package bdrom
+
+import "math"
+
+// BitrateSeries spreads the diagnostics windows of the stream with the given
+// PID over the seconds of the playlist, on the main angle: element i is the
+// bitrate, in bits per second, of the stream's packets in second i. Audio
+// streams only have windows when the scan ran with Settings.BitrateGraph.
+func (p *PlaylistFile) BitrateSeries(pid uint16) []uint64 {
+	series := make([]uint64, int(math.Ceil(p.TotalLength())))
+	for _, clip := range p.StreamClips {
+		if clip.AngleIndex != 0 || clip.StreamFile == nil {
+			continue
+		}
+		for _, diag := range clip.StreamFile.StreamDiagnostics[pid] {
+			if diag.Marker < clip.TimeIn || diag.Marker >= clip.TimeOut {
+				continue
+			}
+			second := int(diag.Marker - clip.TimeIn + clip.RelativeTimeIn)
+			if second >= 0 && second < len(series) {
+				series[second] += diag.Bytes * 8
+			}
+		}
+	}
+	return series
+}
@@ -0,0 +1,82 @@
+package bdrom
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestStreamFileScan_AudioWindows(t *testing.T) {
+	const videoPID, audioPID = 0x1011, 0x1100
+
+	videoPES := func(pts uint64) []byte {
+		pts5 := encodePTS(0x20, pts)
+		payload := make([]byte, 184)
+		copy(payload, []byte{0x00, 0x00, 0x01, 0xE0, 0x00, 0x00, 0x80, 0x80, 0x05})
+		copy(payload[9:14], pts5[:])
+		return payload
+	}
+	var data []byte
+	for i, pts := range []uint64{90000, 93003, 96006} {
+		video := tsPacket188(videoPID, true, videoPES(pts))
+		audio := tsPacket188(audioPID, true, lpcmPES(90000+uint64(i)*3003, 0x11))
+		data = append(data, video[:]...)
+		data = append(data, audio[:]...)
+	}
+
+	scan := func(graph bool) *StreamFile {
+		s := NewStreamFile(&memFileInfo{name: "00001.M2TS", data: data})
+		video := &stream.VideoStream{Stream: stream.Stream{PID: videoPID, StreamType: stream.StreamTypeAVCVideo}}
+		audio := &stream.AudioStream{Stream: stream.Stream{PID: audioPID, StreamType: stream.StreamTypeLPCMAudio}}
+		s.Streams[videoPID] = video
+		s.Streams[audioPID] = audio
+		playlist := &PlaylistFile{
+			Name:        "00800.MPLS",
+			Settings:    settings.Settings{BitrateGraph: graph},
+			StreamClips: []*StreamClip{{Name: s.Name, StreamFile: s, TimeIn: 0, TimeOut: 10}},
+			Streams:     map[uint16]stream.Info{videoPID: video, audioPID: audio},
+		}
+		if err := s.Scan([]*PlaylistFile{playlist}, false); err != nil {
+			t.Fatalf("Scan() error: %v", err)
+		}
+		return s
+	}
+
+	if s := scan(false); len(s.StreamDiagnostics[audioPID]) != 0 {
+		t.Fatalf("audio windows kept without BitrateGraph: %+v", s.StreamDiagnostics[audioPID])
+	}
+	s := scan(true)
+	windows := s.StreamDiagnostics[audioPID]
+	if len(windows) != 3 {
+		t.Fatalf("audio windows got=%d want=3 (%+v)", len(windows), windows)
+	}
+	if first := windows[0]; first.Marker != 93003/90000.0 || first.Packets != 1 || first.Bytes == 0 || first.Tag != "" {
+		t.Fatalf("first audio window %+v", first)
+	}
+	if got := len(s.StreamDiagnostics[videoPID]); got != 2 {
+		t.Fatalf("video windows got=%d want=2", got)
+	}
+}
+
+func TestPlaylistFile_BitrateSeries(t *testing.T) {
+	file := &StreamFile{Name: "00001.M2TS", StreamDiagnostics: map[uint16][]StreamDiagnostics{
+		0x1011: {{Marker: 10.5, Bytes: 1000}, {Marker: 11.2, Bytes: 500}, {Marker: 11.9, Bytes: 250}, {Marker: 13.5, Bytes: 4000}},
+	}}
+	other := &StreamFile{Name: "00002.M2TS", StreamDiagnostics: map[uint16][]StreamDiagnostics{0x1011: {{Marker: 1, Bytes: 9999}}}}
+	playlist := &PlaylistFile{StreamClips: []*StreamClip{
+		{StreamFile: file, TimeIn: 10, TimeOut: 13, Length: 3, RelativeTimeIn: 0, RelativeTimeOut: 3},
+		{StreamFile: other, TimeIn: 0, TimeOut: 3, Length: 3, AngleIndex: 1},
+	}}
+
+	got := playlist.BitrateSeries(0x1011)
+	want := []uint64{8000, 6000, 0}
+	if len(got) != len(want) {
+		t.Fatalf("series %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("series %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package bdrom
+
+import (
+	"image"
+
+	"github.com/autobrr/go-bdinfo/internal/codec"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// maxPGSTransfer bounds the bytes buffered for one PG PES packet; segments are
+// at most 64 KiB and a display set rarely spans more than a few of them.
+const maxPGSTransfer = 1 << 20
+
+// PGSCaption is one subtitle image decoded from a presentation graphics
+// stream. Start and End are seconds on the stream file's clock, the clock of
+// StreamClip.TimeIn/TimeOut; End is 0 when the file ended while it was shown.
+type PGSCaption struct {
+	File     string
+	PID      uint16
+	Language string
+	Start    float64
+	End      float64
+	Forced   bool
+	Image    *image.NRGBA
+}
+
+// pgsTransfer collects the PES packets of one PG stream and decodes each
+// once the next one starts. Without a decoder it only counts the captions
+// into counted.
+type pgsTransfer struct {
+	decoder  *codec.PGSDecoder
+	counted  *stream.GraphicsStream
+	pid      uint16
+	language string
+	pts      uint64
+	data     []byte
+}
+
+// newPGSTransfer returns a transfer for st when the scan decodes or counts
+// captions and st is a presentation graphics stream; nil otherwise. Counting
+// starts the stream's caption counts over.
+func (s *StreamFile) newPGSTransfer(pid uint16, st stream.Info, count bool) *pgsTransfer {
+	if s.captions == nil && !count {
+		return nil
+	}
+	gs, ok := st.(*stream.GraphicsStream)
+	if !ok || gs.StreamType != stream.StreamTypePresentationGraphics {
+		return nil
+	}
+	t := &pgsTransfer{pid: pid, language: gs.LanguageCode()}
+	if s.captions != nil {
+		t.decoder = codec.NewPGSDecoder()
+	}
+	if count {
+		gs.Captions, gs.ForcedCaptions = 0, 0
+		t.counted = gs
+	}
+	return t
+}
+
+// append adds payload bytes of the current PES packet, taking its PTS from
+// the parsed header.
+func (t *pgsTransfer) append(state *streamState, payload []byte) {
+	if len(t.data) == 0 && state.pesPtsDtsFlags&0x02 != 0 && len(state.pesHeaderBuf) >= 14 {
+		t.pts = parsePTS(state.pesHeaderBuf[9:14])
+	}
+	if len(t.data)+len(payload) > maxPGSTransfer {
+		return
+	}
+	t.data = append(t.data, payload...)
+}
+
+// decode hands the buffered PES packet to the decoder and reports the
+// captions it ended; at the end of the file it also reports the one on screen.
+func (s *StreamFile) decodePGS(t *pgsTransfer, final bool) {
+	if t.counted != nil && len(t.data) > 0 {
+		codec.CountPGSCaptions(t.counted, t.data)
+	}
+	if t.decoder == nil {
+		t.data = t.data[:0]
+		return
+	}
+	var done []codec.PGSCaption
+	if len(t.data) > 0 {
+		done = t.decoder.Decode(t.pts, t.data)
+		t.data = t.data[:0]
+	}
+	if final {
+		done = append(done, t.decoder.Flush()...)
+	}
+	for _, caption := range done {
+		s.captions(PGSCaption{
+			File:     s.Name,
+			PID:      t.pid,
+			Language: t.language,
+			Start:    float64(caption.Start) / 90000.0,
+			End:      float64(caption.End) / 90000.0,
+			Forced:   caption.Forced,
+			Image:    caption.Image,
+		})
+	}
+}
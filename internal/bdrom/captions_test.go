@@ -0,0 +1,66 @@
+package bdrom
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// pgsPES wraps PG segments in a PES packet presented at pts.
+func pgsPES(pts uint64, segments ...[]byte) []byte {
+	var body []byte
+	for _, segment := range segments {
+		body = append(body, segment...)
+	}
+	pts5 := encodePTS(0x20, pts)
+	pesLen := 3 + 5 + len(body)
+	pes := []byte{0x00, 0x00, 0x01, 0xBD, byte(pesLen >> 8), byte(pesLen), 0x80, 0x80, 0x05}
+	pes = append(pes, pts5[:]...)
+	return append(pes, body...)
+}
+
+func pgsTestSegment(kind byte, body ...byte) []byte {
+	return append([]byte{kind, byte(len(body) >> 8), byte(len(body))}, body...)
+}
+
+func TestStreamFileScan_PGSCaptions(t *testing.T) {
+	const pid = 0x1200
+	show := pgsPES(900000,
+		pgsTestSegment(0x16, 0x07, 0x80, 0x04, 0x38, 0x10, 0x00, 0x01, 0x80, 0x00, 0x00, 0x01,
+			0x00, 0x00, 0x00, 0x40, 0x00, 0x10, 0x00, 0x20), // forced object 0 at 16,32
+		pgsTestSegment(0x14, 0x00, 0x00, 0x01, 235, 128, 128, 0xFF),
+		pgsTestSegment(0x15, 0x00, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x07, 0x00, 0x01, 0x00, 0x01, 0x01, 0x00, 0x00),
+		pgsTestSegment(0x80))
+	hide := pgsPES(1080000,
+		pgsTestSegment(0x16, 0x07, 0x80, 0x04, 0x38, 0x10, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00),
+		pgsTestSegment(0x80))
+	p1 := tsPacket188(pid, true, show)
+	p2 := tsPacket188(pid, true, hide)
+	p3 := tsPacket188(pid, true, show)
+
+	s := NewStreamFile(&memFileInfo{name: "00001.M2TS", data: append(append(p1[:], p2[:]...), p3[:]...)})
+	gs := stream.NewGraphicsStream()
+	gs.PID = pid
+	gs.StreamType = stream.StreamTypePresentationGraphics
+	gs.SetLanguageCode("eng")
+	s.Streams[pid] = gs
+
+	var captions []PGSCaption
+	s.captions = func(caption PGSCaption) { captions = append(captions, caption) }
+	if err := s.Scan(nil, false); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	if len(captions) != 2 {
+		t.Fatalf("got %d captions, want 2", len(captions))
+	}
+	first := captions[0]
+	if first.File != "00001.M2TS" || first.PID != pid || first.Language != "eng" || !first.Forced || first.Start != 10 || first.End != 12 {
+		t.Fatalf("first caption %+v", first)
+	}
+	if b := first.Image.Bounds(); b.Dx() != 1 || b.Dy() != 1 {
+		t.Fatalf("first caption image %v", b)
+	}
+	if last := captions[1]; last.Start != 10 || last.End != 0 {
+		t.Fatalf("caption shown at the end of the file: start=%v end=%v", last.Start, last.End)
+	}
+}
@@ -0,0 +1,95 @@
+package bdrom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+)
+
+// readChapterNames collects chapter names from the title name metadata in
+// META/TN (tnmt_<lang>_<playlist>.xml), keyed by playlist file name. English
+// is preferred; otherwise the first language in file name order is used.
+func readChapterNames(metaDir fs.DirectoryInfo) map[string][]string {
+	if metaDir == nil {
+		return nil
+	}
+	tnDir, err := metaDir.GetDirectory("TN")
+	if err != nil {
+		return nil
+	}
+	files, err := tnDir.GetFiles()
+	if err != nil {
+		return nil
+	}
+	slices.SortFunc(files, func(a, b fs.FileInfo) int {
+		return strings.Compare(strings.ToLower(a.Name()), strings.ToLower(b.Name()))
+	})
+
+	names := map[string][]string{}
+	english := map[string]bool{}
+	for _, file := range files {
+		lang, playlist, ok := parseTNMTName(file.Name())
+		if !ok || english[playlist] || (names[playlist] != nil && lang != "eng") {
+			continue
+		}
+		data, err := readWholeFile(tnDir, file.Name())
+		if err != nil {
+			continue
+		}
+		if chapters := parseChapterNames(data); len(chapters) > 0 {
+			names[playlist] = chapters
+			english[playlist] = lang == "eng"
+		}
+	}
+	return names
+}
+
+// parseTNMTName splits tnmt_<lang>_<5-digit playlist>.xml into the language
+// and the playlist file name.
+func parseTNMTName(name string) (lang, playlist string, ok bool) {
+	lower := strings.ToLower(name)
+	if !strings.HasPrefix(lower, "tnmt_") || !strings.HasSuffix(lower, ".xml") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(lower[len("tnmt_"):], ".xml"), "_")
+	if len(parts) != 2 || len(parts[0]) != 3 || len(parts[1]) != 5 {
+		return "", "", false
+	}
+	return parts[0], strings.ToUpper(parts[1]) + ".MPLS", true
+}
+
+// parseChapterNames returns the <name> children of <chapters>, in order.
+func parseChapterNames(data []byte) []string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var path []string
+	var names []string
+	var name strings.Builder
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			name.Reset()
+		case xml.EndElement:
+			if len(path) >= 2 && path[len(path)-1] == "name" && path[len(path)-2] == "chapters" {
+				names = append(names, strings.TrimSpace(name.String()))
+			}
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		case xml.CharData:
+			name.Write(t)
+		}
+	}
+	return names
+}
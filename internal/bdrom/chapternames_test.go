@@ -0,0 +1,52 @@
+package bdrom
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+)
+
+func tnmt(names ...string) string {
+	doc := `<?xml version="1.0" encoding="utf-8"?>
+<disclib xmlns="urn:BDA:bdmv;disclib"><tn:title xmlns:tn="urn:BDA:bdmv;tnmt"><tn:name>Feature</tn:name>
+<tn:chapters>`
+	for _, name := range names {
+		doc += "<tn:name> " + name + " </tn:name>"
+	}
+	return doc + "</tn:chapters></tn:title></disclib>"
+}
+
+func TestReadChapterNames(t *testing.T) {
+	root := t.TempDir()
+	tnDir := filepath.Join(root, "META", "TN")
+	if err := os.MkdirAll(tnDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"tnmt_fra_00800.xml": tnmt("Ouverture", "Fin"),
+		"tnmt_eng_00800.xml": tnmt("Opening", "Finale"),
+		"tnmt_deu_00001.xml": tnmt("Anfang"),
+		"tnmt_eng.xml":       tnmt("ignored"),
+		"bdmt_eng.xml":       "<disclib/>",
+	} {
+		if err := os.WriteFile(filepath.Join(tnDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	meta, err := fs.NewDiskFileSystem().GetDirectoryInfo(filepath.Join(root, "META"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := readChapterNames(meta)
+	want := map[string][]string{
+		"00800.MPLS": {"Opening", "Finale"},
+		"00001.MPLS": {"Anfang"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("readChapterNames = %q want %q", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package bdrom
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io"
 	"strings"
@@ -17,6 +18,20 @@ type StreamClipFile struct {
 	Streams  map[uint16]stream.Info
 	// StreamOrder preserves CLPI stream table order for parity with official BDInfo.
 	StreamOrder []uint16
+
+	// ClipInfo() block: how the clip was recorded. Authored discs use
+	// application type 1 and format identifier "HDMV"; camcorder AVCHD clips
+	// have much lower recording rates.
+	ClipStreamType    byte
+	ApplicationType   byte
+	ATCDelta          bool
+	TSRecordingRate   uint32 // bytes per second
+	SourcePacketCount uint32
+	FormatIdentifier  string
+
+	// StructureWarnings lists section addresses and lengths that do not fit
+	// the file (see checkStructure); the clip info may still have parsed.
+	StructureWarnings []string
 }
 
 func NewStreamClipFile(fileInfo fs.FileInfo) *StreamClipFile {
@@ -50,6 +65,9 @@ func (s *StreamClipFile) Scan() error {
 	if fileType != "HDMV0100" && fileType != "HDMV0200" && fileType != "HDMV0300" {
 		return fmt.Errorf("clip info %s has unknown file type %s", s.Name, fileType)
 	}
+	s.StructureWarnings = checkCLPIStructure(data)
+
+	s.parseClipInfo(data)
 
 	clipIndex := int(uint32(data[12])<<24 | uint32(data[13])<<16 | uint32(data[14])<<8 | uint32(data[15]))
 	if clipIndex+4 > len(data) {
@@ -145,3 +163,53 @@ func (s *StreamClipFile) Scan() error {
 	}
 	return nil
 }
+
+// clipInfoOffset is where ClipInfo() starts, after the CLPI header's start
+// addresses and reserved bytes.
+const clipInfoOffset = 40
+
+// parseClipInfo reads the ClipInfo() block: length(4) reserved(2)
+// clip_stream_type(1) application_type(1) reserved(31 bits) is_ATC_delta(1 bit)
+// TS_recording_rate(4) number_of_source_packets(4) reserved(128), then
+// TS_type_info_block() with length(2) validity_flags(1) format_identifier(4).
+func (s *StreamClipFile) parseClipInfo(data []byte) {
+	const (
+		rateOffset   = clipInfoOffset + 12
+		formatOffset = clipInfoOffset + 151
+	)
+	if len(data) < rateOffset+8 {
+		return
+	}
+	s.ClipStreamType = data[clipInfoOffset+6]
+	s.ApplicationType = data[clipInfoOffset+7]
+	s.ATCDelta = data[clipInfoOffset+11]&0x01 != 0
+	s.TSRecordingRate = binary.BigEndian.Uint32(data[rateOffset:])
+	s.SourcePacketCount = binary.BigEndian.Uint32(data[rateOffset+4:])
+	if len(data) >= formatOffset+4 {
+		s.FormatIdentifier = strings.TrimRight(string(data[formatOffset:formatOffset+4]), "\x00 ")
+	}
+}
+
+// ApplicationTypeName describes the clip's application_type.
+func (s *StreamClipFile) ApplicationTypeName() string {
+	switch s.ApplicationType {
+	case 1:
+		return "Main TS (movie)"
+	case 2:
+		return "Main TS (time-based slideshow)"
+	case 3:
+		return "Main TS (browsable slideshow)"
+	case 4:
+		return "Sub TS (browsable slideshow)"
+	case 5:
+		return "Sub TS (interactive graphics)"
+	case 6:
+		return "Sub TS (text subtitle)"
+	case 7:
+		return "Sub TS (elementary streams)"
+	case 8:
+		return "Sub TS (enhancement layer)"
+	default:
+		return fmt.Sprintf("Unknown (%d)", s.ApplicationType)
+	}
+}
@@ -0,0 +1,57 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestStreamClipFileScan_ClipInfo(t *testing.T) {
+	data := make([]byte, 256)
+	copy(data, "HDMV0200")
+	binary.BigEndian.PutUint32(data[12:], 200) // ProgramInfo with no streams
+	data[clipInfoOffset+6] = 1                 // AV stream
+	data[clipInfoOffset+7] = 1                 // main TS for a movie
+	data[clipInfoOffset+11] = 0x01             // is_ATC_delta
+	binary.BigEndian.PutUint32(data[clipInfoOffset+12:], 6_000_000)
+	binary.BigEndian.PutUint32(data[clipInfoOffset+16:], 123456)
+	copy(data[clipInfoOffset+151:], "HDMV")
+	binary.BigEndian.PutUint32(data[200:], 12)
+
+	clpi := NewStreamClipFile(&memFileInfo{name: "00001.clpi", data: data})
+	if err := clpi.Scan(); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	if clpi.ClipStreamType != 1 || clpi.ApplicationTypeName() != "Main TS (movie)" || !clpi.ATCDelta {
+		t.Fatalf("clip info got stream type=%d application=%q atc delta=%v", clpi.ClipStreamType, clpi.ApplicationTypeName(), clpi.ATCDelta)
+	}
+	if clpi.TSRecordingRate != 6_000_000 || clpi.SourcePacketCount != 123456 || clpi.FormatIdentifier != "HDMV" {
+		t.Fatalf("clip info got rate=%d packets=%d format=%q", clpi.TSRecordingRate, clpi.SourcePacketCount, clpi.FormatIdentifier)
+	}
+}
+
+func TestPlaylistScan_OtherStreams(t *testing.T) {
+	audio := &stream.AudioStream{}
+	audio.PID = 4352
+	audio.StreamType = stream.StreamTypeAC3Audio
+	clipFiles := map[string]*StreamClipFile{"00001.CLPI": {Name: "00001.CLPI", Streams: map[uint16]stream.Info{
+		4352: audio,
+		6144: &stream.Stream{PID: 6144, StreamType: 0x05},
+	}}}
+
+	p := NewPlaylistFile(&memFileInfo{name: "00800.mpls", data: testMPLS([][2]uint32{{0, 45000 * 60}})}, settings.Default(""))
+	if err := p.Scan(map[string]*StreamFile{}, clipFiles); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	if len(p.OtherStreams) != 1 || p.OtherStreams[0].Base().PID != 6144 {
+		t.Fatalf("OtherStreams got=%v", p.OtherStreams)
+	}
+	if got := stream.OtherCodecName(p.OtherStreams[0].Base().StreamType); got != "Other (0x05)" {
+		t.Fatalf("OtherCodecName() got=%q", got)
+	}
+	if len(p.SortedStreams) != 1 || p.SortedStreams[0].Base().PID != 4352 {
+		t.Fatalf("SortedStreams got=%v", p.SortedStreams)
+	}
+}
@@ -0,0 +1,106 @@
+package bdrom
+
+import (
+	"math"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// DeclaredRateTolerance is the share by which a measured rate may differ from
+// the declared one before a RateCheck is flagged.
+const DeclaredRateTolerance = 0.05
+
+// Kinds of RateCheck.
+const (
+	// RateCheckRecordingRate compares the clip info's TS_recording_rate, the
+	// most the clip may need, with the average TS bitrate of the stream file.
+	RateCheckRecordingRate = "TS recording rate"
+	// RateCheckSourcePackets compares the clip info's source packet count
+	// with the packets the stream file holds.
+	RateCheckSourcePackets = "Source packets"
+	// RateCheckStream compares the nominal bitrate of a constant bitrate
+	// stream, from its codec headers, with its measured bitrate.
+	RateCheckStream = "Stream"
+)
+
+// RateCheck compares a figure the disc declares with what the scan measured.
+// Rates are in bits per second; RateCheckSourcePackets counts packets.
+type RateCheck struct {
+	File string
+	Kind string
+	// Stream is the checked stream of RateCheckStream rows.
+	Stream   stream.Info
+	Declared float64
+	Measured float64
+	// Mismatch is set when the measured rate exceeds the recording rate, the
+	// packet counts differ, or a stream rate is off by more than
+	// DeclaredRateTolerance.
+	Mismatch bool
+}
+
+// Delta is the relative difference of Measured from Declared.
+func (c RateCheck) Delta() float64 {
+	if c.Declared == 0 {
+		return 0
+	}
+	return (c.Measured - c.Declared) / c.Declared
+}
+
+// DeclaredRateChecks compares the clip info of each stream file the playlist
+// plays, once per file, with the scan: its recording rate and source packet
+// count, and the nominal rate of each constant bitrate stream. Rates need a
+// scan that measured the file; quick scans only get the packet count rows.
+func (p *PlaylistFile) DeclaredRateChecks() []RateCheck {
+	var checks []RateCheck
+	seen := map[string]bool{}
+	for _, clip := range p.StreamClips {
+		file, clipInfo := clip.StreamFile, clip.StreamClipFile
+		if file == nil || clipInfo == nil || seen[file.Name] {
+			continue
+		}
+		seen[file.Name] = true
+
+		packets := float64(file.Size / 192)
+		if clipInfo.SourcePacketCount > 0 {
+			checks = append(checks, RateCheck{
+				File:     file.Name,
+				Kind:     RateCheckSourcePackets,
+				Declared: float64(clipInfo.SourcePacketCount),
+				Measured: packets,
+				Mismatch: float64(clipInfo.SourcePacketCount) != packets,
+			})
+		}
+		if file.Length <= 0 {
+			continue
+		}
+		if clipInfo.TSRecordingRate > 0 {
+			declared := float64(clipInfo.TSRecordingRate) * 8
+			measured := packets * 188 * 8 / file.Length
+			checks = append(checks, RateCheck{
+				File:     file.Name,
+				Kind:     RateCheckRecordingRate,
+				Declared: declared,
+				Measured: measured,
+				Mismatch: measured > declared*(1+DeclaredRateTolerance),
+			})
+		}
+		for _, pid := range stream.SortedPIDs(file.Streams) {
+			info := file.Streams[pid]
+			base := info.Base()
+			if base.IsVBR || base.BitRate <= 0 || base.PayloadBytes == 0 || !base.IsAudioStream() {
+				continue
+			}
+			declared := float64(base.BitRate)
+			measured := math.RoundToEven(float64(base.PayloadBytes) * 8 / file.Length)
+			checks = append(checks, RateCheck{
+				File:     file.Name,
+				Kind:     RateCheckStream,
+				Stream:   info,
+				Declared: declared,
+				Measured: measured,
+				Mismatch: math.Abs(measured-declared) > declared*DeclaredRateTolerance,
+			})
+		}
+	}
+	return checks
+}
@@ -0,0 +1,47 @@
+package bdrom
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestDeclaredRateChecks(t *testing.T) {
+	ac3 := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio, BitRate: 640_000, PayloadBytes: 8_000_000}}
+	dts := &stream.AudioStream{Stream: stream.Stream{PID: 0x1101, StreamType: stream.StreamTypeDTSAudio, BitRate: 1_509_000, PayloadBytes: 15_000_000}}
+	truehd := &stream.AudioStream{Stream: stream.Stream{PID: 0x1102, StreamType: stream.StreamTypeAC3TrueHDAudio, IsVBR: true, BitRate: 4_000_000, PayloadBytes: 50_000_000}}
+	file := &StreamFile{
+		Name:    "00001.M2TS",
+		Size:    192 * 1_000_000,
+		Length:  100,
+		Streams: map[uint16]stream.Info{0x1100: ac3, 0x1101: dts, 0x1102: truehd},
+	}
+	clipInfo := &StreamClipFile{Name: "00001.CLPI", TSRecordingRate: 1_500_000, SourcePacketCount: 1_000_001}
+	playlist := &PlaylistFile{StreamClips: []*StreamClip{
+		{Name: "00001.M2TS", StreamFile: file, StreamClipFile: clipInfo},
+		{Name: "00001.M2TS", StreamFile: file, StreamClipFile: clipInfo},
+	}}
+
+	checks := playlist.DeclaredRateChecks()
+	if len(checks) != 4 {
+		t.Fatalf("DeclaredRateChecks() got %d rows, want 4: %+v", len(checks), checks)
+	}
+	packets, rate, ac3Check, dtsCheck := checks[0], checks[1], checks[2], checks[3]
+	if packets.Kind != RateCheckSourcePackets || packets.Declared != 1_000_001 || packets.Measured != 1_000_000 || !packets.Mismatch {
+		t.Fatalf("source packets got=%+v", packets)
+	}
+	// 1,000,000 packets * 188 bytes over 100 s is 15.04 Mbps, above the
+	// declared 12 Mbps.
+	if rate.Kind != RateCheckRecordingRate || rate.Declared != 12_000_000 || rate.Measured != 15_040_000 || !rate.Mismatch {
+		t.Fatalf("recording rate got=%+v", rate)
+	}
+	if ac3Check.Stream != ac3 || ac3Check.Measured != 640_000 || ac3Check.Mismatch {
+		t.Fatalf("AC3 check got=%+v", ac3Check)
+	}
+	if dtsCheck.Stream != dts || dtsCheck.Measured != 1_200_000 || !dtsCheck.Mismatch {
+		t.Fatalf("DTS check got=%+v", dtsCheck)
+	}
+	if delta := dtsCheck.Delta(); delta > -0.2 || delta < -0.21 {
+		t.Fatalf("DTS Delta() got=%v", delta)
+	}
+}
@@ -0,0 +1,140 @@
+package bdrom
+
+import "github.com/autobrr/go-bdinfo/internal/stream"
+
+// audioDropoutTicks is the shortest gap or silence, in 90 kHz ticks, recorded
+// as an AudioDropout: two seconds, well above the few milliseconds between the
+// PES packets of a lossless track.
+const audioDropoutTicks = 2 * 90000
+
+// lpcmHeaderSize is the Blu-ray LPCM header at the start of each PES payload.
+const lpcmHeaderSize = 4
+
+// AudioDropout is a stretch of a lossless audio stream found by a full scan:
+// a gap between PES packets (Silence false) or LPCM sample data that is all
+// zero (Silence true). Start and End are seconds on the stream file's clock,
+// the clock of StreamClip.TimeIn/TimeOut.
+type AudioDropout struct {
+	PID     uint16
+	Start   float64
+	End     float64
+	Silence bool
+}
+
+// audioContinuity follows the PES timestamps of one LPCM or TrueHD stream,
+// and for LPCM whether each packet carries only zero samples.
+type audioContinuity struct {
+	pid  uint16
+	lpcm bool
+
+	// Current PES packet.
+	pts     uint64
+	bytes   int
+	silent  bool
+	started bool
+
+	// Previous packets.
+	lastPTS     uint64
+	seen        bool
+	silentFrom  uint64
+	silentUntil uint64
+	silentRun   bool
+}
+
+// newAudioContinuity returns a tracker for the LPCM and TrueHD streams of a
+// full scan; nil otherwise.
+func newAudioContinuity(pid uint16, st stream.Info, full bool) *audioContinuity {
+	if !full || st == nil {
+		return nil
+	}
+	switch st.Base().StreamType {
+	case stream.StreamTypeLPCMAudio:
+		return &audioContinuity{pid: pid, lpcm: true}
+	case stream.StreamTypeAC3TrueHDAudio:
+		return &audioContinuity{pid: pid}
+	}
+	return nil
+}
+
+// payload accounts the payload bytes of the current PES packet, taking its
+// PTS from the parsed header.
+func (a *audioContinuity) payload(state *streamState, payload []byte) {
+	if !a.started {
+		if state.pesPtsDtsFlags&0x02 == 0 || len(state.pesHeaderBuf) < 14 {
+			return
+		}
+		a.pts = parsePTS(state.pesHeaderBuf[9:14])
+		a.started = true
+		a.silent = true
+	}
+	if a.lpcm && a.silent {
+		samples := payload
+		if skip := lpcmHeaderSize - a.bytes; skip > 0 {
+			samples = samples[min(skip, len(samples)):]
+		}
+		for _, b := range samples {
+			if b != 0 {
+				a.silent = false
+				break
+			}
+		}
+	}
+	a.bytes += len(payload)
+}
+
+// endAudioPES closes the current PES packet and records the gap before it, or
+// the silence it ends.
+func (s *StreamFile) endAudioPES(a *audioContinuity) {
+	if !a.started {
+		return
+	}
+	pts := a.pts
+	silent := a.lpcm && a.silent && a.bytes > lpcmHeaderSize
+	a.started = false
+	a.bytes = 0
+
+	if a.seen && pts > a.lastPTS && pts-a.lastPTS >= audioDropoutTicks {
+		s.closeSilence(a)
+		s.addAudioDropout(a.pid, a.lastPTS, pts, false)
+	}
+	if pts > a.lastPTS || !a.seen {
+		if silent {
+			if !a.silentRun {
+				a.silentRun = true
+				a.silentFrom = pts
+			}
+			a.silentUntil = pts
+		} else {
+			a.silentUntil = pts
+			s.closeSilence(a)
+		}
+		a.lastPTS = pts
+	}
+	a.seen = true
+}
+
+// flushAudio closes the last PES packet and any silence running at the end
+// of the file.
+func (s *StreamFile) flushAudio(a *audioContinuity) {
+	s.endAudioPES(a)
+	s.closeSilence(a)
+}
+
+func (s *StreamFile) closeSilence(a *audioContinuity) {
+	if !a.silentRun {
+		return
+	}
+	a.silentRun = false
+	if a.silentUntil-a.silentFrom >= audioDropoutTicks {
+		s.addAudioDropout(a.pid, a.silentFrom, a.silentUntil, true)
+	}
+}
+
+func (s *StreamFile) addAudioDropout(pid uint16, from, to uint64, silence bool) {
+	s.AudioDropouts = append(s.AudioDropouts, AudioDropout{
+		PID:     pid,
+		Start:   float64(from) / 90000.0,
+		End:     float64(to) / 90000.0,
+		Silence: silence,
+	})
+}
@@ -0,0 +1,76 @@
+package bdrom
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// lpcmPES builds an LPCM PES packet presented at pts whose samples are all
+// the given byte.
+func lpcmPES(pts uint64, sample byte) []byte {
+	body := make([]byte, 4+64)
+	body[0], body[1], body[2] = 0x00, 0x40, 0x31 // LPCM header: 48 kHz stereo 16-bit
+	for i := 4; i < len(body); i++ {
+		body[i] = sample
+	}
+	pts5 := encodePTS(0x20, pts)
+	pesLen := 3 + 5 + len(body)
+	pes := []byte{0x00, 0x00, 0x01, 0xBD, byte(pesLen >> 8), byte(pesLen), 0x80, 0x80, 0x05}
+	pes = append(pes, pts5[:]...)
+	return append(pes, body...)
+}
+
+func TestStreamFileScan_AudioDropouts(t *testing.T) {
+	const pid = 0x1100
+	const second = 90000
+	var data []byte
+	add := func(pts uint64, sample byte) {
+		pkt := tsPacket188(pid, true, lpcmPES(pts, sample))
+		data = append(data, pkt[:]...)
+	}
+	// Sound from 10s, a 3s gap after 11s, 2.5s of silence from 15s, then
+	// sound to 20s and a short 0.5s silence.
+	for pts := uint64(10 * second); pts <= 11*second; pts += second / 2 {
+		add(pts, 0x11)
+	}
+	for pts := uint64(14 * second); pts < 15*second; pts += second / 2 {
+		add(pts, 0x11)
+	}
+	for pts := uint64(15 * second); pts < 17*second+second/2; pts += second / 2 {
+		add(pts, 0x00)
+	}
+	for pts := uint64(17*second + second/2); pts <= 20*second; pts += second / 2 {
+		add(pts, 0x22)
+	}
+	add(20*second+second/2, 0x00)
+
+	scan := func(full bool) *StreamFile {
+		s := NewStreamFile(&memFileInfo{name: "00001.M2TS", data: data})
+		as := &stream.AudioStream{}
+		as.PID = pid
+		as.StreamType = stream.StreamTypeLPCMAudio
+		s.Streams[pid] = as
+		if err := s.Scan(nil, full); err != nil {
+			t.Fatalf("Scan() error: %v", err)
+		}
+		return s
+	}
+
+	if s := scan(false); len(s.AudioDropouts) != 0 {
+		t.Fatalf("scan without full recorded dropouts: %+v", s.AudioDropouts)
+	}
+	got := scan(true).AudioDropouts
+	want := []AudioDropout{
+		{PID: pid, Start: 11, End: 14},
+		{PID: pid, Start: 15, End: 17.5, Silence: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("dropouts %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("dropout %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
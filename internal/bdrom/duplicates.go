@@ -0,0 +1,113 @@
+package bdrom
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// obfuscationGroupSize is the number of interchangeable playlists from which a
+// group reads as deliberate obfuscation rather than an authoring leftover.
+const obfuscationGroupSize = 5
+
+// PlaylistGroup is a set of playlists that play the same clip time ranges, in
+// any order, with the same streams and angles, and so report the same length
+// and size. Representative is the member a report keeps: the one index.bdmv
+// Title 1 plays, else one a title plays, else the first by name. Reordered is
+// set when members play the clips in different orders, the usual sign of
+// playlist obfuscation.
+type PlaylistGroup struct {
+	Representative string
+	Playlists      []string
+	Reordered      bool
+}
+
+// GroupDuplicatePlaylists fingerprints playlists by their main angle play
+// items (clip, in and out time), angle count and stream PIDs, and returns
+// the groups with more than one member, ordered by representative name.
+func GroupDuplicatePlaylists(playlists []*PlaylistFile) []PlaylistGroup {
+	byKey := map[string][]*PlaylistFile{}
+	var keys []string
+	for _, playlist := range playlists {
+		if playlist == nil || len(playlist.StreamClips) == 0 {
+			continue
+		}
+		key := playlistFingerprint(playlist)
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], playlist)
+	}
+
+	var groups []PlaylistGroup
+	for _, key := range keys {
+		members := byKey[key]
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+		group := PlaylistGroup{Representative: groupRepresentative(members).Name}
+		order := clipOrder(members[0])
+		for _, member := range members {
+			group.Playlists = append(group.Playlists, member.Name)
+			if !group.Reordered && !slices.Equal(clipOrder(member), order) {
+				group.Reordered = true
+			}
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Representative < groups[j].Representative })
+	return groups
+}
+
+// PlaylistObfuscation reports whether groups look like deliberate playlist
+// obfuscation: members playing the same clips in different orders, or many
+// interchangeable copies of one playlist.
+func PlaylistObfuscation(groups []PlaylistGroup) bool {
+	for _, group := range groups {
+		if group.Reordered || len(group.Playlists) >= obfuscationGroupSize {
+			return true
+		}
+	}
+	return false
+}
+
+func playlistFingerprint(playlist *PlaylistFile) string {
+	items := clipOrder(playlist)
+	sort.Strings(items)
+	pids := make([]int, 0, len(playlist.PlaylistStreams))
+	for pid := range playlist.PlaylistStreams {
+		pids = append(pids, int(pid))
+	}
+	sort.Ints(pids)
+	return fmt.Sprintf("%s|%d|%v", strings.Join(items, ","), playlist.AngleCount, pids)
+}
+
+// clipOrder lists the main angle play items in playlist order.
+func clipOrder(playlist *PlaylistFile) []string {
+	items := make([]string, 0, len(playlist.StreamClips))
+	for _, clip := range playlist.StreamClips {
+		if clip.AngleIndex != 0 {
+			continue
+		}
+		items = append(items, fmt.Sprintf("%s@%.3f-%.3f", clip.Name, clip.TimeIn, clip.TimeOut))
+	}
+	return items
+}
+
+// groupRepresentative picks the member index.bdmv navigation plays, members
+// being sorted by name.
+func groupRepresentative(members []*PlaylistFile) *PlaylistFile {
+	for _, member := range members {
+		if member.ReachableFromTitle1 {
+			return member
+		}
+	}
+	for _, member := range members {
+		if !member.Orphaned {
+			return member
+		}
+	}
+	return members[0]
+}
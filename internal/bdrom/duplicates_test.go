@@ -0,0 +1,52 @@
+package bdrom
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestGroupDuplicatePlaylists(t *testing.T) {
+	clip := func(name string, in, out float64) *StreamClip {
+		return &StreamClip{Name: name, TimeIn: in, TimeOut: out}
+	}
+	playlist := func(name string, clips ...*StreamClip) *PlaylistFile {
+		return &PlaylistFile{
+			Name:            name,
+			StreamClips:     clips,
+			PlaylistStreams: map[uint16]stream.Info{0x1011: &stream.VideoStream{}},
+		}
+	}
+	a, b, c := clip("00001.M2TS", 0, 600), clip("00002.M2TS", 0, 600), clip("00003.M2TS", 0, 600)
+	playlists := []*PlaylistFile{
+		playlist("00802.MPLS", b, a, c),
+		playlist("00800.MPLS", a, b, c),
+		playlist("00801.MPLS", c, b, a),
+		playlist("00010.MPLS", clip("00010.M2TS", 0, 30)),
+		playlist("00011.MPLS", clip("00010.M2TS", 0, 30)),
+		// Same clips with another stream selection is a distinct playlist.
+		{Name: "00900.MPLS", StreamClips: []*StreamClip{a, b, c}, PlaylistStreams: map[uint16]stream.Info{}},
+	}
+	playlists[2].ReachableFromTitle1 = true
+
+	groups := GroupDuplicatePlaylists(playlists)
+	if len(groups) != 2 {
+		t.Fatalf("groups got=%+v", groups)
+	}
+	if groups[0].Representative != "00010.MPLS" || groups[0].Reordered || !slices.Equal(groups[0].Playlists, []string{"00010.MPLS", "00011.MPLS"}) {
+		t.Fatalf("identical group got=%+v", groups[0])
+	}
+	if groups[1].Representative != "00801.MPLS" || !groups[1].Reordered || !slices.Equal(groups[1].Playlists, []string{"00800.MPLS", "00801.MPLS", "00802.MPLS"}) {
+		t.Fatalf("reordered group got=%+v", groups[1])
+	}
+	if !PlaylistObfuscation(groups) {
+		t.Fatalf("expected obfuscation for reordered copies")
+	}
+	if PlaylistObfuscation(groups[:1]) {
+		t.Fatalf("one identical copy is not obfuscation")
+	}
+	if PlaylistObfuscation([]PlaylistGroup{{Playlists: []string{"1", "2", "3", "4", "5"}}}) == false {
+		t.Fatalf("expected obfuscation for %d copies", obfuscationGroupSize)
+	}
+}
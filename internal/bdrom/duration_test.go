@@ -0,0 +1,88 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// testMPLS builds a playlist of stream-less play items, one per in/out pair
+// (45kHz ticks), with an empty chapter table.
+func testMPLS(items [][2]uint32) []byte {
+	clips := make([]string, len(items))
+	for i := range items {
+		clips[i] = strings.Repeat("0", 4) + string(rune('1'+i))
+	}
+	return testClipsMPLS(clips, items)
+}
+
+// testClipsMPLS is testMPLS with the 5-digit clip name of each play item.
+func testClipsMPLS(clips []string, items [][2]uint32) []byte {
+	const playlistOffset = 0x40
+	var list []byte
+	for i, item := range items {
+		body := []byte(clips[i] + "M2TS")
+		body = append(body, 0x00, 0x00, 0x00) // connection, stc_id
+		body = binary.BigEndian.AppendUint32(body, item[0])
+		body = binary.BigEndian.AppendUint32(body, item[1])
+		body = append(body, make([]byte, 12)...) // UO mask, flags, still
+		body = append(body, make([]byte, 16)...) // STN_table with no streams
+		list = binary.BigEndian.AppendUint16(list, uint16(len(body)))
+		list = append(list, body...)
+	}
+
+	data := make([]byte, playlistOffset)
+	copy(data, "MPLS0200")
+	data = binary.BigEndian.AppendUint32(data, uint32(6+len(list)))
+	data = binary.BigEndian.AppendUint16(data, 0)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(items)))
+	data = binary.BigEndian.AppendUint16(data, 0)
+	data = append(data, list...)
+	chaptersOffset := len(data)
+	data = append(data, 0, 0, 0, 2, 0, 0)
+	binary.BigEndian.PutUint32(data[8:], playlistOffset)
+	binary.BigEndian.PutUint32(data[12:], uint32(chaptersOffset))
+	return data
+}
+
+func TestPlaylistScan_DurationWarnings(t *testing.T) {
+	clipFiles := map[string]*StreamClipFile{}
+	for i := range 2 {
+		name := "0000" + string(rune('1'+i)) + ".CLPI"
+		clipFiles[name] = &StreamClipFile{Name: name, Streams: map[uint16]stream.Info{}}
+	}
+
+	t.Run("out before in", func(t *testing.T) {
+		data := testMPLS([][2]uint32{{0, 45000 * 60}, {45000 * 10, 45000 * 5}})
+		p := NewPlaylistFile(&memFileInfo{name: "00800.mpls", data: data}, settings.Default(""))
+		if err := p.Scan(map[string]*StreamFile{}, clipFiles); err != nil {
+			t.Fatalf("Scan() error: %v", err)
+		}
+		if got := p.StreamClips[1].Length; got != 0 {
+			t.Fatalf("clamped clip length got=%v want=0", got)
+		}
+		if got := p.TotalLength(); got != 60 {
+			t.Fatalf("TotalLength() got=%v want=60", got)
+		}
+		if len(p.DurationWarnings) != 1 || p.DurationWarnings[0].Clip != "00002.M2TS" {
+			t.Fatalf("DurationWarnings got=%+v", p.DurationWarnings)
+		}
+	})
+
+	t.Run("over 24 hours", func(t *testing.T) {
+		data := testMPLS([][2]uint32{{0, 45000 * 13 * 3600}, {0, 45000 * 13 * 3600}})
+		p := NewPlaylistFile(&memFileInfo{name: "00801.mpls", data: data}, settings.Default(""))
+		if err := p.Scan(map[string]*StreamFile{}, clipFiles); err != nil {
+			t.Fatalf("Scan() error: %v", err)
+		}
+		if got := p.TotalLength(); got != 26*3600 {
+			t.Fatalf("TotalLength() got=%v want=%v", got, 26*3600)
+		}
+		if len(p.DurationWarnings) != 1 || p.DurationWarnings[0].Clip != "" || !strings.Contains(p.DurationWarnings[0].Message, "24 hours") {
+			t.Fatalf("DurationWarnings got=%+v", p.DurationWarnings)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+func TestScan_ManyClipsStayWithinHandleBudget(t *testing.T) {
+	const clips = 500
+	const budget = 3
+
+	disc := t.TempDir()
+	for _, dir := range []string{"PLAYLIST", "CLIPINF", "STREAM"} {
+		if err := os.MkdirAll(filepath.Join(disc, "BDMV", dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clpi := make([]byte, 256)
+	copy(clpi, "HDMV0200")
+	binary.BigEndian.PutUint32(clpi[12:], 200) // ProgramInfo with no streams
+	copy(clpi[clipInfoOffset+151:], "HDMV")
+	binary.BigEndian.PutUint32(clpi[200:], 12)
+	var m2ts []byte
+	for range 8 {
+		pkt := tsPacket188(0x1011, false, nil)
+		m2ts = append(m2ts, pkt[:]...)
+	}
+	write := func(dir, name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(disc, "BDMV", dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range clips {
+		name := fmt.Sprintf("%05d", i+1)
+		write("PLAYLIST", name+".mpls", testClipsMPLS([]string{name}, [][2]uint32{{0, 45000 * 60}}))
+		write("CLIPINF", name+".clpi", clpi)
+		write("STREAM", name+".m2ts", m2ts)
+	}
+
+	cfg := settings.Default(disc)
+	cfg.MaxOpenFiles = budget
+	cfg.IOMode = IOModeParallel
+	rom, err := New(disc, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rom.Close()
+	scan := rom.Scan()
+	if scan.ScanError != nil {
+		t.Fatal(scan.ScanError)
+	}
+	for name, err := range scan.FileErrors {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	if len(rom.PlaylistFiles) != clips || len(rom.StreamFiles) != clips {
+		t.Fatalf("scanned %d playlists and %d stream files, want %d", len(rom.PlaylistFiles), len(rom.StreamFiles), clips)
+	}
+	for name, streamFile := range rom.StreamFiles {
+		if streamFile.PacketCount != 8 {
+			t.Fatalf("%s: %d packets scanned, want 8", name, streamFile.PacketCount)
+		}
+	}
+	if peak := rom.handles.Peak(); peak == 0 || peak > budget {
+		t.Fatalf("peak open files=%d, want 1..%d", peak, budget)
+	}
+	if open := rom.handles.Open(); open != 0 {
+		t.Fatalf("%d files left open after the scan", open)
+	}
+}
@@ -0,0 +1,350 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+)
+
+// IndexTitleKind identifies an index.bdmv entry.
+type IndexTitleKind string
+
+const (
+	IndexFirstPlayback IndexTitleKind = "First Playback"
+	IndexTopMenu       IndexTitleKind = "Top Menu"
+	IndexTitle         IndexTitleKind = "Title"
+)
+
+// IndexEntry is one index.bdmv entry (First Playback, Top Menu or a numbered Title)
+// with the playlists its HDMV movie object plays. BD-J entries only name their
+// BDJO; their playlists are chosen by Java code and cannot be resolved statically.
+// Unresolved is set when the movie object chain plays or jumps through a
+// register whose value is not known statically, so Playlists may be incomplete.
+type IndexEntry struct {
+	Kind        IndexTitleKind
+	Number      int
+	IsBDJ       bool
+	MovieObject int
+	BDJO        string
+	Playlists   []string
+	Unresolved  bool
+}
+
+// Label returns a display name such as "Title 1" or "Top Menu".
+func (e IndexEntry) Label() string {
+	if e.Kind == IndexTitle {
+		return fmt.Sprintf("Title %d", e.Number)
+	}
+	return string(e.Kind)
+}
+
+const indexEntrySize = 12
+
+// parseIndexBDMV decodes the First Playback, Top Menu and Title entries of index.bdmv.
+func parseIndexBDMV(data []byte) ([]IndexEntry, error) {
+	if len(data) < 12 || string(data[:4]) != "INDX" {
+		return nil, fmt.Errorf("index.bdmv: bad signature")
+	}
+	start := int(binary.BigEndian.Uint32(data[8:12]))
+	// length(4) + First Playback(12) + Top Menu(12) + number_of_Titles(2)
+	if start+4+2*indexEntrySize+2 > len(data) {
+		return nil, fmt.Errorf("index.bdmv: truncated indexes")
+	}
+	pos := start + 4
+	entries := []IndexEntry{
+		parseIndexObject(data[pos:pos+indexEntrySize], IndexFirstPlayback, 0),
+		parseIndexObject(data[pos+indexEntrySize:pos+2*indexEntrySize], IndexTopMenu, 0),
+	}
+	pos += 2 * indexEntrySize
+	count := int(binary.BigEndian.Uint16(data[pos : pos+2]))
+	pos += 2
+	for i := range count {
+		if pos+indexEntrySize > len(data) {
+			return entries, fmt.Errorf("index.bdmv: truncated title %d", i+1)
+		}
+		entries = append(entries, parseIndexObject(data[pos:pos+indexEntrySize], IndexTitle, i+1))
+		pos += indexEntrySize
+	}
+	return entries, nil
+}
+
+// parseIndexObject decodes a 12-byte object reference: object_type (2 bits) in the
+// first word, then playback_type and either an HDMV id_ref or a 5-char BDJO name.
+func parseIndexObject(raw []byte, kind IndexTitleKind, number int) IndexEntry {
+	entry := IndexEntry{Kind: kind, Number: number, MovieObject: -1}
+	switch raw[0] >> 6 {
+	case 1:
+		entry.MovieObject = int(binary.BigEndian.Uint16(raw[6:8]))
+	case 2:
+		entry.IsBDJ = true
+		entry.BDJO = string(raw[6:11])
+	}
+	return entry
+}
+
+// Navigation command fields (BD-ROM Part 3, 10.2.1).
+const (
+	navGroupBranch = 0
+	navGroupSet    = 2
+
+	navBranchJump = 1
+	navBranchPlay = 2
+
+	navJumpObject = 0
+	navCallObject = 2
+
+	navPlayPL     = 0
+	navPlayPLatPI = 1
+	navPlayPLatMK = 2
+	navSetMove    = 1
+)
+
+type movieObject struct {
+	playlists  []int
+	jumps      []int
+	unresolved bool
+}
+
+// parseMovieObjects decodes MovieObject.bdmv, collecting for each object the
+// playlists it plays and the objects it jumps to. Playlist operands held in
+// registers are resolved when the register was loaded with an immediate earlier
+// in the same object.
+func parseMovieObjects(data []byte) ([]movieObject, error) {
+	if len(data) < 40+10 || string(data[:4]) != "MOBJ" {
+		return nil, fmt.Errorf("MovieObject.bdmv: bad signature")
+	}
+	count := int(binary.BigEndian.Uint16(data[48:50]))
+	pos := 50
+	objects := make([]movieObject, 0, count)
+	for i := range count {
+		if pos+4 > len(data) {
+			return objects, fmt.Errorf("MovieObject.bdmv: truncated object %d", i)
+		}
+		cmdCount := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		pos += 4
+		if pos+cmdCount*12 > len(data) {
+			return objects, fmt.Errorf("MovieObject.bdmv: truncated commands in object %d", i)
+		}
+		objects = append(objects, decodeNavCommands(data[pos:pos+cmdCount*12]))
+		pos += cmdCount * 12
+	}
+	return objects, nil
+}
+
+func decodeNavCommands(cmds []byte) movieObject {
+	var obj movieObject
+	gpr := map[uint32]uint32{}
+	operand := func(value uint32, immediate bool) (uint32, bool) {
+		if immediate {
+			return value, true
+		}
+		if value&0x80000000 != 0 {
+			return 0, false // PSR: not statically known
+		}
+		v, ok := gpr[value&0xFFF]
+		return v, ok
+	}
+	for pos := 0; pos+12 <= len(cmds); pos += 12 {
+		cmd := cmds[pos : pos+12]
+		group := (cmd[0] >> 3) & 0x03
+		subGroup := cmd[0] & 0x07
+		immDst := cmd[1]&0x80 != 0
+		immSrc := cmd[1]&0x40 != 0
+		branchOpt := cmd[1] & 0x0F
+		setOpt := cmd[3] & 0x1F
+		dst := binary.BigEndian.Uint32(cmd[4:8])
+		src := binary.BigEndian.Uint32(cmd[8:12])
+
+		switch {
+		case group == navGroupSet && subGroup == 0 && setOpt == navSetMove:
+			if immDst || dst&0x80000000 != 0 {
+				continue
+			}
+			if v, ok := operand(src, immSrc); ok {
+				gpr[dst&0xFFF] = v
+			} else {
+				delete(gpr, dst&0xFFF)
+			}
+		case group == navGroupSet && subGroup == 0:
+			if !immDst && dst&0x80000000 == 0 {
+				delete(gpr, dst&0xFFF)
+			}
+		case group == navGroupBranch && subGroup == navBranchPlay:
+			if branchOpt != navPlayPL && branchOpt != navPlayPLatPI && branchOpt != navPlayPLatMK {
+				continue
+			}
+			if v, ok := operand(dst, immDst); ok {
+				obj.playlists = append(obj.playlists, int(v))
+			} else {
+				obj.unresolved = true
+			}
+		case group == navGroupBranch && subGroup == navBranchJump:
+			if branchOpt != navJumpObject && branchOpt != navCallObject {
+				continue
+			}
+			if v, ok := operand(dst, immDst); ok {
+				obj.jumps = append(obj.jumps, int(v))
+			} else {
+				obj.unresolved = true
+			}
+		}
+	}
+	return obj
+}
+
+// resolvePlaylists follows JumpObject/CallObject from id and returns the sorted
+// playlist names (e.g. "00800.MPLS") the object chain can play. complete is
+// false when the chain reaches a missing object or a register operand that could
+// not be resolved, so it may play more than the names returned.
+func resolvePlaylists(objects []movieObject, id int) (names []string, complete bool) {
+	complete = true
+	seen := map[int]bool{}
+	found := map[int]bool{}
+	queue := []int{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if seen[cur] {
+			continue
+		}
+		if cur < 0 || cur >= len(objects) {
+			complete = false
+			continue
+		}
+		seen[cur] = true
+		if objects[cur].unresolved {
+			complete = false
+		}
+		for _, pl := range objects[cur].playlists {
+			found[pl] = true
+		}
+		queue = append(queue, objects[cur].jumps...)
+	}
+	numbers := make([]int, 0, len(found))
+	for pl := range found {
+		numbers = append(numbers, pl)
+	}
+	sort.Ints(numbers)
+	names = make([]string, 0, len(numbers))
+	for _, pl := range numbers {
+		names = append(names, fmt.Sprintf("%05d.MPLS", pl))
+	}
+	return names, complete
+}
+
+// readIndexTitles parses index.bdmv and MovieObject.bdmv from bdmvDir. It returns
+// nil when index.bdmv is missing or malformed; title mapping is informational.
+func readIndexTitles(bdmvDir fs.DirectoryInfo) []IndexEntry {
+	indexData, err := readWholeFile(bdmvDir, "index.bdmv")
+	if err != nil {
+		return nil
+	}
+	entries, err := parseIndexBDMV(indexData)
+	if err != nil && len(entries) == 0 {
+		return nil
+	}
+	var objects []movieObject
+	if mobjData, err := readWholeFile(bdmvDir, "MovieObject.bdmv"); err == nil {
+		objects, _ = parseMovieObjects(mobjData)
+	}
+	for i := range entries {
+		if !entries[i].IsBDJ && entries[i].MovieObject >= 0 {
+			playlists, complete := resolvePlaylists(objects, entries[i].MovieObject)
+			entries[i].Playlists = playlists
+			entries[i].Unresolved = !complete
+		}
+	}
+	return entries
+}
+
+// titleReaches reports whether Title number plays the named playlist.
+func titleReaches(entries []IndexEntry, number int, playlist string) bool {
+	for _, entry := range entries {
+		if entry.Kind == IndexTitle && entry.Number == number {
+			return slices.Contains(entry.Playlists, playlist)
+		}
+	}
+	return false
+}
+
+// TitlePlaylists returns the playlists index.bdmv Title number plays, so a scan
+// can be narrowed to a disc title instead of an MPLS name. BD-J titles choose
+// their playlists at run time and are an error, as is a title that plays none.
+func (b *BDROM) TitlePlaylists(number int) ([]string, error) {
+	titles := 0
+	for _, entry := range b.IndexTitles {
+		if entry.Kind != IndexTitle {
+			continue
+		}
+		titles++
+		if entry.Number != number {
+			continue
+		}
+		switch {
+		case entry.IsBDJ:
+			return nil, fmt.Errorf("title %d is a BD-J title; its playlists are chosen at run time", number)
+		case len(entry.Playlists) == 0:
+			return nil, fmt.Errorf("title %d plays no playlist", number)
+		}
+		return entry.Playlists, nil
+	}
+	if titles == 0 {
+		return nil, fmt.Errorf("title %d not found: no titles in index.bdmv", number)
+	}
+	return nil, fmt.Errorf("title %d not found: index.bdmv has %d titles", number, titles)
+}
+
+// markOrphanedPlaylists sets Orphaned on the playlists no index.bdmv entry
+// plays. It marks nothing unless navigation resolves completely: no BD-J
+// entries, no register-driven plays or jumps, and no interactive graphics on
+// the reachable playlists, whose menu buttons can play further playlists.
+func markOrphanedPlaylists(entries []IndexEntry, playlists []*PlaylistFile) {
+	if len(entries) == 0 {
+		return
+	}
+	reachable := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsBDJ || entry.Unresolved {
+			return
+		}
+		for _, name := range entry.Playlists {
+			reachable[name] = true
+		}
+	}
+	// A reachable playlist that was not scanned may hold a menu too.
+	scanned := 0
+	for _, playlist := range playlists {
+		if playlist == nil || !reachable[playlist.Name] {
+			continue
+		}
+		if playlist.hasInteractiveGraphics() {
+			return
+		}
+		scanned++
+	}
+	if scanned < len(reachable) {
+		return
+	}
+	for _, playlist := range playlists {
+		if playlist != nil {
+			playlist.Orphaned = !reachable[playlist.Name]
+		}
+	}
+}
+
+func readWholeFile(dir fs.DirectoryInfo, name string) ([]byte, error) {
+	file, err := dir.GetFile(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := file.OpenRead()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -0,0 +1,155 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func navCmd(b0, b1, b3 byte, dst, src uint32) []byte {
+	cmd := make([]byte, 12)
+	cmd[0], cmd[1], cmd[3] = b0, b1, b3
+	binary.BigEndian.PutUint32(cmd[4:8], dst)
+	binary.BigEndian.PutUint32(cmd[8:12], src)
+	return cmd
+}
+
+func TestParseIndexAndMovieObjects(t *testing.T) {
+	// index.bdmv: First Playback -> object 0, Top Menu -> BD-J 00000, Title 1 -> object 1.
+	index := make([]byte, 40)
+	copy(index, "INDX0200")
+	binary.BigEndian.PutUint32(index[8:12], 40)
+	hdmv := func(id uint16) []byte {
+		obj := make([]byte, 12)
+		obj[0] = 0x40
+		binary.BigEndian.PutUint16(obj[6:8], id)
+		return obj
+	}
+	bdj := make([]byte, 12)
+	bdj[0] = 0x80
+	copy(bdj[6:11], "00000")
+	index = append(index, 0, 0, 0, 0)
+	index = append(index, hdmv(0)...)
+	index = append(index, bdj...)
+	index = append(index, 0, 1)
+	index = append(index, hdmv(1)...)
+
+	entries, err := parseIndexBDMV(index)
+	if err != nil {
+		t.Fatalf("parseIndexBDMV error: %v", err)
+	}
+	if len(entries) != 3 || entries[1].BDJO != "00000" || !entries[1].IsBDJ || entries[2].Label() != "Title 1" || entries[2].MovieObject != 1 {
+		t.Fatalf("parseIndexBDMV got=%+v", entries)
+	}
+
+	// MovieObject.bdmv: object 0 plays 00010 directly; object 1 loads GPR0=800,
+	// plays it via the register and calls object 2, which plays 00801.
+	objects := [][][]byte{
+		{navCmd(0x22, 0x80, 0, 10, 0)},
+		{
+			navCmd(0x50, 0x40, 0x01, 0, 800), // Move GPR0, 800
+			navCmd(0x22, 0x00, 0, 0, 0),      // PlayPL GPR0
+			navCmd(0x21, 0x82, 0, 2, 0),      // CallObject 2
+		},
+		{navCmd(0x22, 0x80, 0, 801, 0)},
+		{
+			navCmd(0x22, 0x80, 0, 802, 0), // PlayPL 802
+			navCmd(0x22, 0x00, 0, 5, 0),   // PlayPL GPR5, never set
+		},
+	}
+	mobj := make([]byte, 48)
+	copy(mobj, "MOBJ0200")
+	mobj = binary.BigEndian.AppendUint16(mobj, uint16(len(objects)))
+	for _, cmds := range objects {
+		mobj = append(mobj, 0, 0)
+		mobj = binary.BigEndian.AppendUint16(mobj, uint16(len(cmds)))
+		for _, cmd := range cmds {
+			mobj = append(mobj, cmd...)
+		}
+	}
+	parsed, err := parseMovieObjects(mobj)
+	if err != nil {
+		t.Fatalf("parseMovieObjects error: %v", err)
+	}
+	if got, complete := resolvePlaylists(parsed, 1); !complete || !slices.Equal(got, []string{"00800.MPLS", "00801.MPLS"}) {
+		t.Fatalf("resolvePlaylists(1) got=%v complete=%v", got, complete)
+	}
+	if got, complete := resolvePlaylists(parsed, 0); !complete || !slices.Equal(got, []string{"00010.MPLS"}) {
+		t.Fatalf("resolvePlaylists(0) got=%v complete=%v", got, complete)
+	}
+	if got, complete := resolvePlaylists(parsed, 3); complete || !slices.Equal(got, []string{"00802.MPLS"}) {
+		t.Fatalf("resolvePlaylists(3) got=%v complete=%v", got, complete)
+	}
+	if _, complete := resolvePlaylists(parsed, 9); complete {
+		t.Fatalf("resolvePlaylists(9) should be incomplete for a missing object")
+	}
+}
+
+func TestMarkOrphanedPlaylists(t *testing.T) {
+	newPlaylist := func(name string, ig bool) *PlaylistFile {
+		pl := &PlaylistFile{Name: name, PlaylistStreams: map[uint16]stream.Info{}}
+		if ig {
+			gs := stream.NewGraphicsStream()
+			gs.StreamType = stream.StreamTypeInteractiveGraphics
+			pl.PlaylistStreams[0x1400] = gs
+		}
+		return pl
+	}
+	entries := []IndexEntry{
+		{Kind: IndexFirstPlayback, MovieObject: 0, Playlists: []string{"00010.MPLS"}},
+		{Kind: IndexTitle, Number: 1, MovieObject: 1, Playlists: []string{"00800.MPLS"}},
+	}
+
+	playlists := []*PlaylistFile{newPlaylist("00010.MPLS", false), newPlaylist("00800.MPLS", false), newPlaylist("00900.MPLS", false)}
+	markOrphanedPlaylists(entries, playlists)
+	if playlists[0].Orphaned || playlists[1].Orphaned || !playlists[2].Orphaned {
+		t.Fatalf("expected only 00900.MPLS orphaned, got %v %v %v", playlists[0].Orphaned, playlists[1].Orphaned, playlists[2].Orphaned)
+	}
+
+	cases := map[string]struct {
+		entries   []IndexEntry
+		playlists []*PlaylistFile
+	}{
+		"no index": {nil, []*PlaylistFile{newPlaylist("00800.MPLS", false), newPlaylist("00900.MPLS", false)}},
+		"bdj": {
+			append(slices.Clone(entries), IndexEntry{Kind: IndexTopMenu, IsBDJ: true, MovieObject: -1, BDJO: "00000"}),
+			[]*PlaylistFile{newPlaylist("00010.MPLS", false), newPlaylist("00800.MPLS", false), newPlaylist("00900.MPLS", false)},
+		},
+		"unresolved": {
+			append(slices.Clone(entries), IndexEntry{Kind: IndexTopMenu, MovieObject: 2, Unresolved: true}),
+			[]*PlaylistFile{newPlaylist("00010.MPLS", false), newPlaylist("00800.MPLS", false), newPlaylist("00900.MPLS", false)},
+		},
+		"menu":        {entries, []*PlaylistFile{newPlaylist("00010.MPLS", true), newPlaylist("00800.MPLS", false), newPlaylist("00900.MPLS", false)}},
+		"not scanned": {entries, []*PlaylistFile{newPlaylist("00800.MPLS", false), newPlaylist("00900.MPLS", false)}},
+	}
+	for name, tc := range cases {
+		markOrphanedPlaylists(tc.entries, tc.playlists)
+		for _, pl := range tc.playlists {
+			if pl.Orphaned {
+				t.Fatalf("%s: %s should not be marked orphaned", name, pl.Name)
+			}
+		}
+	}
+}
+
+func TestTitlePlaylists(t *testing.T) {
+	b := &BDROM{IndexTitles: []IndexEntry{
+		{Kind: IndexFirstPlayback, MovieObject: 0, Playlists: []string{"00010.MPLS"}},
+		{Kind: IndexTitle, Number: 1, MovieObject: 1, Playlists: []string{"00800.MPLS", "00801.MPLS"}},
+		{Kind: IndexTitle, Number: 2, IsBDJ: true, MovieObject: -1, BDJO: "00001"},
+		{Kind: IndexTitle, Number: 3, MovieObject: 3},
+	}}
+	if got, err := b.TitlePlaylists(1); err != nil || !slices.Equal(got, []string{"00800.MPLS", "00801.MPLS"}) {
+		t.Fatalf("TitlePlaylists(1) got=%v err=%v", got, err)
+	}
+	for _, number := range []int{2, 3, 4} {
+		if _, err := b.TitlePlaylists(number); err == nil {
+			t.Fatalf("TitlePlaylists(%d) should fail", number)
+		}
+	}
+	if _, err := (&BDROM{}).TitlePlaylists(1); err == nil {
+		t.Fatalf("TitlePlaylists without index.bdmv should fail")
+	}
+}
@@ -0,0 +1,101 @@
+package bdrom
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	_ "image/jpeg" // register decoders for image.DecodeConfig
+	_ "image/png"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+)
+
+// maxJARSize caps how much of a single JAR is buffered for image harvesting.
+const maxJARSize = 64 << 20
+
+// JARImage is a JPEG/PNG resource (poster, menu background, ...) found inside a BD-J JAR.
+type JARImage struct {
+	JAR    string
+	Name   string
+	Format string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// harvestJARImages collects the JPEG/PNG images embedded in BDMV/JAR/*.jar.
+// Unreadable or oversized JARs are skipped; harvesting is informational only.
+func harvestJARImages(bdmvDir fs.DirectoryInfo) []JARImage {
+	jarDir, err := bdmvDir.GetDirectory("JAR")
+	if err != nil {
+		return nil
+	}
+	files, err := jarDir.GetFiles()
+	if err != nil {
+		return nil
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	var images []JARImage
+	for _, file := range files {
+		if !strings.EqualFold(path.Ext(file.Name()), ".jar") || file.Length() > maxJARSize {
+			continue
+		}
+		images = append(images, readJARImages(file)...)
+	}
+	return images
+}
+
+func readJARImages(file fs.FileInfo) []JARImage {
+	f, err := file.OpenRead()
+	if err != nil {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxJARSize))
+	f.Close()
+	if err != nil {
+		return nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil
+	}
+
+	var images []JARImage
+	for _, entry := range zr.File {
+		switch strings.ToLower(path.Ext(entry.Name)) {
+		case ".jpg", ".jpeg", ".png":
+		default:
+			continue
+		}
+		if entry.UncompressedSize64 > maxJARSize {
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			continue
+		}
+		raw, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(raw))
+		if err != nil {
+			continue
+		}
+		images = append(images, JARImage{
+			JAR:    strings.ToUpper(file.Name()),
+			Name:   entry.Name,
+			Format: format,
+			Width:  cfg.Width,
+			Height: cfg.Height,
+			Data:   raw,
+		})
+	}
+	return images
+}
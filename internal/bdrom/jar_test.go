@@ -0,0 +1,63 @@
+package bdrom
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+)
+
+func TestHarvestJARImages(t *testing.T) {
+	root := t.TempDir()
+	jarDir := filepath.Join(root, "BDMV", "JAR")
+	if err := os.MkdirAll(jarDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 32, 18))); err != nil {
+		t.Fatal(err)
+	}
+	var jar bytes.Buffer
+	zw := zip.NewWriter(&jar)
+	for name, data := range map[string][]byte{
+		"images/bg.png":          pngData.Bytes(),
+		"images/broken.jpg":      []byte("not a jpeg"),
+		"com/example/Xlet.class": []byte{0xCA, 0xFE, 0xBA, 0xBE},
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(jarDir, "00000.jar"), jar.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bdmv, err := fs.NewDiskFileSystem().GetDirectoryInfo(filepath.Join(root, "BDMV"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	images := harvestJARImages(bdmv)
+	if len(images) != 1 {
+		t.Fatalf("harvestJARImages got=%d images want 1", len(images))
+	}
+	got := images[0]
+	if got.JAR != "00000.JAR" || got.Name != "images/bg.png" || got.Format != "png" || got.Width != 32 || got.Height != 18 {
+		t.Fatalf("harvestJARImages got=%+v", got)
+	}
+	if !bytes.Equal(got.Data, pngData.Bytes()) {
+		t.Fatalf("harvestJARImages data mismatch")
+	}
+}
@@ -0,0 +1,156 @@
+package bdrom
+
+import "encoding/binary"
+
+// Offsets3D is the 3D graphics offset metadata of one play item, read from the
+// playlist's STN_table_SS extension. The offset values themselves are carried
+// in the MVC dependent-view video; the playlist says how many offset sequences
+// there are and which one each subtitle stream follows.
+type Offsets3D struct {
+	// Clip is the stream file of the play item.
+	Clip string
+	// OffsetSequences is number_of_offset_sequences of the dependent view.
+	OffsetSequences        int
+	FixedOffsetDuringPopUp bool
+	Subtitles              []SubtitleOffset3D
+}
+
+// SubtitleOffset3D ties one PG/text subtitle stream to its offset sequence.
+// Stereoscopic subtitles (is_SS_PG) have separate left and right eye streams,
+// which use StereoscopicOffsetSequence instead.
+type SubtitleOffset3D struct {
+	PID                        uint16
+	OffsetSequence             int
+	Stereoscopic               bool
+	StereoscopicOffsetSequence int
+}
+
+// ssPlayItem holds what the STN_table_SS of a play item is laid out by: the
+// primary video and PG/text subtitle entries of its STN_table.
+type ssPlayItem struct {
+	clip         string
+	videoCount   int
+	subtitlePIDs []uint16
+}
+
+// parseOffsets3D reads the STN_table_SS of each play item from the MPLS
+// ExtensionData at offset. It returns nil for 2D playlists and stops at the
+// first truncated entry.
+func parseOffsets3D(data []byte, offset int, items []ssPlayItem) []Offsets3D {
+	block := findMPLSExtension(data, offset, 2, 2)
+	if block == nil {
+		return nil
+	}
+
+	var out []Offsets3D
+	pos := 0
+	for _, item := range items {
+		if pos+2 > len(block) {
+			break
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(block[pos:]))
+		if end > len(block) {
+			break
+		}
+		entry, ok := parseSTNTableSS(block[pos+2:end], item)
+		if !ok {
+			break
+		}
+		out = append(out, entry)
+		pos = end
+	}
+	return out
+}
+
+func parseSTNTableSS(body []byte, item ssPlayItem) (Offsets3D, bool) {
+	entry := Offsets3D{Clip: item.clip}
+	if len(body) < 2 {
+		return entry, false
+	}
+	entry.FixedOffsetDuringPopUp = body[0]&0x80 != 0
+	pos := 2
+	// skip steps over a length-prefixed stream_entry or stream_attributes.
+	skip := func() bool {
+		if pos >= len(body) {
+			return false
+		}
+		pos += 1 + int(body[pos])
+		return pos <= len(body)
+	}
+
+	for range item.videoCount {
+		// Dependent view stream_entry and stream_attributes_SS, then
+		// reserved(10) number_of_offset_sequences(6).
+		if !skip() || !skip() || pos+2 > len(body) {
+			return entry, false
+		}
+		entry.OffsetSequences = max(entry.OffsetSequences, int(body[pos+1]&0x3F))
+		pos += 2
+	}
+
+	for _, pid := range item.subtitlePIDs {
+		// PG_textST_offset_sequence_id_ref(8) reserved(4) is_SS_PG(1)
+		// is_top_AS_PG_textST(1) is_bottom_AS_PG_textST(1) reserved(1).
+		if pos+2 > len(body) {
+			return entry, false
+		}
+		sub := SubtitleOffset3D{PID: pid, OffsetSequence: int(body[pos])}
+		flags := body[pos+1]
+		pos += 2
+		if flags&0x08 != 0 {
+			// Left and right eye stream_entry, reserved(8),
+			// SS_PG_textST_offset_sequence_id_ref(8).
+			if !skip() || !skip() || pos+2 > len(body) {
+				return entry, false
+			}
+			sub.Stereoscopic = true
+			sub.StereoscopicOffsetSequence = int(body[pos+1])
+			pos += 2
+		}
+		for _, present := range []bool{flags&0x04 != 0, flags&0x02 != 0} {
+			// Top/bottom active-area stream_entry, reserved(2) offset_sequence_id_ref(6).
+			if !present {
+				continue
+			}
+			if !skip() || pos+1 > len(body) {
+				return entry, false
+			}
+			pos++
+		}
+		entry.Subtitles = append(entry.Subtitles, sub)
+	}
+	return entry, true
+}
+
+// findMPLSExtension returns the data of the ExtensionData entry with the given
+// IDs; offset is the ExtensionData start from the MPLS header.
+func findMPLSExtension(data []byte, offset int, id1, id2 uint16) []byte {
+	if offset <= 0 || offset+4 > len(data) {
+		return nil
+	}
+	length := int(binary.BigEndian.Uint32(data[offset:]))
+	// length(4) data_block_start_address(4) reserved(3) number_of_ext_data_entries(1).
+	if length == 0 || offset+12 > len(data) {
+		return nil
+	}
+	count := int(data[offset+11])
+	pos := offset + 12
+	for range count {
+		if pos+12 > len(data) {
+			return nil
+		}
+		entryID1 := binary.BigEndian.Uint16(data[pos:])
+		entryID2 := binary.BigEndian.Uint16(data[pos+2:])
+		start := offset + int(binary.BigEndian.Uint32(data[pos+4:]))
+		end := start + int(binary.BigEndian.Uint32(data[pos+8:]))
+		pos += 12
+		if entryID1 != id1 || entryID2 != id2 {
+			continue
+		}
+		if start < offset || end > len(data) || start > end {
+			return nil
+		}
+		return data[start:end]
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestParseOffsets3D(t *testing.T) {
+	body := []byte{
+		0x80, 0x00, // Fixed_offset_during_PopUp_flag, reserved
+		3, 0x01, 0x10, 0x12, // dependent view stream_entry
+		2, 0x20, 0x00, // stream_attributes_SS
+		0x00, 0x20, // 32 offset sequences
+		5, 0x00, // PG 0x1200: offset sequence 5
+		1, 0x0C, // PG 0x1201: offset sequence 1, is_SS_PG, top active area
+		1, 0xAA, 1, 0xBB, 0x00, 7, // left/right stream_entry, SS offset sequence 7
+		1, 0xCC, 0x03, // top active area stream_entry and offset sequence
+	}
+	block := binary.BigEndian.AppendUint16(nil, uint16(len(body)))
+	block = append(block, body...)
+
+	const extOffset = 16
+	data := make([]byte, extOffset)
+	data = binary.BigEndian.AppendUint32(data, uint32(24+len(block))) // length
+	data = binary.BigEndian.AppendUint32(data, 24)                    // data_block_start_address
+	data = append(data, 0, 0, 0, 1)                                   // reserved, one entry
+	data = binary.BigEndian.AppendUint16(data, 2)
+	data = binary.BigEndian.AppendUint16(data, 2)
+	data = binary.BigEndian.AppendUint32(data, 24)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(block)))
+	data = append(data, block...)
+
+	items := []ssPlayItem{{clip: "00001.M2TS", videoCount: 1, subtitlePIDs: []uint16{0x1200, 0x1201}}}
+	got := parseOffsets3D(data, extOffset, items)
+	want := []Offsets3D{{
+		Clip:                   "00001.M2TS",
+		OffsetSequences:        32,
+		FixedOffsetDuringPopUp: true,
+		Subtitles: []SubtitleOffset3D{
+			{PID: 0x1200, OffsetSequence: 5},
+			{PID: 0x1201, OffsetSequence: 1, Stereoscopic: true, StereoscopicOffsetSequence: 7},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseOffsets3D = %+v\nwant %+v", got, want)
+	}
+
+	if got := parseOffsets3D(data[:len(data)-3], extOffset, items); got != nil {
+		t.Fatalf("truncated extension = %+v want nil", got)
+	}
+	if got := parseOffsets3D(data, 0, items); got != nil {
+		t.Fatalf("no extension data = %+v want nil", got)
+	}
+}
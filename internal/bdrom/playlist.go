@@ -1,6 +1,7 @@
 package bdrom
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io"
 	"math"
@@ -14,6 +15,18 @@ import (
 	"github.com/autobrr/go-bdinfo/internal/util"
 )
 
+// maxPlausibleLength is the playlist length, in seconds, above which the
+// duration is reported as suspicious. Looping menus and authoring mistakes
+// can produce 26-hour playlists; they are kept, but flagged.
+const maxPlausibleLength = 24 * 60 * 60
+
+// DurationWarning is a play item time problem found while parsing a playlist.
+// Clip is empty for warnings about the playlist as a whole.
+type DurationWarning struct {
+	Clip    string
+	Message string
+}
+
 type PlaylistFile struct {
 	FileInfo        fs.FileInfo
 	Name            string
@@ -23,9 +36,43 @@ type PlaylistFile struct {
 	HasHiddenTracks bool
 	HasLoops        bool
 	IsCustom        bool
-	MVCBaseViewR    bool
+	// IsBDAV is set for BDAV recorder playlists (.rpls/.vpls, "PLST" files).
+	// Their play items carry no STN table, so the clip info streams are all
+	// reported, and their marks are not read as chapters.
+	IsBDAV       bool
+	MVCBaseViewR bool
+	// ReachableFromTitle1 is set when index.bdmv Title 1 plays this playlist
+	// through its HDMV movie object.
+	ReachableFromTitle1 bool
+	// Orphaned is set when index.bdmv navigation resolves completely and no
+	// entry plays this playlist; see markOrphanedPlaylists.
+	Orphaned bool
+
+	// AppInfoPlayList playback conditions.
+	PlaybackType           byte
+	PlaybackCount          uint16
+	UOMask                 UOMask
+	RandomAccessRestricted bool
 
 	Chapters []float64
+	// ChapterNames are the chapter titles from the disc's title name metadata
+	// (META/TN), by chapter index; nil when the disc names none.
+	ChapterNames []string
+
+	// AudioSegments lists audio PIDs whose codec or language changes between
+	// play items; nil when every item agrees with the reference clip.
+	AudioSegments []AudioSegments
+
+	// Offsets3D holds the 3D graphics offset metadata per play item; nil for
+	// 2D playlists.
+	Offsets3D []Offsets3D
+
+	// DurationWarnings notes play item times that were clamped or look
+	// implausible (see maxPlausibleLength).
+	DurationWarnings []DurationWarning
+	// StructureWarnings lists section addresses and lengths that do not fit
+	// the file (see checkStructure); the playlist may still have parsed.
+	StructureWarnings []string
 
 	Streams         map[uint16]stream.Info
 	PlaylistStreams map[uint16]stream.Info
@@ -39,6 +86,9 @@ type PlaylistFile struct {
 	AudioStreams    []*stream.AudioStream
 	TextStreams     []*stream.TextStream
 	GraphicsStreams []*stream.GraphicsStream
+	// OtherStreams are clip streams whose coding type is none of the above,
+	// in PID order. They are reported separately and left out of SortedStreams.
+	OtherStreams []stream.Info
 }
 
 func NewPlaylistFile(fileInfo fs.FileInfo, settings settings.Settings) *PlaylistFile {
@@ -134,6 +184,28 @@ func (p *PlaylistFile) TotalAngleSize() uint64 {
 	return size
 }
 
+// AngleTotals returns, for angle (1..AngleCount), the length and size of that
+// angle's own clips and of the whole playlist when viewed in that angle (angle
+// clips substituted for the angle-0 clips they replace).
+func (p *PlaylistFile) AngleTotals(angle int) (clipLength float64, clipSize uint64, viewLength float64, viewSize uint64) {
+	for i := 0; i < len(p.StreamClips); {
+		base := p.StreamClips[i]
+		chosen := base
+		j := i + 1
+		for ; j < len(p.StreamClips) && p.StreamClips[j].AngleIndex > 0; j++ {
+			if p.StreamClips[j].AngleIndex == angle {
+				chosen = p.StreamClips[j]
+				clipLength += chosen.Length
+				clipSize += chosen.PacketSize()
+			}
+		}
+		viewLength += chosen.Length
+		viewSize += chosen.PacketSize()
+		i = j
+	}
+	return clipLength, clipSize, viewLength, viewSize
+}
+
 func (p *PlaylistFile) TotalBitRate() uint64 {
 	if p.TotalLength() > 0 {
 		return uint64(float64(p.TotalSize()) * 8.0 / p.TotalLength())
@@ -163,16 +235,28 @@ func (p *PlaylistFile) Scan(streamFiles map[string]*StreamFile, clipFiles map[st
 	}
 	pos := 0
 	p.FileType = util.ReadString(data, 8, &pos)
-	if p.FileType != "MPLS0100" && p.FileType != "MPLS0200" && p.FileType != "MPLS0300" {
+	switch p.FileType {
+	case "MPLS0100", "MPLS0200", "MPLS0300":
+	case "PLST0100", "PLST0200", "PLST0300":
+		p.IsBDAV = true
+	default:
 		return fmt.Errorf("playlist %s has unknown file type %s", p.Name, p.FileType)
 	}
+	p.StructureWarnings = checkMPLSStructure(data, p.IsBDAV)
 	playlistOffset := int(util.ReadUint32(data, &pos))
 	chaptersOffset := int(util.ReadUint32(data, &pos))
-	_ = util.ReadUint32(data, &pos) // extensions offset
-
-	pos = 0x38
-	if pos < len(data) {
-		miscFlags := data[pos]
+	extensionsOffset := int(util.ReadUint32(data, &pos))
+
+	// AppInfoPlayList: length(4) reserved(1) playback_type(1) playback_count(2) UO_mask_table(8) flags(2).
+	// BDAV playlists have a UIAppInfoPlayList (title, recording time) here instead.
+	if !p.IsBDAV && 0x38 < len(data) {
+		p.PlaybackType = data[0x2D] & 0x03
+		if p.PlaybackType == PlaybackRandom || p.PlaybackType == PlaybackShuffle {
+			p.PlaybackCount = binary.BigEndian.Uint16(data[0x2E:0x30])
+		}
+		p.UOMask = UOMask(binary.BigEndian.Uint64(data[0x30:0x38]))
+		miscFlags := data[0x38]
+		p.RandomAccessRestricted = (miscFlags & 0x80) != 0
 		p.MVCBaseViewR = (miscFlags & 0x10) != 0
 	}
 
@@ -183,6 +267,8 @@ func (p *PlaylistFile) Scan(streamFiles map[string]*StreamFile, clipFiles map[st
 	_ = util.ReadUint16(data, &pos) // subitem count
 
 	chapterClips := []*StreamClip{}
+	ssItems := make([]ssPlayItem, 0, itemCount)
+	var itemAudio []SegmentStream
 	for range itemCount {
 		itemStart := pos
 		itemLength := int(util.ReadUint16(data, &pos))
@@ -217,6 +303,13 @@ func (p *PlaylistFile) Scan(streamFiles map[string]*StreamFile, clipFiles map[st
 			outTime &= 0x7fffffff
 		}
 		timeOut := float64(outTime) / 45000.0
+		if timeOut < timeIn {
+			p.DurationWarnings = append(p.DurationWarnings, DurationWarning{
+				Clip:    streamFileName,
+				Message: fmt.Sprintf("out time %.3fs is before in time %.3fs; clamped to a zero-length item", timeOut, timeIn),
+			})
+			timeOut = timeIn
+		}
 
 		clip := NewStreamClip(streamFile, clipFile, p.Settings)
 		clip.Name = streamFileName
@@ -228,8 +321,19 @@ func (p *PlaylistFile) Scan(streamFiles map[string]*StreamFile, clipFiles map[st
 		if p.TotalLength() > 0 {
 			clip.RelativeLength = clip.Length / p.TotalLength()
 		}
+		// UO_mask_table(8) random_access_flag(1) still_mode(1) still_time(2).
+		if !p.IsBDAV && pos+12 <= len(data) {
+			clip.UOMask = UOMask(binary.BigEndian.Uint64(data[pos : pos+8]))
+			clip.RandomAccessRestricted = (data[pos+8] & 0x80) != 0
+			clip.StillMode = data[pos+9]
+			clip.StillTime = binary.BigEndian.Uint16(data[pos+10 : pos+12])
+		}
 		p.StreamClips = append(p.StreamClips, clip)
 		chapterClips = append(chapterClips, clip)
+		if p.IsBDAV {
+			pos = itemStart + itemLength + 2
+			continue
+		}
 
 		pos += 12
 		if multiangle > 0 {
@@ -295,20 +399,24 @@ func (p *PlaylistFile) Scan(streamFiles map[string]*StreamFile, clipFiles map[st
 		for range streamCountAudio {
 			st := createPlaylistStream(data, &pos)
 			if st != nil {
+				itemAudio = append(itemAudio, SegmentStream{Clip: streamFileName, Stream: st})
 				pid := st.Base().PID
 				if _, ok := p.PlaylistStreams[pid]; !ok || clip.RelativeLength > 0.01 {
 					p.PlaylistStreams[pid] = st
 				}
 			}
 		}
+		ssItem := ssPlayItem{clip: streamFileName, videoCount: streamCountVideo}
 		for range streamCountPG {
 			st := createPlaylistStream(data, &pos)
+			var pid uint16
 			if st != nil {
-				pid := st.Base().PID
+				pid = st.Base().PID
 				if _, ok := p.PlaylistStreams[pid]; !ok || clip.RelativeLength > 0.01 {
 					p.PlaylistStreams[pid] = st
 				}
 			}
+			ssItem.subtitlePIDs = append(ssItem.subtitlePIDs, pid)
 		}
 		for range streamCountIG {
 			st := createPlaylistStream(data, &pos)
@@ -342,12 +450,13 @@ func (p *PlaylistFile) Scan(streamFiles map[string]*StreamFile, clipFiles map[st
 		for range streamCountPIP {
 			_ = createPlaylistStream(data, &pos)
 		}
+		ssItems = append(ssItems, ssItem)
 
 		pos = itemStart + itemLength + 2
 	}
 
 	pos = chaptersOffset + 4
-	if pos+2 <= len(data) {
+	if !p.IsBDAV && pos+2 <= len(data) {
 		chapterCount := int(util.ReadUint16(data, &pos))
 		for range chapterCount {
 			if pos+8 > len(data) {
@@ -371,6 +480,17 @@ func (p *PlaylistFile) Scan(streamFiles map[string]*StreamFile, clipFiles map[st
 		}
 	}
 
+	if length := p.TotalLength(); length > maxPlausibleLength {
+		p.DurationWarnings = append(p.DurationWarnings, DurationWarning{
+			Message: fmt.Sprintf("total length %.0fs exceeds 24 hours; the playlist may loop", length),
+		})
+	}
+
+	if !p.IsBDAV {
+		p.Offsets3D = parseOffsets3D(data, extensionsOffset, ssItems)
+	}
+	p.AudioSegments = audioSegmentChanges(itemAudio)
+
 	p.loadStreamClips()
 	p.IsInitialized = true
 	return nil
@@ -399,6 +519,14 @@ func (p *PlaylistFile) Initialize() {
 	p.IsInitialized = true
 }
 
+// ChapterName returns the title of chapter i, or "" when it has none.
+func (p *PlaylistFile) ChapterName(i int) string {
+	if i < 0 || i >= len(p.ChapterNames) {
+		return ""
+	}
+	return p.ChapterNames[i]
+}
+
 func (p *PlaylistFile) ClearBitrates() {
 	for _, clip := range p.StreamClips {
 		clip.PayloadBytes = 0
@@ -436,6 +564,48 @@ func (p *PlaylistFile) IsValid() bool {
 	return !(p.HasLoops && p.Settings.FilterLoopingPlaylists)
 }
 
+// hasInteractiveGraphics reports whether the playlist carries an IG stream,
+// i.e. a menu whose buttons can play other playlists.
+func (p *PlaylistFile) hasInteractiveGraphics() bool {
+	for _, st := range p.PlaylistStreams {
+		if st != nil && st.Base().StreamType == stream.StreamTypeInteractiveGraphics {
+			return true
+		}
+	}
+	return false
+}
+
+// sumCaptions sets the caption counts of the playlist's graphics streams to
+// their sum over the main angle stream files it plays; only scans with
+// Settings.SubtitleSummary count captions.
+func (p *PlaylistFile) sumCaptions() {
+	if !p.Settings.SubtitleSummary {
+		return
+	}
+	type counts struct{ captions, forced int }
+	totals := map[uint16]counts{}
+	seen := map[*StreamFile]bool{}
+	for _, clip := range p.StreamClips {
+		if clip.AngleIndex != 0 || clip.StreamFile == nil || seen[clip.StreamFile] {
+			continue
+		}
+		seen[clip.StreamFile] = true
+		for pid, st := range clip.StreamFile.Streams {
+			if gs, ok := st.(*stream.GraphicsStream); ok {
+				total := totals[pid]
+				total.captions += gs.Captions
+				total.forced += gs.ForcedCaptions
+				totals[pid] = total
+			}
+		}
+	}
+	for pid, total := range totals {
+		if gs, ok := p.Streams[pid].(*stream.GraphicsStream); ok {
+			gs.Captions, gs.ForcedCaptions = total.captions, total.forced
+		}
+	}
+}
+
 func (p *PlaylistFile) loadStreamClips() {
 	p.AngleClips = nil
 	if p.AngleCount > 0 {
@@ -481,15 +651,20 @@ func (p *PlaylistFile) loadStreamClips() {
 		p.Streams = make(map[uint16]stream.Info)
 	}
 
-	for pid, clipStream := range reference.StreamClipFile.Streams {
+	for _, pid := range stream.SortedPIDs(reference.StreamClipFile.Streams) {
+		clipStream := reference.StreamClipFile.Streams[pid]
 		if _, ok := p.Streams[pid]; ok {
 			continue
 		}
 		streamClone := clipStream.Clone()
 		p.Streams[pid] = streamClone
-		if !p.IsCustom {
+		if !p.IsCustom && !p.IsBDAV {
 			if _, ok := p.PlaylistStreams[pid]; !ok {
 				streamClone.Base().IsHidden = true
+				streamClone.Base().HiddenReason = stream.HiddenReasonNotInPlaylist
+				if streamClone.Base().StreamType == stream.StreamTypeMVCVideo {
+					streamClone.Base().HiddenReason = stream.HiddenReasonSSIFOnly
+				}
 				p.HasHiddenTracks = true
 			}
 		}
@@ -504,7 +679,8 @@ func (p *PlaylistFile) loadStreamClips() {
 				}
 			}
 		}
-		for pid, clipStream := range reference.StreamFile.Streams {
+		for _, pid := range stream.SortedPIDs(reference.StreamFile.Streams) {
+			clipStream := reference.StreamFile.Streams[pid]
 			if existing, ok := p.Streams[pid]; ok {
 				if existing.Base().StreamType != clipStream.Base().StreamType {
 					continue
@@ -554,19 +730,17 @@ func (p *PlaylistFile) loadStreamClips() {
 		}
 	}
 
+	p.sumCaptions()
+
 	p.VideoStreams = p.VideoStreams[:0]
 	p.AudioStreams = p.AudioStreams[:0]
 	p.GraphicsStreams = p.GraphicsStreams[:0]
 	p.TextStreams = p.TextStreams[:0]
+	p.OtherStreams = p.OtherStreams[:0]
 	p.SortedStreams = p.SortedStreams[:0]
 
-	pids := make([]int, 0, len(p.Streams))
-	for pid := range p.Streams {
-		pids = append(pids, int(pid))
-	}
-	sort.Ints(pids)
-	for _, pid := range pids {
-		streamInfo := p.Streams[uint16(pid)]
+	for _, pid := range stream.SortedPIDs(p.Streams) {
+		streamInfo := p.Streams[pid]
 		switch st := streamInfo.(type) {
 		case *stream.VideoStream:
 			p.VideoStreams = append(p.VideoStreams, st)
@@ -576,6 +750,10 @@ func (p *PlaylistFile) loadStreamClips() {
 			p.GraphicsStreams = append(p.GraphicsStreams, st)
 		case *stream.TextStream:
 			p.TextStreams = append(p.TextStreams, st)
+		default:
+			if st.Base().IsOtherStream() {
+				p.OtherStreams = append(p.OtherStreams, st)
+			}
 		}
 	}
 
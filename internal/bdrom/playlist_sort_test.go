@@ -3,6 +3,7 @@ package bdrom
 import (
 	"testing"
 
+	"github.com/autobrr/go-bdinfo/internal/settings"
 	"github.com/autobrr/go-bdinfo/internal/stream"
 )
 
@@ -64,3 +65,66 @@ func TestCompareTextStreams_EnglishPIDAscendingWithinLanguage(t *testing.T) {
 		t.Fatalf("expected higher PID english stream after lower PID, got compare=%d", got)
 	}
 }
+
+func TestLoadStreamClips_KeepStreamOrderIsPIDOrder(t *testing.T) {
+	clipStreams := map[uint16]stream.Info{}
+	for pid := uint16(0x1100); pid < 0x1120; pid++ {
+		a := &stream.AudioStream{ChannelCount: 2}
+		a.PID = pid
+		a.StreamType = stream.StreamTypeAC3Audio
+		clipStreams[pid] = a
+	}
+
+	for range 5 {
+		p := &PlaylistFile{
+			Settings:        settings.Settings{KeepStreamOrder: true},
+			PlaylistStreams: map[uint16]stream.Info{},
+			StreamClips:     []*StreamClip{{StreamClipFile: &StreamClipFile{Streams: clipStreams}}},
+		}
+		p.loadStreamClips()
+		if len(p.AudioStreams) != len(clipStreams) {
+			t.Fatalf("audio streams = %d want %d", len(p.AudioStreams), len(clipStreams))
+		}
+		for i := 1; i < len(p.AudioStreams); i++ {
+			if p.AudioStreams[i-1].PID >= p.AudioStreams[i].PID {
+				t.Fatalf("audio streams out of PID order at %d: %d then %d", i, p.AudioStreams[i-1].PID, p.AudioStreams[i].PID)
+			}
+		}
+	}
+}
+
+func TestLoadStreamClips_HiddenReasons(t *testing.T) {
+	video := &stream.VideoStream{}
+	video.PID = 0x1011
+	video.StreamType = stream.StreamTypeAVCVideo
+	mvc := &stream.VideoStream{}
+	mvc.PID = 0x1012
+	mvc.StreamType = stream.StreamTypeMVCVideo
+	audio := &stream.AudioStream{ChannelCount: 2}
+	audio.PID = 0x1100
+	audio.StreamType = stream.StreamTypeAC3Audio
+
+	p := &PlaylistFile{
+		AngleCount:      1,
+		PlaylistStreams: map[uint16]stream.Info{0x1011: video},
+		StreamClips: []*StreamClip{{StreamClipFile: &StreamClipFile{Streams: map[uint16]stream.Info{
+			0x1011: video, 0x1012: mvc, 0x1100: audio,
+		}}}},
+	}
+	p.loadStreamClips()
+
+	want := map[uint16]stream.HiddenReason{
+		0x1011: stream.HiddenReasonNone,
+		0x1012: stream.HiddenReasonSSIFOnly,
+		0x1100: stream.HiddenReasonNotInPlaylist,
+	}
+	for pid, reason := range want {
+		base := p.Streams[pid].Base()
+		if base.IsHidden != (reason != stream.HiddenReasonNone) || base.HiddenReason != reason {
+			t.Errorf("PID %#x: hidden=%v reason=%v want reason %v", pid, base.IsHidden, base.HiddenReason, reason)
+		}
+	}
+	if got := p.AngleStreams[0][0x1012].Base().HiddenDescription(); got != "3D dependent view, only played from the SSIF (angle 1 copy)" {
+		t.Errorf("angle copy description = %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package bdrom
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestScanWithProgress_ReportsStreamFile(t *testing.T) {
+	const pid = 0x1011
+	var data []byte
+	for range 64 {
+		pkt := tsPacket188(pid, false, make([]byte, 184))
+		data = append(data, pkt[:]...)
+	}
+
+	s := NewStreamFile(&memFileInfo{name: "00001.M2TS", data: data})
+	s.Streams[pid] = &stream.VideoStream{Stream: stream.Stream{PID: pid, StreamType: stream.StreamTypeAVCVideo}}
+	playlist := &PlaylistFile{
+		Name:        "00800.MPLS",
+		StreamClips: []*StreamClip{{Name: s.Name, StreamFile: s, TimeOut: 10}},
+		Streams: map[uint16]stream.Info{
+			pid: &stream.VideoStream{Stream: stream.Stream{PID: pid, StreamType: stream.StreamTypeAVCVideo}},
+		},
+	}
+	rom := &BDROM{
+		PlaylistFiles:   map[string]*PlaylistFile{playlist.Name: playlist},
+		StreamClipFiles: map[string]*StreamClipFile{},
+		StreamFiles:     map[string]*StreamFile{s.Name: s},
+	}
+
+	var mu sync.Mutex
+	var fileUpdates []ScanProgress
+	rom.ScanWithProgress(func(update ScanProgress) {
+		if update.Stage != ScanStageStream || update.File == "" {
+			return
+		}
+		mu.Lock()
+		fileUpdates = append(fileUpdates, update)
+		mu.Unlock()
+	})
+
+	if len(fileUpdates) == 0 {
+		t.Fatal("no per-file stream updates")
+	}
+	last := fileUpdates[len(fileUpdates)-1]
+	if last.File != "00001.M2TS" || last.FileTotalBytes != uint64(len(data)) || last.FileProcessedBytes != last.FileTotalBytes || last.Completed != 1 {
+		t.Fatalf("last update = %+v", last)
+	}
+}
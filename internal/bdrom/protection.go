@@ -0,0 +1,102 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+)
+
+// mkbTypeVersionRecord is the record type of the Type and Version Record that
+// opens every AACS Media Key Block.
+const mkbTypeVersionRecord = 0x10
+
+// readMKBVersion returns the version of the AACS Media Key Block in
+// AACS/MKB_RO.inf, falling back to the copy in AACS/DUPLICATE, or 0 when the
+// disc carries no readable MKB. UHD discs keep their AACS2 MKB at the same
+// paths.
+func readMKBVersion(rootDir fs.DirectoryInfo) int {
+	if rootDir == nil {
+		return 0
+	}
+	aacsDir, err := rootDir.GetDirectory("AACS")
+	if err != nil {
+		return 0
+	}
+	dirs := []fs.DirectoryInfo{aacsDir}
+	if dup, err := aacsDir.GetDirectory("DUPLICATE"); err == nil {
+		dirs = append(dirs, dup)
+	}
+	for _, dir := range dirs {
+		file, err := dir.GetFile("MKB_RO.inf")
+		if err != nil {
+			continue
+		}
+		header, err := readFileHeader(file, 12)
+		if err != nil {
+			continue
+		}
+		if version := parseMKBVersion(header); version > 0 {
+			return version
+		}
+	}
+	return 0
+}
+
+// parseMKBVersion reads the version number from the Type and Version Record:
+// a record type byte, a 24-bit record length, the 32-bit MKB type and the
+// 32-bit version number.
+func parseMKBVersion(data []byte) int {
+	if len(data) < 12 || data[0] != mkbTypeVersionRecord {
+		return 0
+	}
+	if length := uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3]); length < 12 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(data[8:12]))
+}
+
+// readBDPlusDate returns the build date of the BD+ content code in
+// BDSVM/00000.svm (or its BACKUP copy) as YYYY-MM-DD, which identifies the
+// SVM generation, or "" when the disc has no readable content code.
+func readBDPlusDate(rootDir fs.DirectoryInfo) string {
+	if rootDir == nil {
+		return ""
+	}
+	svmDir, err := rootDir.GetDirectory("BDSVM")
+	if err != nil {
+		return ""
+	}
+	dirs := []fs.DirectoryInfo{svmDir}
+	if backup, err := svmDir.GetDirectory("BACKUP"); err == nil {
+		dirs = append(dirs, backup)
+	}
+	for _, dir := range dirs {
+		file, err := dir.GetFile("00000.svm")
+		if err != nil {
+			continue
+		}
+		header, err := readFileHeader(file, 17)
+		if err != nil {
+			continue
+		}
+		if date := parseSVMDate(header); date != "" {
+			return date
+		}
+	}
+	return ""
+}
+
+// parseSVMDate reads the content code date that follows the BDSVM_CC magic:
+// a 16-bit year, a month and a day at offset 13.
+func parseSVMDate(data []byte) string {
+	if len(data) < 17 || string(data[:8]) != "BDSVM_CC" {
+		return ""
+	}
+	year := binary.BigEndian.Uint16(data[13:15])
+	month, day := data[15], data[16]
+	if year < 2000 || month < 1 || month > 12 || day < 1 || day > 31 {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+}
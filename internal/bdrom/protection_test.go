@@ -0,0 +1,59 @@
+package bdrom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+)
+
+func TestReadProtectionDetails(t *testing.T) {
+	root := t.TempDir()
+	write := func(name string, data []byte) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Type and Version Record: type 0x10, length 12, MKB type 3, version 68.
+	write("AACS/MKB_RO.inf", []byte{0x10, 0, 0, 12, 0, 3, 0x10, 0x03, 0, 0, 0, 68, 0x11, 0, 0, 4})
+	// The primary content code is damaged; the backup is read instead.
+	write("BDSVM/00000.svm", []byte("BDSVM_CC"))
+	write("BDSVM/BACKUP/00000.svm", append([]byte("BDSVM_CC\x00\x00\x00\x00\x00"), 0x07, 0xdc, 5, 7, 0))
+
+	rootDir, err := fs.NewDiskFileSystem().GetDirectoryInfo(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readMKBVersion(rootDir); got != 68 {
+		t.Fatalf("readMKBVersion()=%d, want 68", got)
+	}
+	if got := readBDPlusDate(rootDir); got != "2012-05-07" {
+		t.Fatalf("readBDPlusDate()=%q, want 2012-05-07", got)
+	}
+
+	empty, err := fs.NewDiskFileSystem().GetDirectoryInfo(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readMKBVersion(empty) != 0 || readBDPlusDate(empty) != "" {
+		t.Fatal("disc without AACS or BDSVM reported protection details")
+	}
+}
+
+func TestParseMKBVersionRejectsOtherRecords(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		{0x10, 0, 0, 12, 0, 3, 0x10, 0x03, 0, 0},
+		{0x81, 0, 0, 12, 0, 3, 0x10, 0x03, 0, 0, 0, 68},
+		{0x10, 0, 0, 8, 0, 3, 0x10, 0x03, 0, 0, 0, 68},
+	} {
+		if got := parseMKBVersion(data); got != 0 {
+			t.Fatalf("parseMKBVersion(% x)=%d, want 0", data, got)
+		}
+	}
+}
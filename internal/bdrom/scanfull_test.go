@@ -0,0 +1,31 @@
+package bdrom
+
+import "testing"
+
+func TestScanFull_RebuildsBitrates(t *testing.T) {
+	s, playlists := streamCacheTestDisc(streamCacheTestData())
+	rom := &BDROM{
+		PlaylistFiles:   map[string]*PlaylistFile{playlists[0].Name: playlists[0], playlists[1].Name: playlists[1]},
+		StreamClipFiles: map[string]*StreamClipFile{},
+		StreamFiles:     map[string]*StreamFile{s.Name: s},
+	}
+	rom.Scan()
+	clip := *playlists[0].StreamClips[0]
+	video := *playlists[0].Streams[0x1011].Base()
+	fileVideo := *s.Streams[0x1011].Base()
+	diagnostics := len(s.StreamDiagnostics[0x1011])
+
+	if scan := rom.ScanFull(); len(scan.FileErrors) != 0 || scan.Stats.BytesRead == 0 {
+		t.Fatalf("ScanFull() errors=%v bytes=%d", scan.FileErrors, scan.Stats.BytesRead)
+	}
+	got := playlists[0].StreamClips[0]
+	if got.PayloadBytes != clip.PayloadBytes || got.PacketCount != clip.PacketCount || got.PacketSeconds != clip.PacketSeconds {
+		t.Fatalf("clip after full pass=%+v, want %+v", got, clip)
+	}
+	if base := playlists[0].Streams[0x1011].Base(); base.PayloadBytes != video.PayloadBytes || base.BitRate != video.BitRate || base.ActiveBitRate != video.ActiveBitRate {
+		t.Fatalf("video after full pass=%+v, want %+v", base, video)
+	}
+	if base := s.Streams[0x1011].Base(); base.PayloadBytes != fileVideo.PayloadBytes || len(s.StreamDiagnostics[0x1011]) != diagnostics {
+		t.Fatalf("stream file video after full pass bytes=%d diagnostics=%d, want %d and %d", base.PayloadBytes, len(s.StreamDiagnostics[0x1011]), fileVideo.PayloadBytes, diagnostics)
+	}
+}
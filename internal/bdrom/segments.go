@@ -0,0 +1,40 @@
+package bdrom
+
+import "github.com/autobrr/go-bdinfo/internal/stream"
+
+// AudioSegments records an audio PID whose codec or language differs between
+// play items, which happens on compilation discs. Segments holds the STN
+// entry of each play item listing the PID, in playlist order.
+type AudioSegments struct {
+	PID      uint16
+	Segments []SegmentStream
+}
+
+// SegmentStream is a stream as one play item's STN table lists it.
+type SegmentStream struct {
+	Clip   string
+	Stream stream.Info
+}
+
+// audioSegmentChanges returns the audio PIDs whose STN entries disagree on
+// codec or language across items, in PID order.
+func audioSegmentChanges(items []SegmentStream) []AudioSegments {
+	byPID := map[uint16][]SegmentStream{}
+	for _, item := range items {
+		pid := item.Stream.Base().PID
+		byPID[pid] = append(byPID[pid], item)
+	}
+	var changes []AudioSegments
+	for _, pid := range stream.SortedPIDs(byPID) {
+		segments := byPID[pid]
+		first := segments[0].Stream.Base()
+		for _, seg := range segments[1:] {
+			base := seg.Stream.Base()
+			if base.StreamType != first.StreamType || base.LanguageCode() != first.LanguageCode() {
+				changes = append(changes, AudioSegments{PID: pid, Segments: segments})
+				break
+			}
+		}
+	}
+	return changes
+}
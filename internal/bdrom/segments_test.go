@@ -0,0 +1,37 @@
+package bdrom
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestAudioSegmentChanges(t *testing.T) {
+	audio := func(pid uint16, streamType stream.StreamType, lang string) stream.Info {
+		a := &stream.AudioStream{}
+		a.PID = pid
+		a.StreamType = streamType
+		a.SetLanguageCode(lang)
+		return a
+	}
+	items := []SegmentStream{
+		{Clip: "00001.M2TS", Stream: audio(0x1100, stream.StreamTypeAC3Audio, "eng")},
+		{Clip: "00001.M2TS", Stream: audio(0x1101, stream.StreamTypeAC3Audio, "fra")},
+		{Clip: "00002.M2TS", Stream: audio(0x1100, stream.StreamTypeAC3Audio, "eng")},
+		{Clip: "00002.M2TS", Stream: audio(0x1101, stream.StreamTypeAC3Audio, "deu")},
+		{Clip: "00003.M2TS", Stream: audio(0x1100, stream.StreamTypeAC3Audio, "eng")},
+		{Clip: "00003.M2TS", Stream: audio(0x1101, stream.StreamTypeDTSAudio, "deu")},
+	}
+
+	changes := audioSegmentChanges(items)
+	if len(changes) != 1 || changes[0].PID != 0x1101 || len(changes[0].Segments) != 3 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if seg := changes[0].Segments[2]; seg.Clip != "00003.M2TS" || seg.Stream.Base().StreamType != stream.StreamTypeDTSAudio {
+		t.Fatalf("third segment = %+v", seg)
+	}
+
+	if changes := audioSegmentChanges(items[:1]); changes != nil {
+		t.Fatalf("single item changes = %+v", changes)
+	}
+}
@@ -0,0 +1,274 @@
+package bdrom
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+// snapshotMagic starts every saved scan; the byte after it is the format version.
+const snapshotMagic = "BDINFOSCAN"
+
+// snapshotVersion is bumped whenever the saved layout changes incompatibly.
+const snapshotVersion = 1
+
+func init() {
+	gob.Register(&fs.StaticFileInfo{})
+}
+
+// romSnapshot is the saved form of a scanned BDROM: its exported state minus the
+// file system handles and the bitrate trace, plus the scan outcome.
+type romSnapshot struct {
+	Path     string
+	Settings settings.Settings
+
+	DirectoryRoot     string
+	DirectoryBDMV     string
+	DirectoryBDJO     string
+	DirectoryCLIPINF  string
+	DirectoryPLAYLIST string
+	DirectorySNP      string
+	DirectorySSIF     string
+	DirectorySTREAM   string
+	DirectoryMeta     string
+
+	VolumeLabel    string
+	DiscTitle      string
+	Size           uint64
+	IsBDPlus       bool
+	IsBDJava       bool
+	IsDBOX         bool
+	IsPSP          bool
+	Is3D           bool
+	Is50Hz         bool
+	IsUHD          bool
+	IsBDAV         bool
+	IsOpticalMedia bool
+	MKBVersion     int
+	BDPlusDate     string
+
+	PlaylistFiles    map[string]*PlaylistFile
+	PlaylistOrder    []string
+	StreamClipFiles  map[string]*StreamClipFile
+	StreamFiles      map[string]*StreamFile
+	InterleavedFiles map[string]*InterleavedFile
+	IndexTitles      []IndexEntry
+	JARImages        []JARImage
+
+	ScanError   string
+	FileErrors  map[string]string
+	BackupFiles map[string]string
+	Stats       ScanStats
+}
+
+// WriteSnapshot saves the scanned disc and the scan outcome to w as gzip-compressed
+// gob. File handles are replaced by static copies first, so b can still be
+// rendered afterwards but no longer reads the disc.
+func (b *BDROM) WriteSnapshot(w io.Writer, scan ScanResult) error {
+	b.detachFiles()
+
+	snap := romSnapshot{
+		Path:              b.Path,
+		Settings:          b.Settings,
+		DirectoryRoot:     b.DirectoryRoot,
+		DirectoryBDMV:     b.DirectoryBDMV,
+		DirectoryBDJO:     b.DirectoryBDJO,
+		DirectoryCLIPINF:  b.DirectoryCLIPINF,
+		DirectoryPLAYLIST: b.DirectoryPLAYLIST,
+		DirectorySNP:      b.DirectorySNP,
+		DirectorySSIF:     b.DirectorySSIF,
+		DirectorySTREAM:   b.DirectorySTREAM,
+		DirectoryMeta:     b.DirectoryMeta,
+		VolumeLabel:       b.VolumeLabel,
+		DiscTitle:         b.DiscTitle,
+		Size:              b.Size,
+		IsBDPlus:          b.IsBDPlus,
+		IsBDJava:          b.IsBDJava,
+		IsDBOX:            b.IsDBOX,
+		IsPSP:             b.IsPSP,
+		Is3D:              b.Is3D,
+		Is50Hz:            b.Is50Hz,
+		IsUHD:             b.IsUHD,
+		IsBDAV:            b.IsBDAV,
+		IsOpticalMedia:    b.IsOpticalMedia,
+		MKBVersion:        b.MKBVersion,
+		BDPlusDate:        b.BDPlusDate,
+		PlaylistFiles:     b.PlaylistFiles,
+		PlaylistOrder:     b.PlaylistOrder,
+		StreamClipFiles:   b.StreamClipFiles,
+		StreamFiles:       b.StreamFiles,
+		InterleavedFiles:  b.InterleavedFiles,
+		IndexTitles:       b.IndexTitles,
+		JARImages:         b.JARImages,
+		FileErrors:        make(map[string]string, len(scan.FileErrors)),
+		BackupFiles:       make(map[string]string, len(scan.BackupFiles)),
+		Stats:             scan.Stats,
+	}
+	if scan.ScanError != nil {
+		snap.ScanError = scan.ScanError.Error()
+	}
+	for name, err := range scan.FileErrors {
+		if err != nil {
+			snap.FileErrors[name] = err.Error()
+		}
+	}
+	for name, err := range scan.BackupFiles {
+		if err != nil {
+			snap.BackupFiles[name] = err.Error()
+		}
+	}
+
+	if _, err := io.WriteString(w, snapshotMagic); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{snapshotVersion}); err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(w)
+	if err := gob.NewEncoder(zw).Encode(&snap); err != nil {
+		return fmt.Errorf("encode scan: %w", err)
+	}
+	return zw.Close()
+}
+
+// ReadSnapshot restores a disc saved by WriteSnapshot. The returned BDROM can be
+// rendered but not scanned again.
+func ReadSnapshot(r io.Reader) (*BDROM, ScanResult, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, ScanResult{}, errors.New("not a saved bdinfo scan")
+	}
+	if version := header[len(snapshotMagic)]; version != snapshotVersion {
+		return nil, ScanResult{}, fmt.Errorf("saved scan format %d is not supported (want %d)", version, snapshotVersion)
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, ScanResult{}, fmt.Errorf("decode scan: %w", err)
+	}
+	defer zr.Close()
+	var snap romSnapshot
+	if err := gob.NewDecoder(zr).Decode(&snap); err != nil {
+		return nil, ScanResult{}, fmt.Errorf("decode scan: %w", err)
+	}
+
+	rom := &BDROM{
+		Path:              snap.Path,
+		Settings:          snap.Settings,
+		DirectoryRoot:     snap.DirectoryRoot,
+		DirectoryBDMV:     snap.DirectoryBDMV,
+		DirectoryBDJO:     snap.DirectoryBDJO,
+		DirectoryCLIPINF:  snap.DirectoryCLIPINF,
+		DirectoryPLAYLIST: snap.DirectoryPLAYLIST,
+		DirectorySNP:      snap.DirectorySNP,
+		DirectorySSIF:     snap.DirectorySSIF,
+		DirectorySTREAM:   snap.DirectorySTREAM,
+		DirectoryMeta:     snap.DirectoryMeta,
+		VolumeLabel:       snap.VolumeLabel,
+		DiscTitle:         snap.DiscTitle,
+		Size:              snap.Size,
+		IsBDPlus:          snap.IsBDPlus,
+		IsBDJava:          snap.IsBDJava,
+		IsDBOX:            snap.IsDBOX,
+		IsPSP:             snap.IsPSP,
+		Is3D:              snap.Is3D,
+		Is50Hz:            snap.Is50Hz,
+		IsUHD:             snap.IsUHD,
+		IsBDAV:            snap.IsBDAV,
+		IsOpticalMedia:    snap.IsOpticalMedia,
+		MKBVersion:        snap.MKBVersion,
+		BDPlusDate:        snap.BDPlusDate,
+		PlaylistFiles:     nonNilMap(snap.PlaylistFiles),
+		PlaylistOrder:     snap.PlaylistOrder,
+		StreamClipFiles:   nonNilMap(snap.StreamClipFiles),
+		StreamFiles:       nonNilMap(snap.StreamFiles),
+		InterleavedFiles:  nonNilMap(snap.InterleavedFiles),
+		IndexTitles:       snap.IndexTitles,
+		JARImages:         snap.JARImages,
+	}
+	scan := ScanResult{FileErrors: make(map[string]error, len(snap.FileErrors)), Stats: snap.Stats}
+	if snap.ScanError != "" {
+		scan.ScanError = errors.New(snap.ScanError)
+	}
+	for name, msg := range snap.FileErrors {
+		scan.FileErrors[name] = errors.New(msg)
+	}
+	if len(snap.BackupFiles) > 0 {
+		scan.BackupFiles = make(map[string]error, len(snap.BackupFiles))
+		for name, msg := range snap.BackupFiles {
+			scan.BackupFiles[name] = errors.New(msg)
+		}
+	}
+	return rom, scan, nil
+}
+
+// detachFiles swaps every file handle for a static copy and drops the trace so
+// the object graph can be encoded.
+func (b *BDROM) detachFiles() {
+	b.BitrateTrace = nil
+	for _, playlist := range b.PlaylistFiles {
+		playlist.FileInfo = detachFile(playlist.FileInfo)
+		for _, clip := range playlist.StreamClips {
+			detachClip(clip)
+		}
+		for _, angle := range playlist.AngleClips {
+			for _, clip := range angle {
+				detachClip(clip)
+			}
+		}
+	}
+	for _, clipFile := range b.StreamClipFiles {
+		clipFile.FileInfo = detachFile(clipFile.FileInfo)
+	}
+	for _, streamFile := range b.StreamFiles {
+		detachStreamFile(streamFile)
+	}
+	for _, interleaved := range b.InterleavedFiles {
+		interleaved.FileInfo = detachFile(interleaved.FileInfo)
+	}
+}
+
+func detachClip(clip *StreamClip) {
+	if clip == nil {
+		return
+	}
+	detachStreamFile(clip.StreamFile)
+	if clip.StreamClipFile != nil {
+		clip.StreamClipFile.FileInfo = detachFile(clip.StreamClipFile.FileInfo)
+	}
+}
+
+func detachStreamFile(streamFile *StreamFile) {
+	if streamFile == nil {
+		return
+	}
+	streamFile.FileInfo = detachFile(streamFile.FileInfo)
+	streamFile.trace = nil
+	if streamFile.InterleavedFile != nil {
+		streamFile.InterleavedFile.FileInfo = detachFile(streamFile.InterleavedFile.FileInfo)
+	}
+}
+
+func detachFile(file fs.FileInfo) fs.FileInfo {
+	switch f := file.(type) {
+	case nil:
+		return nil
+	case *fs.StaticFileInfo:
+		return f
+	default:
+		return fs.NewStaticFileInfo(file)
+	}
+}
+
+func nonNilMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return make(map[K]V)
+	}
+	return m
+}
@@ -0,0 +1,107 @@
+package bdrom
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	m2ts := filepath.Join(dir, "00001.m2ts")
+	if err := os.WriteFile(m2ts, make([]byte, 192*10), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fileInfo, err := fs.NewDiskFileSystem().GetFileInfo(m2ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeHEVCVideo}, Width: 3840}
+	video.SetVideoFormat(stream.VideoFormat2160p)
+	audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3TrueHDAudio}, ChannelCount: 7, LFE: 1}
+	audio.CoreStream = &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeAC3Audio}, ChannelCount: 5}
+	audio.SetLanguageCode("eng")
+	streamFile := &StreamFile{FileInfo: fileInfo, Name: "00001.M2TS", Size: fileInfo.Length(), Streams: map[uint16]stream.Info{video.PID: video, audio.PID: audio}}
+	clip := &StreamClip{Name: "00001.M2TS", Length: 5400, PacketCount: 10, StreamFile: streamFile}
+	cfg := settings.Default(".")
+	playlist := &PlaylistFile{
+		Name:          "00800.MPLS",
+		Settings:      cfg,
+		Chapters:      []float64{0, 600},
+		StreamClips:   []*StreamClip{clip},
+		AngleClips:    []map[float64]*StreamClip{{0: clip}},
+		Streams:       map[uint16]stream.Info{video.PID: video, audio.PID: audio},
+		SortedStreams: []stream.Info{video, audio},
+		VideoStreams:  []*stream.VideoStream{video},
+		AudioStreams:  []*stream.AudioStream{audio},
+		UOMask:        1 << 63,
+	}
+	rom := &BDROM{
+		Path:          dir,
+		Settings:      cfg,
+		VolumeLabel:   "DISC",
+		Size:          123,
+		IsUHD:         true,
+		PlaylistFiles: map[string]*PlaylistFile{playlist.Name: playlist},
+		PlaylistOrder: []string{playlist.Name},
+		StreamFiles:   map[string]*StreamFile{streamFile.Name: streamFile},
+		BitrateTrace:  NewBitrateTrace(&bytes.Buffer{}),
+	}
+	scan := ScanResult{
+		ScanError:  errors.New("stopped"),
+		FileErrors: map[string]error{"00002.M2TS": errors.New("read error")},
+		Stats:      ScanStats{BytesRead: 1920, Workers: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := rom.WriteSnapshot(&buf, scan); err != nil {
+		t.Fatal(err)
+	}
+	got, gotScan, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.VolumeLabel != "DISC" || !got.IsUHD || got.Size != 123 || len(got.PlaylistOrder) != 1 {
+		t.Fatalf("disc fields not restored: %+v", got)
+	}
+	pl := got.PlaylistFiles["00800.MPLS"]
+	if pl == nil || len(pl.StreamClips) != 1 || len(pl.Chapters) != 2 || pl.UOMask != 1<<63 {
+		t.Fatalf("playlist not restored: %+v", pl)
+	}
+	if pl.TotalLength() != 5400 || pl.TotalSize() != playlist.TotalSize() {
+		t.Fatalf("playlist totals length=%v size=%v", pl.TotalLength(), pl.TotalSize())
+	}
+	if v := pl.VideoStreams[0]; v.VideoFormat() != stream.VideoFormat2160p || v.PID != 0x1011 || v.Width != 3840 {
+		t.Fatalf("video stream not restored: format=%v pid=%d width=%d", v.VideoFormat(), v.PID, v.Width)
+	}
+	if a := pl.AudioStreams[0]; a.LanguageCode() != "eng" || a.ChannelCount != 7 || a.CoreStream == nil || a.CoreStream.ChannelCount != 5 {
+		t.Fatalf("audio stream not restored: lang=%q channels=%d", a.LanguageCode(), a.ChannelCount)
+	}
+	restored := pl.StreamClips[0].StreamFile.FileInfo
+	if restored == nil || restored.Length() != 192*10 || restored.Name() != "00001.m2ts" {
+		t.Fatalf("file info not restored: %+v", restored)
+	}
+	if _, err := restored.OpenRead(); !errors.Is(err, fs.ErrDetached) {
+		t.Fatalf("restored file OpenRead err=%v want ErrDetached", err)
+	}
+	if gotScan.ScanError == nil || gotScan.ScanError.Error() != "stopped" || gotScan.FileErrors["00002.M2TS"] == nil || gotScan.Stats.BytesRead != 1920 {
+		t.Fatalf("scan result not restored: %+v", gotScan)
+	}
+}
+
+func TestReadSnapshotRejectsOtherFiles(t *testing.T) {
+	if _, _, err := ReadSnapshot(bytes.NewReader([]byte("not a scan"))); err == nil {
+		t.Fatal("expected error for non-snapshot input")
+	}
+	if _, _, err := ReadSnapshot(bytes.NewReader([]byte(snapshotMagic + "\x63"))); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
@@ -0,0 +1,59 @@
+package bdrom
+
+import "github.com/autobrr/go-bdinfo/internal/stream"
+
+// SSIFSummary is the 3D view breakdown of a playlist scanned through its SSIF
+// interleaved files: the base (AVC) and dependent (MVC) view video streams
+// and the size of the interleaved files. A player decoding 3D reads both
+// views, so CombinedBitRate is what the disc must sustain for video.
+type SSIFSummary struct {
+	// Size sums the interleaved file sizes of the playlist's clips.
+	Size      uint64
+	Base      *stream.VideoStream
+	Dependent *stream.VideoStream
+	// BaseEye is the eye the base view shows, "Left" or "Right"
+	// (MVC_Base_view_R_flag); the dependent view shows the other one.
+	BaseEye string
+}
+
+// DependentEye is the eye the dependent view shows.
+func (s SSIFSummary) DependentEye() string {
+	if s.BaseEye == "Right" {
+		return "Left"
+	}
+	return "Right"
+}
+
+// CombinedBitRate is the base plus the dependent view video bitrate.
+func (s SSIFSummary) CombinedBitRate() uint64 {
+	return uint64(s.Base.BitRate + s.Dependent.BitRate)
+}
+
+// SSIFSummary returns the 3D view breakdown of the playlist. ok is false
+// unless SSIF reading is enabled and the playlist plays interleaved files
+// carrying both an AVC base view and an MVC dependent view.
+func (p *PlaylistFile) SSIFSummary() (summary SSIFSummary, ok bool) {
+	if !p.Settings.EnableSSIF {
+		return SSIFSummary{}, false
+	}
+	summary.Size = p.InterleavedFileSize()
+	if summary.Size == 0 {
+		return SSIFSummary{}, false
+	}
+	for _, vs := range p.VideoStreams {
+		switch {
+		case vs.StreamType == stream.StreamTypeAVCVideo && summary.Base == nil:
+			summary.Base = vs
+		case vs.StreamType == stream.StreamTypeMVCVideo && summary.Dependent == nil:
+			summary.Dependent = vs
+		}
+	}
+	if summary.Base == nil || summary.Dependent == nil {
+		return SSIFSummary{}, false
+	}
+	summary.BaseEye = "Left"
+	if p.MVCBaseViewR {
+		summary.BaseEye = "Right"
+	}
+	return summary, true
+}
@@ -0,0 +1,67 @@
+package bdrom
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ScanStats summarizes the cost of one scan for telemetry.
+type ScanStats struct {
+	// BytesRead counts M2TS/SSIF bytes read by the stream scan.
+	BytesRead uint64
+	// WallTime covers the whole scan call.
+	WallTime time.Duration
+	// Workers is the number of parallel stream-file workers used.
+	Workers int
+	// FileDurations maps clip info, playlist and stream file names to scan time.
+	FileDurations map[string]time.Duration
+	// CacheHits counts files whose scan results were reused instead of re-read.
+	CacheHits int
+}
+
+type scanStatsRecorder struct {
+	start     time.Time
+	bytesRead atomic.Uint64
+	mu        sync.Mutex
+	durations map[string]time.Duration
+	workers   int
+	cacheHits int
+}
+
+func newScanStatsRecorder() *scanStatsRecorder {
+	return &scanStatsRecorder{start: time.Now(), durations: make(map[string]time.Duration)}
+}
+
+// timeFile runs fn and records its duration under name.
+func (r *scanStatsRecorder) timeFile(name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+	r.mu.Lock()
+	r.durations[name] += elapsed
+	r.mu.Unlock()
+	return err
+}
+
+func (r *scanStatsRecorder) addBytes(n uint64) {
+	r.bytesRead.Add(n)
+}
+
+func (r *scanStatsRecorder) addCacheHit() {
+	r.mu.Lock()
+	r.cacheHits++
+	r.mu.Unlock()
+}
+
+func (r *scanStatsRecorder) finish() ScanStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ScanStats{
+		BytesRead:     r.bytesRead.Load(),
+		WallTime:      time.Since(r.start),
+		Workers:       r.workers,
+		FileDurations: r.durations,
+		CacheHits:     r.cacheHits,
+	}
+}
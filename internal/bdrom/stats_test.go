@@ -0,0 +1,39 @@
+package bdrom
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScanStatsRecorder(t *testing.T) {
+	r := newScanStatsRecorder()
+	r.workers = 2
+	wantErr := errors.New("boom")
+
+	if err := r.timeFile("00001.M2TS", func() error {
+		r.addBytes(192)
+		return nil
+	}); err != nil {
+		t.Fatalf("timeFile() error = %v", err)
+	}
+	if err := r.timeFile("00002.M2TS", func() error {
+		r.addBytes(384)
+		return wantErr
+	}); !errors.Is(err, wantErr) {
+		t.Fatalf("timeFile() error got=%v want=%v", err, wantErr)
+	}
+
+	stats := r.finish()
+	if stats.BytesRead != 576 {
+		t.Fatalf("BytesRead got=%d want=%d", stats.BytesRead, 576)
+	}
+	if stats.Workers != 2 {
+		t.Fatalf("Workers got=%d want=%d", stats.Workers, 2)
+	}
+	if len(stats.FileDurations) != 2 {
+		t.Fatalf("FileDurations got=%v want 2 entries", stats.FileDurations)
+	}
+	if stats.WallTime <= 0 {
+		t.Fatalf("WallTime got=%v want >0", stats.WallTime)
+	}
+}
@@ -0,0 +1,311 @@
+package bdrom
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// streamCacheMagic starts every cached stream file scan; the byte after it is
+// the format version.
+const streamCacheMagic = "BDINFOSTREAM"
+
+// streamCacheVersion is bumped whenever the cached layout or the scan results
+// it holds change incompatibly.
+const streamCacheVersion = 1
+
+// streamCache keeps the outcome of each stream file scan in Settings.CacheDir,
+// keyed by the file's path, size and modification time, so scanning the same
+// disc again (for other report flags, or after an interrupted run) skips the
+// files already read.
+type streamCache struct {
+	dir  string
+	disc string
+	// config lists the settings that change what a stream file scan yields.
+	config string
+}
+
+// cachedStreamFile is the saved form of one stream file scan: the stream
+// file's scan results, plus what the scan added to each playlist clip so the
+// playlists can be credited without reading the file.
+type cachedStreamFile struct {
+	Size              int64
+	Length            float64
+	Streams           map[uint16]stream.Info
+	StreamOrder       []uint16
+	StreamDiagnostics map[uint16][]StreamDiagnostics
+	PacketCount       uint64
+	SyncLostPackets   uint64
+	ScrambledPackets  uint64
+	UnknownPIDs       map[uint16]uint64
+	Clips             []cachedClip
+}
+
+// cachedClip is what a stream file scan added to one playlist clip.
+type cachedClip struct {
+	Playlist   string
+	Index      int
+	AngleIndex int
+	TimeIn     float64
+	TimeOut    float64
+
+	PayloadBytes uint64
+	PacketCount  uint64
+	// PacketSeconds is the furthest window end past TimeIn; 0 when none was.
+	PacketSeconds float64
+	Streams       map[uint16]*cachedClipStream
+}
+
+// cachedClipStream is what a stream file scan added to one playlist stream of
+// a clip. Windows counts the packet windows credited to it.
+type cachedClipStream struct {
+	PayloadBytes  uint64
+	PacketCount   uint64
+	PacketSeconds float64
+	Windows       int64
+}
+
+// newStreamCache returns the cache for b, or nil when Settings.CacheDir is
+// empty or cannot be created. Quick scans are not cached: they read too little
+// to be worth it and credit no clips.
+func newStreamCache(b *BDROM) *streamCache {
+	dir := b.Settings.CacheDir
+	if dir == "" || b.Settings.QuickScan || os.MkdirAll(dir, 0o755) != nil {
+		return nil
+	}
+	disc, err := filepath.Abs(b.Path)
+	if err != nil {
+		disc = b.Path
+	}
+	s := b.Settings
+	return &streamCache{
+		dir:    dir,
+		disc:   disc,
+		config: fmt.Sprintf("ssif=%t extdiag=%t unknownpids=%t splitoverlaps=%t bitrategraph=%t subtitles=%t", s.EnableSSIF, s.ExtendedStreamDiagnostics, s.TrackUnknownPIDs, s.SplitOverlappingClips, s.BitrateGraph, s.SubtitleSummary),
+	}
+}
+
+// path returns the cache file of the stream file read through fileInfo.
+func (c *streamCache) path(fileInfo fs.FileInfo) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%d\x00%d\x00%s", c.disc, fileInfo.FullName(), fileInfo.Length(), fileInfo.ModTime().UnixNano(), c.config))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".scan")
+}
+
+// restore applies the cached scan of s to s and to the clips of playlists, as
+// ScanWithProgress would. It reports false, leaving everything untouched, when
+// the file is not cached or the cache entry does not cover every clip.
+func (c *streamCache) restore(s *StreamFile, playlists []*PlaylistFile) bool {
+	if c == nil {
+		return false
+	}
+	fileInfo := s.scanFileInfo(playlistSettings(playlists))
+	if fileInfo == nil {
+		return false
+	}
+	entry, err := c.read(c.path(fileInfo))
+	if err != nil {
+		return false
+	}
+	targets := buildClipTargets(playlists, s.Name)
+	clips := make([]*cachedClip, len(targets))
+	for i, target := range targets {
+		clips[i] = entry.clip(target)
+		if clips[i] == nil {
+			return false
+		}
+	}
+
+	s.Size = entry.Size
+	s.Length = entry.Length
+	s.Streams = entry.Streams
+	if s.Streams == nil {
+		s.Streams = make(map[uint16]stream.Info)
+	}
+	s.StreamOrder = entry.StreamOrder
+	s.StreamDiagnostics = entry.StreamDiagnostics
+	if s.StreamDiagnostics == nil {
+		s.StreamDiagnostics = make(map[uint16][]StreamDiagnostics)
+	}
+	s.PacketCount = entry.PacketCount
+	s.SyncLostPackets = entry.SyncLostPackets
+	s.ScrambledPackets = entry.ScrambledPackets
+	s.UnknownPIDs = entry.UnknownPIDs
+	for i, target := range targets {
+		clips[i].apply(target)
+	}
+	s.finalizePlaylistVBR(playlists)
+	return true
+}
+
+// store saves the scan s just finished, with the clip records it kept.
+// Failures only cost the next scan its shortcut, so they are not reported.
+func (c *streamCache) store(s *StreamFile, playlists []*PlaylistFile) {
+	if c == nil || s.clipRecords == nil {
+		return
+	}
+	fileInfo := s.scanFileInfo(playlistSettings(playlists))
+	if fileInfo == nil {
+		return
+	}
+	entry := cachedStreamFile{
+		Size:              s.Size,
+		Length:            s.Length,
+		Streams:           s.Streams,
+		StreamOrder:       s.StreamOrder,
+		StreamDiagnostics: s.StreamDiagnostics,
+		PacketCount:       s.PacketCount,
+		SyncLostPackets:   s.SyncLostPackets,
+		ScrambledPackets:  s.ScrambledPackets,
+		UnknownPIDs:       s.UnknownPIDs,
+		Clips:             s.clipRecords,
+	}
+	_ = c.write(c.path(fileInfo), &entry)
+}
+
+func (c *streamCache) read(path string) (*cachedStreamFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	header := make([]byte, len(streamCacheMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(streamCacheMagic)]) != streamCacheMagic || header[len(streamCacheMagic)] != streamCacheVersion {
+		return nil, fmt.Errorf("%s: not a stream cache entry", path)
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var entry cachedStreamFile
+	if err := gob.NewDecoder(zr).Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// write stores entry under path through a temporary file in the cache
+// directory, so an interrupted run never leaves a partial entry behind.
+func (c *streamCache) write(path string, entry *cachedStreamFile) error {
+	f, err := os.CreateTemp(c.dir, ".scan-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	bw := bufio.NewWriter(f)
+	bw.WriteString(streamCacheMagic)
+	bw.WriteByte(streamCacheVersion)
+	zw := gzip.NewWriter(bw)
+	err = gob.NewEncoder(zw).Encode(entry)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// clip returns the record of target, or nil when the entry has none for it.
+func (e *cachedStreamFile) clip(target scanClipTarget) *cachedClip {
+	for i := range e.Clips {
+		clip := &e.Clips[i]
+		if clip.Playlist == target.playlist && clip.Index == target.index && clip.AngleIndex == target.clip.AngleIndex &&
+			clip.TimeIn == target.clip.TimeIn && clip.TimeOut == target.clip.TimeOut {
+			return clip
+		}
+	}
+	return nil
+}
+
+// newClipRecords returns an empty record for each of targets.
+func newClipRecords(targets []scanClipTarget) []cachedClip {
+	records := make([]cachedClip, len(targets))
+	for i, target := range targets {
+		records[i] = cachedClip{
+			Playlist:   target.playlist,
+			Index:      target.index,
+			AngleIndex: target.clip.AngleIndex,
+			TimeIn:     target.clip.TimeIn,
+			TimeOut:    target.clip.TimeOut,
+			Streams:    make(map[uint16]*cachedClipStream),
+		}
+	}
+	return records
+}
+
+// record notes a packet window credited to the clip, mirroring what
+// updateStreamBitrate adds to the clip and, when listed, its stream.
+func (r *cachedClip) record(pid uint16, bytes, packets uint64, streamOffset, interval float64, listed, video bool) {
+	r.PayloadBytes += bytes
+	r.PacketCount += packets
+	if streamOffset > r.TimeIn && streamOffset-r.TimeIn > r.PacketSeconds {
+		r.PacketSeconds = streamOffset - r.TimeIn
+	}
+	if !listed {
+		return
+	}
+	st := r.Streams[pid]
+	if st == nil {
+		st = &cachedClipStream{}
+		r.Streams[pid] = st
+	}
+	st.PayloadBytes += bytes
+	st.PacketCount += packets
+	if video {
+		st.PacketSeconds += interval
+	}
+	st.Windows++
+}
+
+// apply credits the recorded windows to target's clip and streams.
+func (r *cachedClip) apply(target scanClipTarget) {
+	clip := target.clip
+	clip.PayloadBytes += r.PayloadBytes
+	clip.PacketCount += r.PacketCount
+	if r.PacketSeconds > clip.PacketSeconds {
+		clip.PacketSeconds = r.PacketSeconds
+	}
+	if target.streams == nil {
+		return
+	}
+	for pid, recorded := range r.Streams {
+		streamInfo, ok := target.streams[pid]
+		if !ok {
+			continue
+		}
+		base := streamInfo.Base()
+		base.PayloadBytes += recorded.PayloadBytes
+		base.PacketCount += recorded.PacketCount
+		if base.IsVideoStream() {
+			base.PacketSeconds += recorded.PacketSeconds
+			if recorded.Windows > 0 && base.PacketSeconds > 0 {
+				base.ActiveBitRate = int64(math.RoundToEven(float64(base.PayloadBytes) * 8.0 / base.PacketSeconds))
+			}
+		}
+		if base.StreamType == stream.StreamTypeAC3TrueHDAudio {
+			if audio, ok := streamInfo.(*stream.AudioStream); ok && audio.CoreStream != nil {
+				base.ActiveBitRate -= recorded.Windows * audio.CoreStream.BitRate
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package bdrom
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func streamCacheTestData() []byte {
+	var data []byte
+	for i := range 10 {
+		ts := encodePTS(0x30, uint64(90000+i*3753))
+		pes := []byte{0x00, 0x00, 0x01, 0xE0, 0x00, 0x00, 0x80, 0x80, 0x05}
+		pes = append(pes, ts[:]...)
+		video := tsPacket188(0x1011, true, pes)
+		audio := tsPacket188(0x1100, false, nil)
+		data = append(data, video[:]...)
+		data = append(data, audio[:]...)
+	}
+	return data
+}
+
+func streamCacheTestDisc(data []byte) (*StreamFile, []*PlaylistFile) {
+	s := NewStreamFile(&memFileInfo{name: "00001.M2TS", data: data})
+	s.Streams[0x1011] = &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo}}
+	s.Streams[0x1100] = &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio}}
+	var playlists []*PlaylistFile
+	for _, name := range []string{"00001.MPLS", "00002.MPLS"} {
+		video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo, IsVBR: true}}
+		audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio}}
+		playlists = append(playlists, &PlaylistFile{
+			Name:          name,
+			Settings:      settings.Default("."),
+			StreamClips:   []*StreamClip{{Name: "00001.M2TS", TimeIn: 0, TimeOut: 10, StreamFile: s}},
+			Streams:       map[uint16]stream.Info{video.PID: video, audio.PID: audio},
+			SortedStreams: []stream.Info{video, audio},
+		})
+	}
+	return s, playlists
+}
+
+func TestStreamCacheRestoresScan(t *testing.T) {
+	data := streamCacheTestData()
+	cache := newStreamCache(&BDROM{Path: t.TempDir(), Settings: settings.Settings{CacheDir: t.TempDir()}})
+
+	scanned, want := streamCacheTestDisc(data)
+	if cache.restore(scanned, want) {
+		t.Fatal("restore() hit on an empty cache")
+	}
+	scanned.recordClips = true
+	if err := scanned.Scan(want, false); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	cache.store(scanned, want)
+
+	restored, got := streamCacheTestDisc(data)
+	if !cache.restore(restored, got) {
+		t.Fatal("restore() missed a stored scan")
+	}
+	if restored.Length != scanned.Length || restored.PacketCount != scanned.PacketCount || len(restored.StreamDiagnostics[0x1011]) != len(scanned.StreamDiagnostics[0x1011]) {
+		t.Fatalf("stream file got length=%v packets=%d, want length=%v packets=%d", restored.Length, restored.PacketCount, scanned.Length, scanned.PacketCount)
+	}
+	if want[0].Streams[0x1011].Base().ActiveBitRate == 0 {
+		t.Fatal("test scan credited no video bitrate")
+	}
+	for i := range want {
+		wantClip, gotClip := want[i].StreamClips[0], got[i].StreamClips[0]
+		if wantClip.PayloadBytes == 0 || gotClip.PayloadBytes != wantClip.PayloadBytes || gotClip.PacketCount != wantClip.PacketCount || gotClip.PacketSeconds != wantClip.PacketSeconds {
+			t.Fatalf("%s clip got=%+v want=%+v", want[i].Name, gotClip, wantClip)
+		}
+		for pid, wantStream := range want[i].Streams {
+			wantBase, gotBase := wantStream.Base(), got[i].Streams[pid].Base()
+			if gotBase.PayloadBytes != wantBase.PayloadBytes || gotBase.PacketSeconds != wantBase.PacketSeconds ||
+				gotBase.ActiveBitRate != wantBase.ActiveBitRate || gotBase.BitRate != wantBase.BitRate {
+				t.Fatalf("%s PID %d got=%+v want=%+v", want[i].Name, pid, gotBase, wantBase)
+			}
+		}
+	}
+}
+
+func TestStreamCacheMissesUnrecordedClips(t *testing.T) {
+	data := streamCacheTestData()
+	cache := newStreamCache(&BDROM{Path: t.TempDir(), Settings: settings.Settings{CacheDir: t.TempDir()}})
+
+	s, playlists := streamCacheTestDisc(data)
+	s.recordClips = true
+	if err := s.Scan(playlists[:1], false); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	cache.store(s, playlists[:1])
+
+	s, playlists = streamCacheTestDisc(data)
+	if cache.restore(s, playlists) {
+		t.Fatal("restore() hit although 00002.MPLS was not scanned")
+	}
+	if s.PacketCount != 0 || playlists[0].StreamClips[0].PayloadBytes != 0 {
+		t.Fatal("restore() changed the disc on a miss")
+	}
+	if !cache.restore(s, playlists[:1]) {
+		t.Fatal("restore() missed the scanned playlist")
+	}
+}
+
+func TestStreamCacheKeyedOnFileAndSettings(t *testing.T) {
+	dir := t.TempDir()
+	rom := &BDROM{Path: t.TempDir(), Settings: settings.Settings{CacheDir: dir}}
+	file := &memFileInfo{name: "00001.M2TS", data: make([]byte, 192)}
+	path := newStreamCache(rom).path(file)
+
+	if got := newStreamCache(rom).path(&memFileInfo{name: "00001.M2TS", data: make([]byte, 384)}); got == path {
+		t.Fatal("cache path ignores the file size")
+	}
+	rom.Settings.TrackUnknownPIDs = true
+	if got := newStreamCache(rom).path(file); got == path {
+		t.Fatal("cache path ignores the scan settings")
+	}
+	if newStreamCache(&BDROM{}) != nil {
+		t.Fatal("newStreamCache() without CacheDir should be nil")
+	}
+}
+
+func TestScan_CountsStreamCacheHits(t *testing.T) {
+	data := streamCacheTestData()
+	cacheDir := t.TempDir()
+	scan := func() ScanStats {
+		s, playlists := streamCacheTestDisc(data)
+		rom := &BDROM{
+			Path:            "DISC",
+			Settings:        settings.Settings{CacheDir: cacheDir},
+			PlaylistFiles:   map[string]*PlaylistFile{playlists[0].Name: playlists[0], playlists[1].Name: playlists[1]},
+			StreamClipFiles: map[string]*StreamClipFile{},
+			StreamFiles:     map[string]*StreamFile{s.Name: s},
+		}
+		return rom.Scan().Stats
+	}
+
+	if first := scan(); first.CacheHits != 0 || first.BytesRead == 0 {
+		t.Fatalf("first scan cache hits=%d bytes=%d", first.CacheHits, first.BytesRead)
+	}
+	if second := scan(); second.CacheHits != 1 || second.BytesRead != 0 {
+		t.Fatalf("second scan cache hits=%d bytes=%d, want 1 and 0", second.CacheHits, second.BytesRead)
+	}
+}
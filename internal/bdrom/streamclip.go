@@ -25,6 +25,13 @@ type StreamClip struct {
 
 	Chapters []float64
 
+	// PlayItem playback conditions: prohibited user operations, the
+	// random_access_flag and the still mode/time (seconds) after the item.
+	UOMask                 UOMask
+	RandomAccessRestricted bool
+	StillMode              byte
+	StillTime              uint16
+
 	StreamFile     *StreamFile
 	StreamClipFile *StreamClipFile
 }
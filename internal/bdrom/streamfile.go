@@ -1,6 +1,7 @@
 package bdrom
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -20,6 +21,10 @@ const (
 	maxStreamDataOther = 128 * 1024
 	maxTSPID           = 8192
 	unknownStatePID    = uint16(0xFFFF)
+
+	// quickScanBytes is how much of each stream file a quick scan reads:
+	// enough PES transfers for the codec analyzers, not for bitrates.
+	quickScanBytes = 8 * 1024 * 1024
 )
 
 var (
@@ -127,7 +132,9 @@ func parsePATPMTPIDSection(section []byte) (uint16, bool) {
 	return 0, false
 }
 
-func detectPMTStreamOrder(fileInfo fs.FileInfo) ([]uint16, bool) {
+// detectPMTStreamOrder reads the PMT stream order from the head of the file,
+// in chunks of readChunk bytes (5 MiB when 0).
+func detectPMTStreamOrder(fileInfo fs.FileInfo, readChunk int) ([]uint16, bool) {
 	if fileInfo == nil {
 		return nil, false
 	}
@@ -155,6 +162,9 @@ func detectPMTStreamOrder(fileInfo fs.FileInfo) ([]uint16, bool) {
 	}
 
 	chunkSize := 5 * 1024 * 1024
+	if readChunk > 0 {
+		chunkSize = readChunk
+	}
 	chunkSize -= chunkSize % packetSize
 	if chunkSize < packetSize {
 		chunkSize = packetSize
@@ -350,11 +360,52 @@ type StreamFile struct {
 	// StreamOrder preserves stream insertion order for diagnostics parity.
 	StreamOrder       []uint16
 	StreamDiagnostics map[uint16][]StreamDiagnostics
+
+	// PacketCount, SyncLostPackets and ScrambledPackets summarize the last scan.
+	// A high share of lost-sync or scrambled packets usually means the stream is
+	// still AACS/BD+ encrypted.
+	PacketCount      uint64
+	SyncLostPackets  uint64
+	ScrambledPackets uint64
+
+	// UnknownPIDs holds the bytes of each PID the clip info does not list, from
+	// the last scan with Settings.TrackUnknownPIDs; nil otherwise.
+	UnknownPIDs map[uint16]uint64
+
+	// AudioDropouts lists the gaps and LPCM silences of at least two seconds
+	// in the lossless audio streams, from the last full scan.
+	AudioDropouts []AudioDropout
+
+	// trace, when set, receives one record per flushed bitrate window.
+	trace           *BitrateTrace
+	traceFile       string
+	tracePass       string
+	tracePacketSize uint64
+
+	// captions, when set, receives the subtitle images decoded from the
+	// presentation graphics streams.
+	captions func(PGSCaption)
+
+	// readChunkSize overrides the default 5 MiB read-ahead (optical media and
+	// Settings.LowMemory).
+	readChunkSize int
+
+	// splitOverlaps shares packet windows between overlapping clips of one
+	// playlist (Settings.SplitOverlappingClips); clipMatches is scratch space
+	// for the clips a window is attributed to.
+	splitOverlaps bool
+	clipMatches   []int
+
+	// recordClips makes a scan keep, in clipRecords, what it adds to each clip
+	// of its playlists, for the stream cache.
+	recordClips bool
+	clipRecords []cachedClip
 }
 
 type streamState struct {
 	windowPackets       uint64
 	windowBytes         uint64
+	windowFirstPacket   uint64
 	dtsPrev             uint64
 	tsCount             uint64
 	tsLast              uint64
@@ -381,11 +432,16 @@ type streamState struct {
 	pesStarted          bool
 	pesStartCount       uint64
 	collectDiagnostics  bool
+	audioWindows        bool
+	pgs                 *pgsTransfer
+	audio               *audioContinuity
 }
 
 type scanClipTarget struct {
-	clip    *StreamClip
-	streams map[uint16]stream.Info
+	clip     *StreamClip
+	streams  map[uint16]stream.Info
+	playlist string
+	index    int
 }
 
 // clipTargetCursor narrows clip-target scans to time-overlapping clips while
@@ -400,13 +456,47 @@ type clipTargetCursor struct {
 	hasLast     bool
 }
 
+// covers reports whether a window marked at streamTime belongs to the target's
+// clip; a zero marker (no timestamp yet) belongs to every clip.
+func (t scanClipTarget) covers(streamTime float64) bool {
+	return streamTime == 0 || (streamTime >= t.clip.TimeIn && streamTime <= t.clip.TimeOut)
+}
+
+// overlapShare returns the position of matches[i] among the matched targets of
+// the same playlist and angle, and how many there are. More than one means the
+// playlist's play items overlap in time on this stream file.
+func overlapShare(targets []scanClipTarget, matches []int, i int) (share, count int) {
+	self := targets[matches[i]]
+	for j, idx := range matches {
+		other := targets[idx]
+		if other.playlist != self.playlist || other.clip.AngleIndex != self.clip.AngleIndex {
+			continue
+		}
+		if j < i {
+			share++
+		}
+		count++
+	}
+	return share, count
+}
+
+// splitCount divides n into count parts and returns part share; the remainder
+// goes to the first parts so the parts add up to n.
+func splitCount(n uint64, share, count int) uint64 {
+	part := n / uint64(count)
+	if uint64(share) < n%uint64(count) {
+		part++
+	}
+	return part
+}
+
 func buildClipTargets(playlists []*PlaylistFile, streamName string) []scanClipTarget {
 	if playlists == nil {
 		return nil
 	}
 	targets := make([]scanClipTarget, 0, 16)
 	for _, playlist := range playlists {
-		for _, clip := range playlist.StreamClips {
+		for i, clip := range playlist.StreamClips {
 			if clip == nil || clip.Name != streamName {
 				continue
 			}
@@ -415,8 +505,10 @@ func buildClipTargets(playlists []*PlaylistFile, streamName string) []scanClipTa
 				playlistStreams = playlist.AngleStreams[clip.AngleIndex-1]
 			}
 			targets = append(targets, scanClipTarget{
-				clip:    clip,
-				streams: playlistStreams,
+				clip:     clip,
+				streams:  playlistStreams,
+				playlist: playlist.Name,
+				index:    i,
 			})
 		}
 	}
@@ -534,6 +626,24 @@ func (s *StreamFile) DisplayName(settings settings.Settings) string {
 	return s.Name
 }
 
+// scanFileInfo returns the file a scan reads: the interleaved SSIF file when
+// enabled and present, the M2TS otherwise.
+func (s *StreamFile) scanFileInfo(settings settings.Settings) fs.FileInfo {
+	if settings.EnableSSIF && s.InterleavedFile != nil && s.InterleavedFile.FileInfo != nil {
+		return s.InterleavedFile.FileInfo
+	}
+	return s.FileInfo
+}
+
+// playlistSettings returns the settings a stream file is scanned with: those
+// of the playlists it belongs to.
+func playlistSettings(playlists []*PlaylistFile) settings.Settings {
+	if len(playlists) > 0 {
+		return playlists[0].Settings
+	}
+	return settings.Settings{}
+}
+
 func (s *StreamFile) Scan(playlists []*PlaylistFile, full bool) error {
 	return s.ScanWithProgress(playlists, full, nil)
 }
@@ -546,6 +656,13 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 	// Match BDInfo: TSStreamFile.Length is derived from parsed timestamps (DTS-based) and
 	// starts at 0. Do not seed it from playlist clip lengths (can differ for tiny/partial captures).
 	s.Length = 0
+	s.PacketCount = 0
+	s.SyncLostPackets = 0
+	s.ScrambledPackets = 0
+	s.UnknownPIDs = nil
+	if full {
+		s.AudioDropouts = nil
+	}
 
 	// ensure streams map populated from clip info
 	if len(s.Streams) == 0 {
@@ -554,11 +671,7 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 				if clip.StreamFile == s && clip.StreamClipFile != nil {
 					pids := clip.StreamClipFile.StreamOrder
 					if len(pids) == 0 {
-						pids = make([]uint16, 0, len(clip.StreamClipFile.Streams))
-						for pid := range clip.StreamClipFile.Streams {
-							pids = append(pids, pid)
-						}
-						sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+						pids = stream.SortedPIDs(clip.StreamClipFile.Streams)
 					}
 					for _, pid := range pids {
 						st, ok := clip.StreamClipFile.Streams[pid]
@@ -576,34 +689,53 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 		}
 	}
 
-	scanSettings := settings.Settings{}
-	if len(playlists) > 0 {
-		scanSettings = playlists[0].Settings
+	scanSettings := playlistSettings(playlists)
+	quick := scanSettings.QuickScan && !full
+	if quick {
+		// The head of the file says nothing about the playlists' bitrates:
+		// leave their packet counts alone so streams keep nominal rates.
+		playlists = nil
+	}
+	s.splitOverlaps = scanSettings.SplitOverlappingClips
+	lowMemory := scanSettings.LowMemory
+	var unknownPackets []uint64
+	if scanSettings.TrackUnknownPIDs {
+		unknownPackets = make([]uint64, maxTSPID)
 	}
-	// Match BDInfo: stream diagnostics data is needed for chapter stats even when the
-	// report omits the STREAM DIAGNOSTICS table.
+	// Match BDInfo: stream diagnostics data is needed for chapter stats and the
+	// frame data file even when the report omits the STREAM DIAGNOSTICS table.
 	collectDiagnostics := true
 
-	fileInfo := s.FileInfo
-	if scanSettings.EnableSSIF && s.InterleavedFile != nil && s.InterleavedFile.FileInfo != nil {
-		fileInfo = s.InterleavedFile.FileInfo
-	}
+	fileInfo := s.scanFileInfo(scanSettings)
 	if fileInfo == nil {
 		return fmt.Errorf("missing stream file info")
 	}
-	initialPMTOrder, _ := detectPMTStreamOrder(fileInfo)
+	initialPMTOrder, _ := detectPMTStreamOrder(fileInfo, s.readChunkSize)
 
 	f, err := fileInfo.OpenRead()
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	closeFile := sync.OnceValue(f.Close)
+	defer closeFile()
 
 	s.Size = fileInfo.Length()
 
 	first := make([]byte, 192)
-	if _, err := io.ReadFull(f, first); err != nil {
-		return err
+	if n, err := io.ReadFull(f, first); err != nil {
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		// Some discs carry stub stream files smaller than a packet: scan
+		// them as empty clips rather than failing.
+		if onBytesProcessed != nil && n > 0 {
+			onBytesProcessed(uint64(n))
+		}
+		return nil
+	}
+	var r io.Reader = f
+	if quick {
+		r = io.LimitReader(f, quickScanBytes-int64(len(first)))
 	}
 
 	packetSize := 192
@@ -617,6 +749,11 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 	} else {
 		return fmt.Errorf("invalid TS sync for %s", s.Name)
 	}
+	if s.trace != nil {
+		s.traceFile = strings.ToUpper(fileInfo.Name())
+		s.tracePass = tracePass(full)
+		s.tracePacketSize = uint64(packetSize)
+	}
 
 	states := make(map[uint16]*streamState, len(s.Streams)+1)
 	var stateByPID [maxTSPID]*streamState
@@ -635,6 +772,9 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 			codecData:          getCodecBuffer(dataCap),
 			pesPacketRemaining: -2,
 			collectDiagnostics: collectDiagnostics,
+			audioWindows:       scanSettings.BitrateGraph && st != nil && st.Base().IsAudioStream(),
+			pgs:                s.newPGSTransfer(pid, st, scanSettings.SubtitleSummary),
+			audio:              newAudioContinuity(pid, st, full),
 		}
 		states[pid] = state
 		if int(pid) < maxTSPID {
@@ -665,11 +805,21 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 	lastTS := uint64(0)
 	clipTargets := buildClipTargets(playlists, s.Name)
 	clipCursor := newClipTargetCursor(clipTargets)
+	s.clipRecords = nil
+	if s.recordClips {
+		s.clipRecords = newClipRecords(clipTargets)
+	}
 
+	packetNumber := uint64(0)
 	processPacket := func(pkt []byte) {
+		packetNumber++
 		if len(pkt) <= syncOffset || pkt[syncOffset] != 0x47 {
+			s.SyncLostPackets++
 			return
 		}
+		if pkt[syncOffset+3]&0xC0 != 0 {
+			s.ScrambledPackets++
+		}
 		pid := (uint16(pkt[syncOffset+1]&0x1f) << 8) | uint16(pkt[syncOffset+2])
 		pidIdx := int(pid)
 		var state *streamState
@@ -679,6 +829,9 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 			st = streamByPID[pidIdx]
 		}
 		if state == nil {
+			if unknownPackets != nil {
+				unknownPackets[pidIdx]++
+			}
 			if unknownState == nil {
 				unknownState = &streamState{pesPacketRemaining: -2, collectDiagnostics: collectDiagnostics}
 				states[unknownStatePID] = unknownState
@@ -697,6 +850,9 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 		payloadStart := (pkt[syncOffset+1] & 0x40) != 0
 		adaptation := (pkt[syncOffset+3] >> 4) & 0x3
 		idx := syncOffset + 4
+		if state.windowPackets == 0 {
+			state.windowFirstPacket = packetNumber - 1
+		}
 		state.windowPackets++
 		if !known {
 			return
@@ -730,6 +886,12 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 
 		if isPESStart {
 			state.pesStartCount++
+			if state.pgs != nil {
+				s.decodePGS(state.pgs, false)
+			}
+			if state.audio != nil {
+				s.endAudioPES(state.audio)
+			}
 
 			// Match BDInfo: HEVC per-transfer tags are derived from the previous PES transfer
 			// (ScanStream runs when a new payload starts, ending the prior transfer).
@@ -808,6 +970,12 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 		if known {
 			state.windowBytes += uint64(len(payload))
 		}
+		if state.pgs != nil {
+			state.pgs.append(state, payload)
+		}
+		if state.audio != nil {
+			state.audio.payload(state, payload)
+		}
 
 		// Match BDInfo: capture per-transfer stream tag for chapter/frame stats.
 		// HEVC tags are derived from slice headers and depend on SPS/PPS state; collect a bounded
@@ -818,7 +986,8 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 					if state.hevcTagBuf == nil {
 						// Match BDInfo: before initialization, TSStreamBuffer captures up to 5MB
 						// and HEVC tag selection can depend on later slices overwriting earlier ones.
-						if state.hevcTagInitialized {
+						// Low-memory scans settle for the prefix used after initialization.
+						if state.hevcTagInitialized || lowMemory {
 							state.hevcTagBuf = make([]byte, 0, 64<<10)
 						} else {
 							state.hevcTagBuf = make([]byte, 0, 5*1024*1024)
@@ -950,7 +1119,10 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 
 	// Match official BDInfo behavior/perf: read large chunks and then walk packets.
 	// (Official uses ~5MB chunks; keep ours aligned to TS packet size.)
-	const targetChunk = 5 * 1024 * 1024
+	targetChunk := 5 * 1024 * 1024
+	if s.readChunkSize > 0 {
+		targetChunk = s.readChunkSize
+	}
 	chunkSize := targetChunk - (targetChunk % packetSize)
 	if chunkSize < packetSize {
 		chunkSize = packetSize * 256
@@ -966,7 +1138,7 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 		copy(buf, first[packetSize:])
 	}
 	for {
-		n, err := f.Read(buf[carryLen : carryLen+chunkSize])
+		n, err := r.Read(buf[carryLen : carryLen+chunkSize])
 		if n == 0 && err != nil {
 			break
 		}
@@ -989,11 +1161,39 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 			break
 		}
 	}
+	// Hold one handle at a time: the PMT fallback below opens the file again.
+	closeFile()
+
+	for _, pid := range stream.SortedPIDs(states) {
+		state := states[pid]
+		if state.pgs != nil {
+			s.decodePGS(state.pgs, true)
+		}
+		if state.audio != nil {
+			s.flushAudio(state.audio)
+		}
+	}
+
+	s.PacketCount = packetNumber
+	if quick {
+		// Timestamps of the head cannot tell the file's duration.
+		s.Length = 0
+	}
+	if unknownPackets != nil {
+		s.UnknownPIDs = make(map[uint16]uint64)
+		for pid, packets := range unknownPackets {
+			if packets > 0 {
+				s.UnknownPIDs[uint16(pid)] = packets * uint64(packetSize)
+			}
+		}
+	}
 
 	// flush remaining window bytes based on last video PTS
 	ptsLast := uint64(0)
 	ptsDiff := int64(0)
-	for pid, st := range s.Streams {
+	// Visit PIDs in order: ptsLast carries over from one video stream to the next.
+	for _, pid := range stream.SortedPIDs(s.Streams) {
+		st := s.Streams[pid]
 		if st == nil || !st.Base().IsVideoStream() {
 			continue
 		}
@@ -1008,7 +1208,8 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 		s.updateStreamBitrates(playlists, clipTargets, clipCursor, states, pid, ptsLast, ptsDiff)
 	}
 
-	for pid, st := range s.Streams {
+	for _, pid := range stream.SortedPIDs(s.Streams) {
+		st := s.Streams[pid]
 		state := states[pid]
 		if state == nil {
 			continue
@@ -1033,9 +1234,11 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 				if state.collectDiagnostics {
 					tag = &state.streamTag
 				}
-				codec.ScanAVC(concrete, data, tag)
+				codec.ScanAVC(concrete, data, tag, scanSettings)
 			case stream.StreamTypeHEVCVideo:
 				codec.ScanHEVC(concrete, data, scanSettings)
+			case stream.StreamTypeMVCVideo:
+				codec.ScanMVC(concrete, data, scanSettings)
 			case stream.StreamTypeMPEG2Video:
 				codec.ScanMPEG2(concrete, data)
 			case stream.StreamTypeVC1Video:
@@ -1046,13 +1249,13 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 			case stream.StreamTypeAC3Audio, stream.StreamTypeAC3PlusAudio, stream.StreamTypeAC3PlusSecondaryAudio:
 				codec.ScanAC3(concrete, data)
 			case stream.StreamTypeAC3TrueHDAudio:
-				codec.ScanTrueHD(concrete, data)
+				codec.ScanTrueHD(concrete, data, scanSettings)
 			case stream.StreamTypeDTSAudio:
 				codec.ScanDTS(concrete, data, int64(concrete.BitRate))
 			case stream.StreamTypeDTSHDAudio, stream.StreamTypeDTSHDMasterAudio, stream.StreamTypeDTSHDSecondaryAudio:
-				codec.ScanDTSHD(concrete, data, int64(concrete.BitRate))
+				codec.ScanDTSHD(concrete, data, int64(concrete.BitRate), scanSettings)
 			case stream.StreamTypeLPCMAudio:
-				codec.ScanLPCM(concrete, data)
+				codec.ScanLPCM(concrete, data, scanSettings)
 			case stream.StreamTypeMPEG2AACAudio, stream.StreamTypeMPEG4AACAudio:
 				codec.ScanAAC(concrete, data)
 			}
@@ -1063,7 +1266,7 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 
 	s.finalizePlaylistVBR(playlists)
 	if len(pmtStreamOrder) == 0 {
-		if detectedOrder, ok := detectPMTStreamOrder(fileInfo); ok {
+		if detectedOrder, ok := detectPMTStreamOrder(fileInfo, s.readChunkSize); ok {
 			pmtStreamOrder = detectedOrder
 		}
 	}
@@ -1093,14 +1296,11 @@ func (s *StreamFile) ScanWithProgress(playlists []*PlaylistFile, full bool, onBy
 			appendIfKnown(pid)
 		}
 		if len(order) < len(s.Streams) {
-			remaining := make([]uint16, 0, len(s.Streams)-len(order))
-			for pid := range s.Streams {
+			for _, pid := range stream.SortedPIDs(s.Streams) {
 				if _, ok := seen[pid]; !ok {
-					remaining = append(remaining, pid)
+					order = append(order, pid)
 				}
 			}
-			sort.Slice(remaining, func(i, j int) bool { return remaining[i] < remaining[j] })
-			order = append(order, remaining...)
 		}
 		s.StreamOrder = order
 	}
@@ -1221,68 +1421,63 @@ func (s *StreamFile) updateStreamBitrate(clipTargets []scanClipTarget, clipCurso
 	streamTime := float64(pts) / 90000.0
 	streamInterval := float64(ptsDiff) / 90000.0
 	streamOffset := streamTime + streamInterval
+	var tracedClips []BitrateTraceClip
 
+	matches := s.clipMatches[:0]
 	if clipCursor != nil {
 		for _, idx := range clipCursor.activeIndices(streamTime) {
-			target := clipTargets[idx]
-			clip := target.clip
-			if streamTime != 0 && (streamTime < clip.TimeIn || streamTime > clip.TimeOut) {
-				continue
-			}
-			clip.PayloadBytes += state.windowBytes
-			clip.PacketCount += state.windowPackets
-
-			if streamOffset > clip.TimeIn && streamOffset-clip.TimeIn > clip.PacketSeconds {
-				clip.PacketSeconds = streamOffset - clip.TimeIn
-			}
-
-			if target.streams != nil {
-				if streamInfo, ok := target.streams[pid]; ok {
-					streamInfo.Base().PayloadBytes += state.windowBytes
-					streamInfo.Base().PacketCount += state.windowPackets
-
-					if streamInfo.Base().IsVideoStream() {
-						streamInfo.Base().PacketSeconds += streamInterval
-						if streamInfo.Base().PacketSeconds > 0 {
-							streamInfo.Base().ActiveBitRate = int64(math.RoundToEven(float64(streamInfo.Base().PayloadBytes) * 8.0 / streamInfo.Base().PacketSeconds))
-						}
-					}
-					if streamInfo.Base().StreamType == stream.StreamTypeAC3TrueHDAudio {
-						if audio, ok := streamInfo.(*stream.AudioStream); ok && audio.CoreStream != nil {
-							streamInfo.Base().ActiveBitRate -= audio.CoreStream.BitRate
-						}
-					}
-				}
+			if clipTargets[idx].covers(streamTime) {
+				matches = append(matches, idx)
 			}
 		}
 	} else {
-		for _, target := range clipTargets {
-			clip := target.clip
-			if streamTime != 0 && (streamTime < clip.TimeIn || streamTime > clip.TimeOut) {
-				continue
+		for idx := range clipTargets {
+			if clipTargets[idx].covers(streamTime) {
+				matches = append(matches, idx)
 			}
-			clip.PayloadBytes += state.windowBytes
-			clip.PacketCount += state.windowPackets
+		}
+	}
+	s.clipMatches = matches
 
-			if streamOffset > clip.TimeIn && streamOffset-clip.TimeIn > clip.PacketSeconds {
-				clip.PacketSeconds = streamOffset - clip.TimeIn
+	for i, idx := range matches {
+		target := clipTargets[idx]
+		clip := target.clip
+		bytes, packets, interval := state.windowBytes, state.windowPackets, streamInterval
+		if s.splitOverlaps {
+			if share, count := overlapShare(clipTargets, matches, i); count > 1 {
+				bytes = splitCount(bytes, share, count)
+				packets = splitCount(packets, share, count)
+				interval /= float64(count)
 			}
+		}
+		clip.PayloadBytes += bytes
+		clip.PacketCount += packets
+		if s.clipRecords != nil {
+			streamInfo, listed := target.streams[pid]
+			s.clipRecords[idx].record(pid, bytes, packets, streamOffset, interval, listed, listed && streamInfo.Base().IsVideoStream())
+		}
+		if s.trace != nil {
+			tracedClips = append(tracedClips, BitrateTraceClip{Playlist: target.playlist, Clip: target.index, Angle: clip.AngleIndex})
+		}
 
-			if target.streams != nil {
-				if streamInfo, ok := target.streams[pid]; ok {
-					streamInfo.Base().PayloadBytes += state.windowBytes
-					streamInfo.Base().PacketCount += state.windowPackets
+		if streamOffset > clip.TimeIn && streamOffset-clip.TimeIn > clip.PacketSeconds {
+			clip.PacketSeconds = streamOffset - clip.TimeIn
+		}
 
-					if streamInfo.Base().IsVideoStream() {
-						streamInfo.Base().PacketSeconds += streamInterval
-						if streamInfo.Base().PacketSeconds > 0 {
-							streamInfo.Base().ActiveBitRate = int64(math.RoundToEven(float64(streamInfo.Base().PayloadBytes) * 8.0 / streamInfo.Base().PacketSeconds))
-						}
+		if target.streams != nil {
+			if streamInfo, ok := target.streams[pid]; ok {
+				streamInfo.Base().PayloadBytes += bytes
+				streamInfo.Base().PacketCount += packets
+
+				if streamInfo.Base().IsVideoStream() {
+					streamInfo.Base().PacketSeconds += interval
+					if streamInfo.Base().PacketSeconds > 0 {
+						streamInfo.Base().ActiveBitRate = int64(math.RoundToEven(float64(streamInfo.Base().PayloadBytes) * 8.0 / streamInfo.Base().PacketSeconds))
 					}
-					if streamInfo.Base().StreamType == stream.StreamTypeAC3TrueHDAudio {
-						if audio, ok := streamInfo.(*stream.AudioStream); ok && audio.CoreStream != nil {
-							streamInfo.Base().ActiveBitRate -= audio.CoreStream.BitRate
-						}
+				}
+				if streamInfo.Base().StreamType == stream.StreamTypeAC3TrueHDAudio {
+					if audio, ok := streamInfo.(*stream.AudioStream); ok && audio.CoreStream != nil {
+						streamInfo.Base().ActiveBitRate -= audio.CoreStream.BitRate
 					}
 				}
 			}
@@ -1316,9 +1511,33 @@ func (s *StreamFile) updateStreamBitrate(clipTargets []scanClipTarget, clipCurso
 			} else {
 				streamInfo.Base().PacketSeconds += streamInterval
 			}
+		} else if state.collectDiagnostics && state.audioWindows {
+			// Audio windows only feed the bitrate graph.
+			s.StreamDiagnostics[pid] = append(s.StreamDiagnostics[pid], StreamDiagnostics{
+				Marker:   streamTime,
+				Interval: streamInterval,
+				Bytes:    state.windowBytes,
+				Packets:  state.windowPackets,
+			})
 		}
 	}
 
+	if s.trace != nil {
+		s.trace.write(BitrateTraceWindow{
+			Kind:            "window",
+			Pass:            s.tracePass,
+			File:            s.traceFile,
+			PID:             pid,
+			FirstPacket:     state.windowFirstPacket,
+			OffsetBytes:     state.windowFirstPacket * s.tracePacketSize,
+			Packets:         state.windowPackets,
+			PayloadBytes:    state.windowBytes,
+			MarkerSeconds:   streamTime,
+			IntervalSeconds: streamInterval,
+			Clips:           tracedClips,
+		})
+	}
+
 	state.windowPackets = 0
 	state.windowBytes = 0
 }
@@ -3,6 +3,8 @@ package bdrom
 import (
 	"slices"
 	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
 )
 
 func TestClipTargetCursor_ActiveIndicesMatchBaseline(t *testing.T) {
@@ -40,3 +42,36 @@ func baselineClipTargetIndices(targets []scanClipTarget, streamTime float64) []i
 	}
 	return out
 }
+
+func TestUpdateStreamBitrate_SplitOverlaps(t *testing.T) {
+	const pid = 0x1100
+	run := func(split bool) ([]*StreamClip, *StreamClip) {
+		first := &StreamClip{TimeIn: 0, TimeOut: 20}
+		second := &StreamClip{TimeIn: 10, TimeOut: 30}
+		other := &StreamClip{TimeIn: 0, TimeOut: 30}
+		targets := []scanClipTarget{
+			{clip: first, playlist: "00001.MPLS", index: 0},
+			{clip: second, playlist: "00001.MPLS", index: 1},
+			{clip: other, playlist: "00002.MPLS", index: 0},
+		}
+		s := &StreamFile{Streams: map[uint16]stream.Info{}, splitOverlaps: split}
+		state := &streamState{windowBytes: 1001, windowPackets: 7}
+		s.updateStreamBitrate(targets, newClipTargetCursor(targets), pid, 15*90000, 90000, state)
+		return []*StreamClip{first, second}, other
+	}
+
+	clips, _ := run(false)
+	for i, clip := range clips {
+		if clip.PayloadBytes != 1001 || clip.PacketCount != 7 {
+			t.Fatalf("default clip %d got bytes=%d packets=%d, want the full window", i, clip.PayloadBytes, clip.PacketCount)
+		}
+	}
+
+	clips, other := run(true)
+	if clips[0].PayloadBytes != 501 || clips[1].PayloadBytes != 500 || clips[0].PacketCount != 4 || clips[1].PacketCount != 3 {
+		t.Fatalf("split clips got bytes=%d/%d packets=%d/%d", clips[0].PayloadBytes, clips[1].PayloadBytes, clips[0].PacketCount, clips[1].PacketCount)
+	}
+	if other.PayloadBytes != 1001 || other.PacketCount != 7 {
+		t.Fatalf("other playlist got bytes=%d packets=%d, want the full window", other.PayloadBytes, other.PacketCount)
+	}
+}
@@ -0,0 +1,73 @@
+package bdrom
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+func TestStreamFileScan_QuickReadsHeadOnly(t *testing.T) {
+	data := streamCacheTestData()
+	for len(data) < quickScanBytes+1024*1024 {
+		data = append(data, data...)
+	}
+
+	scan := func(quick bool) (*StreamFile, []*PlaylistFile) {
+		s, playlists := streamCacheTestDisc(data)
+		for _, playlist := range playlists {
+			playlist.Settings = settings.Settings{QuickScan: quick}
+		}
+		if err := s.Scan(playlists, false); err != nil {
+			t.Fatalf("Scan() error: %v", err)
+		}
+		return s, playlists
+	}
+
+	s, playlists := scan(true)
+	if want := uint64(quickScanBytes / 188); s.PacketCount != want {
+		t.Fatalf("quick scan read %d packets, want %d", s.PacketCount, want)
+	}
+	if s.Length != 0 || playlists[0].StreamClips[0].PayloadBytes != 0 || playlists[0].Streams[0x1011].Base().BitRate != 0 {
+		t.Fatalf("quick scan measured length=%v clip bytes=%d video bitrate=%d", s.Length, playlists[0].StreamClips[0].PayloadBytes, playlists[0].Streams[0x1011].Base().BitRate)
+	}
+
+	s, playlists = scan(false)
+	if s.PacketCount != uint64(len(data)/188) || playlists[0].StreamClips[0].PayloadBytes == 0 {
+		t.Fatalf("full scan read %d packets, clip bytes=%d", s.PacketCount, playlists[0].StreamClips[0].PayloadBytes)
+	}
+}
+
+func TestStreamFileScan_LowMemoryMatchesDefault(t *testing.T) {
+	data := streamCacheTestData()
+	for len(data) < 4*lowMemoryReadChunk {
+		data = append(data, data...)
+	}
+
+	scan := func(lowMemory bool) (*StreamFile, []*PlaylistFile) {
+		s, playlists := streamCacheTestDisc(data)
+		for _, playlist := range playlists {
+			playlist.Settings = settings.Settings{LowMemory: lowMemory}
+		}
+		if lowMemory {
+			s.readChunkSize = lowMemoryReadChunk
+		}
+		if err := s.Scan(playlists, false); err != nil {
+			t.Fatalf("Scan() error: %v", err)
+		}
+		return s, playlists
+	}
+
+	want, wantPlaylists := scan(false)
+	got, gotPlaylists := scan(true)
+	if got.PacketCount != want.PacketCount || got.Length != want.Length {
+		t.Fatalf("low memory scan read %d packets over %v, want %d over %v", got.PacketCount, got.Length, want.PacketCount, want.Length)
+	}
+	for pid, st := range want.Streams {
+		if g, w := got.Streams[pid].Base(), st.Base(); g.PayloadBytes != w.PayloadBytes || len(got.StreamDiagnostics[pid]) != len(want.StreamDiagnostics[pid]) {
+			t.Fatalf("pid %d: low memory payload=%d diagnostics=%d, want %d and %d", pid, g.PayloadBytes, len(got.StreamDiagnostics[pid]), w.PayloadBytes, len(want.StreamDiagnostics[pid]))
+		}
+	}
+	if g, w := gotPlaylists[0].Streams[0x1011].Base().BitRate, wantPlaylists[0].Streams[0x1011].Base().BitRate; g != w {
+		t.Fatalf("low memory video bitrate=%d, want %d", g, w)
+	}
+}
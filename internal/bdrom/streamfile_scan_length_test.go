@@ -101,3 +101,16 @@ func TestStreamFileScanLengthSingleTimestampIsZero(t *testing.T) {
 		t.Fatalf("expected codec uninitialized for single unterminated transfer: IsVBR=%v IsInitialized=%v EncodingProfile=%q", vs.IsVBR, vs.IsInitialized, vs.EncodingProfile)
 	}
 }
+
+func TestStreamFileScan_StubFiles(t *testing.T) {
+	for _, size := range []int{0, 100} {
+		s := NewStreamFile(&memFileInfo{name: "00099.M2TS", data: make([]byte, size)})
+		var processed uint64
+		if err := s.ScanWithProgress(nil, false, func(n uint64) { processed += n }); err != nil {
+			t.Fatalf("%d byte file: Scan() error: %v", size, err)
+		}
+		if s.Size != int64(size) || s.Length != 0 || s.PacketCount != 0 || processed != uint64(size) {
+			t.Fatalf("%d byte file: got size=%d length=%v packets=%d processed=%d", size, s.Size, s.Length, s.PacketCount, processed)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// structureHeaderSize is the fixed MPLS/CLPI header: type indicator, version
+// and the start addresses of the sections, which begin after it.
+const structureHeaderSize = 40
+
+// structureSection is a length-prefixed section of an MPLS or CLPI file at the
+// start address the header declares. Optional sections may have address 0.
+type structureSection struct {
+	name     string
+	start    int
+	optional bool
+}
+
+// StructureWarning is a structural anomaly of one playlist or clip info file.
+type StructureWarning struct {
+	File    string
+	Message string
+}
+
+// StructureWarnings returns the structural anomalies of the disc's playlist
+// and clip info files, ordered by file name.
+func StructureWarnings(b *BDROM) []StructureWarning {
+	var warnings []StructureWarning
+	for _, name := range slices.Sorted(maps.Keys(b.PlaylistFiles)) {
+		for _, message := range b.PlaylistFiles[name].StructureWarnings {
+			warnings = append(warnings, StructureWarning{File: name, Message: message})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(b.StreamClipFiles)) {
+		for _, message := range b.StreamClipFiles[name].StructureWarnings {
+			warnings = append(warnings, StructureWarning{File: name, Message: message})
+		}
+	}
+	return warnings
+}
+
+// checkMPLSStructure validates the section addresses and lengths of a playlist
+// file against its size.
+func checkMPLSStructure(data []byte, bdav bool) []string {
+	if len(data) < structureHeaderSize {
+		return nil
+	}
+	extension := "ExtensionData"
+	if bdav {
+		extension = "MakersPrivateData"
+	}
+	return checkStructure(data, []structureSection{
+		{name: "AppInfoPlayList", start: structureHeaderSize},
+		{name: "PlayList", start: int(binary.BigEndian.Uint32(data[8:12]))},
+		{name: "PlayListMark", start: int(binary.BigEndian.Uint32(data[12:16]))},
+		{name: extension, start: int(binary.BigEndian.Uint32(data[16:20])), optional: true},
+	})
+}
+
+// checkCLPIStructure validates the section addresses and lengths of a clip
+// info file against its size.
+func checkCLPIStructure(data []byte) []string {
+	if len(data) < structureHeaderSize {
+		return nil
+	}
+	return checkStructure(data, []structureSection{
+		{name: "ClipInfo", start: structureHeaderSize},
+		{name: "SequenceInfo", start: int(binary.BigEndian.Uint32(data[8:12]))},
+		{name: "ProgramInfo", start: int(binary.BigEndian.Uint32(data[12:16]))},
+		{name: "CPI", start: int(binary.BigEndian.Uint32(data[16:20]))},
+		{name: "ClipMark", start: int(binary.BigEndian.Uint32(data[20:24]))},
+		{name: "ExtensionData", start: int(binary.BigEndian.Uint32(data[24:28])), optional: true},
+	})
+}
+
+// checkStructure reports sections that start inside the header or past the
+// end of the file, declare more bytes than the file holds, or run into the
+// next section. Gaps between sections are padding and allowed. Unlike a parse
+// error these leave the file readable, which tells authoring quirks from bad
+// rips.
+func checkStructure(data []byte, sections []structureSection) []string {
+	var warnings []string
+	warn := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+	size := len(data)
+	type span struct {
+		name       string
+		start, end int
+	}
+	var spans []span
+	for _, section := range sections {
+		switch {
+		case section.start == 0 && section.optional:
+			continue
+		case section.start < structureHeaderSize:
+			warn("%s start address %d is inside the %d-byte header", section.name, section.start, structureHeaderSize)
+			continue
+		case section.start+4 > size:
+			warn("%s start address %d is past the end of the file (%d bytes)", section.name, section.start, size)
+			continue
+		}
+		length := int(binary.BigEndian.Uint32(data[section.start : section.start+4]))
+		end := section.start + 4 + length
+		if end > size {
+			warn("%s at %d declares %d bytes, %d past the end of the file (%d bytes)", section.name, section.start, length, end-size, size)
+			end = size
+		}
+		spans = append(spans, span{name: section.name, start: section.start, end: end})
+	}
+	slices.SortStableFunc(spans, func(a, b span) int { return a.start - b.start })
+	for i := 1; i < len(spans); i++ {
+		if prev := spans[i-1]; prev.end > spans[i].start {
+			warn("%s (%d-%d) overlaps %s at %d", prev.name, prev.start, prev.end, spans[i].name, spans[i].start)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,55 @@
+package bdrom
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestCheckMPLSStructure(t *testing.T) {
+	if got := checkMPLSStructure(testMPLS([][2]uint32{{0, 45000 * 60}}), false); len(got) != 0 {
+		t.Fatalf("well-formed playlist got %v", got)
+	}
+
+	// AppInfoPlayList at 40, PlayList at 60, PlayListMark at 80, no extensions.
+	data := make([]byte, 100)
+	copy(data, "MPLS0200")
+	binary.BigEndian.PutUint32(data[8:], 60)
+	binary.BigEndian.PutUint32(data[12:], 80)
+	binary.BigEndian.PutUint32(data[40:], 14)
+	binary.BigEndian.PutUint32(data[60:], 16)
+	binary.BigEndian.PutUint32(data[80:], 16)
+	if got := checkMPLSStructure(data, false); len(got) != 0 {
+		t.Fatalf("well-formed sections got %v", got)
+	}
+
+	binary.BigEndian.PutUint32(data[60:], 30) // PlayList runs into PlayListMark
+	binary.BigEndian.PutUint32(data[80:], 40) // PlayListMark runs past the end
+	binary.BigEndian.PutUint32(data[16:], 8)  // extensions inside the header
+	got := checkMPLSStructure(data, false)
+	for _, want := range []string{
+		"ExtensionData start address 8 is inside the 40-byte header",
+		"PlayListMark at 80 declares 40 bytes, 24 past the end of the file (100 bytes)",
+		"PlayList (60-94) overlaps PlayListMark at 80",
+	} {
+		if !strings.Contains(strings.Join(got, "\n"), want) {
+			t.Fatalf("warnings %q missing %q", got, want)
+		}
+	}
+	if len(got) != 3 {
+		t.Fatalf("warnings %q, want 3", got)
+	}
+}
+
+func TestCheckCLPIStructure(t *testing.T) {
+	data := make([]byte, 120)
+	copy(data, "HDMV0200")
+	for i, start := range []uint32{60, 70, 80, 200} { // SequenceInfo, ProgramInfo, CPI, ClipMark
+		binary.BigEndian.PutUint32(data[8+4*i:], start)
+	}
+	binary.BigEndian.PutUint32(data[40:], 16)
+	got := checkCLPIStructure(data)
+	if len(got) != 1 || got[0] != "ClipMark start address 200 is past the end of the file (120 bytes)" {
+		t.Fatalf("warnings %q", got)
+	}
+}
@@ -0,0 +1,167 @@
+package bdrom
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// BitrateTrace writes a JSON Lines audit of how bitrate figures were derived:
+// one "window" record per packet window flushed into the stream/clip counters,
+// followed by "stream" and "playlist" records for the final reported figures.
+// A stream file restored from the scan cache gets a "cached" record instead of
+// its windows. Every record names its pass: "scan" for the regular scan and
+// "full" for the second pass of ScanFull, which starts the counters over.
+type BitrateTrace struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// tracePass names the pass of a stream file scan in trace records.
+func tracePass(full bool) string {
+	if full {
+		return "full"
+	}
+	return "scan"
+}
+
+// BitrateTraceClip identifies one playlist clip that received a window's bytes.
+type BitrateTraceClip struct {
+	Playlist string `json:"playlist"`
+	Clip     int    `json:"clip"`
+	Angle    int    `json:"angle,omitempty"`
+}
+
+// BitrateTraceWindow records one packet window accounted against a PID.
+type BitrateTraceWindow struct {
+	Kind            string             `json:"kind"`
+	Pass            string             `json:"pass"`
+	File            string             `json:"file"`
+	PID             uint16             `json:"pid"`
+	FirstPacket     uint64             `json:"firstPacket"`
+	OffsetBytes     uint64             `json:"offsetBytes"`
+	Packets         uint64             `json:"packets"`
+	PayloadBytes    uint64             `json:"payloadBytes"`
+	MarkerSeconds   float64            `json:"markerSeconds"`
+	IntervalSeconds float64            `json:"intervalSeconds"`
+	Clips           []BitrateTraceClip `json:"clips,omitempty"`
+}
+
+// BitrateTraceStream records the inputs behind one reported stream bitrate.
+type BitrateTraceStream struct {
+	Kind             string  `json:"kind"`
+	Pass             string  `json:"pass"`
+	Playlist         string  `json:"playlist"`
+	PID              uint16  `json:"pid"`
+	Codec            string  `json:"codec"`
+	VBR              bool    `json:"vbr"`
+	PayloadBytes     uint64  `json:"payloadBytes"`
+	PacketCount      uint64  `json:"packetCount"`
+	PacketSeconds    float64 `json:"packetSeconds"`
+	BitrateBps       int64   `json:"bitrateBps"`
+	ActiveBitrateBps int64   `json:"activeBitrateBps"`
+	Formula          string  `json:"formula"`
+}
+
+// BitrateTracePlaylist records the inputs behind a playlist's total bitrate.
+type BitrateTracePlaylist struct {
+	Kind               string  `json:"kind"`
+	Pass               string  `json:"pass"`
+	Playlist           string  `json:"playlist"`
+	TotalSizeBytes     uint64  `json:"totalSizeBytes"`
+	TotalLengthSeconds float64 `json:"totalLengthSeconds"`
+	PacketSeconds      float64 `json:"packetSeconds"`
+	TotalBitrateBps    uint64  `json:"totalBitrateBps"`
+	Formula            string  `json:"formula"`
+}
+
+// BitrateTraceCached records a stream file whose scan was restored from the
+// cache, so no windows were read for it.
+type BitrateTraceCached struct {
+	Kind    string `json:"kind"`
+	Pass    string `json:"pass"`
+	File    string `json:"file"`
+	Packets uint64 `json:"packets"`
+}
+
+// NewBitrateTrace returns a trace writing JSON Lines records to w.
+func NewBitrateTrace(w io.Writer) *BitrateTrace {
+	return &BitrateTrace{enc: json.NewEncoder(w)}
+}
+
+// Err returns the first write error encountered, if any.
+func (t *BitrateTrace) Err() error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+func (t *BitrateTrace) write(record any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return
+	}
+	t.err = t.enc.Encode(record)
+}
+
+func (t *BitrateTrace) recordCached(s *StreamFile) {
+	if t == nil {
+		return
+	}
+	t.write(BitrateTraceCached{Kind: "cached", Pass: "scan", File: s.Name, Packets: s.PacketCount})
+}
+
+func (t *BitrateTrace) recordPlaylists(playlists []*PlaylistFile, pass string) {
+	if t == nil {
+		return
+	}
+	for _, playlist := range playlists {
+		if playlist == nil {
+			continue
+		}
+		packetSeconds := 0.0
+		for _, clip := range playlist.StreamClips {
+			if clip.AngleIndex == 0 {
+				packetSeconds += clip.PacketSeconds
+			}
+		}
+		for _, st := range playlist.SortedStreams {
+			base := st.Base()
+			formula := "declared (CLPI/MPLS)"
+			if base.IsVBR {
+				formula = "payloadBytes*8/packetSeconds"
+			}
+			t.write(BitrateTraceStream{
+				Kind:             "stream",
+				Pass:             pass,
+				Playlist:         playlist.Name,
+				PID:              base.PID,
+				Codec:            stream.CodecNameForInfo(st),
+				VBR:              base.IsVBR,
+				PayloadBytes:     base.PayloadBytes,
+				PacketCount:      base.PacketCount,
+				PacketSeconds:    packetSeconds,
+				BitrateBps:       base.BitRate,
+				ActiveBitrateBps: base.ActiveBitRate,
+				Formula:          formula,
+			})
+		}
+		t.write(BitrateTracePlaylist{
+			Kind:               "playlist",
+			Pass:               pass,
+			Playlist:           playlist.Name,
+			TotalSizeBytes:     playlist.TotalSize(),
+			TotalLengthSeconds: playlist.TotalLength(),
+			PacketSeconds:      packetSeconds,
+			TotalBitrateBps:    playlist.TotalBitRate(),
+			Formula:            "totalSizeBytes*8/totalLengthSeconds",
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package bdrom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestBitrateTraceRecordsWindows(t *testing.T) {
+	const pid = 0x1011
+
+	pesStart := func(dts uint64) []byte {
+		pts5 := encodePTS(0x30, dts)
+		dts5 := encodePTS(0x10, dts)
+		payload := make([]byte, 184)
+		copy(payload, []byte{0x00, 0x00, 0x01, 0xE0, 0x00, 0x00, 0x80, 0xC0, 0x0A})
+		copy(payload[9:14], pts5[:])
+		copy(payload[14:19], dts5[:])
+		return payload
+	}
+
+	var data []byte
+	for _, pkt := range [][188]byte{
+		tsPacket188(pid, true, pesStart(90000)),
+		tsPacket188(pid, false, make([]byte, 184)),
+		tsPacket188(pid, true, pesStart(93003)),
+		tsPacket188(pid, false, make([]byte, 184)),
+		tsPacket188(pid, true, pesStart(96006)),
+	} {
+		data = append(data, pkt[:]...)
+	}
+
+	s := NewStreamFile(&memFileInfo{name: "00001.M2TS", data: data})
+	s.Streams[pid] = &stream.VideoStream{Stream: stream.Stream{PID: pid, StreamType: stream.StreamTypeAVCVideo}}
+	clip := &StreamClip{Name: s.Name, StreamFile: s, TimeIn: 0, TimeOut: 10}
+	playlist := &PlaylistFile{
+		Name:        "00800.MPLS",
+		StreamClips: []*StreamClip{clip},
+		Streams: map[uint16]stream.Info{
+			pid: &stream.VideoStream{Stream: stream.Stream{PID: pid, StreamType: stream.StreamTypeAVCVideo}},
+		},
+	}
+
+	var buf bytes.Buffer
+	s.trace = NewBitrateTrace(&buf)
+	if err := s.Scan([]*PlaylistFile{playlist}, false); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	if err := s.trace.Err(); err != nil {
+		t.Fatalf("trace error: %v", err)
+	}
+
+	var windows []BitrateTraceWindow
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var w BitrateTraceWindow
+		if err := json.Unmarshal(sc.Bytes(), &w); err != nil {
+			t.Fatalf("unmarshal %q: %v", sc.Text(), err)
+		}
+		windows = append(windows, w)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("windows got=%d want=2 (%+v)", len(windows), windows)
+	}
+	first, second := windows[0], windows[1]
+	if first.Kind != "window" || first.Pass != "scan" || first.File != "00001.M2TS" || first.PID != pid {
+		t.Fatalf("unexpected first window: %+v", first)
+	}
+	if first.FirstPacket != 0 || first.Packets != 3 || first.OffsetBytes != 0 {
+		t.Fatalf("first window packets got=%d@%d want=3@0", first.Packets, first.FirstPacket)
+	}
+	if second.FirstPacket != 3 || second.Packets != 2 || second.OffsetBytes != 3*188 {
+		t.Fatalf("second window packets got=%d@%d offset=%d want=2@3 offset=%d", second.Packets, second.FirstPacket, second.OffsetBytes, 3*188)
+	}
+	if len(first.Clips) != 1 || first.Clips[0].Playlist != "00800.MPLS" || first.Clips[0].Clip != 0 {
+		t.Fatalf("first window clips got=%+v", first.Clips)
+	}
+	if clip.PacketCount != first.Packets+second.Packets {
+		t.Fatalf("clip packet count got=%d want=%d", clip.PacketCount, first.Packets+second.Packets)
+	}
+}
+
+func TestBitrateTraceMarksPassesAndCachedFiles(t *testing.T) {
+	data := streamCacheTestData()
+	cacheDir := t.TempDir()
+	scan := func(full bool) map[string]int {
+		s, playlists := streamCacheTestDisc(data)
+		var buf bytes.Buffer
+		rom := &BDROM{
+			Path:            "DISC",
+			Settings:        settings.Settings{CacheDir: cacheDir},
+			PlaylistFiles:   map[string]*PlaylistFile{playlists[0].Name: playlists[0]},
+			StreamClipFiles: map[string]*StreamClipFile{},
+			StreamFiles:     map[string]*StreamFile{s.Name: s},
+			BitrateTrace:    NewBitrateTrace(&buf),
+		}
+		rom.Scan()
+		if full {
+			rom.ScanFull()
+		}
+		counts := map[string]int{}
+		sc := bufio.NewScanner(&buf)
+		for sc.Scan() {
+			var record struct{ Kind, Pass, File string }
+			if err := json.Unmarshal(sc.Bytes(), &record); err != nil {
+				t.Fatalf("unmarshal %q: %v", sc.Text(), err)
+			}
+			counts[record.Kind+"/"+record.Pass]++
+		}
+		return counts
+	}
+
+	read := scan(true)
+	if read["window/scan"] == 0 || read["window/scan"] != read["window/full"] || read["playlist/scan"] != 1 || read["playlist/full"] != 1 || read["cached/scan"] != 0 {
+		t.Fatalf("records of a read scan and full pass got=%v", read)
+	}
+	if cached := scan(false); cached["cached/scan"] != 1 || cached["window/scan"] != 0 || cached["playlist/scan"] != 1 {
+		t.Fatalf("records of a cached scan got=%v", cached)
+	}
+}
@@ -0,0 +1,47 @@
+package bdrom
+
+import "slices"
+
+// minUnknownPIDShare is the share of a stream file an unlisted PID must carry
+// to be reported by SignificantUnknownPIDs.
+const minUnknownPIDShare = 0.001
+
+// UnknownPID is a PID the clip info does not list, with the bytes of its
+// transport packets.
+type UnknownPID struct {
+	PID   uint16
+	Bytes uint64
+}
+
+// isStructuralPID reports the transport stream PIDs clip info never lists: PAT,
+// SIT, the BD program map, PCR and null packets.
+func isStructuralPID(pid uint16) bool {
+	switch pid {
+	case 0x0000, 0x001F, 0x0100, 0x1001, 0x1FFF:
+		return true
+	default:
+		return false
+	}
+}
+
+// SignificantUnknownPIDs returns the UnknownPIDs that are not transport stream
+// structure and carry at least 0.1% of the file, in PID order.
+func (s *StreamFile) SignificantUnknownPIDs() []UnknownPID {
+	var total uint64
+	for _, bytes := range s.UnknownPIDs {
+		total += bytes
+	}
+	if s.Size > 0 {
+		total = max(total, uint64(s.Size))
+	}
+
+	var out []UnknownPID
+	for pid, bytes := range s.UnknownPIDs {
+		if isStructuralPID(pid) || float64(bytes) < float64(total)*minUnknownPIDShare {
+			continue
+		}
+		out = append(out, UnknownPID{PID: pid, Bytes: bytes})
+	}
+	slices.SortFunc(out, func(a, b UnknownPID) int { return int(a.PID) - int(b.PID) })
+	return out
+}
@@ -0,0 +1,38 @@
+package bdrom
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestStreamFileScan_UnknownPIDs(t *testing.T) {
+	var data []byte
+	for _, pid := range []uint16{0x1011, 0x1400, 0x1FFF, 0x1400, 0x1011, 0x1400} {
+		pkt := tsPacket188(pid, false, nil)
+		data = append(data, pkt[:]...)
+	}
+	scan := func(track bool) *StreamFile {
+		s := NewStreamFile(&memFileInfo{name: "00001.M2TS", data: data})
+		s.Streams[0x1011] = &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo}}
+		playlist := &PlaylistFile{Settings: settings.Settings{TrackUnknownPIDs: track}}
+		if err := s.Scan([]*PlaylistFile{playlist}, false); err != nil {
+			t.Fatalf("Scan() error: %v", err)
+		}
+		return s
+	}
+
+	if s := scan(false); s.UnknownPIDs != nil {
+		t.Fatalf("UnknownPIDs without tracking got=%v", s.UnknownPIDs)
+	}
+
+	s := scan(true)
+	if s.UnknownPIDs[0x1400] != 3*188 || s.UnknownPIDs[0x1FFF] != 188 || len(s.UnknownPIDs) != 2 {
+		t.Fatalf("UnknownPIDs got=%v", s.UnknownPIDs)
+	}
+	got := s.SignificantUnknownPIDs()
+	if len(got) != 1 || got[0] != (UnknownPID{PID: 0x1400, Bytes: 3 * 188}) {
+		t.Fatalf("SignificantUnknownPIDs() got=%+v", got)
+	}
+}
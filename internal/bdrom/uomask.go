@@ -0,0 +1,68 @@
+package bdrom
+
+// UOMask is an MPLS UO_mask_table: each set bit prohibits one user operation.
+// Bit 63 is the first field in the table (menu call).
+type UOMask uint64
+
+// uoMaskNames lists the UO_mask_table fields in table order; empty entries are reserved.
+var uoMaskNames = []string{
+	"Menu Call",
+	"Title Search",
+	"Chapter Search",
+	"Time Search",
+	"Skip To Next Point",
+	"Skip To Previous Point",
+	"Play FirstPlay",
+	"Stop",
+	"Pause On",
+	"Pause Off",
+	"Still Off",
+	"Forward Play",
+	"Backward Play",
+	"Resume",
+	"Move Up",
+	"Move Down",
+	"Move Left",
+	"Move Right",
+	"Select",
+	"Activate",
+	"Select And Activate",
+	"Primary Audio Change",
+	"",
+	"Angle Change",
+	"Popup On",
+	"Popup Off",
+	"PG Enable/Disable",
+	"PG Change",
+	"Secondary Video Enable/Disable",
+	"Secondary Video Change",
+	"Secondary Audio Enable/Disable",
+	"Secondary Audio Change",
+	"",
+	"PiP PG Change",
+}
+
+// Prohibited returns the names of the user operations this mask prohibits.
+func (m UOMask) Prohibited() []string {
+	var out []string
+	for i, name := range uoMaskNames {
+		if name != "" && m&(1<<(63-i)) != 0 {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// Playlist playback types from AppInfoPlayList.
+const (
+	PlaybackSequential = 1
+	PlaybackRandom     = 2
+	PlaybackShuffle    = 3
+)
+
+// Still modes from a PlayItem.
+const (
+	StillNone     = 0
+	StillTime     = 1
+	StillInfinite = 2
+)
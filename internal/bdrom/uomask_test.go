@@ -0,0 +1,18 @@
+package bdrom
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUOMaskProhibited(t *testing.T) {
+	// Menu Call (bit 63), Chapter Search (61), reserved (41) and Angle Change (40).
+	mask := UOMask(1<<63 | 1<<61 | 1<<41 | 1<<40)
+	want := []string{"Menu Call", "Chapter Search", "Angle Change"}
+	if got := mask.Prohibited(); !slices.Equal(got, want) {
+		t.Fatalf("Prohibited() got=%v want=%v", got, want)
+	}
+	if got := UOMask(0).Prohibited(); len(got) != 0 {
+		t.Fatalf("Prohibited() on empty mask got=%v", got)
+	}
+}
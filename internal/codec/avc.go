@@ -1,8 +1,26 @@
 package codec
 
-import "github.com/autobrr/go-bdinfo/internal/stream"
+import (
+	"fmt"
 
-func ScanAVC(v *stream.VideoStream, data []byte, tag *string) {
+	"github.com/autobrr/go-bdinfo/internal/buffer"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+const (
+	avcNALUnitTypeSEI       = 6
+	avcNALUnitTypeSPS       = 7
+	avcNALUnitTypeSubsetSPS = 15
+)
+
+const seiFramePackingArrangement = 45
+
+// ScanAVC reads the profile and level from the first sequence parameter set and
+// tags access units by picture type. With extended stream diagnostics it also
+// records the chroma format, bit depth, VUI colour description and frame
+// packing SEI in an AVCExtendedData.
+func ScanAVC(v *stream.VideoStream, data []byte, tag *string, settings settings.Settings) {
 	parse := uint32(0)
 	accessUnit := 0
 	seqParse := 0
@@ -47,39 +65,313 @@ func ScanAVC(v *stream.VideoStream, data []byte, tag *string) {
 			seqParse--
 			switch seqParse {
 			case 2:
-				switch parse & 0xFF {
-				case 66:
-					profile = "Baseline Profile"
-				case 77:
-					profile = "Main Profile"
-				case 88:
-					profile = "Extended Profile"
-				case 100:
-					profile = "High Profile"
-				case 110:
-					profile = "High 10 Profile"
-				case 122:
-					profile = "High 4:2:2 Profile"
-				case 144:
-					profile = "High 4:4:4 Profile"
-				default:
-					profile = "Unknown Profile"
-				}
+				profile = avcProfileName(byte(parse & 0xFF))
 			case 1:
 				constraintSet3 = byte((parse & 0x10) >> 4)
 			case 0:
-				b := byte(parse & 0xFF)
-				level := ""
-				if b == 11 && constraintSet3 == 1 {
-					level = "1b"
-				} else {
-					level = string([]byte{byte('0' + b/10), '.', byte('0' + b%10)})
-				}
-				v.EncodingProfile = profile + " " + level
+				v.EncodingProfile = profile + " " + avcLevelName(byte(parse&0xFF), constraintSet3 == 1)
 				v.IsVBR = true
 				v.IsInitialized = true
+				if settings.ExtendedStreamDiagnostics {
+					v.ExtendedData = &stream.AVCExtendedData{ExtendedFormatInfo: scanAVCExtended(data)}
+				}
 				return
 			}
 		}
 	}
 }
+
+func avcProfileName(profileIDC byte) string {
+	switch profileIDC {
+	case 66:
+		return "Baseline Profile"
+	case 77:
+		return "Main Profile"
+	case 88:
+		return "Extended Profile"
+	case 100:
+		return "High Profile"
+	case 110:
+		return "High 10 Profile"
+	case 118:
+		return "Multiview High Profile"
+	case 122:
+		return "High 4:2:2 Profile"
+	case 128:
+		return "Stereo High Profile"
+	case 144:
+		return "High 4:4:4 Profile"
+	default:
+		return "Unknown Profile"
+	}
+}
+
+func avcLevelName(levelIDC byte, constraintSet3 bool) string {
+	if levelIDC == 11 && constraintSet3 {
+		return "1b"
+	}
+	return string([]byte{byte('0' + levelIDC/10), '.', byte('0' + levelIDC%10)})
+}
+
+// avcSPSInfo is what the extended diagnostics and the MVC scanner need from an
+// AVC SPS.
+type avcSPSInfo struct {
+	profileIDC      byte
+	levelIDC        byte
+	constraintSet3  bool
+	width           int
+	height          int
+	chromaFormatIDC uint64
+	bitDepthLuma    int
+	bitDepthChroma  int
+	vui             hevcVUI
+	vuiPresent      bool
+}
+
+// scanAVCExtended collects the extended format details of the SPS and SEI NAL
+// units in data, in the order ScanHEVC reports them.
+func scanAVCExtended(data []byte) []string {
+	var sps avcSPSInfo
+	spsFound := false
+	framePacking := ""
+	for _, nal := range findNALUnits(data) {
+		if len(nal) < 2 {
+			continue
+		}
+		switch nal[0] & 0x1F {
+		case avcNALUnitTypeSPS:
+			if !spsFound {
+				sps = parseAVCSPS(RemoveEmulationBytes(nal[1:]))
+				spsFound = true
+			}
+		case avcNALUnitTypeSEI:
+			if framePacking == "" {
+				framePacking = parseAVCFramePacking(RemoveEmulationBytes(nal[1:]))
+			}
+		}
+	}
+
+	var info []string
+	if spsFound {
+		switch sps.chromaFormatIDC {
+		case 0:
+			info = append(info, "4:0:0")
+		case 1:
+			info = append(info, "4:2:0")
+		case 2:
+			info = append(info, "4:2:2")
+		case 3:
+			info = append(info, "4:4:4")
+		}
+		if sps.bitDepthLuma == sps.bitDepthChroma {
+			info = append(info, fmt.Sprintf("%d bits", sps.bitDepthLuma))
+		}
+		if sps.vuiPresent && sps.vui.videoSignalTypePresent {
+			if sps.vui.videoFullRangeFlag == 1 {
+				info = append(info, "Full Range")
+			} else {
+				info = append(info, "Limited Range")
+			}
+			if sps.vui.colourDescriptionPresent {
+				if primaries := hevcColourPrimaries(sps.vui.colourPrimaries); primaries != "" {
+					info = append(info, primaries)
+				}
+				if transfer := hevcTransferCharacteristics(sps.vui.transferCharacteristics); transfer != "" {
+					info = append(info, transfer)
+				}
+				if matrix := hevcMatrixCoefficients(sps.vui.matrixCoefficients); matrix != "" {
+					info = append(info, matrix)
+				}
+			}
+		}
+	}
+	if framePacking != "" {
+		info = append(info, "Frame packing: "+framePacking)
+	}
+	return info
+}
+
+// parseAVCSPS reads seq_parameter_set_data up to the start of the VUI, whose
+// leading fields are laid out as in HEVC.
+func parseAVCSPS(rbsp []byte) avcSPSInfo {
+	br := buffer.NewBitReader(rbsp)
+	readBool := func() bool {
+		v, _ := br.ReadBit()
+		return v == 1
+	}
+	readUE := func() uint64 {
+		v, _ := br.ReadUE()
+		return v
+	}
+	readSE := func() {
+		_, _ = br.ReadSE()
+	}
+
+	info := avcSPSInfo{chromaFormatIDC: 1, bitDepthLuma: 8, bitDepthChroma: 8}
+	profileIDC, _ := br.ReadBits(8)
+	constraintFlags, _ := br.ReadBits(8)
+	levelIDC, _ := br.ReadBits(8)
+	info.profileIDC = byte(profileIDC)
+	info.constraintSet3 = constraintFlags&0x10 != 0
+	info.levelIDC = byte(levelIDC)
+	_ = readUE() // seq_parameter_set_id
+	switch profileIDC {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		info.chromaFormatIDC = readUE()
+		if info.chromaFormatIDC == 3 {
+			_ = br.SkipBits(1) // separate_colour_plane_flag
+		}
+		info.bitDepthLuma = int(min(readUE(), 8)) + 8
+		info.bitDepthChroma = int(min(readUE(), 8)) + 8
+		_ = br.SkipBits(1) // qpprime_y_zero_transform_bypass_flag
+		if readBool() {    // seq_scaling_matrix_present_flag
+			lists := 8
+			if info.chromaFormatIDC == 3 {
+				lists = 12
+			}
+			for i := range lists {
+				if !readBool() {
+					continue
+				}
+				size := 16
+				if i >= 6 {
+					size = 64
+				}
+				skipAVCScalingList(br, size)
+			}
+		}
+	}
+
+	_ = readUE() // log2_max_frame_num_minus4
+	switch readUE() {
+	case 0:
+		_ = readUE() // log2_max_pic_order_cnt_lsb_minus4
+	case 1:
+		_ = br.SkipBits(1) // delta_pic_order_always_zero_flag
+		readSE()
+		readSE()
+		cycle := min(readUE(), 255)
+		for range cycle {
+			readSE()
+		}
+	}
+	_ = readUE()       // max_num_ref_frames
+	_ = br.SkipBits(1) // gaps_in_frame_num_value_allowed_flag
+	widthMbs := min(readUE(), 1023) + 1
+	heightMapUnits := min(readUE(), 1023) + 1
+	frameMbsOnly := readBool()
+	if !frameMbsOnly {
+		_ = br.SkipBits(1) // mb_adaptive_frame_field_flag
+	}
+	_ = br.SkipBits(1) // direct_8x8_inference_flag
+	info.width = int(widthMbs) * 16
+	info.height = int(heightMapUnits) * 16
+	if !frameMbsOnly {
+		info.height *= 2
+	}
+	if readBool() { // frame_cropping_flag
+		var crop [4]uint64
+		for i := range crop {
+			crop[i] = min(readUE(), 1023)
+		}
+		// Crop offsets count chroma samples; field coding doubles the vertical unit.
+		unitX, unitY := 1, 1
+		switch info.chromaFormatIDC {
+		case 1:
+			unitX, unitY = 2, 2
+		case 2:
+			unitX = 2
+		}
+		if !frameMbsOnly {
+			unitY *= 2
+		}
+		info.width -= unitX * int(crop[0]+crop[1])
+		info.height -= unitY * int(crop[2]+crop[3])
+	}
+	if br.BitsRemaining() > 0 && readBool() { // vui_parameters_present_flag
+		info.vui = parseHEVCVUI(br)
+		info.vuiPresent = true
+	}
+	return info
+}
+
+func skipAVCScalingList(br *buffer.BitReader, size int) {
+	last, next := int64(8), int64(8)
+	for range size {
+		if next != 0 {
+			delta, _ := br.ReadSE()
+			next = (last + delta + 256) % 256
+		}
+		if next != 0 {
+			last = next
+		}
+	}
+}
+
+// parseAVCFramePacking returns the arrangement of a frame packing SEI message in
+// the SEI rbsp, or "" when there is none (or it cancels a previous one).
+func parseAVCFramePacking(rbsp []byte) string {
+	br := buffer.NewBitReader(rbsp)
+	for br.Position() < br.Length()-1 {
+		payloadType := uint32(0)
+		for {
+			b, ok := br.ReadByteValue()
+			if !ok {
+				return ""
+			}
+			payloadType += uint32(b)
+			if b != 0xFF {
+				break
+			}
+		}
+		payloadSize := uint32(0)
+		for {
+			b, ok := br.ReadByteValue()
+			if !ok {
+				return ""
+			}
+			payloadSize += uint32(b)
+			if b != 0xFF {
+				break
+			}
+		}
+
+		if payloadType != seiFramePackingArrangement {
+			if !br.Skip(int(payloadSize)) {
+				return ""
+			}
+			continue
+		}
+		_, _ = br.ReadUE() // frame_packing_arrangement_id
+		if cancel, _ := br.ReadBit(); cancel == 1 {
+			return ""
+		}
+		arrangement, ok := br.ReadBits(7)
+		if !ok {
+			return ""
+		}
+		return avcFramePackingArrangement(arrangement)
+	}
+	return ""
+}
+
+func avcFramePackingArrangement(arrangement uint64) string {
+	switch arrangement {
+	case 0:
+		return "Checkerboard"
+	case 1:
+		return "Column Interleaved"
+	case 2:
+		return "Row Interleaved"
+	case 3:
+		return "Side by Side"
+	case 4:
+		return "Top and Bottom"
+	case 5:
+		return "Frame Sequential"
+	case 6:
+		return "2D"
+	default:
+		return ""
+	}
+}
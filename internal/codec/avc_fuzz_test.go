@@ -3,6 +3,7 @@ package codec
 import (
 	"testing"
 
+	"github.com/autobrr/go-bdinfo/internal/settings"
 	"github.com/autobrr/go-bdinfo/internal/stream"
 )
 
@@ -17,6 +18,6 @@ func FuzzScanAVC(f *testing.F) {
 		v := &stream.VideoStream{}
 		v.StreamType = stream.StreamTypeAVCVideo
 		tag := ""
-		ScanAVC(v, data, &tag)
+		ScanAVC(v, data, &tag, settings.Settings{ExtendedStreamDiagnostics: true})
 	})
 }
@@ -0,0 +1,61 @@
+package codec
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// rbspBits packs a string of '0'/'1' (spaces ignored) MSB-first and appends the
+// rbsp stop bit.
+func rbspBits(bits string) []byte {
+	bits = strings.ReplaceAll(bits, " ", "") + "1"
+	for len(bits)%8 != 0 {
+		bits += "0"
+	}
+	out := make([]byte, len(bits)/8)
+	for i, c := range bits {
+		if c == '1' {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
+
+func TestScanAVC_Extended(t *testing.T) {
+	sps := rbspBits("01100100 00000000 00101001" + // High profile, level 4.1
+		" 1 010 1 1 0 0" + // sps_id, chroma_format_idc=1, bit depths 8, no scaling matrix
+		" 1 1 1 00101 0" + // log2_max_frame_num, poc type 0, poc lsb, 4 ref frames, no gaps
+		" 0000001111000 0000001000100" + // 120x68 macroblocks
+		" 1 1 1 1 1 1 00101" + // frame_mbs_only, direct_8x8, cropping 0/0/0/4
+		" 1 1 00000001 0" + // VUI: square pixels, no overscan info
+		" 1 101 0 1 00000001 00000001 00000001") // limited range, BT.709
+	sei := append([]byte{seiFramePackingArrangement, 0x02}, rbspBits("1 0 0000011")...)
+
+	data := []byte{0x00, 0x00, 0x01, 0x67}
+	data = append(data, sps...)
+	data = append(data, 0x00, 0x00, 0x01, 0x06)
+	data = append(data, sei...)
+	data = append(data, 0x00, 0x00, 0x01, 0x09, 0xF0) // next access unit delimiter
+
+	v := &stream.VideoStream{}
+	v.StreamType = stream.StreamTypeAVCVideo
+	ScanAVC(v, data, nil, settings.Settings{})
+	if v.EncodingProfile != "High Profile 4.1" || v.ExtendedData != nil {
+		t.Fatalf("default scan got profile=%q extended=%v", v.EncodingProfile, v.ExtendedData)
+	}
+
+	v = &stream.VideoStream{}
+	v.StreamType = stream.StreamTypeAVCVideo
+	ScanAVC(v, data, nil, settings.Settings{ExtendedStreamDiagnostics: true})
+	want := []string{"4:2:0", "8 bits", "Limited Range", "BT.709", "BT.709", "BT.709", "Frame packing: Side by Side"}
+	if got := v.ExtendedFormatInfo(); !slices.Equal(got, want) {
+		t.Fatalf("ExtendedFormatInfo() got=%q want=%q", got, want)
+	}
+	if desc := v.Description(); !strings.HasSuffix(desc, "High Profile 4.1 / 4:2:0 / 8 bits / Limited Range / BT.709 / BT.709 / BT.709 / Frame packing: Side by Side") {
+		t.Fatalf("Description() got=%q", desc)
+	}
+}
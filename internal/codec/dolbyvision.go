@@ -0,0 +1,254 @@
+package codec
+
+import (
+	"github.com/autobrr/go-bdinfo/internal/buffer"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// Dolby Vision uses the unspecified HEVC NAL unit types: 62 carries the RPU
+// (reference processing unit) metadata and 63 wraps enhancement layer NAL
+// units when both layers share one stream. On Blu-ray the EL has a PID of its
+// own and its RPUs are plain type 62 units.
+const (
+	hevcNALUnitTypeDolbyVisionRPU = 62
+	hevcNALUnitTypeDolbyVisionEL  = 63
+)
+
+const dolbyVisionRPUPrefix = 0x19
+
+// dolbyVisionRPUHeader is the part of rpu_data_header() the profile is guessed
+// from, plus the enhancement layer type read from the NLQ parameters.
+type dolbyVisionRPUHeader struct {
+	rpuProfile                uint64
+	blVideoFullRange          bool
+	vdrBitDepthMinus8         uint64
+	elSpatialResamplingFilter bool
+	disableResidual           bool
+	mappingHeaderPresent      bool
+	// elType is "FEL" or "MEL" for RPUs with residual NLQ parameters, "" when
+	// they are absent or could not be read.
+	elType string
+}
+
+// RPU coefficient coding and mapping methods.
+const (
+	dolbyVisionCoefficientFixed = 0
+	dolbyVisionMappingPoly      = 0
+	dolbyVisionMappingMMR       = 1
+	dolbyVisionNLQLinearDZ      = 0
+)
+
+// parseDolbyVisionRPU reads the RPU header from an RPU NAL unit payload (after
+// the two-byte NAL header, emulation prevention removed).
+func parseDolbyVisionRPU(rbsp []byte) (dolbyVisionRPUHeader, bool) {
+	var h dolbyVisionRPUHeader
+	if len(rbsp) < 4 || rbsp[0] != dolbyVisionRPUPrefix {
+		return h, false
+	}
+	br := buffer.NewBitReader(rbsp[1:])
+	readBool := func() bool {
+		v, _ := br.ReadBit()
+		return v == 1
+	}
+	readUE := func() uint64 {
+		v, _ := br.ReadUE()
+		return v
+	}
+
+	rpuType, _ := br.ReadBits(6)
+	rpuFormat, _ := br.ReadBits(11)
+	if rpuType != 2 {
+		return h, false
+	}
+	h.rpuProfile, _ = br.ReadBits(4)
+	_ = br.SkipBits(4) // vdr_rpu_level
+	if !readBool() {   // vdr_seq_info_present_flag
+		return h, true
+	}
+	_ = br.SkipBits(1) // chroma_resampling_explicit_filter_flag
+	coefficientDataType, _ := br.ReadBits(2)
+	denomBits := uint64(32)
+	if coefficientDataType == dolbyVisionCoefficientFixed {
+		denomBits = readUE() // coefficient_log2_denom
+	}
+	_ = br.SkipBits(2) // vdr_rpu_normalized_idc
+	h.blVideoFullRange = readBool()
+	if rpuFormat&0x700 == 0 {
+		blBitDepth := readUE() + 8
+		elBitDepth := readUE() + 8
+		h.vdrBitDepthMinus8 = readUE()
+		_ = br.SkipBits(4) // spatial_resampling_filter_flag, reserved_zero_3bits
+		h.elSpatialResamplingFilter = readBool()
+		h.disableResidual = readBool()
+		h.mappingHeaderPresent = true
+		if !h.disableResidual {
+			h.elType = readDolbyVisionELType(br, coefficientDataType, denomBits, blBitDepth, elBitDepth)
+		}
+	}
+	return h, true
+}
+
+// readDolbyVisionELType reads on from vdr_dm_metadata_present_flag through
+// the mapping curves to the NLQ parameters of a residual EL and tells a
+// minimal enhancement layer (MEL: every nlq_offset 0, vdr_in_max 1.0 and no
+// dead zone) from a full one (FEL), as dovi_tool does. It returns "" when the RPU reuses a
+// previous one's parameters or ends early.
+func readDolbyVisionELType(br *buffer.BitReader, coefficientDataType, denomBits, blBitDepth, elBitDepth uint64) string {
+	failed := false
+	bits := func(n uint64) uint64 {
+		if n > 64 {
+			failed = true
+			return 0
+		}
+		v, ok := br.ReadBits(int(n))
+		failed = failed || !ok
+		return v
+	}
+	ue := func() uint64 {
+		v, ok := br.ReadUE()
+		failed = failed || !ok
+		return v
+	}
+	se := func() int64 {
+		v, ok := br.ReadSE()
+		failed = failed || !ok
+		return v
+	}
+	// coefficient reads an integer part (fixed point only) and a fraction and
+	// reports whether both are zero.
+	coefficient := func(signed bool) bool {
+		zero := true
+		if coefficientDataType == dolbyVisionCoefficientFixed {
+			if signed {
+				zero = se() == 0
+			} else {
+				zero = ue() == 0
+			}
+		}
+		return bits(denomBits) == 0 && zero
+	}
+
+	_ = bits(1) // vdr_dm_metadata_present_flag
+	if usePrevious := bits(1); usePrevious == 1 {
+		return ""
+	}
+	_, _, _ = ue(), ue(), ue() // vdr_rpu_id, mapping_color_space, mapping_chroma_format_idc
+	var pieces [3]uint64
+	for cmp := range pieces {
+		pieces[cmp] = ue() + 1 // num_pivots_minus2 + 1
+		if failed || pieces[cmp] > 64 {
+			return ""
+		}
+		for range pieces[cmp] + 1 {
+			bits(blBitDepth) // pred_pivot_value
+		}
+	}
+	nlqMethod := bits(3)
+	bits(blBitDepth) // nlq_pred_pivot_value, nlq_num_pivots_minus2 = 0
+	bits(blBitDepth)
+	if ue() != 0 || ue() != 0 || failed { // num_x/y_partitions_minus1
+		return ""
+	}
+
+	for cmp := range pieces {
+		for range pieces[cmp] {
+			switch ue() { // mapping_idc
+			case dolbyVisionMappingPoly:
+				order := ue() + 1 // poly_order_minus1 + 1
+				if order == 1 && bits(1) == 1 {
+					return "" // linear_interp_flag: not used on discs
+				}
+				if order > 8 {
+					return ""
+				}
+				for range order + 1 {
+					coefficient(true)
+				}
+			case dolbyVisionMappingMMR:
+				order := bits(2) + 1 // mmr_order_minus1 + 1
+				coefficient(true)    // mmr_constant
+				for range order * 7 {
+					coefficient(true)
+				}
+			default:
+				return ""
+			}
+			if failed {
+				return ""
+			}
+		}
+	}
+
+	mel := true
+	for range 3 {
+		offset := bits(elBitDepth)
+		var inMaxInt uint64
+		if coefficientDataType == dolbyVisionCoefficientFixed {
+			inMaxInt = ue()
+		}
+		inMax := bits(denomBits)
+		if nlqMethod == dolbyVisionNLQLinearDZ {
+			slopeZero := coefficient(false) // linear_deadzone_slope
+			thresholdZero := coefficient(false)
+			mel = mel && slopeZero && thresholdZero
+		}
+		if offset != 0 || inMaxInt != 1 || inMax != 0 {
+			mel = false
+		}
+	}
+	switch {
+	case failed:
+		return ""
+	case mel:
+		return "MEL"
+	}
+	return "FEL"
+}
+
+// profile guesses the Dolby Vision profile the way dovi_tool does: profile 5
+// is the full range single layer, 7 (or the older 4) has a residual EL and 8
+// is everything else with a cross-compatible base layer.
+func (h dolbyVisionRPUHeader) profile() int {
+	switch h.rpuProfile {
+	case 0:
+		if h.blVideoFullRange {
+			return 5
+		}
+	case 1:
+		if !h.mappingHeaderPresent {
+			return 0
+		}
+		if h.elSpatialResamplingFilter && !h.disableResidual {
+			if h.vdrBitDepthMinus8 == 4 {
+				return 7
+			}
+			return 4
+		}
+		return 8
+	}
+	return 0
+}
+
+// newDolbyVision builds the stream summary from the first RPU header, using the
+// base layer transfer characteristics for the profile 8 compatibility ID.
+func newDolbyVision(h dolbyVisionRPUHeader, transfer byte, elPresent bool) *stream.DolbyVision {
+	dv := &stream.DolbyVision{Profile: h.profile(), EnhancementLayer: elPresent, EnhancementType: h.elType}
+	switch dv.Profile {
+	case 4, 7:
+		dv.EnhancementLayer = true
+		if dv.Profile == 7 {
+			// UHD Blu-ray: HDR10 compatible base layer.
+			dv.Compatibility = 6
+		}
+	case 8:
+		switch transfer {
+		case 16:
+			dv.Compatibility = 1 // HDR10
+		case 18:
+			dv.Compatibility = 4 // HLG
+		default:
+			dv.Compatibility = 2 // SDR
+		}
+	}
+	return dv
+}
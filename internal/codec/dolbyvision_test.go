@@ -0,0 +1,118 @@
+package codec
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// dolbyVisionRPU builds an RPU NAL unit payload with a mapping header; the two
+// flags select a residual EL (profile 7) or none (profile 8).
+func dolbyVisionRPU(elResampling, disableResidual string) []byte {
+	header := rbspBits("000010 00000000000 0001 0000" + // rpu_type 2, format 0, profile 1, level 0
+		" 1 0 00 000011000 01 0" + // seq info, coefficient_log2_denom 23
+		" 011 011 00101 0 000" + // BL/EL 10 bits, VDR 12 bits
+		" " + elResampling + " " + disableResidual)
+	return append([]byte{dolbyVisionRPUPrefix}, header...)
+}
+
+// dolbyVisionELRPU builds a profile 7 RPU through its NLQ parameters: one
+// identity polynomial piece per component, then nlqOffset (10 bits) and
+// vdrInMaxInt (ue) for each of them.
+func dolbyVisionELRPU(nlqOffset, vdrInMaxInt string) []byte {
+	zero23 := strings.Repeat("0", 23)
+	bits := "000010 00000000000 0001 0000 1 0 00 000011000 01 0 011 011 00101 0 000 1 0" + // as dolbyVisionRPU("1", "0")
+		" 0 0 1 1 1" // no DM metadata, fresh RPU, id 0, colour space 0, chroma 0
+	for range 3 {
+		bits += " 1 0000000000 1111111111" // one piece between two pivots
+	}
+	bits += " 000 0000000000 1111111111 1 1" // linear dead zone NLQ, one partition
+	for range 3 {
+		bits += " 1 1 0 1" + zero23 + " 010 " + zero23 // polynomial order 1: 0 + 1.0x
+	}
+	for range 3 {
+		bits += " " + nlqOffset + " " + vdrInMaxInt + " " + zero23 + " 1 " + zero23 + " 1 " + zero23
+	}
+	return append([]byte{dolbyVisionRPUPrefix}, rbspBits(bits)...)
+}
+
+func TestParseDolbyVisionRPU(t *testing.T) {
+	tests := []struct {
+		name     string
+		rpu      []byte
+		transfer byte
+		want     string
+	}{
+		{name: "profile 7", rpu: dolbyVisionRPU("1", "0"), transfer: 16, want: "Dolby Vision (Profile 7.6, BL+EL+RPU)"},
+		{name: "profile 8 hdr10", rpu: dolbyVisionRPU("0", "1"), transfer: 16, want: "Dolby Vision (Profile 8.1, BL+RPU)"},
+		{name: "profile 8 hlg", rpu: dolbyVisionRPU("0", "1"), transfer: 18, want: "Dolby Vision (Profile 8.4, BL+RPU)"},
+		{name: "profile 7 mel", rpu: dolbyVisionELRPU("0000000000", "010"), transfer: 16, want: "Dolby Vision (Profile 7.6, BL+EL+RPU, MEL)"},
+		{name: "profile 7 fel", rpu: dolbyVisionELRPU("1000000000", "010"), transfer: 16, want: "Dolby Vision (Profile 7.6, BL+EL+RPU, FEL)"},
+		{name: "profile 7 fel range", rpu: dolbyVisionELRPU("0000000000", "011"), transfer: 16, want: "Dolby Vision (Profile 7.6, BL+EL+RPU, FEL)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, ok := parseDolbyVisionRPU(tt.rpu)
+			if !ok {
+				t.Fatal("parseDolbyVisionRPU() rejected the RPU")
+			}
+			if got := newDolbyVision(h, tt.transfer, false).String(); got != tt.want {
+				t.Fatalf("got=%q want=%q", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := parseDolbyVisionRPU([]byte{0x00, 0x08, 0x00, 0x10}); ok {
+		t.Fatal("parseDolbyVisionRPU() accepted a payload without the RPU prefix")
+	}
+}
+
+func TestScanHEVC_DolbyVision(t *testing.T) {
+	data := []byte{0x00, 0x00, 0x01, hevcNALUnitTypeDolbyVisionRPU << 1, 0x01}
+	data = append(data, dolbyVisionRPU("1", "0")...)
+	data = append(data, 0x00, 0x00, 0x01, 0x46, 0x01, 0x50) // next access unit delimiter
+
+	v := &stream.VideoStream{}
+	v.StreamType = stream.StreamTypeHEVCVideo
+	ScanHEVC(v, data, settings.Settings{})
+	ext, ok := v.ExtendedData.(*stream.HEVCExtendedData)
+	if !ok || ext.DolbyVision == nil || ext.DolbyVision.ProfileName() != "7.6" {
+		t.Fatalf("DolbyVision got=%+v", v.ExtendedData)
+	}
+	if len(ext.ExtendedFormatInfo) != 0 {
+		t.Fatalf("default ExtendedFormatInfo got=%q want none", ext.ExtendedFormatInfo)
+	}
+
+	v = &stream.VideoStream{}
+	v.StreamType = stream.StreamTypeHEVCVideo
+	ScanHEVC(v, data, settings.Settings{ExtendedStreamDiagnostics: true})
+	if got, want := v.ExtendedFormatInfo(), []string{"Dolby Vision (Profile 7.6, BL+EL+RPU)"}; !slices.Equal(got, want) {
+		t.Fatalf("extended ExtendedFormatInfo got=%q want=%q", got, want)
+	}
+}
+
+func TestHEVCHDRLabel(t *testing.T) {
+	dv := &stream.DolbyVision{Profile: 7, Compatibility: 6, EnhancementLayer: true}
+	tests := []struct {
+		name      string
+		hdr10Plus bool
+		dv        *stream.DolbyVision
+		extended  bool
+		want      string
+	}{
+		{name: "hdr10", want: "HDR10"},
+		{name: "hdr10+", hdr10Plus: true, want: "HDR10+"},
+		{name: "dolby vision", dv: dv, want: "Dolby Vision"},
+		{name: "dolby vision extended", dv: dv, extended: true, want: "Dolby Vision (Profile 7.6, BL+EL+RPU)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hevcHDRLabel(tt.hdr10Plus, tt.dv, tt.extended); got != tt.want {
+				t.Fatalf("got=%q want=%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 
 	"github.com/autobrr/go-bdinfo/internal/buffer"
+	"github.com/autobrr/go-bdinfo/internal/settings"
 	"github.com/autobrr/go-bdinfo/internal/stream"
 )
 
@@ -12,7 +13,9 @@ var dtsHdSampleRates = []int{
 	0x2B110, 0x56220, 0x2EE0, 0x5DC0, 0x0BB80, 0x17700, 0x2EE00, 0x5DC00,
 }
 
-func ScanDTSHD(a *stream.AudioStream, data []byte, fallbackBitrate int64) {
+// ScanDTSHD reads the DTS-HD substream header and the DTS core. With extended
+// stream diagnostics it also flags DTS:X streams that are IMAX Enhanced.
+func ScanDTSHD(a *stream.AudioStream, data []byte, fallbackBitrate int64, settings settings.Settings) {
 	if a.IsInitialized && (a.StreamType == stream.StreamTypeDTSHDSecondaryAudio || (a.CoreStream != nil && a.CoreStream.IsInitialized)) {
 		return
 	}
@@ -245,6 +248,9 @@ func ScanDTSHD(a *stream.AudioStream, data []byte, fallbackBitrate int64) {
 	}
 
 	a.HasExtensions = detectDTSX(data[syncOffset:])
+	if a.HasExtensions && settings.ExtendedStreamDiagnostics && detectDTSXIMAX(data[syncOffset:]) {
+		a.ExtendedData = &stream.ObjectAudioExtendedData{IMAXEnhanced: true}
+	}
 
 	if a.CoreStream != nil && a.CoreStream.AudioMode == stream.AudioModeExtended && a.ChannelCount == 5 {
 		a.AudioMode = stream.AudioModeExtended
@@ -298,3 +304,16 @@ func detectDTSX(data []byte) bool {
 	}
 	return false
 }
+
+// detectDTSXIMAX reports whether a DTS:X stream carries the IMAX Enhanced
+// extension, which has a sync word of its own next to the DTS:X one.
+func detectDTSXIMAX(data []byte) bool {
+	var temp uint32
+	for _, b := range data {
+		temp = (temp << 8) | uint32(b)
+		if temp == 0xF14000D0 {
+			return true
+		}
+	}
+	return false
+}
@@ -64,6 +64,9 @@ func ScanHEVC(v *stream.VideoStream, data []byte, settings settings.Settings) {
 	vuiPresent := false
 	bitDepthMatch := false
 	spsFound := false
+	var rpu dolbyVisionRPUHeader
+	rpuFound := false
+	elFound := false
 
 	nalUnits := findNALUnits(data)
 	for _, nal := range nalUnits {
@@ -141,9 +144,19 @@ func ScanHEVC(v *stream.VideoStream, data []byte, settings settings.Settings) {
 		case hevcNALUnitTypePrefixSEI, hevcNALUnitTypeSuffixSEI:
 			rbsp := RemoveEmulationBytes(nal[2:])
 			parseHEVCSEI(rbsp, &masteringDisplayColorPrimaries, &masteringDisplayLuminance, &maxCLL, &maxFALL, &lightLevelAvailable, &preferredTransferCharacteristics, &isHDR10Plus)
+		case hevcNALUnitTypeDolbyVisionRPU:
+			if !rpuFound {
+				rpu, rpuFound = parseDolbyVisionRPU(RemoveEmulationBytes(nal[2:]))
+			}
+		case hevcNALUnitTypeDolbyVisionEL:
+			elFound = true
 		}
 	}
 
+	if rpuFound {
+		ext.DolbyVision = newDolbyVision(rpu, vui.transferCharacteristics, elFound)
+	}
+
 	if chromaFormat != "" && settings.ExtendedStreamDiagnostics {
 		ext.ExtendedFormatInfo = append(ext.ExtendedFormatInfo, chromaFormat)
 	}
@@ -158,14 +171,10 @@ func ScanHEVC(v *stream.VideoStream, data []byte, settings settings.Settings) {
 		vui.transferCharacteristics == 16 &&
 		(vui.matrixCoefficients == 9 || vui.matrixCoefficients == 10) &&
 		masteringDisplayColorPrimaries != "" {
-		hdr := "HDR10"
-		if isHDR10Plus {
-			hdr = "HDR10+"
-		}
-		if v.PID >= 4117 {
-			hdr = "Dolby Vision"
-		}
-		ext.ExtendedFormatInfo = append(ext.ExtendedFormatInfo, hdr)
+		ext.ExtendedFormatInfo = append(ext.ExtendedFormatInfo, hevcHDRLabel(isHDR10Plus, ext.DolbyVision, settings.ExtendedStreamDiagnostics))
+	} else if ext.DolbyVision != nil && settings.ExtendedStreamDiagnostics {
+		// Profile 5 and other non-HDR10 base layers.
+		ext.ExtendedFormatInfo = append(ext.ExtendedFormatInfo, ext.DolbyVision.String())
 	}
 
 	if vuiPresent && vui.videoSignalTypePresent {
@@ -212,6 +221,21 @@ func ScanHEVC(v *stream.VideoStream, data []byte, settings settings.Settings) {
 	}
 }
 
+// hevcHDRLabel names the HDR format of a stream with an HDR10 base: Dolby
+// Vision when the stream carries RPUs, with its profile and layers in extended
+// diagnostics, HDR10+ or HDR10 otherwise.
+func hevcHDRLabel(hdr10Plus bool, dv *stream.DolbyVision, extended bool) string {
+	switch {
+	case dv != nil && extended:
+		return dv.String()
+	case dv != nil:
+		return "Dolby Vision"
+	case hdr10Plus:
+		return "HDR10+"
+	}
+	return "HDR10"
+}
+
 func parseHEVCSPSVUI(br *buffer.BitReader, maxSubLayersMinus1 uint64, log2MaxPicOrderCntLsbMinus4 uint64) (hevcVUI, bool) {
 	readBool := func() bool {
 		v, _ := br.ReadBit()
@@ -1,8 +1,14 @@
 package codec
 
-import "github.com/autobrr/go-bdinfo/internal/stream"
+import (
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
 
-func ScanLPCM(a *stream.AudioStream, data []byte) {
+// ScanLPCM reads the 4-byte BD LPCM header. With extended stream diagnostics
+// it also keeps the channel assignment, which tells layouts with the same
+// channel count apart, and the quantization and start flags.
+func ScanLPCM(a *stream.AudioStream, data []byte, settings settings.Settings) {
 	if a.IsInitialized {
 		return
 	}
@@ -68,6 +74,14 @@ func ScanLPCM(a *stream.AudioStream, data []byte) {
 		default:
 			a.SampleRate = 0
 		}
+
+		if settings.ExtendedStreamDiagnostics {
+			a.ExtendedData = &stream.LPCMExtendedData{
+				ChannelAssignment: int(flags&0xF000) >> 12,
+				Quantization:      int(flags&0x00C0) >> 6,
+				StartFlag:         flags&0x0020 != 0,
+			}
+		}
 	}
 
 	if a.SampleRate > 0 && a.BitDepth > 0 && a.ChannelCount+a.LFE > 0 {
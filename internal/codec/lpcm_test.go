@@ -0,0 +1,48 @@
+package codec
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestScanLPCMChannelAssignment(t *testing.T) {
+	// 3/4.1 at 48 kHz, 24-bit, start flag set.
+	header := []byte{0x05, 0xA0, 0xB1, 0xE0}
+
+	a := &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeLPCMAudio}}
+	ScanLPCM(a, header, settings.Settings{})
+	if a.ChannelCount != 7 || a.LFE != 1 || a.SampleRate != 48000 || a.BitDepth != 24 || a.ExtendedData != nil {
+		t.Fatalf("default scan got channels=%d.%d rate=%d depth=%d extended=%v", a.ChannelCount, a.LFE, a.SampleRate, a.BitDepth, a.ExtendedData)
+	}
+
+	a = &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeLPCMAudio}}
+	ScanLPCM(a, header, settings.Settings{ExtendedStreamDiagnostics: true})
+	ext, ok := a.ExtendedData.(*stream.LPCMExtendedData)
+	if !ok || ext.ChannelAssignment != 11 || ext.Quantization != 3 || !ext.StartFlag {
+		t.Fatalf("extended scan got %+v", a.ExtendedData)
+	}
+	if got := ext.Layout(); got != "3/4.1" {
+		t.Fatalf("Layout() got=%q", got)
+	}
+	if got := ext.Speakers(); !slices.Equal(got, []string{"L", "C", "R", "Ls", "Rs", "Lrs", "Rrs", "LFE"}) {
+		t.Fatalf("Speakers() got=%v", got)
+	}
+	if got, want := a.Description(), "7.1 / 48 kHz /  9216 kbps / 24-bit / 3/4.1 (L C R Ls Rs Lrs Rrs LFE)"; got != want {
+		t.Fatalf("Description() got=%q want=%q", got, want)
+	}
+
+	// 2/2 and 3/1 both carry four channels.
+	for _, tc := range []struct {
+		flags byte
+		want  string
+	}{{0x71, "2/2 (L R Ls Rs)"}, {0x61, "3/1 (L C R S)"}} {
+		a = &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeLPCMAudio}}
+		ScanLPCM(a, []byte{0, 0, tc.flags, 0x40}, settings.Settings{ExtendedStreamDiagnostics: true})
+		if info := a.ExtendedFormatInfo(); a.ChannelCount != 4 || len(info) != 1 || info[0] != tc.want {
+			t.Fatalf("flags %#x got channels=%d info=%q want %q", tc.flags, a.ChannelCount, info, tc.want)
+		}
+	}
+}
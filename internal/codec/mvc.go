@@ -0,0 +1,36 @@
+package codec
+
+import (
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// ScanMVC handles the MVC dependent view carried in SSIF interleaved files. As
+// in BDInfo it only marks the stream VBR, so its bitrate is measured. With
+// extended stream diagnostics it also reads the profile, level and picture size
+// from the first subset sequence parameter set; until one is seen the stream
+// stays uninitialized.
+func ScanMVC(v *stream.VideoStream, data []byte, settings settings.Settings) {
+	if v.IsInitialized {
+		return
+	}
+	v.IsVBR = true
+	if !settings.ExtendedStreamDiagnostics {
+		v.IsInitialized = true
+		return
+	}
+
+	for _, nal := range findNALUnits(data) {
+		if len(nal) < 4 || nal[0]&0x1F != avcNALUnitTypeSubsetSPS {
+			continue
+		}
+		sps := parseAVCSPS(RemoveEmulationBytes(nal[1:]))
+		v.EncodingProfile = avcProfileName(sps.profileIDC) + " " + avcLevelName(sps.levelIDC, sps.constraintSet3)
+		if sps.width > 0 && sps.height > 0 {
+			v.Width = sps.width
+			v.Height = sps.height
+		}
+		v.IsInitialized = true
+		return
+	}
+}
@@ -0,0 +1,39 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestScanMVC(t *testing.T) {
+	subsetSPS := rbspBits("10000000 00000000 00101001" + // Stereo High profile, level 4.1
+		" 1 010 1 1 0 0" + // sps_id, chroma_format_idc=1, bit depths 8, no scaling matrix
+		" 1 1 1 00101 0" + // log2_max_frame_num, poc type 0, poc lsb, 4 ref frames, no gaps
+		" 0000001111000 0000001000100" + // 120x68 macroblocks
+		" 1 1 1 1 1 1 00101" + // frame_mbs_only, direct_8x8, cropping 0/0/0/4
+		" 0") // no VUI
+
+	data := []byte{0x00, 0x00, 0x01, 0x6F}
+	data = append(data, subsetSPS...)
+	data = append(data, 0x00, 0x00, 0x01, 0x18) // next MVC access unit delimiter
+
+	v := &stream.VideoStream{}
+	v.StreamType = stream.StreamTypeMVCVideo
+	ScanMVC(v, data, settings.Settings{})
+	if !v.IsVBR || !v.IsInitialized || v.EncodingProfile != "" || v.Width != 0 {
+		t.Fatalf("default scan got vbr=%v initialized=%v profile=%q width=%d", v.IsVBR, v.IsInitialized, v.EncodingProfile, v.Width)
+	}
+
+	v = &stream.VideoStream{}
+	v.StreamType = stream.StreamTypeMVCVideo
+	ScanMVC(v, data[:4], settings.Settings{ExtendedStreamDiagnostics: true})
+	if v.IsInitialized {
+		t.Fatal("extended scan initialized without a subset SPS")
+	}
+	ScanMVC(v, data, settings.Settings{ExtendedStreamDiagnostics: true})
+	if !v.IsInitialized || v.EncodingProfile != "Stereo High Profile 4.1" || v.Width != 1920 || v.Height != 1080 {
+		t.Fatalf("extended scan got initialized=%v profile=%q size=%dx%d", v.IsInitialized, v.EncodingProfile, v.Width, v.Height)
+	}
+}
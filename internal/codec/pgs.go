@@ -1,6 +1,13 @@
 package codec
 
-import "github.com/autobrr/go-bdinfo/internal/stream"
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
 
 func ScanPGS(g *stream.GraphicsStream, _ []byte) {
 	if g.IsInitialized {
@@ -8,3 +15,353 @@ func ScanPGS(g *stream.GraphicsStream, _ []byte) {
 	}
 	g.IsInitialized = true
 }
+
+// CountPGSCaptions counts the captions of one PES packet of a presentation
+// graphics stream without decoding them: each composition segment that shows
+// objects, palette-only updates such as fades aside, is a caption, and a
+// forced one when any of its objects is forced. It also takes the video size
+// from the composition.
+func CountPGSCaptions(g *stream.GraphicsStream, data []byte) {
+	for pos := 0; pos+3 <= len(data); {
+		kind := data[pos]
+		size := int(binary.BigEndian.Uint16(data[pos+1 : pos+3]))
+		pos += 3
+		if pos+size > len(data) {
+			return
+		}
+		segment := data[pos : pos+size]
+		pos += size
+		if kind != pgsSegmentPCS || len(segment) < 11 {
+			continue
+		}
+		g.Width = int(binary.BigEndian.Uint16(segment[0:2]))
+		g.Height = int(binary.BigEndian.Uint16(segment[2:4]))
+		count := int(segment[10])
+		if count == 0 || segment[8]&0x80 != 0 {
+			continue
+		}
+		g.Captions++
+		for i, at := 0, 11; i < count && at+8 <= len(segment); i++ {
+			flags := segment[at+3]
+			if flags&pgsObjectForced != 0 {
+				g.ForcedCaptions++
+				break
+			}
+			at += 8
+			if flags&pgsObjectCropped != 0 {
+				at += 8
+			}
+		}
+	}
+}
+
+// PGS segment types (BD-ROM Part 3, 9.14.2).
+const (
+	pgsSegmentPDS = 0x14
+	pgsSegmentODS = 0x15
+	pgsSegmentPCS = 0x16
+	pgsSegmentWDS = 0x17
+	pgsSegmentEND = 0x80
+
+	pgsEpochStart      = 0x80
+	pgsObjectCropped   = 0x80
+	pgsObjectForced    = 0x40
+	pgsFirstInSequence = 0x80
+	pgsLastInSequence  = 0x40
+)
+
+// PGSCaption is one presentation graphics composition with its display time on
+// the 90 kHz PTS clock. End is 0 when the stream ended while it was shown.
+type PGSCaption struct {
+	Start  uint64
+	End    uint64
+	Forced bool
+	Image  *image.NRGBA
+}
+
+type pgsPlacement struct {
+	object uint16
+	forced bool
+	x, y   int
+	crop   image.Rectangle
+}
+
+type pgsObject struct {
+	width, height int
+	data          []byte
+	complete      bool
+}
+
+// PGSDecoder assembles the segments of a presentation graphics stream into
+// captions. Feed it every PES payload of one PID in stream order; a caption is
+// returned once the composition that replaces or clears it arrives.
+type PGSDecoder struct {
+	palettes   map[byte]*[256]color.NRGBA
+	objects    map[uint16]*pgsObject
+	pts        uint64
+	paletteID  byte
+	placements []pgsPlacement
+	shown      *PGSCaption
+}
+
+func NewPGSDecoder() *PGSDecoder {
+	return &PGSDecoder{palettes: map[byte]*[256]color.NRGBA{}, objects: map[uint16]*pgsObject{}}
+}
+
+// Decode consumes the segments of one PES payload presented at pts and returns
+// the captions it ended.
+func (d *PGSDecoder) Decode(pts uint64, data []byte) []PGSCaption {
+	var done []PGSCaption
+	for len(data) >= 3 {
+		kind := data[0]
+		size := int(binary.BigEndian.Uint16(data[1:3]))
+		if 3+size > len(data) {
+			break
+		}
+		segment := data[3 : 3+size]
+		data = data[3+size:]
+		switch kind {
+		case pgsSegmentPCS:
+			d.readComposition(pts, segment)
+		case pgsSegmentPDS:
+			d.readPalette(segment)
+		case pgsSegmentODS:
+			d.readObject(segment)
+		case pgsSegmentEND:
+			if caption, ok := d.endDisplaySet(); ok {
+				done = append(done, caption)
+			}
+		}
+	}
+	return done
+}
+
+// Flush returns the caption still on screen when the stream ended.
+func (d *PGSDecoder) Flush() []PGSCaption {
+	if d.shown == nil {
+		return nil
+	}
+	caption := *d.shown
+	d.shown = nil
+	return []PGSCaption{caption}
+}
+
+func (d *PGSDecoder) readComposition(pts uint64, segment []byte) {
+	if len(segment) < 11 {
+		return
+	}
+	if segment[7]&pgsEpochStart != 0 {
+		clear(d.objects)
+		clear(d.palettes)
+	}
+	d.pts = pts
+	d.paletteID = segment[9]
+	d.placements = d.placements[:0]
+	count := int(segment[10])
+	pos := 11
+	for range count {
+		if pos+8 > len(segment) {
+			break
+		}
+		placement := pgsPlacement{
+			object: binary.BigEndian.Uint16(segment[pos : pos+2]),
+			forced: segment[pos+3]&pgsObjectForced != 0,
+			x:      int(binary.BigEndian.Uint16(segment[pos+4 : pos+6])),
+			y:      int(binary.BigEndian.Uint16(segment[pos+6 : pos+8])),
+		}
+		cropped := segment[pos+3]&pgsObjectCropped != 0
+		pos += 8
+		if cropped {
+			if pos+8 > len(segment) {
+				break
+			}
+			cx := int(binary.BigEndian.Uint16(segment[pos : pos+2]))
+			cy := int(binary.BigEndian.Uint16(segment[pos+2 : pos+4]))
+			cw := int(binary.BigEndian.Uint16(segment[pos+4 : pos+6]))
+			ch := int(binary.BigEndian.Uint16(segment[pos+6 : pos+8]))
+			placement.crop = image.Rect(cx, cy, cx+cw, cy+ch)
+			pos += 8
+		}
+		d.placements = append(d.placements, placement)
+	}
+}
+
+// readPalette applies palette entries (id, Y, Cr, Cb, alpha) to their palette.
+func (d *PGSDecoder) readPalette(segment []byte) {
+	if len(segment) < 2 {
+		return
+	}
+	palette := d.palettes[segment[0]]
+	if palette == nil {
+		palette = new([256]color.NRGBA)
+		d.palettes[segment[0]] = palette
+	}
+	for entry := segment[2:]; len(entry) >= 5; entry = entry[5:] {
+		palette[entry[0]] = pgsColor(entry[1], entry[2], entry[3], entry[4])
+	}
+}
+
+// pgsColor converts a limited range BT.709 palette entry to RGB.
+func pgsColor(y, cr, cb, alpha byte) color.NRGBA {
+	l := 1.164 * (float64(y) - 16)
+	v := float64(cr) - 128
+	u := float64(cb) - 128
+	clamp := func(x float64) uint8 {
+		return uint8(math.Round(max(0, min(255, x))))
+	}
+	return color.NRGBA{
+		R: clamp(l + 1.793*v),
+		G: clamp(l - 0.213*u - 0.533*v),
+		B: clamp(l + 2.112*u),
+		A: alpha,
+	}
+}
+
+func (d *PGSDecoder) readObject(segment []byte) {
+	if len(segment) < 4 {
+		return
+	}
+	id := binary.BigEndian.Uint16(segment[:2])
+	sequence := segment[3]
+	data := segment[4:]
+	obj := d.objects[id]
+	if sequence&pgsFirstInSequence != 0 {
+		if len(data) < 7 {
+			return
+		}
+		obj = &pgsObject{
+			// data[0:3] is the object data length, implied by the last fragment.
+			width:  int(binary.BigEndian.Uint16(data[3:5])),
+			height: int(binary.BigEndian.Uint16(data[5:7])),
+		}
+		d.objects[id] = obj
+		data = data[7:]
+	}
+	if obj == nil || obj.complete {
+		return
+	}
+	obj.data = append(obj.data, data...)
+	if sequence&pgsLastInSequence != 0 {
+		obj.complete = true
+	}
+}
+
+// endDisplaySet shows the current composition. The caption it replaces is
+// returned, ending at the new composition's time.
+func (d *PGSDecoder) endDisplaySet() (PGSCaption, bool) {
+	var ended PGSCaption
+	hadShown := d.shown != nil
+	if hadShown {
+		ended = *d.shown
+		ended.End = d.pts
+		d.shown = nil
+	}
+	if img, forced := d.render(); img != nil {
+		d.shown = &PGSCaption{Start: d.pts, Forced: forced, Image: img}
+	}
+	return ended, hadShown
+}
+
+// render draws the composition objects onto one image covering their bounds.
+func (d *PGSDecoder) render() (*image.NRGBA, bool) {
+	palette := d.palettes[d.paletteID]
+	if palette == nil {
+		return nil, false
+	}
+	type placed struct {
+		bitmap *image.NRGBA
+		at     image.Point
+	}
+	var parts []placed
+	var bounds image.Rectangle
+	forced := false
+	for _, placement := range d.placements {
+		obj := d.objects[placement.object]
+		if obj == nil || !obj.complete {
+			continue
+		}
+		bitmap := decodePGSRLE(obj.data, obj.width, obj.height, palette)
+		at := image.Pt(placement.x, placement.y)
+		if !placement.crop.Empty() {
+			bitmap = cropNRGBA(bitmap, placement.crop)
+		}
+		parts = append(parts, placed{bitmap: bitmap, at: at})
+		bounds = bounds.Union(bitmap.Bounds().Sub(bitmap.Bounds().Min).Add(at))
+		forced = forced || placement.forced
+	}
+	if len(parts) == 0 || bounds.Empty() {
+		return nil, false
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for _, part := range parts {
+		origin := part.at.Sub(bounds.Min)
+		src := part.bitmap.Bounds()
+		for y := 0; y < src.Dy(); y++ {
+			for x := 0; x < src.Dx(); x++ {
+				img.SetNRGBA(origin.X+x, origin.Y+y, part.bitmap.NRGBAAt(src.Min.X+x, src.Min.Y+y))
+			}
+		}
+	}
+	return img, forced
+}
+
+func cropNRGBA(img *image.NRGBA, crop image.Rectangle) *image.NRGBA {
+	crop = crop.Intersect(img.Bounds())
+	out := image.NewNRGBA(image.Rect(0, 0, crop.Dx(), crop.Dy()))
+	for y := 0; y < crop.Dy(); y++ {
+		for x := 0; x < crop.Dx(); x++ {
+			out.SetNRGBA(x, y, img.NRGBAAt(crop.Min.X+x, crop.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// decodePGSRLE expands the run-length coded object data into a bitmap. A
+// non-zero byte is one pixel; a zero byte starts a run whose next byte gives
+// the run length (6 or 14 bits) and whether a color follows, or ends the line.
+func decodePGSRLE(data []byte, width, height int, palette *[256]color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	x, y := 0, 0
+	put := func(index byte, run int) {
+		for ; run > 0 && x < width; run-- {
+			img.SetNRGBA(x, y, palette[index])
+			x++
+		}
+	}
+	for i := 0; i < len(data) && y < height; {
+		b := data[i]
+		i++
+		if b != 0 {
+			put(b, 1)
+			continue
+		}
+		if i >= len(data) {
+			break
+		}
+		flags := data[i]
+		i++
+		if flags == 0 {
+			x = 0
+			y++
+			continue
+		}
+		run := int(flags & 0x3F)
+		if flags&0x40 != 0 {
+			if i >= len(data) {
+				break
+			}
+			run = run<<8 | int(data[i])
+			i++
+		}
+		index := byte(0)
+		if flags&0x80 != 0 {
+			if i >= len(data) {
+				break
+			}
+			index = data[i]
+			i++
+		}
+		put(index, run)
+	}
+	return img
+}
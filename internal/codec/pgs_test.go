@@ -0,0 +1,92 @@
+package codec
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func pgsSegment(kind byte, body ...byte) []byte {
+	out := []byte{kind, 0, 0}
+	binary.BigEndian.PutUint16(out[1:], uint16(len(body)))
+	return append(out, body...)
+}
+
+// pgsDisplaySet builds a PCS (one object at 100,50, optionally forced), a
+// palette, a 4x2 object and the END segment. An empty set clears the screen.
+func pgsDisplaySet(compositionNumber byte, forced bool, empty bool) []byte {
+	pcs := []byte{0x07, 0x80, 0x04, 0x38, 0x10, 0, compositionNumber, 0x00, 0x00, 0x00}
+	if empty {
+		pcs = append(pcs, 0)
+		return append(pgsSegment(pgsSegmentPCS, pcs...), pgsSegment(pgsSegmentEND)...)
+	}
+	flags := byte(0)
+	if forced {
+		flags = pgsObjectForced
+	}
+	pcs = append(pcs, 1, 0x00, 0x01, 0x00, flags, 0x00, 100, 0x00, 50)
+	out := pgsSegment(pgsSegmentPCS, pcs...)
+	out = append(out, pgsSegment(pgsSegmentPDS, 0x00, 0x00,
+		0x01, 235, 128, 128, 0xFF, // white
+		0x02, 16, 128, 128, 0x80)...) // half transparent black
+	// Line 1: two white pixels, a run of two black. Line 2: a run of four
+	// transparent pixels.
+	rle := []byte{0x01, 0x01, 0x00, 0x82, 0x02, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00}
+	ods := []byte{0x00, 0x01, 0x00, pgsFirstInSequence | pgsLastInSequence, 0x00, 0x00, byte(len(rle) + 4), 0x00, 0x04, 0x00, 0x02}
+	out = append(out, pgsSegment(pgsSegmentODS, append(ods, rle...)...)...)
+	return append(out, pgsSegment(pgsSegmentEND)...)
+}
+
+func TestPGSDecoder(t *testing.T) {
+	d := NewPGSDecoder()
+	if got := d.Decode(90000, pgsDisplaySet(1, true, false)); len(got) != 0 {
+		t.Fatalf("caption ended before it was cleared: %+v", got)
+	}
+	got := d.Decode(180000, pgsDisplaySet(2, false, true))
+	if len(got) != 1 {
+		t.Fatalf("got %d captions, want 1", len(got))
+	}
+	caption := got[0]
+	if caption.Start != 90000 || caption.End != 180000 || !caption.Forced {
+		t.Fatalf("caption start=%d end=%d forced=%v", caption.Start, caption.End, caption.Forced)
+	}
+	if b := caption.Image.Bounds(); b.Dx() != 4 || b.Dy() != 2 {
+		t.Fatalf("image size %dx%d, want 4x2", b.Dx(), b.Dy())
+	}
+	if c := caption.Image.NRGBAAt(0, 0); c.R != 255 || c.A != 0xFF {
+		t.Fatalf("pixel 0,0 = %+v, want opaque white", c)
+	}
+	if c := caption.Image.NRGBAAt(3, 0); c.R != 0 || c.A != 0x80 {
+		t.Fatalf("pixel 3,0 = %+v, want half transparent black", c)
+	}
+	if c := caption.Image.NRGBAAt(2, 1); c.A != 0 {
+		t.Fatalf("pixel 2,1 = %+v, want transparent", c)
+	}
+	if got := d.Flush(); len(got) != 0 {
+		t.Fatalf("cleared screen flushed %d captions", len(got))
+	}
+
+	d.Decode(270000, pgsDisplaySet(3, false, false))
+	got = d.Flush()
+	if len(got) != 1 || got[0].Start != 270000 || got[0].End != 0 || got[0].Forced {
+		t.Fatalf("flushed %+v, want the unforced caption still shown at 270000", got)
+	}
+}
+
+func TestCountPGSCaptions(t *testing.T) {
+	g := stream.NewGraphicsStream()
+	CountPGSCaptions(g, pgsDisplaySet(1, true, false))
+	CountPGSCaptions(g, pgsDisplaySet(2, false, true))
+	if g.Captions != 1 || g.ForcedCaptions != 1 || !g.ForcedOnly() {
+		t.Fatalf("captions=%d forced=%d forcedOnly=%v, want one forced caption", g.Captions, g.ForcedCaptions, g.ForcedOnly())
+	}
+	if g.Width != 1920 || g.Height != 1080 {
+		t.Fatalf("size %dx%d, want 1920x1080", g.Width, g.Height)
+	}
+
+	CountPGSCaptions(g, pgsDisplaySet(3, false, false))
+	if g.Captions != 2 || g.ForcedCaptions != 1 || g.ForcedOnly() {
+		t.Fatalf("captions=%d forced=%d forcedOnly=%v, want a full track", g.Captions, g.ForcedCaptions, g.ForcedOnly())
+	}
+}
@@ -4,10 +4,18 @@ import (
 	"encoding/binary"
 
 	"github.com/autobrr/go-bdinfo/internal/buffer"
+	"github.com/autobrr/go-bdinfo/internal/settings"
 	"github.com/autobrr/go-bdinfo/internal/stream"
 )
 
-func ScanTrueHD(a *stream.AudioStream, data []byte) {
+// trueHDExtraChannelMeaningBit is the bit offset, after the major sync, of
+// extra_channel_meaning_present; the 16-channel presentation of Atmos streams
+// follows it.
+const trueHDExtraChannelMeaningBit = 175
+
+// ScanTrueHD reads the major sync of a TrueHD stream and its AC3 core. With
+// extended stream diagnostics it also counts the objects of Atmos streams.
+func ScanTrueHD(a *stream.AudioStream, data []byte, settings settings.Settings) {
 	if a.IsInitialized && (a.CoreStream == nil || a.CoreStream.IsInitialized) {
 		return
 	}
@@ -77,6 +85,11 @@ func ScanTrueHD(a *stream.AudioStream, data []byte) {
 			a.HasExtensions = true
 		}
 	}
+	if a.HasExtensions && settings.ExtendedStreamDiagnostics {
+		if ext, ok := scanTrueHDObjects(data[syncOffset+4:]); ok {
+			a.ExtendedData = ext
+		}
+	}
 
 	a.IsVBR = true
 	scanTrueHDCore(a, data)
@@ -85,6 +98,39 @@ func ScanTrueHD(a *stream.AudioStream, data []byte) {
 	}
 }
 
+// scanTrueHDObjects reads the 16-channel presentation of a major sync (from
+// after its sync word): 16ch_dialogue_norm, 16ch_mix_level,
+// 16ch_channel_count and, for object only presentations, the LFE bed flag.
+func scanTrueHDObjects(data []byte) (*stream.ObjectAudioExtendedData, bool) {
+	br := buffer.NewBitReader(data)
+	if !br.SkipBits(trueHDExtraChannelMeaningBit) {
+		return nil, false
+	}
+	if present, ok := br.ReadBits(1); !ok || present == 0 {
+		return nil, false
+	}
+	// extra_channel_meaning_length, 16ch_dialogue_norm, 16ch_mix_level.
+	if !br.SkipBits(4 + 5 + 6) {
+		return nil, false
+	}
+	channels, ok := br.ReadBits(5)
+	if !ok {
+		return nil, false
+	}
+	objectOnly, ok := br.ReadBits(1)
+	if !ok || objectOnly == 0 {
+		return nil, false
+	}
+	lfe, ok := br.ReadBits(1)
+	if !ok {
+		return nil, false
+	}
+	return &stream.ObjectAudioExtendedData{
+		Objects: int(channels) + 1 - int(lfe),
+		LFEBed:  lfe == 1,
+	}, true
+}
+
 func scanTrueHDCore(a *stream.AudioStream, data []byte) {
 	if a == nil || len(data) < 2 {
 		return
@@ -0,0 +1,90 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// testBits packs fields of the given widths MSB first.
+type testBits struct {
+	data []byte
+	n    int
+}
+
+func (b *testBits) put(value uint64, width int) {
+	for i := width - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.data = append(b.data, 0)
+		}
+		if value>>i&1 == 1 {
+			b.data[len(b.data)-1] |= 0x80 >> (b.n % 8)
+		}
+		b.n++
+	}
+}
+
+func TestScanTrueHDAtmosObjects(t *testing.T) {
+	var b testBits
+	b.put(0xF8726FBA, 32)
+	b.put(0, 4)       // 48 kHz
+	b.put(0, 15)      // multichannel types and 2ch/6ch presentations
+	b.put(0x1801, 13) // 8ch_presentation_channel_assignment
+	b.put(0, 49)      // signature, flags, reserved, variable_rate
+	b.put(0, 15)      // peak_data_rate
+	b.put(0, 79)      // substream info and channel_meaning
+	b.put(1, 1)       // extra_channel_meaning_present
+	b.put(1, 4)       // extra_channel_meaning_length
+	b.put(0, 5+6)     // 16ch_dialogue_norm, 16ch_mix_level
+	b.put(15, 5)      // 16ch_channel_count: 16 channels
+	b.put(1, 1)       // 16ch_dyn_object_only
+	b.put(1, 1)       // 16ch_lfe_present
+	b.put(0, 32)
+
+	a := &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeAC3TrueHDAudio}}
+	ScanTrueHD(a, b.data, settings.Settings{})
+	if !a.HasExtensions || a.ExtendedData != nil {
+		t.Fatalf("default scan got extensions=%t extended=%v", a.HasExtensions, a.ExtendedData)
+	}
+
+	a = &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeAC3TrueHDAudio}}
+	ScanTrueHD(a, b.data, settings.Settings{ExtendedStreamDiagnostics: true})
+	ext, ok := a.ExtendedData.(*stream.ObjectAudioExtendedData)
+	if !ok || ext.Objects != 15 || !ext.LFEBed {
+		t.Fatalf("extended scan got %+v", a.ExtendedData)
+	}
+	if info := a.ExtendedFormatInfo(); len(info) != 1 || info[0] != "15 objects + LFE" {
+		t.Fatalf("ExtendedFormatInfo() got=%q", info)
+	}
+}
+
+func TestScanDTSHDIMAXEnhanced(t *testing.T) {
+	header := append([]byte{0x64, 0x58, 0x20, 0x25}, make([]byte, 12)...)
+	dtsx := append(append([]byte{}, header...), 0x41, 0xA2, 0x95, 0x47, 0x02, 0x00, 0x08, 0x50)
+	imax := append(append([]byte{}, dtsx...), 0xF1, 0x40, 0x00, 0xD0)
+	extended := settings.Settings{ExtendedStreamDiagnostics: true}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		imax bool
+	}{{"dtsx", dtsx, false}, {"imax", imax, true}} {
+		a := &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeDTSHDMasterAudio}}
+		ScanDTSHD(a, tc.data, 0, extended)
+		ext, _ := a.ExtendedData.(*stream.ObjectAudioExtendedData)
+		if !a.HasExtensions || (ext != nil && ext.IMAXEnhanced) != tc.imax {
+			t.Fatalf("%s: got extensions=%t extended=%+v", tc.name, a.HasExtensions, a.ExtendedData)
+		}
+	}
+
+	a := &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeDTSHDMasterAudio}}
+	ScanDTSHD(a, imax, 0, settings.Settings{})
+	if a.ExtendedData != nil {
+		t.Fatalf("default scan got extended=%+v", a.ExtendedData)
+	}
+	a.ExtendedData = &stream.ObjectAudioExtendedData{IMAXEnhanced: true}
+	if info := a.ExtendedFormatInfo(); len(info) != 1 || info[0] != "IMAX Enhanced" {
+		t.Fatalf("ExtendedFormatInfo() got=%q", info)
+	}
+}
@@ -0,0 +1,458 @@
+// Package dvd reads the navigation data of DVD-Video discs (VIDEO_TS folders
+// and ISO images): the titles VIDEO_TS.IFO lists, and the chapters, cells and
+// stream attributes of each title set's VTS_nn_0.IFO. The VOBs are not read,
+// so stream bitrates are unknown and sizes come from the cell sector ranges.
+package dvd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/fs"
+	"github.com/autobrr/go-bdinfo/internal/lang"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// maxIFOSize bounds how much of an IFO file is read; real ones stay far below.
+const maxIFOSize = 16 << 20
+
+// ErrNoDVD is returned by Open when path holds no VIDEO_TS structure.
+var ErrNoDVD = errors.New("unable to locate DVD structure")
+
+// Disc is a DVD-Video disc.
+type Disc struct {
+	Path        string
+	VolumeLabel string
+	// Size is the byte count of every file on the disc.
+	Size   uint64
+	Titles []*Title
+}
+
+// Title is one entry of the disc's title table. Chapters, Length and Size
+// cover the first angle.
+type Title struct {
+	Number      int
+	TitleSet    int
+	TitleInSet  int
+	Angles      int
+	Length      float64
+	Size        uint64
+	Chapters    []Chapter
+	Video       Video
+	Audio       []Audio
+	Subpictures []Subpicture
+}
+
+// Chapter is a part of title; Start is its offset in the title in seconds.
+type Chapter struct {
+	Start  float64
+	Length float64
+}
+
+// Video holds the title set's video attributes.
+type Video struct {
+	StreamType  stream.StreamType
+	Standard    string
+	Width       int
+	Height      int
+	FrameRate   float64
+	AspectRatio string
+	Letterboxed bool
+}
+
+// Audio is an audio stream a title enables. StreamID is its MPEG-PS stream
+// (or private stream 1 substream) ID, e.g. 0x80 for the first AC3 stream.
+type Audio struct {
+	StreamID     byte
+	StreamType   stream.StreamType
+	LanguageCode string
+	Channels     int
+	SampleRate   int
+	BitDepth     int
+	Extension    string
+}
+
+// Subpicture is a subtitle stream a title enables; StreamID is its private
+// stream 1 substream ID (0x20-0x3F).
+type Subpicture struct {
+	StreamID     byte
+	LanguageCode string
+	Extension    string
+}
+
+// Open reads the DVD-Video structure at path: a folder with a VIDEO_TS folder
+// at or below it, or an .iso image. It returns ErrNoDVD when there is none.
+func Open(path string) (*Disc, error) {
+	fileSystem := fs.NewDiskFileSystem()
+	rootPath := path
+	volumeLabel := ""
+	if strings.HasSuffix(strings.ToLower(path), ".iso") {
+		isoFS := fs.NewISOFileSystem()
+		if err := isoFS.Mount(path); err != nil {
+			return nil, err
+		}
+		defer func() { _ = isoFS.Unmount() }()
+		fileSystem = isoFS
+		rootPath = "/"
+		volumeLabel = isoFS.GetVolumeLabel()
+	}
+
+	root, err := fileSystem.GetDirectoryInfo(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	videoTS, vmgFile := findVideoTS(root)
+	if videoTS == nil {
+		return nil, ErrNoDVD
+	}
+	if !fileSystem.IsISO() {
+		// The disc is the folder holding VIDEO_TS.
+		if parent, err := fileSystem.GetDirectoryInfo(filepath.Dir(videoTS.FullName())); err == nil {
+			root = parent
+		}
+	}
+
+	if volumeLabel == "" {
+		volumeLabel = filepath.Base(root.FullName())
+	}
+	disc := &Disc{Path: path, VolumeLabel: volumeLabel, Size: directorySize(root)}
+
+	data, err := readIFO(vmgFile)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseVMG(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", vmgFile.Name(), err)
+	}
+	sets := map[int]*titleSet{}
+	for i, entry := range entries {
+		vts, ok := sets[entry.vts]
+		if !ok {
+			name := fmt.Sprintf("VTS_%02d_0.IFO", entry.vts)
+			file, err := videoTS.GetFile(name)
+			if err != nil {
+				return nil, fmt.Errorf("title %d: %w", i+1, err)
+			}
+			data, err := readIFO(file)
+			if err != nil {
+				return nil, err
+			}
+			if vts, err = parseVTS(data); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			sets[entry.vts] = vts
+		}
+		title, err := buildTitle(i+1, entry, vts)
+		if err != nil {
+			return nil, fmt.Errorf("title %d: %w", i+1, err)
+		}
+		disc.Titles = append(disc.Titles, title)
+	}
+	return disc, nil
+}
+
+// findVideoTS finds the VIDEO_TS directory holding VIDEO_TS.IFO at or below
+// root, breadth first.
+func findVideoTS(root fs.DirectoryInfo) (fs.DirectoryInfo, fs.FileInfo) {
+	queue := []fs.DirectoryInfo{root}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		if strings.EqualFold(dir.Name(), "VIDEO_TS") {
+			if file, err := dir.GetFile("VIDEO_TS.IFO"); err == nil {
+				return dir, file
+			}
+		}
+		if subdirs, err := dir.GetDirectories(); err == nil {
+			queue = append(queue, subdirs...)
+		}
+	}
+	return nil, nil
+}
+
+// readIFO reads an IFO file whole.
+func readIFO(file fs.FileInfo) ([]byte, error) {
+	if file.Length() > maxIFOSize {
+		return nil, fmt.Errorf("%s: IFO of %d bytes is too large", file.Name(), file.Length())
+	}
+	r, err := file.OpenRead()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(io.LimitReader(r, maxIFOSize))
+}
+
+// buildTitle resolves a title table entry to its chapters and streams in its
+// title set.
+func buildTitle(number int, entry titleEntry, vts *titleSet) (*Title, error) {
+	if entry.ttn < 1 || entry.ttn > len(vts.ptts) {
+		return nil, fmt.Errorf("title set %d has no title %d", entry.vts, entry.ttn)
+	}
+	title := &Title{
+		Number:     number,
+		TitleSet:   entry.vts,
+		TitleInSet: entry.ttn,
+		Angles:     max(entry.angles, 1),
+		Video:      videoInfo(vts.video),
+	}
+
+	var first *pgc
+	for i, p := range vts.ptts[entry.ttn-1] {
+		if p.pgcn < 1 || p.pgcn > len(vts.pgcs) {
+			return nil, fmt.Errorf("chapter %d: no program chain %d", i+1, p.pgcn)
+		}
+		chain := &vts.pgcs[p.pgcn-1]
+		if first == nil {
+			first = chain
+		}
+		if p.pgn < 1 || p.pgn > len(chain.programs) {
+			return nil, fmt.Errorf("chapter %d: no program %d", i+1, p.pgn)
+		}
+		// A program runs from its entry cell to the next program's.
+		start := chain.programs[p.pgn-1]
+		end := len(chain.cells) + 1
+		if p.pgn < len(chain.programs) {
+			end = chain.programs[p.pgn]
+		}
+		chapter := Chapter{Start: title.Length}
+		for c := max(start, 1); c < end && c <= len(chain.cells); c++ {
+			if cell := chain.cells[c-1]; cell.mainAngle() {
+				chapter.Length += cell.seconds
+				title.Size += cell.size()
+			}
+		}
+		title.Chapters = append(title.Chapters, chapter)
+		title.Length += chapter.Length
+	}
+	if first == nil {
+		return title, nil
+	}
+
+	for i, attrs := range vts.audio {
+		control := first.audioControl[i]
+		if control&0x8000 == 0 {
+			continue
+		}
+		title.Audio = append(title.Audio, audioInfo(attrs, byte(control>>8)&0x07))
+	}
+	for i, attrs := range vts.subpictures {
+		control := first.subpictureControl[i]
+		if control&0x80000000 == 0 {
+			continue
+		}
+		// The 4:3 stream number, or the widescreen one for 16:9 video.
+		number := byte(control>>24) & 0x1F
+		if vts.video.wide {
+			number = byte(control>>16) & 0x1F
+		}
+		title.Subpictures = append(title.Subpictures, Subpicture{
+			StreamID:     0x20 + number,
+			LanguageCode: attrs.language,
+			Extension:    subpictureExtension(attrs.extension),
+		})
+	}
+	return title, nil
+}
+
+func videoInfo(a videoAttributes) Video {
+	v := Video{
+		StreamType:  stream.StreamTypeMPEG1Video,
+		Standard:    "NTSC",
+		Width:       720,
+		Height:      480,
+		FrameRate:   30000.0 / 1001,
+		AspectRatio: "4:3",
+		Letterboxed: a.letterboxed,
+	}
+	if a.mpeg2 {
+		v.StreamType = stream.StreamTypeMPEG2Video
+	}
+	if a.pal {
+		v.Standard, v.Height, v.FrameRate = "PAL", 576, 25
+	}
+	if a.wide {
+		v.AspectRatio = "16:9"
+	}
+	switch a.pictureSize {
+	case 1:
+		v.Width = 704
+	case 2:
+		v.Width = 352
+	case 3:
+		v.Width, v.Height = 352, v.Height/2
+	}
+	return v
+}
+
+func audioInfo(a audioAttributes, number byte) Audio {
+	audio := Audio{
+		LanguageCode: a.language,
+		Channels:     a.channels,
+		SampleRate:   48000,
+		Extension:    audioExtension(a.extension),
+	}
+	if a.sampleRate == 1 {
+		audio.SampleRate = 96000
+	}
+	switch a.coding {
+	case 0:
+		audio.StreamType, audio.StreamID = stream.StreamTypeAC3Audio, 0x80+number
+	case 2:
+		audio.StreamType, audio.StreamID = stream.StreamTypeMPEG1Audio, 0xC0+number
+	case 3:
+		audio.StreamType, audio.StreamID = stream.StreamTypeMPEG2Audio, 0xC0+number
+	case 4:
+		audio.StreamType, audio.StreamID = stream.StreamTypeLPCMAudio, 0xA0+number
+		audio.BitDepth = []int{16, 20, 24, 0}[a.quantDRC]
+	case 6:
+		audio.StreamType, audio.StreamID = stream.StreamTypeDTSAudio, 0x88+number
+	default:
+		audio.StreamType, audio.StreamID = stream.StreamTypeUnknown, 0xBD
+	}
+	return audio
+}
+
+func audioExtension(code byte) string {
+	switch code {
+	case 2:
+		return "Visually Impaired"
+	case 3:
+		return "Director's Comments"
+	case 4:
+		return "Alternate Director's Comments"
+	}
+	return ""
+}
+
+func subpictureExtension(code byte) string {
+	switch code {
+	case 2:
+		return "Large"
+	case 3:
+		return "Children"
+	case 5:
+		return "Closed Captions"
+	case 6:
+		return "Large Closed Captions"
+	case 7:
+		return "Children's Closed Captions"
+	case 9:
+		return "Forced"
+	case 13:
+		return "Director's Comments"
+	case 14:
+		return "Large Director's Comments"
+	case 15:
+		return "Director's Comments for Children"
+	}
+	return ""
+}
+
+// languageCode maps the two-letter code of an attribute to ISO 639-2, keeping
+// an unknown code as it is.
+func languageCode(b []byte) string {
+	code := strings.ToLower(strings.TrimRight(string(b), "\x00 "))
+	if alpha3 := lang.FromAlpha2(code); alpha3 != "" {
+		return alpha3
+	}
+	return code
+}
+
+// LanguageName returns the language of the stream, "" when the disc gives none.
+func (a Audio) LanguageName() string {
+	if a.LanguageCode == "" {
+		return ""
+	}
+	return lang.CodeName(a.LanguageCode)
+}
+
+// LanguageName returns the language of the stream, "" when the disc gives none.
+func (s Subpicture) LanguageName() string {
+	if s.LanguageCode == "" {
+		return ""
+	}
+	return lang.CodeName(s.LanguageCode)
+}
+
+// CodecName returns the codec as the BD report names it, e.g. "MPEG-2 Video".
+func (v Video) CodecName() string {
+	return (&stream.Stream{StreamType: v.StreamType}).CodecName()
+}
+
+// CodecShortName returns the short codec name, e.g. "MPEG-2".
+func (v Video) CodecShortName() string {
+	return (&stream.Stream{StreamType: v.StreamType}).CodecShortName()
+}
+
+// Description returns the report description, e.g. "480i / 29.970 fps / 16:9".
+func (v Video) Description() string {
+	description := fmt.Sprintf("%di / %.3f fps / %s / %s", v.Height, v.FrameRate, v.AspectRatio, v.Standard)
+	if v.Letterboxed {
+		description += " / Letterboxed"
+	}
+	return description
+}
+
+// CodecName returns the codec as the BD report names it, e.g. "Dolby Digital
+// Audio".
+func (a Audio) CodecName() string {
+	return (&stream.Stream{StreamType: a.StreamType}).CodecName()
+}
+
+// CodecShortName returns the short codec name, e.g. "AC3".
+func (a Audio) CodecShortName() string {
+	return (&stream.Stream{StreamType: a.StreamType}).CodecShortName()
+}
+
+// ChannelDescription returns the layout as "5.1" or "2.0"; six or more
+// channels are taken to include LFE.
+func (a Audio) ChannelDescription() string {
+	if a.Channels >= 6 {
+		return fmt.Sprintf("%d.1", a.Channels-1)
+	}
+	return fmt.Sprintf("%d.0", a.Channels)
+}
+
+// Description returns the report description, e.g. "5.1 / 48 kHz".
+func (a Audio) Description() string {
+	description := fmt.Sprintf("%s / %d kHz", a.ChannelDescription(), a.SampleRate/1000)
+	if a.BitDepth > 0 {
+		description += fmt.Sprintf(" / %d-bit", a.BitDepth)
+	}
+	if a.Extension != "" {
+		description += " / " + a.Extension
+	}
+	return description
+}
+
+// TotalBitrate returns the title's average bitrate in bits per second.
+func (t *Title) TotalBitrate() uint64 {
+	if t.Length <= 0 {
+		return 0
+	}
+	return uint64(float64(t.Size) * 8 / t.Length)
+}
+
+// directorySize sums the files below root.
+func directorySize(root fs.DirectoryInfo) uint64 {
+	var size uint64
+	queue := []fs.DirectoryInfo{root}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		if files, err := dir.GetFiles(); err == nil {
+			for _, file := range files {
+				size += uint64(max(file.Length(), 0))
+			}
+		}
+		if subdirs, err := dir.GetDirectories(); err == nil {
+			queue = append(queue, subdirs...)
+		}
+	}
+	return size
+}
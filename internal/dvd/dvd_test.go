@@ -0,0 +1,162 @@
+package dvd
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// testVMG lays out a VIDEO_TS.IFO with two titles, both in title set 1.
+func testVMG() []byte {
+	data := make([]byte, 2*SectorSize)
+	copy(data, "DVDVIDEO-VMG")
+	binary.BigEndian.PutUint32(data[vmgTitleTableSector:], 1)
+	table := data[SectorSize:]
+	binary.BigEndian.PutUint16(table, 2)
+	for i, entry := range []titleEntry{{angles: 1, chapters: 2, vts: 1, ttn: 1}, {angles: 2, chapters: 1, vts: 1, ttn: 2}} {
+		e := table[8+i*12:]
+		e[1] = byte(entry.angles)
+		binary.BigEndian.PutUint16(e[2:], uint16(entry.chapters))
+		e[6], e[7] = byte(entry.vts), byte(entry.ttn)
+	}
+	return data
+}
+
+// testCell is a cell playback entry: category byte, BCD time, sector range.
+type testCell struct {
+	category    byte
+	time        [4]byte
+	first, last uint32
+}
+
+func putPGC(data []byte, off int, programs []byte, cells []testCell, audio []uint16, subpictures []uint32) {
+	p := data[off:]
+	p[2], p[3] = byte(len(programs)), byte(len(cells))
+	for i, control := range audio {
+		binary.BigEndian.PutUint16(p[0x0C+i*2:], control)
+	}
+	for i, control := range subpictures {
+		binary.BigEndian.PutUint32(p[0x1C+i*4:], control)
+	}
+	binary.BigEndian.PutUint16(p[0xE6:], 0xEC)
+	copy(p[0xEC:], programs)
+	binary.BigEndian.PutUint16(p[0xE8:], 0xF0)
+	for i, c := range cells {
+		e := p[0xF0+i*24:]
+		e[0] = c.category
+		copy(e[4:8], c.time[:])
+		binary.BigEndian.PutUint32(e[8:], c.first)
+		binary.BigEndian.PutUint32(e[20:], c.last)
+	}
+}
+
+// testVTS lays out a VTS_01_0.IFO: 16:9 NTSC MPEG-2, AC3 5.1 English and
+// 20-bit LPCM French commentary, German subtitles. Title 1 has two chapters
+// over three cells; title 2 one chapter with a two-angle block.
+func testVTS() []byte {
+	data := make([]byte, 3*SectorSize)
+	copy(data, "DVDVIDEO-VTS")
+	binary.BigEndian.PutUint32(data[vtsPTTTableSector:], 1)
+	binary.BigEndian.PutUint32(data[vtsPGCTableSector:], 2)
+	data[vtsVideoAttributes] = 0x4C
+	binary.BigEndian.PutUint16(data[vtsAudioCount:], 2)
+	copy(data[vtsAudioAttributes:], []byte{0x04, 0x05, 'e', 'n', 0, 0})
+	copy(data[vtsAudioAttributes+8:], []byte{0x84, 0x41, 'f', 'r', 0, 3})
+	binary.BigEndian.PutUint16(data[vtsSubpictureCount:], 1)
+	copy(data[vtsSubpictureAttrs:], []byte{0x01, 0, 'd', 'e', 0, 0})
+
+	ptt := data[SectorSize:]
+	binary.BigEndian.PutUint16(ptt, 2)
+	binary.BigEndian.PutUint32(ptt[4:], 27)
+	binary.BigEndian.PutUint32(ptt[8:], 16)
+	binary.BigEndian.PutUint32(ptt[12:], 24)
+	for i, v := range []uint16{1, 1, 1, 2, 2, 1} {
+		binary.BigEndian.PutUint16(ptt[16+i*2:], v)
+	}
+
+	pgcit := 2 * SectorSize
+	binary.BigEndian.PutUint16(data[pgcit:], 2)
+	binary.BigEndian.PutUint32(data[pgcit+12:], 24)
+	binary.BigEndian.PutUint32(data[pgcit+20:], 24+0x200)
+	putPGC(data, pgcit+24, []byte{1, 3}, []testCell{
+		{time: [4]byte{0x00, 0x10, 0x00, 0xC0}, first: 0, last: 99},
+		{time: [4]byte{0x00, 0x05, 0x00, 0xD5}, first: 100, last: 199},
+		{time: [4]byte{0x00, 0x20, 0x00, 0xC0}, first: 200, last: 399},
+	}, []uint16{0x8000, 0x8000}, []uint32{0x80010000})
+	putPGC(data, pgcit+24+0x200, []byte{1}, []testCell{
+		{time: [4]byte{0x00, 0x01, 0x00, 0xC0}, first: 0, last: 9},
+		{category: 0x50, time: [4]byte{0x00, 0x02, 0x00, 0xC0}, first: 10, last: 19},
+		{category: 0xD0, time: [4]byte{0x00, 0x02, 0x00, 0xC0}, first: 20, last: 29},
+	}, nil, nil)
+	return data
+}
+
+func writeTestDVD(t *testing.T) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "MOVIE")
+	videoTS := filepath.Join(root, "VIDEO_TS")
+	if err := os.MkdirAll(videoTS, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"VIDEO_TS.IFO": testVMG(), "VTS_01_0.IFO": testVTS()} {
+		if err := os.WriteFile(filepath.Join(videoTS, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestOpen(t *testing.T) {
+	root := writeTestDVD(t)
+	for _, path := range []string{root, filepath.Join(root, "VIDEO_TS"), filepath.Dir(root)} {
+		disc, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open(%s) error: %v", path, err)
+		}
+		if disc.VolumeLabel != "MOVIE" || disc.Size != uint64(5*SectorSize) || len(disc.Titles) != 2 {
+			t.Fatalf("Open(%s) = label %q size %d titles %d", path, disc.VolumeLabel, disc.Size, len(disc.Titles))
+		}
+	}
+
+	disc, _ := Open(root)
+	title := disc.Titles[0]
+	if len(title.Chapters) != 2 || math.Abs(title.Chapters[0].Length-900.5005) > 1e-6 || title.Chapters[1].Start != title.Chapters[0].Length {
+		t.Fatalf("title 1 chapters %+v", title.Chapters)
+	}
+	if math.Abs(title.Length-2100.5005) > 1e-6 || title.Size != 400*SectorSize {
+		t.Fatalf("title 1 length %f size %d", title.Length, title.Size)
+	}
+	if got := title.Video.Description(); title.Video.CodecName() != "MPEG-2 Video" || got != "480i / 29.970 fps / 16:9 / NTSC" {
+		t.Fatalf("title 1 video %s %q", title.Video.CodecName(), got)
+	}
+	if len(title.Audio) != 2 {
+		t.Fatalf("title 1 audio %+v", title.Audio)
+	}
+	ac3, lpcm := title.Audio[0], title.Audio[1]
+	if ac3.StreamType != stream.StreamTypeAC3Audio || ac3.StreamID != 0x80 || ac3.LanguageName() != "English" || ac3.Description() != "5.1 / 48 kHz" {
+		t.Fatalf("AC3 stream %+v %q", ac3, ac3.Description())
+	}
+	if lpcm.StreamID != 0xA0 || lpcm.LanguageCode != "fre" || lpcm.Description() != "2.0 / 48 kHz / 20-bit / Director's Comments" {
+		t.Fatalf("LPCM stream %+v %q", lpcm, lpcm.Description())
+	}
+	if len(title.Subpictures) != 1 || title.Subpictures[0].StreamID != 0x21 || title.Subpictures[0].LanguageName() != "German" {
+		t.Fatalf("title 1 subpictures %+v", title.Subpictures)
+	}
+
+	// Only the first cell of the angle block counts.
+	title = disc.Titles[1]
+	if title.Angles != 2 || title.Length != 180 || title.Size != 20*SectorSize || len(title.Audio) != 0 {
+		t.Fatalf("title 2 angles %d length %f size %d audio %d", title.Angles, title.Length, title.Size, len(title.Audio))
+	}
+}
+
+func TestOpen_NoDVD(t *testing.T) {
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNoDVD) {
+		t.Fatalf("Open() error = %v, want ErrNoDVD", err)
+	}
+}
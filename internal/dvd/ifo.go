@@ -0,0 +1,305 @@
+package dvd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// SectorSize is the DVD logical block size IFO tables are addressed in.
+const SectorSize = 2048
+
+// IFO header offsets (DVD-Video specification, VMGI_MAT and VTSI_MAT).
+const (
+	vmgTitleTableSector = 0xC4
+	vtsPTTTableSector   = 0xC8
+	vtsPGCTableSector   = 0xCC
+	vtsVideoAttributes  = 0x200
+	vtsAudioCount       = 0x202
+	vtsAudioAttributes  = 0x204
+	vtsSubpictureCount  = 0x254
+	vtsSubpictureAttrs  = 0x256
+
+	maxAudioStreams      = 8
+	maxSubpictureStreams = 32
+)
+
+// Cell block types and modes of a cell playback entry.
+const (
+	cellBlockTypeAngle = 1
+	cellBlockModeFirst = 1
+)
+
+// titleEntry is one row of the VMG title search pointer table.
+type titleEntry struct {
+	angles   int
+	chapters int
+	vts      int
+	ttn      int
+}
+
+// titleSet is the parsed VTS_nn_0.IFO of a title set.
+type titleSet struct {
+	video       videoAttributes
+	audio       []audioAttributes
+	subpictures []subpictureAttributes
+	// ptts lists each VTS title's chapters (part of title entries).
+	ptts [][]ptt
+	pgcs []pgc
+}
+
+// ptt points a chapter at its program chain and program.
+type ptt struct {
+	pgcn int
+	pgn  int
+}
+
+// pgc is a program chain: its programs (entry cell numbers, 1-based) and
+// cells, and which audio and subpicture streams it enables.
+type pgc struct {
+	audioControl      [maxAudioStreams]uint16
+	subpictureControl [maxSubpictureStreams]uint32
+	programs          []int
+	cells             []cell
+}
+
+type cell struct {
+	seconds     float64
+	firstSector uint32
+	lastSector  uint32
+	blockMode   byte
+	blockType   byte
+}
+
+// mainAngle reports whether the cell plays on the first angle: it is outside
+// an angle block or the block's first cell.
+func (c cell) mainAngle() bool {
+	return c.blockType != cellBlockTypeAngle || c.blockMode == cellBlockModeFirst
+}
+
+// size is the cell's byte count in the title set's VOBs.
+func (c cell) size() uint64 {
+	if c.lastSector < c.firstSector {
+		return 0
+	}
+	return uint64(c.lastSector-c.firstSector+1) * SectorSize
+}
+
+type videoAttributes struct {
+	mpeg2       bool
+	pal         bool
+	wide        bool
+	pictureSize byte
+	letterboxed bool
+}
+
+type audioAttributes struct {
+	coding     byte
+	language   string
+	quantDRC   byte
+	sampleRate byte
+	channels   int
+	extension  byte
+}
+
+type subpictureAttributes struct {
+	language  string
+	extension byte
+}
+
+// parseVMG reads the title table of VIDEO_TS.IFO.
+func parseVMG(data []byte) ([]titleEntry, error) {
+	if len(data) < 0x100 || string(data[:12]) != "DVDVIDEO-VMG" {
+		return nil, errors.New("not a video manager IFO")
+	}
+	off, err := tableOffset(data, vmgTitleTableSector)
+	if err != nil {
+		return nil, fmt.Errorf("title table: %w", err)
+	}
+	count := int(binary.BigEndian.Uint16(data[off:]))
+	titles := make([]titleEntry, 0, count)
+	for i := range count {
+		e := off + 8 + i*12
+		if e+12 > len(data) {
+			return nil, fmt.Errorf("title table: entry %d out of range", i+1)
+		}
+		titles = append(titles, titleEntry{
+			angles:   int(data[e+1]),
+			chapters: int(binary.BigEndian.Uint16(data[e+2:])),
+			vts:      int(data[e+6]),
+			ttn:      int(data[e+7]),
+		})
+	}
+	return titles, nil
+}
+
+// parseVTS reads a title set IFO: stream attributes, chapters and program
+// chains.
+func parseVTS(data []byte) (*titleSet, error) {
+	if len(data) < vtsSubpictureAttrs+maxSubpictureStreams*6 || string(data[:12]) != "DVDVIDEO-VTS" {
+		return nil, errors.New("not a video title set IFO")
+	}
+	vts := &titleSet{video: parseVideoAttributes(data[vtsVideoAttributes:])}
+	audioCount := min(int(binary.BigEndian.Uint16(data[vtsAudioCount:])), maxAudioStreams)
+	for i := range audioCount {
+		vts.audio = append(vts.audio, parseAudioAttributes(data[vtsAudioAttributes+i*8:]))
+	}
+	subpictureCount := min(int(binary.BigEndian.Uint16(data[vtsSubpictureCount:])), maxSubpictureStreams)
+	for i := range subpictureCount {
+		a := data[vtsSubpictureAttrs+i*6:]
+		sp := subpictureAttributes{extension: a[5]}
+		if a[0]&0x03 == 1 {
+			sp.language = languageCode(a[2:4])
+		}
+		vts.subpictures = append(vts.subpictures, sp)
+	}
+
+	var err error
+	if vts.ptts, err = parsePTTTable(data); err != nil {
+		return nil, fmt.Errorf("chapter table: %w", err)
+	}
+	if vts.pgcs, err = parsePGCTable(data); err != nil {
+		return nil, fmt.Errorf("program chain table: %w", err)
+	}
+	return vts, nil
+}
+
+func parseVideoAttributes(a []byte) videoAttributes {
+	return videoAttributes{
+		mpeg2:       a[0]>>6 == 1,
+		pal:         (a[0]>>4)&0x03 == 1,
+		wide:        (a[0]>>2)&0x03 == 3,
+		pictureSize: (a[1] >> 3) & 0x03,
+		letterboxed: a[1]&0x04 != 0,
+	}
+}
+
+func parseAudioAttributes(a []byte) audioAttributes {
+	attrs := audioAttributes{
+		coding:     a[0] >> 5,
+		quantDRC:   a[1] >> 6,
+		sampleRate: (a[1] >> 4) & 0x03,
+		channels:   int(a[1]&0x07) + 1,
+		extension:  a[5],
+	}
+	if (a[0]>>2)&0x03 == 1 {
+		attrs.language = languageCode(a[2:4])
+	}
+	return attrs
+}
+
+// parsePTTTable reads VTS_PTT_SRPT: the chapters of each title of the set.
+func parsePTTTable(data []byte) ([][]ptt, error) {
+	off, err := tableOffset(data, vtsPTTTableSector)
+	if err != nil {
+		return nil, err
+	}
+	count := int(binary.BigEndian.Uint16(data[off:]))
+	end := off + int(binary.BigEndian.Uint32(data[off+4:])) + 1
+	if end > len(data) || off+8+count*4 > end {
+		return nil, errors.New("table out of range")
+	}
+	titles := make([][]ptt, count)
+	for i := range count {
+		start := off + int(binary.BigEndian.Uint32(data[off+8+i*4:]))
+		stop := end
+		if i+1 < count {
+			stop = off + int(binary.BigEndian.Uint32(data[off+8+(i+1)*4:]))
+		}
+		if start < off+8 || stop > end || start > stop {
+			return nil, fmt.Errorf("title %d out of range", i+1)
+		}
+		for p := start; p+4 <= stop; p += 4 {
+			titles[i] = append(titles[i], ptt{
+				pgcn: int(binary.BigEndian.Uint16(data[p:])),
+				pgn:  int(binary.BigEndian.Uint16(data[p+2:])),
+			})
+		}
+	}
+	return titles, nil
+}
+
+// parsePGCTable reads VTS_PGCIT: the program chains of the set.
+func parsePGCTable(data []byte) ([]pgc, error) {
+	off, err := tableOffset(data, vtsPGCTableSector)
+	if err != nil {
+		return nil, err
+	}
+	count := int(binary.BigEndian.Uint16(data[off:]))
+	if off+8+count*8 > len(data) {
+		return nil, errors.New("table out of range")
+	}
+	pgcs := make([]pgc, 0, count)
+	for i := range count {
+		start := off + int(binary.BigEndian.Uint32(data[off+8+i*8+4:]))
+		chain, err := parsePGC(data, start)
+		if err != nil {
+			return nil, fmt.Errorf("program chain %d: %w", i+1, err)
+		}
+		pgcs = append(pgcs, chain)
+	}
+	return pgcs, nil
+}
+
+// parsePGC reads the program chain at off.
+func parsePGC(data []byte, off int) (pgc, error) {
+	if off+0xEC > len(data) {
+		return pgc{}, errors.New("out of range")
+	}
+	var chain pgc
+	programs := int(data[off+2])
+	cells := int(data[off+3])
+	for i := range maxAudioStreams {
+		chain.audioControl[i] = binary.BigEndian.Uint16(data[off+0x0C+i*2:])
+	}
+	for i := range maxSubpictureStreams {
+		chain.subpictureControl[i] = binary.BigEndian.Uint32(data[off+0x1C+i*4:])
+	}
+
+	programMap := off + int(binary.BigEndian.Uint16(data[off+0xE6:]))
+	if programs > 0 && programMap+programs > len(data) {
+		return pgc{}, errors.New("program map out of range")
+	}
+	for i := range programs {
+		chain.programs = append(chain.programs, int(data[programMap+i]))
+	}
+	playback := off + int(binary.BigEndian.Uint16(data[off+0xE8:]))
+	if cells > 0 && playback+cells*24 > len(data) {
+		return pgc{}, errors.New("cell playback table out of range")
+	}
+	for i := range cells {
+		c := data[playback+i*24:]
+		chain.cells = append(chain.cells, cell{
+			seconds:     playbackTime(c[4:8]),
+			firstSector: binary.BigEndian.Uint32(c[8:]),
+			lastSector:  binary.BigEndian.Uint32(c[20:]),
+			blockMode:   c[0] >> 6,
+			blockType:   (c[0] >> 4) & 0x03,
+		})
+	}
+	return chain, nil
+}
+
+// tableOffset returns the byte offset of the table whose start sector the
+// header holds at field, checking that its 8-byte header is in data.
+func tableOffset(data []byte, field int) (int, error) {
+	off := int(binary.BigEndian.Uint32(data[field:])) * SectorSize
+	if off == 0 || off+8 > len(data) {
+		return 0, errors.New("table out of range")
+	}
+	return off, nil
+}
+
+// playbackTime decodes a BCD hh:mm:ss:ff playback time; the top two bits of
+// the frame byte give the frame rate (1: 25 fps, 3: 29.97 fps).
+func playbackTime(b []byte) float64 {
+	bcd := func(v byte) int { return int(v>>4)*10 + int(v&0x0F) }
+	seconds := float64(bcd(b[0])*3600 + bcd(b[1])*60 + bcd(b[2]))
+	switch b[3] >> 6 {
+	case 1:
+		seconds += float64(bcd(b[3]&0x3F)) / 25
+	case 3:
+		seconds += float64(bcd(b[3]&0x3F)) * 1001 / 30000
+	}
+	return seconds
+}
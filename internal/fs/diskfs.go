@@ -109,7 +109,7 @@ func (d *diskDirectoryInfo) GetFiles() ([]FileInfo, error) {
 
 	var files []FileInfo
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || IsMacOSNoise(entry.Name(), false) {
 			continue
 		}
 		files = append(files, &diskFileInfo{
@@ -133,7 +133,7 @@ func (d *diskDirectoryInfo) GetDirectories() ([]DirectoryInfo, error) {
 
 	var dirs []DirectoryInfo
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() && !IsMacOSNoise(entry.Name(), true) {
 			dirs = append(dirs, &diskDirectoryInfo{
 				path: filepath.Join(d.path, entry.Name()),
 			})
@@ -155,7 +155,7 @@ func (d *diskDirectoryInfo) GetFilesPattern(pattern string) ([]FileInfo, error)
 
 	var files []FileInfo
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || IsMacOSNoise(entry.Name(), false) {
 			continue
 		}
 		matched, err := filepath.Match(pattern, entry.Name())
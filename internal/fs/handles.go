@@ -0,0 +1,86 @@
+package fs
+
+import (
+	"io"
+	"sync"
+)
+
+// HandleBudget caps the files open at once across every reader using it, for
+// hosts with a low file descriptor limit. Opens beyond the cap wait until
+// another file is closed, so a caller must never hold one file open while
+// opening a second.
+type HandleBudget struct {
+	slots chan struct{}
+
+	mu   sync.Mutex
+	open int
+	peak int
+}
+
+// NewHandleBudget returns a budget of n open files, or nil (unlimited) when n
+// is not positive.
+func NewHandleBudget(n int) *HandleBudget {
+	if n <= 0 {
+		return nil
+	}
+	return &HandleBudget{slots: make(chan struct{}, n)}
+}
+
+// Open returns how many files are open through the budget right now.
+func (b *HandleBudget) Open() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// Peak returns the most files that were open through the budget at once.
+func (b *HandleBudget) Peak() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.peak
+}
+
+func (b *HandleBudget) acquire() {
+	b.slots <- struct{}{}
+	b.mu.Lock()
+	b.open++
+	b.peak = max(b.peak, b.open)
+	b.mu.Unlock()
+}
+
+func (b *HandleBudget) release() {
+	b.mu.Lock()
+	b.open--
+	b.mu.Unlock()
+	<-b.slots
+}
+
+// WithHandleBudget wraps fsys so every open file holds a slot of budget until
+// it is closed. It returns fsys unchanged when budget is nil.
+func WithHandleBudget(fsys FileSystem, budget *HandleBudget) FileSystem {
+	if budget == nil {
+		return fsys
+	}
+	return wrapOpens(fsys, func(file FileInfo) (io.ReadCloser, error) {
+		budget.acquire()
+		rc, err := file.OpenRead()
+		if err != nil {
+			budget.release()
+			return nil, err
+		}
+		return &budgetedReader{ReadCloser: rc, budget: budget}, nil
+	})
+}
+
+// budgetedReader gives its slot back on the first Close.
+type budgetedReader struct {
+	io.ReadCloser
+	budget *HandleBudget
+	once   sync.Once
+}
+
+func (r *budgetedReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.budget.release)
+	return err
+}
@@ -0,0 +1,84 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHandleBudgetBlocksUntilClose(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "00001.m2ts"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	budget := NewHandleBudget(2)
+	fsys := WithHandleBudget(NewDiskFileSystem(), budget)
+	file, err := fsys.GetFileInfo(filepath.Join(dir, "00001.m2ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := file.OpenRead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := file.OpenRead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened := make(chan struct{})
+	go func() {
+		third, err := file.OpenRead()
+		if err == nil {
+			third.Close()
+		}
+		close(opened)
+	}()
+	select {
+	case <-opened:
+		t.Fatal("third open did not wait for a free handle")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first.Close()
+	first.Close() // a second Close must not free another slot
+	<-opened
+	second.Close()
+	if budget.Open() != 0 || budget.Peak() != 2 {
+		t.Fatalf("open=%d peak=%d, want 0 and 2", budget.Open(), budget.Peak())
+	}
+}
+
+func TestHandleBudgetFailedOpenFreesSlot(t *testing.T) {
+	budget := NewHandleBudget(1)
+	fsys := WithHandleBudget(NewDiskFileSystem(), budget)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "00001.m2ts")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := fsys.GetFileInfo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.OpenRead(); err == nil {
+		t.Fatal("open of a removed file succeeded")
+	}
+	if budget.Open() != 0 {
+		t.Fatalf("failed open kept a slot: open=%d", budget.Open())
+	}
+}
+
+func TestNewHandleBudgetUnlimited(t *testing.T) {
+	if NewHandleBudget(0) != nil {
+		t.Fatal("zero budget should be unlimited")
+	}
+	fsys := NewDiskFileSystem()
+	if WithHandleBudget(fsys, nil) != fsys {
+		t.Fatal("nil budget should not wrap the file system")
+	}
+}
@@ -0,0 +1,200 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// httpBlockSize is the unit small reads are fetched and cached in, so the
+	// many sector-sized UDF metadata reads share a few round trips.
+	httpBlockSize = 256 * 1024
+	// httpCachedBlocks bounds the block cache.
+	httpCachedBlocks = 16
+	// httpTimeout bounds each request, body included, so a stalled server
+	// fails the read instead of hanging the scan.
+	httpTimeout = 2 * time.Minute
+)
+
+// httpClient is shared by the readers; unlike http.DefaultClient it gives up
+// on a stalled server.
+var httpClient = &http.Client{Timeout: httpTimeout}
+
+// IsRemoteURL reports whether path is an http:// or https:// URL.
+func IsRemoteURL(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// RedactURL returns rawURL with any password replaced by "xxxxx", for
+// reports and error messages.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// HTTPReaderAt reads a remote image with HTTP range requests. Reads smaller
+// than a block go through a small cache of aligned blocks; larger reads, such
+// as stream file scans, are fetched as they are asked for.
+type HTTPReaderAt struct {
+	ctx    context.Context
+	client *http.Client
+	url    string
+	size   int64
+
+	mu     sync.Mutex
+	blocks map[int64][]byte
+	order  []int64
+}
+
+// OpenHTTP checks that the server behind rawURL answers range requests and
+// learns the image size. Credentials in the URL are sent as basic auth. ctx
+// bounds every request of the reader, later reads included.
+func OpenHTTP(ctx context.Context, rawURL string) (*HTTPReaderAt, error) {
+	r := &HTTPReaderAt{ctx: ctx, client: httpClient, url: rawURL, blocks: make(map[int64][]byte)}
+	resp, err := r.get(0, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	size, err := strconv.ParseInt(total, 10, 64)
+	if !ok || err != nil || size <= 0 {
+		return nil, fmt.Errorf("%s: server did not report the image size", RedactURL(rawURL))
+	}
+	r.size = size
+	return r, nil
+}
+
+// Size returns the image size in bytes.
+func (r *HTTPReaderAt) Size() int64 {
+	return r.size
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *HTTPReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, iofs.ErrInvalid
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	want := len(p)
+	if rest := r.size - off; int64(want) > rest {
+		p = p[:rest]
+	}
+
+	var n int
+	var err error
+	if len(p) >= httpBlockSize {
+		n, err = r.fetch(p, off)
+	} else {
+		n, err = r.readCached(p, off)
+	}
+	if err == nil && n < want {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (r *HTTPReaderAt) readCached(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		start := (off + int64(n)) / httpBlockSize * httpBlockSize
+		block, err := r.block(start)
+		if err != nil {
+			return n, err
+		}
+		n += copy(p[n:], block[off+int64(n)-start:])
+	}
+	return n, nil
+}
+
+// block returns the cached block at start, fetching it when missing.
+func (r *HTTPReaderAt) block(start int64) ([]byte, error) {
+	r.mu.Lock()
+	block, ok := r.blocks[start]
+	r.mu.Unlock()
+	if ok {
+		return block, nil
+	}
+
+	block = make([]byte, min(httpBlockSize, r.size-start))
+	if _, err := r.fetch(block, start); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.blocks[start]; !ok {
+		if len(r.order) >= httpCachedBlocks {
+			delete(r.blocks, r.order[0])
+			r.order = r.order[1:]
+		}
+		r.blocks[start] = block
+		r.order = append(r.order, start)
+	}
+	return block, nil
+}
+
+// fetch reads len(p) bytes at off with one range request.
+func (r *HTTPReaderAt) fetch(p []byte, off int64) (int, error) {
+	resp, err := r.get(off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	n, err := io.ReadFull(resp.Body, p)
+	if err != nil {
+		return n, fmt.Errorf("%s: read at %d: %w", RedactURL(r.url), off, err)
+	}
+	return n, nil
+}
+
+// get requests length bytes at off and insists on a partial content answer;
+// a server that ignores the range would send the whole image.
+func (r *HTTPReaderAt) get(off, length int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", RedactURL(r.url), err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+length-1))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		// The client error repeats the URL; keep it redacted.
+		if uerr, ok := err.(*url.Error); ok {
+			uerr.URL = RedactURL(uerr.URL)
+		}
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	name := RedactURL(r.url)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil, fmt.Errorf("%s: server does not support range requests", name)
+	case http.StatusNotFound, http.StatusGone:
+		return nil, &iofs.PathError{Op: "open", Path: name, Err: iofs.ErrNotExist}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, &iofs.PathError{Op: "open", Path: name, Err: iofs.ErrPermission}
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil, io.ErrUnexpectedEOF
+	}
+	return nil, fmt.Errorf("%s: %s", name, resp.Status)
+}
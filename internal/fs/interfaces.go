@@ -1,7 +1,9 @@
 package fs
 
 import (
+	"context"
 	"io"
+	"os"
 	"time"
 )
 
@@ -78,6 +80,13 @@ type ISOFileSystem interface {
 	// Mount opens the ISO file and prepares it for reading.
 	Mount(isoPath string) error
 
+	// MountURL opens an ISO served over HTTP(S) with range requests.
+	MountURL(ctx context.Context, rawURL string) error
+
+	// MountStdin opens an ISO read from stdin, spooling a pipe to a file from
+	// createSpill.
+	MountStdin(createSpill func() (*os.File, error)) error
+
 	// Unmount closes the ISO file.
 	Unmount() error
 
@@ -0,0 +1,385 @@
+// Package iso9660 reads ISO 9660 images, with Joliet names when the image has
+// them. It is the fallback for images without a UDF file system.
+package iso9660
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	// SectorSize is the logical block size of every image this package reads.
+	SectorSize = 2048
+
+	// Volume descriptors start at sector 16.
+	descriptorStart = 16
+	maxDescriptors  = 64
+
+	descriptorPrimary       = 1
+	descriptorSupplementary = 2
+	descriptorTerminator    = 255
+
+	flagDirectory   = 0x02
+	flagMultiExtent = 0x80
+
+	// maxDirectorySize bounds how much of a directory extent is read.
+	maxDirectorySize = 16 << 20
+)
+
+// Reader reads the file tree of an ISO 9660 image.
+type Reader struct {
+	src         io.ReaderAt
+	size        int64
+	closer      io.Closer
+	joliet      bool
+	volumeLabel string
+	root        record
+}
+
+// record is a parsed directory record. Files stored in several extents (over
+// 4 GiB) carry one extent per record of the same name.
+type record struct {
+	name    string
+	dir     bool
+	extents []extent
+	size    int64
+	modTime time.Time
+}
+
+type extent struct {
+	offset int64
+	length int64
+}
+
+// File is a file of the image.
+type File struct {
+	reader *Reader
+	Name   string
+	rec    record
+}
+
+// Directory is a directory of the image.
+type Directory struct {
+	reader *Reader
+	Name   string
+	path   string
+	rec    record
+}
+
+// NewReader opens the image at path.
+func NewReader(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ISO file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open ISO file: %w", err)
+	}
+	r, err := NewReaderFrom(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.closer = file
+	return r, nil
+}
+
+// NewReaderFrom reads an image of size bytes through src, which the caller
+// keeps open until it is done with the Reader and its files.
+func NewReaderFrom(src io.ReaderAt, size int64) (*Reader, error) {
+	r := &Reader{src: src, size: size}
+	if err := r.readDescriptors(); err != nil {
+		return nil, fmt.Errorf("not a valid ISO 9660 volume: %w", err)
+	}
+	return r, nil
+}
+
+// Close closes the image when the Reader opened it.
+func (r *Reader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// GetVolumeLabel returns the volume identifier.
+func (r *Reader) GetVolumeLabel() string {
+	return r.volumeLabel
+}
+
+// Joliet reports whether names come from the Joliet directory tree.
+func (r *Reader) Joliet() bool {
+	return r.joliet
+}
+
+// readDescriptors picks the Joliet supplementary descriptor when there is one
+// and the primary descriptor otherwise.
+func (r *Reader) readDescriptors() error {
+	var primary, joliet []byte
+	sector := make([]byte, SectorSize)
+scan:
+	for i := range maxDescriptors {
+		if _, err := r.src.ReadAt(sector, int64(descriptorStart+i)*SectorSize); err != nil {
+			return err
+		}
+		if string(sector[1:6]) != "CD001" {
+			return errors.New("CD001 identifier not found")
+		}
+		switch sector[0] {
+		case descriptorPrimary:
+			if primary == nil {
+				primary = append([]byte(nil), sector...)
+			}
+		case descriptorSupplementary:
+			if joliet == nil && isJolietEscape(sector[88:120]) {
+				joliet = append([]byte(nil), sector...)
+			}
+		case descriptorTerminator:
+			break scan
+		}
+	}
+	if primary == nil {
+		return errors.New("primary volume descriptor not found")
+	}
+
+	desc := primary
+	if joliet != nil {
+		desc, r.joliet = joliet, true
+	}
+	r.volumeLabel = r.decodeName(desc[40:72])
+	root, ok := r.parseRecord(desc[156:190])
+	if !ok || !root.dir {
+		return errors.New("invalid root directory record")
+	}
+	r.root = root
+	return nil
+}
+
+// isJolietEscape reports whether the escape sequences of a supplementary
+// descriptor name a Joliet UCS-2 level.
+func isJolietEscape(escapes []byte) bool {
+	for i := 0; i+2 < len(escapes); i++ {
+		if escapes[i] == '%' && escapes[i+1] == '/' {
+			switch escapes[i+2] {
+			case '@', 'C', 'E':
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseRecord parses the directory record at the start of data.
+func (r *Reader) parseRecord(data []byte) (record, bool) {
+	if len(data) < 34 || int(data[0]) > len(data) || data[0] < 34 {
+		return record{}, false
+	}
+	nameLen := int(data[32])
+	if 33+nameLen > int(data[0]) {
+		return record{}, false
+	}
+	rec := record{
+		dir:     data[25]&flagDirectory != 0,
+		size:    int64(binary.LittleEndian.Uint32(data[10:14])),
+		modTime: recordingTime(data[18:25]),
+	}
+	rec.extents = []extent{{
+		offset: int64(binary.LittleEndian.Uint32(data[2:6])) * SectorSize,
+		length: rec.size,
+	}}
+	name := data[33 : 33+nameLen]
+	switch {
+	case nameLen == 1 && name[0] <= 1:
+		// "." and "..".
+	default:
+		rec.name = r.decodeName(name)
+		if !rec.dir {
+			rec.name = trimVersion(rec.name)
+		}
+	}
+	return rec, true
+}
+
+// decodeName decodes an identifier: UCS-2 big-endian under Joliet, ASCII
+// otherwise, with the padding removed.
+func (r *Reader) decodeName(data []byte) string {
+	if !r.joliet {
+		return strings.TrimRight(string(data), " \x00")
+	}
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, binary.BigEndian.Uint16(data[i:]))
+	}
+	return strings.TrimRight(string(utf16.Decode(units)), " \x00")
+}
+
+// trimVersion drops the ";1" file version and the dot of a name without
+// extension.
+func trimVersion(name string) string {
+	if i := strings.LastIndexByte(name, ';'); i >= 0 {
+		name = name[:i]
+	}
+	return strings.TrimSuffix(name, ".")
+}
+
+// recordingTime decodes the 7-byte directory record date.
+func recordingTime(b []byte) time.Time {
+	if b[0] == 0 && b[1] == 0 {
+		return time.Time{}
+	}
+	zone := time.FixedZone("", int(int8(b[6]))*15*60)
+	return time.Date(1900+int(b[0]), time.Month(b[1]), int(b[2]), int(b[3]), int(b[4]), int(b[5]), 0, zone)
+}
+
+// readRecords lists the entries of the directory dir, joining the records of
+// multi-extent files.
+func (r *Reader) readRecords(dir record) ([]record, error) {
+	if dir.size > maxDirectorySize {
+		return nil, fmt.Errorf("directory of %d bytes is too large", dir.size)
+	}
+	data := make([]byte, dir.size)
+	if _, err := r.src.ReadAt(data, dir.extents[0].offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	var out []record
+	continued := false
+	for pos := 0; pos < len(data); {
+		if data[pos] == 0 {
+			// Records do not cross sectors; the rest of this one is padding.
+			pos = (pos/SectorSize + 1) * SectorSize
+			continue
+		}
+		rec, ok := r.parseRecord(data[pos:])
+		if !ok {
+			return nil, fmt.Errorf("invalid directory record at offset %d", dir.extents[0].offset+int64(pos))
+		}
+		multi := data[pos+25]&flagMultiExtent != 0
+		pos += int(data[pos])
+		if rec.name == "" {
+			continue
+		}
+		if continued && len(out) > 0 && out[len(out)-1].name == rec.name {
+			last := &out[len(out)-1]
+			last.extents = append(last.extents, rec.extents...)
+			last.size += rec.size
+		} else {
+			out = append(out, rec)
+		}
+		continued = multi
+	}
+	return out, nil
+}
+
+// ReadDirectory returns the directory at dirPath, "/" being the root.
+func (r *Reader) ReadDirectory(dirPath string) (*Directory, error) {
+	rec, err := r.lookup(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if !rec.dir {
+		return nil, fmt.Errorf("not a directory: %s", dirPath)
+	}
+	return &Directory{reader: r, Name: rec.name, path: path.Clean("/" + dirPath), rec: rec}, nil
+}
+
+// FindFile returns the file at filePath.
+func (r *Reader) FindFile(filePath string) (*File, error) {
+	rec, err := r.lookup(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if rec.dir {
+		return nil, fmt.Errorf("is a directory: %s", filePath)
+	}
+	return &File{reader: r, Name: rec.name, rec: rec}, nil
+}
+
+// lookup walks p from the root, matching names without regard to case.
+func (r *Reader) lookup(p string) (record, error) {
+	current := r.root
+	for _, part := range strings.Split(strings.Trim(path.Clean("/"+p), "/"), "/") {
+		if part == "" {
+			continue
+		}
+		entries, err := r.readRecords(current)
+		if err != nil {
+			return record{}, err
+		}
+		found := false
+		for _, entry := range entries {
+			if strings.EqualFold(entry.name, part) {
+				current, found = entry, true
+				break
+			}
+		}
+		if !found {
+			return record{}, fmt.Errorf("%s: %w", p, os.ErrNotExist)
+		}
+	}
+	return current, nil
+}
+
+// GetFiles returns the files of the directory.
+func (d *Directory) GetFiles() ([]*File, error) {
+	entries, err := d.reader.readRecords(d.rec)
+	if err != nil {
+		return nil, err
+	}
+	var files []*File
+	for _, entry := range entries {
+		if !entry.dir {
+			files = append(files, &File{reader: d.reader, Name: entry.name, rec: entry})
+		}
+	}
+	return files, nil
+}
+
+// GetDirectories returns the subdirectories of the directory.
+func (d *Directory) GetDirectories() ([]*Directory, error) {
+	entries, err := d.reader.readRecords(d.rec)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []*Directory
+	for _, entry := range entries {
+		if entry.dir {
+			dirs = append(dirs, &Directory{reader: d.reader, Name: entry.name, path: path.Join(d.path, entry.name), rec: entry})
+		}
+	}
+	return dirs, nil
+}
+
+// Size returns the file size in bytes.
+func (f *File) Size() int64 {
+	return f.rec.size
+}
+
+// ModTime returns the recording time of the file.
+func (f *File) ModTime() time.Time {
+	return f.rec.modTime
+}
+
+// Open opens the file for reading.
+func (f *File) Open() (io.ReadCloser, error) {
+	readers := make([]io.Reader, 0, len(f.rec.extents))
+	for _, ext := range f.rec.extents {
+		if ext.offset+ext.length > f.reader.size {
+			return nil, fmt.Errorf("%s: extent beyond the end of the image", f.Name)
+		}
+		readers = append(readers, io.NewSectionReader(f.reader.src, ext.offset, ext.length))
+	}
+	if len(readers) == 1 {
+		return io.NopCloser(readers[0]), nil
+	}
+	return io.NopCloser(io.MultiReader(readers...)), nil
+}
@@ -0,0 +1,177 @@
+package iso9660
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// testEntry is a directory record of a test image.
+type testEntry struct {
+	name  []byte
+	lba   uint32
+	size  uint32
+	flags byte
+}
+
+func testRecord(e testEntry) []byte {
+	n := 33 + len(e.name)
+	if n%2 == 1 {
+		n++
+	}
+	rec := make([]byte, n)
+	rec[0] = byte(n)
+	binary.LittleEndian.PutUint32(rec[2:], e.lba)
+	binary.BigEndian.PutUint32(rec[6:], e.lba)
+	binary.LittleEndian.PutUint32(rec[10:], e.size)
+	binary.BigEndian.PutUint32(rec[14:], e.size)
+	copy(rec[18:25], []byte{124, 6, 1, 12, 30, 0, 0}) // 2024-06-01 12:30:00 UTC
+	rec[25] = e.flags
+	rec[32] = byte(len(e.name))
+	copy(rec[33:], e.name)
+	return rec
+}
+
+func ucs2(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.BigEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+func testDescriptor(kind byte, label []byte, root []byte, joliet bool) []byte {
+	d := make([]byte, SectorSize)
+	d[0] = kind
+	copy(d[1:6], "CD001")
+	d[6] = 1
+	copy(d[40:72], bytes.Repeat([]byte{' '}, 32))
+	if joliet {
+		copy(d[40:72], ucs2(strings.Repeat(" ", 16)))
+		copy(d[88:91], "%/E")
+	}
+	copy(d[40:72], label)
+	copy(d[156:190], root)
+	return d
+}
+
+// testImage lays out an image with a primary tree in upper case and a Joliet
+// tree in mixed case. Each tree has BDMV/index.bdmv and a two-extent
+// BDMV/STREAM.M2TS whose extents are not adjacent.
+func testImage(t *testing.T, withJoliet bool) []byte {
+	t.Helper()
+	image := make([]byte, 40*SectorSize)
+	put := func(sector int, data []byte) { copy(image[sector*SectorSize:], data) }
+
+	put(30, []byte("INDX"))
+	put(32, bytes.Repeat([]byte{'A'}, SectorSize))
+	put(34, []byte("BB"))
+
+	dir := func(self, parent uint32, entries ...testEntry) []byte {
+		var b []byte
+		b = append(b, testRecord(testEntry{name: []byte{0}, lba: self, size: SectorSize, flags: flagDirectory})...)
+		b = append(b, testRecord(testEntry{name: []byte{1}, lba: parent, size: SectorSize, flags: flagDirectory})...)
+		for _, e := range entries {
+			b = append(b, testRecord(e)...)
+		}
+		return b
+	}
+	name := func(joliet bool, primary, long string) []byte {
+		if joliet {
+			return ucs2(long)
+		}
+		return []byte(primary)
+	}
+	for _, joliet := range []bool{false, true} {
+		root, bdmv := uint32(20), uint32(21)
+		if joliet {
+			root, bdmv = 22, 23
+		}
+		put(int(root), dir(root, root,
+			testEntry{name: name(joliet, "BDMV", "BDMV"), lba: bdmv, size: SectorSize, flags: flagDirectory}))
+		put(int(bdmv), dir(bdmv, root,
+			testEntry{name: name(joliet, "INDEX.BDM;1", "index.bdmv"), lba: 30, size: 4},
+			testEntry{name: name(joliet, "STREAM.M2T;1", "Stream.m2ts"), lba: 32, size: SectorSize, flags: flagMultiExtent},
+			testEntry{name: name(joliet, "STREAM.M2T;1", "Stream.m2ts"), lba: 34, size: 2}))
+	}
+
+	rootRecord := func(lba uint32) []byte {
+		return testRecord(testEntry{name: []byte{0}, lba: lba, size: SectorSize, flags: flagDirectory})[:34]
+	}
+	put(16, testDescriptor(descriptorPrimary, []byte("MOVIE_DISC"), rootRecord(20), false))
+	next := 17
+	if withJoliet {
+		put(next, testDescriptor(descriptorSupplementary, ucs2("Movie Disc"), rootRecord(22), true))
+		next++
+	}
+	put(next, testDescriptor(descriptorTerminator, nil, nil, false))
+	return image
+}
+
+func TestReader(t *testing.T) {
+	for _, tc := range []struct {
+		joliet       bool
+		label        string
+		index, video string
+	}{
+		{false, "MOVIE_DISC", "INDEX.BDM", "STREAM.M2T"},
+		{true, "Movie Disc", "index.bdmv", "Stream.m2ts"},
+	} {
+		image := testImage(t, tc.joliet)
+		r, err := NewReaderFrom(bytes.NewReader(image), int64(len(image)))
+		if err != nil {
+			t.Fatalf("joliet=%t: NewReaderFrom() error: %v", tc.joliet, err)
+		}
+		if r.Joliet() != tc.joliet || r.GetVolumeLabel() != tc.label {
+			t.Fatalf("joliet=%t: got joliet=%t label=%q", tc.joliet, r.Joliet(), r.GetVolumeLabel())
+		}
+
+		root, err := r.ReadDirectory("/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dirs, err := root.GetDirectories()
+		if err != nil || len(dirs) != 1 || dirs[0].Name != "BDMV" {
+			t.Fatalf("joliet=%t: root directories %+v, %v", tc.joliet, dirs, err)
+		}
+		files, err := dirs[0].GetFiles()
+		if err != nil || len(files) != 2 || files[0].Name != tc.index || files[1].Name != tc.video {
+			t.Fatalf("joliet=%t: BDMV files %+v, %v", tc.joliet, files, err)
+		}
+		if got := files[0].ModTime(); got.Year() != 2024 || got.Month() != 6 || got.Hour() != 12 {
+			t.Fatalf("joliet=%t: ModTime() = %v", tc.joliet, got)
+		}
+
+		video, err := r.FindFile("/bdmv/" + tc.video)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if video.Size() != SectorSize+2 {
+			t.Fatalf("joliet=%t: multi-extent size %d", tc.joliet, video.Size())
+		}
+		rc, err := video.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := append(bytes.Repeat([]byte{'A'}, SectorSize), 'B', 'B'); !bytes.Equal(data, want) {
+			t.Fatalf("joliet=%t: multi-extent file read %d bytes", tc.joliet, len(data))
+		}
+		if _, err := r.FindFile("/BDMV/MISSING"); err == nil {
+			t.Fatalf("joliet=%t: missing file found", tc.joliet)
+		}
+	}
+}
+
+func TestNewReaderFrom_RejectsNonISO9660(t *testing.T) {
+	image := bytes.NewReader(make([]byte, 40*SectorSize))
+	if _, err := NewReaderFrom(image, image.Size()); err == nil {
+		t.Fatal("NewReaderFrom accepted an image without volume descriptors")
+	}
+}
@@ -0,0 +1,151 @@
+package fs
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/autobrr/go-bdinfo/internal/fs/iso9660"
+)
+
+// iso9660FileInfo implements FileInfo for files of an image mounted through
+// its ISO 9660 tree.
+type iso9660FileInfo struct {
+	fullPath string
+	file     *iso9660.File
+}
+
+// iso9660DirectoryInfo implements DirectoryInfo for directories of an image
+// mounted through its ISO 9660 tree.
+type iso9660DirectoryInfo struct {
+	name     string
+	fullPath string
+	dir      *iso9660.Directory
+}
+
+func (fs *ISOFileSystemImpl) iso9660DirectoryInfo(p string) (DirectoryInfo, error) {
+	dir, err := fs.iso9660.ReadDirectory(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	return &iso9660DirectoryInfo{name: filepath.Base(p), fullPath: p, dir: dir}, nil
+}
+
+func (fs *ISOFileSystemImpl) iso9660FileInfo(p string) (FileInfo, error) {
+	file, err := fs.iso9660.FindFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find file: %w", err)
+	}
+	return &iso9660FileInfo{fullPath: p, file: file}, nil
+}
+
+func (f *iso9660FileInfo) Name() string {
+	return f.file.Name
+}
+
+func (f *iso9660FileInfo) FullName() string {
+	return f.fullPath
+}
+
+func (f *iso9660FileInfo) Length() int64 {
+	return f.file.Size()
+}
+
+func (f *iso9660FileInfo) Extension() string {
+	return path.Ext(f.file.Name)
+}
+
+func (f *iso9660FileInfo) IsDirectory() bool {
+	return false
+}
+
+func (f *iso9660FileInfo) ModTime() time.Time {
+	return f.file.ModTime()
+}
+
+func (f *iso9660FileInfo) OpenRead() (io.ReadCloser, error) {
+	return f.file.Open()
+}
+
+func (d *iso9660DirectoryInfo) Name() string {
+	return d.name
+}
+
+func (d *iso9660DirectoryInfo) FullName() string {
+	return d.fullPath
+}
+
+func (d *iso9660DirectoryInfo) GetFiles() ([]FileInfo, error) {
+	files, err := d.dir.GetFiles()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]FileInfo, 0, len(files))
+	for _, file := range files {
+		out = append(out, &iso9660FileInfo{fullPath: path.Join(d.fullPath, file.Name), file: file})
+	}
+	return out, nil
+}
+
+func (d *iso9660DirectoryInfo) GetDirectories() ([]DirectoryInfo, error) {
+	dirs, err := d.dir.GetDirectories()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DirectoryInfo, 0, len(dirs))
+	for _, dir := range dirs {
+		out = append(out, &iso9660DirectoryInfo{name: dir.Name, fullPath: path.Join(d.fullPath, dir.Name), dir: dir})
+	}
+	return out, nil
+}
+
+func (d *iso9660DirectoryInfo) GetFilesPattern(pattern string) ([]FileInfo, error) {
+	files, err := d.GetFiles()
+	if err != nil {
+		return nil, err
+	}
+	var matches []FileInfo
+	for _, file := range files {
+		matched, err := filepath.Match(pattern, file.Name())
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			matches = append(matches, file)
+		}
+	}
+	return matches, nil
+}
+
+func (d *iso9660DirectoryInfo) GetDirectory(name string) (DirectoryInfo, error) {
+	dirs, err := d.GetDirectories()
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if strings.EqualFold(dir.Name(), name) {
+			return dir, nil
+		}
+	}
+	return nil, fmt.Errorf("directory not found: %s", name)
+}
+
+func (d *iso9660DirectoryInfo) GetFile(name string) (FileInfo, error) {
+	files, err := d.GetFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if strings.EqualFold(file.Name(), name) {
+			return file, nil
+		}
+	}
+	return nil, fmt.Errorf("file not found: %s", name)
+}
+
+func (d *iso9660DirectoryInfo) Exists() bool {
+	return d.dir != nil
+}
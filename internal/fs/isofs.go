@@ -1,22 +1,29 @@
 package fs
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/autobrr/go-bdinfo/internal/fs/iso9660"
 	"github.com/autobrr/go-bdinfo/internal/fs/udf"
 )
 
-// ISOFileSystemImpl implements ISOFileSystem for reading ISO files.
+// ISOFileSystemImpl implements ISOFileSystem for reading ISO files. Images
+// without a UDF file system are read through their ISO 9660 (Joliet) tree.
 type ISOFileSystemImpl struct {
 	isoPath     string
 	volumeLabel string
 	mounted     bool
 	udfReader   *udf.Reader
+	iso9660     *iso9660.Reader
+	// source is an image reader the file system owns, closed on Unmount.
+	source io.Closer
 	// Cache for directory lookups
 	dirCache map[string]*udf.Directory
 }
@@ -37,15 +44,83 @@ func (fs *ISOFileSystemImpl) Mount(isoPath string) error {
 	// Open UDF reader
 	reader, err := udf.NewReader(isoPath)
 	if err != nil {
-		return fmt.Errorf("failed to open UDF volume: %w", err)
+		fallback, ferr := iso9660.NewReader(isoPath)
+		if ferr != nil {
+			return fmt.Errorf("failed to open UDF volume: %w (no ISO 9660 volume either)", err)
+		}
+		fs.mountISO9660(fallback, isoPath)
+		return nil
 	}
 
+	fs.mount(reader, isoPath)
+	return nil
+}
+
+// MountReaderAt prepares an image of size bytes that is not a local file, such
+// as a browser File handle, for reading; name stands in for its path. src must
+// stay readable until Unmount.
+func (fs *ISOFileSystemImpl) MountReaderAt(src io.ReaderAt, size int64, name string) error {
+	if fs.mounted {
+		return fmt.Errorf("ISO already mounted")
+	}
+
+	reader, err := udf.NewReaderFrom(src, size)
+	if err != nil {
+		fallback, ferr := iso9660.NewReaderFrom(src, size)
+		if ferr != nil {
+			return fmt.Errorf("failed to open UDF volume: %w (no ISO 9660 volume either)", err)
+		}
+		fs.mountISO9660(fallback, name)
+		return nil
+	}
+
+	fs.mount(reader, name)
+	return nil
+}
+
+// MountURL prepares an image served over http:// or https:// for reading,
+// fetching only the parts a scan reads. The server must answer range requests.
+// Cancelling ctx fails the requests still to come.
+func (fs *ISOFileSystemImpl) MountURL(ctx context.Context, rawURL string) error {
+	src, err := OpenHTTP(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	return fs.MountReaderAt(src, src.Size(), RedactURL(rawURL))
+}
+
+// MountStdin prepares an image read from stdin. Stdin redirected from a file
+// is read in place; a pipe is spooled to a file from createSpill as far as the
+// scan reads, and the spill file is removed on Unmount.
+func (fs *ISOFileSystemImpl) MountStdin(createSpill func() (*os.File, error)) error {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode().IsRegular() {
+		return fs.MountReaderAt(os.Stdin, info.Size(), "stdin")
+	}
+	spill, err := createSpill()
+	if err != nil {
+		return fmt.Errorf("spool stdin: %w", err)
+	}
+	src := NewSpoolReaderAt(os.Stdin, spill)
+	if err := fs.MountReaderAt(src, spoolSize, "stdin"); err != nil {
+		_ = src.Close()
+		return err
+	}
+	fs.source = src
+	return nil
+}
+
+func (fs *ISOFileSystemImpl) mount(reader *udf.Reader, isoPath string) {
 	fs.udfReader = reader
 	fs.isoPath = isoPath
 	fs.volumeLabel = reader.GetVolumeLabel()
 	fs.mounted = true
+}
 
-	return nil
+func (fs *ISOFileSystemImpl) mountISO9660(reader *iso9660.Reader, isoPath string) {
+	fs.iso9660 = reader
+	fs.isoPath = isoPath
+	fs.volumeLabel = reader.GetVolumeLabel()
+	fs.mounted = true
 }
 
 // Unmount closes the ISO file.
@@ -60,6 +135,18 @@ func (fs *ISOFileSystemImpl) Unmount() error {
 		}
 		fs.udfReader = nil
 	}
+	if fs.iso9660 != nil {
+		if err := fs.iso9660.Close(); err != nil {
+			return err
+		}
+		fs.iso9660 = nil
+	}
+	if fs.source != nil {
+		if err := fs.source.Close(); err != nil {
+			return err
+		}
+		fs.source = nil
+	}
 	fs.mounted = false
 	fs.dirCache = make(map[string]*udf.Directory)
 	return nil
@@ -78,6 +165,9 @@ func (fs *ISOFileSystemImpl) GetDirectoryInfo(path string) (DirectoryInfo, error
 
 	// Normalize path
 	path = fs.normalizePath(path)
+	if fs.iso9660 != nil {
+		return fs.iso9660DirectoryInfo(path)
+	}
 
 	// Check cache
 	if dir, exists := fs.dirCache[path]; exists {
@@ -114,6 +204,9 @@ func (fs *ISOFileSystemImpl) GetFileInfo(path string) (FileInfo, error) {
 
 	// Normalize path
 	path = fs.normalizePath(path)
+	if fs.iso9660 != nil {
+		return fs.iso9660FileInfo(path)
+	}
 
 	// Find file in UDF
 	file, err := fs.udfReader.FindFile(path)
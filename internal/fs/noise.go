@@ -0,0 +1,24 @@
+package fs
+
+import "strings"
+
+// macOSVolumeDirs are the metadata directories macOS creates at the root of
+// mounted volumes (e.g. an ISO attached with hdiutil under /Volumes).
+var macOSVolumeDirs = map[string]struct{}{
+	".Spotlight-V100": {},
+	".fseventsd":      {},
+	".Trashes":        {},
+	".TemporaryItems": {},
+}
+
+// IsMacOSNoise reports whether name is macOS metadata rather than disc content:
+// AppleDouble resource-fork files (`._*`), .DS_Store, or volume metadata
+// directories. Such entries are skipped during enumeration so they neither
+// match stream patterns (`._00000.m2ts`) nor count towards the disc size.
+func IsMacOSNoise(name string, isDir bool) bool {
+	if isDir {
+		_, ok := macOSVolumeDirs[name]
+		return ok
+	}
+	return strings.HasPrefix(name, "._") || name == ".DS_Store"
+}
@@ -0,0 +1,55 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskFileSystemSkipsMacOSNoise(t *testing.T) {
+	root := t.TempDir()
+	stream := filepath.Join(root, "BDMV", "STREAM")
+	if err := os.MkdirAll(stream, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".fseventsd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"00000.m2ts", "._00000.m2ts", ".DS_Store"} {
+		if err := os.WriteFile(filepath.Join(stream, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fsys := NewDiskFileSystem()
+	dir, err := fsys.GetDirectoryInfo(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := dir.GetFilesPattern("*.m2ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "00000.m2ts" {
+		t.Fatalf("GetFilesPattern got=%d files want only 00000.m2ts", len(files))
+	}
+	all, err := dir.GetFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("GetFiles got=%d files want 1", len(all))
+	}
+
+	rootDir, err := fsys.GetDirectoryInfo(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs, err := rootDir.GetDirectories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 1 || dirs[0].Name() != "BDMV" {
+		t.Fatalf("GetDirectories got=%d dirs want only BDMV", len(dirs))
+	}
+}
@@ -0,0 +1,10 @@
+package fs
+
+// IsOpticalDrive reports whether path lives on an optical drive (e.g. `D:\` on
+// Windows with a BD inserted). Optical media seek slowly, so callers should
+// read sequentially with a smaller read-ahead instead of scanning in parallel.
+//
+// Detection is only implemented on Windows; other platforms return false.
+func IsOpticalDrive(path string) bool {
+	return isOpticalDrive(path)
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package fs
+
+func isOpticalDrive(string) bool {
+	return false
+}
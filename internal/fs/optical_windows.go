@@ -0,0 +1,37 @@
+//go:build windows
+
+package fs
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const driveCDROM = 5 // DRIVE_CDROM from GetDriveTypeW
+
+var procGetDriveTypeW = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDriveTypeW")
+
+func isOpticalDrive(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	// VolumeName yields "D:" for drive letters and "\\server\share" for UNC paths;
+	// GetDriveTypeW wants the root with a trailing backslash.
+	volume := filepath.VolumeName(abs)
+	if volume == "" {
+		return false
+	}
+	root := strings.TrimSuffix(volume, `\`) + `\`
+	p, err := syscall.UTF16PtrFromString(root)
+	if err != nil {
+		return false
+	}
+	if err := procGetDriveTypeW.Find(); err != nil {
+		return false
+	}
+	kind, _, _ := procGetDriveTypeW.Call(uintptr(unsafe.Pointer(p)))
+	return kind == driveCDROM
+}
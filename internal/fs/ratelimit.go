@@ -0,0 +1,67 @@
+package fs
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces reads to an aggregate byte rate shared by every reader
+// using it, so parallel scan workers together stay under the limit.
+type RateLimiter struct {
+	mu          sync.Mutex
+	bytesPerSec float64
+	next        time.Time
+}
+
+// NewRateLimiter returns a limiter for mbps megabits per second, or nil
+// (unlimited) when mbps is not positive.
+func NewRateLimiter(mbps float64) *RateLimiter {
+	if mbps <= 0 {
+		return nil
+	}
+	return &RateLimiter{bytesPerSec: mbps * 1_000_000 / 8}
+}
+
+// Wait accounts for n bytes just read, sleeping until the limiter's schedule
+// allows them. Idle time is not banked, so reads never burst above the rate.
+func (l *RateLimiter) Wait(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(float64(n) / l.bytesPerSec * float64(time.Second)))
+	wait := l.next.Sub(now)
+	l.mu.Unlock()
+	time.Sleep(wait)
+}
+
+// WithRateLimit wraps fsys so reads of its files are paced by limiter. It
+// returns fsys unchanged when limiter is nil.
+func WithRateLimit(fsys FileSystem, limiter *RateLimiter) FileSystem {
+	if limiter == nil {
+		return fsys
+	}
+	return wrapOpens(fsys, func(file FileInfo) (io.ReadCloser, error) {
+		rc, err := file.OpenRead()
+		if err != nil {
+			return nil, err
+		}
+		return &limitedReader{ReadCloser: rc, limiter: limiter}, nil
+	})
+}
+
+type limitedReader struct {
+	io.ReadCloser
+	limiter *RateLimiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.limiter.Wait(n)
+	return n, err
+}
@@ -0,0 +1,47 @@
+package fs
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterPacesReads(t *testing.T) {
+	// 8 Mbps = 1,000,000 bytes/s: 100,000 bytes after the first chunk take ~90ms.
+	limiter := NewRateLimiter(8)
+	r := &limitedReader{
+		ReadCloser: io.NopCloser(strings.NewReader(strings.Repeat("x", 100_000))),
+		limiter:    limiter,
+	}
+	start := time.Now()
+	buf := make([]byte, 10_000)
+	total := 0
+	for {
+		n, err := r.Read(buf)
+		total += n
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	elapsed := time.Since(start)
+	if total != 100_000 {
+		t.Fatalf("read %d bytes", total)
+	}
+	if elapsed < 80*time.Millisecond {
+		t.Fatalf("100KB at 8 Mbps took %s, want >= ~90ms", elapsed)
+	}
+}
+
+func TestNewRateLimiterUnlimited(t *testing.T) {
+	if NewRateLimiter(0) != nil {
+		t.Fatal("zero rate should be unlimited")
+	}
+	fsys := NewDiskFileSystem()
+	if WithRateLimit(fsys, nil) != fsys {
+		t.Fatal("nil limiter should not wrap the file system")
+	}
+}
@@ -0,0 +1,89 @@
+package fs
+
+import (
+	"errors"
+	"io"
+	iofs "io/fs"
+	"time"
+)
+
+// maxRetryDelay caps the backoff between attempts.
+const maxRetryDelay = 30 * time.Second
+
+// RetryPolicy retries transient I/O errors, waiting Delay before the first retry
+// and doubling the wait after each further failure. The zero value never retries.
+type RetryPolicy struct {
+	Retries int
+	Delay   time.Duration
+}
+
+// Do runs op, retrying it while it fails with a transient error.
+func (p RetryPolicy) Do(op func() error) error {
+	delay := p.Delay
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= p.Retries || !IsTransient(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// IsTransient reports whether err may succeed on retry. Missing files,
+// permission errors and end of file are permanent; anything else (EIO, stale
+// NFS handles, SMB timeouts, ...) is assumed to be a momentary storage hiccup.
+func IsTransient(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, iofs.ErrNotExist),
+		errors.Is(err, iofs.ErrPermission),
+		errors.Is(err, iofs.ErrInvalid),
+		errors.Is(err, iofs.ErrClosed):
+		return false
+	}
+	return true
+}
+
+// WithRetry wraps fsys so file opens and reads follow policy. It returns fsys
+// unchanged when the policy never retries.
+func WithRetry(fsys FileSystem, policy RetryPolicy) FileSystem {
+	if policy.Retries <= 0 {
+		return fsys
+	}
+	return wrapOpens(fsys, func(file FileInfo) (io.ReadCloser, error) {
+		var rc io.ReadCloser
+		err := policy.Do(func() error {
+			var err error
+			rc, err = file.OpenRead()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &retryReader{ReadCloser: rc, policy: policy}, nil
+	})
+}
+
+// retryReader retries failed reads at the current offset; both the disk and
+// UDF readers leave the position untouched when a read returns nothing.
+type retryReader struct {
+	io.ReadCloser
+	policy RetryPolicy
+}
+
+func (r *retryReader) Read(p []byte) (int, error) {
+	var n int
+	err := r.policy.Do(func() error {
+		var err error
+		n, err = r.ReadCloser.Read(p)
+		if n > 0 && IsTransient(err) {
+			// Deliver what was read; the next Read retries the failure.
+			return nil
+		}
+		return err
+	})
+	return n, err
+}
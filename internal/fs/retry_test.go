@@ -0,0 +1,85 @@
+package fs
+
+import (
+	"errors"
+	"io"
+	iofs "io/fs"
+	"strings"
+	"testing"
+)
+
+var errFlaky = errors.New("input/output error")
+
+type flakyReader struct {
+	r     io.Reader
+	fails int
+}
+
+func (f *flakyReader) Read(p []byte) (int, error) {
+	if f.fails > 0 {
+		f.fails--
+		return 0, errFlaky
+	}
+	return f.r.Read(p)
+}
+
+func (f *flakyReader) Close() error { return nil }
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := RetryPolicy{Retries: 2}
+	calls := 0
+	err := policy.Do(func() error {
+		calls++
+		if calls < 3 {
+			return errFlaky
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("transient: err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	err = policy.Do(func() error {
+		calls++
+		return errFlaky
+	})
+	if !errors.Is(err, errFlaky) || calls != 3 {
+		t.Fatalf("exhausted: err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	err = policy.Do(func() error {
+		calls++
+		return iofs.ErrNotExist
+	})
+	if !errors.Is(err, iofs.ErrNotExist) || calls != 1 {
+		t.Fatalf("permanent: err=%v calls=%d", err, calls)
+	}
+}
+
+func TestRetryReader(t *testing.T) {
+	r := &retryReader{
+		ReadCloser: &flakyReader{r: strings.NewReader("payload"), fails: 2},
+		policy:     RetryPolicy{Retries: 2},
+	}
+	data, err := io.ReadAll(r)
+	if err != nil || string(data) != "payload" {
+		t.Fatalf("ReadAll = %q, %v", data, err)
+	}
+
+	r = &retryReader{
+		ReadCloser: &flakyReader{r: strings.NewReader("payload"), fails: 3},
+		policy:     RetryPolicy{Retries: 2},
+	}
+	if _, err := io.ReadAll(r); !errors.Is(err, errFlaky) {
+		t.Fatalf("ReadAll err=%v want %v", err, errFlaky)
+	}
+}
+
+func TestWithRetryZeroPolicy(t *testing.T) {
+	fsys := NewDiskFileSystem()
+	if WithRetry(fsys, RetryPolicy{}) != fsys {
+		t.Fatal("zero policy should not wrap the file system")
+	}
+}
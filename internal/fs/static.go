@@ -0,0 +1,62 @@
+package fs
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrDetached is returned when opening a file whose disc is no longer attached,
+// e.g. one restored from a saved scan.
+var ErrDetached = errors.New("file is not attached to a disc")
+
+// StaticFileInfo is a FileInfo recorded from another one. It keeps the metadata
+// reports need but cannot be opened.
+type StaticFileInfo struct {
+	BaseName string
+	Path     string
+	Size     int64
+	Dir      bool
+	Modified time.Time
+}
+
+// NewStaticFileInfo records the metadata of file.
+func NewStaticFileInfo(file FileInfo) *StaticFileInfo {
+	return &StaticFileInfo{
+		BaseName: file.Name(),
+		Path:     file.FullName(),
+		Size:     file.Length(),
+		Dir:      file.IsDirectory(),
+		Modified: file.ModTime(),
+	}
+}
+
+func (f *StaticFileInfo) Name() string {
+	return f.BaseName
+}
+
+func (f *StaticFileInfo) FullName() string {
+	return f.Path
+}
+
+func (f *StaticFileInfo) Length() int64 {
+	return f.Size
+}
+
+func (f *StaticFileInfo) Extension() string {
+	return strings.ToLower(filepath.Ext(f.BaseName))
+}
+
+func (f *StaticFileInfo) IsDirectory() bool {
+	return f.Dir
+}
+
+func (f *StaticFileInfo) ModTime() time.Time {
+	return f.Modified
+}
+
+func (f *StaticFileInfo) OpenRead() (io.ReadCloser, error) {
+	return nil, ErrDetached
+}
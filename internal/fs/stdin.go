@@ -0,0 +1,88 @@
+package fs
+
+import (
+	"io"
+	iofs "io/fs"
+	"os"
+	"sync"
+
+	"github.com/autobrr/go-bdinfo/internal/fs/udf"
+)
+
+// StdinPath is the disc path that names an image read from standard input.
+const StdinPath = "-"
+
+const (
+	// spoolChunk is the least a SpoolReaderAt copies from its source at a
+	// time, so the many sector-sized UDF metadata reads share a few copies.
+	spoolChunk = 1 << 20
+	// spoolSize stands in for the unknown size of a piped image; reads past
+	// its real end fail with io.EOF, and the UDF reader does not look for
+	// anchors near it.
+	spoolSize = udf.UnknownSize
+)
+
+// SpoolReaderAt gives random access to a stream, such as an image piped to
+// stdin, by copying it to a spill file as far as reads reach. Earlier parts
+// are read back from the spill file, so the spill grows to the furthest
+// offset read, not to the whole stream.
+type SpoolReaderAt struct {
+	src   io.Reader
+	spill *os.File
+
+	mu      sync.Mutex
+	spooled int64
+	err     error // first error reading src, io.EOF at its end
+}
+
+// NewSpoolReaderAt spools src to spill, an empty file that Close removes.
+func NewSpoolReaderAt(src io.Reader, spill *os.File) *SpoolReaderAt {
+	return &SpoolReaderAt{src: src, spill: spill}
+}
+
+// ReadAt implements io.ReaderAt.
+func (s *SpoolReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, iofs.ErrInvalid
+	}
+	s.mu.Lock()
+	s.fill(off + int64(len(p)))
+	spooled, srcErr := s.spooled, s.err
+	s.mu.Unlock()
+
+	want := len(p)
+	if off >= spooled {
+		p = p[:0]
+	} else if rest := spooled - off; int64(want) > rest {
+		p = p[:rest]
+	}
+	n, err := s.spill.ReadAt(p, off)
+	if err != nil || n == want {
+		return n, err
+	}
+	if srcErr == nil {
+		srcErr = io.EOF
+	}
+	return n, srcErr
+}
+
+// fill copies src to the spill file until end bytes are spooled or src fails.
+func (s *SpoolReaderAt) fill(end int64) {
+	if s.spooled >= end || s.err != nil {
+		return
+	}
+	n, err := io.CopyN(io.NewOffsetWriter(s.spill, s.spooled), s.src, max(end-s.spooled, spoolChunk))
+	s.spooled += n
+	if err != nil {
+		s.err = err
+	}
+}
+
+// Close closes and removes the spill file.
+func (s *SpoolReaderAt) Close() error {
+	err := s.spill.Close()
+	if rerr := os.Remove(s.spill.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
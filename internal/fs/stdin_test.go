@@ -0,0 +1,52 @@
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestSpoolReaderAt(t *testing.T) {
+	data := make([]byte, 3*spoolChunk+100)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	spill, err := os.CreateTemp(t.TempDir(), "spool-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A pipe only reads forward; hide the bytes.Reader's ReadAt.
+	r := NewSpoolReaderAt(io.MultiReader(bytes.NewReader(data)), spill)
+
+	// A read ahead spools only to its end; reading
+	// back before it comes from the spill file.
+	buf := make([]byte, 4096)
+	off := int64(spoolChunk + 10)
+	if n, err := r.ReadAt(buf, off); n != len(buf) || err != nil || !bytes.Equal(buf, data[off:off+4096]) {
+		t.Fatalf("ReadAt() ahead = %d, %v", n, err)
+	}
+	if info, _ := spill.Stat(); info.Size() != spoolChunk+4096+10 {
+		t.Fatalf("spooled %d bytes, want %d", info.Size(), spoolChunk+4096+10)
+	}
+	if n, err := r.ReadAt(buf, 100); n != len(buf) || err != nil || !bytes.Equal(buf, data[100:100+4096]) {
+		t.Fatalf("ReadAt() back = %d, %v", n, err)
+	}
+
+	tail := make([]byte, 200)
+	n, err := r.ReadAt(tail, int64(len(data)-50))
+	if n != 50 || err != io.EOF || !bytes.Equal(tail[:n], data[len(data)-50:]) {
+		t.Fatalf("ReadAt() at the end = %d, %v", n, err)
+	}
+	if n, err := r.ReadAt(tail, int64(len(data)+10)); n != 0 || err != io.EOF {
+		t.Fatalf("ReadAt() past the end = %d, %v", n, err)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if _, err := os.Stat(spill.Name()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("spill file left behind: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"os"
 	"path"
 	"strings"
 	"sync"
@@ -12,7 +13,7 @@ import (
 )
 
 func (r *Reader) readFullAt(off int64, p []byte) error {
-	sr := io.NewSectionReader(r.file, off, int64(len(p)))
+	sr := io.NewSectionReader(r.src, off, int64(len(p)))
 	_, err := io.ReadFull(sr, p)
 	return err
 }
@@ -352,6 +353,55 @@ func (r *Reader) readFileEntry(icb LongAD) (any, error) {
 	return entry, err
 }
 
+// ICBExtent is one allocation descriptor of a file entry.
+type ICBExtent struct {
+	Length       uint32
+	LBN          uint32
+	PartitionRef uint16
+}
+
+// ICBInfo summarizes the file entry referenced by an ICB.
+type ICBInfo struct {
+	Location          uint32
+	TagIdentifier     uint16
+	FileType          uint8
+	Flags             uint16
+	InformationLength uint64
+	ModTime           time.Time
+	Extents           []ICBExtent
+}
+
+// InspectICB reads the file entry at icb and reports its header and extents.
+func (r *Reader) InspectICB(icb LongAD) (ICBInfo, error) {
+	location, err := r.resolveLBAddr(icb.ExtentLocation)
+	if err != nil {
+		return ICBInfo{}, err
+	}
+	entry, entryData, err := r.readFileEntryWithData(icb)
+	if err != nil {
+		return ICBInfo{}, err
+	}
+	info := ICBInfo{Location: location}
+	switch e := entry.(type) {
+	case *FileEntry:
+		info.TagIdentifier = e.DescriptorTag.TagIdentifier
+		info.FileType = e.ICBTag.FileType
+		info.Flags = e.ICBTag.Flags
+		info.InformationLength = e.InformationLength
+		info.ModTime = convertTimestamp(e.ModificationTime)
+	case *ExtendedFileEntry:
+		info.TagIdentifier = e.DescriptorTag.TagIdentifier
+		info.FileType = e.ICBTag.FileType
+		info.Flags = e.ICBTag.Flags
+		info.InformationLength = e.InformationLength
+		info.ModTime = convertTimestamp(e.ModificationTime)
+	}
+	for _, ad := range r.readAllocationDescriptors(entry, entryData, icb.ExtentLocation.PartitionReferenceNumber) {
+		info.Extents = append(info.Extents, ICBExtent{Length: ad.length, LBN: ad.lbn, PartitionRef: ad.pref})
+	}
+	return info, nil
+}
+
 // readAllocationDescriptors extracts allocation descriptors from a file entry.
 // If the descriptor format doesn't contain a partition reference (short_ad), defaultPref is used.
 func (r *Reader) readAllocationDescriptors(entry any, entryData []byte, defaultPref uint16) []allocationDescriptor {
@@ -639,19 +689,34 @@ func (f *File) Open() (io.ReadCloser, error) {
 	if len(exts) == 0 {
 		return &fileReader{reader: f.reader, offset: 0, size: 0}, nil
 	}
+	handle, err := f.reader.handles.get()
+	if err != nil {
+		return nil, err
+	}
 	if len(exts) == 1 && exts[0].fileStart == 0 {
-		return &fileReader{reader: f.reader, offset: exts[0].physOff, size: exts[0].fileEnd}, nil
+		return &fileReader{reader: f.reader, handle: handle, offset: exts[0].physOff, size: exts[0].fileEnd}, nil
 	}
 	return &extentReader{
 		reader:  f.reader,
+		handle:  handle,
 		extents: exts,
 		size:    size,
 	}, nil
 }
 
+// source returns what a file reader reads through: its own handle, or the
+// Reader's shared image when the pool gave it none.
+func (r *Reader) source(handle *os.File) io.ReaderAt {
+	if handle != nil {
+		return handle
+	}
+	return r.src
+}
+
 // fileReader implements io.ReadCloser for UDF files
 type fileReader struct {
 	reader   *Reader
+	handle   *os.File
 	offset   int64
 	size     int64
 	position int64
@@ -668,7 +733,7 @@ func (fr *fileReader) Read(p []byte) (n int, err error) {
 		toRead = int(remaining)
 	}
 
-	n, err = fr.reader.file.ReadAt(p[:toRead], fr.offset+fr.position)
+	n, err = fr.reader.source(fr.handle).ReadAt(p[:toRead], fr.offset+fr.position)
 	fr.position += int64(n)
 
 	if fr.position >= fr.size && err == nil {
@@ -679,7 +744,8 @@ func (fr *fileReader) Read(p []byte) (n int, err error) {
 }
 
 func (fr *fileReader) Close() error {
-	// Nothing to close - the main file handle stays open
+	fr.reader.handles.put(fr.handle)
+	fr.handle = nil
 	return nil
 }
 
@@ -691,6 +757,7 @@ type extent struct {
 
 type extentReader struct {
 	reader  *Reader
+	handle  *os.File
 	extents []extent
 	size    int64
 
@@ -732,7 +799,7 @@ func (er *extentReader) Read(p []byte) (n int, err error) {
 		}
 
 		off := ex.physOff + (er.pos - ex.fileStart)
-		nn, rerr := er.reader.file.ReadAt(p[n:n+want], off)
+		nn, rerr := er.reader.source(er.handle).ReadAt(p[n:n+want], off)
 		n += nn
 		er.pos += int64(nn)
 		if rerr != nil {
@@ -753,7 +820,11 @@ func (er *extentReader) Read(p []byte) (n int, err error) {
 	return n, nil
 }
 
-func (er *extentReader) Close() error { return nil }
+func (er *extentReader) Close() error {
+	er.reader.handles.put(er.handle)
+	er.handle = nil
+	return nil
+}
 
 // convertTimestamp converts UDF timestamp to Go time.Time
 func convertTimestamp(ts Timestamp) time.Time {
@@ -920,22 +991,18 @@ func (d *Directory) tryReadBlurayRootDirectory() error {
 	}
 
 	for _, loc := range locations {
-		if _, err := d.reader.file.Seek(loc, io.SeekStart); err != nil {
-			continue
-		}
-
-		// Read a small amount to check for FID tag
+		// Read a small amount to check for FID tag. ReadAt leaves the shared
+		// file position alone, so this is safe alongside open file readers.
 		header := make([]byte, 4)
-		if _, err := d.reader.file.Read(header); err != nil {
+		if err := d.reader.readFullAt(loc, header); err != nil {
 			continue
 		}
 
 		tag := binary.LittleEndian.Uint16(header[0:2])
 		if tag == TagFileIdentifier {
 			// Found FID! Read more data and parse
-			d.reader.file.Seek(loc, io.SeekStart)
 			data := make([]byte, 2048) // Read one sector
-			if _, err := d.reader.file.Read(data); err != nil {
+			if err := d.reader.readFullAt(loc, data); err != nil {
 				continue
 			}
 
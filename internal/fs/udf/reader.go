@@ -6,11 +6,20 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 )
 
-// Reader provides UDF file system reading capabilities
+// Reader provides UDF file system reading capabilities over any io.ReaderAt.
+// Metadata is read through src; each reader returned by File.Open gets its own descriptor from
+// handles, so concurrent stream scans of one image neither share a file
+// position nor fight over the kernel's read-ahead window. Images opened with
+// NewReaderFrom have no pool and every read goes through src.
 type Reader struct {
-	file            *os.File
+	src             io.ReaderAt
+	size            int64
+	closer          io.Closer
+	cursor          *io.SectionReader // sequential descriptor reads while mounting
+	handles         handlePool
 	volumeLabel     string
 	blockSize       uint32
 	partitionStart  uint32
@@ -21,6 +30,10 @@ type Reader struct {
 	fileSetDesc     *FileSetDescriptor
 	fileSetLocation uint32
 
+	anchor         *AnchorVolumeDescriptorPointer
+	logicalVolume  *LogicalVolumeDescriptor
+	partitionDescs []PartitionDescriptor
+
 	metadataFileICB        *LongAD
 	metadataFileAllocDescs []allocationDescriptor
 }
@@ -49,13 +62,15 @@ func NewReader(path string) (*Reader, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open ISO file: %w", err)
 	}
-
-	reader := &Reader{
-		file:            file,
-		blockSize:       SectorSize,
-		partitionStarts: make(map[uint16]uint32),
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open ISO file: %w", err)
 	}
 
+	reader := newReader(file, info.Size())
+	reader.closer = file
+	reader.handles = handlePool{path: path}
 	if err := reader.initialize(); err != nil {
 		file.Close()
 		return nil, err
@@ -64,14 +79,105 @@ func NewReader(path string) (*Reader, error) {
 	return reader, nil
 }
 
-// Close closes the UDF reader
+// UnknownSize is the size to pass NewReaderFrom for an image whose end is not
+// known, such as one piped to stdin. The anchor is then only looked for at
+// sectors 256 and 512: the copies near the end would need the whole image
+// read to find it.
+const UnknownSize = 1 << 50
+
+// NewReaderFrom creates a UDF reader over an image of size bytes that is not a
+// local file: an image held in memory, a network-backed reader or a file
+// inside an archive. The caller keeps src open until it is done with the Reader
+// and its files.
+func NewReaderFrom(src io.ReaderAt, size int64) (*Reader, error) {
+	reader := newReader(src, size)
+	if err := reader.initialize(); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+func newReader(src io.ReaderAt, size int64) *Reader {
+	return &Reader{
+		src:             src,
+		size:            size,
+		cursor:          io.NewSectionReader(src, 0, size),
+		blockSize:       SectorSize,
+		partitionStarts: make(map[uint16]uint32),
+	}
+}
+
+// Close closes the UDF reader. Readers still open from File.Open close their
+// own descriptors when they are closed. An image passed to NewReaderFrom is left
+// open.
 func (r *Reader) Close() error {
-	if r.file != nil {
-		return r.file.Close()
+	r.handles.close()
+	if r.closer != nil {
+		return r.closer.Close()
 	}
 	return nil
 }
 
+// maxIdleHandles bounds the descriptors kept open for reuse after their file
+// readers close; it matches the scan worker ceiling.
+const maxIdleHandles = 8
+
+// handlePool hands out descriptors for the image at path, reusing the ones
+// closed readers gave back. A pool without a path hands out nothing and
+// readers fall back to the shared Reader.src.
+type handlePool struct {
+	mu     sync.Mutex
+	path   string
+	idle   []*os.File
+	closed bool
+}
+
+func (p *handlePool) get() (*os.File, error) {
+	p.mu.Lock()
+	if p.path == "" || p.closed {
+		p.mu.Unlock()
+		return nil, nil
+	}
+	if n := len(p.idle); n > 0 {
+		f := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return f, nil
+	}
+	p.mu.Unlock()
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ISO file: %w", err)
+	}
+	return f, nil
+}
+
+func (p *handlePool) put(f *os.File) {
+	if f == nil {
+		return
+	}
+	p.mu.Lock()
+	if !p.closed && len(p.idle) < maxIdleHandles {
+		p.idle = append(p.idle, f)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	f.Close()
+}
+
+func (p *handlePool) close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+	for _, f := range idle {
+		f.Close()
+	}
+}
+
 // GetVolumeLabel returns the volume label
 func (r *Reader) GetVolumeLabel() string {
 	return r.volumeLabel
@@ -90,6 +196,7 @@ func (r *Reader) initialize() error {
 	if err != nil {
 		return fmt.Errorf("failed to find anchor volume descriptor: %w", err)
 	}
+	r.anchor = anchor
 
 	// Read volume descriptor sequence
 	if err := r.readVolumeDescriptorSequence(anchor.MainVolumeDescriptorSequenceExtent); err != nil {
@@ -101,7 +208,7 @@ func (r *Reader) initialize() error {
 	if r.fileSetLocation > 0 {
 		location := r.partitionStart + r.fileSetLocation
 
-		if _, err := r.file.Seek(int64(location)*int64(r.blockSize), io.SeekStart); err != nil {
+		if _, err := r.cursor.Seek(int64(location)*int64(r.blockSize), io.SeekStart); err != nil {
 			return fmt.Errorf("failed to seek to file set descriptor: %w", err)
 		}
 
@@ -128,7 +235,7 @@ func (r *Reader) initialize() error {
 // verifyVolume checks for UDF volume recognition sequence
 func (r *Reader) verifyVolume() error {
 	// Read VRS starting at sector 16
-	if _, err := r.file.Seek(VRSOffset, io.SeekStart); err != nil {
+	if _, err := r.cursor.Seek(VRSOffset, io.SeekStart); err != nil {
 		return err
 	}
 
@@ -138,7 +245,7 @@ func (r *Reader) verifyVolume() error {
 
 	for i := 0; i < 16 && !foundTerminator; i++ { // Check up to 16 sectors
 		var vrs VolumeRecognitionDescriptor
-		if err := binary.Read(r.file, binary.LittleEndian, &vrs); err != nil {
+		if err := binary.Read(r.cursor, binary.LittleEndian, &vrs); err != nil {
 			// If we can't read a full descriptor, stop
 			if err == io.EOF {
 				break
@@ -185,30 +292,28 @@ func (r *Reader) findAnchorVolumeDescriptor() (*AnchorVolumeDescriptorPointer, e
 	locations := []int64{256, 512}
 
 	// Get file size to check end locations
-	info, err := r.file.Stat()
-	if err != nil {
-		return nil, err
+	if r.size != UnknownSize {
+		totalSectors := r.size / SectorSize
+		locations = append(locations, totalSectors-256, totalSectors)
 	}
-	totalSectors := info.Size() / SectorSize
-	locations = append(locations, totalSectors-256, totalSectors)
 
 	for _, sector := range locations {
-		if sector < 0 || sector*SectorSize >= info.Size() {
+		if sector < 0 || sector*SectorSize >= r.size {
 			continue
 		}
 
-		if _, err := r.file.Seek(sector*SectorSize, io.SeekStart); err != nil {
+		if _, err := r.cursor.Seek(sector*SectorSize, io.SeekStart); err != nil {
 			continue
 		}
 
 		var tag Tag
-		if err := binary.Read(r.file, binary.LittleEndian, &tag); err != nil {
+		if err := binary.Read(r.cursor, binary.LittleEndian, &tag); err != nil {
 			continue
 		}
 
 		if tag.TagIdentifier == TagAnchorVolume {
 			// Read the rest of the anchor descriptor
-			r.file.Seek(sector*SectorSize, io.SeekStart)
+			r.cursor.Seek(sector*SectorSize, io.SeekStart)
 			anchor := &AnchorVolumeDescriptorPointer{}
 			if err := r.readDescriptor(anchor); err != nil {
 				continue
@@ -217,12 +322,15 @@ func (r *Reader) findAnchorVolumeDescriptor() (*AnchorVolumeDescriptorPointer, e
 		}
 	}
 
+	if r.size == UnknownSize {
+		return nil, fmt.Errorf("anchor volume descriptor not found at sector 256 or 512 (the end of an image of unknown size is not searched)")
+	}
 	return nil, fmt.Errorf("anchor volume descriptor not found")
 }
 
 // readVolumeDescriptorSequence reads the main volume descriptor sequence
 func (r *Reader) readVolumeDescriptorSequence(extent ExtentAD) error {
-	if _, err := r.file.Seek(int64(extent.Location)*SectorSize, io.SeekStart); err != nil {
+	if _, err := r.cursor.Seek(int64(extent.Location)*SectorSize, io.SeekStart); err != nil {
 		return err
 	}
 
@@ -231,12 +339,12 @@ func (r *Reader) readVolumeDescriptorSequence(extent ExtentAD) error {
 		var tag Tag
 		tagPos := r.getCurrentPosition()
 
-		if err := binary.Read(r.file, binary.LittleEndian, &tag); err != nil {
+		if err := binary.Read(r.cursor, binary.LittleEndian, &tag); err != nil {
 			return err
 		}
 
 		// Seek back to read full descriptor
-		r.file.Seek(tagPos, io.SeekStart)
+		r.cursor.Seek(tagPos, io.SeekStart)
 
 		switch tag.TagIdentifier {
 		case TagPrimaryVolume:
@@ -251,6 +359,7 @@ func (r *Reader) readVolumeDescriptorSequence(extent ExtentAD) error {
 			if err := r.readDescriptor(&pd); err != nil {
 				return err
 			}
+			r.partitionDescs = append(r.partitionDescs, pd)
 			r.partitionStarts[pd.PartitionNumber] = pd.PartitionStartingLocation
 			// Keep legacy single-partition fields for callers that assume one partition.
 			if r.partitionStart == 0 {
@@ -263,12 +372,13 @@ func (r *Reader) readVolumeDescriptorSequence(extent ExtentAD) error {
 			if err := r.readDescriptor(&lvd); err != nil {
 				return err
 			}
+			r.logicalVolume = &lvd
 			if lvd.LogicalBlockSize != 0 {
 				r.blockSize = lvd.LogicalBlockSize
 			}
 			if lvd.MapTableLength > 0 && lvd.NumberOfPartitionMaps > 0 {
 				pm := make([]byte, lvd.MapTableLength)
-				if _, err := io.ReadFull(r.file, pm); err != nil {
+				if _, err := io.ReadFull(r.cursor, pm); err != nil {
 					return fmt.Errorf("failed to read partition map table: %w", err)
 				}
 				if err := r.parsePartitionMaps(pm, lvd.NumberOfPartitionMaps); err != nil {
@@ -312,7 +422,7 @@ func (r *Reader) readVolumeDescriptorSequence(extent ExtentAD) error {
 
 		// Always advance by one sector
 		bytesRead += SectorSize
-		r.file.Seek(int64(extent.Location+bytesRead/SectorSize)*int64(SectorSize), io.SeekStart)
+		r.cursor.Seek(int64(extent.Location+bytesRead/SectorSize)*int64(SectorSize), io.SeekStart)
 	}
 
 	return nil
@@ -320,12 +430,12 @@ func (r *Reader) readVolumeDescriptorSequence(extent ExtentAD) error {
 
 // readDescriptor reads a descriptor with its tag
 func (r *Reader) readDescriptor(desc any) error {
-	return binary.Read(r.file, binary.LittleEndian, desc)
+	return binary.Read(r.cursor, binary.LittleEndian, desc)
 }
 
 // getCurrentPosition returns current file position
 func (r *Reader) getCurrentPosition() int64 {
-	pos, _ := r.file.Seek(0, io.SeekCurrent)
+	pos, _ := r.cursor.Seek(0, io.SeekCurrent)
 	return pos
 }
 
@@ -368,6 +478,21 @@ func (r *Reader) FileSetLocation() uint32 {
 }
 func (r *Reader) RootICB() LongAD { return r.rootICB }
 
+// AnchorDescriptor returns the anchor volume descriptor pointer found during mount.
+func (r *Reader) AnchorDescriptor() *AnchorVolumeDescriptorPointer { return r.anchor }
+
+// LogicalVolume returns the logical volume descriptor from the main sequence.
+func (r *Reader) LogicalVolume() *LogicalVolumeDescriptor { return r.logicalVolume }
+
+// PartitionDescriptors returns partition descriptors in sequence order.
+func (r *Reader) PartitionDescriptors() []PartitionDescriptor { return r.partitionDescs }
+
+// FileSet returns the file set descriptor.
+func (r *Reader) FileSet() *FileSetDescriptor { return r.fileSetDesc }
+
+// DecodeString decodes a UDF dstring (e.g. identifiers in debug dumps).
+func (r *Reader) DecodeString(data []byte) string { return r.decodeString(data) }
+
 func (r *Reader) DebugPartitionMaps() []string {
 	var out []string
 	for i, pm := range r.partitionMaps {
@@ -461,7 +586,7 @@ func (r *Reader) parsePartitionMaps(pm []byte, n uint32) error {
 		}
 	}
 
-	if r.metadataFileICB != nil && r.file != nil {
+	if r.metadataFileICB != nil && r.src != nil {
 		if _, err := r.metadataFileAllocationDescriptors(); err != nil {
 			return err
 		}
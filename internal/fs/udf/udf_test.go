@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -99,7 +100,7 @@ func TestExtentReader_ReadsAcrossExtents(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	r := &Reader{file: f}
+	r := &Reader{src: f}
 	er := &extentReader{
 		reader: r,
 		extents: []extent{
@@ -118,3 +119,114 @@ func TestExtentReader_ReadsAcrossExtents(t *testing.T) {
 		t.Fatalf("data mismatch: got len=%d want len=%d", len(got), len(want))
 	}
 }
+
+func TestFileReader_OwnHandles(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "udf-handles-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data := append(bytes.Repeat([]byte("A"), 4096), bytes.Repeat([]byte("B"), 4096)...)
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Reader{src: f, handles: handlePool{path: f.Name()}}
+	readers := make([]*fileReader, 2)
+	for i := range readers {
+		handle, err := r.handles.get()
+		if err != nil {
+			t.Fatalf("get() err: %v", err)
+		}
+		readers[i] = &fileReader{reader: r, handle: handle, offset: int64(i) * 4096, size: 4096}
+	}
+	if readers[0].handle == nil || readers[0].handle == readers[1].handle || readers[0].handle == f {
+		t.Fatal("file readers should get their own handles")
+	}
+
+	// Interleave reads the way concurrent stream scans do.
+	var got [2][]byte
+	buf := make([]byte, 512)
+	for range 8 {
+		for i, fr := range readers {
+			n, _ := fr.Read(buf)
+			got[i] = append(got[i], buf[:n]...)
+		}
+	}
+	if !bytes.Equal(got[0], data[:4096]) || !bytes.Equal(got[1], data[4096:]) {
+		t.Fatal("interleaved reads returned the wrong data")
+	}
+
+	handle := readers[0].handle
+	readers[0].Close()
+	if reused, _ := r.handles.get(); reused != handle {
+		t.Fatal("closed reader's handle was not reused")
+	}
+	r.handles.put(handle)
+	readers[1].Close()
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() err: %v", err)
+	}
+	if _, err := handle.Stat(); err == nil {
+		t.Fatal("idle handle still open after Close")
+	}
+	if h, err := r.handles.get(); h != nil || err != nil {
+		t.Fatalf("get() after Close got=%v err=%v", h, err)
+	}
+}
+
+func TestNewReaderFrom_RejectsNonUDF(t *testing.T) {
+	image := bytes.NewReader(make([]byte, 300*SectorSize))
+	if _, err := NewReaderFrom(image, image.Size()); err == nil {
+		t.Fatal("NewReaderFrom accepted an image without a UDF volume")
+	}
+}
+
+func TestFileReader_ReaderAtImage(t *testing.T) {
+	data := append(bytes.Repeat([]byte("A"), 4096), bytes.Repeat([]byte("B"), 4096)...)
+	image := bytes.NewReader(data)
+	r := newReader(image, image.Size())
+
+	// Without a pool path every file reads through the image itself.
+	handle, err := r.handles.get()
+	if handle != nil || err != nil {
+		t.Fatalf("get() got=%v err=%v, want no handle", handle, err)
+	}
+	fr := &fileReader{reader: r, offset: 4096 - 512, size: 1024}
+	got, err := io.ReadAll(fr)
+	if err != nil {
+		t.Fatalf("ReadAll err: %v", err)
+	}
+	if !bytes.Equal(got, data[4096-512:4096+512]) {
+		t.Fatal("file reader returned the wrong data")
+	}
+	if err := fr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() err: %v", err)
+	}
+}
+
+// offsetRecorder is an image that remembers the furthest byte read.
+type offsetRecorder struct {
+	*bytes.Reader
+	furthest int64
+}
+
+func (o *offsetRecorder) ReadAt(p []byte, off int64) (int, error) {
+	o.furthest = max(o.furthest, off+int64(len(p)))
+	return o.Reader.ReadAt(p, off)
+}
+
+func TestFindAnchor_UnknownSizeSkipsEnd(t *testing.T) {
+	image := &offsetRecorder{Reader: bytes.NewReader(make([]byte, 600*SectorSize))}
+	r := newReader(image, UnknownSize)
+	_, err := r.findAnchorVolumeDescriptor()
+	if err == nil || !strings.Contains(err.Error(), "unknown size") {
+		t.Fatalf("findAnchorVolumeDescriptor() err=%v, want unknown size error", err)
+	}
+	if image.furthest > 513*SectorSize {
+		t.Fatalf("read up to byte %d, past sector 512", image.furthest)
+	}
+}
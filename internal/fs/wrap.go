@@ -0,0 +1,88 @@
+package fs
+
+import "io"
+
+// openFunc opens file for reading on behalf of a wrapping file system.
+type openFunc func(file FileInfo) (io.ReadCloser, error)
+
+// wrapOpens returns fsys with every FileInfo it hands out (directly or through
+// directory listings) opening through open.
+func wrapOpens(fsys FileSystem, open openFunc) FileSystem {
+	return &wrappedFileSystem{FileSystem: fsys, open: open}
+}
+
+type wrappedFileSystem struct {
+	FileSystem
+	open openFunc
+}
+
+func (w *wrappedFileSystem) GetDirectoryInfo(path string) (DirectoryInfo, error) {
+	dir, err := w.FileSystem.GetDirectoryInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedDirectoryInfo{DirectoryInfo: dir, open: w.open}, nil
+}
+
+func (w *wrappedFileSystem) GetFileInfo(path string) (FileInfo, error) {
+	file, err := w.FileSystem.GetFileInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedFileInfo{FileInfo: file, open: w.open}, nil
+}
+
+type wrappedDirectoryInfo struct {
+	DirectoryInfo
+	open openFunc
+}
+
+func (d *wrappedDirectoryInfo) GetFiles() ([]FileInfo, error) {
+	files, err := d.DirectoryInfo.GetFiles()
+	return d.wrapFiles(files), err
+}
+
+func (d *wrappedDirectoryInfo) GetFilesPattern(pattern string) ([]FileInfo, error) {
+	files, err := d.DirectoryInfo.GetFilesPattern(pattern)
+	return d.wrapFiles(files), err
+}
+
+func (d *wrappedDirectoryInfo) GetDirectories() ([]DirectoryInfo, error) {
+	dirs, err := d.DirectoryInfo.GetDirectories()
+	for i, dir := range dirs {
+		dirs[i] = &wrappedDirectoryInfo{DirectoryInfo: dir, open: d.open}
+	}
+	return dirs, err
+}
+
+func (d *wrappedDirectoryInfo) GetDirectory(name string) (DirectoryInfo, error) {
+	dir, err := d.DirectoryInfo.GetDirectory(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedDirectoryInfo{DirectoryInfo: dir, open: d.open}, nil
+}
+
+func (d *wrappedDirectoryInfo) GetFile(name string) (FileInfo, error) {
+	file, err := d.DirectoryInfo.GetFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedFileInfo{FileInfo: file, open: d.open}, nil
+}
+
+func (d *wrappedDirectoryInfo) wrapFiles(files []FileInfo) []FileInfo {
+	for i, file := range files {
+		files[i] = &wrappedFileInfo{FileInfo: file, open: d.open}
+	}
+	return files
+}
+
+type wrappedFileInfo struct {
+	FileInfo
+	open openFunc
+}
+
+func (f *wrappedFileInfo) OpenRead() (io.ReadCloser, error) {
+	return f.open(f.FileInfo)
+}
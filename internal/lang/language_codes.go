@@ -469,3 +469,25 @@ var codes = map[string]string{
 	"zul": "Zulu",
 	"zun": "Zuni",
 }
+
+// FromAlpha2 returns the ISO 639-2 (bibliographic) code for an ISO 639-1 code
+// such as the "en" of DVD IFO attributes, or "" when it is not known.
+func FromAlpha2(code string) string {
+	return alpha2[code]
+}
+
+var alpha2 = map[string]string{
+	"af": "afr", "ar": "ara", "bg": "bul", "bn": "ben", "bs": "bos",
+	"ca": "cat", "cs": "cze", "cy": "wel", "da": "dan", "de": "ger",
+	"el": "gre", "en": "eng", "es": "spa", "et": "est", "eu": "baq",
+	"fa": "per", "fi": "fin", "fr": "fre", "ga": "gle", "gl": "glg",
+	"he": "heb", "hi": "hin", "hr": "hrv", "hu": "hun", "hy": "arm",
+	"id": "ind", "is": "ice", "it": "ita", "iw": "heb", "ja": "jpn",
+	"ka": "geo", "kk": "kaz", "km": "khm", "ko": "kor", "la": "lat",
+	"lt": "lit", "lv": "lav", "mk": "mac", "ml": "mal", "mn": "mon",
+	"ms": "may", "mt": "mlt", "nl": "dut", "no": "nor", "pa": "pan",
+	"pl": "pol", "pt": "por", "ro": "rum", "ru": "rus", "sk": "slo",
+	"sl": "slv", "sq": "alb", "sr": "srp", "sv": "swe", "ta": "tam",
+	"te": "tel", "th": "tha", "tl": "tgl", "tr": "tur", "uk": "ukr",
+	"ur": "urd", "vi": "vie", "yi": "yid", "zh": "chi", "zu": "zul",
+}
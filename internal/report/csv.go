@@ -0,0 +1,104 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// IsCSV reports whether reportPath selects the CSV report.
+func IsCSV(reportPath string) bool {
+	return strings.EqualFold(filepath.Ext(reportPath), ".csv")
+}
+
+// ClipsCSVPath names the clip table written next to the CSV report at reportPath.
+func ClipsCSVPath(reportPath string) string {
+	return strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + ".clips.csv"
+}
+
+// StreamKind names the report section of st: "video", "audio", "subtitle",
+// "text" or "other".
+func StreamKind(st stream.Info) string {
+	base := st.Base()
+	switch {
+	case base.IsVideoStream():
+		return "video"
+	case base.IsAudioStream():
+		return "audio"
+	case base.IsGraphicsStream():
+		return "subtitle"
+	case base.IsTextStream():
+		return "text"
+	default:
+		return "other"
+	}
+}
+
+// StreamID identifies a stream across scans and output formats by playlist,
+// kind, PID and angle, e.g. "00800.MPLS/audio/4352" or
+// "00800.MPLS/video/4113/angle1".
+func StreamID(playlist string, st stream.Info) string {
+	base := st.Base()
+	id := fmt.Sprintf("%s/%s/%d", playlist, StreamKind(st), base.PID)
+	if base.AngleIndex > 0 {
+		id += fmt.Sprintf("/angle%d", base.AngleIndex)
+	}
+	return id
+}
+
+// renderStreamsCSV writes one row per stream per reported playlist. Bitrates are
+// bits per second.
+func renderStreamsCSV(playlists []*bdrom.PlaylistFile) (string, error) {
+	rows := [][]string{{"Playlist", "PID", "Type", "Codec", "Language", "Bitrate", "Description", "ID"}}
+	for _, playlist := range playlists {
+		for _, st := range playlist.SortedStreams {
+			base := st.Base()
+			kind := StreamKind(st)
+			rows = append(rows, []string{
+				playlist.Name,
+				fmt.Sprint(base.PID),
+				strings.ToUpper(kind[:1]) + kind[1:],
+				stream.CodecNameForInfo(st),
+				base.LanguageName,
+				fmt.Sprint(base.BitRate),
+				st.Description(),
+				StreamID(playlist.Name, st),
+			})
+		}
+	}
+	return encodeCSV(rows)
+}
+
+// RenderClipsCSV writes one row per clip of each playlist the report covers.
+// Times are seconds, sizes bytes and bitrates bits per second.
+func RenderClipsCSV(playlists []*bdrom.PlaylistFile, settings settings.Settings) (string, error) {
+	rows := [][]string{{"Playlist", "File", "Angle", "Time In", "Length", "Size", "Bitrate"}}
+	for _, playlist := range reportPlaylists(playlists, settings) {
+		for _, clip := range playlist.StreamClips {
+			rows = append(rows, []string{
+				playlist.Name,
+				clip.DisplayName(),
+				fmt.Sprint(clip.AngleIndex),
+				fmt.Sprintf("%.3f", clip.RelativeTimeIn),
+				fmt.Sprintf("%.3f", clip.Length),
+				fmt.Sprint(clip.PacketSize()),
+				fmt.Sprint(clip.PacketBitRate()),
+			})
+		}
+	}
+	return encodeCSV(rows)
+}
+
+func encodeCSV(rows [][]string) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,157 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/dvd"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/util"
+)
+
+// RenderDVDReport renders the text report of a DVD-Video disc: one block per
+// title with its video, audio and subtitle streams and chapters, laid out
+// like the playlist blocks of a BD report. Stream bitrates are not known as
+// the VOBs are not read. It returns the resolved report name and the report.
+func RenderDVDReport(path string, disc *dvd.Disc, settings settings.Settings) (string, string, error) {
+	reportName := reportFileName(path, disc.VolumeLabel, settings)
+	if ext := strings.ToLower(filepath.Ext(reportName)); ext == ".xml" || ext == ".csv" {
+		return "", "", errors.New("DVD reports are text only")
+	}
+	lbl, err := loadLabels(settings)
+	if err != nil {
+		return "", "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%s\n", lbl.field("Disc Label:", 16), disc.VolumeLabel)
+	fmt.Fprintf(&b, "%s%s %s\n", lbl.field("Disc Size:", 16), util.FormatNumber(int64(disc.Size)), lbl.get("bytes"))
+	fmt.Fprintf(&b, "%s%s\n", lbl.field("Format:", 16), "DVD-Video")
+	fmt.Fprintf(&b, "%s%s\n\n\n", lbl.field("BDInfo:", 16), reportProductVersion(settings))
+	writeNotes(&b, settings, lbl)
+
+	for _, title := range DVDTitles(disc, settings) {
+		writeDVDTitleBlock(&b, disc, title, settings, lbl)
+	}
+	return reportName, appendGeneratedBy(b.String(), settings, lbl), nil
+}
+
+// DVDTitles returns the titles that get a report block: the longest one for
+// the main or big playlist settings, the TopPlaylists longest ones, or all of
+// them, in title order.
+func DVDTitles(disc *dvd.Disc, settings settings.Settings) []*dvd.Title {
+	titles := slices.Clone(disc.Titles)
+	byLength := slices.Clone(titles)
+	slices.SortStableFunc(byLength, func(a, b *dvd.Title) int {
+		switch {
+		case a.Length > b.Length:
+			return -1
+		case a.Length < b.Length:
+			return 1
+		}
+		return 0
+	})
+	switch {
+	case len(titles) == 0:
+		return titles
+	case settings.MainPlaylistOnly || settings.BigPlaylistOnly:
+		return byLength[:1]
+	case settings.TopPlaylists > 0 && settings.TopPlaylists < len(titles):
+		keep := byLength[:settings.TopPlaylists]
+		return slices.DeleteFunc(titles, func(t *dvd.Title) bool { return !slices.Contains(keep, t) })
+	}
+	return titles
+}
+
+// DVDTitleName names a title in reports, e.g. "Title 01".
+func DVDTitleName(title *dvd.Title) string {
+	return fmt.Sprintf("Title %02d", title.Number)
+}
+
+func writeDVDTitleBlock(b *strings.Builder, disc *dvd.Disc, title *dvd.Title, settings settings.Settings, lbl labels) {
+	rounding := newBitrateRounding(settings)
+	var summary strings.Builder
+
+	b.WriteString("\n\n********************\n")
+	fmt.Fprintf(b, "%s %02d\n", lbl.get("TITLE:"), title.Number)
+	b.WriteString("********************\n\n\n")
+
+	b.WriteString("TITLE REPORT:\n\n\n")
+	fmt.Fprintf(b, "%-24s%s\n", "Name:", DVDTitleName(title))
+	fmt.Fprintf(b, "%-24s%s (h:m:s.ms)\n", "Length:", util.FormatTime(title.Length, true))
+	fmt.Fprintf(b, "%-24s%s bytes\n", "Size:", util.FormatNumber(int64(title.Size)))
+	fmt.Fprintf(b, "%-24s%s Mbps\n", "Total Bitrate:", rounding.mbps(title.TotalBitrate()))
+	fmt.Fprintf(b, "%-24sVTS_%02d_0.IFO (title %d)\n", "Title Set:", title.TitleSet, title.TitleInSet)
+	if title.Angles > 1 {
+		fmt.Fprintf(b, "%-24s%d\n", "Angles:", title.Angles)
+	}
+
+	b.WriteString("\n\nVIDEO:\n\n\n")
+	table := newReportTable(settings.WideColumns, 24, 20, 16)
+	table.row("Codec", "Bitrate", "Description")
+	table.row("-----", "-------", "-----------")
+	table.row(title.Video.CodecName(), "", title.Video.Description())
+	table.write(b)
+	if settings.GenerateTextSummary {
+		fmt.Fprintf(&summary, "%s %s / %s\n", lbl.get("Video:"), title.Video.CodecName(), title.Video.Description())
+	}
+
+	if len(title.Audio) > 0 || settings.ShowEmptySections {
+		b.WriteString("\n\nAUDIO:\n\n\n")
+		table := newReportTable(settings.WideColumns, 32, 16, 16, 16)
+		table.row("Codec", "Language", "Bitrate", "Description")
+		table.row("-----", "--------", "-------", "-----------")
+		if len(title.Audio) == 0 {
+			table.row("No audio streams", "", "", "")
+		}
+		for _, audio := range title.Audio {
+			table.row(audio.CodecName(), audio.LanguageName(), "", audio.Description())
+			if settings.GenerateTextSummary {
+				fmt.Fprintf(&summary, "%s %s / %s / %s\n", lbl.get("Audio:"), audio.LanguageName(), audio.CodecName(), audio.Description())
+			}
+		}
+		table.write(b)
+	}
+
+	if len(title.Subpictures) > 0 || settings.ShowEmptySections {
+		b.WriteString("\n\nSUBTITLES:\n\n\n")
+		table := newReportTable(settings.WideColumns, 32, 16, 16, 16)
+		table.row("Codec", "Language", "Bitrate", "Description")
+		table.row("-----", "--------", "-------", "-----------")
+		if len(title.Subpictures) == 0 {
+			table.row("No subtitles", "", "", "")
+		}
+		for _, sp := range title.Subpictures {
+			table.row("DVD Subtitles", sp.LanguageName(), "", sp.Extension)
+			if settings.GenerateTextSummary {
+				fmt.Fprintf(&summary, "%s %s\n", lbl.get("Subtitle:"), sp.LanguageName())
+			}
+		}
+		table.write(b)
+	}
+
+	b.WriteString("\n\nCHAPTERS:\n\n\n")
+	fmt.Fprintf(b, "%-16s%-16s%-16s\n", "Number", "Time In", "Length")
+	fmt.Fprintf(b, "%-16s%-16s%-16s\n", "------", "-------", "------")
+	if len(title.Chapters) == 0 && settings.ShowEmptySections {
+		fmt.Fprintf(b, "%-16s\n", "No chapters")
+	}
+	for i, chapter := range title.Chapters {
+		fmt.Fprintf(b, "%-16d%-16s%-16s\n", i+1, util.FormatTime(chapter.Start, true), util.FormatTime(chapter.Length, true))
+	}
+
+	if settings.GenerateTextSummary {
+		b.WriteString("\n\n")
+		b.WriteString(lbl.get("QUICK SUMMARY:") + "\n\n\n")
+		fmt.Fprintf(b, "%s %s\n", lbl.get("Disc Label:"), disc.VolumeLabel)
+		fmt.Fprintf(b, "%s %s %s\n", lbl.get("Disc Size:"), util.FormatNumber(int64(disc.Size)), lbl.get("bytes"))
+		fmt.Fprintf(b, "%s %s\n", lbl.get("Title:"), DVDTitleName(title))
+		fmt.Fprintf(b, "%s %s %s\n", lbl.get("Size:"), util.FormatNumber(int64(title.Size)), lbl.get("bytes"))
+		fmt.Fprintf(b, "%s %s\n", lbl.get("Length:"), util.FormatTime(title.Length, true))
+		fmt.Fprintf(b, "%s %s Mbps\n", lbl.get("Total Bitrate:"), rounding.mbps(title.TotalBitrate()))
+		b.WriteString(summary.String())
+	}
+}
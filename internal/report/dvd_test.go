@@ -0,0 +1,88 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/dvd"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func testDVDDisc() *dvd.Disc {
+	video := dvd.Video{StreamType: stream.StreamTypeMPEG2Video, Standard: "NTSC", Width: 720, Height: 480, FrameRate: 29.97, AspectRatio: "16:9"}
+	return &dvd.Disc{
+		VolumeLabel: "MOVIE",
+		Size:        4_000_000,
+		Titles: []*dvd.Title{
+			{Number: 1, TitleSet: 1, TitleInSet: 1, Angles: 1, Length: 60, Size: 100_000, Video: video},
+			{
+				Number: 2, TitleSet: 1, TitleInSet: 2, Angles: 1, Length: 2100, Size: 3_000_000, Video: video,
+				Chapters:    []dvd.Chapter{{Start: 0, Length: 900}, {Start: 900, Length: 1200}},
+				Audio:       []dvd.Audio{{StreamID: 0x80, StreamType: stream.StreamTypeAC3Audio, LanguageCode: "eng", Channels: 6, SampleRate: 48000}},
+				Subpictures: []dvd.Subpicture{{StreamID: 0x20, LanguageCode: "ger"}},
+			},
+			{Number: 3, TitleSet: 2, TitleInSet: 1, Angles: 1, Length: 300, Size: 500_000, Video: video},
+		},
+	}
+}
+
+func TestRenderDVDReport(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	_, text, err := RenderDVDReport("-", testDVDDisc(), cfg)
+	if err != nil {
+		t.Fatalf("RenderDVDReport() error = %v", err)
+	}
+	for _, want := range []string{
+		"Format:         DVD-Video\n",
+		"Name:                   Title 02\n",
+		"Title Set:              VTS_02_0.IFO (title 1)\n",
+		"English",
+		"German",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("report missing %q:\n%s", want, text)
+		}
+	}
+	if got := strings.Count(text, "TITLE REPORT:"); got != 3 {
+		t.Fatalf("title blocks = %d, want 3", got)
+	}
+
+	if _, _, err := RenderDVDReport("report.xml", testDVDDisc(), cfg); err == nil {
+		t.Fatal("RenderDVDReport() XML error = nil")
+	}
+}
+
+func TestDVDTitles(t *testing.T) {
+	disc := testDVDDisc()
+	names := func(titles []*dvd.Title) string {
+		var out []string
+		for _, title := range titles {
+			out = append(out, DVDTitleName(title))
+		}
+		return strings.Join(out, ",")
+	}
+	tests := []struct {
+		name string
+		cfg  settings.Settings
+		want string
+	}{
+		{name: "all", want: "Title 01,Title 02,Title 03"},
+		{name: "main", cfg: settings.Settings{MainPlaylistOnly: true}, want: "Title 02"},
+		{name: "top two in title order", cfg: settings.Settings{TopPlaylists: 2}, want: "Title 02,Title 03"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(DVDTitles(disc, tt.cfg)); got != tt.want {
+				t.Fatalf("DVDTitles() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderDVDOneLine(t *testing.T) {
+	want := "MOVIE | 0:35:00 | 2.86 MB | MPEG-2 480i | AC3 5.1 | ger subs"
+	if got := RenderDVDOneLine(testDVDDisc()); got != want {
+		t.Fatalf("RenderDVDOneLine() = %q, want %q", got, want)
+	}
+}
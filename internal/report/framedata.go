@@ -0,0 +1,65 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+// FrameDataFile is the frame data of one reported playlist.
+type FrameDataFile struct {
+	Playlist string
+	Path     string
+	Data     string
+}
+
+// FrameDataPath names the frame data file of playlist written next to the
+// report at reportPath: BDINFO.MOVIE.txt and 00800.MPLS get
+// BDINFO.MOVIE.00800.FrameData.txt.
+func FrameDataPath(reportPath, playlist string) string {
+	base := strings.TrimSuffix(reportPath, filepath.Ext(reportPath))
+	return base + "." + strings.TrimSuffix(playlist, filepath.Ext(playlist)) + ".FrameData.txt"
+}
+
+// RenderFrameData lists the video frames of each reported playlist that has a
+// video stream, one file per playlist. Each video stream gets a block of
+// tab-separated rows on the main angle: the frame's time on the playlist
+// clock in seconds, its picture type (I, P, B or BI; empty when the scan did
+// not find one), and its size in bytes and transport packets. The layout is
+// this package's own and does not follow official BDInfo's FrameData.txt.
+func RenderFrameData(reportPath string, playlists []*bdrom.PlaylistFile, settings settings.Settings) []FrameDataFile {
+	var files []FrameDataFile
+	for _, playlist := range reportPlaylists(playlists, settings) {
+		if len(playlist.VideoStreams) == 0 {
+			continue
+		}
+		var b strings.Builder
+		for i, video := range playlist.VideoStreams {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "# %s PID %d (0x%04X) %s\n", playlist.Name, video.PID, video.PID, video.CodecName())
+			b.WriteString("# Time\tType\tBytes\tPackets\n")
+			for _, clip := range playlist.StreamClips {
+				if clip.AngleIndex != 0 || clip.StreamFile == nil {
+					continue
+				}
+				for _, diag := range clip.StreamFile.StreamDiagnostics[video.PID] {
+					if diag.Marker < clip.TimeIn || diag.Marker >= clip.TimeOut {
+						continue
+					}
+					fmt.Fprintf(&b, "%.6f\t%s\t%d\t%d\n", diag.Marker-clip.TimeIn+clip.RelativeTimeIn, diag.Tag, diag.Bytes, diag.Packets)
+				}
+			}
+		}
+		files = append(files, FrameDataFile{
+			Playlist: playlist.Name,
+			Path:     FrameDataPath(reportPath, playlist.Name),
+			Data:     b.String(),
+		})
+	}
+	return files
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+const (
+	toolName   = "go-bdinfo"
+	modulePath = "github.com/autobrr/go-bdinfo"
+)
+
+// reportToolVersion returns the go-bdinfo version named in the Generated by
+// footer: Settings.ToolVersion, else the module version recorded in the
+// binary's build info, else "dev".
+func reportToolVersion(settings settings.Settings) string {
+	if v := strings.TrimSpace(settings.ToolVersion); v != "" {
+		return v
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == modulePath && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath && dep.Version != "" {
+				return dep.Version
+			}
+		}
+	}
+	return "dev"
+}
+
+// generator names the binary that made the report, e.g. "go-bdinfo v0.9.0".
+func generator(settings settings.Settings) string {
+	return toolName + " " + reportToolVersion(settings)
+}
+
+// appendGeneratedBy adds the Generated by footer to a text report when
+// Settings.IncludeGeneratedBy is set. The BDInfo: line keeps the emulated
+// version for parsers; the footer also repeats it next to the real one.
+func appendGeneratedBy(output string, settings settings.Settings, lbl labels) string {
+	if !settings.IncludeGeneratedBy {
+		return output
+	}
+	if output != "" && !strings.HasSuffix(output, "\n\n") {
+		output += "\n"
+	}
+	return output + fmt.Sprintf("%s%s (BDInfo %s)\n", lbl.field("Generated by:", 16), generator(settings), reportProductVersion(settings))
+}
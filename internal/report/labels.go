@@ -0,0 +1,78 @@
+package report
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+// Community translation files live in labels/<lang>.json and map the English
+// label (as printed by the report) to its translation.
+//
+//go:embed labels/*.json
+var labelFiles embed.FS
+
+// labels translates section headers and field names in the non-forums parts
+// of the report. The forums paste stays English so tracker parsers keep working;
+// only the headings of the opt-in sections inside it are translated.
+type labels map[string]string
+
+// get returns the translation for key, falling back to the English label.
+func (l labels) get(key string) string {
+	if v, ok := l[key]; ok && v != "" {
+		return v
+	}
+	return key
+}
+
+// field pads a translated label to width, keeping at least one separating space.
+func (l labels) field(key string, width int) string {
+	label := l.get(key)
+	n := utf8.RuneCountInString(label)
+	if n >= width {
+		return label + " "
+	}
+	return label + strings.Repeat(" ", width-n)
+}
+
+func loadLabels(settings settings.Settings) (labels, error) {
+	if settings.ReportLabelsFile != "" {
+		data, err := os.ReadFile(settings.ReportLabelsFile)
+		if err != nil {
+			return nil, fmt.Errorf("read report labels: %w", err)
+		}
+		return parseLabels(data, settings.ReportLabelsFile)
+	}
+	lang := strings.ToLower(strings.TrimSpace(settings.ReportLanguage))
+	if lang == "" || lang == "en" {
+		return nil, nil
+	}
+	data, err := labelFiles.ReadFile("labels/" + lang + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("unsupported report language: %s (available: %s)", lang, strings.Join(reportLanguages(), ", "))
+	}
+	return parseLabels(data, lang)
+}
+
+func parseLabels(data []byte, source string) (labels, error) {
+	var l labels
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("parse report labels %s: %w", source, err)
+	}
+	return l, nil
+}
+
+// reportLanguages lists the built-in report label translations.
+func reportLanguages() []string {
+	langs := []string{"en"}
+	entries, _ := labelFiles.ReadDir("labels")
+	for _, entry := range entries {
+		langs = append(langs, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	return langs
+}
@@ -0,0 +1,255 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Lint severities: errors break tracker BDInfo validators, warnings are
+// layouts they usually tolerate.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintIssue is one deviation of a text report from the layout tracker
+// validators parse. Line is 1-based; 0 refers to the report as a whole.
+type LintIssue struct {
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", i.Severity, i.Rule, i.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s: %s", i.Line, i.Severity, i.Rule, i.Message)
+}
+
+// lintColumn is one fixed-width column of a report table; the last column of
+// a table is not padded to a width by validators, so its width is ignored.
+type lintColumn struct {
+	name  string
+	width int
+}
+
+// lintSection is a section of a playlist block with the table layout
+// validators split on, if it has a table.
+type lintSection struct {
+	name     string
+	required bool
+	columns  []lintColumn
+}
+
+// lintSections are the sections of a playlist block in the order BDInfo writes
+// them. Sections not listed here (CHAPTER NAMES, CLIP INFO, ...) are extensions
+// and only warned about.
+var lintSections = []lintSection{
+	{name: "DISC INFO:", required: true},
+	{name: "PLAYLIST REPORT:", required: true},
+	{name: "VIDEO:", columns: []lintColumn{{"Codec", 24}, {"Bitrate", 20}, {"Description", 16}}},
+	{name: "AUDIO:", columns: []lintColumn{{"Codec", 32}, {"Language", 16}, {"Bitrate", 16}, {"Description", 16}}},
+	{name: "SUBTITLES:", columns: []lintColumn{{"Codec", 32}, {"Language", 16}, {"Bitrate", 16}, {"Description", 16}}},
+	{name: "TEXT:", columns: []lintColumn{{"Codec", 32}, {"Language", 16}, {"Bitrate", 16}, {"Description", 16}}},
+	{name: "FILES:", required: true, columns: []lintColumn{{"Name", 16}, {"Time In", 16}, {"Length", 16}, {"Size", 16}, {"Total Bitrate", 16}}},
+	{name: "CHAPTERS:", required: true, columns: []lintColumn{
+		{"Number", 16}, {"Time In", 16}, {"Length", 16}, {"Avg Video Rate", 16}, {"Max 1-Sec Rate", 16},
+		{"Max 1-Sec Time", 16}, {"Max 5-Sec Rate", 16}, {"Max 5-Sec Time", 16}, {"Max 10Sec Rate", 16},
+		{"Max 10Sec Time", 16}, {"Avg Frame Size", 16}, {"Max Frame Size", 16}, {"Max Frame Time", 16},
+	}},
+	{name: "STREAM DIAGNOSTICS:", columns: []lintColumn{
+		{"File", 16}, {"PID", 16}, {"Type", 16}, {"Codec", 16}, {"Language", 24},
+		{"Seconds", 24}, {"Bitrate", 24}, {"Bytes", 16}, {"Packets", 16},
+	}},
+}
+
+// lintRequiredLines are the labelled lines validators read from DISC INFO and
+// PLAYLIST REPORT, with the width their labels are padded to.
+var lintRequiredLines = map[string]struct {
+	width  int
+	labels []string
+}{
+	"DISC INFO:":       {width: 16, labels: []string{"Disc Label:", "Disc Size:", "Protection:"}},
+	"PLAYLIST REPORT:": {width: 24, labels: []string{"Name:", "Length:", "Size:", "Total Bitrate:"}},
+}
+
+// Lint checks an English text report against the layout tracker BDInfo
+// validators expect: each playlist block has its sections in BDInfo order,
+// the labelled DISC INFO and PLAYLIST REPORT lines, and table headers with
+// the official column widths; forum paste markers and [code] tags pair up and
+// no line contains a tab. Issues are returned in line order.
+func Lint(text string) []LintIssue {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var issues []LintIssue
+	add := func(line int, severity, rule, format string, args ...any) {
+		issues = append(issues, LintIssue{Line: line, Severity: severity, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var (
+		inBlock     bool
+		blockStart  int
+		seen        []string
+		lastIndex   int
+		section     string
+		sectionLine int
+		labels      map[string]bool
+		columns     []lintColumn
+		tableState  int // 0: before header, 1: header seen, 2: rule seen (rows follow)
+		codeDepth   int
+		pasteOpen   int
+	)
+	endSection := func() {
+		want, ok := lintRequiredLines[section]
+		if !ok {
+			return
+		}
+		for _, label := range want.labels {
+			if !labels[label] {
+				add(sectionLine, LintError, "required-line", "%s has no %q line", strings.TrimSuffix(section, ":"), label)
+			}
+		}
+	}
+	endBlock := func() {
+		endSection()
+		for _, s := range lintSections {
+			if s.required && !slices.Contains(seen, s.name) {
+				add(blockStart, LintError, "required-section", "playlist block has no %s section", strings.TrimSuffix(s.name, ":"))
+			}
+		}
+	}
+
+	for i, line := range lines {
+		lineNo := i + 1
+		if strings.Contains(line, "\t") {
+			add(lineNo, LintError, "tab", "line contains a tab; validators split columns on spaces")
+		}
+		switch strings.TrimSpace(line) {
+		case "[code]":
+			codeDepth++
+		case "[/code]":
+			codeDepth--
+			if codeDepth < 0 {
+				add(lineNo, LintError, "code-tags", "[/code] without an opening [code]")
+				codeDepth = 0
+			}
+		case "<--- BEGIN FORUMS PASTE --->":
+			if pasteOpen > 0 {
+				add(lineNo, LintError, "forums-paste", "BEGIN FORUMS PASTE before the previous END")
+			}
+			pasteOpen = 1
+		case "<---- END FORUMS PASTE ---->":
+			if pasteOpen == 0 {
+				add(lineNo, LintError, "forums-paste", "END FORUMS PASTE without a BEGIN")
+			}
+			pasteOpen = 0
+		}
+
+		if isSectionHeader(line) {
+			if line == "DISC INFO:" {
+				if inBlock {
+					endBlock()
+				}
+				inBlock, blockStart, seen, lastIndex = true, lineNo, nil, -1
+			} else if !inBlock {
+				continue
+			} else {
+				endSection()
+			}
+			section, sectionLine, labels, columns, tableState = line, lineNo, map[string]bool{}, nil, 0
+			index := slices.IndexFunc(lintSections, func(s lintSection) bool { return s.name == line })
+			if index < 0 {
+				if line != "QUICK SUMMARY:" {
+					add(lineNo, LintWarning, "unknown-section", "%s is not a BDInfo section; validators may ignore or reject it", strings.TrimSuffix(line, ":"))
+				}
+				continue
+			}
+			if slices.Contains(seen, line) {
+				add(lineNo, LintError, "section-order", "%s appears twice in one playlist block", strings.TrimSuffix(line, ":"))
+			} else if index < lastIndex {
+				add(lineNo, LintError, "section-order", "%s must come before %s", strings.TrimSuffix(line, ":"), strings.TrimSuffix(lintSections[lastIndex].name, ":"))
+			}
+			seen = append(seen, line)
+			lastIndex = max(lastIndex, index)
+			columns = lintSections[index].columns
+			continue
+		}
+		if !inBlock || section == "" {
+			continue
+		}
+
+		if want, ok := lintRequiredLines[section]; ok {
+			for _, label := range want.labels {
+				if !strings.HasPrefix(strings.TrimSpace(line), label) {
+					continue
+				}
+				labels[label] = true
+				if !strings.HasPrefix(line, fmt.Sprintf("%-*s", want.width, label)) {
+					add(lineNo, LintError, "label-width", "%q label is not padded to %d columns", label, want.width)
+				}
+			}
+			continue
+		}
+
+		if len(columns) == 0 || strings.TrimSpace(line) == "" {
+			if tableState == 2 && strings.TrimSpace(line) == "" {
+				columns = nil
+			}
+			continue
+		}
+		switch tableState {
+		case 0:
+			if want := lintHeader(columns); strings.TrimRight(line, " ") != want {
+				add(lineNo, LintError, "table-header", "%s header is %q, want %q", strings.TrimSuffix(section, ":"), strings.TrimRight(line, " "), want)
+			}
+			tableState = 1
+		case 1:
+			tableState = 2
+		case 2:
+			start := 0
+			for _, col := range columns[:len(columns)-1] {
+				start += col.width
+				if len(line) > start && line[start-1] != ' ' {
+					add(lineNo, LintWarning, "column-overflow", "%s row overflows the %s column (%d characters)", strings.TrimSuffix(section, ":"), col.name, col.width)
+					break
+				}
+			}
+		}
+	}
+	if inBlock {
+		endBlock()
+	} else {
+		add(0, LintError, "required-section", "report has no DISC INFO section")
+	}
+	if codeDepth > 0 {
+		add(0, LintError, "code-tags", "%d [code] tag(s) not closed", codeDepth)
+	}
+	if pasteOpen > 0 {
+		add(0, LintError, "forums-paste", "BEGIN FORUMS PASTE without an END")
+	}
+
+	slices.SortStableFunc(issues, func(a, b LintIssue) int { return a.Line - b.Line })
+	return issues
+}
+
+// isSectionHeader reports an unindented upper-case line ending in a colon,
+// such as "AUDIO:" or "STREAM DIAGNOSTICS:".
+func isSectionHeader(line string) bool {
+	if len(line) < 2 || !strings.HasSuffix(line, ":") || line[0] == ' ' {
+		return false
+	}
+	name := strings.TrimSuffix(line, ":")
+	return strings.ToUpper(name) == name && strings.ContainsFunc(name, func(r rune) bool { return r >= 'A' && r <= 'Z' })
+}
+
+// lintHeader renders a table header row the way the report writes it, without
+// trailing padding.
+func lintHeader(columns []lintColumn) string {
+	var b strings.Builder
+	for _, col := range columns {
+		fmt.Fprintf(&b, "%-*s", col.width, col.name)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
@@ -0,0 +1,90 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func lintTestReport(t *testing.T) string {
+	t.Helper()
+	cfg := settings.Default(t.TempDir())
+	cfg.ReportFileName = "-"
+
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo, BitRate: 30_000_000}}
+	audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio, BitRate: 640_000, LanguageName: "English"}, ChannelCount: 5, LFE: 1}
+	audio.SetLanguageCode("eng")
+	clip := &bdrom.StreamClip{Name: "00001.M2TS", Length: 3600, PacketCount: 1000}
+	playlist := &bdrom.PlaylistFile{
+		Name:          "00800.MPLS",
+		Settings:      cfg,
+		StreamClips:   []*bdrom.StreamClip{clip},
+		Chapters:      []float64{0, 1800},
+		SortedStreams: []stream.Info{video, audio},
+		VideoStreams:  []*stream.VideoStream{video},
+		AudioStreams:  []*stream.AudioStream{audio},
+	}
+	bd := &bdrom.BDROM{VolumeLabel: "DISC", Size: 1234}
+
+	_, output, err := RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func TestLint_RenderedReport(t *testing.T) {
+	output := lintTestReport(t)
+	if issues := Lint(output); len(issues) != 0 {
+		t.Fatalf("Lint() of a rendered report got=%v\n%s", issues, output)
+	}
+}
+
+func TestLint_Deviations(t *testing.T) {
+	output := lintTestReport(t)
+	tests := []struct {
+		name string
+		edit func(string) string
+		rule string
+	}{
+		{name: "tab", edit: func(s string) string { return strings.Replace(s, "Disc Label:     ", "Disc Label:\t", 1) }, rule: "tab"},
+		{name: "label width", edit: func(s string) string {
+			return strings.Replace(s, "DISC INFO:\n\n\nDisc Label:     ", "DISC INFO:\n\n\nDisc Label: ", 1)
+		}, rule: "label-width"},
+		{name: "missing line", edit: func(s string) string { return strings.Replace(s, "Total Bitrate:", "Bitrate:", 1) }, rule: "required-line"},
+		{name: "missing section", edit: func(s string) string { return strings.Replace(s, "FILES:", "", 1) }, rule: "required-section"},
+		{name: "header width", edit: func(s string) string {
+			return strings.Replace(s, "Codec                   Bitrate", "Codec           Bitrate", 1)
+		}, rule: "table-header"},
+		{name: "section order", edit: func(s string) string {
+			s = strings.Replace(s, "VIDEO:", "@@", 1)
+			s = strings.Replace(s, "AUDIO:", "VIDEO:", 1)
+			return strings.Replace(s, "@@", "AUDIO:", 1)
+		}, rule: "section-order"},
+		{name: "code tags", edit: func(s string) string { return strings.Replace(s, "[/code]", "", 1) }, rule: "code-tags"},
+		{name: "unknown section", edit: func(s string) string { return strings.Replace(s, "FILES:", "EXTRAS:\n\nFILES:", 1) }, rule: "unknown-section"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edited := tt.edit(output)
+			if edited == output {
+				t.Fatal("edit did not change the report")
+			}
+			issues := Lint(edited)
+			found := false
+			for _, issue := range issues {
+				found = found || issue.Rule == tt.rule
+			}
+			if !found {
+				t.Fatalf("Lint() got=%v want a %q issue", issues, tt.rule)
+			}
+		})
+	}
+
+	if issues := Lint("not a report"); len(issues) != 1 || issues[0].Rule != "required-section" {
+		t.Fatalf("Lint() of a non-report got=%v", issues)
+	}
+}
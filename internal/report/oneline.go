@@ -0,0 +1,174 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/dvd"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+	"github.com/autobrr/go-bdinfo/internal/util"
+)
+
+// oneLineSeparator joins the fields of RenderOneLine.
+const oneLineSeparator = " | "
+
+// RenderOneLine summarizes a disc in one line for shell pipelines, from its
+// main playlist (the one --main picks):
+//
+//	LABEL | 2:14:05 | 38.50 GB | HEVC 2160p DV HDR10 | TrueHD Atmos 7.1 | eng,fra subs
+//
+// Video and audio describe the first stream of each kind the playlist does not
+// hide; HDR formats are gathered from all its video streams. The line has no
+// trailing newline. A disc without a main playlist yields the label and
+// "no main playlist".
+func RenderOneLine(bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, settings settings.Settings) string {
+	label := bd.VolumeLabel
+	if label == "" {
+		label = "-"
+	}
+	main, _ := MainPlaylistTies(slices.Clone(playlists), settings)
+	if main == nil {
+		return label + oneLineSeparator + "no main playlist"
+	}
+
+	fields := []string{
+		label,
+		util.FormatTime(main.TotalLength(), false),
+		util.FormatFileSize(float64(main.TotalSize()), true),
+		oneLineVideo(main),
+		oneLineAudio(main),
+		oneLineSubtitles(main),
+	}
+	return strings.Join(fields, oneLineSeparator)
+}
+
+func oneLineVideo(playlist *bdrom.PlaylistFile) string {
+	var parts, hdr []string
+	for _, v := range playlist.VideoStreams {
+		if v.IsHidden {
+			continue
+		}
+		if len(parts) == 0 {
+			parts = append(parts, v.CodecShortName())
+			if v.Height > 0 {
+				scan := "p"
+				if v.IsInterlaced {
+					scan = "i"
+				}
+				parts = append(parts, fmt.Sprintf("%d%s", v.Height, scan))
+			}
+		}
+		formats := v.ExtendedFormatInfo()
+		if ext, ok := v.ExtendedData.(*stream.HEVCExtendedData); ok && ext != nil && ext.DolbyVision != nil {
+			formats = append(slices.Clone(formats), "Dolby Vision")
+		}
+		for _, item := range formats {
+			format := item
+			switch {
+			case strings.HasPrefix(item, "Dolby Vision"):
+				format = "DV"
+			case item != "HDR10" && item != "HDR10+":
+				continue
+			}
+			switch {
+			case slices.Contains(hdr, format):
+			case format == "DV":
+				// Dolby Vision first, as release names write it (DV HDR10).
+				hdr = slices.Insert(hdr, 0, format)
+			default:
+				hdr = append(hdr, format)
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return "no video"
+	}
+	return strings.Join(append(parts, hdr...), " ")
+}
+
+func oneLineAudio(playlist *bdrom.PlaylistFile) string {
+	for _, a := range playlist.AudioStreams {
+		if a.IsHidden {
+			continue
+		}
+		codec := stream.CodecShortNameForInfo(a)
+		if a.HasExtensions {
+			switch a.StreamType {
+			case stream.StreamTypeAC3TrueHDAudio:
+				codec = "TrueHD Atmos"
+			case stream.StreamTypeAC3PlusAudio, stream.StreamTypeAC3PlusSecondaryAudio:
+				codec = "AC3+ Atmos"
+			}
+		}
+		if channels := a.ChannelDescription(); channels != "" {
+			codec += " " + channels
+		}
+		return codec
+	}
+	return "no audio"
+}
+
+func oneLineSubtitles(playlist *bdrom.PlaylistFile) string {
+	var languages []string
+	for _, g := range playlist.GraphicsStreams {
+		if g.IsHidden || g.StreamType != stream.StreamTypePresentationGraphics {
+			continue
+		}
+		code := g.LanguageCode()
+		if code == "" {
+			code = "und"
+		}
+		if !slices.Contains(languages, code) {
+			languages = append(languages, code)
+		}
+	}
+	if len(languages) == 0 {
+		return "no subs"
+	}
+	return strings.Join(languages, ",") + " subs"
+}
+
+// RenderDVDOneLine is RenderOneLine for a DVD-Video disc, from its longest
+// title.
+func RenderDVDOneLine(disc *dvd.Disc) string {
+	label := disc.VolumeLabel
+	if label == "" {
+		label = "-"
+	}
+	main := DVDTitles(disc, settings.Settings{MainPlaylistOnly: true})
+	if len(main) == 0 {
+		return label + oneLineSeparator + "no main playlist"
+	}
+	title := main[0]
+
+	audio := "no audio"
+	if len(title.Audio) > 0 {
+		audio = title.Audio[0].CodecShortName() + " " + title.Audio[0].ChannelDescription()
+	}
+	var languages []string
+	for _, sp := range title.Subpictures {
+		code := sp.LanguageCode
+		if code == "" {
+			code = "und"
+		}
+		if !slices.Contains(languages, code) {
+			languages = append(languages, code)
+		}
+	}
+	subs := "no subs"
+	if len(languages) > 0 {
+		subs = strings.Join(languages, ",") + " subs"
+	}
+	fields := []string{
+		label,
+		util.FormatTime(title.Length, false),
+		util.FormatFileSize(float64(title.Size), true),
+		fmt.Sprintf("%s %di", title.Video.CodecShortName(), title.Video.Height),
+		audio,
+		subs,
+	}
+	return strings.Join(fields, oneLineSeparator)
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+)
+
+// Playlist orderings accepted by Settings.PlaylistSort.
+const (
+	PlaylistSortSize    = "size"    // descending file size (BDInfo default)
+	PlaylistSortLength  = "length"  // descending playlist length
+	PlaylistSortName    = "name"    // ascending playlist name/number
+	PlaylistSortBitrate = "bitrate" // descending total bitrate
+)
+
+// ValidPlaylistSort reports whether key is a supported Settings.PlaylistSort value.
+func ValidPlaylistSort(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "", PlaylistSortSize, PlaylistSortLength, PlaylistSortName, PlaylistSortBitrate:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortPlaylists returns playlists in report order for key, a
+// Settings.PlaylistSort value, leaving the caller's slice untouched so renders
+// can share one scan. The sort is stable so
+// equal keys keep the disc's playlist order.
+func SortPlaylists(playlists []*bdrom.PlaylistFile, key string) []*bdrom.PlaylistFile {
+	playlists = slices.Clone(playlists)
+	var less func(a, b *bdrom.PlaylistFile) bool
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case PlaylistSortLength:
+		less = func(a, b *bdrom.PlaylistFile) bool { return a.TotalLength() > b.TotalLength() }
+	case PlaylistSortName:
+		less = func(a, b *bdrom.PlaylistFile) bool { return a.Name < b.Name }
+	case PlaylistSortBitrate:
+		less = func(a, b *bdrom.PlaylistFile) bool { return a.TotalBitRate() > b.TotalBitRate() }
+	default:
+		less = func(a, b *bdrom.PlaylistFile) bool { return a.FileSize() > b.FileSize() }
+	}
+	sort.SliceStable(playlists, func(i, j int) bool { return less(playlists[i], playlists[j]) })
+	return playlists
+}
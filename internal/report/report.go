@@ -2,12 +2,16 @@ package report
 
 import (
 	"fmt"
+	"maps"
 	"math"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/autobrr/go-bdinfo/internal/bdrom"
@@ -18,6 +22,15 @@ import (
 
 const productVersion = "0.8.0.0"
 
+// reportProductVersion returns the BDInfo version printed in reports, honoring
+// the ProductVersion override.
+func reportProductVersion(settings settings.Settings) string {
+	if v := strings.TrimSpace(settings.ProductVersion); v != "" {
+		return v
+	}
+	return productVersion
+}
+
 func WriteReport(path string, bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, scan bdrom.ScanResult, settings settings.Settings) (string, error) {
 	reportName, output, err := RenderReport(path, bd, playlists, scan, settings)
 	if err != nil {
@@ -36,15 +49,134 @@ func WriteReport(path string, bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile,
 		return reportName, err
 	}
 
-	return reportName, os.WriteFile(reportName, []byte(output), 0o644)
+	if err := os.WriteFile(reportName, []byte(output), 0o644); err != nil {
+		return reportName, err
+	}
+	if IsCSV(reportName) {
+		clips, err := RenderClipsCSV(playlists, settings)
+		if err != nil {
+			return reportName, err
+		}
+		return reportName, os.WriteFile(ClipsCSVPath(reportName), []byte(clips), 0o644)
+	}
+	return reportName, nil
 }
 
 func RenderReport(path string, bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, scan bdrom.ScanResult, settings settings.Settings) (string, string, error) {
+	reportName := reportFileName(path, bd.VolumeLabel, settings)
+
+	lbl, err := loadLabels(settings)
+	if err != nil {
+		return "", "", err
+	}
+	if !ValidTimeFormat(settings.TimeFormat) {
+		return "", "", fmt.Errorf("unsupported time format: %s", settings.TimeFormat)
+	}
+	if !ValidRoundingMode(settings.RoundingMode) {
+		return "", "", fmt.Errorf("unsupported rounding mode: %s", settings.RoundingMode)
+	}
+	if !ValidPlaylistSort(settings.PlaylistSort) {
+		return "", "", fmt.Errorf("unsupported playlist sort: %s", settings.PlaylistSort)
+	}
+
+	if settings.SummaryOnly {
+		output := buildSummaryOnly(bd, playlists, settings, lbl)
+		return reportName, appendGeneratedBy(output, settings, lbl), nil
+	}
+
+	if strings.EqualFold(filepath.Ext(reportName), ".xml") {
+		output, err := renderXML(bd, reportPlaylists(playlists, settings), scan, settings)
+		return reportName, output, err
+	}
+	if IsCSV(reportName) {
+		output, err := renderStreamsCSV(reportPlaylists(playlists, settings))
+		return reportName, output, err
+	}
+
+	var b strings.Builder
+	protection := discProtection(bd, settings)
+
+	if bd.DiscTitle != "" {
+		fmt.Fprintf(&b, "%s%s\n", lbl.field("Disc Title:", 16), bd.DiscTitle)
+	}
+	fmt.Fprintf(&b, "%s%s\n", lbl.field("Disc Label:", 16), bd.VolumeLabel)
+	fmt.Fprintf(&b, "%s%s %s\n", lbl.field("Disc Size:", 16), util.FormatNumber(int64(bd.Size)), lbl.get("bytes"))
+	fmt.Fprintf(&b, "%s%s\n", lbl.field("Protection:", 16), protection)
+
+	extra := discExtras(bd)
+	if len(extra) > 0 {
+		fmt.Fprintf(&b, "%s%s\n", lbl.field("Extras:", 16), strings.Join(extra, ", "))
+	}
+	fmt.Fprintf(&b, "%s%s\n\n\n", lbl.field("BDInfo:", 16), reportProductVersion(settings))
+
+	writeNotes(&b, settings, lbl)
+	if settings.IncludeTitleMap {
+		writeIndexTitles(&b, bd, lbl)
+	}
+	if settings.CollapseDuplicates {
+		writeDuplicatePlaylists(&b, playlists, lbl)
+	}
+	writeJARImages(&b, bd, lbl)
+
+	if scan.ScanError != nil {
+		fmt.Fprintf(&b, "%s %s\n", lbl.get("WARNING: Report is incomplete because:"), scan.ScanError.Error())
+	}
+	if len(scan.FileErrors) > 0 {
+		b.WriteString(lbl.get("WARNING: File errors were encountered during scan:") + "\n")
+		for _, name := range slices.Sorted(maps.Keys(scan.FileErrors)) {
+			// C# appends stack trace; Go errors generally don't include one.
+			fmt.Fprintf(&b, "\n%s\t%s\n", name, scan.FileErrors[name].Error())
+		}
+	}
+	writeStructureWarnings(&b, bd, lbl)
+	if len(scan.BackupFiles) > 0 {
+		b.WriteString(lbl.get("WARNING: Files were read from BDMV/BACKUP because the primary copy is unreadable:") + "\n")
+		for _, name := range slices.Sorted(maps.Keys(scan.BackupFiles)) {
+			fmt.Fprintf(&b, "\n%s\t%s\n", name, scan.BackupFiles[name].Error())
+		}
+	}
+
+	playlists = reportPlaylists(playlists, settings)
+	for _, block := range renderPlaylistBlocks(playlists, func(b *strings.Builder, playlist *bdrom.PlaylistFile) {
+		writePlaylistBlock(b, bd, playlist, settings, lbl, protection, extra)
+	}) {
+		b.WriteString(block)
+	}
+
+	output := b.String()
+	if settings.SummaryOnly {
+		output = extractQuickSummary(output, lbl.get("QUICK SUMMARY:"))
+	} else if settings.ForumsOnly {
+		output = extractForumsBlocks(output)
+	}
+	return reportName, appendGeneratedBy(output, settings, lbl), nil
+}
+
+// writeStructureWarnings lists the section addresses and lengths of playlist
+// and clip info files that do not fit the file, apart from the file errors:
+// such files may still have been read.
+func writeStructureWarnings(b *strings.Builder, bd *bdrom.BDROM, lbl labels) {
+	warnings := bdrom.StructureWarnings(bd)
+	if len(warnings) == 0 {
+		return
+	}
+	b.WriteString(lbl.get("WARNING: Structural anomalies were found in playlist and clip info files:") + "\n")
+	for _, warning := range warnings {
+		fmt.Fprintf(b, "\n%s\t%s\n", warning.File, warning.Message)
+	}
+}
+
+// reportFileName resolves settings.ReportFileName for the disc labelled label;
+// a non-empty path overrides it.
+func reportFileName(path, label string, settings settings.Settings) string {
+	if path != "" {
+		return path
+	}
 	reportName := settings.ReportFileName
 	if strings.Contains(reportName, "{0}") {
-		reportName = strings.ReplaceAll(reportName, "{0}", bd.VolumeLabel)
+		reportName = strings.ReplaceAll(reportName, "{0}", label)
 	} else if regexp.MustCompile(`\{\d+\}`).MatchString(reportName) {
-		reportName = fmt.Sprintf(reportName, bd.VolumeLabel)
+		reportName = fmt.Sprintf(reportName, label)
 	}
 
 	if reportName != "-" {
@@ -54,31 +186,77 @@ func RenderReport(path string, bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile,
 			reportName = reportName + ".txt"
 		}
 	}
+	return reportName
+}
 
-	if path != "" {
-		reportName = path
+// reportPlaylists applies the playlist selection and order settings, returning
+// the playlists that get a report block.
+func reportPlaylists(playlists []*bdrom.PlaylistFile, settings settings.Settings) []*bdrom.PlaylistFile {
+	if settings.CollapseDuplicates {
+		playlists = collapseDuplicatePlaylists(playlists)
+	}
+	if settings.MainPlaylistOnly || settings.BigPlaylistOnly {
+		playlists = selectMainPlaylist(playlists, settings)
+	} else if settings.TopPlaylists > 0 {
+		playlists = selectTopPlaylists(playlists, settings.TopPlaylists, settings)
 	}
 
-	if settings.SummaryOnly {
-		output := buildSummaryOnly(bd, playlists, settings)
-		return reportName, output, nil
+	playlists = SortPlaylists(playlists, settings.PlaylistSort)
+
+	if settings.FilterLoopingPlaylists {
+		playlists = slices.DeleteFunc(slices.Clone(playlists), func(playlist *bdrom.PlaylistFile) bool {
+			return !playlist.IsValid()
+		})
 	}
+	return playlists
+}
 
-	var b strings.Builder
+// collapseDuplicatePlaylists keeps one representative of each group of
+// duplicate playlists (see bdrom.GroupDuplicatePlaylists).
+func collapseDuplicatePlaylists(playlists []*bdrom.PlaylistFile) []*bdrom.PlaylistFile {
+	drop := map[string]bool{}
+	for _, group := range bdrom.GroupDuplicatePlaylists(playlists) {
+		for _, name := range group.Playlists {
+			drop[name] = name != group.Representative
+		}
+	}
+	if len(drop) == 0 {
+		return playlists
+	}
+	return slices.DeleteFunc(slices.Clone(playlists), func(playlist *bdrom.PlaylistFile) bool {
+		return playlist != nil && drop[playlist.Name]
+	})
+}
+
+// discProtection names the copy protection BDInfo reports for bd. With
+// IncludeProtectionDetails the AACS MKB version and BD+ content code date found
+// on the disc follow in parentheses, e.g. "BD+ (MKB v40, SVM 2012-05-07)".
+func discProtection(bd *bdrom.BDROM, settings settings.Settings) string {
 	protection := "AACS"
-	if bd.IsBDPlus {
+	switch {
+	case bd.IsBDPlus:
 		protection = "BD+"
-	} else if bd.IsUHD {
+	case bd.IsUHD:
 		protection = "AACS2"
 	}
-
-	if bd.DiscTitle != "" {
-		fmt.Fprintf(&b, "%-16s%s\n", "Disc Title:", bd.DiscTitle)
+	if !settings.IncludeProtectionDetails {
+		return protection
 	}
-	fmt.Fprintf(&b, "%-16s%s\n", "Disc Label:", bd.VolumeLabel)
-	fmt.Fprintf(&b, "%-16s%s bytes\n", "Disc Size:", util.FormatNumber(int64(bd.Size)))
-	fmt.Fprintf(&b, "%-16s%s\n", "Protection:", protection)
+	var details []string
+	if bd.MKBVersion > 0 {
+		details = append(details, fmt.Sprintf("MKB v%d", bd.MKBVersion))
+	}
+	if bd.BDPlusDate != "" {
+		details = append(details, "SVM "+bd.BDPlusDate)
+	}
+	if len(details) == 0 {
+		return protection
+	}
+	return protection + " (" + strings.Join(details, ", ") + ")"
+}
 
+// discExtras lists the disc features shown on the Extras line.
+func discExtras(bd *bdrom.BDROM) []string {
 	extra := []string{}
 	if bd.IsUHD {
 		extra = append(extra, "Ultra HD")
@@ -98,510 +276,622 @@ func RenderReport(path string, bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile,
 	if bd.IsPSP {
 		extra = append(extra, "PSP Digital Copy")
 	}
-	if len(extra) > 0 {
-		fmt.Fprintf(&b, "%-16s%s\n", "Extras:", strings.Join(extra, ", "))
-	}
-	fmt.Fprintf(&b, "%-16s%s\n\n\n", "BDInfo:", productVersion)
-
-	if settings.IncludeVersionAndNotes {
-		fmt.Fprintf(&b, "%-16s%s\n\n\n", "Notes:", "")
-		b.WriteString("BDINFO HOME:\n")
-		b.WriteString("  Cinema Squid (old)\n")
-		b.WriteString("    http://www.cinemasquid.com/blu-ray/tools/bdinfo\n")
-		b.WriteString("  UniqProject GitHub (new)\n")
-		b.WriteString("   https://github.com/UniqProject/BDInfo\n")
-		b.WriteString("\n")
-		b.WriteString("INCLUDES FORUMS REPORT FOR:\n")
-		b.WriteString("  AVS Forum Blu-ray Audio and Video Specifications Thread\n")
-		b.WriteString("    http://www.avsforum.com/avs-vb/showthread.php?t=1155731\n")
-		b.WriteString("\n\n")
-	}
-
-	if scan.ScanError != nil {
-		fmt.Fprintf(&b, "WARNING: Report is incomplete because: %s\n", scan.ScanError.Error())
-	}
-	if len(scan.FileErrors) > 0 {
-		b.WriteString("WARNING: File errors were encountered during scan:\n")
-		for name, err := range scan.FileErrors {
-			// C# appends stack trace; Go errors generally don't include one.
-			fmt.Fprintf(&b, "\n%s\t%s\n", name, err.Error())
-		}
+	if bd.IsBDAV {
+		extra = append(extra, "BDAV Recording")
 	}
+	return extra
+}
 
-	if settings.MainPlaylistOnly || settings.BigPlaylistOnly {
-		playlists = selectMainPlaylist(playlists, settings)
+// renderPlaylistBlocks renders each playlist into its own buffer, several at a
+// time on multi-core machines, and returns the blocks in playlist order.
+func renderPlaylistBlocks(playlists []*bdrom.PlaylistFile, render func(*strings.Builder, *bdrom.PlaylistFile)) []string {
+	blocks := make([]string, len(playlists))
+	sem := make(chan struct{}, max(runtime.NumCPU(), 1))
+	var wg sync.WaitGroup
+	for i, playlist := range playlists {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var b strings.Builder
+			render(&b, playlist)
+			blocks[i] = b.String()
+		}()
 	}
+	wg.Wait()
+	return blocks
+}
 
-	sort.SliceStable(playlists, func(i, j int) bool {
-		return playlists[i].FileSize() > playlists[j].FileSize()
-	})
-
+// writePlaylistBlock writes the full report block of one playlist: forums paste,
+// stream tables, files, chapters, diagnostics and the quick summary.
+func writePlaylistBlock(b *strings.Builder, bd *bdrom.BDROM, playlist *bdrom.PlaylistFile, settings settings.Settings, lbl labels, protection string, extra []string) {
 	separator := strings.Repeat("#", 10)
-	for _, playlist := range playlists {
-		if settings.FilterLoopingPlaylists && !playlist.IsValid() {
-			continue
-		}
-		var summary strings.Builder
+	var summary strings.Builder
+	rounding := newBitrateRounding(settings)
 
-		playlistLength := playlist.TotalLength()
-		totalLength := util.FormatTime(playlistLength, true)
-		totalLengthShort := util.FormatTime(playlistLength, false)
+	playlistLength := playlist.TotalLength()
+	totalLength := util.FormatTime(playlistLength, true)
+	totalLengthShort := util.FormatTime(playlistLength, false)
 
-		totalSize := playlist.TotalSize()
-		discSize := bd.Size
-		totalSizeStr := util.FormatNumber(int64(totalSize))
-		discSizeStr := util.FormatNumber(int64(discSize))
-		totalBitrate := formatMbps(playlist.TotalBitRate())
+	totalSize := playlist.TotalSize()
+	discSize := bd.Size
+	totalSizeStr := util.FormatNumber(int64(totalSize))
+	discSizeStr := util.FormatNumber(int64(discSize))
+	totalBitrate := rounding.mbps(playlist.TotalBitRate())
 
-		videoCodec := ""
-		videoBitrate := ""
-		if len(playlist.VideoStreams) > 0 {
-			vs := playlist.VideoStreams[0]
-			videoCodec = stream.CodecAltNameForInfo(vs)
-			videoBitrate = formatMbps(uint64(vs.BitRate))
-		}
+	videoCodec := ""
+	videoBitrate := ""
+	if len(playlist.VideoStreams) > 0 {
+		vs := playlist.VideoStreams[0]
+		videoCodec = stream.CodecAltNameForInfo(vs)
+		videoBitrate = rounding.mbps(uint64(vs.BitRate))
+	}
 
-		mainAudio := ""
-		secondaryAudio := ""
-		mainLang := ""
-		if len(playlist.AudioStreams) > 0 {
-			as := playlist.AudioStreams[0]
-			mainLang = as.LanguageCode()
-			mainAudio = fmt.Sprintf("%s %s", stream.CodecAltNameForInfo(as), as.ChannelDescription())
+	mainAudio := ""
+	secondaryAudio := ""
+	mainLang := ""
+	if len(playlist.AudioStreams) > 0 {
+		as := playlist.AudioStreams[0]
+		mainLang = as.LanguageCode()
+		mainAudio = fmt.Sprintf("%s %s", stream.CodecAltNameForInfo(as), as.ChannelDescription())
+		if as.BitRate > 0 {
+			mainAudio += fmt.Sprintf(" %dKbps", int(rounding.kbps(float64(as.BitRate))))
+		}
+		if as.SampleRate > 0 && as.BitDepth > 0 {
+			mainAudio += fmt.Sprintf(" (%dkHz/%d-bit)", as.SampleRate/1000, as.BitDepth)
+		}
+	}
+	if len(playlist.AudioStreams) > 1 {
+		for i := 1; i < len(playlist.AudioStreams); i++ {
+			as := playlist.AudioStreams[i]
+			if as.LanguageCode() != mainLang {
+				continue
+			}
+			if as.StreamType == stream.StreamTypeAC3PlusSecondaryAudio ||
+				as.StreamType == stream.StreamTypeDTSHDSecondaryAudio ||
+				(as.StreamType == stream.StreamTypeAC3Audio && as.ChannelCount == 2) {
+				continue
+			}
+			secondaryAudio = fmt.Sprintf("%s %s", stream.CodecAltNameForInfo(as), as.ChannelDescription())
 			if as.BitRate > 0 {
-				mainAudio += fmt.Sprintf(" %dKbps", int(math.RoundToEven(float64(as.BitRate)/1000)))
+				secondaryAudio += fmt.Sprintf(" %dKbps", int(rounding.kbps(float64(as.BitRate))))
 			}
 			if as.SampleRate > 0 && as.BitDepth > 0 {
-				mainAudio += fmt.Sprintf(" (%dkHz/%d-bit)", as.SampleRate/1000, as.BitDepth)
-			}
-		}
-		if len(playlist.AudioStreams) > 1 {
-			for i := 1; i < len(playlist.AudioStreams); i++ {
-				as := playlist.AudioStreams[i]
-				if as.LanguageCode() != mainLang {
-					continue
-				}
-				if as.StreamType == stream.StreamTypeAC3PlusSecondaryAudio ||
-					as.StreamType == stream.StreamTypeDTSHDSecondaryAudio ||
-					(as.StreamType == stream.StreamTypeAC3Audio && as.ChannelCount == 2) {
-					continue
-				}
-				secondaryAudio = fmt.Sprintf("%s %s", stream.CodecAltNameForInfo(as), as.ChannelDescription())
-				if as.BitRate > 0 {
-					secondaryAudio += fmt.Sprintf(" %dKbps", int(math.RoundToEven(float64(as.BitRate)/1000)))
-				}
-				if as.SampleRate > 0 && as.BitDepth > 0 {
-					secondaryAudio += fmt.Sprintf(" (%dkHz/%d-bit)", as.SampleRate/1000, as.BitDepth)
-				}
-				break
+				secondaryAudio += fmt.Sprintf(" (%dkHz/%d-bit)", as.SampleRate/1000, as.BitDepth)
 			}
+			break
 		}
+	}
 
-		b.WriteString("\n\n********************\n")
-		fmt.Fprintf(&b, "PLAYLIST: %s\n", playlist.Name)
-		b.WriteString("********************\n\n\n")
-		b.WriteString("<--- BEGIN FORUMS PASTE --->\n")
-		b.WriteString("[code]\n")
-		fmt.Fprintf(&b, "%-64s%-8s%-8s%-16s%-16s%-8s%-8s%-42s%s\n", "", "", "", "", "", "Total", "Video", "", "")
-		fmt.Fprintf(&b, "%-64s%-8s%-8s%-16s%-16s%-8s%-8s%-42s%s\n", "Title", "Codec", "Length", "Movie Size", "Disc Size", "Bitrate", "Bitrate", "Main Audio Track", "Secondary Audio Track")
-		fmt.Fprintf(&b, "%-64s%-8s%-8s%-16s%-16s%-8s%-8s%-42s%s\n", "-----", "------", "-------", "--------------", "--------------", "-------", "-------", "------------------", "---------------------")
-		fmt.Fprintf(&b, "%-64s%-8s%-8s%-16s%-16s%-8s%-8s%-42s%s\n", playlist.Name, videoCodec, totalLengthShort, totalSizeStr, discSizeStr, totalBitrate, videoBitrate, mainAudio, secondaryAudio)
-		b.WriteString("[/code]\n\n\n")
-		b.WriteString("[code]\n\n\n")
-		if settings.GroupByTime {
-			fmt.Fprintf(&b, "\n%sStart group %.0f%s\n", separator, playlistLength*1000, separator)
-		}
+	b.WriteString("\n\n********************\n")
+	fmt.Fprintf(b, "%s %s\n", lbl.get("PLAYLIST:"), playlist.Name)
+	b.WriteString("********************\n\n\n")
+	b.WriteString("<--- BEGIN FORUMS PASTE --->\n")
+	b.WriteString("[code]\n")
+	fmt.Fprintf(b, "%-64s%-8s%-8s%-16s%-16s%-8s%-8s%-42s%s\n", "", "", "", "", "", "Total", "Video", "", "")
+	fmt.Fprintf(b, "%-64s%-8s%-8s%-16s%-16s%-8s%-8s%-42s%s\n", "Title", "Codec", "Length", "Movie Size", "Disc Size", "Bitrate", "Bitrate", "Main Audio Track", "Secondary Audio Track")
+	fmt.Fprintf(b, "%-64s%-8s%-8s%-16s%-16s%-8s%-8s%-42s%s\n", "-----", "------", "-------", "--------------", "--------------", "-------", "-------", "------------------", "---------------------")
+	fmt.Fprintf(b, "%-64s%-8s%-8s%-16s%-16s%-8s%-8s%-42s%s\n", playlist.Name, videoCodec, totalLengthShort, totalSizeStr, discSizeStr, totalBitrate, videoBitrate, mainAudio, secondaryAudio)
+	b.WriteString("[/code]\n\n\n")
+	b.WriteString("[code]\n\n\n")
+	if settings.GroupByTime {
+		fmt.Fprintf(b, "\n%sStart group %.0f%s\n", separator, playlistLength*1000, separator)
+	}
 
-		b.WriteString("DISC INFO:\n\n\n")
-		if bd.DiscTitle != "" {
-			fmt.Fprintf(&b, "%-16s%s\n", "Disc Title:", bd.DiscTitle)
+	b.WriteString("DISC INFO:\n\n\n")
+	if bd.DiscTitle != "" {
+		fmt.Fprintf(b, "%-16s%s\n", "Disc Title:", bd.DiscTitle)
+	}
+	fmt.Fprintf(b, "%-16s%s\n", "Disc Label:", bd.VolumeLabel)
+	fmt.Fprintf(b, "%-16s%s bytes\n", "Disc Size:", util.FormatNumber(int64(bd.Size)))
+	fmt.Fprintf(b, "%-16s%s\n", "Protection:", protection)
+	if len(extra) > 0 {
+		fmt.Fprintf(b, "%-16s%s\n", "Extras:", strings.Join(extra, ", "))
+	}
+	// BDInfo prints the product version in every playlist block.
+	fmt.Fprintf(b, "%-16s%s\n\n\n", "BDInfo:", reportProductVersion(settings))
+
+	b.WriteString("PLAYLIST REPORT:\n\n\n")
+	fmt.Fprintf(b, "%-24s%s\n", "Name:", playlist.Name)
+	fmt.Fprintf(b, "%-24s%s (h:m:s.ms)\n", "Length:", totalLength)
+	fmt.Fprintf(b, "%-24s%s bytes\n", "Size:", totalSizeStr)
+	fmt.Fprintf(b, "%-24s%s Mbps\n", "Total Bitrate:", totalBitrate)
+	writeAngleTotals(b, playlist, rounding)
+
+	if playlist.HasHiddenTracks {
+		// Match official BDInfo: it inserts a CRLF line-break before the hidden-tracks note.
+		// The surrounding report uses LF; this specific CRLF is a quirk in the official output.
+		b.WriteString("\r\n(*) Indicates included stream hidden by this playlist.\n")
+	}
+
+	if len(playlist.VideoStreams) > 0 || settings.ShowEmptySections {
+		b.WriteString("\n\nVIDEO:\n\n\n")
+		table := newReportTable(settings.WideColumns, 24, 20, 16)
+		table.row("Codec", "Bitrate", "Description")
+		table.row("-----", "-------", "-----------")
+		if len(playlist.VideoStreams) == 0 {
+			table.row("No video streams", "", "")
 		}
-		fmt.Fprintf(&b, "%-16s%s\n", "Disc Label:", bd.VolumeLabel)
-		fmt.Fprintf(&b, "%-16s%s bytes\n", "Disc Size:", util.FormatNumber(int64(bd.Size)))
-		fmt.Fprintf(&b, "%-16s%s\n", "Protection:", protection)
-		if len(extra) > 0 {
-			fmt.Fprintf(&b, "%-16s%s\n", "Extras:", strings.Join(extra, ", "))
+		for _, st := range playlist.SortedStreams {
+			if !st.Base().IsVideoStream() {
+				continue
+			}
+			name := stream.CodecNameForInfo(st)
+			if st.Base().AngleIndex > 0 {
+				name = fmt.Sprintf("%s (%d)", name, st.Base().AngleIndex)
+			}
+			bitrate := fmt.Sprintf("%d", int(rounding.kbps(float64(st.Base().BitRate))))
+			if st.Base().AngleIndex > 0 {
+				bitrate = fmt.Sprintf("%s (%d)", bitrate, int(rounding.kbps(float64(st.Base().ActiveBitRate))))
+			}
+			bitrate = fmt.Sprintf("%s kbps", bitrate)
+			table.row(hiddenPrefix(st)+name, bitrate, st.Description())
+			if settings.GenerateTextSummary {
+				fmt.Fprintf(&summary, "%s%s %s / %s / %s\n", hiddenPrefix(st), lbl.get("Video:"), name, bitrate, st.Description())
+			}
 		}
-		// BDInfo prints the product version in every playlist block.
-		fmt.Fprintf(&b, "%-16s%s\n\n\n", "BDInfo:", productVersion)
-
-		b.WriteString("PLAYLIST REPORT:\n\n\n")
-		fmt.Fprintf(&b, "%-24s%s\n", "Name:", playlist.Name)
-		fmt.Fprintf(&b, "%-24s%s (h:m:s.ms)\n", "Length:", totalLength)
-		fmt.Fprintf(&b, "%-24s%s bytes\n", "Size:", totalSizeStr)
-		fmt.Fprintf(&b, "%-24s%s Mbps\n", "Total Bitrate:", totalBitrate)
+		table.write(b)
+	}
 
-		if playlist.HasHiddenTracks {
-			// Match official BDInfo: it inserts a CRLF line-break before the hidden-tracks note.
-			// The surrounding report uses LF; this specific CRLF is a quirk in the official output.
-			b.WriteString("\r\n(*) Indicates included stream hidden by this playlist.\n")
+	if len(playlist.AudioStreams) > 0 || settings.ShowEmptySections {
+		b.WriteString("\n\nAUDIO:\n\n\n")
+		table := newReportTable(settings.WideColumns, 32, 16, 16, 16)
+		table.row("Codec", "Language", "Bitrate", "Description")
+		table.row("-----", "--------", "-------", "-----------")
+		if len(playlist.AudioStreams) == 0 {
+			table.row("No audio streams", "", "", "")
 		}
-
-		if len(playlist.VideoStreams) > 0 {
-			b.WriteString("\n\nVIDEO:\n\n\n")
-			fmt.Fprintf(&b, "%-24s%-20s%-16s\n", "Codec", "Bitrate", "Description")
-			fmt.Fprintf(&b, "%-24s%-20s%-16s\n", "-----", "-------", "-----------")
-			for _, st := range playlist.SortedStreams {
-				if !st.Base().IsVideoStream() {
-					continue
-				}
-				name := stream.CodecNameForInfo(st)
-				if st.Base().AngleIndex > 0 {
-					name = fmt.Sprintf("%s (%d)", name, st.Base().AngleIndex)
-				}
-				bitrate := fmt.Sprintf("%d", int(math.RoundToEven(float64(st.Base().BitRate)/1000)))
-				if st.Base().AngleIndex > 0 {
-					bitrate = fmt.Sprintf("%s (%d)", bitrate, int(math.RoundToEven(float64(st.Base().ActiveBitRate)/1000)))
-				}
-				bitrate = fmt.Sprintf("%s kbps", bitrate)
-				fmt.Fprintf(&b, "%-24s%-20s%-16s\n", hiddenPrefix(st)+name, bitrate, st.Description())
-				if settings.GenerateTextSummary {
-					fmt.Fprintf(&summary, "%sVideo: %s / %s / %s\n", hiddenPrefix(st), name, bitrate, st.Description())
-				}
+		for _, st := range playlist.SortedStreams {
+			if !st.Base().IsAudioStream() {
+				continue
+			}
+			bitrate := fmt.Sprintf("%d kbps", int(rounding.kbps(float64(st.Base().BitRate))))
+			table.row(
+				hiddenPrefix(st)+stream.CodecNameForInfo(st),
+				st.Base().LanguageName,
+				bitrate,
+				st.Description(),
+			)
+			if settings.GenerateTextSummary {
+				fmt.Fprintf(&summary, "%s%s %s / %s / %s\n", hiddenPrefix(st), lbl.get("Audio:"), st.Base().LanguageName, stream.CodecNameForInfo(st), st.Description())
 			}
 		}
+		table.write(b)
+	}
 
-		if len(playlist.AudioStreams) > 0 {
-			b.WriteString("\n\nAUDIO:\n\n\n")
-			fmt.Fprintf(&b, "%-32s%-16s%-16s%-16s\n", "Codec", "Language", "Bitrate", "Description")
-			fmt.Fprintf(&b, "%-32s%-16s%-16s%-16s\n", "-----", "--------", "-------", "-----------")
-			for _, st := range playlist.SortedStreams {
-				if !st.Base().IsAudioStream() {
-					continue
-				}
-				bitrate := fmt.Sprintf("%d kbps", int(math.RoundToEven(float64(st.Base().BitRate)/1000)))
-				fmt.Fprintf(&b, "%-32s%-16s%-16s%-16s\n",
-					hiddenPrefix(st)+stream.CodecNameForInfo(st),
-					st.Base().LanguageName,
-					bitrate,
-					st.Description(),
-				)
-				if settings.GenerateTextSummary {
-					fmt.Fprintf(&summary, "%sAudio: %s / %s / %s\n", hiddenPrefix(st), st.Base().LanguageName, stream.CodecNameForInfo(st), st.Description())
-				}
+	if len(playlist.GraphicsStreams) > 0 || settings.ShowEmptySections {
+		b.WriteString("\n\nSUBTITLES:\n\n\n")
+		table := newReportTable(settings.WideColumns, 32, 16, 16, 16)
+		table.row("Codec", "Language", "Bitrate", "Description")
+		table.row("-----", "--------", "-------", "-----------")
+		if len(playlist.GraphicsStreams) == 0 {
+			table.row("No subtitles", "", "", "")
+		}
+		for _, st := range playlist.SortedStreams {
+			if !st.Base().IsGraphicsStream() {
+				continue
+			}
+			bitrate := fmt.Sprintf("%.3f kbps", float64(st.Base().BitRate)/1000.0)
+			table.row(
+				hiddenPrefix(st)+stream.CodecNameForInfo(st),
+				st.Base().LanguageName,
+				bitrate,
+				st.Description(),
+			)
+			if settings.GenerateTextSummary {
+				fmt.Fprintf(&summary, "%s%s %s / %s%s\n", hiddenPrefix(st), lbl.get("Subtitle:"), st.Base().LanguageName, bitrate, forcedOnlySuffix(st, settings))
 			}
 		}
+		table.write(b)
+	}
 
-		if len(playlist.GraphicsStreams) > 0 {
-			b.WriteString("\n\nSUBTITLES:\n\n\n")
-			fmt.Fprintf(&b, "%-32s%-16s%-16s%-16s\n", "Codec", "Language", "Bitrate", "Description")
-			fmt.Fprintf(&b, "%-32s%-16s%-16s%-16s\n", "-----", "--------", "-------", "-----------")
-			for _, st := range playlist.SortedStreams {
-				if !st.Base().IsGraphicsStream() {
-					continue
-				}
-				bitrate := fmt.Sprintf("%.3f kbps", float64(st.Base().BitRate)/1000.0)
-				fmt.Fprintf(&b, "%-32s%-16s%-16s%-16s\n",
-					hiddenPrefix(st)+stream.CodecNameForInfo(st),
-					st.Base().LanguageName,
-					bitrate,
-					st.Description(),
-				)
-				if settings.GenerateTextSummary {
-					fmt.Fprintf(&summary, "%sSubtitle: %s / %s\n", hiddenPrefix(st), st.Base().LanguageName, bitrate)
-				}
+	if len(playlist.TextStreams) > 0 {
+		b.WriteString("\n\nTEXT:\n\n\n")
+		table := newReportTable(settings.WideColumns, 32, 16, 16, 16)
+		table.row("Codec", "Language", "Bitrate", "Description")
+		table.row("-----", "--------", "-------", "-----------")
+		for _, st := range playlist.SortedStreams {
+			if !st.Base().IsTextStream() {
+				continue
 			}
+			bitrate := fmt.Sprintf("%.3f kbps", float64(st.Base().BitRate)/1000.0)
+			table.row(
+				hiddenPrefix(st)+stream.CodecNameForInfo(st),
+				st.Base().LanguageName,
+				bitrate,
+				st.Description(),
+			)
 		}
+		table.write(b)
+	}
 
-		if len(playlist.TextStreams) > 0 {
-			b.WriteString("\n\nTEXT:\n\n\n")
-			fmt.Fprintf(&b, "%-32s%-16s%-16s%-16s\n", "Codec", "Language", "Bitrate", "Description")
-			fmt.Fprintf(&b, "%-32s%-16s%-16s%-16s\n", "-----", "--------", "-------", "-----------")
-			for _, st := range playlist.SortedStreams {
-				if !st.Base().IsTextStream() {
-					continue
-				}
-				bitrate := fmt.Sprintf("%.3f kbps", float64(st.Base().BitRate)/1000.0)
-				fmt.Fprintf(&b, "%-32s%-16s%-16s%-16s\n",
-					hiddenPrefix(st)+stream.CodecNameForInfo(st),
-					st.Base().LanguageName,
-					bitrate,
-					st.Description(),
-				)
-			}
+	if len(playlist.OtherStreams) > 0 {
+		b.WriteString("\n\nOTHER:\n\n\n")
+		table := newReportTable(settings.WideColumns, 32, 16, 16)
+		table.row("Codec", "PID", "Bitrate")
+		table.row("-----", "---", "-------")
+		for _, st := range playlist.OtherStreams {
+			bitrate := fmt.Sprintf("%d kbps", int(rounding.kbps(float64(st.Base().BitRate))))
+			table.row(
+				hiddenPrefix(st)+stream.OtherCodecName(st.Base().StreamType),
+				fmt.Sprintf("%d (0x%X)", st.Base().PID, st.Base().PID),
+				bitrate,
+			)
 		}
+		table.write(b)
+	}
 
-		b.WriteString("\n\nFILES:\n\n\n")
-		fmt.Fprintf(&b, "%-16s%-16s%-16s%-16s%-16s\n", "Name", "Time In", "Length", "Size", "Total Bitrate")
-		fmt.Fprintf(&b, "%-16s%-16s%-16s%-16s%-16s\n", "----", "-------", "------", "----", "-------------")
+	times := newTimeFormatter(settings, playlist)
+	b.WriteString("\n\nFILES:\n\n\n")
+	fmt.Fprintf(b, "%-16s%-16s%-16s%-16s%-16s\n", "Name", "Time In", "Length", "Size", "Total Bitrate")
+	fmt.Fprintf(b, "%-16s%-16s%-16s%-16s%-16s\n", "----", "-------", "------", "----", "-------------")
+	if len(playlist.StreamClips) == 0 && settings.ShowEmptySections {
+		fmt.Fprintf(b, "%-16s%-16s%-16s%-16s%-16s\n", "No stream files", "", "", "", "")
+	}
+	for _, clip := range playlist.StreamClips {
+		clipName := clip.DisplayName()
+		if clip.AngleIndex > 0 {
+			clipName = fmt.Sprintf("%s (%d)", clipName, clip.AngleIndex)
+		}
+		length := times.formatTime(clip.Length, false)
+		timeIn := times.formatTime(clip.RelativeTimeIn, false)
+		clipSize := util.FormatNumber(int64(clip.PacketSize()))
+		bitrate := util.FormatNumber(rounding.kbps(float64(clip.PacketBitRate())))
+		fmt.Fprintf(b, "%-16s%-16s%-16s%-16s%-16s\n", clipName, timeIn, length, clipSize, bitrate)
+	}
+
+	if settings.GroupByTime {
+		b.WriteString("\n")
+		fmt.Fprintf(b, "%sEnd group%s\n\n\n", separator, separator)
+	}
+
+	// Match official BDInfo: always print the CHAPTERS section (even when empty).
+	b.WriteString("\n\nCHAPTERS:\n\n\n")
+	fmt.Fprintf(b, "%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s\n",
+		"Number",
+		"Time In",
+		"Length",
+		"Avg Video Rate",
+		"Max 1-Sec Rate",
+		"Max 1-Sec Time",
+		"Max 5-Sec Rate",
+		"Max 5-Sec Time",
+		"Max 10Sec Rate",
+		"Max 10Sec Time",
+		"Avg Frame Size",
+		"Max Frame Size",
+		"Max Frame Time",
+	)
+	fmt.Fprintf(b, "%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s\n",
+		"------",
+		"-------",
+		"------",
+		"--------------",
+		"--------------",
+		"--------------",
+		"--------------",
+		"--------------",
+		"--------------",
+		"--------------",
+		"--------------",
+		"--------------",
+		"--------------",
+	)
+	if len(playlist.Chapters) == 0 && settings.ShowEmptySections {
+		fmt.Fprintf(b, "%-16s\n", "No chapters")
+	}
+	writeChapters(b, playlist, times, rounding)
+	if settings.IncludeChapterNames && len(playlist.ChapterNames) > 0 {
+		writeChapterNames(b, playlist, times, lbl)
+	}
+	if len(playlist.AudioSegments) > 0 {
+		writeAudioSegments(b, playlist, lbl)
+	}
+	if settings.SubtitleSummary && len(playlist.GraphicsStreams) > 0 {
+		writeSubtitleSummary(b, playlist, lbl)
+	}
+	if settings.IncludeRestrictions {
+		writeRestrictions(b, playlist, lbl)
+	}
+	if settings.Include3DOffsets {
+		write3DOffsets(b, playlist, lbl)
+	}
+	if settings.Include3DBitrates {
+		write3DBitrates(b, playlist, rounding, lbl)
+	}
+	if settings.IncludeBitrateGraph {
+		writeBitrateGraph(b, playlist, rounding, lbl)
+	}
+	if settings.ExtendedStreamDiagnostics && playlist.HasHiddenTracks {
+		writeHiddenStreams(b, playlist, lbl)
+	}
+	if settings.ExtendedStreamDiagnostics {
+		writeClipInfo(b, playlist, lbl)
+		writeDeclaredRates(b, playlist, rounding, lbl)
+	}
+	if settings.TrackUnknownPIDs {
+		writeUnknownPIDs(b, playlist, lbl)
+	}
+
+	if settings.GenerateStreamDiagnostics {
+		b.WriteString("\n\nSTREAM DIAGNOSTICS:\n\n\n")
+		table := newReportTable(settings.WideColumns, 16, 16, 16, 16, 24, 24, 24, 16, 16)
+		table.row("File", "PID", "Type", "Codec", "Language", "Seconds", "Bitrate", "Bytes", "Packets")
+		table.row("----", "---", "----", "-----", "--------", "--------------", "--------------", "-------------", "-----")
+
+		reported := map[string]bool{}
 		for _, clip := range playlist.StreamClips {
+			if clip.StreamFile == nil {
+				continue
+			}
+			if reported[clip.Name] {
+				continue
+			}
+			reported[clip.Name] = true
+
 			clipName := clip.DisplayName()
 			if clip.AngleIndex > 0 {
 				clipName = fmt.Sprintf("%s (%d)", clipName, clip.AngleIndex)
 			}
-			length := util.FormatTime(clip.Length, true)
-			timeIn := util.FormatTime(clip.RelativeTimeIn, true)
-			clipSize := util.FormatNumber(int64(clip.PacketSize()))
-			bitrate := util.FormatNumber(int64(math.RoundToEven(float64(clip.PacketBitRate()) / 1000)))
-			fmt.Fprintf(&b, "%-16s%-16s%-16s%-16s%-16s\n", clipName, timeIn, length, clipSize, bitrate)
-		}
-
-		if settings.GroupByTime {
-			b.WriteString("\n")
-			fmt.Fprintf(&b, "%sEnd group%s\n\n\n", separator, separator)
-		}
-
-		// Match official BDInfo: always print the CHAPTERS section (even when empty).
-		b.WriteString("\n\nCHAPTERS:\n\n\n")
-		fmt.Fprintf(&b, "%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s\n",
-			"Number",
-			"Time In",
-			"Length",
-			"Avg Video Rate",
-			"Max 1-Sec Rate",
-			"Max 1-Sec Time",
-			"Max 5-Sec Rate",
-			"Max 5-Sec Time",
-			"Max 10Sec Rate",
-			"Max 10Sec Time",
-			"Avg Frame Size",
-			"Max Frame Size",
-			"Max Frame Time",
-		)
-		fmt.Fprintf(&b, "%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s\n",
-			"------",
-			"-------",
-			"------",
-			"--------------",
-			"--------------",
-			"--------------",
-			"--------------",
-			"--------------",
-			"--------------",
-			"--------------",
-			"--------------",
-			"--------------",
-			"--------------",
-		)
-		writeChapters(&b, playlist)
-
-		if settings.GenerateStreamDiagnostics {
-			b.WriteString("\n\nSTREAM DIAGNOSTICS:\n\n\n")
-			fmt.Fprintf(&b, "%-16s%-16s%-16s%-16s%-24s%-24s%-24s%-16s%-16s\n",
-				"File", "PID", "Type", "Codec", "Language", "Seconds", "Bitrate", "Bytes", "Packets")
-			fmt.Fprintf(&b, "%-16s%-16s%-16s%-16s%-24s%-24s%-24s%-16s%-16s\n",
-				"----", "---", "----", "-----", "--------", "--------------", "--------------", "-------------", "-----")
-
-			reported := map[string]bool{}
-			for _, clip := range playlist.StreamClips {
-				if clip.StreamFile == nil {
-					continue
-				}
-				if reported[clip.Name] {
-					continue
-				}
-				reported[clip.Name] = true
-
-				clipName := clip.DisplayName()
-				if clip.AngleIndex > 0 {
-					clipName = fmt.Sprintf("%s (%d)", clipName, clip.AngleIndex)
-				}
 
-				// Match official BDInfo ordering: when stream insertion order is known, use it directly.
-				// Fallback to deterministic kind/PID ordering.
-				pids := make([]uint16, 0, len(clip.StreamFile.Streams))
-				hasStreamOrder := len(clip.StreamFile.StreamOrder) > 0
-				if hasStreamOrder {
-					for _, pid := range clip.StreamFile.StreamOrder {
-						clipStream := clip.StreamFile.Streams[pid]
-						if clipStream == nil {
-							continue
-						}
-						if _, ok := playlist.Streams[pid]; !ok {
-							continue
-						}
-						pids = append(pids, pid)
+			// Match official BDInfo ordering: when stream insertion order is known, use it directly.
+			// Fallback to deterministic kind/PID ordering.
+			pids := make([]uint16, 0, len(clip.StreamFile.Streams))
+			hasStreamOrder := len(clip.StreamFile.StreamOrder) > 0
+			if hasStreamOrder {
+				for _, pid := range clip.StreamFile.StreamOrder {
+					clipStream := clip.StreamFile.Streams[pid]
+					if clipStream == nil {
+						continue
 					}
-				} else {
-					for pid, clipStream := range clip.StreamFile.Streams {
-						if clipStream == nil {
-							continue
-						}
-						if _, ok := playlist.Streams[pid]; !ok {
-							continue
-						}
-						pids = append(pids, pid)
+					if _, ok := playlist.Streams[pid]; !ok {
+						continue
 					}
+					pids = append(pids, pid)
 				}
-				streamWeight := func(pid uint16) int {
-					if playlistStream := playlist.Streams[pid]; playlistStream != nil {
-						base := playlistStream.Base()
-						if base.IsVideoStream() && base.IsHidden {
-							return 5
-						}
+			} else {
+				for _, pid := range stream.SortedPIDs(clip.StreamFile.Streams) {
+					if clip.StreamFile.Streams[pid] == nil {
+						continue
 					}
-					info := clip.StreamFile.Streams[pid]
-					if info == nil {
-						return 9
+					if _, ok := playlist.Streams[pid]; !ok {
+						continue
 					}
-					base := info.Base()
-					switch {
-					case base.IsVideoStream():
-						return 0
-					case base.IsAudioStream():
-						return 1
-					case base.IsGraphicsStream():
-						return 2
-					case base.IsTextStream():
-						return 3
-					default:
-						return 4
+					pids = append(pids, pid)
+				}
+			}
+			streamWeight := func(pid uint16) int {
+				if playlistStream := playlist.Streams[pid]; playlistStream != nil {
+					base := playlistStream.Base()
+					if base.IsVideoStream() && base.IsHidden {
+						return 5
 					}
 				}
-				if !hasStreamOrder {
-					sort.Slice(pids, func(i, j int) bool {
-						wi := streamWeight(pids[i])
-						wj := streamWeight(pids[j])
-						if wi != wj {
-							return wi < wj
-						}
-						return pids[i] < pids[j]
-					})
+				info := clip.StreamFile.Streams[pid]
+				if info == nil {
+					return 9
 				}
-
-				for _, pid := range pids {
-					clipStream := clip.StreamFile.Streams[pid]
-					if clipStream == nil {
-						continue
+				base := info.Base()
+				switch {
+				case base.IsVideoStream():
+					return 0
+				case base.IsAudioStream():
+					return 1
+				case base.IsGraphicsStream():
+					return 2
+				case base.IsTextStream():
+					return 3
+				default:
+					return 4
+				}
+			}
+			if !hasStreamOrder {
+				sort.Slice(pids, func(i, j int) bool {
+					wi := streamWeight(pids[i])
+					wj := streamWeight(pids[j])
+					if wi != wj {
+						return wi < wj
 					}
+					return pids[i] < pids[j]
+				})
+			}
 
-					clipSeconds := "0"
-					clipBitRate := "0"
-					if clip.StreamFile.Length > 0 {
-						seconds := clip.StreamFile.Length
-						clipSeconds = fmt.Sprintf("%.3f", seconds)
-						clipBitRate = util.FormatNumber(int64(math.RoundToEven(float64(clipStream.Base().PayloadBytes) * 8 / seconds / 1000)))
-					}
+			for _, pid := range pids {
+				clipStream := clip.StreamFile.Streams[pid]
+				if clipStream == nil {
+					continue
+				}
 
-					language := ""
-					if playlistStream := playlist.Streams[pid]; playlistStream != nil {
-						if code := playlistStream.Base().LanguageCode(); code != "" {
-							language = fmt.Sprintf("%s (%s)", code, playlistStream.Base().LanguageName)
-						}
+				clipSeconds := "0"
+				clipBitRate := "0"
+				if clip.StreamFile.Length > 0 {
+					seconds := clip.StreamFile.Length
+					clipSeconds = fmt.Sprintf("%.3f", seconds)
+					clipBitRate = util.FormatNumber(rounding.kbps(float64(clipStream.Base().PayloadBytes) * 8 / seconds))
+				}
+
+				language := ""
+				if playlistStream := playlist.Streams[pid]; playlistStream != nil {
+					if code := playlistStream.Base().LanguageCode(); code != "" {
+						language = fmt.Sprintf("%s (%s)", code, playlistStream.Base().LanguageName)
 					}
+				}
 
-					fmt.Fprintf(&b, "%-16s%-16s%-16s%-16s%-24s%-24s%-24s%-16s%-16s\n",
-						clipName,
-						fmt.Sprintf("%d (0x%X)", clipStream.Base().PID, clipStream.Base().PID),
-						fmt.Sprintf("0x%02X", byte(clipStream.Base().StreamType)),
-						stream.CodecShortNameForInfo(clipStream),
-						language,
-						clipSeconds,
-						clipBitRate,
-						util.FormatNumber(int64(clipStream.Base().PayloadBytes)),
-						util.FormatNumber(int64(clipStream.Base().PacketCount)),
-					)
+				table.row(
+					clipName,
+					fmt.Sprintf("%d (0x%X)", clipStream.Base().PID, clipStream.Base().PID),
+					fmt.Sprintf("0x%02X", byte(clipStream.Base().StreamType)),
+					stream.CodecShortNameForInfo(clipStream),
+					language,
+					clipSeconds,
+					clipBitRate,
+					util.FormatNumber(int64(clipStream.Base().PayloadBytes)),
+					util.FormatNumber(int64(clipStream.Base().PacketCount)),
+				)
+				if audio, ok := clipStream.(*stream.AudioStream); ok && settings.ExtendedStreamDiagnostics && clip.StreamFile.Length > 0 {
+					writeCoreDiagnostics(table, audio, language, clip.StreamFile.Length, rounding)
 				}
 			}
 		}
+		table.write(b)
+	}
 
-		b.WriteString("\n\n[/code]\n<---- END FORUMS PASTE ---->\n\n\n")
+	b.WriteString("\n\n[/code]\n<---- END FORUMS PASTE ---->\n\n\n")
 
-		if settings.GenerateTextSummary {
-			b.WriteString("QUICK SUMMARY:\n\n\n")
-			if bd.DiscTitle != "" {
-				fmt.Fprintf(&b, "Disc Title: %s\n", bd.DiscTitle)
-			}
-			fmt.Fprintf(&b, "Disc Label: %s\n", bd.VolumeLabel)
-			fmt.Fprintf(&b, "Disc Size: %s bytes\n", util.FormatNumber(int64(bd.Size)))
-			fmt.Fprintf(&b, "Protection: %s\n", protection)
-			fmt.Fprintf(&b, "Playlist: %s\n", playlist.Name)
-			fmt.Fprintf(&b, "Size: %s bytes\n", totalSizeStr)
-			fmt.Fprintf(&b, "Length: %s\n", totalLength)
-			fmt.Fprintf(&b, "Total Bitrate: %s Mbps\n", totalBitrate)
-			if summary.Len() > 0 {
-				b.WriteString(summary.String())
-			}
-			b.WriteString("\n\n\n\n\n")
+	if settings.GenerateTextSummary {
+		b.WriteString(lbl.get("QUICK SUMMARY:") + "\n\n\n")
+		if bd.DiscTitle != "" {
+			fmt.Fprintf(b, "%s %s\n", lbl.get("Disc Title:"), bd.DiscTitle)
 		}
+		fmt.Fprintf(b, "%s %s\n", lbl.get("Disc Label:"), bd.VolumeLabel)
+		fmt.Fprintf(b, "%s %s %s\n", lbl.get("Disc Size:"), util.FormatNumber(int64(bd.Size)), lbl.get("bytes"))
+		fmt.Fprintf(b, "%s %s\n", lbl.get("Protection:"), protection)
+		fmt.Fprintf(b, "%s %s\n", lbl.get("Playlist:"), playlist.Name)
+		fmt.Fprintf(b, "%s %s %s\n", lbl.get("Size:"), totalSizeStr, lbl.get("bytes"))
+		fmt.Fprintf(b, "%s %s\n", lbl.get("Length:"), totalLength)
+		fmt.Fprintf(b, "%s %s Mbps\n", lbl.get("Total Bitrate:"), totalBitrate)
+		if summary.Len() > 0 {
+			b.WriteString(summary.String())
+		}
+		b.WriteString("\n\n\n\n\n")
 	}
-
-	output := b.String()
-	if settings.SummaryOnly {
-		output = extractQuickSummary(output)
-	} else if settings.ForumsOnly {
-		output = extractForumsBlocks(output)
-	}
-	return reportName, output, nil
 }
 
 func selectMainPlaylist(playlists []*bdrom.PlaylistFile, settings settings.Settings) []*bdrom.PlaylistFile {
 	if len(playlists) == 0 {
 		return playlists
 	}
-	candidates := playlists
-	if settings.FilterLoopingPlaylists || settings.FilterShortPlaylists {
-		filtered := make([]*bdrom.PlaylistFile, 0, len(playlists))
-		for _, p := range playlists {
-			if p == nil {
-				continue
-			}
-			if !p.IsValid() {
-				continue
-			}
-			filtered = append(filtered, p)
-		}
-		if len(filtered) > 0 {
-			candidates = filtered
-		}
-	}
+	candidates := mainCandidates(playlists, settings)
 	main := candidates[0]
 	for _, p := range candidates[1:] {
 		if p == nil {
 			continue
 		}
-
-		// Official BDInfo `--printonlybigplaylist`: pick by size (fallback to name).
-		if settings.BigPlaylistOnly {
-			mainSize := main.TotalSize()
-			pSize := p.TotalSize()
-			if pSize > mainSize {
-				main = p
-				continue
-			}
-			if pSize < mainSize {
-				continue
-			}
-			if p.Name < main.Name {
-				main = p
-			}
-			continue
-		}
-
-		// `--main`: heuristic main feature selection. Prefer longest, then largest.
-		mainLen := main.TotalLength()
-		pLen := p.TotalLength()
-		if pLen > mainLen {
+		cmp := compareMainCandidates(p, main, settings)
+		if cmp > 0 || (cmp == 0 && p.Name < main.Name) {
 			main = p
-			continue
-		}
-		if pLen < mainLen {
-			continue
 		}
-		mainSize := main.TotalSize()
-		pSize := p.TotalSize()
-		if pSize > mainSize {
-			main = p
-			continue
+	}
+	return []*bdrom.PlaylistFile{main}
+}
+
+// mainCandidates drops invalid playlists when filtering is on, and playlists
+// no index.bdmv entry plays (except for --printonlybigplaylist, which follows
+// official BDInfo), unless a step would leave nothing to choose from.
+func mainCandidates(playlists []*bdrom.PlaylistFile, settings settings.Settings) []*bdrom.PlaylistFile {
+	candidates := playlists
+	if settings.FilterLoopingPlaylists || settings.FilterShortPlaylists {
+		candidates = keepPlaylists(candidates, (*bdrom.PlaylistFile).IsValid)
+	}
+	if !settings.BigPlaylistOnly {
+		candidates = keepPlaylists(candidates, func(p *bdrom.PlaylistFile) bool { return !p.Orphaned })
+	}
+	return candidates
+}
+
+// keepPlaylists returns the non-nil playlists keep accepts, or playlists
+// unchanged when it accepts none.
+func keepPlaylists(playlists []*bdrom.PlaylistFile, keep func(*bdrom.PlaylistFile) bool) []*bdrom.PlaylistFile {
+	filtered := make([]*bdrom.PlaylistFile, 0, len(playlists))
+	for _, p := range playlists {
+		if p != nil && keep(p) {
+			filtered = append(filtered, p)
 		}
-		if pSize < mainSize {
+	}
+	if len(filtered) == 0 {
+		return playlists
+	}
+	return filtered
+}
+
+// selectTopPlaylists keeps the n best playlists ranked like --main (or by size
+// with --printonlybigplaylist). The report then orders them by PlaylistSort.
+func selectTopPlaylists(playlists []*bdrom.PlaylistFile, n int, settings settings.Settings) []*bdrom.PlaylistFile {
+	if n <= 0 || len(playlists) == 0 {
+		return playlists
+	}
+	candidates := slices.Clone(mainCandidates(playlists, settings))
+	candidates = slices.DeleteFunc(candidates, func(p *bdrom.PlaylistFile) bool { return p == nil })
+	sort.SliceStable(candidates, func(i, j int) bool {
+		cmp := compareMainCandidates(candidates[i], candidates[j], settings)
+		return cmp > 0 || (cmp == 0 && candidates[i].Name < candidates[j].Name)
+	})
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// compareMainCandidates ranks two playlists for main selection, ignoring the
+// final name tie-break: >0 when a is preferred, <0 when b is, 0 on a tie.
+func compareMainCandidates(a, b *bdrom.PlaylistFile, settings settings.Settings) int {
+	// Official BDInfo `--printonlybigplaylist`: pick by size (fallback to name).
+	if settings.BigPlaylistOnly {
+		return cmpOrdered(a.TotalSize(), b.TotalSize())
+	}
+	// `--main`: heuristic main feature selection. Prefer longest, then largest, then highest bitrate.
+	if c := cmpOrdered(a.TotalLength(), b.TotalLength()); c != 0 {
+		return c
+	}
+	if c := cmpOrdered(a.TotalSize(), b.TotalSize()); c != 0 {
+		return c
+	}
+	if c := cmpOrdered(a.TotalBitRate(), b.TotalBitRate()); c != 0 {
+		return c
+	}
+	// index.bdmv: on an otherwise exact tie, prefer the playlist Title 1 plays.
+	return cmpBool(a.ReachableFromTitle1, b.ReachableFromTitle1)
+}
+
+func cmpBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func cmpOrdered[T uint64 | float64](a, b T) int {
+	switch {
+	case a > b:
+		return 1
+	case a < b:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// MainPlaylistTies returns the playlist picked by --main (or --printonlybigplaylist)
+// and the names of other candidates that matched it on every ranking metric, i.e.
+// where only the playlist-name tie-break decided the selection.
+func MainPlaylistTies(playlists []*bdrom.PlaylistFile, settings settings.Settings) (*bdrom.PlaylistFile, []string) {
+	selected := selectMainPlaylist(playlists, settings)
+	if len(selected) != 1 || selected[0] == nil {
+		return nil, nil
+	}
+	main := selected[0]
+	var ties []string
+	for _, p := range playlists {
+		if p == nil || p == main {
 			continue
 		}
-
-		mainBitrate := main.TotalBitRate()
-		pBitrate := p.TotalBitRate()
-		if pBitrate > mainBitrate {
-			main = p
+		if (settings.FilterLoopingPlaylists || settings.FilterShortPlaylists) && !p.IsValid() && main.IsValid() {
 			continue
 		}
-		if pBitrate < mainBitrate {
+		if !settings.BigPlaylistOnly && p.Orphaned && !main.Orphaned {
 			continue
 		}
-		if p.Name < main.Name {
-			main = p
+		if compareMainCandidates(p, main, settings) == 0 {
+			ties = append(ties, p.Name)
 		}
 	}
-	return []*bdrom.PlaylistFile{main}
+	sort.Strings(ties)
+	return main, ties
 }
 
 func extractForumsBlocks(report string) string {
@@ -633,8 +923,7 @@ func extractForumsBlocks(report string) string {
 	return out.String()
 }
 
-func extractQuickSummary(report string) string {
-	const marker = "QUICK SUMMARY:"
+func extractQuickSummary(report string, marker string) string {
 	start := strings.Index(report, marker)
 	if start == -1 {
 		return report
@@ -646,22 +935,484 @@ func extractQuickSummary(report string) string {
 	return out + "\n"
 }
 
-func buildSummaryOnly(bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, settings settings.Settings) string {
+// writeNotes renders the Notes block. IncludeVersionAndNotes gates the whole
+// block; IncludeNotesHome/IncludeNotesForums select its individual sections.
+func writeNotes(b *strings.Builder, settings settings.Settings, lbl labels) {
+	if !settings.IncludeVersionAndNotes || (!settings.IncludeNotesHome && !settings.IncludeNotesForums) {
+		return
+	}
+	fmt.Fprintf(b, "%s\n\n\n", lbl.field("Notes:", 16))
+	if settings.IncludeNotesHome {
+		b.WriteString(lbl.get("BDINFO HOME:") + "\n")
+		b.WriteString("  Cinema Squid (old)\n")
+		b.WriteString("    http://www.cinemasquid.com/blu-ray/tools/bdinfo\n")
+		b.WriteString("  UniqProject GitHub (new)\n")
+		b.WriteString("   https://github.com/UniqProject/BDInfo\n")
+		b.WriteString("\n")
+	}
+	if settings.IncludeNotesForums {
+		b.WriteString(lbl.get("INCLUDES FORUMS REPORT FOR:") + "\n")
+		b.WriteString("  AVS Forum Blu-ray Audio and Video Specifications Thread\n")
+		b.WriteString("    http://www.avsforum.com/avs-vb/showthread.php?t=1155731\n")
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
+
+// writeRestrictions lists the AppInfoPlayList and PlayItem playback conditions
+// (UO mask table, random access, still modes) that can make players behave oddly.
+func writeRestrictions(b *strings.Builder, playlist *bdrom.PlaylistFile, lbl labels) {
+	b.WriteString("\n\n" + lbl.get("PLAYBACK RESTRICTIONS:") + "\n\n\n")
+	lines := 0
+	line := func(name string, parts []string) {
+		if len(parts) == 0 {
+			return
+		}
+		fmt.Fprintf(b, "%-16s%s\n", name, strings.Join(parts, "; "))
+		lines++
+	}
+
+	var parts []string
+	switch playlist.PlaybackType {
+	case bdrom.PlaybackRandom:
+		parts = append(parts, fmt.Sprintf("Random playback (%d items)", playlist.PlaybackCount))
+	case bdrom.PlaybackShuffle:
+		parts = append(parts, fmt.Sprintf("Shuffle playback (%d items)", playlist.PlaybackCount))
+	}
+	if playlist.RandomAccessRestricted {
+		parts = append(parts, "Random access prohibited")
+	}
+	if prohibited := playlist.UOMask.Prohibited(); len(prohibited) > 0 {
+		parts = append(parts, "Prohibited: "+strings.Join(prohibited, ", "))
+	}
+	line(playlist.Name, parts)
+
+	for _, clip := range playlist.StreamClips {
+		if clip.AngleIndex > 0 {
+			continue
+		}
+		parts = parts[:0]
+		switch clip.StillMode {
+		case bdrom.StillTime:
+			parts = append(parts, fmt.Sprintf("Still %ds", clip.StillTime))
+		case bdrom.StillInfinite:
+			parts = append(parts, "Still (infinite)")
+		}
+		if clip.RandomAccessRestricted {
+			parts = append(parts, "Random access prohibited")
+		}
+		if prohibited := clip.UOMask.Prohibited(); len(prohibited) > 0 {
+			parts = append(parts, "Prohibited: "+strings.Join(prohibited, ", "))
+		}
+		line(clip.DisplayName(), parts)
+	}
+	if lines == 0 {
+		b.WriteString("None\n")
+	}
+}
+
+// writeChapterNames lists the chapter titles from the disc's title name metadata.
+func writeChapterNames(b *strings.Builder, playlist *bdrom.PlaylistFile, times timeFormatter, lbl labels) {
+	b.WriteString("\n\n" + lbl.get("CHAPTER NAMES:") + "\n\n\n")
+	fmt.Fprintf(b, "%-16s%-16s%s\n", "Number", "Time In", "Name")
+	fmt.Fprintf(b, "%-16s%-16s%s\n", "------", "-------", "----")
+	for i, start := range playlist.Chapters {
+		fmt.Fprintf(b, "%-16d%-16s%s\n", i+1, times.formatTime(start, false), playlist.ChapterName(i))
+	}
+}
+
+// writeAudioSegments lists, per play item, the attributes of audio streams
+// whose codec or language changes during the playlist; the AUDIO table only
+// shows the reference clip's.
+func writeAudioSegments(b *strings.Builder, playlist *bdrom.PlaylistFile, lbl labels) {
+	b.WriteString("\n\n" + lbl.get("AUDIO SEGMENTS:") + "\n\n\n")
+	fmt.Fprintf(b, "%-16s%-16s%-32s%s\n", "PID", "File", "Codec", "Language")
+	fmt.Fprintf(b, "%-16s%-16s%-32s%s\n", "---", "----", "-----", "--------")
+	for _, change := range playlist.AudioSegments {
+		for _, seg := range change.Segments {
+			fmt.Fprintf(b, "%-16s%-16s%-32s%s\n",
+				fmt.Sprintf("%d (0x%X)", change.PID, change.PID),
+				seg.Clip,
+				stream.CodecNameForInfo(seg.Stream),
+				seg.Stream.Base().LanguageName,
+			)
+		}
+	}
+}
+
+// forcedOnlySuffix marks subtitle tracks carrying only forced captions in the
+// quick summary, when the scan counted captions.
+func forcedOnlySuffix(st stream.Info, settings settings.Settings) string {
+	if gs, ok := st.(*stream.GraphicsStream); ok && settings.SubtitleSummary && gs.ForcedOnly() {
+		return " / Forced Only"
+	}
+	return ""
+}
+
+// writeSubtitleSummary groups the visible PGS tracks by language, e.g.
+// "English (2: full + forced)", where forced tracks carry only forced captions.
+func writeSubtitleSummary(b *strings.Builder, playlist *bdrom.PlaylistFile, lbl labels) {
+	b.WriteString("\n\n" + lbl.get("SUBTITLE SUMMARY:") + "\n\n\n")
+	var languages []string
+	kinds := map[string][]string{}
+	for _, st := range playlist.SortedStreams {
+		gs, ok := st.(*stream.GraphicsStream)
+		if !ok || gs.IsHidden || gs.StreamType != stream.StreamTypePresentationGraphics {
+			continue
+		}
+		language := gs.LanguageName
+		if language == "" {
+			language = "Unknown"
+		}
+		if _, ok := kinds[language]; !ok {
+			languages = append(languages, language)
+		}
+		kind := "full"
+		if gs.ForcedOnly() {
+			kind = "forced"
+		}
+		kinds[language] = append(kinds[language], kind)
+	}
+	for _, language := range languages {
+		fmt.Fprintf(b, "%s (%d: %s)\n", language, len(kinds[language]), strings.Join(kinds[language], " + "))
+	}
+}
+
+// write3DOffsets lists the offset sequences of each play item and the one each
+// subtitle stream follows.
+func write3DOffsets(b *strings.Builder, playlist *bdrom.PlaylistFile, lbl labels) {
+	b.WriteString("\n\n" + lbl.get("3D GRAPHICS OFFSETS:") + "\n\n\n")
+	if len(playlist.Offsets3D) == 0 {
+		b.WriteString("None\n")
+		return
+	}
+	fmt.Fprintf(b, "%-16s%-16s%-20s%s\n", "File", "Stream", "Offset Sequence", "Notes")
+	fmt.Fprintf(b, "%-16s%-16s%-20s%s\n", "----", "------", "---------------", "-----")
+	for _, item := range playlist.Offsets3D {
+		notes := ""
+		if item.FixedOffsetDuringPopUp {
+			notes = "Fixed offset during pop-up"
+		}
+		fmt.Fprintf(b, "%-16s%-16s%-20s%s\n", item.Clip, "Video", fmt.Sprintf("%d sequences", item.OffsetSequences), notes)
+		for _, sub := range item.Subtitles {
+			var parts []string
+			if st := playlist.Streams[sub.PID]; st != nil && st.Base().LanguageName != "" {
+				parts = append(parts, st.Base().LanguageName)
+			}
+			if sub.Stereoscopic {
+				parts = append(parts, fmt.Sprintf("Stereoscopic, offset sequence %d", sub.StereoscopicOffsetSequence))
+			}
+			fmt.Fprintf(b, "%-16s%-16s%-20d%s\n", item.Clip, fmt.Sprintf("%d (0x%X)", sub.PID, sub.PID), sub.OffsetSequence, strings.Join(parts, "; "))
+		}
+	}
+}
+
+// writeCoreDiagnostics adds Core and Extension rows under an audio stream with
+// an embedded core (TrueHD with AC3, DTS-HD with DTS): the core at its nominal
+// bitrate and the rest of the stream's packets as the extension. The byte
+// counts are derived from those bitrates, not counted.
+func writeCoreDiagnostics(table *reportTable, audio *stream.AudioStream, language string, seconds float64, rounding bitrateRounding) {
+	total := int64(math.RoundToEven(float64(audio.PayloadBytes) * 8 / seconds))
+	core, extension, ok := audio.CoreBitRateSplit(total)
+	if !ok {
+		return
+	}
+	coreBytes := min(int64(math.RoundToEven(float64(core)*seconds/8)), int64(audio.PayloadBytes))
+	clipSeconds := fmt.Sprintf("%.3f", seconds)
+	table.row("", "", "Core", stream.CodecShortNameForInfo(audio.CoreStream), language, clipSeconds,
+		util.FormatNumber(rounding.kbps(float64(core))), util.FormatNumber(coreBytes), "")
+	table.row("", "", "Extension", stream.CodecShortNameForInfo(audio), language, clipSeconds,
+		util.FormatNumber(rounding.kbps(float64(extension))), util.FormatNumber(int64(audio.PayloadBytes)-coreBytes), "")
+}
+
+// write3DBitrates sums up the views of a 3D playlist scanned through its SSIF
+// files: the bitrate of each eye's video and both together, which is what 3D
+// playback reads. 2D playlists and scans without SSIF get no section.
+func write3DBitrates(b *strings.Builder, playlist *bdrom.PlaylistFile, rounding bitrateRounding, lbl labels) {
+	summary, ok := playlist.SSIFSummary()
+	if !ok {
+		return
+	}
+	kbps := func(bitrate uint64) string {
+		return fmt.Sprintf("%s kbps", util.FormatNumber(rounding.kbps(float64(bitrate))))
+	}
+	pid := func(vs *stream.VideoStream) string {
+		return fmt.Sprintf("%d (0x%X)", vs.PID, vs.PID)
+	}
+	b.WriteString("\n\n" + lbl.get("3D BITRATES (SSIF):") + "\n\n\n")
+	fmt.Fprintf(b, "%-24s%-16s%-16s%s\n", "View", "Eye", "PID", "Bitrate")
+	fmt.Fprintf(b, "%-24s%-16s%-16s%s\n", "----", "---", "---", "-------")
+	fmt.Fprintf(b, "%-24s%-16s%-16s%s\n", "Base (AVC)", summary.BaseEye, pid(summary.Base), kbps(uint64(summary.Base.BitRate)))
+	fmt.Fprintf(b, "%-24s%-16s%-16s%s\n", "Dependent (MVC)", summary.DependentEye(), pid(summary.Dependent), kbps(uint64(summary.Dependent.BitRate)))
+	fmt.Fprintf(b, "%-24s%-16s%-16s%s\n", "Combined", "Both", "", kbps(summary.CombinedBitRate()))
+	fmt.Fprintf(b, "\n%-24s%s bytes\n", "SSIF Size:", util.FormatNumber(int64(summary.Size)))
+}
+
+// sparklineWidth is the most columns a BITRATE GRAPH line takes; longer
+// playlists put several seconds in each column.
+const sparklineWidth = 64
+
+// sparklineLevels draws a column from its lowest to its highest bitrate.
+const sparklineLevels = ".:-=+*#%@"
+
+// writeBitrateGraph draws the per-second bitrate of each video stream of the
+// main angle, and of the audio streams the scan kept windows for, as an ASCII
+// sparkline scaled between the stream's lowest and highest column.
+func writeBitrateGraph(b *strings.Builder, playlist *bdrom.PlaylistFile, rounding bitrateRounding, lbl labels) {
+	seconds := int(math.Ceil(playlist.TotalLength()))
+	if seconds == 0 {
+		return
+	}
+	step := (seconds + sparklineWidth - 1) / sparklineWidth
+	var rows []string
+	for _, st := range playlist.SortedStreams {
+		base := st.Base()
+		if base.AngleIndex > 0 || !(base.IsVideoStream() || base.IsAudioStream()) {
+			continue
+		}
+		series := playlist.BitrateSeries(base.PID)
+		var columns []float64
+		for start := 0; start < len(series); start += step {
+			end := min(start+step, len(series))
+			var sum uint64
+			for _, bitrate := range series[start:end] {
+				sum += bitrate
+			}
+			columns = append(columns, float64(sum)/float64(end-start))
+		}
+		low, high := slices.Min(columns), slices.Max(columns)
+		if high == 0 {
+			continue
+		}
+		var line strings.Builder
+		for _, column := range columns {
+			level := 0
+			if high > low {
+				level = int(math.Round((column - low) / (high - low) * float64(len(sparklineLevels)-1)))
+			}
+			line.WriteByte(sparklineLevels[level])
+		}
+		rows = append(rows, fmt.Sprintf("%-16s%-16s%-16s%s\n", fmt.Sprintf("%d (0x%X)", base.PID, base.PID),
+			util.FormatNumber(rounding.kbps(low))+" kbps", util.FormatNumber(rounding.kbps(high))+" kbps", line.String()))
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n\n" + lbl.get("BITRATE GRAPH:") + "\n\n\n")
+	unit := "1 second"
+	if step > 1 {
+		unit = fmt.Sprintf("%d seconds", step)
+	}
+	fmt.Fprintf(b, "Each column is %s, from the lowest (%c) to the highest (%c) column of the stream.\n\n", unit, sparklineLevels[0], sparklineLevels[len(sparklineLevels)-1])
+	fmt.Fprintf(b, "%-16s%-16s%-16s%s\n", "PID", "Low", "High", "Graph")
+	fmt.Fprintf(b, "%-16s%-16s%-16s%s\n", "---", "---", "----", "-----")
+	for _, row := range rows {
+		b.WriteString(row)
+	}
+}
+
+// writeHiddenStreams explains each stream listed with the "*" prefix.
+func writeHiddenStreams(b *strings.Builder, playlist *bdrom.PlaylistFile, lbl labels) {
+	b.WriteString("\n\n" + lbl.get("HIDDEN STREAMS:") + "\n\n\n")
+	fmt.Fprintf(b, "%-16s%-24s%s\n", "PID", "Codec", "Reason")
+	fmt.Fprintf(b, "%-16s%-24s%s\n", "---", "-----", "------")
+	for _, st := range playlist.SortedStreams {
+		base := st.Base()
+		if !base.IsHidden {
+			continue
+		}
+		fmt.Fprintf(b, "%-16s%-24s%s\n",
+			fmt.Sprintf("%d (0x%X)", base.PID, base.PID),
+			stream.CodecNameForInfo(st),
+			base.HiddenDescription(),
+		)
+	}
+}
+
+// writeClipInfo lists the CLPI ClipInfo() recording details of each clip file.
+func writeClipInfo(b *strings.Builder, playlist *bdrom.PlaylistFile, lbl labels) {
+	b.WriteString("\n\n" + lbl.get("CLIP INFO:") + "\n\n\n")
+	fmt.Fprintf(b, "%-16s%-32s%-16s%-16s%s\n", "File", "Application", "Rate", "Packets", "Format")
+	fmt.Fprintf(b, "%-16s%-32s%-16s%-16s%s\n", "----", "-----------", "----", "-------", "------")
+	seen := map[string]bool{}
+	for _, clip := range playlist.StreamClips {
+		clipFile := clip.StreamClipFile
+		if clipFile == nil || seen[clipFile.Name] {
+			continue
+		}
+		seen[clipFile.Name] = true
+		format := clipFile.FormatIdentifier
+		if format == "" {
+			format = "-"
+		}
+		fmt.Fprintf(b, "%-16s%-32s%-16s%-16s%s\n",
+			clipFile.Name,
+			clipFile.ApplicationTypeName(),
+			fmt.Sprintf("%.2f Mbps", float64(clipFile.TSRecordingRate)*8/1_000_000),
+			util.FormatNumber(int64(clipFile.SourcePacketCount)),
+			format,
+		)
+	}
+}
+
+// writeDeclaredRates compares what the clip info declares (recording rate and
+// source packet count) and the nominal rate of constant bitrate streams with
+// the scan, flagging the rows off by more than bdrom.DeclaredRateTolerance.
+func writeDeclaredRates(b *strings.Builder, playlist *bdrom.PlaylistFile, rounding bitrateRounding, lbl labels) {
+	b.WriteString("\n\n" + lbl.get("DECLARED VS MEASURED:") + "\n\n\n")
+	fmt.Fprintf(b, "%-16s%-24s%-20s%-20s%-12s%s\n", "File", "Check", "Declared", "Measured", "Delta", "Flag")
+	fmt.Fprintf(b, "%-16s%-24s%-20s%-20s%-12s%s\n", "----", "-----", "--------", "--------", "-----", "----")
+	for _, check := range playlist.DeclaredRateChecks() {
+		item := check.Kind
+		var declared, measured string
+		switch check.Kind {
+		case bdrom.RateCheckSourcePackets:
+			declared = util.FormatNumber(int64(check.Declared))
+			measured = util.FormatNumber(int64(check.Measured))
+		case bdrom.RateCheckRecordingRate:
+			declared = fmt.Sprintf("%.2f Mbps", check.Declared/1_000_000)
+			measured = fmt.Sprintf("%.2f Mbps", check.Measured/1_000_000)
+		default:
+			base := check.Stream.Base()
+			item = fmt.Sprintf("%d (0x%X) %s", base.PID, base.PID, stream.CodecShortNameForInfo(check.Stream))
+			declared = util.FormatNumber(rounding.kbps(check.Declared)) + " kbps"
+			measured = util.FormatNumber(rounding.kbps(check.Measured)) + " kbps"
+		}
+		flag := ""
+		if check.Mismatch {
+			flag = "MISMATCH"
+		}
+		fmt.Fprintf(b, "%-16s%-24s%-20s%-20s%-12s%s\n", check.File, item, declared, measured, fmt.Sprintf("%+.2f%%", check.Delta()*100), flag)
+	}
+}
+
+// writeUnknownPIDs lists, per stream file, the PIDs the clip info does not
+// declare that carry a noticeable share of the file.
+func writeUnknownPIDs(b *strings.Builder, playlist *bdrom.PlaylistFile, lbl labels) {
+	b.WriteString("\n\n" + lbl.get("UNKNOWN PIDS:") + "\n\n\n")
+	fmt.Fprintf(b, "%-16s%-16s%-16s\n", "File", "PID", "Bytes")
+	fmt.Fprintf(b, "%-16s%-16s%-16s\n", "----", "---", "-----")
+	seen := map[string]bool{}
+	for _, clip := range playlist.StreamClips {
+		if clip.StreamFile == nil || seen[clip.Name] {
+			continue
+		}
+		seen[clip.Name] = true
+		for _, unknown := range clip.StreamFile.SignificantUnknownPIDs() {
+			fmt.Fprintf(b, "%-16s%-16s%-16s\n",
+				clip.DisplayName(),
+				fmt.Sprintf("%d (0x%X)", unknown.PID, unknown.PID),
+				util.FormatNumber(int64(unknown.Bytes)),
+			)
+		}
+	}
+}
+
+// writeIndexTitles maps index.bdmv entries to the playlists their movie objects play.
+func writeIndexTitles(b *strings.Builder, bd *bdrom.BDROM, lbl labels) {
+	if len(bd.IndexTitles) == 0 {
+		return
+	}
+	b.WriteString(lbl.get("TITLES:") + "\n\n")
+	fmt.Fprintf(b, "%-16s%-24s%s\n", "Title", "Object", "Playlists")
+	fmt.Fprintf(b, "%-16s%-24s%s\n", "-----", "------", "---------")
+	var main []string
+	for _, entry := range bd.IndexTitles {
+		object := "-"
+		playlists := "-"
+		switch {
+		case entry.IsBDJ:
+			object = "BD-J " + entry.BDJO
+			playlists = "(selected by BD-J)"
+		case entry.MovieObject >= 0:
+			object = fmt.Sprintf("Movie Object %d", entry.MovieObject)
+			if len(entry.Playlists) > 0 {
+				playlists = strings.Join(entry.Playlists, ", ")
+			}
+		}
+		if entry.Kind == bdrom.IndexTitle && entry.Number == 1 {
+			main = entry.Playlists
+		}
+		fmt.Fprintf(b, "%-16s%-24s%s\n", entry.Label(), object, playlists)
+	}
+	var orphaned []string
+	for _, playlist := range bd.PlaylistFiles {
+		if playlist != nil && playlist.Orphaned {
+			orphaned = append(orphaned, playlist.Name)
+		}
+	}
+	sort.Strings(orphaned)
+	if len(main) > 0 || len(orphaned) > 0 {
+		b.WriteString("\n")
+	}
+	if len(main) > 0 {
+		fmt.Fprintf(b, "%s %s\n", lbl.get("Main title (Title 1):"), strings.Join(main, ", "))
+	}
+	if len(orphaned) > 0 {
+		fmt.Fprintf(b, "%s %s\n", lbl.get("Orphaned playlists:"), strings.Join(orphaned, ", "))
+	}
+	b.WriteString("\n\n")
+}
+
+// writeDuplicatePlaylists lists the groups of duplicate playlists the report
+// collapsed to their representative, and flags playlist obfuscation.
+func writeDuplicatePlaylists(b *strings.Builder, playlists []*bdrom.PlaylistFile, lbl labels) {
+	groups := bdrom.GroupDuplicatePlaylists(playlists)
+	if len(groups) == 0 {
+		return
+	}
+	b.WriteString(lbl.get("DUPLICATE PLAYLISTS:") + "\n\n")
+	if bdrom.PlaylistObfuscation(groups) {
+		b.WriteString(lbl.get("Playlist obfuscation detected.") + "\n\n")
+	}
+	fmt.Fprintf(b, "%-16s%-16s%s\n", "Kept", "Order", "Duplicates")
+	fmt.Fprintf(b, "%-16s%-16s%s\n", "----", "-----", "----------")
+	for _, group := range groups {
+		order := "same"
+		if group.Reordered {
+			order = "reordered"
+		}
+		duplicates := slices.DeleteFunc(slices.Clone(group.Playlists), func(name string) bool { return name == group.Representative })
+		fmt.Fprintf(b, "%-16s%-16s%s\n", group.Representative, order, strings.Join(duplicates, ", "))
+	}
+	b.WriteString("\n\n")
+}
+
+// writeJARImages lists the images harvested from BD-J JARs (Settings.HarvestJARImages).
+func writeJARImages(b *strings.Builder, bd *bdrom.BDROM, lbl labels) {
+	if len(bd.JARImages) == 0 {
+		return
+	}
+	b.WriteString(lbl.get("BD-JAVA:") + "\n\n")
+	fmt.Fprintf(b, "%-16s%-48s%-8s%-16s%16s\n", "JAR", "Image", "Format", "Dimensions", "Size")
+	fmt.Fprintf(b, "%-16s%-48s%-8s%-16s%16s\n", "---", "-----", "------", "----------", "----")
+	for _, img := range bd.JARImages {
+		fmt.Fprintf(b, "%-16s%-48s%-8s%-16s%16s\n",
+			img.JAR,
+			img.Name,
+			img.Format,
+			fmt.Sprintf("%dx%d", img.Width, img.Height),
+			util.FormatNumber(int64(len(img.Data))),
+		)
+	}
+	b.WriteString("\n\n")
+}
+
+func buildSummaryOnly(bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, settings settings.Settings, lbl labels) string {
+	if settings.CollapseDuplicates {
+		playlists = collapseDuplicatePlaylists(playlists)
+	}
 	if settings.MainPlaylistOnly {
 		playlists = selectMainPlaylist(playlists, settings)
+	} else if settings.TopPlaylists > 0 {
+		playlists = selectTopPlaylists(playlists, settings.TopPlaylists, settings)
 	}
 
-	sort.SliceStable(playlists, func(i, j int) bool {
-		return playlists[i].FileSize() > playlists[j].FileSize()
-	})
+	playlists = SortPlaylists(playlists, settings.PlaylistSort)
 
-	protection := "AACS"
-	if bd.IsBDPlus {
-		protection = "BD+"
-	} else if bd.IsUHD {
-		protection = "AACS2"
-	}
+	protection := discProtection(bd, settings)
 
+	rounding := newBitrateRounding(settings)
 	var out strings.Builder
 	for _, playlist := range playlists {
 		if settings.FilterLoopingPlaylists && !playlist.IsValid() {
@@ -674,7 +1425,7 @@ func buildSummaryOnly(bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, settings
 
 		totalSize := playlist.TotalSize()
 		totalSizeStr := util.FormatNumber(int64(totalSize))
-		totalBitrate := formatMbps(playlist.TotalBitRate())
+		totalBitrate := rounding.mbps(playlist.TotalBitRate())
 
 		if len(playlist.VideoStreams) > 0 {
 			for _, st := range playlist.SortedStreams {
@@ -685,13 +1436,13 @@ func buildSummaryOnly(bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, settings
 				if st.Base().AngleIndex > 0 {
 					name = fmt.Sprintf("%s (%d)", name, st.Base().AngleIndex)
 				}
-				bitrate := fmt.Sprintf("%d", int(math.RoundToEven(float64(st.Base().BitRate)/1000)))
+				bitrate := fmt.Sprintf("%d", int(rounding.kbps(float64(st.Base().BitRate))))
 				if st.Base().AngleIndex > 0 {
-					bitrate = fmt.Sprintf("%s (%d)", bitrate, int(math.RoundToEven(float64(st.Base().ActiveBitRate)/1000)))
+					bitrate = fmt.Sprintf("%s (%d)", bitrate, int(rounding.kbps(float64(st.Base().ActiveBitRate))))
 				}
 				bitrate = fmt.Sprintf("%s kbps", bitrate)
 				if settings.GenerateTextSummary {
-					fmt.Fprintf(&summary, "%sVideo: %s / %s / %s\n", hiddenPrefix(st), name, bitrate, st.Description())
+					fmt.Fprintf(&summary, "%s%s %s / %s / %s\n", hiddenPrefix(st), lbl.get("Video:"), name, bitrate, st.Description())
 				}
 			}
 		}
@@ -702,7 +1453,7 @@ func buildSummaryOnly(bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, settings
 					continue
 				}
 				if settings.GenerateTextSummary {
-					fmt.Fprintf(&summary, "%sAudio: %s / %s / %s\n", hiddenPrefix(st), st.Base().LanguageName, stream.CodecNameForInfo(st), st.Description())
+					fmt.Fprintf(&summary, "%s%s %s / %s / %s\n", hiddenPrefix(st), lbl.get("Audio:"), st.Base().LanguageName, stream.CodecNameForInfo(st), st.Description())
 				}
 			}
 		}
@@ -714,23 +1465,23 @@ func buildSummaryOnly(bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, settings
 				}
 				if settings.GenerateTextSummary {
 					bitrate := fmt.Sprintf("%.3f kbps", float64(st.Base().BitRate)/1000.0)
-					fmt.Fprintf(&summary, "%sSubtitle: %s / %s\n", hiddenPrefix(st), st.Base().LanguageName, bitrate)
+					fmt.Fprintf(&summary, "%s%s %s / %s%s\n", hiddenPrefix(st), lbl.get("Subtitle:"), st.Base().LanguageName, bitrate, forcedOnlySuffix(st, settings))
 				}
 			}
 		}
 
 		if settings.GenerateTextSummary {
-			out.WriteString("QUICK SUMMARY:\n\n")
+			out.WriteString(lbl.get("QUICK SUMMARY:") + "\n\n")
 			if bd.DiscTitle != "" {
-				fmt.Fprintf(&out, "Disc Title: %s\n", bd.DiscTitle)
-			}
-			fmt.Fprintf(&out, "Disc Label: %s\n", bd.VolumeLabel)
-			fmt.Fprintf(&out, "Disc Size: %s bytes\n", util.FormatNumber(int64(bd.Size)))
-			fmt.Fprintf(&out, "Protection: %s\n", protection)
-			fmt.Fprintf(&out, "Playlist: %s\n", playlist.Name)
-			fmt.Fprintf(&out, "Size: %s bytes\n", totalSizeStr)
-			fmt.Fprintf(&out, "Length: %s\n", totalLength)
-			fmt.Fprintf(&out, "Total Bitrate: %s Mbps\n", totalBitrate)
+				fmt.Fprintf(&out, "%s %s\n", lbl.get("Disc Title:"), bd.DiscTitle)
+			}
+			fmt.Fprintf(&out, "%s %s\n", lbl.get("Disc Label:"), bd.VolumeLabel)
+			fmt.Fprintf(&out, "%s %s %s\n", lbl.get("Disc Size:"), util.FormatNumber(int64(bd.Size)), lbl.get("bytes"))
+			fmt.Fprintf(&out, "%s %s\n", lbl.get("Protection:"), protection)
+			fmt.Fprintf(&out, "%s %s\n", lbl.get("Playlist:"), playlist.Name)
+			fmt.Fprintf(&out, "%s %s %s\n", lbl.get("Size:"), totalSizeStr, lbl.get("bytes"))
+			fmt.Fprintf(&out, "%s %s\n", lbl.get("Length:"), totalLength)
+			fmt.Fprintf(&out, "%s %s Mbps\n", lbl.get("Total Bitrate:"), totalBitrate)
 			if summary.Len() > 0 {
 				out.WriteString(summary.String())
 			}
@@ -739,12 +1490,32 @@ func buildSummaryOnly(bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, settings
 	return out.String()
 }
 
-func formatMbps(bitrate uint64) string {
-	if bitrate == 0 {
-		return "0.00"
+// writeAngleTotals adds per-angle and all-angle totals to the PLAYLIST REPORT
+// block of multi-angle playlists; the lines above only cover angle 0.
+func writeAngleTotals(b *strings.Builder, playlist *bdrom.PlaylistFile, rounding bitrateRounding) {
+	if playlist.AngleCount == 0 {
+		return
+	}
+	bitrate := func(size uint64, length float64) string {
+		if length <= 0 {
+			return rounding.mbps(0)
+		}
+		return rounding.mbps(uint64(float64(size) * 8.0 / length))
+	}
+	b.WriteString("\n")
+	for angle := 1; angle <= playlist.AngleCount; angle++ {
+		clipLength, clipSize, viewLength, viewSize := playlist.AngleTotals(angle)
+		fmt.Fprintf(b, "%-24s%s (h:m:s.ms) / %s (h:m:s.ms)\n", fmt.Sprintf("Angle %d Length:", angle),
+			util.FormatTime(clipLength, true), util.FormatTime(viewLength, true))
+		fmt.Fprintf(b, "%-24s%s bytes / %s bytes\n", fmt.Sprintf("Angle %d Size:", angle),
+			util.FormatNumber(int64(clipSize)), util.FormatNumber(int64(viewSize)))
+		fmt.Fprintf(b, "%-24s%s Mbps / %s Mbps\n", fmt.Sprintf("Angle %d Total Bitrate:", angle),
+			bitrate(clipSize, clipLength), bitrate(viewSize, viewLength))
 	}
-	val := math.RoundToEven(float64(bitrate)/10000.0) / 100.0
-	return fmt.Sprintf("%.2f", val)
+	b.WriteString("\n")
+	fmt.Fprintf(b, "%-24s%s (h:m:s.ms)\n", "All Angles Length:", util.FormatTime(playlist.TotalAngleLength(), true))
+	fmt.Fprintf(b, "%-24s%s bytes\n", "All Angles Size:", util.FormatNumber(int64(playlist.TotalAngleSize())))
+	fmt.Fprintf(b, "%-24s%s Mbps\n", "All Angles Bitrate:", rounding.mbps(playlist.TotalAngleBitRate()))
 }
 
 func hiddenPrefix(info stream.Info) string {
@@ -775,7 +1546,7 @@ func (q *floatQueue) Dequeue() float64 {
 }
 
 func formatTimeHmsms(seconds float64, padHour bool) string {
-	ticks := max(int64(seconds*10000000.0), 0)
+	ticks := util.TimeTicks(seconds)
 	totalMillis := ticks / 10000
 	ms := int(totalMillis % 1000)
 	totalSeconds := ticks / 10000000
@@ -789,7 +1560,7 @@ func formatTimeHmsms(seconds float64, padHour bool) string {
 	return fmt.Sprintf("%d:%02d:%02d.%03d", h, m, s, ms)
 }
 
-func writeChapters(b *strings.Builder, playlist *bdrom.PlaylistFile) {
+func writeChapters(b *strings.Builder, playlist *bdrom.PlaylistFile, times timeFormatter, rounding bitrateRounding) {
 	if playlist == nil || len(playlist.Chapters) == 0 {
 		return
 	}
@@ -951,18 +1722,18 @@ func writeChapters(b *strings.Builder, playlist *bdrom.PlaylistFile) {
 
 			fmt.Fprintf(b, "%-16d%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s%-16s\n",
 				chapterIndex,
-				formatTimeHmsms(chapterStart, false),
-				formatTimeHmsms(chapterLength, false),
-				fmt.Sprintf("%s kbps", util.FormatNumber(int64(math.RoundToEven(chapterBitrate/1000)))),
-				fmt.Sprintf("%s kbps", util.FormatNumber(int64(math.RoundToEven(window1PeakBitrate/1000)))),
-				formatTimeHmsms(window1PeakLocation, true),
-				fmt.Sprintf("%s kbps", util.FormatNumber(int64(math.RoundToEven(window5PeakBitrate/1000)))),
-				formatTimeHmsms(window5PeakLocation, true),
-				fmt.Sprintf("%s kbps", util.FormatNumber(int64(math.RoundToEven(window10PeakBitrate/1000)))),
-				formatTimeHmsms(window10PeakLocation, true),
+				times.formatTime(chapterStart, false),
+				times.formatTime(chapterLength, false),
+				fmt.Sprintf("%s kbps", util.FormatNumber(rounding.kbps(chapterBitrate))),
+				fmt.Sprintf("%s kbps", util.FormatNumber(rounding.kbps(window1PeakBitrate))),
+				times.formatTime(window1PeakLocation, true),
+				fmt.Sprintf("%s kbps", util.FormatNumber(rounding.kbps(window5PeakBitrate))),
+				times.formatTime(window5PeakLocation, true),
+				fmt.Sprintf("%s kbps", util.FormatNumber(rounding.kbps(window10PeakBitrate))),
+				times.formatTime(window10PeakLocation, true),
 				fmt.Sprintf("%s bytes", util.FormatNumber(int64(math.RoundToEven(chapterAvgFrameSize)))),
 				fmt.Sprintf("%s bytes", util.FormatNumber(int64(math.RoundToEven(chapterMaxFrameSize)))),
-				formatTimeHmsms(chapterMaxFrameLocation, true),
+				times.formatTime(chapterMaxFrameLocation, true),
 			)
 
 			window1Bits = &floatQueue{}
@@ -1,9 +1,15 @@
 package report
 
 import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/autobrr/go-bdinfo/internal/bdrom"
@@ -168,3 +174,1290 @@ func TestWriteReport_ReportFileNameExtensionHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestRenderReport_NotesSections(t *testing.T) {
+	bd := &bdrom.BDROM{VolumeLabel: "TEST_DISC"}
+
+	tests := []struct {
+		name       string
+		home       bool
+		forums     bool
+		version    string
+		wantNotes  bool
+		wantHome   bool
+		wantForums bool
+		wantVer    string
+	}{
+		{name: "defaults", home: true, forums: true, wantNotes: true, wantHome: true, wantForums: true, wantVer: productVersion},
+		{name: "home only", home: true, wantNotes: true, wantHome: true, wantVer: productVersion},
+		{name: "forums only", forums: true, wantNotes: true, wantForums: true, wantVer: productVersion},
+		{name: "no sections", wantVer: productVersion},
+		{name: "version override", home: true, forums: true, version: "0.7.5.6", wantNotes: true, wantHome: true, wantForums: true, wantVer: "0.7.5.6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := settings.Default(t.TempDir())
+			cfg.IncludeNotesHome = tt.home
+			cfg.IncludeNotesForums = tt.forums
+			cfg.ProductVersion = tt.version
+
+			_, text, err := RenderReport("-", bd, nil, bdrom.ScanResult{}, cfg)
+			if err != nil {
+				t.Fatalf("RenderReport() error = %v", err)
+			}
+			if got := strings.Contains(text, "Notes:"); got != tt.wantNotes {
+				t.Fatalf("Notes header got=%v want=%v", got, tt.wantNotes)
+			}
+			if got := strings.Contains(text, "BDINFO HOME:"); got != tt.wantHome {
+				t.Fatalf("BDINFO HOME got=%v want=%v", got, tt.wantHome)
+			}
+			if got := strings.Contains(text, "avsforum.com"); got != tt.wantForums {
+				t.Fatalf("forums link got=%v want=%v", got, tt.wantForums)
+			}
+			if !strings.Contains(text, "BDInfo:         "+tt.wantVer+"\n") {
+				t.Fatalf("product version %q missing from report", tt.wantVer)
+			}
+		})
+	}
+}
+
+func TestRenderReport_Labels(t *testing.T) {
+	bd := &bdrom.BDROM{VolumeLabel: "TEST_DISC", Size: 1024}
+	playlist := &bdrom.PlaylistFile{Name: "00800.MPLS", IsInitialized: true}
+
+	t.Run("builtin language", func(t *testing.T) {
+		cfg := settings.Default(t.TempDir())
+		cfg.FilterShortPlaylists = false
+		cfg.ReportLanguage = "de"
+
+		_, text, err := RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+		if err != nil {
+			t.Fatalf("RenderReport() error = %v", err)
+		}
+		for _, want := range []string{"Disc-Label:     TEST_DISC\n", "KURZÜBERSICHT:", "Disc-Größe: 1,024 Bytes\n"} {
+			if !strings.Contains(text, want) {
+				t.Fatalf("expected %q in report", want)
+			}
+		}
+		// The forums paste stays English.
+		if !strings.Contains(text, "PLAYLIST REPORT:") || !strings.Contains(text, "Disc Label:     TEST_DISC\n") {
+			t.Fatalf("expected forums paste labels to stay English")
+		}
+	})
+
+	t.Run("labels file", func(t *testing.T) {
+		dir := t.TempDir()
+		labelsPath := filepath.Join(dir, "labels.json")
+		if err := os.WriteFile(labelsPath, []byte(`{"QUICK SUMMARY:": "SAMMANFATTNING:"}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := settings.Default(dir)
+		cfg.FilterShortPlaylists = false
+		cfg.SummaryOnly = true
+		cfg.ReportLabelsFile = labelsPath
+
+		_, text, err := RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+		if err != nil {
+			t.Fatalf("RenderReport() error = %v", err)
+		}
+		if !strings.HasPrefix(text, "SAMMANFATTNING:\n") || !strings.Contains(text, "Disc Label: TEST_DISC\n") {
+			t.Fatalf("unexpected summary: %q", text)
+		}
+	})
+
+	t.Run("unknown language", func(t *testing.T) {
+		cfg := settings.Default(t.TempDir())
+		cfg.ReportLanguage = "xx"
+		if _, _, err := RenderReport("-", bd, nil, bdrom.ScanResult{}, cfg); err == nil {
+			t.Fatalf("expected error for unknown report language")
+		}
+	})
+}
+
+func TestTimeFormatter(t *testing.T) {
+	video := &stream.VideoStream{FrameRateEnum: 24000, FrameRateDen: 1001}
+	playlist := &bdrom.PlaylistFile{VideoStreams: []*stream.VideoStream{video}}
+
+	tests := []struct {
+		name    string
+		format  string
+		seconds float64
+		padHour bool
+		want    string
+	}{
+		{name: "default", format: "", seconds: 3723.5, want: "1:02:03.500"},
+		{name: "hms padded", format: TimeFormatHMS, seconds: 3723.5, padHour: true, want: "01:02:03.500"},
+		{name: "seconds", format: TimeFormatSeconds, seconds: 3723.5, want: "3723.500"},
+		{name: "smpte", format: TimeFormatSMPTE, seconds: 3723.5, want: "1:02:03:11"},
+		{name: "smpte padded", format: TimeFormatSMPTE, seconds: 62.999, padHour: true, want: "00:01:02:23"},
+		{name: "negative", format: TimeFormatHMS, seconds: -5, want: "0:00:00.000"},
+		{name: "nan", format: TimeFormatHMS, seconds: math.NaN(), want: "0:00:00.000"},
+		{name: "seconds negative", format: TimeFormatSeconds, seconds: -5, want: "0.000"},
+		{name: "smpte negative", format: TimeFormatSMPTE, seconds: -1, want: "0:00:00:00"},
+		{name: "over 24h", format: TimeFormatHMS, seconds: 26 * 3600, want: "26:00:00.000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := settings.Settings{TimeFormat: tt.format}
+			got := newTimeFormatter(cfg, playlist).formatTime(tt.seconds, tt.padHour)
+			if got != tt.want {
+				t.Fatalf("formatTime(%v) got=%q want=%q", tt.seconds, got, tt.want)
+			}
+		})
+	}
+
+	// SMPTE without a known frame rate falls back to h:mm:ss.mmm.
+	got := newTimeFormatter(settings.Settings{TimeFormat: TimeFormatSMPTE}, &bdrom.PlaylistFile{}).formatTime(1.25, false)
+	if got != "0:00:01.250" {
+		t.Fatalf("smpte fallback got=%q want=%q", got, "0:00:01.250")
+	}
+}
+
+func TestMainPlaylistTies(t *testing.T) {
+	clip := func(length float64) *bdrom.StreamClip {
+		return &bdrom.StreamClip{Length: length, PacketCount: 1000}
+	}
+	a := &bdrom.PlaylistFile{Name: "00002.MPLS", IsInitialized: true, StreamClips: []*bdrom.StreamClip{clip(3600)}}
+	b := &bdrom.PlaylistFile{Name: "00001.MPLS", IsInitialized: true, StreamClips: []*bdrom.StreamClip{clip(3600)}}
+	c := &bdrom.PlaylistFile{Name: "00003.MPLS", IsInitialized: true, StreamClips: []*bdrom.StreamClip{clip(60)}}
+
+	cfg := settings.Default(t.TempDir())
+	cfg.MainPlaylistOnly = true
+
+	main, ties := MainPlaylistTies([]*bdrom.PlaylistFile{a, b, c}, cfg)
+	if main != b {
+		t.Fatalf("main got=%v want=%q", main, b.Name)
+	}
+	if len(ties) != 1 || ties[0] != a.Name {
+		t.Fatalf("ties got=%v want=[%s]", ties, a.Name)
+	}
+
+	// index.bdmv Title 1 breaks the exact tie.
+	a.ReachableFromTitle1 = true
+	if main, ties := MainPlaylistTies([]*bdrom.PlaylistFile{a, b, c}, cfg); main != a || len(ties) != 0 {
+		t.Fatalf("expected Title 1 playlist %q, got main=%v ties=%v", a.Name, main, ties)
+	}
+	a.ReachableFromTitle1 = false
+
+	c.StreamClips[0].Length = 7200
+	if main, ties := MainPlaylistTies([]*bdrom.PlaylistFile{a, b, c}, cfg); main != c || len(ties) != 0 {
+		t.Fatalf("expected unique main %q, got main=%v ties=%v", c.Name, main, ties)
+	}
+
+	// A playlist no index.bdmv entry plays is skipped while others remain.
+	c.Orphaned = true
+	if main, ties := MainPlaylistTies([]*bdrom.PlaylistFile{a, b, c}, cfg); main != b || len(ties) != 1 {
+		t.Fatalf("expected orphaned %q skipped, got main=%v ties=%v", c.Name, main, ties)
+	}
+	if main, _ := MainPlaylistTies([]*bdrom.PlaylistFile{c}, cfg); main != c {
+		t.Fatalf("expected orphaned %q kept as the only candidate, got %v", c.Name, main)
+	}
+}
+
+func TestRenderReport_JARImages(t *testing.T) {
+	bd := &bdrom.BDROM{
+		VolumeLabel: "TEST_DISC",
+		IsBDJava:    true,
+		JARImages: []bdrom.JARImage{
+			{JAR: "00000.JAR", Name: "images/bg.png", Format: "png", Width: 1920, Height: 1080, Data: make([]byte, 1234)},
+		},
+	}
+	cfg := settings.Default(t.TempDir())
+
+	_, text, err := RenderReport("-", bd, nil, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatalf("RenderReport() error = %v", err)
+	}
+	if !strings.Contains(text, "BD-JAVA:\n") {
+		t.Fatalf("missing BD-JAVA section:\n%s", text)
+	}
+	for _, want := range []string{"00000.JAR", "images/bg.png", "1920x1080", "1,234"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("BD-JAVA section missing %q:\n%s", want, text)
+		}
+	}
+
+	bd.JARImages = nil
+	_, text, err = RenderReport("-", bd, nil, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatalf("RenderReport() error = %v", err)
+	}
+	if strings.Contains(text, "BD-JAVA:") {
+		t.Fatalf("unexpected BD-JAVA section without harvested images")
+	}
+}
+
+func TestRenderReport_TitleMap(t *testing.T) {
+	bd := &bdrom.BDROM{
+		VolumeLabel: "TEST_DISC",
+		IndexTitles: []bdrom.IndexEntry{
+			{Kind: bdrom.IndexFirstPlayback, MovieObject: 0, Playlists: []string{"00010.MPLS"}},
+			{Kind: bdrom.IndexTopMenu, IsBDJ: true, MovieObject: -1, BDJO: "00000"},
+			{Kind: bdrom.IndexTitle, Number: 1, MovieObject: 2, Playlists: []string{"00800.MPLS"}},
+		},
+		PlaylistFiles: map[string]*bdrom.PlaylistFile{
+			"00800.MPLS": {Name: "00800.MPLS"},
+			"00900.MPLS": {Name: "00900.MPLS", Orphaned: true},
+		},
+	}
+	cfg := settings.Default(t.TempDir())
+
+	_, text, err := RenderReport("-", bd, nil, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatalf("RenderReport() error = %v", err)
+	}
+	if strings.Contains(text, "TITLES:") {
+		t.Fatalf("TITLES section rendered without IncludeTitleMap")
+	}
+
+	cfg.IncludeTitleMap = true
+	_, text, err = RenderReport("-", bd, nil, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatalf("RenderReport() error = %v", err)
+	}
+	for _, want := range []string{
+		"TITLES:\n",
+		"First Playback  Movie Object 0          00010.MPLS\n",
+		"Top Menu        BD-J 00000              (selected by BD-J)\n",
+		"Title 1         Movie Object 2          00800.MPLS\n",
+		"Main title (Title 1): 00800.MPLS\n",
+		"Orphaned playlists: 00900.MPLS\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("TITLES section missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestWriteRestrictions(t *testing.T) {
+	playlist := &bdrom.PlaylistFile{
+		Name:                   "00800.MPLS",
+		PlaybackType:           bdrom.PlaybackShuffle,
+		PlaybackCount:          4,
+		RandomAccessRestricted: true,
+		UOMask:                 bdrom.UOMask(1 << 60), // Time Search
+		StreamClips: []*bdrom.StreamClip{
+			{Name: "00001.M2TS"},
+			{Name: "00002.M2TS", StillMode: bdrom.StillTime, StillTime: 5},
+			{Name: "00003.M2TS", AngleIndex: 1, StillMode: bdrom.StillInfinite},
+		},
+	}
+
+	var b strings.Builder
+	writeRestrictions(&b, playlist, nil)
+	text := b.String()
+	for _, want := range []string{
+		"PLAYBACK RESTRICTIONS:",
+		"00800.MPLS      Shuffle playback (4 items); Random access prohibited; Prohibited: Time Search\n",
+		"00002.M2TS      Still 5s\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("restrictions missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "00001.M2TS") || strings.Contains(text, "00003.M2TS") {
+		t.Fatalf("unexpected unrestricted or angle clip:\n%s", text)
+	}
+
+	b.Reset()
+	writeRestrictions(&b, &bdrom.PlaylistFile{Name: "00001.MPLS", PlaybackType: bdrom.PlaybackSequential}, nil)
+	if !strings.HasSuffix(b.String(), "None\n") {
+		t.Fatalf("expected None for unrestricted playlist:\n%s", b.String())
+	}
+}
+
+func TestWriteChapterNames(t *testing.T) {
+	playlist := &bdrom.PlaylistFile{
+		Name:         "00800.MPLS",
+		Chapters:     []float64{0, 605.5, 1200},
+		ChapterNames: []string{"Opening", "Chase"},
+	}
+
+	var b strings.Builder
+	writeChapterNames(&b, playlist, timeFormatter{format: TimeFormatHMS}, nil)
+	for _, want := range []string{
+		"CHAPTER NAMES:",
+		"1               0:00:00.000     Opening\n",
+		"2               0:10:05.500     Chase\n",
+		"3               0:20:00.000     \n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("chapter names missing %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestWriteAudioSegments(t *testing.T) {
+	segment := func(clip string, streamType stream.StreamType, lang string) bdrom.SegmentStream {
+		a := &stream.AudioStream{Stream: stream.Stream{PID: 0x1101, StreamType: streamType}}
+		a.SetLanguageCode(lang)
+		return bdrom.SegmentStream{Clip: clip, Stream: a}
+	}
+	playlist := &bdrom.PlaylistFile{
+		Name: "00800.MPLS",
+		AudioSegments: []bdrom.AudioSegments{{PID: 0x1101, Segments: []bdrom.SegmentStream{
+			segment("00001.M2TS", stream.StreamTypeAC3Audio, "fra"),
+			segment("00002.M2TS", stream.StreamTypeDTSAudio, "deu"),
+		}}},
+	}
+
+	var b strings.Builder
+	writeAudioSegments(&b, playlist, nil)
+	for _, want := range []string{
+		"AUDIO SEGMENTS:",
+		"4353 (0x1101)   00001.M2TS      Dolby Digital Audio             French\n",
+		"4353 (0x1101)   00002.M2TS      DTS Audio                       German\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("audio segments missing %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestWriteSubtitleSummary(t *testing.T) {
+	pg := func(pid uint16, lang string, captions, forced int) *stream.GraphicsStream {
+		g := &stream.GraphicsStream{Stream: stream.Stream{PID: pid, StreamType: stream.StreamTypePresentationGraphics}, Captions: captions, ForcedCaptions: forced}
+		g.SetLanguageCode(lang)
+		return g
+	}
+	full, forced, french := pg(0x1200, "eng", 900, 12), pg(0x1201, "eng", 12, 12), pg(0x1202, "fra", 880, 0)
+	playlist := &bdrom.PlaylistFile{Name: "00800.MPLS", SortedStreams: []stream.Info{full, forced, french}}
+
+	var b strings.Builder
+	writeSubtitleSummary(&b, playlist, nil)
+	for _, want := range []string{
+		"SUBTITLE SUMMARY:",
+		"English (2: full + forced)\n",
+		"French (1: full)\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("subtitle summary missing %q:\n%s", want, b.String())
+		}
+	}
+
+	de, err := loadLabels(settings.Settings{ReportLanguage: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	writeSubtitleSummary(&b, playlist, de)
+	if !strings.HasPrefix(b.String(), "\n\nUNTERTITELÜBERSICHT:\n") {
+		t.Fatalf("German subtitle summary heading:\n%s", b.String())
+	}
+
+	cfg := settings.Settings{SubtitleSummary: true}
+	if got := forcedOnlySuffix(forced, cfg); got != " / Forced Only" {
+		t.Fatalf("forced track suffix %q", got)
+	}
+	if got := forcedOnlySuffix(full, cfg); got != "" {
+		t.Fatalf("full track suffix %q", got)
+	}
+	if got := forcedOnlySuffix(forced, settings.Settings{}); got != "" {
+		t.Fatalf("suffix %q without SubtitleSummary", got)
+	}
+}
+
+func TestWrite3DOffsets(t *testing.T) {
+	pg := &stream.GraphicsStream{Stream: stream.Stream{PID: 0x1200, StreamType: stream.StreamTypePresentationGraphics, LanguageName: "English"}}
+	playlist := &bdrom.PlaylistFile{
+		Name:    "00800.MPLS",
+		Streams: map[uint16]stream.Info{0x1200: pg},
+		Offsets3D: []bdrom.Offsets3D{{
+			Clip:                   "00001.M2TS",
+			OffsetSequences:        32,
+			FixedOffsetDuringPopUp: true,
+			Subtitles:              []bdrom.SubtitleOffset3D{{PID: 0x1200, OffsetSequence: 1, Stereoscopic: true, StereoscopicOffsetSequence: 7}},
+		}},
+	}
+
+	var b strings.Builder
+	write3DOffsets(&b, playlist, nil)
+	for _, want := range []string{
+		"00001.M2TS      Video           32 sequences        Fixed offset during pop-up\n",
+		"00001.M2TS      4608 (0x1200)   1                   English; Stereoscopic, offset sequence 7\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("3D offsets missing %q:\n%s", want, b.String())
+		}
+	}
+
+	b.Reset()
+	write3DOffsets(&b, &bdrom.PlaylistFile{Name: "00001.MPLS"}, nil)
+	if !strings.HasSuffix(b.String(), "None\n") {
+		t.Fatalf("expected None for 2D playlist:\n%s", b.String())
+	}
+}
+
+func TestWriteCoreDiagnostics(t *testing.T) {
+	core := &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeAC3Audio, BitRate: 640_000}}
+	truehd := &stream.AudioStream{
+		Stream:     stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3TrueHDAudio, PayloadBytes: 40_000_000},
+		CoreStream: core,
+	}
+	rounding := newBitrateRounding(settings.Settings{})
+
+	var b strings.Builder
+	table := newReportTable(false, 16, 16, 16, 16, 24, 24, 24, 16, 16)
+	writeCoreDiagnostics(table, truehd, "eng (English)", 100, rounding)
+	table.write(&b)
+	for _, want := range []string{
+		"                                Core            AC3             eng (English)           100.000                 640                     8,000,000",
+		"                                Extension       TrueHD          eng (English)           100.000                 2,560                   32,000,000",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("core diagnostics missing %q:\n%s", want, b.String())
+		}
+	}
+
+	b.Reset()
+	table = newReportTable(false, 16, 16, 16, 16, 24, 24, 24, 16, 16)
+	writeCoreDiagnostics(table, &stream.AudioStream{Stream: stream.Stream{PayloadBytes: 1000}}, "", 100, rounding)
+	table.write(&b)
+	if b.Len() != 0 {
+		t.Fatalf("expected no rows without a core:\n%s", b.String())
+	}
+}
+
+func TestWrite3DBitrates(t *testing.T) {
+	base := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo, BitRate: 18_500_400}}
+	dependent := &stream.VideoStream{Stream: stream.Stream{PID: 0x1012, StreamType: stream.StreamTypeMVCVideo, BitRate: 9_200_700}}
+	playlist := &bdrom.PlaylistFile{
+		Name:         "00800.MPLS",
+		Settings:     settings.Settings{EnableSSIF: true},
+		MVCBaseViewR: true,
+		VideoStreams: []*stream.VideoStream{base, dependent},
+		StreamClips:  []*bdrom.StreamClip{{Name: "00001.M2TS", InterleavedFileSize: 30_000_000_000}},
+	}
+	rounding := newBitrateRounding(settings.Settings{})
+
+	var b strings.Builder
+	write3DBitrates(&b, playlist, rounding, nil)
+	for _, want := range []string{
+		"Base (AVC)              Right           4113 (0x1011)   18,500 kbps\n",
+		"Dependent (MVC)         Left            4114 (0x1012)   9,201 kbps\n",
+		"Combined                Both                            27,701 kbps\n",
+		"SSIF Size:              30,000,000,000 bytes\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("3D bitrates missing %q:\n%s", want, b.String())
+		}
+	}
+
+	// Without SSIF reading the dependent view is not scanned: no section.
+	b.Reset()
+	playlist.Settings.EnableSSIF = false
+	write3DBitrates(&b, playlist, rounding, nil)
+	if b.Len() != 0 {
+		t.Fatalf("expected no section without SSIF:\n%s", b.String())
+	}
+}
+
+func TestWriteBitrateGraph(t *testing.T) {
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo}}
+	audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio}}
+	var windows []bdrom.StreamDiagnostics
+	for second := range 4 {
+		windows = append(windows, bdrom.StreamDiagnostics{Marker: float64(second) + 0.5, Bytes: uint64(second+1) * 1_000_000})
+	}
+	file := &bdrom.StreamFile{Name: "00001.M2TS", StreamDiagnostics: map[uint16][]bdrom.StreamDiagnostics{0x1011: windows}}
+	playlist := &bdrom.PlaylistFile{
+		Name:          "00800.MPLS",
+		StreamClips:   []*bdrom.StreamClip{{StreamFile: file, TimeOut: 4, Length: 4, RelativeTimeOut: 4}},
+		SortedStreams: []stream.Info{video, audio},
+	}
+
+	var b strings.Builder
+	writeBitrateGraph(&b, playlist, newBitrateRounding(settings.Settings{}), nil)
+	text := b.String()
+	for _, want := range []string{
+		"BITRATE GRAPH:",
+		"Each column is 1 second, from the lowest (.) to the highest (@) column of the stream.",
+		"4113 (0x1011)   8,000 kbps      32,000 kbps     .=*@\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("bitrate graph missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "0x1100") {
+		t.Fatalf("audio without windows graphed:\n%s", text)
+	}
+}
+
+func TestRenderFrameData(t *testing.T) {
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo}}
+	first := &bdrom.StreamFile{Name: "00001.M2TS", StreamDiagnostics: map[uint16][]bdrom.StreamDiagnostics{0x1011: {
+		{Marker: 0, Bytes: 90000, Packets: 480, Tag: "I"},
+		{Marker: 0.5, Bytes: 20000, Packets: 107, Tag: "B"},
+	}}}
+	second := &bdrom.StreamFile{Name: "00002.M2TS", StreamDiagnostics: map[uint16][]bdrom.StreamDiagnostics{0x1011: {
+		{Marker: 9.5, Bytes: 1, Packets: 1, Tag: "P"},
+		{Marker: 10.25, Bytes: 40000, Packets: 213, Tag: "P"},
+	}}}
+	playlist := &bdrom.PlaylistFile{
+		Name: "00800.MPLS",
+		StreamClips: []*bdrom.StreamClip{
+			{StreamFile: first, TimeOut: 1, Length: 1, RelativeTimeOut: 1},
+			{StreamFile: second, TimeIn: 10, TimeOut: 11, Length: 1, RelativeTimeIn: 1, RelativeTimeOut: 2},
+		},
+		VideoStreams:  []*stream.VideoStream{video},
+		SortedStreams: []stream.Info{video},
+	}
+	audioOnly := &bdrom.PlaylistFile{Name: "00801.MPLS"}
+
+	files := RenderFrameData("/out/BDINFO.MOVIE.txt", []*bdrom.PlaylistFile{playlist, audioOnly}, settings.Settings{})
+	if len(files) != 1 {
+		t.Fatalf("files got=%d want=1", len(files))
+	}
+	if got, want := files[0].Path, "/out/BDINFO.MOVIE.00800.FrameData.txt"; got != want {
+		t.Fatalf("path got=%q want=%q", got, want)
+	}
+	want := "# 00800.MPLS PID 4113 (0x1011) MPEG-4 AVC Video\n" +
+		"# Time\tType\tBytes\tPackets\n" +
+		"0.000000\tI\t90000\t480\n" +
+		"0.500000\tB\t20000\t107\n" +
+		"1.250000\tP\t40000\t213\n"
+	if files[0].Data != want {
+		t.Fatalf("data got:\n%s\nwant:\n%s", files[0].Data, want)
+	}
+}
+
+func TestWriteHiddenStreams(t *testing.T) {
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo}}
+	audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio, IsHidden: true, HiddenReason: stream.HiddenReasonNotInPlaylist}}
+	playlist := &bdrom.PlaylistFile{Name: "00800.MPLS", SortedStreams: []stream.Info{video, audio}}
+
+	var b strings.Builder
+	writeHiddenStreams(&b, playlist, nil)
+	text := b.String()
+	if !strings.Contains(text, "4352 (0x1100)   Dolby Digital Audio     not listed in the playlist STN table\n") {
+		t.Fatalf("hidden stream row missing:\n%s", text)
+	}
+	if strings.Contains(text, "0x1011") {
+		t.Fatalf("visible stream listed:\n%s", text)
+	}
+}
+
+func TestWriteAngleTotals(t *testing.T) {
+	clip := func(angle int, length float64, packets uint64) *bdrom.StreamClip {
+		return &bdrom.StreamClip{AngleIndex: angle, Length: length, PacketCount: packets}
+	}
+	playlist := &bdrom.PlaylistFile{
+		Name:       "00001.MPLS",
+		AngleCount: 1,
+		StreamClips: []*bdrom.StreamClip{
+			clip(0, 10, 1000),
+			clip(0, 20, 2000),
+			clip(1, 20, 3000),
+		},
+	}
+
+	var b strings.Builder
+	writeAngleTotals(&b, playlist, newBitrateRounding(settings.Settings{}))
+	text := b.String()
+	for _, want := range []string{
+		"Angle 1 Length:         0:00:20.000 (h:m:s.ms) / 0:00:30.000 (h:m:s.ms)\n",
+		"Angle 1 Size:           576,000 bytes / 768,000 bytes\n",
+		"All Angles Size:        1,152,000 bytes\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("angle totals missing %q:\n%s", want, text)
+		}
+	}
+
+	b.Reset()
+	playlist.AngleCount = 0
+	writeAngleTotals(&b, playlist, newBitrateRounding(settings.Settings{}))
+	if b.Len() != 0 {
+		t.Fatalf("unexpected angle totals for single-angle playlist:\n%s", b.String())
+	}
+}
+
+func TestSortPlaylists(t *testing.T) {
+	mk := func(name string, length float64, packets uint64) *bdrom.PlaylistFile {
+		return &bdrom.PlaylistFile{Name: name, StreamClips: []*bdrom.StreamClip{{Length: length, PacketCount: packets, FileSize: packets * 192}}}
+	}
+	a := mk("00003.MPLS", 100, 3000) // largest, highest bitrate
+	b := mk("00001.MPLS", 400, 2000) // longest
+	c := mk("00002.MPLS", 50, 1000)
+
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{key: "", want: []string{"00003.MPLS", "00001.MPLS", "00002.MPLS"}},
+		{key: PlaylistSortSize, want: []string{"00003.MPLS", "00001.MPLS", "00002.MPLS"}},
+		{key: PlaylistSortLength, want: []string{"00001.MPLS", "00003.MPLS", "00002.MPLS"}},
+		{key: PlaylistSortName, want: []string{"00001.MPLS", "00002.MPLS", "00003.MPLS"}},
+		{key: PlaylistSortBitrate, want: []string{"00003.MPLS", "00002.MPLS", "00001.MPLS"}},
+	}
+	for _, tt := range tests {
+		unsorted := []*bdrom.PlaylistFile{c, b, a}
+		playlists := SortPlaylists(unsorted, tt.key)
+		if unsorted[0] != c || unsorted[1] != b || unsorted[2] != a {
+			t.Fatalf("SortPlaylists(%q) reordered its input", tt.key)
+		}
+		got := make([]string, 0, len(playlists))
+		for _, p := range playlists {
+			got = append(got, p.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Fatalf("SortPlaylists(%q) got=%v want=%v", tt.key, got, tt.want)
+		}
+	}
+
+	if ValidPlaylistSort("date") {
+		t.Fatalf("ValidPlaylistSort(date) = true, want false")
+	}
+}
+
+func TestSelectTopPlaylists(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	mk := func(name string, length float64) *bdrom.PlaylistFile {
+		return &bdrom.PlaylistFile{Name: name, IsInitialized: true, Settings: cfg, StreamClips: []*bdrom.StreamClip{{Length: length, PacketCount: 1000}}}
+	}
+	playlists := []*bdrom.PlaylistFile{mk("00001.MPLS", 1200), mk("00002.MPLS", 3600), mk("00003.MPLS", 5), mk("00004.MPLS", 2400)}
+
+	got := selectTopPlaylists(playlists, 2, cfg)
+	if len(got) != 2 || got[0].Name != "00002.MPLS" || got[1].Name != "00004.MPLS" {
+		t.Fatalf("selectTopPlaylists(2) got=%v", got)
+	}
+	// Short playlists are filtered before ranking, so N larger than the valid set shrinks.
+	if got := selectTopPlaylists(playlists, 10, cfg); len(got) != 3 {
+		t.Fatalf("selectTopPlaylists(10) got=%d playlists want 3", len(got))
+	}
+	if got := selectTopPlaylists(playlists, 0, cfg); len(got) != len(playlists) {
+		t.Fatalf("selectTopPlaylists(0) should keep all playlists")
+	}
+}
+
+func TestRenderReport_PlaylistBlocksKeepOrder(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	cfg.PlaylistSort = PlaylistSortName
+	cfg.GenerateStreamDiagnostics = true
+
+	bd := &bdrom.BDROM{VolumeLabel: "DISC"}
+	var playlists []*bdrom.PlaylistFile
+	for i := 40; i > 0; i-- {
+		clip := &bdrom.StreamClip{Name: fmt.Sprintf("%05d.M2TS", i), Length: float64(i * 60), PacketCount: uint64(i * 1000)}
+		playlists = append(playlists, &bdrom.PlaylistFile{Name: fmt.Sprintf("%05d.MPLS", i), Settings: cfg, StreamClips: []*bdrom.StreamClip{clip}})
+	}
+
+	_, output, err := RenderReport("", bd, playlists, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := -1
+	for i := 1; i <= 40; i++ {
+		idx := strings.Index(output, fmt.Sprintf("PLAYLIST: %05d.MPLS\n", i))
+		if idx <= last {
+			t.Fatalf("playlist %05d block at %d, want after %d", i, idx, last)
+		}
+		last = idx
+	}
+}
+
+func TestRenderReport_CollapseDuplicates(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	cfg.PlaylistSort = PlaylistSortName
+	bd := &bdrom.BDROM{VolumeLabel: "DISC"}
+	a := &bdrom.StreamClip{Name: "00001.M2TS", TimeOut: 600, Length: 600, PacketCount: 1000}
+	b := &bdrom.StreamClip{Name: "00002.M2TS", TimeOut: 600, Length: 600, PacketCount: 1000}
+	playlists := []*bdrom.PlaylistFile{
+		{Name: "00800.MPLS", Settings: cfg, StreamClips: []*bdrom.StreamClip{a, b}},
+		{Name: "00801.MPLS", Settings: cfg, StreamClips: []*bdrom.StreamClip{b, a}},
+		{Name: "00802.MPLS", Settings: cfg, StreamClips: []*bdrom.StreamClip{a, b}},
+	}
+
+	_, output, err := RenderReport("", bd, playlists, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output, "DUPLICATE PLAYLISTS:") || !strings.Contains(output, "PLAYLIST: 00801.MPLS\n") {
+		t.Fatalf("duplicates collapsed without CollapseDuplicates:\n%s", output)
+	}
+
+	cfg.CollapseDuplicates = true
+	_, output, err = RenderReport("", bd, playlists, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"DUPLICATE PLAYLISTS:\n\nPlaylist obfuscation detected.\n\n",
+		"00800.MPLS      reordered       00801.MPLS, 00802.MPLS\n",
+		"PLAYLIST: 00800.MPLS\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("report missing %q:\n%s", want, output)
+		}
+	}
+	for _, dropped := range []string{"PLAYLIST: 00801.MPLS\n", "PLAYLIST: 00802.MPLS\n"} {
+		if strings.Contains(output, dropped) {
+			t.Fatalf("report kept duplicate %q", dropped)
+		}
+	}
+}
+
+func TestRenderReport_XML(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	cfg.ReportFileName = filepath.Join(t.TempDir(), "report.xml")
+
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo, BitRate: 30_000_000}}
+	audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio, BitRate: 640_000, LanguageName: "English"}, ChannelCount: 5, LFE: 1}
+	audio.SetLanguageCode("eng")
+	clip := &bdrom.StreamClip{Name: "00001.M2TS", Length: 3600, PacketCount: 1000}
+	playlist := &bdrom.PlaylistFile{
+		Name:          "00800.MPLS",
+		Settings:      cfg,
+		StreamClips:   []*bdrom.StreamClip{clip},
+		Chapters:      []float64{0, 1800},
+		SortedStreams: []stream.Info{video, audio},
+		VideoStreams:  []*stream.VideoStream{video},
+		AudioStreams:  []*stream.AudioStream{audio},
+	}
+	bd := &bdrom.BDROM{VolumeLabel: "DISC & CO", IsUHD: true, Size: 1234}
+
+	reportPath, output, err := RenderReport("", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reportPath != cfg.ReportFileName {
+		t.Fatalf("report path %q want %q", reportPath, cfg.ReportFileName)
+	}
+	if !strings.HasPrefix(output, "<?xml") {
+		t.Fatalf("expected an XML document, got:\n%s", output)
+	}
+
+	var doc xmlReport
+	if err := xml.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, output)
+	}
+	if doc.Disc.Label != "DISC & CO" || doc.Disc.Protection != "AACS2" || doc.Disc.Size != 1234 || len(doc.Disc.Extras) != 1 {
+		t.Fatalf("disc = %+v", doc.Disc)
+	}
+	if len(doc.Playlists) != 1 {
+		t.Fatalf("playlists = %d want 1", len(doc.Playlists))
+	}
+	pl := doc.Playlists[0]
+	if pl.Name != "00800.MPLS" || pl.LengthSeconds != "3600.000" || len(pl.VideoStreams) != 1 || len(pl.AudioStreams) != 1 {
+		t.Fatalf("playlist = %+v", pl)
+	}
+	if a := pl.AudioStreams[0]; a.LanguageCode != "eng" || a.Bitrate != 640_000 || a.PID != 0x1100 || a.ID != "00800.MPLS/audio/4352" {
+		t.Fatalf("audio stream = %+v", a)
+	}
+	if len(pl.Chapters) != 2 || pl.Chapters[1].TimeIn != "1800.000" || pl.Chapters[1].Length != "1800.000" {
+		t.Fatalf("chapters = %+v", pl.Chapters)
+	}
+	if len(pl.Files) != 1 || pl.Files[0].Name != "00001.M2TS" {
+		t.Fatalf("files = %+v", pl.Files)
+	}
+}
+
+func TestWriteReport_CSV(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	reportPath := filepath.Join(t.TempDir(), "report.csv")
+
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo, BitRate: 30_000_000}}
+	audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio, BitRate: 640_000, LanguageName: "English"}, ChannelCount: 5, LFE: 1}
+	playlist := &bdrom.PlaylistFile{
+		Name:     "00800.MPLS",
+		Settings: cfg,
+		StreamClips: []*bdrom.StreamClip{
+			{Name: "00001.M2TS", Length: 1800, PacketCount: 1000},
+			{Name: "00002.M2TS", Length: 1200, RelativeTimeIn: 1800, PacketCount: 500},
+		},
+		SortedStreams: []stream.Info{video, audio},
+		VideoStreams:  []*stream.VideoStream{video},
+		AudioStreams:  []*stream.AudioStream{audio},
+	}
+
+	if _, err := WriteReport(reportPath, &bdrom.BDROM{VolumeLabel: "DISC"}, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	readCSV := func(path string) [][]string {
+		t.Helper()
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		rows, err := csv.NewReader(f).ReadAll()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		return rows
+	}
+
+	streams := readCSV(reportPath)
+	if len(streams) != 3 || streams[0][0] != "Playlist" {
+		t.Fatalf("stream rows = %q", streams)
+	}
+	if got := streams[2]; got[0] != "00800.MPLS" || got[1] != "4352" || got[2] != "Audio" || got[4] != "English" || got[5] != "640000" || got[7] != "00800.MPLS/audio/4352" {
+		t.Fatalf("audio row = %q", got)
+	}
+
+	clips := readCSV(ClipsCSVPath(reportPath))
+	if len(clips) != 3 || clips[2][1] != "00002.M2TS" || clips[2][3] != "1800.000" || clips[2][4] != "1200.000" {
+		t.Fatalf("clip rows = %q", clips)
+	}
+}
+
+func TestStreamID(t *testing.T) {
+	tests := []struct {
+		name string
+		st   stream.Info
+		want string
+	}{
+		{"video", &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeHEVCVideo}}, "00800.MPLS/video/4113"},
+		{"angle", &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeHEVCVideo, AngleIndex: 2}}, "00800.MPLS/video/4113/angle2"},
+		{"subtitle", &stream.GraphicsStream{Stream: stream.Stream{PID: 0x12A0, StreamType: stream.StreamTypePresentationGraphics}}, "00800.MPLS/subtitle/4768"},
+	}
+	for _, tt := range tests {
+		if got := StreamID("00800.MPLS", tt.st); got != tt.want {
+			t.Errorf("%s: StreamID = %q want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRenderReport_FileErrorsSorted(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	scan := bdrom.ScanResult{FileErrors: map[string]error{}}
+	for _, name := range []string{"00005.M2TS", "00001.M2TS", "00009.M2TS", "00003.M2TS", "00007.M2TS"} {
+		scan.FileErrors[name] = errors.New("read error")
+	}
+
+	_, output, err := RenderReport("", &bdrom.BDROM{VolumeLabel: "DISC"}, nil, scan, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := -1
+	for _, name := range []string{"00001.M2TS", "00003.M2TS", "00005.M2TS", "00007.M2TS", "00009.M2TS"} {
+		idx := strings.Index(output, "\n"+name+"\t")
+		if idx <= last {
+			t.Fatalf("file error %s at %d, want after %d:\n%s", name, idx, last, output)
+		}
+		last = idx
+	}
+}
+
+func TestRenderReport_BackupFiles(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	scan := bdrom.ScanResult{BackupFiles: map[string]error{"00800.MPLS": errors.New("playlist 00800.MPLS has unknown file type XXXX0000")}}
+
+	_, output, err := RenderReport("", &bdrom.BDROM{VolumeLabel: "DISC"}, nil, scan, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "WARNING: Files were read from BDMV/BACKUP because the primary copy is unreadable:\n\n00800.MPLS\tplaylist 00800.MPLS has unknown file type XXXX0000\n"
+	if !strings.Contains(output, want) {
+		t.Fatalf("backup note missing:\n%s", output)
+	}
+}
+
+func TestRenderReport_StructureWarnings(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	bd := &bdrom.BDROM{
+		VolumeLabel:     "DISC",
+		PlaylistFiles:   map[string]*bdrom.PlaylistFile{"00800.MPLS": {Name: "00800.MPLS", StructureWarnings: []string{"PlayListMark at 80 declares 40 bytes, 24 past the end of the file (100 bytes)"}}},
+		StreamClipFiles: map[string]*bdrom.StreamClipFile{"00001.CLPI": {Name: "00001.CLPI"}},
+	}
+
+	_, output, err := RenderReport("", bd, nil, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "WARNING: Structural anomalies were found in playlist and clip info files:\n\n00800.MPLS\tPlayListMark at 80 declares 40 bytes, 24 past the end of the file (100 bytes)\n"
+	if !strings.Contains(output, want) {
+		t.Fatalf("structure warning missing:\n%s", output)
+	}
+	if strings.Contains(output, "00001.CLPI") {
+		t.Fatalf("clip info without anomalies listed:\n%s", output)
+	}
+}
+
+func TestWriteClipInfo(t *testing.T) {
+	clipFile := &bdrom.StreamClipFile{Name: "00001.CLPI", ApplicationType: 1, TSRecordingRate: 6_000_000, SourcePacketCount: 123456, FormatIdentifier: "HDMV"}
+	playlist := &bdrom.PlaylistFile{StreamClips: []*bdrom.StreamClip{
+		{StreamClipFile: clipFile},
+		{StreamClipFile: clipFile},
+	}}
+
+	var b strings.Builder
+	writeClipInfo(&b, playlist, nil)
+	text := b.String()
+	if !strings.Contains(text, "00001.CLPI      Main TS (movie)                 48.00 Mbps      123,456         HDMV\n") {
+		t.Fatalf("clip info row missing:\n%s", text)
+	}
+	if strings.Count(text, "00001.CLPI") != 1 {
+		t.Fatalf("expected one row per clip file:\n%s", text)
+	}
+}
+
+func TestRenderReport_WideColumns(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio, BitRate: 640_000}, ChannelCount: 5, LFE: 1}
+	audio.SetLanguageCode("alg")
+	playlist := &bdrom.PlaylistFile{
+		Name:          "00800.MPLS",
+		Settings:      cfg,
+		StreamClips:   []*bdrom.StreamClip{{Name: "00001.M2TS", Length: 3600}},
+		SortedStreams: []stream.Info{audio},
+		AudioStreams:  []*stream.AudioStream{audio},
+	}
+	bd := &bdrom.BDROM{VolumeLabel: "DISC"}
+
+	audioRow := func(text string) (header, row string) {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			if line == "AUDIO:" {
+				return lines[i+3], lines[i+5]
+			}
+		}
+		t.Fatalf("no AUDIO section:\n%s", text)
+		return "", ""
+	}
+
+	_, text, err := RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, row := audioRow(text)
+	if want := fmt.Sprintf("%-32s%-16s%-16s%-16s", "Dolby Digital Audio", "Algonquian languages", "640 kbps", audio.Description()); row != want {
+		t.Fatalf("default row got=%q want=%q", row, want)
+	}
+	if strings.Index(header, "Bitrate") != 48 {
+		t.Fatalf("default header got=%q", header)
+	}
+
+	cfg.WideColumns = true
+	_, text, err = RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, row = audioRow(text)
+	if got, want := strings.Index(header, "Bitrate"), strings.Index(row, "640 kbps"); got != want || got != 32+len("Algonquian languages")+2 {
+		t.Fatalf("wide columns not aligned:\n%s\n%s", header, row)
+	}
+}
+
+func TestRenderReport_EmptyPlaylists(t *testing.T) {
+	playlists := func(cfg settings.Settings) []*bdrom.PlaylistFile {
+		video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo, IsVBR: true}}
+		file := &bdrom.StreamFile{Name: "00002.M2TS", Streams: map[uint16]stream.Info{0x1011: video}}
+		return []*bdrom.PlaylistFile{
+			{Name: "00001.MPLS", Settings: cfg},
+			{
+				Name:          "00002.MPLS",
+				Settings:      cfg,
+				StreamClips:   []*bdrom.StreamClip{{Name: "00002.M2TS", StreamFile: file}},
+				Chapters:      []float64{0},
+				Streams:       map[uint16]stream.Info{0x1011: video},
+				SortedStreams: []stream.Info{video},
+				VideoStreams:  []*stream.VideoStream{video},
+			},
+		}
+	}
+	bd := &bdrom.BDROM{VolumeLabel: "EMPTY"}
+
+	variants := map[string]func(*settings.Settings){
+		"default": func(*settings.Settings) {},
+		"smpte":   func(s *settings.Settings) { s.TimeFormat = TimeFormatSMPTE },
+		"seconds": func(s *settings.Settings) { s.TimeFormat = TimeFormatSeconds },
+		"main":    func(s *settings.Settings) { s.MainPlaylistOnly = true },
+		"summary": func(s *settings.Settings) { s.SummaryOnly = true },
+		"extended": func(s *settings.Settings) {
+			s.ExtendedStreamDiagnostics, s.TrackUnknownPIDs, s.IncludeRestrictions = true, true, true
+		},
+	}
+	for name, apply := range variants {
+		for _, ext := range []string{".txt", ".xml", ".csv"} {
+			cfg := settings.Default(t.TempDir())
+			apply(&cfg)
+			reportPath := filepath.Join(t.TempDir(), "report"+ext)
+			_, output, err := RenderReport(reportPath, bd, playlists(cfg), bdrom.ScanResult{}, cfg)
+			if err != nil {
+				t.Fatalf("%s%s: %v", name, ext, err)
+			}
+			for _, bad := range []string{"NaN", "+Inf", "-Inf"} {
+				if strings.Contains(output, bad) {
+					t.Fatalf("%s%s report contains %s:\n%s", name, ext, bad, output)
+				}
+			}
+		}
+	}
+
+	cfg := settings.Default(t.TempDir())
+	_, output, err := RenderReport("-", bd, playlists(cfg), bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output, "AUDIO:") || strings.Contains(output, "No chapters") {
+		t.Fatalf("default report has placeholder sections:\n%s", output)
+	}
+
+	cfg.ShowEmptySections = true
+	_, output, err = RenderReport("-", bd, playlists(cfg), bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"No video streams", "No audio streams", "No subtitles", "No stream files", "No chapters"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("placeholder %q missing:\n%s", want, output)
+		}
+	}
+	for _, issue := range Lint(output) {
+		if issue.Severity == LintError {
+			t.Fatalf("placeholder report fails lint: %v", issue)
+		}
+	}
+}
+
+func TestRenderOneLine(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeHEVCVideo}, Height: 2160}
+	video.ExtendedData = &stream.HEVCExtendedData{ExtendedFormatInfo: []string{"10 bits", "HDR10"}, DolbyVision: &stream.DolbyVision{}}
+	hiddenAudio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio, IsHidden: true}, ChannelCount: 2}
+	audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1101, StreamType: stream.StreamTypeAC3TrueHDAudio}, ChannelCount: 7, LFE: 1, HasExtensions: true}
+	var subs []*stream.GraphicsStream
+	for i, code := range []string{"eng", "fra", "eng"} {
+		sub := &stream.GraphicsStream{Stream: stream.Stream{PID: uint16(0x1200 + i), StreamType: stream.StreamTypePresentationGraphics}}
+		sub.SetLanguageCode(code)
+		subs = append(subs, sub)
+	}
+	playlist := &bdrom.PlaylistFile{
+		Name:            "00800.MPLS",
+		Settings:        cfg,
+		StreamClips:     []*bdrom.StreamClip{{Name: "00001.M2TS", Length: 8045, PacketCount: 200_000_000}},
+		VideoStreams:    []*stream.VideoStream{video},
+		AudioStreams:    []*stream.AudioStream{hiddenAudio, audio},
+		GraphicsStreams: subs,
+	}
+	bd := &bdrom.BDROM{VolumeLabel: "MOVIE"}
+
+	want := "MOVIE | 2:14:05 | 35.76 GB | HEVC 2160p DV HDR10 | TrueHD Atmos 7.1 | eng,fra subs"
+	if got := RenderOneLine(bd, []*bdrom.PlaylistFile{playlist}, cfg); got != want {
+		t.Fatalf("RenderOneLine() got=%q want=%q", got, want)
+	}
+	if got, want := RenderOneLine(bd, nil, cfg), "MOVIE | no main playlist"; got != want {
+		t.Fatalf("RenderOneLine(no playlists) got=%q want=%q", got, want)
+	}
+}
+
+func TestRenderReport_RoundingMode(t *testing.T) {
+	cfg := settings.Default(t.TempDir())
+	// Both bitrates sit exactly halfway: 640.5 kbps and 30.005 Mbps.
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo, BitRate: 30_005_000}}
+	audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio, BitRate: 640_500}, ChannelCount: 5, LFE: 1}
+	playlist := &bdrom.PlaylistFile{
+		Name:          "00800.MPLS",
+		Settings:      cfg,
+		StreamClips:   []*bdrom.StreamClip{{Name: "00001.M2TS", Length: 3600}},
+		SortedStreams: []stream.Info{video, audio},
+		VideoStreams:  []*stream.VideoStream{video},
+		AudioStreams:  []*stream.AudioStream{audio},
+	}
+	bd := &bdrom.BDROM{VolumeLabel: "DISC"}
+
+	tests := []struct {
+		mode       string
+		want, not  []string
+		wantErrMsg string
+	}{
+		// Official BDInfo rounds the audio description half up but the
+		// tables half to even, so "641 kbps" still shows in the description.
+		{mode: "", want: []string{"640 kbps ", "640Kbps", " 30.00 ", "/   641 kbps"}, not: []string{"641Kbps", " 30.01 "}},
+		{mode: RoundingOfficial, want: []string{"640 kbps ", "640Kbps", " 30.00 ", "/   641 kbps"}, not: []string{"641Kbps", " 30.01 "}},
+		{mode: RoundingMathematical, want: []string{"641 kbps", "641Kbps", " 30.01 "}, not: []string{"640 kbps", "640Kbps", " 30.00 "}},
+		{mode: "bankers", wantErrMsg: "unsupported rounding mode: bankers"},
+	}
+	for _, tt := range tests {
+		cfg.RoundingMode = tt.mode
+		_, text, err := RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+		if tt.wantErrMsg != "" {
+			if err == nil || err.Error() != tt.wantErrMsg {
+				t.Fatalf("mode %q err=%v", tt.mode, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(text, want) {
+				t.Fatalf("mode %q missing %q:\n%s", tt.mode, want, text)
+			}
+		}
+		for _, not := range tt.not {
+			if strings.Contains(text, not) {
+				t.Fatalf("mode %q has %q:\n%s", tt.mode, not, text)
+			}
+		}
+	}
+}
+
+func TestRenderReport_GeneratedBy(t *testing.T) {
+	bd := &bdrom.BDROM{VolumeLabel: "TEST_DISC", Size: 1024}
+	playlist := &bdrom.PlaylistFile{Name: "00800.MPLS", IsInitialized: true}
+	cfg := settings.Default(t.TempDir())
+	cfg.FilterShortPlaylists = false
+	cfg.ToolVersion = "v1.2.3"
+
+	_, text, err := RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(text, "Generated by") {
+		t.Fatalf("footer should be opt-in:\n%s", text)
+	}
+
+	cfg.IncludeGeneratedBy = true
+	const footer = "Generated by:   go-bdinfo v1.2.3 (BDInfo 0.8.0.0)\n"
+	_, text, err = RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(text, "\n\n"+footer) || !strings.Contains(text, "BDInfo:         0.8.0.0\n") {
+		t.Fatalf("full report footer:\n%s", text)
+	}
+	for _, issue := range Lint(text) {
+		if issue.Severity == LintError {
+			t.Fatalf("footer breaks lint: %s", issue)
+		}
+	}
+
+	cfg.SummaryOnly = true
+	_, text, err = RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(text, footer) {
+		t.Fatalf("summary footer:\n%s", text)
+	}
+
+	cfg.SummaryOnly = false
+	cfg.ReportLanguage = "de"
+	_, text, err = RenderReport("-", bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(text, "Erstellt mit:   go-bdinfo v1.2.3 (BDInfo 0.8.0.0)\n") {
+		t.Fatalf("translated footer:\n%s", text)
+	}
+
+	cfg.ReportLanguage = "en"
+	_, text, err = RenderReport(filepath.Join(t.TempDir(), "r.xml"), bd, []*bdrom.PlaylistFile{playlist}, bdrom.ScanResult{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, `Version="0.8.0.0" GeneratedBy="go-bdinfo v1.2.3"`) {
+		t.Fatalf("XML generator attribute:\n%s", text)
+	}
+}
+
+func TestRenderReport_ConcurrentRenders(t *testing.T) {
+	base := settings.Default(t.TempDir())
+	base.FilterShortPlaylists = false
+	mk := func(name string, length float64, packets uint64) *bdrom.PlaylistFile {
+		video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeHEVCVideo, BitRate: 40_000_000}, Height: 2160}
+		audio := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3TrueHDAudio, BitRate: 4_000_000}, ChannelCount: 7, LFE: 1}
+		audio.SetLanguageCode("eng")
+		file := &bdrom.StreamFile{Name: name + ".M2TS", Length: length, Streams: map[uint16]stream.Info{video.PID: video, audio.PID: audio}}
+		clip := &bdrom.StreamClip{Name: file.Name, Length: length, PacketCount: packets, FileSize: packets * 192, StreamFile: file}
+		return &bdrom.PlaylistFile{
+			Name:          name + ".MPLS",
+			Settings:      base,
+			IsInitialized: true,
+			Chapters:      []float64{0, length / 2},
+			StreamClips:   []*bdrom.StreamClip{clip},
+			Streams:       map[uint16]stream.Info{video.PID: video, audio.PID: audio},
+			SortedStreams: []stream.Info{video, audio},
+			VideoStreams:  []*stream.VideoStream{video},
+			AudioStreams:  []*stream.AudioStream{audio},
+		}
+	}
+	playlists := []*bdrom.PlaylistFile{mk("00001", 100, 3000), mk("00002", 5000, 900000), mk("00003", 300, 5000)}
+	bd := &bdrom.BDROM{VolumeLabel: "DISC", Size: 1 << 30}
+
+	variants := []settings.Settings{base, base, base, base, base, base}
+	variants[1].MainPlaylistOnly = true
+	variants[2].PlaylistSort = PlaylistSortName
+	variants[3].SummaryOnly = true
+	variants[4].ForumsOnly = true
+	variants[5].TopPlaylists = 2
+	render := func(cfg settings.Settings) string {
+		_, text, err := RenderReport("-", bd, playlists, bdrom.ScanResult{}, cfg)
+		if err != nil {
+			t.Error(err)
+		}
+		return text + RenderOneLine(bd, playlists, cfg)
+	}
+	want := make([]string, len(variants))
+	for i, cfg := range variants {
+		want[i] = render(cfg)
+	}
+
+	// Run with -race: renders only read the scanned disc.
+	var wg sync.WaitGroup
+	for i := range 4 * len(variants) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := render(variants[i%len(variants)]); got != want[i%len(variants)] {
+				t.Errorf("variant %d differs when rendered concurrently", i%len(variants))
+			}
+		}()
+	}
+	wg.Wait()
+	if playlists[0].Name != "00001.MPLS" || playlists[1].Name != "00002.MPLS" || playlists[2].Name != "00003.MPLS" {
+		t.Fatalf("render reordered the scan's playlists")
+	}
+}
+
+func TestDiscProtection(t *testing.T) {
+	details := settings.Settings{IncludeProtectionDetails: true}
+	for _, tc := range []struct {
+		bd       bdrom.BDROM
+		settings settings.Settings
+		want     string
+	}{
+		{bdrom.BDROM{MKBVersion: 68}, settings.Settings{}, "AACS"},
+		{bdrom.BDROM{MKBVersion: 68}, details, "AACS (MKB v68)"},
+		{bdrom.BDROM{IsUHD: true, MKBVersion: 72}, details, "AACS2 (MKB v72)"},
+		{bdrom.BDROM{IsBDPlus: true, MKBVersion: 40, BDPlusDate: "2012-05-07"}, details, "BD+ (MKB v40, SVM 2012-05-07)"},
+		{bdrom.BDROM{IsBDPlus: true}, details, "BD+"},
+	} {
+		if got := discProtection(&tc.bd, tc.settings); got != tc.want {
+			t.Fatalf("discProtection(%+v)=%q, want %q", tc.settings, got, tc.want)
+		}
+	}
+}
+
+func TestWriteDeclaredRates(t *testing.T) {
+	ac3 := &stream.AudioStream{Stream: stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3Audio, BitRate: 640_000, PayloadBytes: 8_000_000}}
+	file := &bdrom.StreamFile{
+		Name:    "00001.M2TS",
+		Size:    192 * 1_000_000,
+		Length:  100,
+		Streams: map[uint16]stream.Info{0x1100: ac3},
+	}
+	playlist := &bdrom.PlaylistFile{StreamClips: []*bdrom.StreamClip{{
+		Name:           "00001.M2TS",
+		StreamFile:     file,
+		StreamClipFile: &bdrom.StreamClipFile{TSRecordingRate: 6_000_000, SourcePacketCount: 1_000_000},
+	}}}
+
+	var b strings.Builder
+	writeDeclaredRates(&b, playlist, newBitrateRounding(settings.Settings{}), nil)
+	for _, want := range []string{
+		"DECLARED VS MEASURED:",
+		"00001.M2TS      Source packets          1,000,000           1,000,000           +0.00%      \n",
+		"00001.M2TS      TS recording rate       48.00 Mbps          15.04 Mbps          -68.67%     \n",
+		"00001.M2TS      4352 (0x1100) AC3       640 kbps            640 kbps            +0.00%      \n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("declared rates missing %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+// Rounding modes accepted by Settings.RoundingMode.
+const (
+	RoundingOfficial     = "official"     // half to even, as official BDInfo (default)
+	RoundingMathematical = "mathematical" // half away from zero
+)
+
+// bitrateRounding rounds kbps and Mbps values according to Settings.RoundingMode.
+// Audio descriptions always round half up, as in official BDInfo, which agrees
+// with the mathematical mode for the positive values reported.
+type bitrateRounding func(float64) float64
+
+func newBitrateRounding(settings settings.Settings) bitrateRounding {
+	if strings.ToLower(strings.TrimSpace(settings.RoundingMode)) == RoundingMathematical {
+		return math.Round
+	}
+	return math.RoundToEven
+}
+
+// ValidRoundingMode reports whether mode is a supported Settings.RoundingMode value.
+func ValidRoundingMode(mode string) bool {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", RoundingOfficial, RoundingMathematical:
+		return true
+	default:
+		return false
+	}
+}
+
+// kbps converts bits per second to whole kbps.
+func (r bitrateRounding) kbps(bitrate float64) int64 {
+	return int64(r(bitrate / 1000))
+}
+
+// mbps formats bits per second as Mbps with two decimals.
+func (r bitrateRounding) mbps(bitrate uint64) string {
+	if bitrate == 0 {
+		return "0.00"
+	}
+	return fmt.Sprintf("%.2f", r(float64(bitrate)/10000.0)/100.0)
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// wideColumnGap is the space left after the longest cell of a widened column.
+const wideColumnGap = 2
+
+// reportTable buffers a text report table so its columns can be sized before
+// writing. Columns have BDInfo's fixed widths, and a cell longer than its
+// column runs into the next one as in official BDInfo; with wide set, every
+// column but the last grows to fit its longest cell, header and divider
+// included, so rows stay aligned.
+type reportTable struct {
+	widths []int
+	rows   [][]string
+	wide   bool
+}
+
+func newReportTable(wide bool, widths ...int) *reportTable {
+	return &reportTable{widths: widths, wide: wide}
+}
+
+// row adds one line; it takes one cell per column.
+func (t *reportTable) row(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+func (t *reportTable) write(b *strings.Builder) {
+	widths := t.widths
+	if t.wide {
+		widths = append([]int(nil), t.widths...)
+		for _, cells := range t.rows {
+			for i, cell := range cells[:len(cells)-1] {
+				widths[i] = max(widths[i], utf8.RuneCountInString(cell)+wideColumnGap)
+			}
+		}
+	}
+	for _, cells := range t.rows {
+		for i, cell := range cells {
+			fmt.Fprintf(b, "%-*s", widths[i], cell)
+		}
+		b.WriteByte('\n')
+	}
+}
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/util"
+)
+
+// Time formats accepted by Settings.TimeFormat.
+const (
+	TimeFormatHMS     = "hms"     // h:mm:ss.mmm (BDInfo default)
+	TimeFormatSMPTE   = "smpte"   // h:mm:ss:ff at the playlist frame rate
+	TimeFormatSeconds = "seconds" // raw seconds with millisecond precision
+)
+
+// timeFormatter renders CHAPTERS/FILES timestamps according to Settings.TimeFormat.
+type timeFormatter struct {
+	format string
+	fps    float64
+}
+
+func newTimeFormatter(settings settings.Settings, playlist *bdrom.PlaylistFile) timeFormatter {
+	f := timeFormatter{format: strings.ToLower(strings.TrimSpace(settings.TimeFormat))}
+	if f.format == TimeFormatSMPTE && playlist != nil && len(playlist.VideoStreams) > 0 {
+		vs := playlist.VideoStreams[0]
+		if vs.FrameRateEnum > 0 && vs.FrameRateDen > 0 {
+			f.fps = float64(vs.FrameRateEnum) / float64(vs.FrameRateDen)
+		}
+	}
+	return f
+}
+
+// ValidTimeFormat reports whether format is a supported Settings.TimeFormat value.
+func ValidTimeFormat(format string) bool {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", TimeFormatHMS, TimeFormatSMPTE, TimeFormatSeconds:
+		return true
+	default:
+		return false
+	}
+}
+
+// FormatDuration renders seconds for a playlist using Settings.TimeFormat.
+func FormatDuration(seconds float64, playlist *bdrom.PlaylistFile, settings settings.Settings) string {
+	return newTimeFormatter(settings, playlist).formatTime(seconds, false)
+}
+
+func (f timeFormatter) formatTime(seconds float64, padHour bool) string {
+	switch f.format {
+	case TimeFormatSeconds:
+		if math.IsNaN(seconds) || seconds < 0 {
+			seconds = 0
+		}
+		return fmt.Sprintf("%.3f", seconds)
+	case TimeFormatSMPTE:
+		if f.fps > 0 {
+			return formatTimeSMPTE(seconds, f.fps, padHour)
+		}
+	}
+	return formatTimeHmsms(seconds, padHour)
+}
+
+// formatTimeSMPTE renders whole seconds as h:mm:ss and the remainder as a frame count.
+func formatTimeSMPTE(seconds float64, fps float64, padHour bool) string {
+	ticks := util.TimeTicks(seconds)
+	totalSeconds := ticks / 10000000
+	frac := float64(ticks%10000000) / 10000000.0
+	ff := int(math.Floor(frac*fps + 1e-6))
+	if nominal := int(math.Ceil(fps)); ff >= nominal {
+		ff = nominal - 1
+	}
+	s := int(totalSeconds % 60)
+	totalMinutes := totalSeconds / 60
+	m := int(totalMinutes % 60)
+	h := int(totalMinutes / 60)
+	if padHour {
+		return fmt.Sprintf("%02d:%02d:%02d:%02d", h, m, s, ff)
+	}
+	return fmt.Sprintf("%d:%02d:%02d:%02d", h, m, s, ff)
+}
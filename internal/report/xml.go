@@ -0,0 +1,194 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+	"github.com/autobrr/go-bdinfo/internal/util"
+)
+
+// The XML report carries the data of the text report's sections (disc,
+// playlists, streams, files, chapters) without its layout. Sizes are bytes,
+// bitrates bits per second and times seconds unless an element says otherwise.
+
+type xmlReport struct {
+	XMLName    xml.Name       `xml:"BDInfo"`
+	Version    string         `xml:"Version,attr"`
+	Generator  string         `xml:"GeneratedBy,attr,omitempty"`
+	Disc       xmlDisc        `xml:"Disc"`
+	ScanError  string         `xml:"ScanError,omitempty"`
+	FileErrors []xmlFileError `xml:"FileErrors>FileError,omitempty"`
+	// BackupFiles are the files read from BDMV/BACKUP, with the error of the
+	// primary copy.
+	BackupFiles []xmlFileError `xml:"BackupFiles>BackupFile,omitempty"`
+	// StructureWarnings are section addresses and lengths of playlist and
+	// clip info files that do not fit the file.
+	StructureWarnings []xmlFileError `xml:"StructureWarnings>StructureWarning,omitempty"`
+	Playlists         []xmlPlaylist  `xml:"Playlists>Playlist"`
+}
+
+type xmlDisc struct {
+	Title      string   `xml:"Title,omitempty"`
+	Label      string   `xml:"Label"`
+	Size       uint64   `xml:"Size"`
+	Protection string   `xml:"Protection"`
+	Extras     []string `xml:"Extras>Extra,omitempty"`
+}
+
+type xmlFileError struct {
+	Name    string `xml:"Name,attr"`
+	Message string `xml:",chardata"`
+}
+
+type xmlPlaylist struct {
+	Name            string       `xml:"Name,attr"`
+	Length          string       `xml:"Length"`
+	LengthSeconds   string       `xml:"LengthSeconds"`
+	Size            uint64       `xml:"Size"`
+	TotalBitrate    uint64       `xml:"TotalBitrate"`
+	HasHiddenTracks bool         `xml:"HasHiddenTracks"`
+	VideoStreams    []xmlStream  `xml:"VideoStreams>Stream,omitempty"`
+	AudioStreams    []xmlStream  `xml:"AudioStreams>Stream,omitempty"`
+	SubtitleStreams []xmlStream  `xml:"SubtitleStreams>Stream,omitempty"`
+	TextStreams     []xmlStream  `xml:"TextStreams>Stream,omitempty"`
+	Files           []xmlFile    `xml:"Files>File"`
+	Chapters        []xmlChapter `xml:"Chapters>Chapter"`
+}
+
+type xmlStream struct {
+	ID           string `xml:"ID,attr"`
+	PID          uint16 `xml:"PID,attr"`
+	Hidden       bool   `xml:"Hidden,attr"`
+	HiddenReason string `xml:"HiddenReason,attr,omitempty"`
+	Angle        int    `xml:"Angle,attr,omitempty"`
+	Codec        string `xml:"Codec"`
+	Language     string `xml:"Language,omitempty"`
+	LanguageCode string `xml:"LanguageCode,omitempty"`
+	Bitrate      int64  `xml:"Bitrate"`
+	Description  string `xml:"Description"`
+}
+
+type xmlFile struct {
+	Name    string `xml:"Name,attr"`
+	Angle   int    `xml:"Angle,attr,omitempty"`
+	TimeIn  string `xml:"TimeIn,attr"`
+	Length  string `xml:"Length,attr"`
+	Size    uint64 `xml:"Size,attr"`
+	Bitrate uint64 `xml:"Bitrate,attr"`
+}
+
+type xmlChapter struct {
+	Number int    `xml:"Number,attr"`
+	TimeIn string `xml:"TimeIn,attr"`
+	Length string `xml:"Length,attr"`
+	Name   string `xml:"Name,attr,omitempty"`
+}
+
+// renderXML renders the report as an XML document; used when the report file
+// name ends in .xml.
+func renderXML(bd *bdrom.BDROM, playlists []*bdrom.PlaylistFile, scan bdrom.ScanResult, settings settings.Settings) (string, error) {
+	doc := xmlReport{
+		Version: reportProductVersion(settings),
+		Disc: xmlDisc{
+			Title:      bd.DiscTitle,
+			Label:      bd.VolumeLabel,
+			Size:       bd.Size,
+			Protection: discProtection(bd, settings),
+			Extras:     discExtras(bd),
+		},
+		Playlists: make([]xmlPlaylist, 0, len(playlists)),
+	}
+	if settings.IncludeGeneratedBy {
+		doc.Generator = generator(settings)
+	}
+	if scan.ScanError != nil {
+		doc.ScanError = scan.ScanError.Error()
+	}
+	for _, name := range slices.Sorted(maps.Keys(scan.FileErrors)) {
+		doc.FileErrors = append(doc.FileErrors, xmlFileError{Name: name, Message: scan.FileErrors[name].Error()})
+	}
+	for _, name := range slices.Sorted(maps.Keys(scan.BackupFiles)) {
+		doc.BackupFiles = append(doc.BackupFiles, xmlFileError{Name: name, Message: scan.BackupFiles[name].Error()})
+	}
+	for _, warning := range bdrom.StructureWarnings(bd) {
+		doc.StructureWarnings = append(doc.StructureWarnings, xmlFileError{Name: warning.File, Message: warning.Message})
+	}
+	for _, playlist := range playlists {
+		doc.Playlists = append(doc.Playlists, xmlPlaylistOf(playlist))
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+func xmlPlaylistOf(playlist *bdrom.PlaylistFile) xmlPlaylist {
+	length := playlist.TotalLength()
+	out := xmlPlaylist{
+		Name:            playlist.Name,
+		Length:          util.FormatTime(length, true),
+		LengthSeconds:   xmlSeconds(length),
+		Size:            playlist.TotalSize(),
+		TotalBitrate:    playlist.TotalBitRate(),
+		HasHiddenTracks: playlist.HasHiddenTracks,
+	}
+	for _, st := range playlist.SortedStreams {
+		base := st.Base()
+		entry := xmlStream{
+			ID:           StreamID(playlist.Name, st),
+			PID:          base.PID,
+			Hidden:       base.IsHidden,
+			HiddenReason: base.HiddenDescription(),
+			Angle:        base.AngleIndex,
+			Codec:        stream.CodecNameForInfo(st),
+			Language:     base.LanguageName,
+			LanguageCode: base.LanguageCode(),
+			Bitrate:      base.BitRate,
+			Description:  st.Description(),
+		}
+		switch {
+		case base.IsVideoStream():
+			out.VideoStreams = append(out.VideoStreams, entry)
+		case base.IsAudioStream():
+			out.AudioStreams = append(out.AudioStreams, entry)
+		case base.IsGraphicsStream():
+			out.SubtitleStreams = append(out.SubtitleStreams, entry)
+		case base.IsTextStream():
+			out.TextStreams = append(out.TextStreams, entry)
+		}
+	}
+	for _, clip := range playlist.StreamClips {
+		out.Files = append(out.Files, xmlFile{
+			Name:    clip.DisplayName(),
+			Angle:   clip.AngleIndex,
+			TimeIn:  xmlSeconds(clip.RelativeTimeIn),
+			Length:  xmlSeconds(clip.Length),
+			Size:    clip.PacketSize(),
+			Bitrate: clip.PacketBitRate(),
+		})
+	}
+	for i, start := range playlist.Chapters {
+		end := length
+		if i+1 < len(playlist.Chapters) {
+			end = playlist.Chapters[i+1]
+		}
+		out.Chapters = append(out.Chapters, xmlChapter{Number: i + 1, TimeIn: xmlSeconds(start), Length: xmlSeconds(end - start), Name: playlist.ChapterName(i)})
+	}
+	return out
+}
+
+func xmlSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
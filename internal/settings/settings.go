@@ -1,11 +1,20 @@
 package settings
 
-import "path/filepath"
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrTempFilesDisabled is returned by CreateTemp when NoTempFiles is set.
+var ErrTempFilesDisabled = errors.New("temporary files are disabled")
 
 // Settings mirrors BDInfo options.
 type Settings struct {
 	GenerateStreamDiagnostics bool
 	ExtendedStreamDiagnostics bool
+	GenerateFrameDataFile     bool
 	EnableSSIF                bool
 	BigPlaylistOnly           bool
 	FilterLoopingPlaylists    bool
@@ -15,17 +24,58 @@ type Settings struct {
 	GenerateTextSummary       bool
 	ReportFileName            string
 	IncludeVersionAndNotes    bool
+	IncludeNotesHome          bool
+	IncludeNotesForums        bool
+	ProductVersion            string
+	ToolVersion               string
+	IncludeGeneratedBy        bool
+	ReportLanguage            string
+	ReportLabelsFile          string
+	TimeFormat                string
+	RoundingMode              string
+	PlaylistSort              string
 	GroupByTime               bool
 	ForumsOnly                bool
 	PlaylistOnly              string
+	TitleOnly                 int
 	MainPlaylistOnly          bool
+	TopPlaylists              int
 	SummaryOnly               bool
+	TempDir                   string
+	NoTempFiles               bool
+	HarvestJARImages          bool
+	IncludeTitleMap           bool
+	IncludeRestrictions       bool
+	Include3DOffsets          bool
+	Include3DBitrates         bool
+	IncludeBitrateGraph       bool
+	IncludeChapterNames       bool
+	IncludeProtectionDetails  bool
+	CollapseDuplicates        bool
+	WideColumns               bool
+	ShowEmptySections         bool
+	SplitOverlappingClips     bool
+	TrackUnknownPIDs          bool
+	QuickScan                 bool
+	FullScan                  bool
+	LowMemory                 bool
+	BitrateGraph              bool
+	SubtitleSummary           bool
+	SubtitleTiming            bool
+	IORetries                 int
+	IORetryDelay              time.Duration
+	MaxReadMbps               float64
+	MaxOpenFiles              int
+	Workers                   int
+	IOMode                    string
+	CacheDir                  string
 }
 
 func Default(reportBaseDir string) Settings {
 	return Settings{
 		GenerateStreamDiagnostics: true,
 		ExtendedStreamDiagnostics: false,
+		GenerateFrameDataFile:     false,
 		EnableSSIF:                true,
 		BigPlaylistOnly:           false,
 		FilterLoopingPlaylists:    false,
@@ -35,10 +85,60 @@ func Default(reportBaseDir string) Settings {
 		GenerateTextSummary:       true,
 		ReportFileName:            filepath.Join(reportBaseDir, "BDInfo_{0}"),
 		IncludeVersionAndNotes:    true,
+		IncludeNotesHome:          true,
+		IncludeNotesForums:        true,
+		ProductVersion:            "",
+		ToolVersion:               "",
+		IncludeGeneratedBy:        false,
+		ReportLanguage:            "en",
+		ReportLabelsFile:          "",
+		TimeFormat:                "hms",
+		RoundingMode:              "official",
+		PlaylistSort:              "size",
 		GroupByTime:               false,
 		ForumsOnly:                false,
 		PlaylistOnly:              "",
+		TitleOnly:                 0,
 		MainPlaylistOnly:          false,
+		TopPlaylists:              0,
 		SummaryOnly:               false,
+		TempDir:                   "",
+		NoTempFiles:               false,
+		HarvestJARImages:          false,
+		IncludeTitleMap:           false,
+		IncludeRestrictions:       false,
+		Include3DOffsets:          false,
+		Include3DBitrates:         false,
+		IncludeBitrateGraph:       false,
+		IncludeChapterNames:       false,
+		IncludeProtectionDetails:  false,
+		CollapseDuplicates:        false,
+		WideColumns:               false,
+		ShowEmptySections:         false,
+		SplitOverlappingClips:     false,
+		TrackUnknownPIDs:          false,
+		QuickScan:                 false,
+		FullScan:                  false,
+		LowMemory:                 false,
+		BitrateGraph:              false,
+		SubtitleSummary:           false,
+		SubtitleTiming:            false,
+		IORetries:                 0,
+		IORetryDelay:              time.Second,
+		MaxReadMbps:               0,
+		MaxOpenFiles:              0,
+		Workers:                   0,
+		IOMode:                    "auto",
+		CacheDir:                  "",
+	}
+}
+
+// CreateTemp creates a temporary file in TempDir (or the OS default when empty).
+// Any spill, extraction or checkpoint file must be created through here so
+// NoTempFiles can guarantee nothing is written outside the report path.
+func (s Settings) CreateTemp(pattern string) (*os.File, error) {
+	if s.NoTempFiles {
+		return nil, ErrTempFilesDisabled
 	}
+	return os.CreateTemp(s.TempDir, pattern)
 }
@@ -0,0 +1,29 @@
+package settings
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateTemp(t *testing.T) {
+	dir := t.TempDir()
+	s := Default(".")
+	s.TempDir = dir
+
+	f, err := s.CreateTemp("bdinfo-*.tmp")
+	if err != nil {
+		t.Fatalf("CreateTemp error: %v", err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	if got := filepath.Dir(f.Name()); got != dir {
+		t.Fatalf("CreateTemp dir got=%q want=%q", got, dir)
+	}
+
+	s.NoTempFiles = true
+	if _, err := s.CreateTemp("bdinfo-*.tmp"); !errors.Is(err, ErrTempFilesDisabled) {
+		t.Fatalf("CreateTemp with NoTempFiles err=%v want=%v", err, ErrTempFilesDisabled)
+	}
+}
@@ -0,0 +1,207 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// The stream types carry unexported state (language code, video format, frame
+// rate) that gob would drop, so each concrete type implements GobEncoder.
+// Every type embedding Stream must define its own methods: the ones promoted
+// from Stream would only encode the base fields.
+
+func init() {
+	gob.Register(&Stream{})
+	gob.Register(&VideoStream{})
+	gob.Register(&AudioStream{})
+	gob.Register(&GraphicsStream{})
+	gob.Register(&TextStream{})
+	gob.Register(&HEVCExtendedData{})
+	gob.Register(&AVCExtendedData{})
+	gob.Register(&LPCMExtendedData{})
+	gob.Register(&ObjectAudioExtendedData{})
+}
+
+func gobEncode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type streamFields Stream
+
+type streamGob struct {
+	Fields       streamFields
+	LanguageCode string
+}
+
+// GobEncode implements gob.GobEncoder.
+func (s *Stream) GobEncode() ([]byte, error) {
+	return gobEncode(&streamGob{Fields: streamFields(*s), LanguageCode: s.languageCode})
+}
+
+// GobDecode implements gob.GobDecoder.
+func (s *Stream) GobDecode(data []byte) error {
+	var g streamGob
+	if err := gobDecode(data, &g); err != nil {
+		return err
+	}
+	*s = Stream(g.Fields)
+	s.languageCode = g.LanguageCode
+	return nil
+}
+
+// The derived types list their fields explicitly: a method-free copy would
+// still embed Stream and so inherit its GobEncode, dropping everything else.
+
+type videoStreamGob struct {
+	Stream          Stream
+	Width           int
+	Height          int
+	IsInterlaced    bool
+	FrameRateEnum   int
+	FrameRateDen    int
+	AspectRatio     AspectRatio
+	EncodingProfile string
+	ExtendedData    any
+	VideoFormat     VideoFormat
+	FrameRate       FrameRate
+}
+
+// GobEncode implements gob.GobEncoder.
+func (v *VideoStream) GobEncode() ([]byte, error) {
+	return gobEncode(&videoStreamGob{
+		Stream:          v.Stream,
+		Width:           v.Width,
+		Height:          v.Height,
+		IsInterlaced:    v.IsInterlaced,
+		FrameRateEnum:   v.FrameRateEnum,
+		FrameRateDen:    v.FrameRateDen,
+		AspectRatio:     v.AspectRatio,
+		EncodingProfile: v.EncodingProfile,
+		ExtendedData:    v.ExtendedData,
+		VideoFormat:     v.videoFormat,
+		FrameRate:       v.frameRate,
+	})
+}
+
+// GobDecode implements gob.GobDecoder.
+func (v *VideoStream) GobDecode(data []byte) error {
+	var g videoStreamGob
+	if err := gobDecode(data, &g); err != nil {
+		return err
+	}
+	*v = VideoStream{
+		Stream:          g.Stream,
+		Width:           g.Width,
+		Height:          g.Height,
+		IsInterlaced:    g.IsInterlaced,
+		FrameRateEnum:   g.FrameRateEnum,
+		FrameRateDen:    g.FrameRateDen,
+		AspectRatio:     g.AspectRatio,
+		EncodingProfile: g.EncodingProfile,
+		ExtendedData:    g.ExtendedData,
+		videoFormat:     g.VideoFormat,
+		frameRate:       g.FrameRate,
+	}
+	return nil
+}
+
+type audioStreamGob struct {
+	Stream        Stream
+	SampleRate    int
+	ChannelCount  int
+	BitDepth      int
+	LFE           int
+	DialNorm      int
+	HasExtensions bool
+	ExtendedData  any
+	AudioMode     AudioMode
+	CoreStream    *AudioStream
+	ChannelLayout ChannelLayout
+}
+
+// GobEncode implements gob.GobEncoder.
+func (a *AudioStream) GobEncode() ([]byte, error) {
+	return gobEncode(&audioStreamGob{
+		Stream:        a.Stream,
+		SampleRate:    a.SampleRate,
+		ChannelCount:  a.ChannelCount,
+		BitDepth:      a.BitDepth,
+		LFE:           a.LFE,
+		DialNorm:      a.DialNorm,
+		HasExtensions: a.HasExtensions,
+		ExtendedData:  a.ExtendedData,
+		AudioMode:     a.AudioMode,
+		CoreStream:    a.CoreStream,
+		ChannelLayout: a.ChannelLayout,
+	})
+}
+
+// GobDecode implements gob.GobDecoder.
+func (a *AudioStream) GobDecode(data []byte) error {
+	var g audioStreamGob
+	if err := gobDecode(data, &g); err != nil {
+		return err
+	}
+	*a = AudioStream{
+		Stream:        g.Stream,
+		SampleRate:    g.SampleRate,
+		ChannelCount:  g.ChannelCount,
+		BitDepth:      g.BitDepth,
+		LFE:           g.LFE,
+		DialNorm:      g.DialNorm,
+		HasExtensions: g.HasExtensions,
+		ExtendedData:  g.ExtendedData,
+		AudioMode:     g.AudioMode,
+		CoreStream:    g.CoreStream,
+		ChannelLayout: g.ChannelLayout,
+	}
+	return nil
+}
+
+// graphicsStreamGob omits the caption tracking state, which is only used while
+// scanning.
+type graphicsStreamGob struct {
+	Stream         Stream
+	Width          int
+	Height         int
+	Captions       int
+	ForcedCaptions int
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *GraphicsStream) GobEncode() ([]byte, error) {
+	return gobEncode(&graphicsStreamGob{
+		Stream:         g.Stream,
+		Width:          g.Width,
+		Height:         g.Height,
+		Captions:       g.Captions,
+		ForcedCaptions: g.ForcedCaptions,
+	})
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *GraphicsStream) GobDecode(data []byte) error {
+	var fields graphicsStreamGob
+	if err := gobDecode(data, &fields); err != nil {
+		return err
+	}
+	*g = GraphicsStream{
+		Stream:         fields.Stream,
+		Width:          fields.Width,
+		Height:         fields.Height,
+		Captions:       fields.Captions,
+		ForcedCaptions: fields.ForcedCaptions,
+		CaptionIDs:     make(map[int]any),
+	}
+	return nil
+}
+
+// TextStream has no fields beyond Stream, so it uses the promoted methods.
@@ -2,6 +2,8 @@ package stream
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/autobrr/go-bdinfo/internal/lang"
@@ -18,6 +20,7 @@ type Stream struct {
 	IsInitialized bool
 	LanguageName  string
 	IsHidden      bool
+	HiddenReason  HiddenReason
 
 	PayloadBytes  uint64
 	PacketCount   uint64
@@ -29,12 +32,35 @@ type Stream struct {
 	languageCode string
 }
 
+// HiddenDescription explains why the stream carries the report's "*" prefix;
+// empty when it is not hidden. Angle copies name the angle they belong to.
+func (s *Stream) HiddenDescription() string {
+	if !s.IsHidden {
+		return ""
+	}
+	desc := s.HiddenReason.String()
+	if desc == "" {
+		desc = "hidden"
+	}
+	if s.AngleIndex > 0 {
+		desc += fmt.Sprintf(" (angle %d copy)", s.AngleIndex)
+	}
+	return desc
+}
+
 type Info interface {
 	Base() *Stream
 	Description() string
 	Clone() Info
 }
 
+// SortedPIDs returns the PIDs of streams in ascending order. Code whose output
+// or accumulated state depends on visiting order ranges over this instead of
+// the map itself.
+func SortedPIDs[V any](streams map[uint16]V) []uint16 {
+	return slices.Sorted(maps.Keys(streams))
+}
+
 func (s *Stream) String() string {
 	return fmt.Sprintf("%s (%d)", s.CodecShortName(), s.PID)
 }
@@ -90,6 +116,19 @@ func (s *Stream) IsTextStream() bool {
 	}
 }
 
+// IsOtherStream reports a stream type that is neither video, audio, graphics
+// nor text, such as the private streams some authoring tools carry over from
+// ATSC or DVB muxes.
+func (s *Stream) IsOtherStream() bool {
+	return !s.IsVideoStream() && !s.IsAudioStream() && !s.IsGraphicsStream() && !s.IsTextStream()
+}
+
+// OtherCodecName names a stream of unknown coding type by its code, e.g.
+// "Other (0x05)".
+func OtherCodecName(streamType StreamType) string {
+	return fmt.Sprintf("Other (0x%02X)", byte(streamType))
+}
+
 func (s *Stream) CodecName() string {
 	switch s.StreamType {
 	case StreamTypeMPEG1Video:
@@ -485,12 +524,8 @@ func (v *VideoStream) Description() string {
 	if v.EncodingProfile != "" {
 		description += v.EncodingProfile + " / "
 	}
-	if v.StreamType == StreamTypeHEVCVideo && v.ExtendedData != nil {
-		if ext, ok := v.ExtendedData.(*HEVCExtendedData); ok {
-			if len(ext.ExtendedFormatInfo) > 0 {
-				description += strings.Join(ext.ExtendedFormatInfo, " / ")
-			}
-		}
+	if info := v.ExtendedFormatInfo(); len(info) > 0 {
+		description += strings.Join(info, " / ")
 	}
 	if before, ok := strings.CutSuffix(description, " / "); ok {
 		description = before
@@ -609,6 +644,9 @@ func (a *AudioStream) Description() string {
 			description += " / Joint Stereo"
 		}
 	}
+	if info := a.ExtendedFormatInfo(); len(info) > 0 {
+		description += " / " + strings.Join(info, " / ")
+	}
 	if before, ok := strings.CutSuffix(description, " / "); ok {
 		description = before
 	}
@@ -632,6 +670,17 @@ func (a *AudioStream) Description() string {
 	return description
 }
 
+// CoreBitRateSplit splits total, a bitrate measured on the stream's PID, between
+// the embedded core at its nominal rate and the extension carried with it
+// (the TrueHD or DTS-HD substream). ok is false without a core of known rate.
+func (a *AudioStream) CoreBitRateSplit(total int64) (core, extension int64, ok bool) {
+	if a.CoreStream == nil || a.CoreStream.BitRate <= 0 || total <= 0 {
+		return 0, 0, false
+	}
+	core = min(a.CoreStream.BitRate, total)
+	return core, total - core, true
+}
+
 func (a *AudioStream) Base() *Stream {
 	return &a.Stream
 }
@@ -689,6 +738,12 @@ func (g *GraphicsStream) Description() string {
 	return description
 }
 
+// ForcedOnly reports a subtitle track whose captions are all forced, such as
+// one carrying only the translations of foreign dialogue.
+func (g *GraphicsStream) ForcedOnly() bool {
+	return g.Captions > 0 && g.ForcedCaptions == g.Captions
+}
+
 func (g *GraphicsStream) Base() *Stream {
 	return &g.Stream
 }
@@ -708,6 +763,185 @@ type TextStream struct {
 // HEVCExtendedData holds HEVC extended format info for descriptions.
 type HEVCExtendedData struct {
 	ExtendedFormatInfo []string
+	// DolbyVision is set when the stream carries Dolby Vision RPU NAL units.
+	DolbyVision *DolbyVision
+}
+
+// DolbyVision describes the Dolby Vision metadata found in an HEVC stream.
+type DolbyVision struct {
+	// Profile is the profile guessed from the RPU header (4, 5, 7 or 8), or 0
+	// when the header does not tell.
+	Profile int
+	// Compatibility is the base layer signal compatibility: the 6 of 7.6 or the
+	// 1 of 8.1; 0 when the profile has none.
+	Compatibility int
+	// EnhancementLayer reports a dual-layer stream: an EL PID of its own on
+	// Blu-ray, or EL NAL units in the same stream.
+	EnhancementLayer bool
+	// EnhancementType is "FEL" (full enhancement layer) or "MEL" (minimal)
+	// when the RPU's residual parameters tell, "" otherwise.
+	EnhancementType string
+}
+
+// ProfileName returns the profile as "7.6" or "5", or "" when unknown.
+func (d *DolbyVision) ProfileName() string {
+	switch {
+	case d.Profile == 0:
+		return ""
+	case d.Compatibility > 0:
+		return fmt.Sprintf("%d.%d", d.Profile, d.Compatibility)
+	default:
+		return fmt.Sprintf("%d", d.Profile)
+	}
+}
+
+// Layers returns the layer layout, "BL+EL+RPU" or "BL+RPU".
+func (d *DolbyVision) Layers() string {
+	if d.EnhancementLayer {
+		return "BL+EL+RPU"
+	}
+	return "BL+RPU"
+}
+
+// String returns the extended diagnostics label, e.g.
+// "Dolby Vision (Profile 7.6, BL+EL+RPU)" or, with a known EL type,
+// "Dolby Vision (Profile 7.6, BL+EL+RPU, FEL)".
+func (d *DolbyVision) String() string {
+	layers := d.Layers()
+	if d.EnhancementType != "" {
+		layers += ", " + d.EnhancementType
+	}
+	if name := d.ProfileName(); name != "" {
+		return "Dolby Vision (Profile " + name + ", " + layers + ")"
+	}
+	return "Dolby Vision (" + layers + ")"
+}
+
+// AVCExtendedData holds AVC extended format info for descriptions. It is only
+// filled with extended stream diagnostics, as official BDInfo shows none.
+type AVCExtendedData struct {
+	ExtendedFormatInfo []string
+}
+
+// ExtendedFormatInfo returns the HEVC or AVC extended format details.
+func (v *VideoStream) ExtendedFormatInfo() []string {
+	switch ext := v.ExtendedData.(type) {
+	case *HEVCExtendedData:
+		if ext != nil {
+			return ext.ExtendedFormatInfo
+		}
+	case *AVCExtendedData:
+		if ext != nil {
+			return ext.ExtendedFormatInfo
+		}
+	}
+	return nil
+}
+
+// LPCMExtendedData holds the BD LPCM header fields beyond the channel count,
+// sample rate and bit depth. It is only filled with extended stream
+// diagnostics, as official BDInfo shows none.
+type LPCMExtendedData struct {
+	// ChannelAssignment is the header's channel_assignment code, which names
+	// the speaker layout: 2/2 and 3/1 both carry four channels.
+	ChannelAssignment int
+	// Quantization is the header's bits_per_sample code: 1 for 16, 2 for 20
+	// and 3 for 24 bits.
+	Quantization int
+	// StartFlag is the header's start_flag bit.
+	StartFlag bool
+}
+
+// lpcmLayout is the speaker layout of an LPCM channel assignment.
+type lpcmLayout struct {
+	name     string
+	speakers []string
+}
+
+// lpcmLayouts maps the BD LPCM channel assignments to their layout, front and
+// surround channels plus LFE, and the speakers in stream order.
+var lpcmLayouts = map[int]lpcmLayout{
+	1:  {"1/0", []string{"C"}},
+	3:  {"2/0", []string{"L", "R"}},
+	4:  {"3/0", []string{"L", "C", "R"}},
+	5:  {"2/1", []string{"L", "R", "S"}},
+	6:  {"3/1", []string{"L", "C", "R", "S"}},
+	7:  {"2/2", []string{"L", "R", "Ls", "Rs"}},
+	8:  {"3/2", []string{"L", "C", "R", "Ls", "Rs"}},
+	9:  {"3/2.1", []string{"L", "C", "R", "Ls", "Rs", "LFE"}},
+	10: {"3/4", []string{"L", "C", "R", "Ls", "Rs", "Lrs", "Rrs"}},
+	11: {"3/4.1", []string{"L", "C", "R", "Ls", "Rs", "Lrs", "Rrs", "LFE"}},
+}
+
+// Layout returns the speaker layout, e.g. "3/2.1", or "" for a reserved
+// channel assignment.
+func (e *LPCMExtendedData) Layout() string {
+	return lpcmLayouts[e.ChannelAssignment].name
+}
+
+// Speakers returns the channels in stream order, e.g. L, C, R, Ls, Rs, LFE.
+func (e *LPCMExtendedData) Speakers() []string {
+	return lpcmLayouts[e.ChannelAssignment].speakers
+}
+
+// String returns the extended diagnostics label, e.g. "3/4.1 (L C R Ls Rs
+// Lrs Rrs LFE)", or "" for a reserved channel assignment.
+func (e *LPCMExtendedData) String() string {
+	layout, ok := lpcmLayouts[e.ChannelAssignment]
+	if !ok {
+		return ""
+	}
+	return layout.name + " (" + strings.Join(layout.speakers, " ") + ")"
+}
+
+// ObjectAudioExtendedData holds the object audio details of Atmos TrueHD and
+// DTS:X streams. It is only filled with extended stream diagnostics, as
+// official BDInfo shows none.
+type ObjectAudioExtendedData struct {
+	// Objects is the number of dynamic objects of a TrueHD Atmos
+	// presentation, as MediaInfo counts them; 0 when the stream does not
+	// tell.
+	Objects int
+	// LFEBed is set when the Atmos presentation keeps an LFE channel bed
+	// next to its objects.
+	LFEBed bool
+	// IMAXEnhanced is set for DTS:X streams carrying the IMAX Enhanced
+	// extension.
+	IMAXEnhanced bool
+}
+
+// ExtendedFormatInfo returns the labels for the description, e.g.
+// "15 objects + LFE" or "IMAX Enhanced".
+func (e *ObjectAudioExtendedData) ExtendedFormatInfo() []string {
+	var info []string
+	if e.Objects > 0 {
+		label := fmt.Sprintf("%d objects", e.Objects)
+		if e.LFEBed {
+			label += " + LFE"
+		}
+		info = append(info, label)
+	}
+	if e.IMAXEnhanced {
+		info = append(info, "IMAX Enhanced")
+	}
+	return info
+}
+
+// ExtendedFormatInfo returns the LPCM speaker layout or object audio details.
+func (a *AudioStream) ExtendedFormatInfo() []string {
+	switch ext := a.ExtendedData.(type) {
+	case *LPCMExtendedData:
+		if ext != nil {
+			if label := ext.String(); label != "" {
+				return []string{label}
+			}
+		}
+	case *ObjectAudioExtendedData:
+		if ext != nil {
+			return ext.ExtendedFormatInfo()
+		}
+	}
+	return nil
 }
 
 func NewTextStream() *TextStream {
@@ -29,6 +29,30 @@ const (
 	StreamTypeSubtitle              StreamType = 0x92
 )
 
+// HiddenReason records why a stream is marked IsHidden.
+type HiddenReason uint8
+
+const (
+	HiddenReasonNone HiddenReason = iota
+	// HiddenReasonNotInPlaylist: the clip carries the stream but the playlist's
+	// STN table does not list it, so players never select it.
+	HiddenReasonNotInPlaylist
+	// HiddenReasonSSIFOnly: an MVC dependent view, which is only played from the
+	// interleaved (SSIF) file in 3D mode.
+	HiddenReasonSSIFOnly
+)
+
+func (r HiddenReason) String() string {
+	switch r {
+	case HiddenReasonNotInPlaylist:
+		return "not listed in the playlist STN table"
+	case HiddenReasonSSIFOnly:
+		return "3D dependent view, only played from the SSIF"
+	default:
+		return ""
+	}
+}
+
 type VideoFormat uint8
 
 const (
@@ -61,8 +61,25 @@ func ReadByte(data []byte, pos *int) byte {
 	return b
 }
 
+// maxTimeTicks is the largest duration, in 100ns ticks, the formatters handle.
+const maxTimeTicks = math.MaxInt64
+
+// TimeTicks converts seconds to 100ns ticks for formatting. Negative and NaN
+// durations become 0 and values beyond int64 are capped, so pathological
+// playlist times never wrap around into nonsense.
+func TimeTicks(seconds float64) int64 {
+	switch {
+	case math.IsNaN(seconds) || seconds <= 0:
+		return 0
+	case seconds*10000000.0 >= maxTimeTicks:
+		return maxTimeTicks
+	default:
+		return int64(seconds * 10000000.0)
+	}
+}
+
 func FormatTime(seconds float64, withMillis bool) string {
-	ticks := max(int64(seconds*10000000.0), 0)
+	ticks := TimeTicks(seconds)
 	totalMillis := ticks / 10000
 	ms := int(totalMillis % 1000)
 	totalSeconds := ticks / 10000000
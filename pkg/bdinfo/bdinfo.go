@@ -2,12 +2,21 @@ package bdinfo
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/dvd"
+	"github.com/autobrr/go-bdinfo/internal/fs"
 	"github.com/autobrr/go-bdinfo/internal/report"
 	internalsettings "github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/util"
 )
 
 // Stage represents a coarse progress stage for Run.
@@ -26,39 +35,207 @@ const (
 	StageDone            Stage = "done"
 )
 
-// ProgressEvent is emitted when Run transitions between major phases.
+// ProgressEvent is emitted when Run transitions between major phases and,
+// during StageStream, as stream bytes are read. ProcessedBytes of TotalBytes
+// covers all stream files; File, FileProcessedBytes and FileTotalBytes the file
+// the update comes from. ETA estimates the remaining stream scan time from the
+// average throughput so far and is zero while unknown.
 type ProgressEvent struct {
-	Stage          Stage
-	Path           string
-	Playlists      int
-	ClipInfos      int
-	Streams        int
-	Completed      int
-	Total          int
-	TotalBytes     uint64
-	ProcessedBytes uint64
-	Elapsed        time.Duration
-	OccurredAt     time.Time
-}
-
-// Settings are library-facing scan and report controls.
+	Stage              Stage
+	Path               string
+	Playlists          int
+	ClipInfos          int
+	Streams            int
+	Completed          int
+	Total              int
+	TotalBytes         uint64
+	ProcessedBytes     uint64
+	File               string
+	FileProcessedBytes uint64
+	FileTotalBytes     uint64
+	ETA                time.Duration
+	Elapsed            time.Duration
+	OccurredAt         time.Time
+}
+
+// Settings are library-facing scan and report controls. Start from
+// DefaultSettings: the zero value is not the CLI's defaults. Settings that
+// decide what is read take effect in Scan (and Run); Render applies only the
+// report settings.
 type Settings struct {
+	// GenerateStreamDiagnostics adds the STREAM DIAGNOSTICS table to the text
+	// report.
 	GenerateStreamDiagnostics bool
+	// ExtendedStreamDiagnostics adds HEVC, AVC and MVC metadata to the video
+	// stream descriptions.
 	ExtendedStreamDiagnostics bool
-	EnableSSIF                bool
-	BigPlaylistOnly           bool
-	FilterLoopingPlaylists    bool
-	FilterShortPlaylists      bool
-	FilterShortPlaylistsVal   int
-	KeepStreamOrder           bool
-	GenerateTextSummary       bool
-	ReportFileName            string
-	IncludeVersionAndNotes    bool
-	GroupByTime               bool
-	ForumsOnly                bool
-	PlaylistOnly              string
-	MainPlaylistOnly          bool
-	SummaryOnly               bool
+	// GenerateFrameDataFile fills Result.FrameData with the per-frame type,
+	// size and time of the video streams of each reported playlist. The
+	// frames come from reading the stream files in full, so QuickScan is
+	// ignored with it.
+	GenerateFrameDataFile bool
+	// EnableSSIF reads the 3D interleaved files of BDMV/STREAM/SSIF. Scan only.
+	EnableSSIF bool
+	// BigPlaylistOnly limits the report to the largest playlist.
+	BigPlaylistOnly bool
+	// FilterLoopingPlaylists drops playlists that play a clip more than once.
+	// Scan only.
+	FilterLoopingPlaylists bool
+	// FilterShortPlaylists drops playlists shorter than FilterShortPlaylistsVal
+	// seconds. Scan only.
+	FilterShortPlaylists    bool
+	FilterShortPlaylistsVal int
+	// KeepStreamOrder lists streams in clip order instead of BDInfo's codec
+	// and language order. Scan only.
+	KeepStreamOrder bool
+	// GenerateTextSummary adds the QUICK SUMMARY block to the text report.
+	GenerateTextSummary bool
+	// ReportFileName names the report Run renders: {0} stands for the disc
+	// label, "-" for stdout, and the .xml or .csv extension picks that format.
+	// Run never writes it; Result.ReportPath carries the resolved name.
+	ReportFileName string
+	// IncludeVersionAndNotes adds the version and notes block; the two
+	// IncludeNotes settings pick its links.
+	IncludeVersionAndNotes bool
+	IncludeNotesHome       bool
+	IncludeNotesForums     bool
+	// ProductVersion overrides the BDInfo version printed on the "BDInfo:"
+	// line.
+	ProductVersion string
+	// ReportLanguage ("en", "de", "fr") translates the labels outside the
+	// forums paste; ReportLabelsFile, a JSON file of English label to
+	// translation, overrides it.
+	ReportLanguage   string
+	ReportLabelsFile string
+	// TimeFormat of the CHAPTERS and FILES tables: "hms", "smpte" or
+	// "seconds".
+	TimeFormat string
+	// PlaylistSort orders the report's playlists: "size", "length", "name" or
+	// "bitrate".
+	PlaylistSort string
+	// GroupByTime groups playlists of equal length.
+	GroupByTime bool
+	// ForumsOnly, MainPlaylistOnly, TopPlaylists and SummaryOnly narrow the
+	// report to the forums paste, the main playlist, the N largest playlists
+	// or the quick summary.
+	ForumsOnly bool
+	// PlaylistOnly scans only the named playlist (e.g. "00800.MPLS"). Scan
+	// only.
+	PlaylistOnly     string
+	MainPlaylistOnly bool
+	TopPlaylists     int
+	SummaryOnly      bool
+	// TitleOnly scans only the playlists index.bdmv Title N plays (0 scans
+	// all). It cannot be combined with PlaylistOnly. Scan only.
+	TitleOnly int
+	// TempDir holds temporary files (the OS default when empty); NoTempFiles
+	// forbids them.
+	TempDir     string
+	NoTempFiles bool
+	// HarvestJARImages collects the images of BD-J JARs into Result.JARImages.
+	// Scan only.
+	HarvestJARImages bool
+	// IncludeTitleMap, IncludeRestrictions, Include3DOffsets and
+	// IncludeChapterNames add the TITLES, PLAYBACK RESTRICTIONS, 3D GRAPHICS
+	// OFFSETS and CHAPTER NAMES sections to the text report.
+	IncludeTitleMap     bool
+	IncludeRestrictions bool
+	Include3DOffsets    bool
+	IncludeChapterNames bool
+	// Include3DBitrates adds a 3D BITRATES (SSIF) section to the text report of
+	// 3D playlists scanned with EnableSSIF. PlaylistInfo.SSIF is filled either way.
+	Include3DBitrates bool
+	// IncludeBitrateGraph adds a BITRATE GRAPH section to the text report of
+	// each playlist: an ASCII sparkline of the per-second bitrate of every
+	// video stream, and of the audio streams when BitrateGraph is set.
+	IncludeBitrateGraph bool
+	// RoundingMode selects how kbps and Mbps figures of the text report are
+	// rounded: "official" (half to even, as official BDInfo) or "mathematical"
+	// (half away from zero).
+	RoundingMode string
+	// IncludeGeneratedBy appends a "Generated by:" footer to text reports (and a
+	// GeneratedBy attribute to XML) naming the go-bdinfo version that made them;
+	// the "BDInfo:" line keeps the emulated ProductVersion.
+	IncludeGeneratedBy bool
+	// ToolVersion is the go-bdinfo version for that footer; empty uses the
+	// module version from the binary's build info.
+	ToolVersion string
+	// WideColumns widens the stream and diagnostics table columns of the text
+	// report to fit their longest cell instead of keeping BDInfo's fixed widths.
+	WideColumns bool
+	// ShowEmptySections keeps the VIDEO, AUDIO and SUBTITLES tables of the text
+	// report when a playlist has no such streams, and marks empty tables with a
+	// placeholder row such as "No audio streams".
+	ShowEmptySections bool
+	// SplitOverlappingClips shares each packet window between the play items of
+	// a playlist whose time ranges overlap on the same stream file, instead of
+	// counting it in full for each (official BDInfo behaviour).
+	SplitOverlappingClips bool
+	// TrackUnknownPIDs counts the bytes of PIDs the clip info does not list;
+	// ClipInfo.UnknownPIDs and the text report's UNKNOWN PIDS section show the
+	// ones that carry a noticeable share of a stream file.
+	TrackUnknownPIDs bool
+	// SubtitleSummary counts the captions of PGS subtitle tracks: their
+	// descriptions and SubtitleDetails get caption counts, forced-only
+	// tracks are marked, and the text report adds a SUBTITLE SUMMARY section
+	// grouping the tracks by language.
+	SubtitleSummary bool
+	// IORetries retries failed file opens and reads, waiting IORetryDelay
+	// before the first retry and doubling it after each further failure.
+	IORetries    int
+	IORetryDelay time.Duration
+	// MaxReadMbps caps disc reads across all workers, in megabits per second;
+	// 0 is unlimited.
+	MaxReadMbps float64
+	// Workers sets the scan worker count; 0 picks it from IOMode.
+	Workers int
+	// IOMode picks the scan parallelism when Workers is 0: "auto" (default;
+	// one worker for stream files and on optical drives), "sequential" (one
+	// worker throughout) or "parallel" (as many as allowed, for NVMe arrays).
+	IOMode string
+	// CacheDir, when set, keeps each stream file's scan results in this
+	// directory, keyed by file path, size and modification time, so scanning
+	// the disc again (after an interrupted run, or for other report settings)
+	// skips the stream files already read.
+	CacheDir string
+	// MaxOpenFiles caps the disc files open at once, for containers with a low
+	// file descriptor limit; scan workers beyond it wait for a free handle. 0
+	// means unlimited. An ISO image stays open for the whole scan on top.
+	MaxOpenFiles int
+	// QuickScan reads only the first 8 MiB of each stream file: enough for
+	// codec details and the stream list, while bitrates stay nominal (from
+	// the codec headers; 0 for VBR streams such as video) and stream file
+	// lengths stay 0.
+	QuickScan bool
+	// FullScan adds official BDInfo's second pass: after the scan every stream
+	// file is read again from the start and the bitrates and stream
+	// diagnostics, chapter peak bitrates included, are rebuilt from that pass.
+	// It doubles the stream I/O, and QuickScan is ignored with it.
+	FullScan bool
+	// IncludeProtectionDetails adds the AACS MKB version and the BD+ content
+	// code date to the Protection line of the reports, e.g.
+	// "BD+ (MKB v40, SVM 2012-05-07)". DiscInfo carries both either way.
+	IncludeProtectionDetails bool
+	// LowMemory scans with 256 KiB reads, a single worker and small HEVC frame
+	// tag buffers, for NAS boxes and single-board computers. Reports match a
+	// normal scan except that HEVC chapter frame counts can differ slightly.
+	// Workers still overrides the worker count.
+	LowMemory bool
+	// CollapseDuplicates keeps one playlist of each group of duplicates
+	// (Result.DuplicatePlaylists) in the reports and adds a DUPLICATE
+	// PLAYLISTS section to the text report.
+	CollapseDuplicates bool
+	// BitrateGraph fills Result.BitrateGraph with the per-second bitrate of
+	// every video and audio stream of the main playlist, and
+	// Result.BitrateGraphs with that of every playlist. The scan then also
+	// keeps the packet windows of audio streams, which the report's
+	// diagnostics only need for video. Scan only.
+	BitrateGraph bool
+	// SubtitleTiming decodes the PGS subtitle streams and fills
+	// Result.SubtitleTimings with when each caption was shown;
+	// PlaylistInfo.SubtitleTimeline puts them on a playlist's clock. Scan
+	// only; the timings are not kept by Save.
+	SubtitleTiming bool
 }
 
 // DefaultSettings returns library defaults equivalent to CLI defaults.
@@ -67,65 +244,331 @@ func DefaultSettings(reportBaseDir string) Settings {
 	return fromInternalSettings(base)
 }
 
-// Options configure one Run call for a single disc folder or ISO path.
+// Options configure one Run or Scan call for a single disc folder or ISO path.
 type Options struct {
-	Path       string
+	// Path is the disc folder (holding BDMV or BDAV), or an .iso image; "-"
+	// reads an image from stdin and needs Settings.QuickScan.
+	Path string
+	// ReportPath, when set, is returned as Result.ReportPath in place of the
+	// name derived from Settings.ReportFileName.
 	ReportPath string
 	Settings   Settings
+	// OnProgress receives progress events. During StageStream it is called
+	// from the scan workers concurrently.
 	OnProgress func(ProgressEvent)
+	// BitrateTrace, when set, receives a JSON Lines audit of the packet windows
+	// and inputs that produced each reported bitrate figure. Every record
+	// names its pass ("scan", or "full" with Settings.FullScan, which traces
+	// only the full pass); stream files restored from Settings.CacheDir get a
+	// "cached" record instead of their windows.
+	BitrateTrace io.Writer
+	// SaveScan, when set, receives the completed scan in the form LoadScan
+	// reads, so reports can be rendered again later without the disc.
+	SaveScan io.Writer
+	// SubtitleOCR, when set, receives every subtitle bitmap decoded from the
+	// PGS streams and returns its text, collected in Result.Captions. It is
+	// called from the scan workers concurrently; the first error fails the
+	// scan. Captions are not kept by Save.
+	SubtitleOCR func(SubtitleImage) (string, error)
+	// HumanizeSizes fills the optional *Human string fields of the Result.
+	// Raw SizeBytes/BitrateBps values are always populated.
+	HumanizeSizes bool
 }
 
 // DiscInfo contains high-level disc metadata.
+// Sizes are raw byte counts; SizeHuman is only set when Options.HumanizeSizes is true.
 type DiscInfo struct {
-	Path      string
-	Title     string
-	Label     string
-	SizeBytes uint64
-	IsBDPlus  bool
-	IsBDJava  bool
-	IsDBOX    bool
-	IsPSP     bool
-	Is3D      bool
-	Is50Hz    bool
-	IsUHD     bool
+	Path      string `json:"path"`
+	Title     string `json:"title,omitempty"`
+	Label     string `json:"label"`
+	SizeBytes uint64 `json:"sizeBytes"`
+	SizeHuman string `json:"sizeHuman,omitempty"`
+	IsBDPlus  bool   `json:"isBDPlus"`
+	IsBDJava  bool   `json:"isBDJava"`
+	IsDBOX    bool   `json:"isDBOX"`
+	IsPSP     bool   `json:"isPSP"`
+	Is3D      bool   `json:"is3D"`
+	Is50Hz    bool   `json:"is50Hz"`
+	IsUHD     bool   `json:"isUHD"`
+	// IsBDAV is set for recorder and camcorder discs with a BDAV directory
+	// instead of BDMV.
+	IsBDAV bool `json:"isBDAV"`
+	// IsDVD is set for DVD-Video discs (VIDEO_TS), whose titles are reported
+	// as playlists named "Title 01" and so on.
+	IsDVD bool `json:"isDVD,omitempty"`
+	// MKBVersion is the AACS Media Key Block version from AACS/MKB_RO.inf,
+	// 0 when the disc has none.
+	MKBVersion int `json:"mkbVersion,omitempty"`
+	// BDPlusDate is the BD+ content code date (YYYY-MM-DD) from
+	// BDSVM/00000.svm, which identifies the SVM generation.
+	BDPlusDate string `json:"bdPlusDate,omitempty"`
+	// PlaylistObfuscation is set when the duplicate playlists look like
+	// deliberate obfuscation: members of a group play the same clips in
+	// different orders, or one playlist has many copies.
+	PlaylistObfuscation bool `json:"playlistObfuscation,omitempty"`
 }
 
 // PlaylistInfo contains top-level playlist metrics.
+// Sizes and bitrates are raw integers; the *Human fields are only set when
+// Options.HumanizeSizes is true. SizeBytes covers angle 0 only; AllAnglesSizeBytes
+// adds every alternate angle clip. ReachableFromTitle1 is set when index.bdmv
+// Title 1 plays the playlist; Orphaned when navigation resolves completely and
+// no index.bdmv entry plays it.
 type PlaylistInfo struct {
-	Name            string
-	LengthSeconds   float64
-	SizeBytes       uint64
-	TotalBitrateBps uint64
-	HasHiddenTracks bool
-	IsValid         bool
+	Name                string  `json:"name"`
+	LengthSeconds       float64 `json:"lengthSeconds"`
+	Length              string  `json:"length"`
+	SizeBytes           uint64  `json:"sizeBytes"`
+	SizeHuman           string  `json:"sizeHuman,omitempty"`
+	AllAnglesSizeBytes  uint64  `json:"allAnglesSizeBytes"`
+	TotalBitrateBps     uint64  `json:"totalBitrateBps"`
+	TotalBitrateHuman   string  `json:"totalBitrateHuman,omitempty"`
+	HasHiddenTracks     bool    `json:"hasHiddenTracks"`
+	IsValid             bool    `json:"isValid"`
+	ReachableFromTitle1 bool    `json:"reachableFromTitle1"`
+	Orphaned            bool    `json:"orphaned,omitempty"`
+	// Streams lists the playlist's streams in report order.
+	Streams []StreamInfo `json:"streams"`
+	// Clips lists the playlist's stream files in FILES table order, angle
+	// copies included.
+	Clips    []ClipInfo    `json:"clips"`
+	Chapters []ChapterInfo `json:"chapters"`
+	// SSIF is the 3D view breakdown of playlists scanned through their SSIF
+	// interleaved files (Settings.EnableSSIF); nil for 2D playlists.
+	SSIF *SSIFInfo `json:"ssif,omitempty"`
+}
+
+// SSIFInfo gives the video bitrate of each eye of a 3D playlist and of both
+// views together, which is what 3D playback reads, with the size of its
+// interleaved files. BaseEye is "Left" or "Right".
+type SSIFInfo struct {
+	SizeBytes           uint64 `json:"sizeBytes"`
+	BaseEye             string `json:"baseEye"`
+	BaseBitrateBps      uint64 `json:"baseBitrateBps"`
+	DependentBitrateBps uint64 `json:"dependentBitrateBps"`
+	CombinedBitrateBps  uint64 `json:"combinedBitrateBps"`
+}
+
+// ClipInfo is one row of the FILES table. StartSeconds is where the clip starts
+// in the playlist; InSeconds and OutSeconds are the play item's in and out
+// times within the stream file. Angle is 0 for the main angle. UnknownPIDs is
+// only filled when Settings.TrackUnknownPIDs is set.
+type ClipInfo struct {
+	Name          string  `json:"name"`
+	Angle         int     `json:"angle,omitempty"`
+	StartSeconds  float64 `json:"startSeconds"`
+	InSeconds     float64 `json:"inSeconds"`
+	OutSeconds    float64 `json:"outSeconds"`
+	LengthSeconds float64 `json:"lengthSeconds"`
+	SizeBytes     uint64  `json:"sizeBytes"`
+	BitrateBps    uint64  `json:"bitrateBps"`
+	// UnknownPIDs lists the PIDs of the stream file the clip info does not
+	// declare and that carry at least 0.1% of it, in PID order.
+	UnknownPIDs []UnknownPIDInfo `json:"unknownPids,omitempty"`
 }
 
-// ScanInfo exposes non-fatal scan errors captured during Run.
+// UnknownPIDInfo is a PID missing from the clip info and the bytes of its
+// transport packets.
+type UnknownPIDInfo struct {
+	PID   uint16 `json:"pid"`
+	Bytes uint64 `json:"bytes"`
+}
+
+// ChapterInfo is one chapter of a playlist. Name comes from the disc's title
+// name metadata (BDMV/META/TN) and is empty when the disc names none.
+type ChapterInfo struct {
+	Number        int     `json:"number"`
+	StartSeconds  float64 `json:"startSeconds"`
+	LengthSeconds float64 `json:"lengthSeconds"`
+	Name          string  `json:"name,omitempty"`
+}
+
+// ScanInfo exposes non-fatal scan errors captured during Run. BackupFiles
+// names the playlists and clip infos read from their BDMV/BACKUP copies
+// because the primary file could not be parsed, with that file's error.
 type ScanInfo struct {
-	ScanError  string
-	FileErrors map[string]string
+	ScanError   string            `json:"scanError,omitempty"`
+	FileErrors  map[string]string `json:"fileErrors,omitempty"`
+	BackupFiles map[string]string `json:"backupFiles,omitempty"`
+}
+
+// ScanStats reports the cost of a Run for telemetry and performance dashboards.
+// Durations are encoded in nanoseconds.
+type ScanStats struct {
+	BytesRead     uint64                   `json:"bytesRead"`
+	WallTime      time.Duration            `json:"wallTimeNs"`
+	ScanTime      time.Duration            `json:"scanTimeNs"`
+	Workers       int                      `json:"workers"`
+	FileDurations map[string]time.Duration `json:"fileDurationsNs,omitempty"`
+	CacheHits     int                      `json:"cacheHits"`
+}
+
+// JARImage is an image embedded in a BD-J JAR, harvested when
+// Settings.HarvestJARImages is enabled. Data holds the raw file bytes.
+type JARImage struct {
+	JAR       string `json:"jar"`
+	Name      string `json:"name"`
+	Format    string `json:"format"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	SizeBytes int    `json:"sizeBytes"`
+	Data      []byte `json:"-"`
 }
 
 // Result contains structured scan output plus rendered report content.
+// MainPlaylist names the playlist --main would pick, whether or not the
+// report was limited to it.
 type Result struct {
-	Disc       DiscInfo
-	Playlists  []PlaylistInfo
-	Scan       ScanInfo
-	Report     string
-	ReportPath string
+	Disc         DiscInfo       `json:"disc"`
+	Playlists    []PlaylistInfo `json:"playlists"`
+	MainPlaylist string         `json:"mainPlaylist,omitempty"`
+	Scan         ScanInfo       `json:"scan"`
+	Warnings     []Warning      `json:"warnings,omitempty"`
+	Stats        ScanStats      `json:"stats"`
+	JARImages    []JARImage     `json:"jarImages,omitempty"`
+	Report       string         `json:"report,omitempty"`
+	ReportPath   string         `json:"reportPath,omitempty"`
+	// ClipsCSV holds the clip table that accompanies a CSV report (ReportPath
+	// ending in .csv); write it to ClipsPath.
+	ClipsCSV  string `json:"-"`
+	ClipsPath string `json:"-"`
+	// FrameData holds one frame data file per reported playlist with a video
+	// stream, named after ReportPath; only set with
+	// Settings.GenerateFrameDataFile.
+	FrameData []FrameDataFile `json:"-"`
+	// OneLine summarizes the disc on one line (label, length, size, video,
+	// audio, subtitles of the main playlist), as rendered by FormatOneLine.
+	OneLine string `json:"-"`
+	// FFprobe describes the main playlist in ffprobe's JSON layout, as
+	// rendered by FormatFFprobe; nil when there is no playlist.
+	FFprobe *FFprobeOutput `json:"-"`
+	// DuplicatePlaylists groups the scanned playlists that play the same clip
+	// time ranges with the same streams, whatever the order.
+	DuplicatePlaylists []PlaylistGroup `json:"duplicatePlaylists,omitempty"`
+	// Captions holds the text of the subtitles Options.SubtitleOCR read,
+	// ordered by file, PID and time; PlaylistInfo.WriteSRT turns them into
+	// SubRip.
+	Captions []Caption `json:"captions,omitempty"`
+	// SubtitleTimings holds when each PGS caption was shown, ordered by file,
+	// PID and time; only set with Settings.SubtitleTiming.
+	SubtitleTimings []SubtitleTiming `json:"subtitleTimings,omitempty"`
+	// BitrateGraph is the per-second bitrate of the main playlist's video and
+	// audio streams; only set with Settings.BitrateGraph.
+	BitrateGraph *BitrateGraph `json:"bitrateGraph,omitempty"`
+	// BitrateGraphs holds the bitrate graph of every playlist, in the order
+	// of Playlists; only set with Settings.BitrateGraph.
+	BitrateGraphs []*BitrateGraph `json:"bitrateGraphs,omitempty"`
+}
+
+// FrameDataFile is the frame data of one playlist: for each video stream, a
+// "#" header and one tab-separated row per frame on the main angle with its
+// time on the playlist clock in seconds, picture type (I, P, B or BI; empty
+// when unknown), and size in bytes and transport packets. Write Data to Path.
+type FrameDataFile struct {
+	Playlist string
+	Path     string
+	Data     string
+}
+
+// PlaylistGroup is a set of duplicate playlists. Representative is the one a
+// report keeps with Settings.CollapseDuplicates: the playlist index.bdmv
+// Title 1 plays when it is a member. Reordered is set when members play the
+// clips in different orders.
+type PlaylistGroup struct {
+	Representative string   `json:"representative"`
+	Playlists      []string `json:"playlists"`
+	Reordered      bool     `json:"reordered"`
+}
+
+// Format selects the output produced by Render.
+type Format string
+
+const (
+	// FormatText is the BDInfo text report, shaped by the report settings.
+	FormatText Format = "text"
+	// FormatJSON is the Result that Run would return, encoded as indented JSON.
+	FormatJSON Format = "json"
+	// FormatOneLine is a single line per disc for shell pipelines, e.g.
+	// "LABEL | 2:14:05 | 38.50 GB | HEVC 2160p HDR10 | TrueHD Atmos 7.1 | eng,fra subs".
+	FormatOneLine Format = "oneline"
+	// FormatFFprobe is the main playlist as `ffprobe -show_streams
+	// -show_format -of json` would describe it (see FFprobeOutput).
+	FormatFFprobe Format = "ffprobe"
+)
+
+// ScanResultFull is a completed disc scan. Render can turn it into reports any
+// number of times without touching the disc again; the disc is closed by the
+// time Scan returns.
+//
+// Settings that decide what is read (EnableSSIF, PlaylistOnly, the playlist
+// filters, HarvestJARImages, Workers and the I/O options) are fixed by Scan;
+// Render only applies the report settings.
+//
+// The scanned disc is never modified once Scan or LoadScan returns: rendering
+// only reads it, so Render and Save may be called on one ScanResultFull from
+// several goroutines at once, e.g. to serve different report variants of one
+// scan to parallel requests.
+//
+// ScanResultFull is opaque: the scanned disc model stays internal to the
+// module. Render it with FormatJSON, or use the Result of Run, for structured
+// data.
+type ScanResultFull struct {
+	// Path is the disc folder or ISO the scan read.
+	Path string
+
+	rom       *bdrom.BDROM
+	dvd       *dvd.Disc
+	playlists []*bdrom.PlaylistFile
+	scan      bdrom.ScanResult
+	cfg       internalsettings.Settings
+	wallTime  time.Duration
+	captions  []Caption
+	timings   []SubtitleTiming
 }
 
 // Run scans one path and returns structured output plus report content.
 // The API does not write files; callers own output persistence behavior.
 func Run(ctx context.Context, options Options) (Result, error) {
+	start := time.Now()
+	full, err := Scan(ctx, options)
+	if err != nil {
+		return Result{}, err
+	}
+
+	emit(options.OnProgress, ProgressEvent{
+		Stage:      StageRenderingReport,
+		Path:       options.Path,
+		OccurredAt: time.Now(),
+	})
+
+	result, err := full.result(options.ReportPath, full.cfg, options.HumanizeSizes)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Stats.WallTime = time.Since(start)
+
+	emit(options.OnProgress, ProgressEvent{
+		Stage:      StageDone,
+		Path:       options.Path,
+		Elapsed:    time.Since(start),
+		OccurredAt: time.Now(),
+	})
+
+	return result, nil
+}
+
+// Scan reads one disc folder or ISO without rendering a report. Progress is
+// reported through StageScanComplete; Options.ReportPath and HumanizeSizes are
+// ignored.
+func Scan(ctx context.Context, options Options) (*ScanResultFull, error) {
 	if options.Path == "" {
-		return Result{}, errors.New("path is required")
+		return nil, errors.New("path is required")
 	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	if err := ctx.Err(); err != nil {
-		return Result{}, err
+		return nil, err
 	}
 
 	start := time.Now()
@@ -136,14 +579,48 @@ func Run(ctx context.Context, options Options) (Result, error) {
 	})
 
 	cfg := toInternalSettings(options.Settings)
-	rom, err := bdrom.New(options.Path, cfg)
+	if cfg.FullScan || cfg.GenerateFrameDataFile {
+		cfg.QuickScan = false
+	}
+	if cfg.TitleOnly < 0 {
+		return nil, errors.New("TitleOnly must not be negative")
+	}
+	if cfg.TitleOnly != 0 && cfg.PlaylistOnly != "" {
+		return nil, errors.New("PlaylistOnly and TitleOnly cannot be combined")
+	}
+	rom, err := bdrom.NewContext(ctx, options.Path, cfg)
 	if err != nil {
-		return Result{}, err
+		if fs.IsRemoteURL(options.Path) || options.Path == fs.StdinPath {
+			return nil, err
+		}
+		disc, dvdErr := dvd.Open(options.Path)
+		switch {
+		case dvdErr == nil:
+			return scanDVD(options, cfg, disc, start)
+		case errors.Is(dvdErr, dvd.ErrNoDVD):
+			return nil, err
+		}
+		return nil, dvdErr
 	}
 	defer rom.Close()
+	if options.BitrateTrace != nil {
+		rom.BitrateTrace = bdrom.NewBitrateTrace(options.BitrateTrace)
+	}
+	var ocr *subtitleOCR
+	if options.SubtitleOCR != nil {
+		ocr = &subtitleOCR{ocr: options.SubtitleOCR}
+	}
+	var timings *subtitleTimings
+	if cfg.SubtitleTiming {
+		timings = &subtitleTimings{}
+	}
+	rom.PGSCaptions = captionSink(ocr, timings)
 
 	if err := filterROMToPlaylist(rom, cfg.PlaylistOnly); err != nil {
-		return Result{}, err
+		return nil, err
+	}
+	if err := filterROMToTitle(rom, cfg.TitleOnly); err != nil {
+		return nil, err
 	}
 
 	emit(options.OnProgress, ProgressEvent{
@@ -156,7 +633,7 @@ func Run(ctx context.Context, options Options) (Result, error) {
 	})
 
 	if err := ctx.Err(); err != nil {
-		return Result{}, err
+		return nil, err
 	}
 
 	emit(options.OnProgress, ProgressEvent{
@@ -164,25 +641,23 @@ func Run(ctx context.Context, options Options) (Result, error) {
 		Path:       options.Path,
 		OccurredAt: time.Now(),
 	})
-	var scan bdrom.ScanResult
-	if options.OnProgress != nil {
-		scan = rom.ScanWithProgress(func(update bdrom.ScanProgress) {
-			stage, ok := stageFromScanProgress(update.Stage)
-			if !ok {
-				return
-			}
-			emit(options.OnProgress, ProgressEvent{
-				Stage:          stage,
-				Path:           options.Path,
-				Completed:      update.Completed,
-				Total:          update.Total,
-				ProcessedBytes: update.ProcessedBytes,
-				TotalBytes:     update.TotalBytes,
-				OccurredAt:     time.Now(),
-			})
-		})
-	} else {
-		scan = rom.Scan()
+	trace := rom.BitrateTrace
+	if cfg.FullScan {
+		// The full pass re-reads every window; trace only that one.
+		rom.BitrateTrace = nil
+	}
+	scan := rom.ScanWithProgress(scanProgress(options))
+	if cfg.FullScan {
+		rom.BitrateTrace = trace
+		scan = mergeScans(scan, rom.ScanFullWithProgress(scanProgress(options)))
+	}
+
+	if err := rom.BitrateTrace.Err(); err != nil {
+		return nil, fmt.Errorf("write bitrate trace: %w", err)
+	}
+	captions, err := ocr.result()
+	if err != nil {
+		return nil, fmt.Errorf("subtitle OCR: %w", err)
 	}
 
 	emit(options.OnProgress, ProgressEvent{
@@ -195,36 +670,162 @@ func Run(ctx context.Context, options Options) (Result, error) {
 	})
 
 	if err := ctx.Err(); err != nil {
-		return Result{}, err
+		return nil, err
 	}
 
-	playlists := orderedPlaylists(rom)
-	emit(options.OnProgress, ProgressEvent{
-		Stage:      StageRenderingReport,
-		Path:       options.Path,
-		OccurredAt: time.Now(),
-	})
+	full := &ScanResultFull{
+		Path:      options.Path,
+		rom:       rom,
+		playlists: orderedPlaylists(rom),
+		scan:      scan,
+		cfg:       cfg,
+		wallTime:  time.Since(start),
+		captions:  captions,
+		timings:   timings.result(),
+	}
+	if options.SaveScan != nil {
+		if err := full.Save(options.SaveScan); err != nil {
+			return nil, fmt.Errorf("save scan: %w", err)
+		}
+	}
+	return full, nil
+}
+
+// Save writes the scan to w in a compact binary form that LoadScan restores, so
+// reports can be re-rendered later without reading the disc again.
+func (s *ScanResultFull) Save(w io.Writer) error {
+	if s.dvd != nil {
+		return errDVDSave
+	}
+	return s.rom.WriteSnapshot(w, s.scan)
+}
 
-	reportPath, reportText, err := report.RenderReport(options.ReportPath, rom, playlists, scan, cfg)
+// LoadScan restores a scan written by ScanResultFull.Save. The wall time of the
+// loaded result covers the original scan call only.
+func LoadScan(r io.Reader) (*ScanResultFull, error) {
+	rom, scan, err := bdrom.ReadSnapshot(r)
 	if err != nil {
-		return Result{}, err
+		return nil, err
 	}
+	return &ScanResultFull{
+		Path:      rom.Path,
+		rom:       rom,
+		playlists: orderedPlaylists(rom),
+		scan:      scan,
+		cfg:       rom.Settings,
+		wallTime:  scan.Stats.WallTime,
+	}, nil
+}
 
-	result := Result{
-		Disc:       buildDiscInfo(rom),
-		Playlists:  buildPlaylistInfo(playlists),
-		Scan:       buildScanInfo(scan),
-		Report:     reportText,
-		ReportPath: reportPath,
+// Render produces one output from a completed scan using the report settings
+// in settings (main-only, forums-only, language, sort, ...).
+func Render(result *ScanResultFull, format Format, settings Settings) (string, error) {
+	if result == nil {
+		return "", errors.New("scan result is required")
+	}
+	cfg := toInternalSettings(settings)
+	if result.dvd != nil {
+		return result.renderDVD(format, cfg)
+	}
+	switch format {
+	case FormatText, "":
+		_, text, err := report.RenderReport("", result.rom, result.playlists, result.scan, cfg)
+		return text, err
+	case FormatJSON:
+		res, err := result.result("", cfg, false)
+		if err != nil {
+			return "", err
+		}
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	case FormatOneLine:
+		return report.RenderOneLine(result.rom, result.playlists, cfg) + "\n", nil
+	case FormatFFprobe:
+		main, _ := report.MainPlaylistTies(report.SortPlaylists(result.playlists, cfg.PlaylistSort), cfg)
+		if main == nil {
+			return "", errors.New("no playlist to describe")
+		}
+		data, err := json.MarshalIndent(buildFFprobe(buildDiscInfo(result.rom, false), main), "", "    ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
 	}
+}
 
-	emit(options.OnProgress, ProgressEvent{
-		Stage:      StageDone,
-		Path:       options.Path,
-		Elapsed:    time.Since(start),
-		OccurredAt: time.Now(),
-	})
+// RenderTo writes the output Render produces to w.
+func RenderTo(w io.Writer, result *ScanResultFull, format Format, settings Settings) error {
+	output, err := Render(result, format, settings)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, output)
+	return err
+}
+
+// result renders the text report and assembles the structured Result.
+func (s *ScanResultFull) result(reportPath string, cfg internalsettings.Settings, humanize bool) (Result, error) {
+	if s.dvd != nil {
+		return s.dvdResult(reportPath, cfg, humanize)
+	}
+	// Result.Playlists follows the report's playlist order.
+	playlists := report.SortPlaylists(s.playlists, cfg.PlaylistSort)
+	reportPath, reportText, err := report.RenderReport(reportPath, s.rom, playlists, s.scan, cfg)
+	if err != nil {
+		return Result{}, err
+	}
 
+	result := Result{
+		Disc:            buildDiscInfo(s.rom, humanize),
+		Playlists:       buildPlaylistInfo(playlists, cfg, humanize),
+		Scan:            buildScanInfo(s.scan),
+		Warnings:        buildWarnings(s.rom, playlists, cfg),
+		Stats:           buildScanStats(s.scan.Stats, s.wallTime),
+		JARImages:       buildJARImages(s.rom.JARImages),
+		Report:          reportText,
+		ReportPath:      reportPath,
+		OneLine:         report.RenderOneLine(s.rom, playlists, cfg),
+		Captions:        s.captions,
+		SubtitleTimings: s.timings,
+	}
+	if main, _ := report.MainPlaylistTies(playlists, cfg); main != nil {
+		result.MainPlaylist = main.Name
+		result.FFprobe = buildFFprobe(result.Disc, main)
+		if cfg.BitrateGraph {
+			result.BitrateGraph = buildBitrateGraph(main)
+		}
+	}
+	if cfg.BitrateGraph {
+		for _, playlist := range playlists {
+			graph := buildBitrateGraph(playlist)
+			if result.BitrateGraph != nil && playlist.Name == result.BitrateGraph.Playlist {
+				graph = result.BitrateGraph
+			}
+			result.BitrateGraphs = append(result.BitrateGraphs, graph)
+		}
+	}
+	groups := bdrom.GroupDuplicatePlaylists(playlists)
+	for _, group := range groups {
+		result.DuplicatePlaylists = append(result.DuplicatePlaylists, PlaylistGroup(group))
+	}
+	result.Disc.PlaylistObfuscation = bdrom.PlaylistObfuscation(groups)
+	if report.IsCSV(reportPath) {
+		result.ClipsCSV, err = report.RenderClipsCSV(playlists, cfg)
+		if err != nil {
+			return Result{}, err
+		}
+		result.ClipsPath = report.ClipsCSVPath(reportPath)
+	}
+	if cfg.GenerateFrameDataFile && reportPath != "-" {
+		for _, file := range report.RenderFrameData(reportPath, playlists, cfg) {
+			result.FrameData = append(result.FrameData, FrameDataFile(file))
+		}
+	}
 	return result, nil
 }
 
@@ -234,6 +835,69 @@ func emit(cb func(ProgressEvent), event ProgressEvent) {
 	}
 }
 
+// estimateRemaining extrapolates the time left to process total bytes from the
+// average rate over elapsed; zero until there is a rate to go on.
+func estimateRemaining(elapsed time.Duration, processed, total uint64) time.Duration {
+	if elapsed <= 0 || processed == 0 || processed >= total {
+		return 0
+	}
+	rate := float64(processed) / elapsed.Seconds()
+	return time.Duration(float64(total-processed) / rate * float64(time.Second)).Round(time.Second)
+}
+
+// scanProgress forwards the progress of one scan pass to options.OnProgress,
+// or returns nil when there is no callback.
+func scanProgress(options Options) bdrom.ScanProgressFunc {
+	if options.OnProgress == nil {
+		return nil
+	}
+	var streamStart time.Time
+	return func(update bdrom.ScanProgress) {
+		stage, ok := stageFromScanProgress(update.Stage)
+		if !ok {
+			return
+		}
+		now := time.Now()
+		var eta time.Duration
+		if stage == StageStream {
+			// The stage's opening update arrives before any stream
+			// worker starts, so only that call writes streamStart.
+			if streamStart.IsZero() {
+				streamStart = now
+			}
+			eta = estimateRemaining(now.Sub(streamStart), update.ProcessedBytes, update.TotalBytes)
+		}
+		emit(options.OnProgress, ProgressEvent{
+			Stage:              stage,
+			Path:               options.Path,
+			Completed:          update.Completed,
+			Total:              update.Total,
+			ProcessedBytes:     update.ProcessedBytes,
+			TotalBytes:         update.TotalBytes,
+			File:               update.File,
+			FileProcessedBytes: update.FileProcessedBytes,
+			FileTotalBytes:     update.FileTotalBytes,
+			ETA:                eta,
+			OccurredAt:         now,
+		})
+	}
+}
+
+// mergeScans adds the outcome of the full pass to that of the scan before it.
+// Files the full pass read without error keep the scan's error, if any.
+func mergeScans(scan, full bdrom.ScanResult) bdrom.ScanResult {
+	for name, err := range full.FileErrors {
+		scan.FileErrors[name] = err
+	}
+	scan.Stats.BytesRead += full.Stats.BytesRead
+	scan.Stats.WallTime += full.Stats.WallTime
+	scan.Stats.Workers = max(scan.Stats.Workers, full.Stats.Workers)
+	for name, elapsed := range full.Stats.FileDurations {
+		scan.Stats.FileDurations[name] += elapsed
+	}
+	return scan
+}
+
 func stageFromScanProgress(stage bdrom.ScanProgressStage) (Stage, bool) {
 	switch stage {
 	case bdrom.ScanStageClipInfo:
@@ -259,14 +923,33 @@ func orderedPlaylists(rom *bdrom.BDROM) []*bdrom.PlaylistFile {
 		}
 		return playlists
 	}
-	for _, pl := range rom.PlaylistFiles {
-		playlists = append(playlists, pl)
+	for _, name := range slices.Sorted(maps.Keys(rom.PlaylistFiles)) {
+		playlists = append(playlists, rom.PlaylistFiles[name])
 	}
 	return playlists
 }
 
-func buildDiscInfo(rom *bdrom.BDROM) DiscInfo {
-	return DiscInfo{
+func buildJARImages(images []bdrom.JARImage) []JARImage {
+	if len(images) == 0 {
+		return nil
+	}
+	out := make([]JARImage, 0, len(images))
+	for _, img := range images {
+		out = append(out, JARImage{
+			JAR:       img.JAR,
+			Name:      img.Name,
+			Format:    img.Format,
+			Width:     img.Width,
+			Height:    img.Height,
+			SizeBytes: len(img.Data),
+			Data:      img.Data,
+		})
+	}
+	return out
+}
+
+func buildDiscInfo(rom *bdrom.BDROM, humanize bool) DiscInfo {
+	info := DiscInfo{
 		Path:      rom.Path,
 		Title:     rom.DiscTitle,
 		Label:     rom.VolumeLabel,
@@ -275,30 +958,130 @@ func buildDiscInfo(rom *bdrom.BDROM) DiscInfo {
 		IsBDJava:  rom.IsBDJava,
 		IsDBOX:    rom.IsDBOX,
 		IsPSP:     rom.IsPSP,
+		IsBDAV:    rom.IsBDAV,
 		Is3D:      rom.Is3D,
 		Is50Hz:    rom.Is50Hz,
 		IsUHD:     rom.IsUHD,
+
+		MKBVersion: rom.MKBVersion,
+		BDPlusDate: rom.BDPlusDate,
+	}
+	if humanize {
+		info.SizeHuman = humanBytes(info.SizeBytes)
 	}
+	return info
 }
 
-func buildPlaylistInfo(playlists []*bdrom.PlaylistFile) []PlaylistInfo {
+func buildPlaylistInfo(playlists []*bdrom.PlaylistFile, cfg internalsettings.Settings, humanize bool) []PlaylistInfo {
 	out := make([]PlaylistInfo, 0, len(playlists))
 	for _, playlist := range playlists {
 		if playlist == nil {
 			continue
 		}
-		out = append(out, PlaylistInfo{
-			Name:            playlist.Name,
-			LengthSeconds:   playlist.TotalLength(),
-			SizeBytes:       playlist.TotalSize(),
-			TotalBitrateBps: playlist.TotalBitRate(),
-			HasHiddenTracks: playlist.HasHiddenTracks,
-			IsValid:         playlist.IsValid(),
+		info := PlaylistInfo{
+			Name:                playlist.Name,
+			LengthSeconds:       playlist.TotalLength(),
+			Length:              report.FormatDuration(playlist.TotalLength(), playlist, cfg),
+			SizeBytes:           playlist.TotalSize(),
+			AllAnglesSizeBytes:  playlist.TotalAngleSize(),
+			TotalBitrateBps:     playlist.TotalBitRate(),
+			HasHiddenTracks:     playlist.HasHiddenTracks,
+			IsValid:             playlist.IsValid(),
+			ReachableFromTitle1: playlist.ReachableFromTitle1,
+			Orphaned:            playlist.Orphaned,
+			Streams:             buildStreamInfo(playlist),
+			Clips:               buildClipInfo(playlist),
+			Chapters:            buildChapterInfo(playlist),
+			SSIF:                buildSSIFInfo(playlist),
+		}
+		if humanize {
+			info.SizeHuman = humanBytes(info.SizeBytes)
+			info.TotalBitrateHuman = humanBitrate(info.TotalBitrateBps)
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+func buildSSIFInfo(playlist *bdrom.PlaylistFile) *SSIFInfo {
+	summary, ok := playlist.SSIFSummary()
+	if !ok {
+		return nil
+	}
+	return &SSIFInfo{
+		SizeBytes:           summary.Size,
+		BaseEye:             summary.BaseEye,
+		BaseBitrateBps:      uint64(summary.Base.BitRate),
+		DependentBitrateBps: uint64(summary.Dependent.BitRate),
+		CombinedBitrateBps:  summary.CombinedBitRate(),
+	}
+}
+
+func buildClipInfo(playlist *bdrom.PlaylistFile) []ClipInfo {
+	clips := make([]ClipInfo, 0, len(playlist.StreamClips))
+	for _, clip := range playlist.StreamClips {
+		clips = append(clips, ClipInfo{
+			Name:          clip.DisplayName(),
+			Angle:         clip.AngleIndex,
+			StartSeconds:  clip.RelativeTimeIn,
+			InSeconds:     clip.TimeIn,
+			OutSeconds:    clip.TimeOut,
+			LengthSeconds: clip.Length,
+			SizeBytes:     clip.PacketSize(),
+			BitrateBps:    clip.PacketBitRate(),
+			UnknownPIDs:   unknownPIDInfo(clip.StreamFile),
 		})
 	}
+	return clips
+}
+
+func unknownPIDInfo(file *bdrom.StreamFile) []UnknownPIDInfo {
+	if file == nil {
+		return nil
+	}
+	var out []UnknownPIDInfo
+	for _, unknown := range file.SignificantUnknownPIDs() {
+		out = append(out, UnknownPIDInfo{PID: unknown.PID, Bytes: unknown.Bytes})
+	}
 	return out
 }
 
+func buildChapterInfo(playlist *bdrom.PlaylistFile) []ChapterInfo {
+	chapters := make([]ChapterInfo, 0, len(playlist.Chapters))
+	for i, start := range playlist.Chapters {
+		end := playlist.TotalLength()
+		if i+1 < len(playlist.Chapters) {
+			end = playlist.Chapters[i+1]
+		}
+		chapters = append(chapters, ChapterInfo{
+			Number:        i + 1,
+			StartSeconds:  start,
+			LengthSeconds: end - start,
+			Name:          playlist.ChapterName(i),
+		})
+	}
+	return chapters
+}
+
+func humanBytes(n uint64) string {
+	return util.FormatFileSize(float64(n), true)
+}
+
+func humanBitrate(bps uint64) string {
+	return fmt.Sprintf("%.2f Mbps", float64(bps)/1_000_000)
+}
+
+func buildScanStats(stats bdrom.ScanStats, wall time.Duration) ScanStats {
+	return ScanStats{
+		BytesRead:     stats.BytesRead,
+		WallTime:      wall,
+		ScanTime:      stats.WallTime,
+		Workers:       stats.Workers,
+		FileDurations: stats.FileDurations,
+		CacheHits:     stats.CacheHits,
+	}
+}
+
 func buildScanInfo(scan bdrom.ScanResult) ScanInfo {
 	info := ScanInfo{FileErrors: make(map[string]string, len(scan.FileErrors))}
 	if scan.ScanError != nil {
@@ -310,6 +1093,12 @@ func buildScanInfo(scan bdrom.ScanResult) ScanInfo {
 		}
 		info.FileErrors[name] = err.Error()
 	}
+	for name, err := range scan.BackupFiles {
+		if info.BackupFiles == nil {
+			info.BackupFiles = make(map[string]string, len(scan.BackupFiles))
+		}
+		info.BackupFiles[name] = err.Error()
+	}
 	return info
 }
 
@@ -317,6 +1106,7 @@ func fromInternalSettings(s internalsettings.Settings) Settings {
 	return Settings{
 		GenerateStreamDiagnostics: s.GenerateStreamDiagnostics,
 		ExtendedStreamDiagnostics: s.ExtendedStreamDiagnostics,
+		GenerateFrameDataFile:     s.GenerateFrameDataFile,
 		EnableSSIF:                s.EnableSSIF,
 		BigPlaylistOnly:           s.BigPlaylistOnly,
 		FilterLoopingPlaylists:    s.FilterLoopingPlaylists,
@@ -326,11 +1116,51 @@ func fromInternalSettings(s internalsettings.Settings) Settings {
 		GenerateTextSummary:       s.GenerateTextSummary,
 		ReportFileName:            s.ReportFileName,
 		IncludeVersionAndNotes:    s.IncludeVersionAndNotes,
+		IncludeNotesHome:          s.IncludeNotesHome,
+		IncludeNotesForums:        s.IncludeNotesForums,
+		ProductVersion:            s.ProductVersion,
+		ToolVersion:               s.ToolVersion,
+		IncludeGeneratedBy:        s.IncludeGeneratedBy,
+		ReportLanguage:            s.ReportLanguage,
+		ReportLabelsFile:          s.ReportLabelsFile,
+		TimeFormat:                s.TimeFormat,
+		RoundingMode:              s.RoundingMode,
+		PlaylistSort:              s.PlaylistSort,
 		GroupByTime:               s.GroupByTime,
 		ForumsOnly:                s.ForumsOnly,
 		PlaylistOnly:              s.PlaylistOnly,
+		TitleOnly:                 s.TitleOnly,
 		MainPlaylistOnly:          s.MainPlaylistOnly,
+		TopPlaylists:              s.TopPlaylists,
 		SummaryOnly:               s.SummaryOnly,
+		TempDir:                   s.TempDir,
+		NoTempFiles:               s.NoTempFiles,
+		HarvestJARImages:          s.HarvestJARImages,
+		IncludeTitleMap:           s.IncludeTitleMap,
+		IncludeRestrictions:       s.IncludeRestrictions,
+		Include3DOffsets:          s.Include3DOffsets,
+		Include3DBitrates:         s.Include3DBitrates,
+		IncludeBitrateGraph:       s.IncludeBitrateGraph,
+		IncludeChapterNames:       s.IncludeChapterNames,
+		IncludeProtectionDetails:  s.IncludeProtectionDetails,
+		WideColumns:               s.WideColumns,
+		ShowEmptySections:         s.ShowEmptySections,
+		SplitOverlappingClips:     s.SplitOverlappingClips,
+		TrackUnknownPIDs:          s.TrackUnknownPIDs,
+		SubtitleSummary:           s.SubtitleSummary,
+		QuickScan:                 s.QuickScan,
+		FullScan:                  s.FullScan,
+		LowMemory:                 s.LowMemory,
+		CollapseDuplicates:        s.CollapseDuplicates,
+		BitrateGraph:              s.BitrateGraph,
+		SubtitleTiming:            s.SubtitleTiming,
+		IORetries:                 s.IORetries,
+		IORetryDelay:              s.IORetryDelay,
+		MaxReadMbps:               s.MaxReadMbps,
+		Workers:                   s.Workers,
+		IOMode:                    s.IOMode,
+		CacheDir:                  s.CacheDir,
+		MaxOpenFiles:              s.MaxOpenFiles,
 	}
 }
 
@@ -338,6 +1168,7 @@ func toInternalSettings(s Settings) internalsettings.Settings {
 	return internalsettings.Settings{
 		GenerateStreamDiagnostics: s.GenerateStreamDiagnostics,
 		ExtendedStreamDiagnostics: s.ExtendedStreamDiagnostics,
+		GenerateFrameDataFile:     s.GenerateFrameDataFile,
 		EnableSSIF:                s.EnableSSIF,
 		BigPlaylistOnly:           s.BigPlaylistOnly,
 		FilterLoopingPlaylists:    s.FilterLoopingPlaylists,
@@ -347,11 +1178,51 @@ func toInternalSettings(s Settings) internalsettings.Settings {
 		GenerateTextSummary:       s.GenerateTextSummary,
 		ReportFileName:            s.ReportFileName,
 		IncludeVersionAndNotes:    s.IncludeVersionAndNotes,
+		IncludeNotesHome:          s.IncludeNotesHome,
+		IncludeNotesForums:        s.IncludeNotesForums,
+		ProductVersion:            s.ProductVersion,
+		ToolVersion:               s.ToolVersion,
+		IncludeGeneratedBy:        s.IncludeGeneratedBy,
+		ReportLanguage:            s.ReportLanguage,
+		ReportLabelsFile:          s.ReportLabelsFile,
+		TimeFormat:                s.TimeFormat,
+		RoundingMode:              s.RoundingMode,
+		PlaylistSort:              s.PlaylistSort,
 		GroupByTime:               s.GroupByTime,
 		ForumsOnly:                s.ForumsOnly,
 		PlaylistOnly:              s.PlaylistOnly,
+		TitleOnly:                 s.TitleOnly,
 		MainPlaylistOnly:          s.MainPlaylistOnly,
+		TopPlaylists:              s.TopPlaylists,
 		SummaryOnly:               s.SummaryOnly,
+		TempDir:                   s.TempDir,
+		NoTempFiles:               s.NoTempFiles,
+		HarvestJARImages:          s.HarvestJARImages,
+		IncludeTitleMap:           s.IncludeTitleMap,
+		IncludeRestrictions:       s.IncludeRestrictions,
+		Include3DOffsets:          s.Include3DOffsets,
+		Include3DBitrates:         s.Include3DBitrates,
+		IncludeBitrateGraph:       s.IncludeBitrateGraph,
+		IncludeChapterNames:       s.IncludeChapterNames,
+		IncludeProtectionDetails:  s.IncludeProtectionDetails,
+		WideColumns:               s.WideColumns,
+		ShowEmptySections:         s.ShowEmptySections,
+		SplitOverlappingClips:     s.SplitOverlappingClips,
+		TrackUnknownPIDs:          s.TrackUnknownPIDs,
+		SubtitleSummary:           s.SubtitleSummary,
+		QuickScan:                 s.QuickScan,
+		FullScan:                  s.FullScan,
+		LowMemory:                 s.LowMemory,
+		CollapseDuplicates:        s.CollapseDuplicates,
+		BitrateGraph:              s.BitrateGraph,
+		SubtitleTiming:            s.SubtitleTiming,
+		IORetries:                 s.IORetries,
+		IORetryDelay:              s.IORetryDelay,
+		MaxReadMbps:               s.MaxReadMbps,
+		Workers:                   s.Workers,
+		IOMode:                    s.IOMode,
+		CacheDir:                  s.CacheDir,
+		MaxOpenFiles:              s.MaxOpenFiles,
 	}
 }
 
@@ -367,3 +1238,33 @@ func filterROMToPlaylist(rom *bdrom.BDROM, playlistName string) error {
 	rom.PlaylistOrder = []string{playlistName}
 	return nil
 }
+
+// filterROMToTitle keeps the playlists index.bdmv Title number plays, in disc
+// order. Playlists the title names but the disc lacks are skipped.
+func filterROMToTitle(rom *bdrom.BDROM, number int) error {
+	if rom == nil || number == 0 {
+		return nil
+	}
+	names, err := rom.TitlePlaylists(number)
+	if err != nil {
+		return err
+	}
+	files := make(map[string]*bdrom.PlaylistFile, len(names))
+	for _, name := range names {
+		if pl, ok := rom.PlaylistFiles[name]; ok {
+			files[name] = pl
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("title %d: playlists not found: %s", number, strings.Join(names, ", "))
+	}
+	order := make([]string, 0, len(files))
+	for _, name := range rom.PlaylistOrder {
+		if _, ok := files[name]; ok {
+			order = append(order, name)
+		}
+	}
+	rom.PlaylistFiles = files
+	rom.PlaylistOrder = order
+	return nil
+}
@@ -0,0 +1,188 @@
+package bdinfo
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	internalsettings "github.com/autobrr/go-bdinfo/internal/settings"
+)
+
+// writeTestDisc lays out a disc named MOVIE whose playlists each play one
+// 60 second item of clip 00001, which has a clip info but no stream file.
+func writeTestDisc(t *testing.T, playlists ...string) string {
+	t.Helper()
+	disc := filepath.Join(t.TempDir(), "MOVIE")
+	for _, dir := range []string{"PLAYLIST", "CLIPINF", "STREAM"} {
+		if err := os.MkdirAll(filepath.Join(disc, "BDMV", dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(disc, "BDMV", path), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clpi := make([]byte, 256)
+	copy(clpi, "HDMV0200")
+	binary.BigEndian.PutUint32(clpi[12:], 200) // ProgramInfo with no streams
+	copy(clpi[40+151:], "HDMV")
+	binary.BigEndian.PutUint32(clpi[200:], 12)
+	write("CLIPINF/00001.clpi", clpi)
+
+	item := []byte("00001M2TS")
+	item = append(item, 0x00, 0x00, 0x00) // connection, stc_id
+	item = binary.BigEndian.AppendUint32(item, 0)
+	item = binary.BigEndian.AppendUint32(item, 45000*60)
+	item = append(item, make([]byte, 12)...) // UO mask, flags, still
+	item = append(item, make([]byte, 16)...) // STN_table with no streams
+	list := binary.BigEndian.AppendUint16(nil, uint16(len(item)))
+	list = append(list, item...)
+	mpls := make([]byte, 0x40)
+	copy(mpls, "MPLS0200")
+	mpls = binary.BigEndian.AppendUint32(mpls, uint32(6+len(list)))
+	mpls = binary.BigEndian.AppendUint16(mpls, 0)
+	mpls = binary.BigEndian.AppendUint16(mpls, 1)
+	mpls = binary.BigEndian.AppendUint16(mpls, 0)
+	mpls = append(mpls, list...)
+	binary.BigEndian.PutUint32(mpls[8:], 0x40)
+	binary.BigEndian.PutUint32(mpls[12:], uint32(len(mpls)))
+	mpls = append(mpls, 0, 0, 0, 2, 0, 0) // no chapters
+	for _, name := range playlists {
+		write("PLAYLIST/"+name, mpls)
+	}
+	return disc
+}
+
+func TestScanRender(t *testing.T) {
+	disc := writeTestDisc(t, "00800.mpls", "00801.mpls")
+	settings := DefaultSettings(t.TempDir())
+	full, err := Scan(context.Background(), Options{Path: disc, Settings: settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := Render(full, FormatText, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "MOVIE") || !strings.Contains(text, "00800.MPLS") || !strings.Contains(text, "00801.MPLS") {
+		t.Fatalf("text report misses the disc or a playlist:\n%s", text)
+	}
+
+	// A report setting applies to the next Render without rescanning.
+	settings.MainPlaylistOnly = true
+	mainOnly, err := Render(full, FormatText, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(mainOnly, "00801.MPLS") || !strings.Contains(mainOnly, "00800.MPLS") {
+		t.Fatalf("main playlist report lists other playlists:\n%s", mainOnly)
+	}
+
+	data, err := Render(full, FormatJSON, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result Result
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Disc.Label != "MOVIE" || result.MainPlaylist != "00800.MPLS" || len(result.Playlists) != 2 || result.Playlists[0].LengthSeconds != 60 {
+		t.Fatalf("JSON result got label=%q main=%q playlists=%+v", result.Disc.Label, result.MainPlaylist, result.Playlists)
+	}
+	if result.Report != mainOnly {
+		t.Fatal("JSON result carries a different report than FormatText")
+	}
+
+	line, err := Render(full, FormatOneLine, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(line, "MOVIE | 0:01:00") || strings.Count(line, "\n") != 1 {
+		t.Fatalf("one line got=%q", line)
+	}
+
+	if _, err := Render(full, "yaml", settings); err == nil {
+		t.Fatal("Render() accepted an unknown format")
+	}
+	if _, err := Render(nil, FormatText, settings); err == nil {
+		t.Fatal("Render() accepted a nil scan")
+	}
+}
+
+// TestScanSoak scans the same disc many times, as a long batch does, and
+// checks that nothing from a finished scan stays reachable.
+func TestScanSoak(t *testing.T) {
+	if testing.Short() {
+		t.Skip("soak test")
+	}
+	disc := writeTestDisc(t, "00800.mpls", "00801.mpls")
+	settings := DefaultSettings(t.TempDir())
+	settings.ExtendedStreamDiagnostics = true
+	heap := func() uint64 {
+		runtime.GC()
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return stats.HeapAlloc
+	}
+
+	scan := func(n int) {
+		for range n {
+			result, err := Scan(context.Background(), Options{Path: disc, Settings: settings})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Render(result, FormatText, settings); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	scan(20)
+	before := heap()
+	scan(2000)
+	if after := heap(); after > before+4<<20 {
+		t.Fatalf("heap grew over 2000 scans: before=%d after=%d", before, after)
+	}
+}
+
+func TestBuildClipInfo(t *testing.T) {
+	file := &bdrom.StreamFile{Name: "00002.M2TS", Size: 192 * 100_000, UnknownPIDs: map[uint16]uint64{0x1F00: 192 * 1000, 0x1F01: 192}}
+	ssif := &bdrom.StreamFile{Name: "00003.M2TS", InterleavedFile: &bdrom.InterleavedFile{Name: "00003.SSIF"}}
+	playlist := &bdrom.PlaylistFile{
+		Name: "00800.MPLS",
+		StreamClips: []*bdrom.StreamClip{
+			{Name: "00002.M2TS", StreamFile: file, TimeIn: 10, TimeOut: 70, Length: 60, RelativeTimeOut: 60, PacketCount: 50_000, PacketSeconds: 60},
+			{Name: "00003.M2TS", StreamFile: ssif, AngleIndex: 1, TimeIn: 0, TimeOut: 30, Length: 30, RelativeTimeIn: 60, RelativeTimeOut: 90, Settings: internalsettings.Settings{EnableSSIF: true}},
+		},
+	}
+
+	clips := buildClipInfo(playlist)
+	if len(clips) != 2 {
+		t.Fatalf("clips got=%d want=2", len(clips))
+	}
+	want := ClipInfo{
+		Name:          "00002.M2TS",
+		InSeconds:     10,
+		OutSeconds:    70,
+		LengthSeconds: 60,
+		SizeBytes:     192 * 50_000,
+		BitrateBps:    192 * 50_000 * 8 / 60,
+		UnknownPIDs:   []UnknownPIDInfo{{PID: 0x1F00, Bytes: 192 * 1000}},
+	}
+	if got := clips[0]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("clip got=%+v want=%+v", got, want)
+	}
+	if got := clips[1]; got.Name != "00003.SSIF" || got.Angle != 1 || got.StartSeconds != 60 || got.LengthSeconds != 30 || got.BitrateBps != 0 {
+		t.Fatalf("interleaved angle clip got=%+v", got)
+	}
+}
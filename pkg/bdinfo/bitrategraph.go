@@ -0,0 +1,90 @@
+package bdinfo
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/report"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// BitrateGraph is the bitrate over time of a playlist's video and audio
+// streams, one value per second of the playlist, for plotting. It is built
+// from the same packet windows as the STREAM DIAGNOSTICS and chapter
+// statistics, on the main angle.
+type BitrateGraph struct {
+	Playlist string          `json:"playlist"`
+	Seconds  int             `json:"seconds"`
+	Series   []BitrateSeries `json:"series"`
+}
+
+// BitrateSeries is one stream of a BitrateGraph. BitratesBps[i] is the
+// bitrate, in bits per second, of the stream's packets in second i of the
+// playlist. ID matches StreamInfo.ID.
+type BitrateSeries struct {
+	ID          string     `json:"id"`
+	Kind        StreamKind `json:"kind"`
+	PID         uint16     `json:"pid"`
+	Codec       string     `json:"codec"`
+	Language    string     `json:"language,omitempty"`
+	BitratesBps []uint64   `json:"bitratesBps"`
+}
+
+// buildBitrateGraph spreads the diagnostics windows of playlist's streams over
+// the seconds of the playlist.
+func buildBitrateGraph(playlist *bdrom.PlaylistFile) *BitrateGraph {
+	seconds := int(math.Ceil(playlist.TotalLength()))
+	graph := &BitrateGraph{Playlist: playlist.Name, Seconds: seconds, Series: []BitrateSeries{}}
+	for _, st := range playlist.SortedStreams {
+		base := st.Base()
+		if base.AngleIndex > 0 {
+			continue
+		}
+		var kind StreamKind
+		switch {
+		case base.IsVideoStream():
+			kind = StreamKindVideo
+		case base.IsAudioStream():
+			kind = StreamKindAudio
+		default:
+			continue
+		}
+		graph.Series = append(graph.Series, BitrateSeries{
+			ID:          report.StreamID(playlist.Name, st),
+			Kind:        kind,
+			PID:         base.PID,
+			Codec:       stream.CodecShortNameForInfo(st),
+			Language:    base.LanguageCode(),
+			BitratesBps: playlist.BitrateSeries(base.PID),
+		})
+	}
+	return graph
+}
+
+// WriteCSV writes g as CSV: a Second column, then one column per series
+// headed by its ID, in bits per second.
+func (g *BitrateGraph) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"Second"}
+	for _, series := range g.Series {
+		header = append(header, series.ID)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	row := make([]string, len(header))
+	for second := range g.Seconds {
+		row[0] = strconv.Itoa(second)
+		for i, series := range g.Series {
+			row[i+1] = strconv.FormatUint(series.BitratesBps[second], 10)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,116 @@
+package bdinfo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// ChapterFormat selects the chapter file layout written by WriteChapters.
+type ChapterFormat string
+
+const (
+	// ChapterFormatMatroska is Matroska XML chapters, as read by mkvmerge --chapters.
+	ChapterFormatMatroska ChapterFormat = "matroska"
+	// ChapterFormatOGM is OGM simple chapters (CHAPTER01=00:00:00.000 and
+	// CHAPTER01NAME=... lines).
+	ChapterFormatOGM ChapterFormat = "ogm"
+)
+
+// ChapterFormatForPath picks Matroska XML for .xml file names and OGM simple
+// chapters for anything else.
+func ChapterFormatForPath(path string) ChapterFormat {
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		return ChapterFormatMatroska
+	}
+	return ChapterFormatOGM
+}
+
+// Playlist returns the playlist called name from r.Playlists, or the main
+// playlist when name is empty.
+func (r Result) Playlist(name string) (PlaylistInfo, bool) {
+	if name == "" {
+		name = r.MainPlaylist
+	}
+	for _, playlist := range r.Playlists {
+		if strings.EqualFold(playlist.Name, name) {
+			return playlist, true
+		}
+	}
+	return PlaylistInfo{}, false
+}
+
+// WriteChapters writes the playlist's chapter marks to w in format. Chapters
+// without a name from the disc are called "Chapter NN".
+func (p PlaylistInfo) WriteChapters(w io.Writer, format ChapterFormat) error {
+	switch format {
+	case ChapterFormatMatroska:
+		return writeMatroskaChapters(w, p.Chapters)
+	case ChapterFormatOGM:
+		return writeOGMChapters(w, p.Chapters)
+	default:
+		return fmt.Errorf("unsupported chapter format: %s", format)
+	}
+}
+
+func chapterTitle(chapter ChapterInfo) string {
+	if chapter.Name != "" {
+		return chapter.Name
+	}
+	return fmt.Sprintf("Chapter %02d", chapter.Number)
+}
+
+func writeOGMChapters(w io.Writer, chapters []ChapterInfo) error {
+	var b strings.Builder
+	for _, chapter := range chapters {
+		ms := int64(math.Round(chapter.StartSeconds * 1000))
+		fmt.Fprintf(&b, "CHAPTER%02d=%02d:%02d:%02d.%03d\n", chapter.Number, ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+		fmt.Fprintf(&b, "CHAPTER%02dNAME=%s\n", chapter.Number, chapterTitle(chapter))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type mkvChapters struct {
+	XMLName xml.Name   `xml:"Chapters"`
+	Edition mkvEdition `xml:"EditionEntry"`
+}
+
+type mkvEdition struct {
+	Atoms []mkvChapterAtom `xml:"ChapterAtom"`
+}
+
+type mkvChapterAtom struct {
+	TimeStart string         `xml:"ChapterTimeStart"`
+	Display   mkvChapterName `xml:"ChapterDisplay"`
+}
+
+type mkvChapterName struct {
+	String   string `xml:"ChapterString"`
+	Language string `xml:"ChapterLanguage"`
+}
+
+func writeMatroskaChapters(w io.Writer, chapters []ChapterInfo) error {
+	doc := mkvChapters{}
+	for _, chapter := range chapters {
+		ns := int64(math.Round(chapter.StartSeconds * 1e9))
+		doc.Edition.Atoms = append(doc.Edition.Atoms, mkvChapterAtom{
+			TimeStart: fmt.Sprintf("%02d:%02d:%02d.%09d", ns/3600e9, ns/60e9%60, ns/1e9%60, ns%1e9),
+			Display:   mkvChapterName{String: chapterTitle(chapter), Language: "und"},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE Chapters SYSTEM \"matroskachapters.dtd\">\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
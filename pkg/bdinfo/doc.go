@@ -0,0 +1,19 @@
+// Package bdinfo scans Blu-ray discs (BDMV or BDAV folders and ISO images) and
+// renders BDInfo reports from them.
+//
+// Run scans one disc and returns a Result: the text report, byte-identical to
+// official BDInfo with the default settings, together with the same data in
+// structured form. To render several reports from one scan, call Scan once and
+// Render (or RenderTo) per variant; Save and LoadScan keep a scan for later.
+// Scanning a folder of discs is a loop over Run whose results can be collected
+// into a MultiDiscResult for the combined report.
+//
+// # Compatibility
+//
+// The exported API follows semantic versioning from v1: within a major version
+// types, functions and struct fields are only added, and the JSON field names
+// of Result are kept. New settings default to the behaviour of the release
+// before them, so a zero field never changes a report.
+// ScanResultFull is opaque and no exported identifier refers to the internal
+// packages, which may change in any release.
+package bdinfo
@@ -0,0 +1,205 @@
+package bdinfo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/autobrr/go-bdinfo/internal/dvd"
+	"github.com/autobrr/go-bdinfo/internal/report"
+	internalsettings "github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/util"
+)
+
+// errDVDSave is returned when a DVD scan is saved: snapshots hold BD scans only.
+var errDVDSave = errors.New("DVD scans cannot be saved")
+
+// scanDVD completes Scan for a DVD-Video disc. Each title of VIDEO_TS.IFO
+// stands in for a playlist; TitleOnly keeps one title and the short playlist
+// filter drops short titles.
+func scanDVD(options Options, cfg internalsettings.Settings, disc *dvd.Disc, start time.Time) (*ScanResultFull, error) {
+	if cfg.PlaylistOnly != "" {
+		return nil, errors.New("PlaylistOnly does not apply to DVDs; use TitleOnly")
+	}
+	if cfg.TitleOnly > 0 {
+		disc.Titles = slices.DeleteFunc(disc.Titles, func(t *dvd.Title) bool { return t.Number != cfg.TitleOnly })
+		if len(disc.Titles) == 0 {
+			return nil, fmt.Errorf("title %d not found", cfg.TitleOnly)
+		}
+	}
+	if cfg.FilterShortPlaylists {
+		disc.Titles = slices.DeleteFunc(disc.Titles, func(t *dvd.Title) bool {
+			return t.Length < float64(cfg.FilterShortPlaylistsVal)
+		})
+	}
+	if options.SaveScan != nil {
+		return nil, fmt.Errorf("save scan: %w", errDVDSave)
+	}
+
+	emit(options.OnProgress, ProgressEvent{
+		Stage:      StageDiscovered,
+		Path:       options.Path,
+		Playlists:  len(disc.Titles),
+		OccurredAt: time.Now(),
+	})
+	emit(options.OnProgress, ProgressEvent{
+		Stage:      StageScanComplete,
+		Path:       options.Path,
+		Completed:  1,
+		Total:      1,
+		Elapsed:    time.Since(start),
+		OccurredAt: time.Now(),
+	})
+	return &ScanResultFull{
+		Path:     options.Path,
+		dvd:      disc,
+		cfg:      cfg,
+		wallTime: time.Since(start),
+	}, nil
+}
+
+// renderDVD is Render for a DVD scan; there is no ffprobe view of a title.
+func (s *ScanResultFull) renderDVD(format Format, cfg internalsettings.Settings) (string, error) {
+	switch format {
+	case FormatText, "":
+		_, text, err := report.RenderDVDReport("", s.dvd, cfg)
+		return text, err
+	case FormatJSON:
+		res, err := s.dvdResult("", cfg, false)
+		if err != nil {
+			return "", err
+		}
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	case FormatOneLine:
+		return report.RenderDVDOneLine(s.dvd) + "\n", nil
+	case FormatFFprobe:
+		return "", errors.New("ffprobe output is not available for DVDs")
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// dvdResult renders the DVD report and assembles the Result: titles fill
+// Playlists, named "Title 01" and so on, with their streams and chapters.
+func (s *ScanResultFull) dvdResult(reportPath string, cfg internalsettings.Settings, humanize bool) (Result, error) {
+	reportPath, reportText, err := report.RenderDVDReport(reportPath, s.dvd, cfg)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{
+		Disc: DiscInfo{
+			Path:      s.dvd.Path,
+			Label:     s.dvd.VolumeLabel,
+			SizeBytes: s.dvd.Size,
+			IsDVD:     true,
+		},
+		Playlists:  make([]PlaylistInfo, 0, len(s.dvd.Titles)),
+		Stats:      ScanStats{WallTime: s.wallTime, ScanTime: s.wallTime},
+		Report:     reportText,
+		ReportPath: reportPath,
+		OneLine:    report.RenderDVDOneLine(s.dvd),
+	}
+	if humanize {
+		result.Disc.SizeHuman = humanBytes(result.Disc.SizeBytes)
+	}
+	for _, title := range s.dvd.Titles {
+		info := dvdPlaylistInfo(title)
+		if humanize {
+			info.SizeHuman = humanBytes(info.SizeBytes)
+			info.TotalBitrateHuman = humanBitrate(info.TotalBitrateBps)
+		}
+		result.Playlists = append(result.Playlists, info)
+	}
+	if main := report.DVDTitles(s.dvd, internalsettings.Settings{MainPlaylistOnly: true}); len(main) > 0 {
+		result.MainPlaylist = report.DVDTitleName(main[0])
+	}
+	return result, nil
+}
+
+func dvdPlaylistInfo(title *dvd.Title) PlaylistInfo {
+	name := report.DVDTitleName(title)
+	info := PlaylistInfo{
+		Name:                name,
+		LengthSeconds:       title.Length,
+		Length:              util.FormatTime(title.Length, false),
+		SizeBytes:           title.Size,
+		AllAnglesSizeBytes:  title.Size,
+		TotalBitrateBps:     title.TotalBitrate(),
+		IsValid:             true,
+		ReachableFromTitle1: title.Number == 1,
+		Streams:             []StreamInfo{},
+		Clips:               []ClipInfo{},
+		Chapters:            make([]ChapterInfo, 0, len(title.Chapters)),
+	}
+
+	video := title.Video
+	info.Streams = append(info.Streams, StreamInfo{
+		ID:          fmt.Sprintf("%s/%s/%d", name, StreamKindVideo, 0xE0),
+		Kind:        StreamKindVideo,
+		PID:         0xE0,
+		Codec:       video.CodecName(),
+		CodecShort:  video.CodecShortName(),
+		Description: video.Description(),
+		Video: &VideoDetails{
+			Width:       video.Width,
+			Height:      video.Height,
+			Interlaced:  true,
+			FrameRate:   math.Round(video.FrameRate*1000) / 1000,
+			AspectRatio: video.AspectRatio,
+		},
+	})
+	for _, audio := range title.Audio {
+		info.Streams = append(info.Streams, StreamInfo{
+			ID:           fmt.Sprintf("%s/%s/%d", name, StreamKindAudio, audio.StreamID),
+			Kind:         StreamKindAudio,
+			PID:          uint16(audio.StreamID),
+			Codec:        audio.CodecName(),
+			CodecShort:   audio.CodecShortName(),
+			Language:     audio.LanguageName(),
+			LanguageCode: audio.LanguageCode,
+			Description:  audio.Description(),
+			Audio:        dvdAudioDetails(audio),
+		})
+	}
+	for _, sp := range title.Subpictures {
+		info.Streams = append(info.Streams, StreamInfo{
+			ID:           fmt.Sprintf("%s/%s/%d", name, StreamKindSubtitle, sp.StreamID),
+			Kind:         StreamKindSubtitle,
+			PID:          uint16(sp.StreamID),
+			Codec:        "DVD Subtitles",
+			CodecShort:   "VobSub",
+			Language:     sp.LanguageName(),
+			LanguageCode: sp.LanguageCode,
+			Description:  sp.Extension,
+			Subtitle:     &SubtitleDetails{},
+		})
+	}
+	for i, chapter := range title.Chapters {
+		info.Chapters = append(info.Chapters, ChapterInfo{
+			Number:        i + 1,
+			StartSeconds:  chapter.Start,
+			LengthSeconds: chapter.Length,
+		})
+	}
+	return info
+}
+
+func dvdAudioDetails(audio dvd.Audio) *AudioDetails {
+	details := &AudioDetails{
+		Channels:     audio.ChannelDescription(),
+		ChannelCount: audio.Channels,
+		SampleRateHz: audio.SampleRate,
+		BitDepth:     audio.BitDepth,
+	}
+	if audio.Channels >= 6 {
+		details.ChannelCount, details.LFE = audio.Channels-1, 1
+	}
+	return details
+}
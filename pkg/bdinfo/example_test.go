@@ -0,0 +1,96 @@
+package bdinfo_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/autobrr/go-bdinfo/pkg/bdinfo"
+)
+
+func ExampleRun() {
+	settings := bdinfo.DefaultSettings(".")
+	settings.MainPlaylistOnly = true
+	result, err := bdinfo.Run(context.Background(), bdinfo.Options{
+		Path:     "/media/MOVIE_DISC",
+		Settings: settings,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	playlist, _ := result.Playlist("")
+	fmt.Printf("%s: %s, %d streams\n", result.Disc.Label, playlist.Length, len(playlist.Streams))
+	if err := os.WriteFile(result.ReportPath, []byte(result.Report), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleRenderTo() {
+	full, err := bdinfo.Scan(context.Background(), bdinfo.Options{
+		Path:     "/media/MOVIE_DISC.iso",
+		Settings: bdinfo.DefaultSettings("."),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// One scan, several reports: the full report and the forums paste.
+	settings := bdinfo.DefaultSettings(".")
+	if err := bdinfo.RenderTo(os.Stdout, full, bdinfo.FormatText, settings); err != nil {
+		log.Fatal(err)
+	}
+	settings.ForumsOnly = true
+	if err := bdinfo.RenderTo(os.Stdout, full, bdinfo.FormatText, settings); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleMultiDiscResult() {
+	settings := bdinfo.DefaultSettings(".")
+	var batch bdinfo.MultiDiscResult
+	for _, path := range []string{"/media/SEASON_1_DISC_1", "/media/SEASON_1_DISC_2"} {
+		disc := bdinfo.DiscResult{Path: path}
+		result, err := bdinfo.Run(context.Background(), bdinfo.Options{Path: path, Settings: settings})
+		if err != nil {
+			// Keep going: a failed disc is listed in the index with its error.
+			disc.Error = err.Error()
+		} else {
+			disc.Result = result
+		}
+		batch.Discs = append(batch.Discs, disc)
+	}
+
+	for _, summary := range batch.Summaries() {
+		fmt.Println(summary.Label, summary.MainPlaylist, summary.Length, summary.Error)
+	}
+	fmt.Print(batch.Report())
+}
+
+func ExampleResult_Field() {
+	result := bdinfo.Result{
+		MainPlaylist: "00800.MPLS",
+		Playlists: []bdinfo.PlaylistInfo{{
+			Name:          "00800.MPLS",
+			LengthSeconds: 7265.4,
+			Streams: []bdinfo.StreamInfo{
+				{Kind: bdinfo.StreamKindVideo, CodecShort: "HEVC"},
+				{Kind: bdinfo.StreamKindAudio, CodecShort: "TrueHD", Language: "English"},
+				{Kind: bdinfo.StreamKindAudio, CodecShort: "AC3", Language: "German"},
+				{Kind: bdinfo.StreamKindAudio, CodecShort: "AC3", Language: "English"},
+			},
+		}},
+	}
+	for _, name := range []string{"runtime_minutes", "video_codec", "audio_languages"} {
+		value, err := result.Field(name, "")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(value)
+	}
+	// Output:
+	// 121
+	// HEVC
+	// English, German
+}
@@ -0,0 +1,203 @@
+package bdinfo
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// FFprobeOutput describes a playlist with the keys of `ffprobe -show_streams
+// -show_format -of json`, so tools that parse ffprobe output can read it.
+// Values that ffprobe prints as strings (bit_rate, sample_rate, duration,
+// size) are strings here too.
+type FFprobeOutput struct {
+	Streams []FFprobeStream `json:"streams"`
+	Format  FFprobeFormat   `json:"format"`
+}
+
+// FFprobeStream is one entry of FFprobeOutput.Streams. CodecName uses the
+// FFmpeg codec names (h264, hevc, truehd, dts, hdmv_pgs_subtitle, ...); ID is
+// the PID in hex as ffprobe shows MPEG-TS stream IDs. Streams of a type FFmpeg
+// does not decode are listed with CodecType "data" and no codec name.
+type FFprobeStream struct {
+	Index              int               `json:"index"`
+	CodecName          string            `json:"codec_name,omitempty"`
+	CodecLongName      string            `json:"codec_long_name,omitempty"`
+	Profile            string            `json:"profile,omitempty"`
+	CodecType          string            `json:"codec_type"`
+	Width              int               `json:"width,omitempty"`
+	Height             int               `json:"height,omitempty"`
+	DisplayAspectRatio string            `json:"display_aspect_ratio,omitempty"`
+	SampleRate         string            `json:"sample_rate,omitempty"`
+	Channels           int               `json:"channels,omitempty"`
+	ChannelLayout      string            `json:"channel_layout,omitempty"`
+	BitsPerRawSample   string            `json:"bits_per_raw_sample,omitempty"`
+	ID                 string            `json:"id"`
+	RFrameRate         string            `json:"r_frame_rate,omitempty"`
+	AvgFrameRate       string            `json:"avg_frame_rate,omitempty"`
+	BitRate            string            `json:"bit_rate,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+}
+
+// FFprobeFormat is the container part of FFprobeOutput. Filename is the disc
+// path with the bluray: prefix ffprobe uses for discs; the playlist and the
+// disc title are in Tags.
+type FFprobeFormat struct {
+	Filename       string            `json:"filename"`
+	NbStreams      int               `json:"nb_streams"`
+	FormatName     string            `json:"format_name"`
+	FormatLongName string            `json:"format_long_name"`
+	Duration       string            `json:"duration"`
+	Size           string            `json:"size"`
+	BitRate        string            `json:"bit_rate"`
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+type ffprobeCodec struct {
+	name, longName, kind string
+}
+
+var ffprobeCodecs = map[stream.StreamType]ffprobeCodec{
+	stream.StreamTypeMPEG1Video:            {"mpeg1video", "MPEG-1 video", "video"},
+	stream.StreamTypeMPEG2Video:            {"mpeg2video", "MPEG-2 video", "video"},
+	stream.StreamTypeAVCVideo:              {"h264", "H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10", "video"},
+	stream.StreamTypeMVCVideo:              {"h264", "H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10", "video"},
+	stream.StreamTypeHEVCVideo:             {"hevc", "H.265 / HEVC (High Efficiency Video Coding)", "video"},
+	stream.StreamTypeVC1Video:              {"vc1", "SMPTE VC-1", "video"},
+	stream.StreamTypeMPEG1Audio:            {"mp3", "MP3 (MPEG audio layer 3)", "audio"},
+	stream.StreamTypeMPEG2Audio:            {"mp3", "MP3 (MPEG audio layer 3)", "audio"},
+	stream.StreamTypeMPEG2AACAudio:         {"aac", "AAC (Advanced Audio Coding)", "audio"},
+	stream.StreamTypeMPEG4AACAudio:         {"aac", "AAC (Advanced Audio Coding)", "audio"},
+	stream.StreamTypeLPCMAudio:             {"pcm_bluray", "PCM signed 16|20|24-bit big-endian for Blu-ray media", "audio"},
+	stream.StreamTypeAC3Audio:              {"ac3", "ATSC A/52A (AC-3)", "audio"},
+	stream.StreamTypeAC3PlusAudio:          {"eac3", "ATSC A/52B (AC-3, E-AC-3)", "audio"},
+	stream.StreamTypeAC3PlusSecondaryAudio: {"eac3", "ATSC A/52B (AC-3, E-AC-3)", "audio"},
+	stream.StreamTypeAC3TrueHDAudio:        {"truehd", "TrueHD", "audio"},
+	stream.StreamTypeDTSAudio:              {"dts", "DCA (DTS Coherent Acoustics)", "audio"},
+	stream.StreamTypeDTSHDAudio:            {"dts", "DCA (DTS Coherent Acoustics)", "audio"},
+	stream.StreamTypeDTSHDSecondaryAudio:   {"dts", "DCA (DTS Coherent Acoustics)", "audio"},
+	stream.StreamTypeDTSHDMasterAudio:      {"dts", "DCA (DTS Coherent Acoustics)", "audio"},
+	stream.StreamTypePresentationGraphics:  {"hdmv_pgs_subtitle", "HDMV Presentation Graphic Stream subtitles", "subtitle"},
+	stream.StreamTypeSubtitle:              {"hdmv_text_subtitle", "HDMV Text subtitle", "subtitle"},
+}
+
+// ffprobeLayouts names FFmpeg channel layouts by main channels and LFE.
+var ffprobeLayouts = map[[2]int]string{
+	{1, 0}: "mono",
+	{2, 0}: "stereo",
+	{2, 1}: "2.1",
+	{3, 0}: "3.0",
+	{3, 1}: "3.1",
+	{4, 0}: "4.0",
+	{4, 1}: "4.1",
+	{5, 0}: "5.0",
+	{5, 1}: "5.1",
+	{6, 0}: "6.0",
+	{6, 1}: "6.1",
+	{7, 0}: "7.0",
+	{7, 1}: "7.1",
+}
+
+// buildFFprobe describes playlist in ffprobe terms, its streams in report order.
+func buildFFprobe(disc DiscInfo, playlist *bdrom.PlaylistFile) *FFprobeOutput {
+	out := &FFprobeOutput{Streams: []FFprobeStream{}}
+	for _, st := range playlist.SortedStreams {
+		out.Streams = append(out.Streams, ffprobeStream(len(out.Streams), st))
+	}
+	for _, st := range playlist.OtherStreams {
+		out.Streams = append(out.Streams, ffprobeStream(len(out.Streams), st))
+	}
+
+	title := disc.Title
+	if title == "" {
+		title = disc.Label
+	}
+	out.Format = FFprobeFormat{
+		Filename:       "bluray:" + disc.Path,
+		NbStreams:      len(out.Streams),
+		FormatName:     "mpegts",
+		FormatLongName: "MPEG-TS (MPEG-2 Transport Stream)",
+		Duration:       fmt.Sprintf("%.6f", playlist.TotalLength()),
+		Size:           strconv.FormatUint(playlist.TotalSize(), 10),
+		BitRate:        strconv.FormatUint(playlist.TotalBitRate(), 10),
+		Tags:           map[string]string{"title": title, "playlist": playlist.Name},
+	}
+	return out
+}
+
+func ffprobeStream(index int, st stream.Info) FFprobeStream {
+	base := st.Base()
+	out := FFprobeStream{Index: index, CodecType: "data", ID: fmt.Sprintf("0x%x", base.PID)}
+	if codec, ok := ffprobeCodecs[base.StreamType]; ok {
+		out.CodecName = codec.name
+		out.CodecLongName = codec.longName
+		out.CodecType = codec.kind
+	}
+	if base.BitRate > 0 {
+		out.BitRate = strconv.FormatInt(base.BitRate, 10)
+	}
+	if code := base.LanguageCode(); code != "" {
+		out.Tags = map[string]string{"language": code}
+	}
+
+	switch s := st.(type) {
+	case *stream.VideoStream:
+		out.Width = s.Width
+		out.Height = s.Height
+		switch s.AspectRatio {
+		case stream.Aspect43:
+			out.DisplayAspectRatio = "4:3"
+		case stream.Aspect169:
+			out.DisplayAspectRatio = "16:9"
+		}
+		if s.FrameRateDen > 0 {
+			out.RFrameRate = fmt.Sprintf("%d/%d", s.FrameRateEnum, s.FrameRateDen)
+			out.AvgFrameRate = out.RFrameRate
+		}
+	case *stream.AudioStream:
+		out.Profile = ffprobeAudioProfile(s)
+		if s.SampleRate > 0 {
+			out.SampleRate = strconv.Itoa(s.SampleRate)
+		}
+		if s.ChannelCount > 0 {
+			out.Channels = s.ChannelCount + s.LFE
+			out.ChannelLayout = ffprobeLayouts[[2]int{s.ChannelCount, s.LFE}]
+		}
+		if s.BitDepth > 0 {
+			out.BitsPerRawSample = strconv.Itoa(s.BitDepth)
+		}
+	case *stream.GraphicsStream:
+		out.Width = s.Width
+		out.Height = s.Height
+	}
+	return out
+}
+
+// ffprobeAudioProfile returns the profile FFmpeg reports for DTS variants and
+// object audio.
+func ffprobeAudioProfile(a *stream.AudioStream) string {
+	switch a.StreamType {
+	case stream.StreamTypeDTSAudio:
+		return "DTS"
+	case stream.StreamTypeDTSHDAudio:
+		return "DTS-HD HRA"
+	case stream.StreamTypeDTSHDSecondaryAudio:
+		return "DTS Express"
+	case stream.StreamTypeDTSHDMasterAudio:
+		if a.HasExtensions {
+			return "DTS-HD MA + DTS:X"
+		}
+		return "DTS-HD MA"
+	case stream.StreamTypeAC3TrueHDAudio:
+		if a.HasExtensions {
+			return "Dolby TrueHD + Dolby Atmos"
+		}
+	case stream.StreamTypeAC3PlusAudio:
+		if a.HasExtensions {
+			return "Dolby Digital Plus + Dolby Atmos"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package bdinfo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestBuildFFprobe(t *testing.T) {
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeAVCVideo, BitRate: 30_000_000}}
+	video.SetVideoFormat(stream.VideoFormat1080p)
+	video.SetFrameRate(stream.FrameRate23976)
+	video.Width = 1920
+	video.AspectRatio = stream.Aspect169
+
+	audio := &stream.AudioStream{
+		Stream:        stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeDTSHDMasterAudio, BitRate: 5_000_000},
+		SampleRate:    48000,
+		ChannelCount:  7,
+		LFE:           1,
+		BitDepth:      24,
+		HasExtensions: true,
+	}
+	audio.SetLanguageCode("eng")
+
+	subtitle := &stream.GraphicsStream{Stream: stream.Stream{PID: 0x1200, StreamType: stream.StreamTypePresentationGraphics, BitRate: 40_000}, Width: 1920, Height: 1080}
+	subtitle.SetLanguageCode("deu")
+
+	other := &stream.Stream{PID: 0x1400, StreamType: 0x05}
+
+	playlist := &bdrom.PlaylistFile{
+		Name:          "00800.MPLS",
+		StreamClips:   []*bdrom.StreamClip{{Length: 60, PacketCount: 1_000_000}},
+		SortedStreams: []stream.Info{video, audio, subtitle},
+		OtherStreams:  []stream.Info{other},
+	}
+	out := buildFFprobe(DiscInfo{Path: "/media/MOVIE", Label: "MOVIE"}, playlist)
+
+	want := []FFprobeStream{
+		{
+			Index:              0,
+			CodecName:          "h264",
+			CodecLongName:      "H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10",
+			CodecType:          "video",
+			Width:              1920,
+			Height:             1080,
+			DisplayAspectRatio: "16:9",
+			ID:                 "0x1011",
+			RFrameRate:         "24000/1001",
+			AvgFrameRate:       "24000/1001",
+			BitRate:            "30000000",
+		},
+		{
+			Index:            1,
+			CodecName:        "dts",
+			CodecLongName:    "DCA (DTS Coherent Acoustics)",
+			Profile:          "DTS-HD MA + DTS:X",
+			CodecType:        "audio",
+			SampleRate:       "48000",
+			Channels:         8,
+			ChannelLayout:    "7.1",
+			BitsPerRawSample: "24",
+			ID:               "0x1100",
+			BitRate:          "5000000",
+			Tags:             map[string]string{"language": "eng"},
+		},
+		{
+			Index:         2,
+			CodecName:     "hdmv_pgs_subtitle",
+			CodecLongName: "HDMV Presentation Graphic Stream subtitles",
+			CodecType:     "subtitle",
+			Width:         1920,
+			Height:        1080,
+			ID:            "0x1200",
+			BitRate:       "40000",
+			Tags:          map[string]string{"language": "deu"},
+		},
+		{Index: 3, CodecType: "data", ID: "0x1400"},
+	}
+	if !reflect.DeepEqual(out.Streams, want) {
+		t.Fatalf("streams got=%+v\nwant=%+v", out.Streams, want)
+	}
+
+	wantFormat := FFprobeFormat{
+		Filename:       "bluray:/media/MOVIE",
+		NbStreams:      4,
+		FormatName:     "mpegts",
+		FormatLongName: "MPEG-TS (MPEG-2 Transport Stream)",
+		Duration:       "60.000000",
+		Size:           "192000000",
+		BitRate:        "25600000",
+		Tags:           map[string]string{"title": "MOVIE", "playlist": "00800.MPLS"},
+	}
+	if !reflect.DeepEqual(out.Format, wantFormat) {
+		t.Fatalf("format got=%+v\nwant=%+v", out.Format, wantFormat)
+	}
+}
@@ -0,0 +1,164 @@
+package bdinfo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RuntimeMinutes returns the playlist length rounded to whole minutes, as
+// tracker upload forms ask for it.
+func (p PlaylistInfo) RuntimeMinutes() int {
+	return int(math.Round(p.LengthSeconds / 60))
+}
+
+// fields maps the names Result.Field accepts to their value for a playlist.
+// Stream fields describe the first stream of the kind that is not hidden.
+var fields = map[string]func(r Result, p PlaylistInfo) string{
+	"disc_label":         func(r Result, _ PlaylistInfo) string { return r.Disc.Label },
+	"disc_title":         func(r Result, _ PlaylistInfo) string { return r.Disc.Title },
+	"disc_size_bytes":    func(r Result, _ PlaylistInfo) string { return strconv.FormatUint(r.Disc.SizeBytes, 10) },
+	"playlist":           func(_ Result, p PlaylistInfo) string { return p.Name },
+	"runtime":            func(_ Result, p PlaylistInfo) string { return p.Length },
+	"runtime_seconds":    func(_ Result, p PlaylistInfo) string { return strconv.FormatFloat(p.LengthSeconds, 'f', 3, 64) },
+	"runtime_minutes":    func(_ Result, p PlaylistInfo) string { return strconv.Itoa(p.RuntimeMinutes()) },
+	"size_bytes":         func(_ Result, p PlaylistInfo) string { return strconv.FormatUint(p.SizeBytes, 10) },
+	"total_bitrate_kbps": func(_ Result, p PlaylistInfo) string { return strconv.FormatUint((p.TotalBitrateBps+500)/1000, 10) },
+	"chapters":           func(_ Result, p PlaylistInfo) string { return strconv.Itoa(len(p.Chapters)) },
+	"video_codec": func(_ Result, p PlaylistInfo) string {
+		return firstStreamField(p, StreamKindVideo, func(st StreamInfo) string { return st.CodecShort })
+	},
+	"video_resolution": func(_ Result, p PlaylistInfo) string {
+		return firstStreamField(p, StreamKindVideo, func(st StreamInfo) string {
+			if st.Video == nil || st.Video.Height == 0 {
+				return ""
+			}
+			return fmt.Sprintf("%dx%d", st.Video.Width, st.Video.Height)
+		})
+	},
+	"video_frame_rate": func(_ Result, p PlaylistInfo) string {
+		return firstStreamField(p, StreamKindVideo, func(st StreamInfo) string {
+			if st.Video == nil || st.Video.FrameRate == 0 {
+				return ""
+			}
+			return strconv.FormatFloat(st.Video.FrameRate, 'f', -1, 64)
+		})
+	},
+	"video_hdr": func(_ Result, p PlaylistInfo) string {
+		return firstStreamField(p, StreamKindVideo, func(st StreamInfo) string {
+			if st.Video == nil {
+				return ""
+			}
+			return st.Video.HDR
+		})
+	},
+	"audio_codec": func(_ Result, p PlaylistInfo) string {
+		return firstStreamField(p, StreamKindAudio, func(st StreamInfo) string { return st.CodecShort })
+	},
+	"audio_channels": func(_ Result, p PlaylistInfo) string {
+		return firstStreamField(p, StreamKindAudio, func(st StreamInfo) string {
+			if st.Audio == nil {
+				return ""
+			}
+			return st.Audio.Channels
+		})
+	},
+	"audio_language": func(_ Result, p PlaylistInfo) string {
+		return firstStreamField(p, StreamKindAudio, func(st StreamInfo) string { return st.Language })
+	},
+	"audio_languages":    func(_ Result, p PlaylistInfo) string { return streamLanguages(p, StreamKindAudio) },
+	"subtitle_languages": func(_ Result, p PlaylistInfo) string { return streamLanguages(p, StreamKindSubtitle) },
+}
+
+// FieldNames returns the names Result.Field accepts, sorted.
+func FieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Field returns one value of the scan for shell scripting, such as
+// "runtime_minutes" or "video_codec" (see FieldNames), for the playlist
+// called playlist or the main playlist when it is empty. Values that do not
+// apply, such as the HDR format of SDR video, are empty; lists are joined
+// with ", ".
+func (r Result) Field(name, playlist string) (string, error) {
+	value, ok := fields[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unknown field %q (supported: %s)", name, strings.Join(FieldNames(), ", "))
+	}
+	p, ok := r.Playlist(playlist)
+	if !ok {
+		if playlist == "" {
+			return "", fmt.Errorf("field %s: no main playlist", name)
+		}
+		return "", fmt.Errorf("field %s: playlist %s not found", name, playlist)
+	}
+	return value(r, p), nil
+}
+
+// firstStreamField returns value of the playlist's first visible stream of
+// kind, or "" when it has none.
+func firstStreamField(p PlaylistInfo, kind StreamKind, value func(StreamInfo) string) string {
+	for _, st := range p.Streams {
+		if st.Kind == kind && !st.Hidden {
+			return value(st)
+		}
+	}
+	return ""
+}
+
+// streamLanguages lists the languages of the playlist's visible streams of
+// kind once each, in stream order.
+func streamLanguages(p PlaylistInfo, kind StreamKind) string {
+	var languages []string
+	for _, st := range p.Streams {
+		if st.Kind != kind || st.Hidden || st.Language == "" || slices.Contains(languages, st.Language) {
+			continue
+		}
+		languages = append(languages, st.Language)
+	}
+	return strings.Join(languages, ", ")
+}
+
+// PlaylistIndex is a small list of a disc's playlists for playlist pickers,
+// such as GUIs wrapping the CLI, that do not want to parse the report.
+type PlaylistIndex struct {
+	Label        string               `json:"label"`
+	MainPlaylist string               `json:"mainPlaylist,omitempty"`
+	Playlists    []PlaylistIndexEntry `json:"playlists"`
+}
+
+// PlaylistIndexEntry is one playlist of a PlaylistIndex. Main is set for the
+// playlist --main would pick.
+type PlaylistIndexEntry struct {
+	Name          string  `json:"name"`
+	Length        string  `json:"length"`
+	LengthSeconds float64 `json:"lengthSeconds"`
+	SizeBytes     uint64  `json:"sizeBytes"`
+	Main          bool    `json:"main"`
+}
+
+// PlaylistIndex returns the playlists of r in report order.
+func (r Result) PlaylistIndex() PlaylistIndex {
+	index := PlaylistIndex{
+		Label:        r.Disc.Label,
+		MainPlaylist: r.MainPlaylist,
+		Playlists:    make([]PlaylistIndexEntry, 0, len(r.Playlists)),
+	}
+	for _, p := range r.Playlists {
+		index.Playlists = append(index.Playlists, PlaylistIndexEntry{
+			Name:          p.Name,
+			Length:        p.Length,
+			LengthSeconds: p.LengthSeconds,
+			SizeBytes:     p.SizeBytes,
+			Main:          strings.EqualFold(p.Name, r.MainPlaylist),
+		})
+	}
+	return index
+}
@@ -0,0 +1,119 @@
+package bdinfo
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/autobrr/go-bdinfo/internal/util"
+)
+
+// MultiDiscResult is a run over a folder of several discs, in scan order.
+type MultiDiscResult struct {
+	Discs []DiscResult `json:"discs"`
+}
+
+// DiscResult is one disc of a multi-disc run. Error is set when the disc could
+// not be scanned; Result is then empty.
+type DiscResult struct {
+	Path   string `json:"path"`
+	Result Result `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DiscSummary is one row of the multi-disc index: the disc's main playlist with
+// its length, size and the codecs of its first video and audio streams.
+// Playlist fields are empty when the disc has no main playlist.
+type DiscSummary struct {
+	Path          string  `json:"path"`
+	Label         string  `json:"label"`
+	MainPlaylist  string  `json:"mainPlaylist,omitempty"`
+	Length        string  `json:"length,omitempty"`
+	LengthSeconds float64 `json:"lengthSeconds,omitempty"`
+	SizeBytes     uint64  `json:"sizeBytes,omitempty"`
+	Video         string  `json:"video,omitempty"`
+	Audio         string  `json:"audio,omitempty"`
+	Error         string  `json:"error,omitempty"`
+}
+
+// Summary returns the index row for the disc.
+func (d DiscResult) Summary() DiscSummary {
+	summary := DiscSummary{Path: d.Path, Label: d.Result.Disc.Label, Error: d.Error}
+	playlist, ok := d.Result.Playlist(d.Result.MainPlaylist)
+	if !ok {
+		return summary
+	}
+	summary.MainPlaylist = playlist.Name
+	summary.Length = playlist.Length
+	summary.LengthSeconds = playlist.LengthSeconds
+	summary.SizeBytes = playlist.SizeBytes
+	for _, st := range playlist.Streams {
+		if st.Hidden {
+			continue
+		}
+		switch {
+		case st.Kind == StreamKindVideo && summary.Video == "":
+			summary.Video = st.CodecShort
+		case st.Kind == StreamKindAudio && summary.Audio == "":
+			summary.Audio = st.CodecShort
+			if st.Audio != nil && st.Audio.Channels != "" {
+				summary.Audio += " " + st.Audio.Channels
+			}
+		}
+	}
+	return summary
+}
+
+// Summaries returns the index rows of all discs in scan order.
+func (m MultiDiscResult) Summaries() []DiscSummary {
+	out := make([]DiscSummary, 0, len(m.Discs))
+	for _, disc := range m.Discs {
+		out = append(out, disc.Summary())
+	}
+	return out
+}
+
+// Report renders the combined text report: an index table of the discs
+// followed by each disc's own report under a numbered banner. A disc that
+// failed to scan keeps its section with the error in place of a report.
+func (m MultiDiscResult) Report() string {
+	var b strings.Builder
+	b.WriteString("DISC INDEX:\n\n")
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tDisc Label\tMain Playlist\tLength\tSize\tVideo\tAudio")
+	fmt.Fprintln(tw, "-\t----------\t-------------\t------\t----\t-----\t-----")
+	for i, summary := range m.Summaries() {
+		label, playlist, length, size, video, audio := summary.Label, "-", "-", "-", "-", "-"
+		if label == "" {
+			label = summary.Path
+		}
+		if summary.MainPlaylist != "" {
+			playlist, length = summary.MainPlaylist, summary.Length
+			size = util.FormatFileSize(float64(summary.SizeBytes), true)
+		}
+		if summary.Video != "" {
+			video = summary.Video
+		}
+		if summary.Audio != "" {
+			audio = summary.Audio
+		}
+		if summary.Error != "" {
+			playlist = "failed"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", i+1, label, playlist, length, size, video, audio)
+	}
+	tw.Flush()
+
+	for i, disc := range m.Discs {
+		banner := fmt.Sprintf("DISC %d OF %d: %s", i+1, len(m.Discs), disc.Path)
+		rule := strings.Repeat("*", utf8.RuneCountInString(banner))
+		fmt.Fprintf(&b, "\n\n\n%s\n%s\n%s\n\n\n", rule, banner, rule)
+		if disc.Error != "" {
+			fmt.Fprintf(&b, "Error: %s\n", disc.Error)
+			continue
+		}
+		b.WriteString(disc.Result.Report)
+	}
+	return b.String()
+}
@@ -0,0 +1,275 @@
+package bdinfo
+
+import (
+	"math"
+	"slices"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/report"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// StreamKind classifies a playlist stream.
+type StreamKind string
+
+const (
+	StreamKindVideo    StreamKind = "video"
+	StreamKindAudio    StreamKind = "audio"
+	StreamKindSubtitle StreamKind = "subtitle"
+	StreamKindText     StreamKind = "text"
+	// StreamKindOther is a clip stream of a coding type BDInfo does not know;
+	// its Codec is "Other (0xNN)" with the type code.
+	StreamKindOther StreamKind = "other"
+)
+
+// StreamInfo describes one stream of a playlist, in report order. Exactly one
+// of Video, Audio and Subtitle is set for those kinds; text and other streams
+// carry only the common fields. Codec is the full name used in the report
+// tables and Description the report's description column; HiddenReason
+// explains a hidden stream (one the report prefixes with "*"). ID identifies
+// the stream by playlist, kind, PID and angle (e.g. "00800.MPLS/audio/4352");
+// it is the same across repeated scans and matches the ID of the XML and CSV
+// reports.
+type StreamInfo struct {
+	ID           string           `json:"id"`
+	Kind         StreamKind       `json:"kind"`
+	PID          uint16           `json:"pid"`
+	Codec        string           `json:"codec"`
+	CodecShort   string           `json:"codecShort"`
+	Language     string           `json:"language,omitempty"`
+	LanguageCode string           `json:"languageCode,omitempty"`
+	BitrateBps   uint64           `json:"bitrateBps"`
+	Hidden       bool             `json:"hidden"`
+	HiddenReason string           `json:"hiddenReason,omitempty"`
+	Angle        int              `json:"angle,omitempty"`
+	Description  string           `json:"description"`
+	Video        *VideoDetails    `json:"video,omitempty"`
+	Audio        *AudioDetails    `json:"audio,omitempty"`
+	Subtitle     *SubtitleDetails `json:"subtitle,omitempty"`
+	// Segments is set for audio streams whose codec or language changes
+	// between play items and lists each item's attributes; the other fields
+	// describe the reference clip.
+	Segments []SegmentInfo `json:"segments,omitempty"`
+}
+
+// SegmentInfo is a stream as one play item of the playlist lists it.
+type SegmentInfo struct {
+	File         string `json:"file"`
+	Codec        string `json:"codec"`
+	Language     string `json:"language,omitempty"`
+	LanguageCode string `json:"languageCode,omitempty"`
+}
+
+// VideoDetails holds video stream properties. FrameRate is frames per second
+// (e.g. 23.976); HDR is "HDR10", "HDR10+" or "Dolby Vision" when detected.
+type VideoDetails struct {
+	Width       int     `json:"width,omitempty"`
+	Height      int     `json:"height,omitempty"`
+	Interlaced  bool    `json:"interlaced"`
+	FrameRate   float64 `json:"frameRate,omitempty"`
+	AspectRatio string  `json:"aspectRatio,omitempty"`
+	Profile     string  `json:"profile,omitempty"`
+	HDR         string  `json:"hdr,omitempty"`
+	// Extended lists the HEVC or AVC format details shown in the description
+	// (bit depth, colour primaries, HDR metadata, frame packing, ...).
+	Extended []string `json:"extended,omitempty"`
+	// DolbyVision is set when the stream carries Dolby Vision RPUs.
+	DolbyVision *DolbyVisionDetails `json:"dolbyVision,omitempty"`
+}
+
+// DolbyVisionDetails describes Dolby Vision metadata. Profile is e.g. "7.6" or
+// "8.1" (empty when the RPU does not tell) and Layers "BL+EL+RPU" or "BL+RPU".
+// ELType is "FEL" or "MEL" for a dual-layer stream whose RPU tells which.
+type DolbyVisionDetails struct {
+	Profile string `json:"profile,omitempty"`
+	Layers  string `json:"layers"`
+	ELType  string `json:"elType,omitempty"`
+}
+
+// AudioDetails holds audio stream properties. Channels is the report's layout
+// ("5.1", "7.1", "2.0-EX", ...). Core describes an embedded core stream such as
+// the AC3 inside TrueHD or the DTS core of DTS-HD; CoreBitrateBps is its
+// nominal bitrate and ExtensionBitrateBps the rest of the stream's bitrate.
+type AudioDetails struct {
+	Channels     string        `json:"channels,omitempty"`
+	ChannelCount int           `json:"channelCount,omitempty"`
+	LFE          int           `json:"lfe,omitempty"`
+	SampleRateHz int           `json:"sampleRateHz,omitempty"`
+	BitDepth     int           `json:"bitDepth,omitempty"`
+	DialNorm     int           `json:"dialNorm,omitempty"`
+	Atmos        bool          `json:"atmos"`
+	DTSX         bool          `json:"dtsX"`
+	Core         *AudioDetails `json:"core,omitempty"`
+	CoreCodec    string        `json:"coreCodec,omitempty"`
+
+	// SpeakerLayout and Speakers give the exact LPCM layout, such as "3/4.1"
+	// and L, C, R, Ls, Rs, Lrs, Rrs, LFE; only with extended stream
+	// diagnostics.
+	SpeakerLayout string   `json:"speakerLayout,omitempty"`
+	Speakers      []string `json:"speakers,omitempty"`
+	// AtmosObjects counts the dynamic objects of a TrueHD Atmos stream and
+	// IMAXEnhanced flags IMAX Enhanced DTS:X; only with extended stream
+	// diagnostics.
+	AtmosObjects int  `json:"atmosObjects,omitempty"`
+	IMAXEnhanced bool `json:"imaxEnhanced,omitempty"`
+
+	CoreBitrateBps      uint64 `json:"coreBitrateBps,omitempty"`
+	ExtensionBitrateBps uint64 `json:"extensionBitrateBps,omitempty"`
+}
+
+// SubtitleDetails holds presentation graphics properties.
+type SubtitleDetails struct {
+	Width          int `json:"width,omitempty"`
+	Height         int `json:"height,omitempty"`
+	Captions       int `json:"captions"`
+	ForcedCaptions int `json:"forcedCaptions"`
+	// ForcedOnly is set for tracks whose captions are all forced; captions
+	// are only counted with Settings.SubtitleSummary.
+	ForcedOnly bool `json:"forcedOnly,omitempty"`
+}
+
+// hdrFormats are the HDR labels the HEVC analyzer adds to the extended format info.
+var hdrFormats = []string{"HDR10", "HDR10+", "Dolby Vision"}
+
+func buildStreamInfo(playlist *bdrom.PlaylistFile) []StreamInfo {
+	out := make([]StreamInfo, 0, len(playlist.SortedStreams))
+	for _, st := range playlist.SortedStreams {
+		base := st.Base()
+		info := StreamInfo{
+			ID:           report.StreamID(playlist.Name, st),
+			PID:          base.PID,
+			Codec:        stream.CodecNameForInfo(st),
+			CodecShort:   stream.CodecShortNameForInfo(st),
+			Language:     base.LanguageName,
+			LanguageCode: base.LanguageCode(),
+			BitrateBps:   uint64(max(base.BitRate, 0)),
+			Hidden:       base.IsHidden,
+			HiddenReason: base.HiddenDescription(),
+			Angle:        base.AngleIndex,
+			Description:  st.Description(),
+		}
+		switch s := st.(type) {
+		case *stream.VideoStream:
+			info.Kind = StreamKindVideo
+			info.Video = videoDetails(s)
+		case *stream.AudioStream:
+			info.Kind = StreamKindAudio
+			info.Audio = audioDetails(s)
+			info.Segments = segmentInfo(playlist, s.PID)
+		case *stream.GraphicsStream:
+			info.Kind = StreamKindSubtitle
+			info.Subtitle = &SubtitleDetails{Width: s.Width, Height: s.Height, Captions: s.Captions, ForcedCaptions: s.ForcedCaptions, ForcedOnly: s.ForcedOnly()}
+		case *stream.TextStream:
+			info.Kind = StreamKindText
+		default:
+			continue
+		}
+		out = append(out, info)
+	}
+	for _, st := range playlist.OtherStreams {
+		base := st.Base()
+		out = append(out, StreamInfo{
+			ID:           report.StreamID(playlist.Name, st),
+			Kind:         StreamKindOther,
+			PID:          base.PID,
+			Codec:        stream.OtherCodecName(base.StreamType),
+			CodecShort:   stream.OtherCodecName(base.StreamType),
+			BitrateBps:   uint64(max(base.BitRate, 0)),
+			Hidden:       base.IsHidden,
+			HiddenReason: base.HiddenDescription(),
+		})
+	}
+	return out
+}
+
+func segmentInfo(playlist *bdrom.PlaylistFile, pid uint16) []SegmentInfo {
+	for _, change := range playlist.AudioSegments {
+		if change.PID != pid {
+			continue
+		}
+		segments := make([]SegmentInfo, 0, len(change.Segments))
+		for _, seg := range change.Segments {
+			base := seg.Stream.Base()
+			segments = append(segments, SegmentInfo{
+				File:         seg.Clip,
+				Codec:        stream.CodecNameForInfo(seg.Stream),
+				Language:     base.LanguageName,
+				LanguageCode: base.LanguageCode(),
+			})
+		}
+		return segments
+	}
+	return nil
+}
+
+func videoDetails(v *stream.VideoStream) *VideoDetails {
+	details := &VideoDetails{
+		Width:       v.Width,
+		Height:      v.Height,
+		Interlaced:  v.IsInterlaced,
+		AspectRatio: aspectRatioName(v.AspectRatio),
+		Profile:     v.EncodingProfile,
+	}
+	if v.FrameRateDen > 0 {
+		details.FrameRate = math.Round(float64(v.FrameRateEnum)/float64(v.FrameRateDen)*1000) / 1000
+	}
+	if info := v.ExtendedFormatInfo(); len(info) > 0 {
+		details.Extended = slices.Clone(info)
+		for _, item := range info {
+			if slices.Contains(hdrFormats, item) {
+				details.HDR = item
+			}
+		}
+	}
+	if ext, ok := v.ExtendedData.(*stream.HEVCExtendedData); ok && ext != nil && ext.DolbyVision != nil {
+		details.HDR = "Dolby Vision"
+		details.DolbyVision = &DolbyVisionDetails{Profile: ext.DolbyVision.ProfileName(), Layers: ext.DolbyVision.Layers(), ELType: ext.DolbyVision.EnhancementType}
+	}
+	return details
+}
+
+func audioDetails(a *stream.AudioStream) *AudioDetails {
+	details := &AudioDetails{
+		Channels:     a.ChannelDescription(),
+		ChannelCount: a.ChannelCount,
+		LFE:          a.LFE,
+		SampleRateHz: a.SampleRate,
+		BitDepth:     a.BitDepth,
+		DialNorm:     a.DialNorm,
+	}
+	if a.HasExtensions {
+		switch a.StreamType {
+		case stream.StreamTypeAC3PlusAudio, stream.StreamTypeAC3TrueHDAudio:
+			details.Atmos = true
+		case stream.StreamTypeDTSHDAudio, stream.StreamTypeDTSHDMasterAudio:
+			details.DTSX = true
+		}
+	}
+	if ext, ok := a.ExtendedData.(*stream.LPCMExtendedData); ok && ext != nil {
+		details.SpeakerLayout, details.Speakers = ext.Layout(), ext.Speakers()
+	}
+	if ext, ok := a.ExtendedData.(*stream.ObjectAudioExtendedData); ok && ext != nil {
+		details.AtmosObjects, details.IMAXEnhanced = ext.Objects, ext.IMAXEnhanced
+	}
+	if a.CoreStream != nil {
+		details.Core = audioDetails(a.CoreStream)
+		details.CoreCodec = stream.CodecNameForInfo(a.CoreStream)
+		if core, extension, ok := a.CoreBitRateSplit(a.BitRate); ok {
+			details.CoreBitrateBps, details.ExtensionBitrateBps = uint64(core), uint64(extension)
+		}
+	}
+	return details
+}
+
+func aspectRatioName(ratio stream.AspectRatio) string {
+	switch ratio {
+	case stream.Aspect43:
+		return "4:3"
+	case stream.Aspect169:
+		return "16:9"
+	case stream.Aspect221:
+		return "2.21:1"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,79 @@
+package bdinfo
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func TestBuildStreamInfo(t *testing.T) {
+	video := &stream.VideoStream{Stream: stream.Stream{PID: 0x1011, StreamType: stream.StreamTypeHEVCVideo, BitRate: 60_000_000}}
+	video.SetVideoFormat(stream.VideoFormat2160p)
+	video.SetFrameRate(stream.FrameRate23976)
+	video.Width = 3840
+	video.AspectRatio = stream.Aspect169
+	video.EncodingProfile = "Main 10 @ Level 5.1 @ High"
+	video.ExtendedData = &stream.HEVCExtendedData{
+		ExtendedFormatInfo: []string{"10 bits", "HDR10", "BT.2020"},
+		DolbyVision:        &stream.DolbyVision{Profile: 8, Compatibility: 1},
+	}
+
+	core := &stream.AudioStream{Stream: stream.Stream{StreamType: stream.StreamTypeAC3Audio, BitRate: 640_000}, SampleRate: 48000, ChannelCount: 5, LFE: 1}
+	audio := &stream.AudioStream{
+		Stream:        stream.Stream{PID: 0x1100, StreamType: stream.StreamTypeAC3TrueHDAudio, BitRate: 4_000_000},
+		SampleRate:    48000,
+		ChannelCount:  7,
+		LFE:           1,
+		BitDepth:      24,
+		HasExtensions: true,
+		CoreStream:    core,
+	}
+	audio.SetLanguageCode("eng")
+
+	subtitle := &stream.GraphicsStream{Stream: stream.Stream{PID: 0x1200, StreamType: stream.StreamTypePresentationGraphics}, Width: 1920, Height: 1080, Captions: 3, ForcedCaptions: 3}
+	subtitle.SetLanguageCode("fra")
+
+	playlist := &bdrom.PlaylistFile{Name: "00800.MPLS", SortedStreams: []stream.Info{video, audio, subtitle}}
+	streams := buildStreamInfo(playlist)
+	if len(streams) != 3 {
+		t.Fatalf("streams got=%d want=3", len(streams))
+	}
+
+	v := streams[0]
+	if v.Kind != StreamKindVideo || v.ID != "00800.MPLS/video/4113" || v.BitrateBps != 60_000_000 || v.Video == nil {
+		t.Fatalf("video stream got=%+v", v)
+	}
+	if got := *v.Video; got.Width != 3840 || got.Height != 2160 || got.Interlaced || got.FrameRate != 23.976 || got.AspectRatio != "16:9" || got.Profile != "Main 10 @ Level 5.1 @ High" {
+		t.Fatalf("video details got=%+v", got)
+	}
+	if v.Video.HDR != "Dolby Vision" || !slices.Equal(v.Video.Extended, []string{"10 bits", "HDR10", "BT.2020"}) {
+		t.Fatalf("video HDR got=%q extended=%q", v.Video.HDR, v.Video.Extended)
+	}
+	if dv := v.Video.DolbyVision; dv == nil || dv.Profile != "8.1" || dv.Layers != "BL+RPU" || dv.ELType != "" {
+		t.Fatalf("Dolby Vision got=%+v", dv)
+	}
+
+	a := streams[1]
+	if a.Kind != StreamKindAudio || a.Language != "English" || a.LanguageCode != "eng" || a.Audio == nil {
+		t.Fatalf("audio stream got=%+v", a)
+	}
+	if got := *a.Audio; got.Channels != "7.1" || got.ChannelCount != 7 || got.LFE != 1 || got.SampleRateHz != 48000 || got.BitDepth != 24 || !got.Atmos || got.DTSX {
+		t.Fatalf("audio details got=%+v", got)
+	}
+	if a.Audio.Core == nil || a.Audio.Core.Channels != "5.1" || a.Audio.CoreCodec != "Dolby Digital Audio" {
+		t.Fatalf("audio core got=%+v codec=%q", a.Audio.Core, a.Audio.CoreCodec)
+	}
+	if a.Audio.CoreBitrateBps != 640_000 || a.Audio.ExtensionBitrateBps != 3_360_000 {
+		t.Fatalf("audio bitrate split got=%d+%d", a.Audio.CoreBitrateBps, a.Audio.ExtensionBitrateBps)
+	}
+
+	s := streams[2]
+	if s.Kind != StreamKindSubtitle || s.Language != "French" || s.Subtitle == nil {
+		t.Fatalf("subtitle stream got=%+v", s)
+	}
+	if got := *s.Subtitle; got.Width != 1920 || got.Height != 1080 || got.Captions != 3 || got.ForcedCaptions != 3 || !got.ForcedOnly {
+		t.Fatalf("subtitle details got=%+v", got)
+	}
+}
@@ -0,0 +1,270 @@
+package bdinfo
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+)
+
+// srtDefaultDuration is how long WriteSRT shows a caption whose end the scan
+// did not see.
+const srtDefaultDuration = 4.0
+
+// SubtitleImage is one subtitle bitmap decoded from a PGS stream, handed to
+// Options.SubtitleOCR. StartSeconds and EndSeconds are on the stream file's
+// clock (ClipInfo.InSeconds/OutSeconds); EndSeconds is 0 when the scan did not
+// see the caption end.
+type SubtitleImage struct {
+	File         string
+	PID          uint16
+	Language     string
+	Forced       bool
+	StartSeconds float64
+	EndSeconds   float64
+	Image        image.Image
+}
+
+// Caption is the text Options.SubtitleOCR returned for one SubtitleImage.
+type Caption struct {
+	File         string  `json:"file"`
+	PID          uint16  `json:"pid"`
+	Language     string  `json:"language,omitempty"`
+	Forced       bool    `json:"forced,omitempty"`
+	StartSeconds float64 `json:"startSeconds"`
+	EndSeconds   float64 `json:"endSeconds,omitempty"`
+	Text         string  `json:"text"`
+}
+
+// subtitleOCR runs the OCR callback on the captions of the scan workers and
+// keeps the first error; later images are dropped once one failed.
+type subtitleOCR struct {
+	ocr      func(SubtitleImage) (string, error)
+	mu       sync.Mutex
+	captions []Caption
+	err      error
+}
+
+func (o *subtitleOCR) add(caption bdrom.PGSCaption) {
+	o.mu.Lock()
+	failed := o.err != nil
+	o.mu.Unlock()
+	if failed {
+		return
+	}
+	image := SubtitleImage{
+		File:         caption.File,
+		PID:          caption.PID,
+		Language:     caption.Language,
+		Forced:       caption.Forced,
+		StartSeconds: caption.Start,
+		EndSeconds:   caption.End,
+		Image:        caption.Image,
+	}
+	text, err := o.ocr(image)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err != nil {
+		if o.err == nil {
+			o.err = fmt.Errorf("%s PID %d at %.3fs: %w", caption.File, caption.PID, caption.Start, err)
+		}
+		return
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	o.captions = append(o.captions, Caption{
+		File:         image.File,
+		PID:          image.PID,
+		Language:     image.Language,
+		Forced:       image.Forced,
+		StartSeconds: image.StartSeconds,
+		EndSeconds:   image.EndSeconds,
+		Text:         text,
+	})
+}
+
+// result returns the captions ordered by file, PID and start time.
+func (o *subtitleOCR) result() ([]Caption, error) {
+	if o == nil {
+		return nil, nil
+	}
+	if o.err != nil {
+		return nil, o.err
+	}
+	slices.SortStableFunc(o.captions, func(a, b Caption) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.PID, b.PID), cmp.Compare(a.StartSeconds, b.StartSeconds))
+	})
+	return o.captions, nil
+}
+
+// SubtitleTiming is when one PGS caption was shown. In Result.SubtitleTimings
+// the times are on the stream file's clock, like SubtitleImage, and
+// EndSeconds is 0 when the scan did not see the caption end.
+type SubtitleTiming struct {
+	File         string  `json:"file"`
+	PID          uint16  `json:"pid"`
+	Language     string  `json:"language,omitempty"`
+	Forced       bool    `json:"forced,omitempty"`
+	StartSeconds float64 `json:"startSeconds"`
+	EndSeconds   float64 `json:"endSeconds"`
+}
+
+// subtitleTimings collects the caption timings of the scan workers.
+type subtitleTimings struct {
+	mu      sync.Mutex
+	timings []SubtitleTiming
+}
+
+func (t *subtitleTimings) add(caption bdrom.PGSCaption) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timings = append(t.timings, SubtitleTiming{
+		File:         caption.File,
+		PID:          caption.PID,
+		Language:     caption.Language,
+		Forced:       caption.Forced,
+		StartSeconds: caption.Start,
+		EndSeconds:   caption.End,
+	})
+}
+
+// result returns the timings ordered by file, PID and start time.
+func (t *subtitleTimings) result() []SubtitleTiming {
+	if t == nil {
+		return nil
+	}
+	slices.SortStableFunc(t.timings, func(a, b SubtitleTiming) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.PID, b.PID), cmp.Compare(a.StartSeconds, b.StartSeconds))
+	})
+	return t.timings
+}
+
+// captionSink returns the bdrom.BDROM.PGSCaptions callback feeding the OCR
+// and the timings that are set; nil when neither is.
+func captionSink(ocr *subtitleOCR, timings *subtitleTimings) func(bdrom.PGSCaption) {
+	switch {
+	case ocr != nil && timings != nil:
+		return func(caption bdrom.PGSCaption) {
+			timings.add(caption)
+			ocr.add(caption)
+		}
+	case ocr != nil:
+		return ocr.add
+	case timings != nil:
+		return timings.add
+	}
+	return nil
+}
+
+// SubtitleTimeline returns the timings that fall within the playlist's main
+// angle clips, timed from the start of the playlist and ordered by PID and
+// time. A caption still shown when its clip ends, or whose end the scan did
+// not see, ends with the clip.
+func (p PlaylistInfo) SubtitleTimeline(timings []SubtitleTiming) []SubtitleTiming {
+	var timeline []SubtitleTiming
+	for _, clip := range p.Clips {
+		if clip.Angle != 0 {
+			continue
+		}
+		offset := clip.StartSeconds - clip.InSeconds
+		for _, timing := range timings {
+			if !sameStreamFile(timing.File, clip.Name) {
+				continue
+			}
+			if timing.StartSeconds < clip.InSeconds || timing.StartSeconds >= clip.OutSeconds {
+				continue
+			}
+			end := timing.EndSeconds
+			if end <= timing.StartSeconds || end > clip.OutSeconds {
+				end = clip.OutSeconds
+			}
+			timing.StartSeconds += offset
+			timing.EndSeconds = end + offset
+			timeline = append(timeline, timing)
+		}
+	}
+	slices.SortStableFunc(timeline, func(a, b SubtitleTiming) int {
+		return cmp.Or(cmp.Compare(a.PID, b.PID), cmp.Compare(a.StartSeconds, b.StartSeconds))
+	})
+	return timeline
+}
+
+// WriteSubtitleTimingCSV writes a SubtitleTimeline as CSV, one caption per
+// row with its times in seconds.
+func WriteSubtitleTimingCSV(w io.Writer, timeline []SubtitleTiming) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"PID", "Language", "Forced", "File", "Start", "End", "Duration"}); err != nil {
+		return err
+	}
+	seconds := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	for _, timing := range timeline {
+		row := []string{
+			strconv.Itoa(int(timing.PID)),
+			timing.Language,
+			strconv.FormatBool(timing.Forced),
+			timing.File,
+			seconds(timing.StartSeconds),
+			seconds(timing.EndSeconds),
+			seconds(timing.EndSeconds - timing.StartSeconds),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSRT writes the captions of the given PID that fall within the
+// playlist's main angle clips to w as SubRip, timed from the start of the
+// playlist. A caption without an end is shown for four seconds, cut at the end
+// of its clip.
+func (p PlaylistInfo) WriteSRT(w io.Writer, captions []Caption, pid uint16) error {
+	var b strings.Builder
+	n := 0
+	for _, clip := range p.Clips {
+		if clip.Angle != 0 {
+			continue
+		}
+		for _, caption := range captions {
+			if caption.PID != pid || !sameStreamFile(caption.File, clip.Name) {
+				continue
+			}
+			if caption.StartSeconds < clip.InSeconds || caption.StartSeconds >= clip.OutSeconds {
+				continue
+			}
+			end := caption.EndSeconds
+			if end <= caption.StartSeconds {
+				end = caption.StartSeconds + srtDefaultDuration
+			}
+			end = min(end, clip.OutSeconds)
+			offset := clip.StartSeconds - clip.InSeconds
+			n++
+			fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", n, srtTime(caption.StartSeconds+offset), srtTime(end+offset), caption.Text)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sameStreamFile matches a caption's M2TS name to a FILES table name, which is
+// the SSIF when the scan reads interleaved files.
+func sameStreamFile(file, clip string) bool {
+	return strings.EqualFold(strings.TrimSuffix(file, filepath.Ext(file)), strings.TrimSuffix(clip, filepath.Ext(clip)))
+}
+
+func srtTime(seconds float64) string {
+	ms := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
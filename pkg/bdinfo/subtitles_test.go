@@ -0,0 +1,70 @@
+package bdinfo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+)
+
+func bdromCaption(file string, start float64) bdrom.PGSCaption {
+	return bdrom.PGSCaption{File: file, PID: 4608, Language: "eng", Start: start, End: start + 2}
+}
+
+func TestSubtitleTimeline(t *testing.T) {
+	playlist := PlaylistInfo{Name: "00800.MPLS", Clips: []ClipInfo{
+		{Name: "00001.M2TS", StartSeconds: 0, InSeconds: 600, OutSeconds: 1200},
+		{Name: "00002.M2TS", StartSeconds: 600, InSeconds: 10, OutSeconds: 70},
+		{Name: "00009.M2TS", Angle: 1, StartSeconds: 600, InSeconds: 10, OutSeconds: 70},
+	}}
+	timings := []SubtitleTiming{
+		{File: "00001.M2TS", PID: 4608, Forced: true, StartSeconds: 601.5, EndSeconds: 603},
+		{File: "00001.M2TS", PID: 4608, StartSeconds: 100, EndSeconds: 102},
+		{File: "00001.M2TS", PID: 4608, StartSeconds: 1199, EndSeconds: 1202},
+		{File: "00002.M2TS", PID: 4608, StartSeconds: 68},
+		{File: "00009.M2TS", PID: 4608, StartSeconds: 20, EndSeconds: 21},
+		{File: "00002.M2TS", PID: 4609, StartSeconds: 20, EndSeconds: 21},
+	}
+
+	got := playlist.SubtitleTimeline(timings)
+	want := []SubtitleTiming{
+		{File: "00001.M2TS", PID: 4608, Forced: true, StartSeconds: 1.5, EndSeconds: 3},
+		{File: "00001.M2TS", PID: 4608, StartSeconds: 599, EndSeconds: 600},
+		{File: "00002.M2TS", PID: 4608, StartSeconds: 658, EndSeconds: 660},
+		{File: "00002.M2TS", PID: 4609, StartSeconds: 610, EndSeconds: 611},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("timeline %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("timeline[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	var b strings.Builder
+	if err := WriteSubtitleTimingCSV(&b, got[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "PID,Language,Forced,File,Start,End,Duration\n4608,,true,00001.M2TS,1.500,3.000,1.500\n"; b.String() != want {
+		t.Fatalf("CSV:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestCaptionSink(t *testing.T) {
+	if captionSink(nil, nil) != nil {
+		t.Fatal("sink without OCR or timings")
+	}
+	timings := &subtitleTimings{}
+	ocr := &subtitleOCR{ocr: func(img SubtitleImage) (string, error) { return "Hi", nil }}
+	sink := captionSink(ocr, timings)
+	sink(bdromCaption("00002.M2TS", 20))
+	sink(bdromCaption("00001.M2TS", 30))
+	got := timings.result()
+	if len(got) != 2 || got[0].File != "00001.M2TS" || got[1].StartSeconds != 20 {
+		t.Fatalf("timings %+v", got)
+	}
+	if captions, err := ocr.result(); err != nil || len(captions) != 2 {
+		t.Fatalf("OCR captions %+v, %v", captions, err)
+	}
+}
@@ -0,0 +1,329 @@
+package bdinfo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/report"
+	internalsettings "github.com/autobrr/go-bdinfo/internal/settings"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+// WarningCode classifies an actionable, non-fatal issue found during Run.
+type WarningCode string
+
+const (
+	// WarningSuspiciousFrameRate flags video streams with an unknown frame rate
+	// or playlists mixing 50Hz and 60Hz video.
+	WarningSuspiciousFrameRate WarningCode = "suspicious_frame_rate"
+	// WarningTruncatedClip flags stream files that end before the clip's out time
+	// or whose size is not a whole number of TS packets.
+	WarningTruncatedClip WarningCode = "truncated_clip"
+	// WarningEmptyClip flags stream files smaller than one TS packet, which
+	// are scanned as empty clips.
+	WarningEmptyClip WarningCode = "empty_clip"
+	// WarningEncryptedHint flags stream files that look AACS/BD+ encrypted.
+	WarningEncryptedHint WarningCode = "encrypted_hint"
+	// WarningInvalidDuration flags play items whose out time precedes the in
+	// time (clamped to zero length) and playlists longer than 24 hours.
+	WarningInvalidDuration WarningCode = "invalid_duration"
+	// WarningMainPlaylistTie flags main playlist selections decided only by name.
+	WarningMainPlaylistTie WarningCode = "main_playlist_tie"
+	// WarningPlaylistObfuscation flags discs whose duplicate playlists look like
+	// deliberate obfuscation (see DiscInfo.PlaylistObfuscation).
+	WarningPlaylistObfuscation WarningCode = "playlist_obfuscation"
+	// WarningAudioDropout flags LPCM and TrueHD streams that carry no packets
+	// for two seconds or more (full scans only), usually an authoring or rip
+	// fault.
+	WarningAudioDropout WarningCode = "audio_dropout"
+	// WarningAudioSilence flags LPCM streams whose samples are all zero for two
+	// seconds or more (full scans only).
+	WarningAudioSilence WarningCode = "audio_silence"
+	// WarningDolbyVisionPairing flags UHD playlists whose Dolby Vision layers
+	// do not pair: an enhancement layer (PID 0x1015) without a base layer
+	// (0x1011) or the reverse for a dual-layer RPU, or layers whose frame
+	// rates differ or whose resolutions are neither equal nor 2:1.
+	WarningDolbyVisionPairing WarningCode = "dolby_vision_pairing"
+	// WarningStructureAnomaly flags playlist and clip info files whose
+	// section addresses or lengths do not fit the file. The file may still
+	// have been read; parse failures are in ScanInfo.FileErrors instead.
+	WarningStructureAnomaly WarningCode = "structure_anomaly"
+)
+
+// Warning is a typed issue embedders can surface without parsing the report.
+type Warning struct {
+	Code     WarningCode `json:"code"`
+	Playlist string      `json:"playlist,omitempty"`
+	File     string      `json:"file,omitempty"`
+	PID      uint16      `json:"pid,omitempty"`
+	Message  string      `json:"message"`
+}
+
+// truncationSlackSeconds tolerates timestamp rounding at clip boundaries.
+const truncationSlackSeconds = 1.0
+
+func buildWarnings(rom *bdrom.BDROM, playlists []*bdrom.PlaylistFile, cfg internalsettings.Settings) []Warning {
+	var warnings []Warning
+
+	names := make([]string, 0, len(rom.StreamFiles))
+	for name := range rom.StreamFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		file := rom.StreamFiles[name]
+		if file == nil || file.PacketCount == 0 {
+			continue
+		}
+		if file.SyncLostPackets*2 > file.PacketCount || file.ScrambledPackets > 0 {
+			warnings = append(warnings, Warning{
+				Code: WarningEncryptedHint,
+				File: file.Name,
+				Message: fmt.Sprintf("%d of %d packets lost sync and %d were scrambled; the stream may still be encrypted",
+					file.SyncLostPackets, file.PacketCount, file.ScrambledPackets),
+			})
+		}
+	}
+
+	for _, playlist := range playlists {
+		if playlist == nil {
+			continue
+		}
+		warnings = append(warnings, frameRateWarnings(playlist)...)
+		warnings = append(warnings, truncationWarnings(playlist)...)
+		warnings = append(warnings, dropoutWarnings(playlist)...)
+		warnings = append(warnings, dolbyVisionWarnings(playlist)...)
+		for _, dw := range playlist.DurationWarnings {
+			warnings = append(warnings, Warning{
+				Code:     WarningInvalidDuration,
+				Playlist: playlist.Name,
+				File:     dw.Clip,
+				Message:  dw.Message,
+			})
+		}
+	}
+
+	for _, sw := range bdrom.StructureWarnings(rom) {
+		warnings = append(warnings, Warning{
+			Code:    WarningStructureAnomaly,
+			File:    sw.File,
+			Message: sw.Message,
+		})
+	}
+
+	if groups := bdrom.GroupDuplicatePlaylists(playlists); bdrom.PlaylistObfuscation(groups) {
+		duplicates := 0
+		for _, group := range groups {
+			duplicates += len(group.Playlists) - 1
+		}
+		warnings = append(warnings, Warning{
+			Code:    WarningPlaylistObfuscation,
+			Message: fmt.Sprintf("playlist obfuscation detected: %d duplicate playlists in %d groups", duplicates, len(groups)),
+		})
+	}
+
+	if cfg.MainPlaylistOnly || cfg.BigPlaylistOnly {
+		if main, ties := report.MainPlaylistTies(playlists, cfg); main != nil && len(ties) > 0 {
+			warnings = append(warnings, Warning{
+				Code:     WarningMainPlaylistTie,
+				Playlist: main.Name,
+				Message:  fmt.Sprintf("main playlist chosen by name over identical candidates: %s", strings.Join(ties, ", ")),
+			})
+		}
+	}
+	return warnings
+}
+
+func frameRateWarnings(playlist *bdrom.PlaylistFile) []Warning {
+	var warnings []Warning
+	has50, has60 := false, false
+	for _, vs := range playlist.VideoStreams {
+		switch vs.FrameRate() {
+		case stream.FrameRateUnknown:
+			warnings = append(warnings, Warning{
+				Code:     WarningSuspiciousFrameRate,
+				Playlist: playlist.Name,
+				PID:      vs.PID,
+				Message:  "video stream has an unknown frame rate",
+			})
+		case stream.FrameRate25, stream.FrameRate50:
+			has50 = true
+		default:
+			has60 = true
+		}
+	}
+	if has50 && has60 {
+		warnings = append(warnings, Warning{
+			Code:     WarningSuspiciousFrameRate,
+			Playlist: playlist.Name,
+			Message:  "playlist mixes 50Hz and 60Hz video streams",
+		})
+	}
+	return warnings
+}
+
+func truncationWarnings(playlist *bdrom.PlaylistFile) []Warning {
+	var warnings []Warning
+	seen := map[string]bool{}
+	for _, clip := range playlist.StreamClips {
+		file := clip.StreamFile
+		if file == nil || seen[file.Name] {
+			continue
+		}
+		switch {
+		case file.Size < 192:
+			seen[file.Name] = true
+			warnings = append(warnings, Warning{
+				Code:     WarningEmptyClip,
+				Playlist: playlist.Name,
+				File:     file.Name,
+				Message:  fmt.Sprintf("file is %d bytes, smaller than a TS packet; scanned as an empty clip", file.Size),
+			})
+		case file.Size%192 != 0 && file.Size%188 != 0:
+			seen[file.Name] = true
+			warnings = append(warnings, Warning{
+				Code:     WarningTruncatedClip,
+				Playlist: playlist.Name,
+				File:     file.Name,
+				Message:  fmt.Sprintf("file size %d is not a whole number of TS packets", file.Size),
+			})
+		case file.Length > 0 && clip.Length-file.Length > truncationSlackSeconds:
+			seen[file.Name] = true
+			warnings = append(warnings, Warning{
+				Code:     WarningTruncatedClip,
+				Playlist: playlist.Name,
+				File:     file.Name,
+				Message:  fmt.Sprintf("stream timestamps cover %.3fs of the %.3fs clip", file.Length, clip.Length),
+			})
+		}
+	}
+	return warnings
+}
+
+// dropoutWarnings reports the audio dropouts of the playlist's clips at their
+// playlist time.
+func dropoutWarnings(playlist *bdrom.PlaylistFile) []Warning {
+	var warnings []Warning
+	for _, clip := range playlist.StreamClips {
+		if clip.StreamFile == nil || clip.AngleIndex != 0 {
+			continue
+		}
+		for _, dropout := range clip.StreamFile.AudioDropouts {
+			st, ok := playlist.Streams[dropout.PID]
+			if !ok || dropout.End <= clip.TimeIn || dropout.Start >= clip.TimeOut {
+				continue
+			}
+			start := max(dropout.Start, clip.TimeIn)
+			end := min(dropout.End, clip.TimeOut)
+			at := clip.RelativeTimeIn + start - clip.TimeIn
+			warning := Warning{
+				Code:     WarningAudioDropout,
+				Playlist: playlist.Name,
+				File:     clip.StreamFile.Name,
+				PID:      dropout.PID,
+				Message:  fmt.Sprintf("%s stream carries no audio for %.3fs at %s", stream.CodecShortNameForInfo(st), end-start, warningTime(at)),
+			}
+			if dropout.Silence {
+				warning.Code = WarningAudioSilence
+				warning.Message = fmt.Sprintf("%s stream is silent for %.3fs at %s", stream.CodecShortNameForInfo(st), end-start, warningTime(at))
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// Blu-ray PIDs of the HEVC base and Dolby Vision enhancement layers.
+const (
+	dolbyVisionBLPID = 0x1011
+	dolbyVisionELPID = 0x1015
+)
+
+// dolbyVisionWarnings checks that the main angle's Dolby Vision base and
+// enhancement layers pair up.
+func dolbyVisionWarnings(playlist *bdrom.PlaylistFile) []Warning {
+	var bl, el *stream.VideoStream
+	var dv *stream.DolbyVision
+	for _, vs := range playlist.VideoStreams {
+		if vs.StreamType != stream.StreamTypeHEVCVideo || vs.AngleIndex > 0 {
+			continue
+		}
+		switch {
+		case vs.PID == dolbyVisionBLPID && bl == nil:
+			bl = vs
+		case vs.PID == dolbyVisionELPID && el == nil:
+			el = vs
+		}
+		if ext, ok := vs.ExtendedData.(*stream.HEVCExtendedData); ok && ext != nil && ext.DolbyVision != nil && dv == nil {
+			dv = ext.DolbyVision
+		}
+	}
+	warn := func(pid uint16, format string, args ...any) []Warning {
+		return []Warning{{
+			Code:     WarningDolbyVisionPairing,
+			Playlist: playlist.Name,
+			PID:      pid,
+			Message:  fmt.Sprintf(format, args...),
+		}}
+	}
+
+	switch {
+	case el == nil && bl != nil && dv != nil && dv.Profile == 7:
+		return warn(bl.PID, "Dolby Vision profile 7 RPU without an enhancement layer stream")
+	case el == nil:
+		return nil
+	case bl == nil:
+		return warn(el.PID, "Dolby Vision enhancement layer without a base layer (PID 0x%04X)", dolbyVisionBLPID)
+	}
+
+	var warnings []Warning
+	if bl.FrameRate() != el.FrameRate() {
+		warnings = append(warnings, warn(el.PID, "Dolby Vision enhancement layer frame rate %s does not match the base layer's %s",
+			frameRateLabel(el), frameRateLabel(bl))...)
+	}
+	if !dolbyVisionLayerSizesPair(bl, el) {
+		warnings = append(warnings, warn(el.PID, "Dolby Vision enhancement layer %s does not pair with the %s base layer",
+			videoSizeLabel(el), videoSizeLabel(bl))...)
+	}
+	return warnings
+}
+
+// dolbyVisionLayerSizesPair reports whether the enhancement layer has the base
+// layer's size or half of it in each dimension. Widths are compared only when
+// both streams' sequence headers were read.
+func dolbyVisionLayerSizesPair(bl, el *stream.VideoStream) bool {
+	pairs := func(b, e int) bool { return e == b || e*2 == b }
+	if bl.Height == 0 || el.Height == 0 {
+		return true
+	}
+	if !pairs(bl.Height, el.Height) {
+		return false
+	}
+	if bl.Width == 0 || el.Width == 0 {
+		return true
+	}
+	return pairs(bl.Width, el.Width) && (el.Width == bl.Width) == (el.Height == bl.Height)
+}
+
+func frameRateLabel(vs *stream.VideoStream) string {
+	if vs.FrameRateDen == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%.3f fps", float64(vs.FrameRateEnum)/float64(vs.FrameRateDen))
+}
+
+func videoSizeLabel(vs *stream.VideoStream) string {
+	if vs.Width > 0 {
+		return fmt.Sprintf("%dx%d", vs.Width, vs.Height)
+	}
+	return fmt.Sprintf("%dp", vs.Height)
+}
+
+// warningTime formats playlist seconds as h:mm:ss.mmm.
+func warningTime(seconds float64) string {
+	ms := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,116 @@
+package bdinfo
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/autobrr/go-bdinfo/internal/bdrom"
+	"github.com/autobrr/go-bdinfo/internal/stream"
+)
+
+func testHEVC(pid uint16, format stream.VideoFormat, rate stream.FrameRate, dv *stream.DolbyVision) *stream.VideoStream {
+	vs := &stream.VideoStream{Stream: stream.Stream{PID: pid, StreamType: stream.StreamTypeHEVCVideo}}
+	vs.SetVideoFormat(format)
+	vs.SetFrameRate(rate)
+	if dv != nil {
+		vs.ExtendedData = &stream.HEVCExtendedData{DolbyVision: dv}
+	}
+	return vs
+}
+
+func TestDolbyVisionWarnings(t *testing.T) {
+	profile7 := &stream.DolbyVision{Profile: 7, Compatibility: 6, EnhancementLayer: true}
+	profile8 := &stream.DolbyVision{Profile: 8, Compatibility: 1}
+	tests := []struct {
+		name   string
+		videos []*stream.VideoStream
+		want   []uint16 // PIDs of the expected warnings
+	}{
+		{
+			name:   "single layer",
+			videos: []*stream.VideoStream{testHEVC(0x1011, stream.VideoFormat2160p, stream.FrameRate23976, profile8)},
+		},
+		{
+			name: "dual layer",
+			videos: []*stream.VideoStream{
+				testHEVC(0x1011, stream.VideoFormat2160p, stream.FrameRate23976, profile7),
+				testHEVC(0x1015, stream.VideoFormat1080p, stream.FrameRate23976, nil),
+			},
+		},
+		{
+			name: "dual layer frame rate mismatch",
+			videos: []*stream.VideoStream{
+				testHEVC(0x1011, stream.VideoFormat2160p, stream.FrameRate23976, profile7),
+				testHEVC(0x1015, stream.VideoFormat1080p, stream.FrameRate24, nil),
+			},
+			want: []uint16{0x1015},
+		},
+		{
+			name:   "profile 7 without enhancement layer",
+			videos: []*stream.VideoStream{testHEVC(0x1011, stream.VideoFormat2160p, stream.FrameRate23976, profile7)},
+			want:   []uint16{0x1011},
+		},
+		{
+			name:   "enhancement layer without base layer",
+			videos: []*stream.VideoStream{testHEVC(0x1015, stream.VideoFormat1080p, stream.FrameRate23976, nil)},
+			want:   []uint16{0x1015},
+		},
+		{
+			name: "picture in picture",
+			videos: []*stream.VideoStream{
+				testHEVC(0x1011, stream.VideoFormat2160p, stream.FrameRate23976, profile8),
+				testHEVC(0x1B00, stream.VideoFormat1080p, stream.FrameRate5994, nil),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playlist := &bdrom.PlaylistFile{Name: "00800.MPLS", VideoStreams: tt.videos}
+			var got []uint16
+			for _, w := range dolbyVisionWarnings(playlist) {
+				if w.Code != WarningDolbyVisionPairing || w.Playlist != "00800.MPLS" {
+					t.Fatalf("warning got=%+v", w)
+				}
+				got = append(got, w.PID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("warning PIDs got=%v want=%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncationWarnings(t *testing.T) {
+	clip := func(name string, size int64, fileLength, clipLength float64) *bdrom.StreamClip {
+		return &bdrom.StreamClip{StreamFile: &bdrom.StreamFile{Name: name, Size: size, Length: fileLength}, Length: clipLength}
+	}
+	short := clip("00004.M2TS", 192*1000, 30, 60)
+	playlist := &bdrom.PlaylistFile{
+		Name: "00800.MPLS",
+		StreamClips: []*bdrom.StreamClip{
+			clip("00001.M2TS", 192*1000, 60, 60),
+			clip("00002.M2TS", 100, 0, 10),
+			clip("00003.M2TS", 192*1000+5, 60, 60),
+			short,
+			clip("00005.M2TS", 192*1000, 59.5, 60),
+			short, // played twice, warned once
+		},
+	}
+	want := []struct {
+		code WarningCode
+		file string
+	}{
+		{WarningEmptyClip, "00002.M2TS"},
+		{WarningTruncatedClip, "00003.M2TS"},
+		{WarningTruncatedClip, "00004.M2TS"},
+	}
+	got := truncationWarnings(playlist)
+	if len(got) != len(want) {
+		t.Fatalf("warnings got=%+v", got)
+	}
+	for i, w := range want {
+		if got[i].Code != w.code || got[i].File != w.file || got[i].Playlist != "00800.MPLS" {
+			t.Fatalf("warning %d got=%+v want %s on %s", i, got[i], w.code, w.file)
+		}
+	}
+}